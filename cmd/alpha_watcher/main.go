@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 
+	"alpha_trading/internal/clock"
 	"alpha_trading/internal/config"
 	"alpha_trading/internal/logger"
 	"alpha_trading/internal/market"
@@ -24,6 +25,8 @@ func main() {
 	// Load configuration first to get logger settings
 	cfg := config.Load()
 	cfg.Version = readVersion()
+	// Label every message when several instances share the bot (SHARD_NAME)
+	telegram.SetInstance(cfg.ShardName, cfg.ShardListenAddr != "")
 
 	// Setup logging with configured values
 	logger.Setup(LogFile, cfg.MaxLogSizeMB, cfg.MaxLogBackups)
@@ -34,11 +37,52 @@ func main() {
 
 	// 2. Setup Dependencies
 	// 4. Initialize Dependency Injection
-	// Market Provider (Alpaca)
-	marketProvider := market.NewAlpacaProvider()
+	// Market Provider (Alpaca by default, Kraken for crypto accounts)
+	var marketProvider market.MarketProvider = market.NewAlpacaProvider()
+	if cfg.MarketProvider == "kraken" {
+		kraken, err := market.NewKrakenProvider()
+		if err != nil {
+			log.Fatalf("CRITICAL: Kraken provider: %v", err)
+		}
+		marketProvider = kraken
+	}
+	// Per-call deadline, so a hung broker request cannot block the poll loop
+	market.SetCallTimeout(time.Duration(cfg.MarketCallTimeoutSec) * time.Second)
+	// Per-method call counts, latencies and errors of the broker's own endpoints (/stats)
+	if cfg.MetricsEnabled {
+		marketProvider = market.WithMetrics(marketProvider, time.Duration(cfg.MetricsSlowCallMs)*time.Millisecond)
+	}
+	// Client-side throttling (token buckets per endpoint class) to stay under broker rate limits
+	marketProvider = market.WithRateLimit(marketProvider, cfg.RateLimitDataPerMin, cfg.RateLimitTradingPerMin)
+	// Retry transient errors on safe calls (never PlaceOrder); each retry still passes the rate limiter
+	marketProvider = market.WithRetry(marketProvider, cfg.RetryMaxAttempts, time.Duration(cfg.RetryBaseDelayMs)*time.Millisecond)
+	// Secondary price feed so stop-loss checks survive a broker data outage
+	if cfg.DataFallbackProvider != "" {
+		source, err := market.NewPriceSource(cfg.DataFallbackProvider)
+		if err != nil {
+			log.Fatalf("CRITICAL: Data fallback provider: %v", err)
+		}
+		marketProvider = market.WithFallback(marketProvider, source)
+		log.Printf("Data Fallback Provider: %s", source.Name())
+	}
+	// Watch-only forex pairs (EURUSD): rates from a dedicated FX feed, orders refused
+	if cfg.FXProvider != "" {
+		source, err := market.NewFXSource(cfg.FXProvider)
+		if err != nil {
+			log.Fatalf("CRITICAL: FX provider: %v", err)
+		}
+		marketProvider = market.WithFX(marketProvider, source)
+		log.Printf("FX Provider: %s", source.Name())
+	}
+	// Short-lived price cache shared by /status, /list, the risk check and sync
+	marketProvider = market.WithPriceCache(marketProvider, time.Duration(cfg.PriceCacheTTLSec)*time.Second, cfg.Clock)
+	log.Printf("Market Provider: %s", cfg.MarketProvider)
 
 	// Watcher (The core logic)
-	w := watcher.New(cfg, marketProvider)
+	w := watcher.New(ctx, cfg, marketProvider)
+
+	// Order fills/cancels/rejects pushed by the broker (replaces confirmation polling while up)
+	w.StartTradeStream(ctx)
 
 	// 3. Start Telegram Command Listener (Background)
 	// We pass the watcher to the listener so it can query state/uptime
@@ -47,7 +91,13 @@ func main() {
 	// Let's check how we started it before.
 	// Previously: go telegram.StartListener(ctx, w.HandleCommand)
 	// That remains valid since w.HandleCommand signature hasn't changed.
-	go telegram.StartListener(w.HandleCommand, w.HandleCallback)
+	// A secondary instance (SHARD_LISTEN_ADDR) receives its commands from the primary's
+	// listener instead of polling Telegram itself
+	if cfg.ShardListenAddr != "" {
+		w.StartShardServer(ctx)
+	} else {
+		go telegram.StartListener(w.HandleCommand, w.HandleCallback, w.HandleDocument)
+	}
 
 	// 4. Setup Signal Handling (Graceful Shutdown)
 	c := make(chan os.Signal, 1)
@@ -64,21 +114,40 @@ func main() {
 
 	// 5. Main Loop
 	// Listen for context cancellation or ticker
-	w.Poll() // Run once immediately on start
+	w.CheckDowntime() // Report fills/triggers missed while offline (before Poll updates LastSync)
+	w.Poll()          // Run once immediately on start
+
+	// Timers run on the watcher's clock (config.Clock), like everything inside it
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+
+	// The interval is shortened to land on session opens/closes from the trading calendar
+	interval := time.Duration(cfg.PollIntervalMins) * time.Minute
+	next := clk.After(w.NextPollDelay(interval))
 
-	ticker := time.NewTicker(time.Duration(cfg.PollIntervalMins) * time.Minute)
-	defer ticker.Stop()
+	// Hot positions (/priority) are also checked between polls, on the same goroutine
+	var hotTick <-chan time.Time
+	if cfg.HotPollIntervalSec > 0 {
+		hot := clk.NewTicker(time.Duration(cfg.HotPollIntervalSec) * time.Second)
+		defer hot.Stop()
+		hotTick = hot.C()
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("🛑 Main loop stopping...")
+			w.FlushState()
 			return
-		case <-ticker.C:
-			// Calculate next run time for logging purposes
-			nextTick := time.Now().In(config.CetLoc).Add(time.Duration(cfg.PollIntervalMins) * time.Minute)
-			log.Printf("Next check scheduled for: %s", nextTick.Format("2006-01-02 15:04:05 MST"))
+		case <-hotTick:
+			w.CheckHot()
+		case <-next:
 			w.Poll()
+			delay := w.NextPollDelay(interval)
+			log.Printf("Next check scheduled for: %s", clk.Now().In(config.CetLoc).Add(delay).Format("2006-01-02 15:04:05 MST"))
+			next = clk.After(delay)
 		}
 	}
 }
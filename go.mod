@@ -3,13 +3,13 @@ module alpha_trading
 go 1.22
 
 require (
+	cloud.google.com/go v0.118.0
 	github.com/alpacahq/alpaca-trade-api-go/v3 v3.9.0
 	github.com/joho/godotenv v1.5.1
 	github.com/shopspring/decimal v1.4.0
 )
 
 require (
-	cloud.google.com/go v0.118.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -8,13 +9,21 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Client struct {
 	apiKey string
-	url    string
+	models []string // Primary first, then fallbacks (GEMINI_FALLBACK_MODELS)
 }
 
+const (
+	attemptsPerModel = 2               // Tries per model before falling back
+	retryDelay       = 2 * time.Second // Pause between tries of the same model
+)
+
 func NewClient() *Client {
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	model := os.Getenv("GEMINI_MODEL")
@@ -22,8 +31,18 @@ func NewClient() *Client {
 		model = "gemini-2.5-flash" // Sensible default
 	}
 
-	// Dynamic endpoint construction
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", model)
+	// Fallback chain: used when the primary errors or returns malformed JSON
+	fallbacks := os.Getenv("GEMINI_FALLBACK_MODELS")
+	if fallbacks == "" {
+		fallbacks = "gemini-2.5-flash-lite" // Cheaper sibling
+	}
+	models := []string{model}
+	for _, m := range strings.Split(fallbacks, ",") {
+		m = strings.TrimSpace(m)
+		if m != "" && m != model && m != "none" {
+			models = append(models, m)
+		}
+	}
 
 	if apiKey == "" {
 		log.Println("WARNING: GEMINI_API_KEY not found. AI features will be disabled/mocked.")
@@ -31,18 +50,140 @@ func NewClient() *Client {
 
 	return &Client{
 		apiKey: apiKey,
-		url:    url,
+		models: models,
 	}
 }
 
 // AnalyzePortfolio sends the snapshot to Gemini and parses the response.
+// Each model in the chain gets attemptsPerModel tries; the first valid answer wins.
+// AIAnalysis.Model records which model produced it.
 func (c *Client) AnalyzePortfolio(systemInstruction string, snapshot PortfolioSnapshot) (*AIAnalysis, error) {
-	if c.apiKey == "" {
-		return nil, fmt.Errorf("AI client not configured")
-	}
+	return c.analyze(systemInstruction, snapshot, nil)
+}
 
+// AnalyzePortfolioStream is AnalyzePortfolio over the streaming endpoint: onText
+// receives the accumulated raw output after every chunk (restarting on retries/fallbacks).
+func (c *Client) AnalyzePortfolioStream(systemInstruction string, snapshot PortfolioSnapshot, onText func(string)) (*AIAnalysis, error) {
+	return c.analyze(systemInstruction, snapshot, onText)
+}
+
+func (c *Client) analyze(systemInstruction string, snapshot PortfolioSnapshot, onText func(string)) (*AIAnalysis, error) {
 	// Prepare Payload
 	snapJSON, _ := json.Marshal(snapshot)
+	prompt := fmt.Sprintf("Analyze this portfolio state: %s", string(snapJSON))
+
+	var analysis *AIAnalysis
+	model, err := c.generate(systemInstruction, prompt, onText, func(text string) error {
+		var err error
+		analysis, err = parseAnalysis(text)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	analysis.Model = model
+	return analysis, nil
+}
+
+// ReviewStrategy asks for structured strategy suggestions on a week of trading.
+func (c *Client) ReviewStrategy(systemInstruction string, input StrategyReviewInput) (*StrategyReview, error) {
+	inputJSON, _ := json.Marshal(input)
+	prompt := fmt.Sprintf("Review this trading week: %s", string(inputJSON))
+
+	var review StrategyReview
+	model, err := c.generate(systemInstruction, prompt, nil, func(text string) error {
+		review = StrategyReview{}
+		if err := json.Unmarshal([]byte(text), &review); err != nil {
+			return fmt.Errorf("failed to parse strategy review JSON: %v. Raw: %s", err, text)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	review.Model = model
+	return &review, nil
+}
+
+// Commentary asks for a short narrative of the day's EOD report.
+func (c *Client) Commentary(systemInstruction, report string) (*Commentary, error) {
+	prompt := fmt.Sprintf("Comment on today's market close report:\n%s", report)
+
+	var out Commentary
+	model, err := c.generate(systemInstruction, prompt, nil, func(text string) error {
+		out = Commentary{}
+		if err := json.Unmarshal([]byte(text), &out); err != nil {
+			return fmt.Errorf("failed to parse commentary JSON: %v. Raw: %s", err, text)
+		}
+		if strings.TrimSpace(out.Commentary) == "" {
+			return fmt.Errorf("empty commentary")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Model = model
+	return &out, nil
+}
+
+// generate runs the fallback chain: each model gets attemptsPerModel tries and the
+// first answer that parse accepts wins. Returns the model that produced it.
+func (c *Client) generate(systemInstruction, prompt string, onText func(string), parse func(string) error) (string, error) {
+	if c.apiKey == "" {
+		return "", fmt.Errorf("AI client not configured")
+	}
+
+	var errs []string
+	for i, model := range c.models {
+		for attempt := 1; attempt <= attemptsPerModel; attempt++ {
+			text, err := c.generateWith(model, systemInstruction, prompt, onText)
+			if err == nil {
+				err = parse(text)
+			}
+			if err == nil {
+				if i > 0 {
+					log.Printf("AI: answered by fallback model %s (primary %s failed)", model, c.models[0])
+				}
+				return model, nil
+			}
+			log.Printf("AI: model %s attempt %d/%d failed: %v", model, attempt, attemptsPerModel, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", model, err))
+			if attempt < attemptsPerModel {
+				time.Sleep(retryDelay)
+			}
+		}
+	}
+	return "", fmt.Errorf("all AI models failed:\n%s", strings.Join(errs, "\n"))
+}
+
+// geminiResponse is the (partial) generateContent response schema.
+// candidates[0].content.parts[0].text
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+}
+
+func (r geminiResponse) text() (string, bool) {
+	if len(r.Candidates) == 0 || len(r.Candidates[0].Content.Parts) == 0 {
+		return "", false
+	}
+	return r.Candidates[0].Content.Parts[0].Text, true
+}
+
+// generateWith makes one request against a single model (streamed when onText is set)
+// and returns the raw answer text.
+func (c *Client) generateWith(model, systemInstruction, prompt string, onText func(string)) (string, error) {
+	// Dynamic endpoint construction
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", model, c.apiKey)
+	if onText != nil {
+		url = fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", model, c.apiKey)
+	}
 
 	// Construct the prompt payload for Gemini REST API
 	// We use a simplified structure for the HTTP request
@@ -55,7 +196,7 @@ func (c *Client) AnalyzePortfolio(systemInstruction string, snapshot PortfolioSn
 		"contents": []map[string]interface{}{
 			{
 				"parts": []map[string]interface{}{
-					{"text": fmt.Sprintf("Analyze this portfolio state: %s", string(snapJSON))},
+					{"text": prompt},
 				},
 			},
 		},
@@ -66,19 +207,19 @@ func (c *Client) AnalyzePortfolio(systemInstruction string, snapshot PortfolioSn
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	req, err := http.NewRequest("POST", c.url+"?key="+c.apiKey, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -93,29 +234,56 @@ func (c *Client) AnalyzePortfolio(systemInstruction string, snapshot PortfolioSn
 			} `json:"error"`
 		}
 		if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil && errResp.Error.Message != "" {
-			return nil, fmt.Errorf("AI Error %d (%s): %s", resp.StatusCode, errResp.Error.Status, errResp.Error.Message)
+			return "", fmt.Errorf("AI Error %d (%s): %s", resp.StatusCode, errResp.Error.Status, errResp.Error.Message)
 		}
-		return nil, fmt.Errorf("AI API error %d: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("AI API error %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse Response
-	var result map[string]interface{}
+	if onText != nil {
+		return readStream(resp.Body, onText)
+	}
+	var result geminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+		return "", err
+	}
+	text, ok := result.text()
+	if !ok {
+		return "", fmt.Errorf("no candidates in AI response")
 	}
+	return text, nil
+}
 
-	// Extract text from Gemini response structure
-	// candidates[0].content.parts[0].text
-	// This is a bit verbose in Go map[string]interface{}, skipping strict struct for brevity
-	candidates, ok := result["candidates"].([]interface{})
-	if !ok || len(candidates) == 0 {
-		return nil, fmt.Errorf("no candidates in AI response")
+// readStream accumulates the text of a server-sent event stream ("data: {...}" lines).
+func readStream(body io.Reader, onText func(string)) (string, error) {
+	var sb strings.Builder
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", fmt.Errorf("malformed stream chunk: %v", err)
+		}
+		if t, ok := chunk.text(); ok && t != "" {
+			sb.WriteString(t)
+			onText(sb.String())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("no candidates in AI response")
 	}
-	candidate := candidates[0].(map[string]interface{})
-	content := candidate["content"].(map[string]interface{})
-	parts := content["parts"].([]interface{})
-	text := parts[0].(map[string]interface{})["text"].(string)
+	return sb.String(), nil
+}
 
+// parseAnalysis decodes the model's JSON answer (an object or a one-element list).
+func parseAnalysis(text string) (*AIAnalysis, error) {
 	// Unmarshal the JSON text inside the response
 	// Helper to handle potential array response (some models/prompts return list)
 	var analysisList []AIAnalysis
@@ -135,3 +303,51 @@ func (c *Client) AnalyzePortfolio(systemInstruction string, snapshot PortfolioSn
 
 	return &analysis, nil
 }
+
+// PartialField extracts the (possibly unterminated) string value of key from
+// incomplete JSON, so a streamed answer can be shown before it is valid JSON.
+func PartialField(raw, key string) string {
+	i := strings.Index(raw, `"`+key+`"`)
+	if i < 0 {
+		return ""
+	}
+	rest := raw[i+len(key)+2:]
+	j := strings.Index(rest, `"`)
+	if j < 0 || strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(rest[:j]), ":")) != "" {
+		return ""
+	}
+	rest = rest[j+1:]
+
+	var sb strings.Builder
+	for k := 0; k < len(rest); k++ {
+		ch := rest[k]
+		switch {
+		case ch == '"':
+			return sb.String()
+		case ch == '\\' && k+1 < len(rest):
+			k++
+			switch rest[k] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if k+4 < len(rest) {
+					if r, err := strconv.ParseUint(rest[k+1:k+5], 16, 32); err == nil {
+						sb.WriteRune(rune(r))
+					}
+					k += 4
+				} else {
+					return sb.String() // Escape cut mid-stream
+				}
+			default:
+				sb.WriteByte(rest[k]) // \" \\ \/
+			}
+		case ch == '\\':
+			return sb.String()
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
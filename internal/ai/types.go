@@ -1,6 +1,10 @@
 package ai
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // AIAnalysis represents the structured output expected from the Gemini model (Spec 59).
 type AIAnalysis struct {
@@ -9,18 +13,107 @@ type AIAnalysis struct {
 	ActionCommand   string  `json:"action_command"`
 	ConfidenceScore float64 `json:"confidence_score"`
 	RiskAssessment  string  `json:"risk_assessment"` // LOW, MEDIUM, HIGH
+	Model           string  `json:"-"`               // Model that produced the answer (set by the client)
 }
 
 // PortfolioSnapshot represents the data payload sent to the AI.
 type PortfolioSnapshot struct {
-	Timestamp       string             `json:"timestamp"`
-	MarketStatus    string             `json:"market_status"`
-	Capital         decimal.Decimal    `json:"capital_available"` // Buying Power
-	Equity          decimal.Decimal    `json:"equity"`
-	FiscalLimit     decimal.Decimal    `json:"fiscal_limit"`     // Spec 63 Hard Limit
-	AvailableBudget decimal.Decimal    `json:"available_budget"` // Spec 65: FiscalLimit - CurrentExposure
-	CurrentExposure decimal.Decimal    `json:"current_exposure"` // Total cost basis of active positions
-	Positions       interface{}        `json:"positions"`        // Raw list from state
-	MarketContext   string             `json:"market_context"`   // E.g., global trend or sector info if available
-	WatchlistPrices map[string]float64 `json:"watchlist_prices"` // Spec 74: Watchlist Prices injection
+	Timestamp       string                     `json:"timestamp"`
+	MarketStatus    string                     `json:"market_status"`
+	Capital         decimal.Decimal            `json:"capital_available"` // Buying Power
+	Equity          decimal.Decimal            `json:"equity"`
+	FiscalLimit     decimal.Decimal            `json:"fiscal_limit"`            // Spec 63 Hard Limit
+	AvailableBudget decimal.Decimal            `json:"available_budget"`        // Spec 65: FiscalLimit - CurrentExposure
+	CurrentExposure decimal.Decimal            `json:"current_exposure"`        // Total cost basis of active positions
+	Positions       interface{}                `json:"positions"`               // Raw list from state
+	MarketContext   string                     `json:"market_context"`          // E.g., global trend or sector info if available
+	WatchlistPrices map[string]decimal.Decimal `json:"watchlist_prices"`        // Spec 74: Watchlist Prices injection
+	WatchlistAsOf   map[string]time.Time       `json:"watchlist_prices_as_of"`  // Observation time per watchlist price (freshness)
+	Universe        string                     `json:"universe,omitempty"`      // Scope of a focused review (e.g., "watchlist", "sector:biotech")
+	UniverseData    []UniverseSymbol           `json:"universe_data,omitempty"` // Per-symbol data for the scope
+	Intraday        map[string][]Bar           `json:"intraday,omitempty"`      // Recent intraday bars per held/focus ticker, oldest first
+	IntradayFrame   string                     `json:"intraday_timeframe,omitempty"`
+	News            map[string][]Headline      `json:"news,omitempty"`         // Recent headlines per held/focus ticker, newest first
+	Fundamentals    map[string]Fundamentals    `json:"fundamentals,omitempty"` // Per held/focus ticker
+}
+
+// Fundamentals is a ticker's company data in the snapshot. Zero means unknown.
+type Fundamentals struct {
+	MarketCap float64 `json:"market_cap,omitempty"` // USD
+	PE        float64 `json:"pe_ttm,omitempty"`
+	AvgVolume float64 `json:"avg_volume,omitempty"` // Shares per day
+	High52w   float64 `json:"high_52w,omitempty"`
+	Low52w    float64 `json:"low_52w,omitempty"`
+}
+
+// Headline is one news item in the snapshot.
+type Headline struct {
+	Time     string `json:"t"` // RFC3339, UTC
+	Headline string `json:"headline"`
+}
+
+// Bar is one OHLCV bar in the snapshot.
+type Bar struct {
+	Time   string  `json:"t"` // RFC3339, UTC
+	Open   float64 `json:"o"`
+	High   float64 `json:"h"`
+	Low    float64 `json:"l"`
+	Close  float64 `json:"c"`
+	Volume uint64  `json:"v"`
+}
+
+// UniverseSymbol is the per-symbol data set for a focused /analyze review.
+type UniverseSymbol struct {
+	Ticker        string          `json:"ticker"`
+	Sector        string          `json:"sector"`
+	Price         decimal.Decimal `json:"price"`
+	DayChangePct  decimal.Decimal `json:"day_change_pct"`
+	Return5DPct   decimal.Decimal `json:"return_5d_pct"`
+	Return20DPct  decimal.Decimal `json:"return_20d_pct"`
+	Volatility20D decimal.Decimal `json:"volatility_20d_pct"` // Stdev of daily returns
+	AvgVolume20D  float64         `json:"avg_volume_20d"`
+	Held          bool            `json:"held"`
+	HeldQty       decimal.Decimal `json:"held_qty,omitempty"`
+	UnrealizedPct decimal.Decimal `json:"unrealized_pct,omitempty"`
+}
+
+// StrategyReviewInput is the payload for the weekly strategy review.
+type StrategyReviewInput struct {
+	PeriodStart   string             `json:"period_start"`
+	PeriodEnd     string             `json:"period_end"`
+	Parameters    map[string]float64 `json:"parameters"`     // Current defaults (stop_loss_pct, take_profit_pct, trailing_stop_pct)
+	Stats         interface{}        `json:"stats"`          // Win rate, payoff ratio, realized P/L for the period
+	Journal       interface{}        `json:"journal"`        // Round trips closed in the period
+	MissedSignals []string           `json:"missed_signals"` // Dismissed/expired alerts and proposals
+	Watchlist     []string           `json:"watchlist"`
+	Positions     interface{}        `json:"positions"`
+}
+
+// StrategyReview is the structured answer of the weekly strategy review.
+type StrategyReview struct {
+	Summary          string            `json:"summary"`
+	ParameterChanges []ParameterChange `json:"parameter_changes"`
+	DropTickers      []TickerNote      `json:"drop_tickers"`
+	Notes            []string          `json:"notes"`
+	Model            string            `json:"-"` // Model that produced the answer (set by the client)
+}
+
+// ParameterChange is a suggested new value for one of the input parameters.
+type ParameterChange struct {
+	Parameter string  `json:"parameter"`
+	Current   float64 `json:"current"`
+	Suggested float64 `json:"suggested"`
+	Reason    string  `json:"reason"`
+}
+
+// TickerNote names a ticker with the reason for the suggestion.
+type TickerNote struct {
+	Ticker string `json:"ticker"`
+	Reason string `json:"reason"`
+}
+
+// Commentary is the AI narrative appended to the EOD report.
+type Commentary struct {
+	Commentary string `json:"commentary"`
+	Model      string `json:"-"` // Model that produced the answer (set by the client)
 }
@@ -0,0 +1,222 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// Params are the exit rules applied to every simulated position (percent; 0 disables).
+type Params struct {
+	StopLossPct     float64
+	TakeProfitPct   float64
+	TrailingStopPct float64
+}
+
+func (p Params) String() string {
+	return fmt.Sprintf("SL %.1f%% / TP %.1f%% / TS %.1f%%", p.StopLossPct, p.TakeProfitPct, p.TrailingStopPct)
+}
+
+// Result aggregates one parameter set over one or more price series.
+type Result struct {
+	Params    Params
+	Trades    int
+	Wins      int
+	ReturnPct float64 // Mean compounded return per series
+	MaxDDPct  float64 // Worst peak-to-trough equity drawdown across series
+}
+
+// WinRate is Wins/Trades (0 without trades).
+func (r Result) WinRate() float64 {
+	if r.Trades == 0 {
+		return 0
+	}
+	return float64(r.Wins) / float64(r.Trades)
+}
+
+// Simulate runs the exit rules over daily bars: always invested, a position is
+// opened at the first close and re-opened at the close of the bar after each exit.
+// Within a bar the stop is checked before the target (the conservative order);
+// gaps through a level fill at the open.
+func Simulate(bars []marketdata.Bar, p Params) Result {
+	res := Result{Params: p}
+	if len(bars) < 2 {
+		return res
+	}
+
+	equity, peak := 1.0, 1.0
+	entry, hwm := bars[0].Close, bars[0].Close
+	invested := true
+
+	closeTrade := func(exit float64) {
+		ret := exit / entry
+		equity *= ret
+		res.Trades++
+		if ret > 1 {
+			res.Wins++
+		}
+		invested = false
+	}
+
+	for _, b := range bars[1:] {
+		if !invested {
+			entry, hwm = b.Close, b.Close
+			invested = true
+			continue
+		}
+
+		stop := 0.0
+		if p.StopLossPct > 0 {
+			stop = entry * (1 - p.StopLossPct/100)
+		}
+		if p.TrailingStopPct > 0 {
+			stop = math.Max(stop, hwm*(1-p.TrailingStopPct/100))
+		}
+		target := 0.0
+		if p.TakeProfitPct > 0 {
+			target = entry * (1 + p.TakeProfitPct/100)
+		}
+
+		switch {
+		case stop > 0 && b.Low <= stop:
+			closeTrade(math.Min(b.Open, stop))
+		case target > 0 && b.High >= target:
+			closeTrade(math.Max(b.Open, target))
+		default:
+			hwm = math.Max(hwm, b.High)
+		}
+
+		mark := equity
+		if invested {
+			mark = equity * b.Close / entry
+		}
+		peak = math.Max(peak, mark)
+		res.MaxDDPct = math.Max(res.MaxDDPct, (peak-mark)/peak*100)
+	}
+	if invested {
+		closeTrade(bars[len(bars)-1].Close)
+	}
+	res.ReturnPct = (equity - 1) * 100
+	return res
+}
+
+// Evaluate runs one parameter set over every series and aggregates the results.
+func Evaluate(series map[string][]marketdata.Bar, p Params) Result {
+	agg := Result{Params: p}
+	n := 0
+	for _, bars := range series {
+		r := Simulate(bars, p)
+		agg.Trades += r.Trades
+		agg.Wins += r.Wins
+		agg.ReturnPct += r.ReturnPct
+		agg.MaxDDPct = math.Max(agg.MaxDDPct, r.MaxDDPct)
+		n++
+	}
+	if n > 0 {
+		agg.ReturnPct /= float64(n)
+	}
+	return agg
+}
+
+// Range is an inclusive sweep of one parameter.
+type Range struct {
+	From, To, Step float64
+}
+
+// ParseRange parses "from:to:step" (e.g., "2:10:1") or a single value.
+func ParseRange(s string) (Range, error) {
+	parts := strings.Split(s, ":")
+	vals := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil || v < 0 {
+			return Range{}, fmt.Errorf("invalid range %q (use from:to:step)", s)
+		}
+		vals[i] = v
+	}
+	switch len(vals) {
+	case 1:
+		return Range{vals[0], vals[0], 1}, nil
+	case 3:
+		if vals[2] <= 0 || vals[1] < vals[0] {
+			return Range{}, fmt.Errorf("invalid range %q (need from <= to and step > 0)", s)
+		}
+		return Range{vals[0], vals[1], vals[2]}, nil
+	}
+	return Range{}, fmt.Errorf("invalid range %q (use from:to:step)", s)
+}
+
+func (r Range) values() []float64 {
+	var out []float64
+	for v := r.From; v <= r.To+1e-9; v += r.Step {
+		out = append(out, math.Round(v*100)/100)
+	}
+	return out
+}
+
+// Grid expands the ranges into every parameter combination.
+func Grid(sl, tp, ts Range) []Params {
+	var grid []Params
+	for _, s := range sl.values() {
+		for _, t := range tp.values() {
+			for _, tr := range ts.values() {
+				grid = append(grid, Params{StopLossPct: s, TakeProfitPct: t, TrailingStopPct: tr})
+			}
+		}
+	}
+	return grid
+}
+
+// Split cuts every series at trainFrac: the first part for fitting, the rest for validation.
+func Split(series map[string][]marketdata.Bar, trainFrac float64) (train, test map[string][]marketdata.Bar) {
+	train = make(map[string][]marketdata.Bar)
+	test = make(map[string][]marketdata.Bar)
+	for t, bars := range series {
+		cut := int(float64(len(bars)) * trainFrac)
+		train[t] = bars[:cut]
+		test[t] = bars[cut:]
+	}
+	return train, test
+}
+
+// Candidate is a parameter set ranked on the training window with its validation result.
+type Candidate struct {
+	InSample  Result
+	OutSample Result
+}
+
+// Optimize sweeps the grid on the training window, ranks by mean return and
+// validates the top sets on the held-out window.
+func Optimize(series map[string][]marketdata.Bar, grid []Params, trainFrac float64, top int) []Candidate {
+	train, test := Split(series, trainFrac)
+
+	results := rank(train, grid)
+	if len(results) > top {
+		results = results[:top]
+	}
+
+	out := make([]Candidate, len(results))
+	for i, r := range results {
+		out[i] = Candidate{InSample: r, OutSample: Evaluate(test, r.Params)}
+	}
+	return out
+}
+
+// rank evaluates every grid point and sorts by mean return, then shallower drawdown.
+func rank(series map[string][]marketdata.Bar, grid []Params) []Result {
+	results := make([]Result, 0, len(grid))
+	for _, p := range grid {
+		results = append(results, Evaluate(series, p))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].ReturnPct != results[j].ReturnPct {
+			return results[i].ReturnPct > results[j].ReturnPct
+		}
+		return results[i].MaxDDPct < results[j].MaxDDPct
+	})
+	return results
+}
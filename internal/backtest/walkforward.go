@@ -0,0 +1,117 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// Fold is one walk-forward step: the best set fitted on the train window and
+// how it (and the baseline) did on the following, unseen window.
+type Fold struct {
+	TrainStart, TestStart, TestEnd time.Time
+	Best                           Candidate
+	Baseline                       Result // Baseline params on the same test window
+}
+
+// WalkForward rolls a train/test window across every series. Each series is cut
+// into folds+trainSegs equal segments; fold i fits on segments [i, i+trainSegs)
+// and validates on segment i+trainSegs, so every test window is out-of-sample
+// and the windows never overlap.
+func WalkForward(series map[string][]marketdata.Bar, grid []Params, baseline Params, folds, trainSegs int) []Fold {
+	segs := folds + trainSegs
+	var out []Fold
+	for i := 0; i < folds; i++ {
+		train := make(map[string][]marketdata.Bar)
+		test := make(map[string][]marketdata.Bar)
+		var f Fold
+		longest := 0
+		for t, bars := range series {
+			size := len(bars) / segs
+			if size < 2 {
+				continue
+			}
+			trainFrom, testFrom := i*size, (i+trainSegs)*size
+			testTo := testFrom + size
+			if i == folds-1 {
+				testTo = len(bars) // Last fold takes the remainder
+			}
+			train[t] = bars[trainFrom:testFrom]
+			test[t] = bars[testFrom:testTo]
+			if len(bars) > longest { // Date labels follow the longest history
+				longest = len(bars)
+				f.TrainStart, f.TestStart, f.TestEnd = bars[trainFrom].Timestamp, bars[testFrom].Timestamp, bars[testTo-1].Timestamp
+			}
+		}
+		if len(train) == 0 {
+			break
+		}
+		best := rank(train, grid)[0]
+		f.Best = Candidate{InSample: best, OutSample: Evaluate(test, best.Params)}
+		f.Baseline = Evaluate(test, baseline)
+		out = append(out, f)
+	}
+	return out
+}
+
+// Stability summarizes how consistent the fitted parameters are across folds.
+type Stability struct {
+	SL, TP, TS   [2]float64 // Min/max of each chosen parameter
+	Mode         Params     // Most frequently chosen set
+	ModeCount    int
+	MeanIn       float64 // Mean in-sample return of the chosen sets
+	MeanOut      float64 // Mean out-of-sample return of the chosen sets
+	MeanBaseline float64 // Mean out-of-sample return of the baseline
+	Efficiency   float64 // MeanOut / MeanIn (≈1 robust, ≤0 curve-fit); 0 when MeanIn ≤ 0
+}
+
+// Summarize computes parameter spread and in/out-of-sample consistency.
+func Summarize(folds []Fold) Stability {
+	var s Stability
+	if len(folds) == 0 {
+		return s
+	}
+	counts := make(map[Params]int)
+	for i, f := range folds {
+		p := f.Best.InSample.Params
+		if i == 0 {
+			s.SL, s.TP, s.TS = [2]float64{p.StopLossPct, p.StopLossPct}, [2]float64{p.TakeProfitPct, p.TakeProfitPct}, [2]float64{p.TrailingStopPct, p.TrailingStopPct}
+		}
+		widen(&s.SL, p.StopLossPct)
+		widen(&s.TP, p.TakeProfitPct)
+		widen(&s.TS, p.TrailingStopPct)
+		counts[p]++
+		s.MeanIn += f.Best.InSample.ReturnPct
+		s.MeanOut += f.Best.OutSample.ReturnPct
+		s.MeanBaseline += f.Baseline.ReturnPct
+	}
+	n := float64(len(folds))
+	s.MeanIn /= n
+	s.MeanOut /= n
+	s.MeanBaseline /= n
+	if s.MeanIn > 0 {
+		s.Efficiency = s.MeanOut / s.MeanIn
+	}
+
+	sets := make([]Params, 0, len(counts))
+	for p := range counts {
+		sets = append(sets, p)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].String() < sets[j].String() }) // Deterministic ties
+	for _, p := range sets {
+		if counts[p] > s.ModeCount {
+			s.Mode, s.ModeCount = p, counts[p]
+		}
+	}
+	return s
+}
+
+func widen(r *[2]float64, v float64) {
+	if v < r[0] {
+		r[0] = v
+	}
+	if v > r[1] {
+		r[1] = v
+	}
+}
@@ -0,0 +1,82 @@
+// Package budget computes the capital metrics behind the fiscal hard-stop
+// (Spec 63), the persisted budget (Spec 65) and the AI planning budget (Spec 77).
+//
+// Rules:
+//   - Exposure is the cost basis (Qty x EntryPrice) of ACTIVE positions, never the
+//     market value: gains on open positions do not eat into the budget and losses
+//     do not free it up. Capital is only released when a position is closed.
+//   - The real cap is min(Equity, FiscalLimit). A paper account with $100k equity
+//     is still held to the fiscal limit, and an account that shrank below the
+//     limit cannot plan with "ghost money" it no longer has. Unknown equity
+//     (zero) falls back to the fiscal limit alone.
+//   - Available = RealCap - Exposure, floored at zero.
+//   - All amounts round to the cent (money.Cash).
+package budget
+
+import (
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// Metrics is one consistent view of the budget.
+type Metrics struct {
+	FiscalLimit decimal.Decimal // FISCAL_BUDGET_LIMIT
+	RealCap     decimal.Decimal // min(Equity, FiscalLimit)
+	Exposure    decimal.Decimal // Cost basis of ACTIVE positions
+	Available   decimal.Decimal // RealCap - Exposure, never negative
+}
+
+// Exposure sums the cost basis of ACTIVE positions.
+func Exposure(positions []models.Position) decimal.Decimal {
+	total := decimal.Zero
+	for _, p := range positions {
+		if p.Status == "ACTIVE" {
+			total = total.Add(p.Quantity.Mul(p.EntryPrice))
+		}
+	}
+	return money.Cash(total)
+}
+
+// BookExposure sums the cost basis of ACTIVE positions assigned to book.
+func BookExposure(positions []models.Position, book string) decimal.Decimal {
+	var in []models.Position
+	for _, p := range positions {
+		if p.Book == book {
+			in = append(in, p)
+		}
+	}
+	return Exposure(in)
+}
+
+// RealCap returns min(equity, fiscalLimit). A non-positive equity means unknown.
+func RealCap(fiscalLimit, equity decimal.Decimal) decimal.Decimal {
+	if equity.IsPositive() && equity.LessThan(fiscalLimit) {
+		return money.Cash(equity)
+	}
+	return money.Cash(fiscalLimit)
+}
+
+// Compute builds the metrics for positions under fiscalLimit and equity.
+func Compute(positions []models.Position, fiscalLimit, equity decimal.Decimal) Metrics {
+	m := Metrics{
+		FiscalLimit: money.Cash(fiscalLimit),
+		RealCap:     RealCap(fiscalLimit, equity),
+		Exposure:    Exposure(positions),
+	}
+	m.Available = decimal.Max(decimal.Zero, m.RealCap.Sub(m.Exposure))
+	return m
+}
+
+// Allows reports whether a new order costing cost fits in the available budget.
+func (m Metrics) Allows(cost decimal.Decimal) bool {
+	return !cost.GreaterThan(m.Available)
+}
+
+// Apply stores the metrics in the persisted state fields (Spec 65).
+func (m Metrics) Apply(state *models.PortfolioState) {
+	state.FiscalLimit = m.FiscalLimit
+	state.CurrentExposure = m.Exposure
+	state.AvailableBudget = m.Available
+}
@@ -0,0 +1,97 @@
+package budget
+
+import (
+	"testing"
+
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func d(s string) decimal.Decimal { return decimal.RequireFromString(s) }
+
+// pos is an ACTIVE position; hwm stands in for the market price it has reached.
+func pos(qty, entry, hwm string) models.Position {
+	return models.Position{Ticker: "T", Quantity: d(qty), EntryPrice: d(entry), HighWaterMark: d(hwm), Status: "ACTIVE"}
+}
+
+func TestExposure(t *testing.T) {
+	closed := pos("10", "50", "50")
+	closed.Status = "CLOSED"
+	cases := []struct {
+		name      string
+		positions []models.Position
+		want      string
+	}{
+		{"none", nil, "0"},
+		{"cost basis", []models.Position{pos("10", "100", "100")}, "1000"},
+		{"gain does not count", []models.Position{pos("10", "100", "180")}, "1000"},
+		{"loss does not free budget", []models.Position{pos("10", "100", "40")}, "1000"},
+		{"closed positions are ignored", []models.Position{pos("2", "25", "25"), closed}, "50"},
+		{"fractional, rounded to the cent", []models.Position{pos("0.333", "10.01", "10.01")}, "3.33"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Exposure(tc.positions); !got.Equal(d(tc.want)) {
+				t.Fatalf("Exposure = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	cases := []struct {
+		name          string
+		positions     []models.Position
+		limit, equity string
+		wantCap       string
+		wantAvailable string
+	}{
+		{"equity above the limit: capped by the limit", []models.Position{pos("10", "100", "100")}, "5000", "100000", "5000", "4000"},
+		{"ghost money: equity below the limit", []models.Position{pos("10", "100", "100")}, "5000", "3000", "3000", "2000"},
+		{"unknown equity falls back to the limit", []models.Position{pos("10", "100", "100")}, "5000", "0", "5000", "4000"},
+		{"negative equity is unknown too", nil, "5000", "-10", "5000", "5000"},
+		{"market value above cost does not shrink the budget", []models.Position{pos("10", "100", "300")}, "5000", "8000", "5000", "4000"},
+		{"exactly at the limit", []models.Position{pos("50", "100", "100")}, "5000", "10000", "5000", "0"},
+		{"over the limit is floored at zero, never negative", []models.Position{pos("60", "100", "100")}, "5000", "10000", "5000", "0"},
+		{"equity shrank under the exposure", []models.Position{pos("40", "100", "60")}, "5000", "2400", "2400", "0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := Compute(tc.positions, d(tc.limit), d(tc.equity))
+			if !m.RealCap.Equal(d(tc.wantCap)) {
+				t.Fatalf("RealCap = %s, want %s", m.RealCap, tc.wantCap)
+			}
+			if !m.Available.Equal(d(tc.wantAvailable)) {
+				t.Fatalf("Available = %s, want %s", m.Available, tc.wantAvailable)
+			}
+			if m.Available.IsNegative() {
+				t.Fatalf("Available = %s is negative", m.Available)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	over := Compute([]models.Position{pos("60", "100", "100")}, d("5000"), d("10000"))
+	m := Compute([]models.Position{pos("10", "100", "100")}, d("5000"), d("3000"))
+	cases := []struct {
+		name string
+		m    Metrics
+		cost string
+		want bool
+	}{
+		{"within the available budget", m, "1999.99", true},
+		{"exactly the available budget", m, "2000", true},
+		{"one cent over", m, "2000.01", false},
+		{"ghost money is refused", m, "3500", false},
+		{"over the limit refuses any buy", over, "0.01", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.m.Allows(d(tc.cost)); got != tc.want {
+				t.Fatalf("Allows(%s) = %v, want %v (available %s)", tc.cost, got, tc.want, tc.m.Available)
+			}
+		})
+	}
+}
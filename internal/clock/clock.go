@@ -0,0 +1,152 @@
+// Package clock abstracts the wall clock so time-driven logic (heartbeat windows, TTL
+// expiries, EOD transitions) can run against a fake clock instead of real sleeps.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the watcher.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func())
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on C() until stopped.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the system clock.
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (Real) AfterFunc(d time.Duration, f func())    { time.AfterFunc(d, f) }
+func (Real) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+// Fake is a manual clock: time only moves on Advance (or Sleep), which fires every
+// timer and ticker that has come due. AfterFunc callbacks run on the Advance caller's
+// goroutine, in due order, so a test sees their effects as soon as Advance returns.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	at     time.Time
+	every  time.Duration // Zero for one-shot timers
+	ch     chan time.Time
+	fn     func() // AfterFunc callback instead of a send on ch
+	closed bool
+}
+
+// NewFake returns a fake clock set to now.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Sleep advances the clock by d instead of blocking.
+func (f *Fake) Sleep(d time.Duration) {
+	f.Advance(d)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.add(d, 0).ch
+}
+
+func (f *Fake) AfterFunc(d time.Duration, fn func()) {
+	f.add(d, 0).fn = fn
+}
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive ticker interval")
+	}
+	return &fakeTicker{f: f, w: f.add(d, d)}
+}
+
+func (f *Fake) add(d, every time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{at: f.now.Add(d), every: every, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	return w
+}
+
+// Advance moves the clock forward by d. Like time.Ticker, a ticker that is not read
+// drops the ticks it misses.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.now = f.now.Add(d)
+	sort.SliceStable(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+	var due []func()
+	kept := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.closed {
+			continue
+		}
+		for !w.at.After(f.now) {
+			if w.fn != nil {
+				due = append(due, w.fn)
+			} else {
+				select {
+				case w.ch <- w.at:
+				default:
+				}
+			}
+			if w.every == 0 {
+				w.closed = true
+				break
+			}
+			w.at = w.at.Add(w.every)
+		}
+		if !w.closed {
+			kept = append(kept, w)
+		}
+	}
+	f.waiters = kept
+	f.mu.Unlock()
+
+	for _, fn := range due {
+		fn() // Unlocked: a callback may read the clock or arm a new timer
+	}
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.f.mu.Lock()
+	defer t.f.mu.Unlock()
+	t.w.closed = true
+	for i, w := range t.f.waiters {
+		if w == t.w {
+			t.f.waiters = append(t.f.waiters[:i], t.f.waiters[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultLeveragedKeywords flag leveraged/inverse products by asset name
+// (e.g., "Direxion Daily Semiconductor Bull 3X Shares", "ProShares UltraPro QQQ").
+var defaultLeveragedKeywords = []string{"2X", "3X", "ULTRAPRO", "ULTRA ", "LEVERAGED", "DAILY BULL", "DAILY BEAR"}
+
+// Rules is the declarative pre-trade rulebook (a JSON object, see compliance_rules.json).
+// Zero values disable a rule.
+type Rules struct {
+	MaxOrderValue     float64  `json:"max_order_value"`    // Max notional per buy order (USD)
+	BannedTickers     []string `json:"banned_tickers"`     // Never opened by any buy path
+	NoLeveragedETFs   bool     `json:"no_leveraged_etfs"`  // Reject leveraged/inverse products
+	LeveragedKeywords []string `json:"leveraged_keywords"` // Asset-name markers (defaults if empty)
+	OpenBlackoutMins  int      `json:"open_blackout_mins"` // No buys in the first N minutes after the open
+}
+
+// Order is the information a rule needs about a proposed buy.
+type Order struct {
+	Ticker    string
+	AssetName string
+	Value     decimal.Decimal // qty * price
+	SinceOpen time.Duration   // Time since the session open; negative if unknown or closed
+}
+
+// Violation is one rule hit.
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+// Load reads the rules file. A missing or invalid file yields empty rules
+// (everything allowed) so callers never need nil checks.
+func Load(path string) *Rules {
+	r := &Rules{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Warning: Compliance rules file %s not loaded: %v", path, err)
+		return r
+	}
+	if err := json.Unmarshal(b, r); err != nil {
+		log.Printf("Warning: Compliance rules file %s is invalid: %v", path, err)
+		return &Rules{}
+	}
+	for i, t := range r.BannedTickers {
+		r.BannedTickers[i] = strings.ToUpper(strings.TrimSpace(t))
+	}
+	log.Printf("Compliance rules loaded: MaxOrder=$%.2f, Banned=%d, NoLeveraged=%t, OpenBlackout=%dm",
+		r.MaxOrderValue, len(r.BannedTickers), r.NoLeveragedETFs, r.OpenBlackoutMins)
+	return r
+}
+
+// Evaluate returns every rule the order violates (empty means compliant).
+func (r *Rules) Evaluate(o Order) []Violation {
+	var out []Violation
+
+	if r.MaxOrderValue > 0 && o.Value.GreaterThan(decimal.NewFromFloat(r.MaxOrderValue)) {
+		out = append(out, Violation{"max_order_value",
+			fmt.Sprintf("order value $%s exceeds max $%.2f", o.Value.StringFixed(2), r.MaxOrderValue)})
+	}
+
+	for _, t := range r.BannedTickers {
+		if t == strings.ToUpper(o.Ticker) {
+			out = append(out, Violation{"banned_tickers", fmt.Sprintf("%s is on the banned list", o.Ticker)})
+			break
+		}
+	}
+
+	if r.NoLeveragedETFs {
+		keywords := r.LeveragedKeywords
+		if len(keywords) == 0 {
+			keywords = defaultLeveragedKeywords
+		}
+		name := strings.ToUpper(o.AssetName)
+		for _, k := range keywords {
+			if k != "" && strings.Contains(name, strings.ToUpper(k)) {
+				out = append(out, Violation{"no_leveraged_etfs",
+					fmt.Sprintf("%s (%s) looks like a leveraged/inverse product", o.Ticker, o.AssetName)})
+				break
+			}
+		}
+	}
+
+	if r.OpenBlackoutMins > 0 && o.SinceOpen >= 0 && o.SinceOpen < time.Duration(r.OpenBlackoutMins)*time.Minute {
+		out = append(out, Violation{"open_blackout_mins",
+			fmt.Sprintf("no trades in the first %d minutes after the open (%s elapsed)", r.OpenBlackoutMins, o.SinceOpen.Round(time.Second))})
+	}
+	return out
+}
+
+// Format renders violations as a rejection message.
+func Format(ticker string, v []Violation) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⛔ *Compliance Rejection: %s*", ticker))
+	for _, x := range v {
+		sb.WriteString(fmt.Sprintf("\n• [%s] %s", x.Rule, x.Reason))
+	}
+	return sb.String()
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"alpha_trading/internal/clock"
+
 	"github.com/joho/godotenv"
 )
 
@@ -22,7 +24,10 @@ type Config struct {
 	MaxLogSizeMB                int64    // Environment: WATCHER_MAX_LOG_SIZE_MB
 	MaxLogBackups               int      // Environment: WATCHER_MAX_LOG_BACKUPS
 	PollIntervalMins            int      // Environment: WATCHER_POLL_INTERVAL
+	HotPollIntervalSec          int      // Environment: HOT_POLL_INTERVAL_SEC
+	SlowPollEvery               int      // Environment: SLOW_POLL_EVERY
 	ConfirmationTTLSec          int      // Environment: CONFIRMATION_TTL_SEC
+	TradeChecklist              []string // Environment: TRADE_CHECKLIST
 	ConfirmationMaxDeviationPct float64  // Environment: CONFIRMATION_MAX_DEVIATION_PCT
 	DefaultTakeProfitPct        float64  // Environment: DEFAULT_TAKE_PROFIT_PCT
 	DefaultStopLossPct          float64  // Environment: DEFAULT_STOP_LOSS_PCT
@@ -32,6 +37,97 @@ type Config struct {
 	MaxStagnationHours          int      // Environment: MAX_STAGNATION_HOURS (Spec 66)
 	GeminiAPIKey                string   // Environment: GEMINI_API_KEY
 	WatchlistTickers            []string // Environment: WATCHLIST_TICKERS (Spec 72)
+	WatchlistAlertPct           float64  // Environment: WATCHLIST_ALERT_PCT
+	MaxDrawdownPct              float64  // Environment: MAX_DRAWDOWN_PCT
+	MaxDailyLossPct             float64  // Environment: MAX_DAILY_LOSS_PCT
+	KellyFraction               float64  // Environment: KELLY_FRACTION
+	SectorCaps                  []string // Environment: SECTOR_CAPS
+	SectorCapMode               string   // Environment: SECTOR_CAP_MODE
+	CorrelationThreshold        float64  // Environment: CORRELATION_THRESHOLD
+	PDTGuard                    string   // Environment: PDT_GUARD
+	RiskPerTradePct             float64  // Environment: RISK_PER_TRADE_PCT
+	SizeATRMultiple             float64  // Environment: SIZE_ATR_MULTIPLE
+	HedgeETF                    string   // Environment: HEDGE_ETF
+	HedgeETFExpensePct          float64  // Environment: HEDGE_ETF_EXPENSE_PCT
+	HedgeBorrowPct              float64  // Environment: HEDGE_BORROW_PCT
+	SweepETF                    string   // Environment: SWEEP_ETF
+	SweepCashThreshold          float64  // Environment: SWEEP_CASH_THRESHOLD
+	SweepIdleDays               int      // Environment: SWEEP_IDLE_DAYS
+	StopReviewEnabled           bool     // Environment: STOP_REVIEW_ENABLED
+	StopReviewBufferPct         float64  // Environment: STOP_REVIEW_BUFFER_PCT
+	AssetMetadataFile           string   // Environment: ASSET_METADATA_FILE
+	SymbolAliases               []string // Environment: SYMBOL_ALIASES
+	SettlementWaitSec           int      // Environment: SETTLEMENT_WAIT_SEC
+	TriggerPrecedence           []string // Environment: TRIGGER_PRECEDENCE
+	TriggerHysteresisBps        float64  // Environment: TRIGGER_HYSTERESIS_BPS
+	TriggerConfirmChecks        int      // Environment: TRIGGER_CONFIRM_CHECKS
+	TriggerPriceSource          string   // Environment: TRIGGER_PRICE_SOURCE
+	GapAlertPct                 float64  // Environment: GAP_ALERT_PCT
+	AlpacaWatchlistName         string   // Environment: ALPACA_WATCHLIST_NAME
+	BrokerProtectionEnabled     bool     // Environment: BROKER_PROTECTION_ENABLED
+	AIDailyCallLimit            int      // Environment: AI_DAILY_CALL_LIMIT
+	ComplianceRulesFile         string   // Environment: COMPLIANCE_RULES_FILE
+	VacationPolicy              []string // Environment: VACATION_POLICY
+	StopReAlertMins             int      // Environment: STOP_REALERT_MINS
+	StopReAlertMax              int      // Environment: STOP_REALERT_MAX
+	AICacheTolerancePct         float64  // Environment: AI_CACHE_TOLERANCE_PCT
+	AICacheMaxAgeMins           int      // Environment: AI_CACHE_MAX_AGE_MINS
+	MarketProvider              string   // Environment: MARKET_PROVIDER
+	AIStreamReports             bool     // Environment: AI_STREAM_REPORTS
+	OptimizeSLRange             string   // Environment: OPTIMIZE_SL_RANGE
+	OptimizeTPRange             string   // Environment: OPTIMIZE_TP_RANGE
+	OptimizeTSRange             string   // Environment: OPTIMIZE_TS_RANGE
+	DataFallbackProvider        string   // Environment: DATA_FALLBACK_PROVIDER
+	FXProvider                  string   // Environment: FX_PROVIDER
+	SpreadTrackingEnabled       bool     // Environment: SPREAD_TRACKING_ENABLED
+	SpreadLookbackDays          int      // Environment: SPREAD_LOOKBACK_DAYS
+	SpreadStopRatioPct          float64  // Environment: SPREAD_STOP_RATIO_PCT
+	AlpacaPaperKeyID            string   // Environment: APCA_PAPER_KEY_ID
+	AlpacaPaperSecret           string   // Environment: APCA_PAPER_SECRET_KEY
+	AlpacaLiveKeyID             string   // Environment: APCA_LIVE_KEY_ID
+	AlpacaLiveSecret            string   // Environment: APCA_LIVE_SECRET_KEY
+	StateSaveDebounceMs         int      // Environment: STATE_SAVE_DEBOUNCE_MS
+	RateLimitDataPerMin         int      // Environment: RATE_LIMIT_DATA_PER_MIN
+	RateLimitTradingPerMin      int      // Environment: RATE_LIMIT_TRADING_PER_MIN
+	HWMStrictMode               bool     // Environment: HWM_STRICT_MODE
+	PriceCacheTTLSec            int      // Environment: PRICE_CACHE_TTL_SEC
+	RetryMaxAttempts            int      // Environment: RETRY_MAX_ATTEMPTS
+	RetryBaseDelayMs            int      // Environment: RETRY_BASE_DELAY_MS
+	MarketCallTimeoutSec        int      // Environment: MARKET_CALL_TIMEOUT_SEC
+	MetricsEnabled              bool     // Environment: METRICS_ENABLED
+	MetricsSlowCallMs           int      // Environment: METRICS_SLOW_CALL_MS
+	ExtHoursLimitPct            float64  // Environment: EXT_HOURS_LIMIT_PCT
+	WatchlistPriceMaxAgeSec     int      // Environment: WATCHLIST_PRICE_MAX_AGE_SEC
+	BrokerTrailingStop          bool     // Environment: BROKER_TRAILING_STOP
+	StagnationATRMultiple       float64  // Environment: STAGNATION_ATR_MULTIPLE
+	StagnationRules             []string // Environment: STAGNATION_RULES
+	DivergenceMatchMins         int      // Environment: DIVERGENCE_MATCH_MINS
+	DivergenceReportEnabled     bool     // Environment: DIVERGENCE_REPORT_ENABLED
+	ConfirmAutoMax              string   // Environment: CONFIRM_AUTO_MAX
+	ConfirmPINMin               string   // Environment: CONFIRM_PIN_MIN
+	ConfirmPIN                  string   // Environment: CONFIRM_PIN
+	OptionsEnabled              bool     // Environment: OPTIONS_ENABLED
+	OptionsExpiryAlertDays      int      // Environment: OPTIONS_EXPIRY_ALERT_DAYS
+	CorporateActionsEnabled     bool     // Environment: CORPORATE_ACTIONS_ENABLED
+	SyncIntervalMins            int      // Environment: SYNC_INTERVAL_MINS
+	DriftQtyTolerancePct        float64  // Environment: DRIFT_QTY_TOLERANCE_PCT
+	AIIntradayTimeframe         string   // Environment: AI_INTRADAY_TIMEFRAME
+	AIIntradayBars              int      // Environment: AI_INTRADAY_BARS
+	TradeStreamEnabled          bool     // Environment: TRADE_STREAM_ENABLED
+	AINewsHeadlines             int      // Environment: AI_NEWS_HEADLINES
+	RiskFreeRatePct             float64  // Environment: RISK_FREE_RATE_PCT
+	FundamentalsProvider        string   // Environment: FUNDAMENTALS_PROVIDER
+	FundamentalsCacheHours      int      // Environment: FUNDAMENTALS_CACHE_HOURS
+	AIFundamentalsEnabled       bool     // Environment: AI_FUNDAMENTALS_ENABLED
+	ShardName                   string   // Environment: SHARD_NAME
+	ShardPeers                  []string // Environment: SHARD_PEERS
+	ShardListenAddr             string   // Environment: SHARD_LISTEN_ADDR
+	ShardSecret                 string   // Environment: SHARD_SECRET
+	CryptoCarryRates            []string // Environment: CRYPTO_CARRY_RATES
+
+	// Clock is the watcher's time source: the system clock, or a fake one in tests so
+	// heartbeat windows, TTLs and EOD transitions can be fast-forwarded.
+	Clock clock.Clock
 }
 
 // Load initializes the configuration.
@@ -44,12 +140,45 @@ func Load() *Config {
 
 	// 1. Validation: Fatal check for required secrets
 	requiredSecretVars := map[string]bool{
-		"APCA_API_KEY_ID":     true,
-		"APCA_API_SECRET_KEY": true,
-		"APCA_API_BASE_URL":   true,
-		"TELEGRAM_BOT_TOKEN":  true,
-		"TELEGRAM_CHAT_ID":    true,
-		"GEMINI_API_KEY":      true,
+		"TELEGRAM_BOT_TOKEN": true,
+		"TELEGRAM_CHAT_ID":   true,
+		"GEMINI_API_KEY":     true,
+	}
+	// Broker credentials depend on the selected provider
+	if strings.EqualFold(os.Getenv("MARKET_PROVIDER"), "kraken") {
+		requiredSecretVars["KRAKEN_API_KEY"] = true
+		requiredSecretVars["KRAKEN_API_SECRET"] = true
+	} else {
+		requiredSecretVars["APCA_API_KEY_ID"] = true
+		requiredSecretVars["APCA_API_SECRET_KEY"] = true
+		requiredSecretVars["APCA_API_BASE_URL"] = true
+	}
+	switch strings.ToLower(os.Getenv("DATA_FALLBACK_PROVIDER")) {
+	case "polygon":
+		requiredSecretVars["POLYGON_API_KEY"] = true
+	case "finnhub":
+		requiredSecretVars["FINNHUB_API_KEY"] = true
+	}
+	if strings.EqualFold(os.Getenv("FX_PROVIDER"), "polygon") {
+		requiredSecretVars["POLYGON_API_KEY"] = true
+	}
+	if strings.EqualFold(os.Getenv("FUNDAMENTALS_PROVIDER"), "finnhub") {
+		requiredSecretVars["FINNHUB_API_KEY"] = true
+	}
+	// Relayed commands can trade: cooperating instances must authenticate each other
+	if os.Getenv("SHARD_PEERS") != "" || os.Getenv("SHARD_LISTEN_ADDR") != "" {
+		requiredSecretVars["SHARD_SECRET"] = true
+	}
+
+	// Secrets that are only needed by optional features (masked in the log below)
+	optionalSecretVars := map[string]bool{
+		"APCA_PAPER_KEY_ID":     true,
+		"APCA_PAPER_SECRET_KEY": true,
+		"APCA_LIVE_KEY_ID":      true,
+		"APCA_LIVE_SECRET_KEY":  true,
+		"POLYGON_API_KEY":       true,
+		"FINNHUB_API_KEY":       true,
+		"SHARD_SECRET":          true,
 	}
 
 	var missing []string
@@ -62,13 +191,17 @@ func Load() *Config {
 	if len(missing) > 0 {
 		log.Fatalf("CRITICAL: Missing required environment variables: %v", missing)
 	}
+	// A secondary's buttons are routed back to it by name
+	if os.Getenv("SHARD_LISTEN_ADDR") != "" && os.Getenv("SHARD_NAME") == "" {
+		log.Fatalf("CRITICAL: SHARD_LISTEN_ADDR requires SHARD_NAME")
+	}
 
 	// 2. Print variables explicitly defined in the local .env file (for debugging)
 	envMap, err := godotenv.Read()
 	if err == nil {
 		log.Println("--- .env File Variables ---")
 		for key, val := range envMap {
-			if requiredSecretVars[key] {
+			if requiredSecretVars[key] || optionalSecretVars[key] {
 				// Mask secret values (last 4 chars visible)
 				masked := "***"
 				if len(val) > 4 {
@@ -90,11 +223,15 @@ func Load() *Config {
 	}
 
 	cfg := &Config{
+		Clock:                       clock.Real{},
 		LogLevel:                    getEnv("WATCHER_LOG_LEVEL", "INFO"),
 		MaxLogSizeMB:                getEnvAsInt64("WATCHER_MAX_LOG_SIZE_MB", 5),
 		MaxLogBackups:               getEnvAsInt("WATCHER_MAX_LOG_BACKUPS", 3),
 		PollIntervalMins:            getEnvAsInt("WATCHER_POLL_INTERVAL", 60),
+		HotPollIntervalSec:          getEnvAsInt("HOT_POLL_INTERVAL_SEC", 60),                 // Extra checks of /priority hot positions; 0 = off
+		SlowPollEvery:               getEnvAsInt("SLOW_POLL_EVERY", 4),                        // /priority slow positions are checked every Nth poll
 		ConfirmationTTLSec:          getEnvAsInt("CONFIRMATION_TTL_SEC", 300),                 // Default 5 mins
+		TradeChecklist:              getEnvAsSlice("TRADE_CHECKLIST", []string{}),             // Questions asked before a /buy proposal
 		ConfirmationMaxDeviationPct: getEnvAsFloat64("CONFIRMATION_MAX_DEVIATION_PCT", 0.005), // Default 0.5%
 		DefaultTakeProfitPct:        getEnvAsFloat64("DEFAULT_TAKE_PROFIT_PCT", 15.0),         // Default 15.0%
 		DefaultStopLossPct:          getEnvAsFloat64("DEFAULT_STOP_LOSS_PCT", 5.0),            // Default 5.0%
@@ -103,7 +240,94 @@ func Load() *Config {
 		FiscalBudgetLimit:           fiscalLimit,
 		MaxStagnationHours:          getEnvAsInt("MAX_STAGNATION_HOURS", 120), // Default 120 (5 days)
 		GeminiAPIKey:                os.Getenv("GEMINI_API_KEY"),
-		WatchlistTickers:            getEnvAsSlice("WATCHLIST_TICKERS", []string{}), // Default empty
+		WatchlistTickers:            getEnvAsSlice("WATCHLIST_TICKERS", []string{}),                                       // Default empty
+		WatchlistAlertPct:           getEnvAsFloat64("WATCHLIST_ALERT_PCT", 5.0),                                          // Default 5.0%
+		MaxDrawdownPct:              getEnvAsFloat64("MAX_DRAWDOWN_PCT", 10.0),                                            // Kill switch level from peak equity; 0 = off
+		MaxDailyLossPct:             getEnvAsFloat64("MAX_DAILY_LOSS_PCT", 0),                                             // Day P/L (%) that trips the buy circuit breaker; 0 = off
+		KellyFraction:               getEnvAsFloat64("KELLY_FRACTION", 0.25),                                              // Default quarter-Kelly
+		SectorCaps:                  getEnvAsSlice("SECTOR_CAPS", []string{}),                                             // e.g. "Technology=30%,energy=2000,*=40%" (% of equity)
+		SectorCapMode:               strings.ToLower(getEnv("SECTOR_CAP_MODE", "block")),                                  // block | warn
+		CorrelationThreshold:        getEnvAsFloat64("CORRELATION_THRESHOLD", 0.8),                                        // Warn on buys this correlated with a holding; 0 = off
+		PDTGuard:                    strings.ToLower(getEnv("PDT_GUARD", "confirm")),                                      // confirm | block | off
+		RiskPerTradePct:             getEnvAsFloat64("RISK_PER_TRADE_PCT", 1.0),                                           // /size: capital lost if the stop is hit
+		SizeATRMultiple:             getEnvAsFloat64("SIZE_ATR_MULTIPLE", 2.0),                                            // /size: volatility stop distance in daily ATRs; 0 = off
+		HedgeETF:                    strings.ToUpper(getEnv("HEDGE_ETF", "SH")),                                           // Inverse S&P 500 ETF proposed by /hedge buy
+		HedgeETFExpensePct:          getEnvAsFloat64("HEDGE_ETF_EXPENSE_PCT", 0.89),                                       // Annual expense ratio of HEDGE_ETF (cost estimate)
+		HedgeBorrowPct:              getEnvAsFloat64("HEDGE_BORROW_PCT", 0.5),                                             // Annual borrow fee assumed for shorting SPY (cost estimate)
+		SweepETF:                    strings.ToUpper(getEnv("SWEEP_ETF", "")),                                             // Cash-equivalent ETF for idle cash (e.g., SGOV); empty = off
+		SweepCashThreshold:          getEnvAsFloat64("SWEEP_CASH_THRESHOLD", 1000),                                        // Cash kept uninvested; only the excess is swept
+		SweepIdleDays:               getEnvAsInt("SWEEP_IDLE_DAYS", 3),                                                    // Days cash must stay above the threshold before a sweep is proposed
+		StopReviewEnabled:           getEnvAsBool("STOP_REVIEW_ENABLED", true),                                            // Weekly stop review after the week's last session
+		StopReviewBufferPct:         getEnvAsFloat64("STOP_REVIEW_BUFFER_PCT", 1.0),                                       // Proposed stops sit this % under the weekly low
+		AssetMetadataFile:           getEnv("ASSET_METADATA_FILE", "asset_metadata.json"),                                 // Bundled reference file
+		SymbolAliases:               getEnvAsSlice("SYMBOL_ALIASES", []string{}),                                          // e.g. "GOOGLE=GOOGL,XBT=BTC/USD"
+		SettlementWaitSec:           getEnvAsInt("SETTLEMENT_WAIT_SEC", 10),                                               // Max wait for sale proceeds to settle before resizing a dependent buy
+		TriggerPrecedence:           getEnvAsSlice("TRIGGER_PRECEDENCE", []string{"TP", "SL", "TS"}),                      // Spec 36 default order
+		TriggerHysteresisBps:        getEnvAsFloat64("TRIGGER_HYSTERESIS_BPS", 0),                                         // 0 = trigger on first touch
+		TriggerConfirmChecks:        getEnvAsInt("TRIGGER_CONFIRM_CHECKS", 1),                                             // Consecutive breaching polls required
+		TriggerPriceSource:          strings.ToLower(getEnv("TRIGGER_PRICE_SOURCE", "last")),                              // last | mid | bid
+		GapAlertPct:                 getEnvAsFloat64("GAP_ALERT_PCT", 3.0),                                                // Opening gap (%) vs previous close that alerts on held positions
+		AlpacaWatchlistName:         getEnv("ALPACA_WATCHLIST_NAME", "alpha_watcher"),                                     // Server-side watchlist mirrored by /watch sync
+		BrokerProtectionEnabled:     getEnvAsBool("BROKER_PROTECTION_ENABLED", false),                                     // Expect a broker-side protective sell order per position
+		AIDailyCallLimit:            getEnvAsInt("AI_DAILY_CALL_LIMIT", 0),                                                // 0 = unlimited
+		ComplianceRulesFile:         getEnv("COMPLIANCE_RULES_FILE", "compliance_rules.json"),                             // Declarative pre-trade rules
+		VacationPolicy:              getEnvAsSlice("VACATION_POLICY", []string{"SL=execute", "TS=execute", "TP=dismiss"}), // Applied to unanswered trigger alerts in vacation mode
+		StopReAlertMins:             getEnvAsInt("STOP_REALERT_MINS", 5),                                                  // 0 disables SL/TS re-alerts
+		StopReAlertMax:              getEnvAsInt("STOP_REALERT_MAX", 3),                                                   // Reminders per unanswered stop alert
+		AICacheTolerancePct:         getEnvAsFloat64("AI_CACHE_TOLERANCE_PCT", 1.0),                                       // Max price move (%) to reuse the last analysis. 0 = no caching
+		AICacheMaxAgeMins:           getEnvAsInt("AI_CACHE_MAX_AGE_MINS", 240),                                            // Cached analysis expires after this
+		MarketProvider:              strings.ToLower(getEnv("MARKET_PROVIDER", "alpaca")),                                 // alpaca | kraken
+		AIStreamReports:             getEnvAsBool("AI_STREAM_REPORTS", true),                                              // Progressive Telegram edits for /analyze
+		OptimizeSLRange:             getEnv("OPTIMIZE_SL_RANGE", "2:10:1"),                                                // /optimize sweep, from:to:step (%)
+		OptimizeTPRange:             getEnv("OPTIMIZE_TP_RANGE", "5:30:5"),                                                // Take-profit sweep
+		OptimizeTSRange:             getEnv("OPTIMIZE_TS_RANGE", "0:8:2"),                                                 // 0 = no trailing stop
+		DataFallbackProvider:        strings.ToLower(getEnv("DATA_FALLBACK_PROVIDER", "")),                                // polygon | finnhub | empty = off
+		FXProvider:                  strings.ToLower(getEnv("FX_PROVIDER", "")),                                           // frankfurter | polygon | empty = no forex pairs
+		SpreadTrackingEnabled:       getEnvAsBool("SPREAD_TRACKING_ENABLED", true),                                        // Sample held/watched bid-ask spreads every poll (spread_log.jsonl)
+		SpreadLookbackDays:          getEnvAsInt("SPREAD_LOOKBACK_DAYS", 14),                                              // Window for the typical (median) spread
+		SpreadStopRatioPct:          getEnvAsFloat64("SPREAD_STOP_RATIO_PCT", 20),                                         // Warn when the typical spread reaches this % of the stop distance; 0 = off
+		AlpacaPaperKeyID:            os.Getenv("APCA_PAPER_KEY_ID"),                                                       // /env paper account
+		AlpacaPaperSecret:           os.Getenv("APCA_PAPER_SECRET_KEY"),                                                   // Paired with APCA_PAPER_KEY_ID
+		AlpacaLiveKeyID:             os.Getenv("APCA_LIVE_KEY_ID"),                                                        // /env live account
+		AlpacaLiveSecret:            os.Getenv("APCA_LIVE_SECRET_KEY"),                                                    // Paired with APCA_LIVE_KEY_ID
+		StateSaveDebounceMs:         getEnvAsInt("STATE_SAVE_DEBOUNCE_MS", 500),                                           // Coalesce state saves within this window
+		RateLimitDataPerMin:         getEnvAsInt("RATE_LIMIT_DATA_PER_MIN", 200),                                          // Prices/quotes/bars; 0 = unlimited
+		RateLimitTradingPerMin:      getEnvAsInt("RATE_LIMIT_TRADING_PER_MIN", 200),                                       // Orders/account/clock; 0 = unlimited
+		HWMStrictMode:               getEnvAsBool("HWM_STRICT_MODE", false),                                               // Refuse to persist a regressed HWM
+		PriceCacheTTLSec:            getEnvAsInt("PRICE_CACHE_TTL_SEC", 5),                                                // Shared GetPrice/GetQuote cache; 0 = off
+		RetryMaxAttempts:            getEnvAsInt("RETRY_MAX_ATTEMPTS", 3),                                                 // Transient provider errors; 1 = no retry
+		RetryBaseDelayMs:            getEnvAsInt("RETRY_BASE_DELAY_MS", 300),                                              // Doubles per attempt (max 5s), with jitter
+		MarketCallTimeoutSec:        getEnvAsInt("MARKET_CALL_TIMEOUT_SEC", 20),                                           // Per broker call, so a hung request cannot stall the poll loop
+		MetricsEnabled:              getEnvAsBool("METRICS_ENABLED", true),                                                // Per-method call counts, latencies and errors (/stats)
+		MetricsSlowCallMs:           getEnvAsInt("METRICS_SLOW_CALL_MS", 3000),                                            // Log provider calls at least this slow as [SLOW_CALL]; 0 = never
+		ExtHoursLimitPct:            getEnvAsFloat64("EXT_HOURS_LIMIT_PCT", 0.5),                                          // Limit buffer over ask/under bid for extended-hours orders (%)
+		WatchlistPriceMaxAgeSec:     getEnvAsInt("WATCHLIST_PRICE_MAX_AGE_SEC", 60),                                       // Reuse last-known watchlist prices up to this age instead of calling REST
+		BrokerTrailingStop:          getEnvAsBool("BROKER_TRAILING_STOP", false),                                          // Broker exit is a native trailing stop (instead of the OCO pair) when the position trails
+		StagnationATRMultiple:       getEnvAsFloat64("STAGNATION_ATR_MULTIPLE", 0.5),                                      // Flat = |price - entry| below this many daily ATRs (Spec 66)
+		StagnationRules:             getEnvAsSlice("STAGNATION_RULES", []string{}),                                        // Per-tag overrides, e.g. "lowvol=0.3:240,crypto=0.8:48" (ATR multiple:hours)
+		DivergenceMatchMins:         getEnvAsInt("DIVERGENCE_MATCH_MINS", 30),                                             // Paper/live fills this close count as the same signal
+		DivergenceReportEnabled:     getEnvAsBool("DIVERGENCE_REPORT_ENABLED", false),                                     // Weekly paper vs live comparison (needs both key pairs)
+		ConfirmAutoMax:              getEnv("CONFIRM_AUTO_MAX", ""),                                                       // "250" ($) or "1%" (of equity): orders up to this skip the button
+		ConfirmPINMin:               getEnv("CONFIRM_PIN_MIN", ""),                                                        // "5000" ($) or "10%" (of equity): orders from this need button + /pin
+		ConfirmPIN:                  os.Getenv("CONFIRM_PIN"),                                                             // Secret for /pin (required by CONFIRM_PIN_MIN)
+		OptionsEnabled:              getEnvAsBool("OPTIONS_ENABLED", false),                                               // Monitor Alpaca option positions (/status, EOD, expiry alerts)
+		OptionsExpiryAlertDays:      getEnvAsInt("OPTIONS_EXPIRY_ALERT_DAYS", 3),                                          // Daily warning this many days before an option expires
+		CorporateActionsEnabled:     getEnvAsBool("CORPORATE_ACTIONS_ENABLED", true),                                      // Daily split/dividend check for held tickers
+		SyncIntervalMins:            getEnvAsInt("SYNC_INTERVAL_MINS", 60),                                                // Scheduled broker reconciliation; 0 disables
+		DriftQtyTolerancePct:        getEnvAsFloat64("DRIFT_QTY_TOLERANCE_PCT", 0),                                        // Quantity differences up to this % are corrected silently
+		AIIntradayTimeframe:         getEnv("AI_INTRADAY_TIMEFRAME", "15Min"),                                             // Intraday bar size in the AI snapshot (1Min, 5Min, 15Min, 1H)
+		AIIntradayBars:              getEnvAsInt("AI_INTRADAY_BARS", 16),                                                  // Recent intraday bars per ticker in the AI snapshot. 0 = none
+		TradeStreamEnabled:          getEnvAsBool("TRADE_STREAM_ENABLED", true),                                           // Alpaca trade-updates stream for order confirmation
+		AINewsHeadlines:             getEnvAsInt("AI_NEWS_HEADLINES", 3),                                                  // Recent headlines per held/focus ticker in the AI snapshot. 0 = none
+		RiskFreeRatePct:             getEnvAsFloat64("RISK_FREE_RATE_PCT", 4.0),                                           // Annual yield of "just holding cash" (reports benchmark against it)
+		FundamentalsProvider:        strings.ToLower(getEnv("FUNDAMENTALS_PROVIDER", "")),                                 // finnhub | empty = bar-derived fields only
+		FundamentalsCacheHours:      getEnvAsInt("FUNDAMENTALS_CACHE_HOURS", 12),                                          // Market cap / P/E barely move intraday
+		AIFundamentalsEnabled:       getEnvAsBool("AI_FUNDAMENTALS_ENABLED", true),                                        // Fundamentals per held/focus ticker in the AI snapshot
+		ShardName:                   strings.ToLower(getEnv("SHARD_NAME", "")),                                            // Routing prefix of this instance (/crypto status); empty = single instance
+		ShardPeers:                  getEnvAsSlice("SHARD_PEERS", []string{}),                                             // Primary only: name=url of each secondary instance
+		ShardListenAddr:             getEnv("SHARD_LISTEN_ADDR", ""),                                                      // Secondary only: relay address (e.g., 127.0.0.1:8091); disables its Telegram listener
+		ShardSecret:                 os.Getenv("SHARD_SECRET"),                                                            // Shared by all instances; authenticates relayed commands
+		CryptoCarryRates:            getEnvAsSlice("CRYPTO_CARRY_RATES", []string{}),                                      // Annual %, e.g. "ETH/USD=3.5,BTC/USD=-8" (+ staking yield, - funding/borrow cost)
 	}
 
 	log.Printf("Configuration Loaded: LogLevel=%s, MaxSize=%dMB, Backups=%d, PollInterval=%dm",
@@ -5,9 +5,45 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 	"sync"
+	"time"
 )
 
+// errorMarkers identify log lines worth surfacing as "last error" (e.g., in the heartbeat).
+var errorMarkers = []string{"ERROR", "FATAL", "CRITICAL", "Error", "Failed"}
+
+// errorTracker remembers the most recent error-looking log line.
+type errorTracker struct {
+	mu   sync.Mutex
+	line string
+	at   time.Time
+}
+
+var lastErr = &errorTracker{}
+
+func (t *errorTracker) Write(p []byte) (int, error) {
+	line := string(p)
+	for _, m := range errorMarkers {
+		if strings.Contains(line, m) {
+			t.mu.Lock()
+			t.line = strings.TrimSpace(line)
+			t.at = time.Now()
+			t.mu.Unlock()
+			break
+		}
+	}
+	return len(p), nil
+}
+
+// LastError returns the most recent error log line and when it was written.
+// The line is empty if no error has been logged since startup.
+func LastError() (string, time.Time) {
+	lastErr.mu.Lock()
+	defer lastErr.mu.Unlock()
+	return lastErr.line, lastErr.at
+}
+
 // Rotator implements io.Writer and handles log file rotation based on size.
 type Rotator struct {
 	Filename   string
@@ -27,12 +63,13 @@ func Setup(filename string, maxSizeMB int64, maxBackups int) {
 	}
 
 	if err := rotator.openExistingOrNew(); err != nil {
+		log.SetOutput(io.MultiWriter(os.Stdout, lastErr))
 		log.Printf("Failed to open log file, using stdout only: %v", err)
 		return
 	}
 
-	// MultiWriter writes to both stdout and the rotator
-	mw := io.MultiWriter(os.Stdout, rotator)
+	// MultiWriter writes to both stdout and the rotator (plus the last-error tracker)
+	mw := io.MultiWriter(os.Stdout, rotator, lastErr)
 	log.SetOutput(mw)
 	// Remove default flags as we rely on our own formatting or system logs usually,
 	// but user asked for robust logging with timestamps.
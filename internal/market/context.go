@@ -0,0 +1,60 @@
+package market
+
+import (
+	"context"
+	"time"
+)
+
+// callTimeout bounds a single broker call whose context carries no deadline of its own
+// (MARKET_CALL_TIMEOUT_SEC), so a hung request cannot stall the poll loop.
+var callTimeout = 20 * time.Second
+
+// SetCallTimeout sets the per-call timeout. d <= 0 keeps the default.
+func SetCallTimeout(d time.Duration) {
+	if d > 0 {
+		callTimeout = d
+	}
+}
+
+// callContext applies the per-call timeout unless ctx already has a deadline.
+func callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, callTimeout)
+}
+
+// await runs f, an SDK call that takes no context, and returns its result, or ctx's error
+// as soon as ctx is done or the per-call timeout passes. An abandoned call finishes in the
+// background within the SDK's own HTTP timeout; its result is discarded.
+func await[T any](ctx context.Context, f func() (T, error)) (T, error) {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1) // Buffered: an abandoned call must not block forever
+	go func() {
+		v, err := f()
+		done <- result{v, err}
+	}()
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// awaitErr is await for calls that only return an error.
+func awaitErr(ctx context.Context, f func() error) error {
+	_, err := await(ctx, func() (struct{}, error) { return struct{}{}, f() })
+	return err
+}
@@ -0,0 +1,68 @@
+package market
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// Corporate action types returned by GetCorporateActions.
+const (
+	ActionSplit    = "split"
+	ActionDividend = "dividend"
+)
+
+// CorporateAction is a split or cash dividend affecting a symbol.
+type CorporateAction struct {
+	Symbol  string
+	Type    string          // ActionSplit or ActionDividend
+	Ratio   decimal.Decimal // Split: new shares per old share (2 for 2:1, 0.1 for 1:10)
+	Rate    decimal.Decimal // Dividend: cash per share
+	ExDate  time.Time       // First session trading on the new basis (midnight UTC)
+	PayDate time.Time       // Dividend payable date (zero if unknown)
+}
+
+// GetCorporateActions returns forward/reverse splits and cash dividends for symbols with
+// an ex-date in [start, end].
+func (a *AlpacaProvider) GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) ([]CorporateAction, error) {
+	return await(ctx, func() ([]CorporateAction, error) {
+		cas, err := a.md().GetCorporateActions(marketdata.GetCorporateActionsRequest{
+			Symbols: symbols,
+			Types:   []string{"forward_split", "reverse_split", "cash_dividend"},
+			Start:   civil.DateOf(start),
+			End:     civil.DateOf(end),
+		})
+		if err != nil {
+			return nil, err
+		}
+		var out []CorporateAction
+		split := func(symbol string, newRate, oldRate float64, ex civil.Date) {
+			if newRate <= 0 || oldRate <= 0 {
+				return
+			}
+			out = append(out, CorporateAction{
+				Symbol: symbol,
+				Type:   ActionSplit,
+				Ratio:  decimal.NewFromFloat(newRate).Div(decimal.NewFromFloat(oldRate)),
+				ExDate: ex.In(time.UTC),
+			})
+		}
+		for _, s := range cas.ForwardSplits {
+			split(s.Symbol, s.NewRate, s.OldRate, s.ExDate)
+		}
+		for _, s := range cas.ReverseSplits {
+			split(s.Symbol, s.NewRate, s.OldRate, s.ExDate)
+		}
+		for _, d := range cas.CashDividends {
+			ca := CorporateAction{Symbol: d.Symbol, Type: ActionDividend, Rate: decimal.NewFromFloat(d.Rate), ExDate: d.ExDate.In(time.UTC)}
+			if d.PayableDate != nil {
+				ca.PayDate = d.PayableDate.In(time.UTC)
+			}
+			out = append(out, ca)
+		}
+		return out, nil
+	})
+}
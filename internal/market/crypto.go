@@ -0,0 +1,90 @@
+package market
+
+import (
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// Alpaca serves crypto (BTC/USD, ETH/USD) from separate market data endpoints with
+// fractional sizes. These helpers fetch from them and convert to the equity types the
+// rest of the bot works with, so callers stay asset-class agnostic.
+
+func (a *AlpacaProvider) cryptoPrice(ticker string) (float64, error) {
+	trade, err := a.md().GetLatestCryptoTrade(ticker, marketdata.GetLatestCryptoTradeRequest{})
+	if err != nil || trade == nil {
+		return 0, err
+	}
+	return trade.Price, nil
+}
+
+func (a *AlpacaProvider) cryptoQuote(ticker string) (*marketdata.Quote, error) {
+	q, err := a.md().GetLatestCryptoQuote(ticker, marketdata.GetLatestCryptoQuoteRequest{})
+	if err != nil || q == nil {
+		return nil, err
+	}
+	return fromCryptoQuote(q), nil
+}
+
+func (a *AlpacaProvider) cryptoSnapshot(ticker string) (*marketdata.Snapshot, error) {
+	s, err := a.md().GetCryptoSnapshot(ticker, marketdata.GetCryptoSnapshotRequest{})
+	if err != nil || s == nil {
+		return nil, err
+	}
+	return fromCryptoSnapshot(s), nil
+}
+
+func (a *AlpacaProvider) cryptoSnapshots(tickers []string) (map[string]*marketdata.Snapshot, error) {
+	snaps, err := a.md().GetCryptoSnapshots(tickers, marketdata.GetCryptoSnapshotRequest{})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]*marketdata.Snapshot, len(snaps))
+	for t, s := range snaps {
+		out[t] = fromCryptoSnapshot(&s)
+	}
+	return out, nil
+}
+
+func fromCryptoSnapshot(s *marketdata.CryptoSnapshot) *marketdata.Snapshot {
+	out := &marketdata.Snapshot{
+		MinuteBar:    fromCryptoBar(s.MinuteBar),
+		DailyBar:     fromCryptoBar(s.DailyBar),
+		PrevDailyBar: fromCryptoBar(s.PrevDailyBar),
+	}
+	if s.LatestTrade != nil {
+		out.LatestTrade = &marketdata.Trade{Timestamp: s.LatestTrade.Timestamp, Price: s.LatestTrade.Price, ID: s.LatestTrade.ID}
+	}
+	if s.LatestQuote != nil {
+		out.LatestQuote = fromCryptoQuote(s.LatestQuote)
+	}
+	return out
+}
+
+func (a *AlpacaProvider) cryptoBars(ticker string, tf marketdata.TimeFrame, start, end time.Time) ([]marketdata.Bar, error) {
+	bars, err := a.md().GetCryptoBars(ticker, marketdata.GetCryptoBarsRequest{TimeFrame: tf, Start: start, End: end})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]marketdata.Bar, 0, len(bars))
+	for i := range bars {
+		out = append(out, *fromCryptoBar(&bars[i]))
+	}
+	return out, nil
+}
+
+// fromCryptoQuote drops the fractional sizes (only prices are used).
+func fromCryptoQuote(q *marketdata.CryptoQuote) *marketdata.Quote {
+	return &marketdata.Quote{Timestamp: q.Timestamp, BidPrice: q.BidPrice, AskPrice: q.AskPrice}
+}
+
+// fromCryptoBar truncates the fractional volume. Nil stays nil.
+func fromCryptoBar(b *marketdata.CryptoBar) *marketdata.Bar {
+	if b == nil {
+		return nil
+	}
+	return &marketdata.Bar{
+		Timestamp: b.Timestamp, Open: b.Open, High: b.High, Low: b.Low, Close: b.Close,
+		Volume: uint64(b.Volume), TradeCount: b.TradeCount, VWAP: b.VWAP,
+	}
+}
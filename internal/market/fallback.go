@@ -0,0 +1,220 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// PriceSource is a secondary quote feed consulted when the broker's data fails.
+type PriceSource interface {
+	Name() string
+	GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error)
+	GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error)
+}
+
+// FallbackProvider wraps a MarketProvider and answers GetPrice/GetQuote from a
+// secondary source when the primary errors or returns an empty price.
+// Everything else (orders, account, bars) goes to the primary unchanged.
+type FallbackProvider struct {
+	MarketProvider
+	secondary PriceSource
+}
+
+// WithFallback returns primary wrapped with the secondary price source.
+func WithFallback(primary MarketProvider, secondary PriceSource) *FallbackProvider {
+	return &FallbackProvider{MarketProvider: primary, secondary: secondary}
+}
+
+// NewPriceSource builds the secondary source named by DATA_FALLBACK_PROVIDER.
+// Keys come from POLYGON_API_KEY or FINNHUB_API_KEY.
+func NewPriceSource(name string) (PriceSource, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch strings.ToLower(name) {
+	case "polygon":
+		key := os.Getenv("POLYGON_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("POLYGON_API_KEY is not set")
+		}
+		return &polygonSource{apiKey: key, http: client}, nil
+	case "finnhub":
+		key := os.Getenv("FINNHUB_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("FINNHUB_API_KEY is not set")
+		}
+		return &finnhubSource{apiKey: key, http: client}, nil
+	}
+	return nil, fmt.Errorf("unknown fallback provider %q (use polygon or finnhub)", name)
+}
+
+// Unwrap returns the primary provider.
+func (f *FallbackProvider) Unwrap() MarketProvider {
+	return f.MarketProvider
+}
+
+// GetPrice tries the primary first; an error or a zero price falls through to the secondary.
+func (f *FallbackProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	price, err := f.MarketProvider.GetPrice(ctx, ticker)
+	if err == nil && price.IsPositive() {
+		return price, nil
+	}
+	alt, altErr := f.secondary.GetPrice(ctx, ticker)
+	if altErr != nil || !alt.IsPositive() {
+		if err == nil {
+			err = fmt.Errorf("no price for %s", ticker)
+		}
+		return decimal.Zero, fmt.Errorf("%v (fallback %s: %v)", err, f.secondary.Name(), orEmpty(altErr))
+	}
+	log.Printf("[DATA_FALLBACK] %s price from %s: $%s (primary: %v)", ticker, f.secondary.Name(), alt.StringFixed(2), orEmpty(err))
+	return alt, nil
+}
+
+// GetQuote tries the primary first; an error or an empty book falls through to the secondary.
+func (f *FallbackProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	q, err := f.MarketProvider.GetQuote(ctx, ticker)
+	if err == nil && q != nil && q.BidPrice > 0 && q.AskPrice > 0 {
+		return q, nil
+	}
+	alt, altErr := f.secondary.GetQuote(ctx, ticker)
+	if altErr != nil || alt == nil || alt.BidPrice <= 0 || alt.AskPrice <= 0 {
+		if err != nil {
+			return nil, fmt.Errorf("%v (fallback %s: %v)", err, f.secondary.Name(), orEmpty(altErr))
+		}
+		return q, nil // Keep the primary's (partial) quote rather than nothing
+	}
+	log.Printf("[DATA_FALLBACK] %s quote from %s: %.2f/%.2f (primary: %v)", ticker, f.secondary.Name(), alt.BidPrice, alt.AskPrice, orEmpty(err))
+	return alt, nil
+}
+
+func orEmpty(err error) string {
+	if err == nil {
+		return "empty"
+	}
+	return err.Error()
+}
+
+// getJSON fetches u and decodes a JSON body into out.
+func getJSON(ctx context.Context, client *http.Client, u string, out interface{}) error {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %.200s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// polygonSource reads Polygon.io's last trade and NBBO (US equities).
+type polygonSource struct {
+	apiKey string
+	http   *http.Client
+}
+
+func (p *polygonSource) Name() string { return "polygon" }
+
+func (p *polygonSource) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	var res struct {
+		Results struct {
+			Price float64 `json:"p"`
+		} `json:"results"`
+	}
+	u := fmt.Sprintf("https://api.polygon.io/v2/last/trade/%s?apiKey=%s", url.PathEscape(ticker), url.QueryEscape(p.apiKey))
+	if err := getJSON(ctx, p.http, u, &res); err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromFloat(res.Results.Price), nil
+}
+
+func (p *polygonSource) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	var res struct {
+		Results struct {
+			AskPrice  float64 `json:"P"`
+			AskSize   float64 `json:"S"`
+			BidPrice  float64 `json:"p"`
+			BidSize   float64 `json:"s"`
+			Timestamp int64   `json:"t"` // Unix nanoseconds
+		} `json:"results"`
+	}
+	u := fmt.Sprintf("https://api.polygon.io/v2/last/nbbo/%s?apiKey=%s", url.PathEscape(ticker), url.QueryEscape(p.apiKey))
+	if err := getJSON(ctx, p.http, u, &res); err != nil {
+		return nil, err
+	}
+	r := res.Results
+	return &marketdata.Quote{
+		Timestamp: time.Unix(0, r.Timestamp),
+		BidPrice:  r.BidPrice,
+		BidSize:   uint32(r.BidSize),
+		AskPrice:  r.AskPrice,
+		AskSize:   uint32(r.AskSize),
+	}, nil
+}
+
+// finnhubSource reads Finnhub's /quote. The free tier has no bid/ask, so the
+// quote is synthesized from the current price (zero spread).
+type finnhubSource struct {
+	apiKey string
+	http   *http.Client
+}
+
+func (f *finnhubSource) Name() string { return "finnhub" }
+
+type finnhubQuote struct {
+	Current   float64 `json:"c"`
+	Timestamp int64   `json:"t"` // Unix seconds
+}
+
+func (f *finnhubSource) quote(ctx context.Context, ticker string) (*finnhubQuote, error) {
+	var q finnhubQuote
+	u := fmt.Sprintf("https://finnhub.io/api/v1/quote?symbol=%s&token=%s", url.QueryEscape(ticker), url.QueryEscape(f.apiKey))
+	if err := getJSON(ctx, f.http, u, &q); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (f *finnhubSource) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	q, err := f.quote(ctx, ticker)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromFloat(q.Current), nil
+}
+
+func (f *finnhubSource) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	q, err := f.quote(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	return &marketdata.Quote{
+		Timestamp: time.Unix(q.Timestamp, 0),
+		BidPrice:  q.Current,
+		AskPrice:  q.Current,
+	}, nil
+}
+
+var (
+	_ PriceSource = (*polygonSource)(nil)
+	_ PriceSource = (*finnhubSource)(nil)
+)
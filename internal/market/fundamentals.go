@@ -0,0 +1,77 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Fundamentals is the slow-moving company data behind /info and the AI snapshot.
+// Zero means unknown.
+type Fundamentals struct {
+	MarketCap float64 // USD
+	PE        float64 // Trailing twelve months
+	AvgVolume float64 // Shares per day
+	High52w   float64
+	Low52w    float64
+}
+
+// FundamentalsSource serves company fundamentals for a ticker.
+type FundamentalsSource interface {
+	Name() string
+	GetFundamentals(ctx context.Context, ticker string) (*Fundamentals, error)
+}
+
+// NewFundamentalsSource builds the source named by FUNDAMENTALS_PROVIDER.
+// The key comes from FINNHUB_API_KEY.
+func NewFundamentalsSource(name string) (FundamentalsSource, error) {
+	switch strings.ToLower(name) {
+	case "finnhub":
+		key := os.Getenv("FINNHUB_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("FINNHUB_API_KEY is not set")
+		}
+		return &finnhubSource{apiKey: key, http: &http.Client{Timeout: 10 * time.Second}}, nil
+	}
+	return nil, fmt.Errorf("unknown fundamentals provider %q (use finnhub)", name)
+}
+
+// GetFundamentals reads Finnhub's /stock/metric. Market cap and volumes come in millions.
+func (f *finnhubSource) GetFundamentals(ctx context.Context, ticker string) (*Fundamentals, error) {
+	var res struct {
+		Metric struct {
+			MarketCap  float64 `json:"marketCapitalization"`
+			PE         float64 `json:"peTTM"`
+			PEBasic    float64 `json:"peBasicExclExtraTTM"`
+			Volume10D  float64 `json:"10DayAverageTradingVolume"`
+			Volume3M   float64 `json:"3MonthAverageTradingVolume"`
+			High52Week float64 `json:"52WeekHigh"`
+			Low52Week  float64 `json:"52WeekLow"`
+		} `json:"metric"`
+	}
+	u := fmt.Sprintf("https://finnhub.io/api/v1/stock/metric?symbol=%s&metric=all&token=%s", url.QueryEscape(ticker), url.QueryEscape(f.apiKey))
+	if err := getJSON(ctx, f.http, u, &res); err != nil {
+		return nil, err
+	}
+	m := res.Metric
+	fd := &Fundamentals{
+		MarketCap: m.MarketCap * 1e6,
+		PE:        m.PE,
+		AvgVolume: m.Volume3M * 1e6,
+		High52w:   m.High52Week,
+		Low52w:    m.Low52Week,
+	}
+	if fd.PE == 0 {
+		fd.PE = m.PEBasic
+	}
+	if fd.AvgVolume == 0 {
+		fd.AvgVolume = m.Volume10D * 1e6
+	}
+	return fd, nil
+}
+
+var _ FundamentalsSource = (*finnhubSource)(nil)
@@ -0,0 +1,232 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/symbols"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// FXProvider wraps a MarketProvider and answers price lookups for forex pairs (EUR/USD)
+// from a dedicated FX source, since neither broker quotes them. FX pairs are watch-only:
+// orders for them are refused here, before they reach the broker.
+type FXProvider struct {
+	MarketProvider
+	source PriceSource
+}
+
+// WithFX returns p wrapped with the FX rate source.
+func WithFX(p MarketProvider, source PriceSource) *FXProvider {
+	return &FXProvider{MarketProvider: p, source: source}
+}
+
+// NewFXSource builds the FX rate source named by FX_PROVIDER.
+// frankfurter needs no key (ECB reference rates, updated once per business day);
+// polygon reads the live quote and needs POLYGON_API_KEY.
+func NewFXSource(name string) (PriceSource, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	switch strings.ToLower(name) {
+	case "frankfurter":
+		return &frankfurterSource{http: client}, nil
+	case "polygon":
+		key := os.Getenv("POLYGON_API_KEY")
+		if key == "" {
+			return nil, fmt.Errorf("POLYGON_API_KEY is not set")
+		}
+		return &polygonFXSource{apiKey: key, http: client}, nil
+	}
+	return nil, fmt.Errorf("unknown FX provider %q (use frankfurter or polygon)", name)
+}
+
+// Unwrap returns the wrapped provider.
+func (f *FXProvider) Unwrap() MarketProvider {
+	return f.MarketProvider
+}
+
+func (f *FXProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	if symbols.IsForex(ticker) {
+		return f.source.GetPrice(ctx, ticker)
+	}
+	return f.MarketProvider.GetPrice(ctx, ticker)
+}
+
+func (f *FXProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	if symbols.IsForex(ticker) {
+		return f.source.GetQuote(ctx, ticker)
+	}
+	return f.MarketProvider.GetQuote(ctx, ticker)
+}
+
+// GetSnapshot builds an FX pair's snapshot from its quote (no daily bars).
+func (f *FXProvider) GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	if symbols.IsForex(ticker) {
+		return f.fxSnapshot(ctx, ticker)
+	}
+	return f.MarketProvider.GetSnapshot(ctx, ticker)
+}
+
+// GetSnapshots serves FX pairs from the FX source and the rest in one batch from the
+// wrapped provider. Pairs the source cannot serve are left out, as the batch does.
+func (f *FXProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	var rest, fx []string
+	for _, t := range tickers {
+		if symbols.IsForex(t) {
+			fx = append(fx, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	out := make(map[string]*marketdata.Snapshot, len(tickers))
+	var err error
+	if len(rest) > 0 {
+		var snaps map[string]*marketdata.Snapshot
+		snaps, err = f.MarketProvider.GetSnapshots(ctx, rest)
+		for t, s := range snaps {
+			out[t] = s
+		}
+	}
+	for _, t := range fx {
+		if s, fxErr := f.fxSnapshot(ctx, t); fxErr == nil {
+			out[t] = s
+		}
+	}
+	return out, err
+}
+
+func (f *FXProvider) fxSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	q, err := f.source.GetQuote(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	mid := (q.BidPrice + q.AskPrice) / 2
+	return &marketdata.Snapshot{
+		LatestTrade: &marketdata.Trade{Price: mid, Timestamp: q.Timestamp},
+		LatestQuote: q,
+	}, nil
+}
+
+func (f *FXProvider) GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error) {
+	if symbols.IsForex(ticker) {
+		return nil, fmt.Errorf("no bars for forex pair %s (FX_PROVIDER serves rates only)", ticker)
+	}
+	return f.MarketProvider.GetBars(ctx, ticker, limit)
+}
+
+func (f *FXProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error) {
+	if symbols.IsForex(ticker) {
+		return nil, fmt.Errorf("no bars for forex pair %s (FX_PROVIDER serves rates only)", ticker)
+	}
+	return f.MarketProvider.GetBarsRange(ctx, ticker, timeframe, start, end)
+}
+
+// GetAsset describes an FX pair as an active, non-tradable asset.
+func (f *FXProvider) GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error) {
+	if symbols.IsForex(ticker) {
+		return &alpaca.Asset{Symbol: ticker, Name: ticker + " (forex, watch-only)", Class: "forex", Status: alpaca.AssetActive}, nil
+	}
+	return f.MarketProvider.GetAsset(ctx, ticker)
+}
+
+// PlaceOrder refuses FX pairs: neither broker trades them.
+func (f *FXProvider) PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (*alpaca.Order, error) {
+	if symbols.IsForex(ticker) {
+		return nil, fmt.Errorf("%s is a forex pair: watch-only, not tradable at the broker", ticker)
+	}
+	return f.MarketProvider.PlaceOrder(ctx, ticker, qty, side, opts...)
+}
+
+// frankfurterSource reads ECB reference rates from api.frankfurter.app. There is
+// no bid/ask, so the quote has zero spread and carries the rate's publication date.
+type frankfurterSource struct {
+	http *http.Client
+}
+
+func (s *frankfurterSource) Name() string { return "frankfurter" }
+
+func (s *frankfurterSource) rate(ctx context.Context, pair string) (float64, time.Time, error) {
+	base, quote, _ := strings.Cut(pair, "/")
+	var res struct {
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	u := fmt.Sprintf("https://api.frankfurter.app/latest?from=%s&to=%s", url.QueryEscape(base), url.QueryEscape(quote))
+	if err := getJSON(ctx, s.http, u, &res); err != nil {
+		return 0, time.Time{}, err
+	}
+	r, ok := res.Rates[quote]
+	if !ok || r <= 0 {
+		return 0, time.Time{}, fmt.Errorf("no %s rate", pair)
+	}
+	at, _ := time.Parse("2006-01-02", res.Date)
+	return r, at, nil
+}
+
+func (s *frankfurterSource) GetPrice(ctx context.Context, pair string) (decimal.Decimal, error) {
+	r, _, err := s.rate(ctx, pair)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromFloat(r), nil
+}
+
+func (s *frankfurterSource) GetQuote(ctx context.Context, pair string) (*marketdata.Quote, error) {
+	r, at, err := s.rate(ctx, pair)
+	if err != nil {
+		return nil, err
+	}
+	return &marketdata.Quote{Timestamp: at, BidPrice: r, AskPrice: r}, nil
+}
+
+// polygonFXSource reads Polygon.io's last forex quote.
+type polygonFXSource struct {
+	apiKey string
+	http   *http.Client
+}
+
+func (p *polygonFXSource) Name() string { return "polygon" }
+
+func (p *polygonFXSource) GetQuote(ctx context.Context, pair string) (*marketdata.Quote, error) {
+	base, quote, _ := strings.Cut(pair, "/")
+	var res struct {
+		Last struct {
+			Ask       float64 `json:"ask"`
+			Bid       float64 `json:"bid"`
+			Timestamp int64   `json:"timestamp"` // Unix milliseconds
+		} `json:"last"`
+	}
+	u := fmt.Sprintf("https://api.polygon.io/v1/last_quote/currencies/%s/%s?apiKey=%s", url.PathEscape(base), url.PathEscape(quote), url.QueryEscape(p.apiKey))
+	if err := getJSON(ctx, p.http, u, &res); err != nil {
+		return nil, err
+	}
+	if res.Last.Bid <= 0 || res.Last.Ask <= 0 {
+		return nil, fmt.Errorf("no %s quote", pair)
+	}
+	return &marketdata.Quote{
+		Timestamp: time.UnixMilli(res.Last.Timestamp),
+		BidPrice:  res.Last.Bid,
+		AskPrice:  res.Last.Ask,
+	}, nil
+}
+
+func (p *polygonFXSource) GetPrice(ctx context.Context, pair string) (decimal.Decimal, error) {
+	q, err := p.GetQuote(ctx, pair)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return decimal.NewFromFloat((q.BidPrice + q.AskPrice) / 2), nil
+}
+
+var (
+	_ MarketProvider = (*FXProvider)(nil)
+	_ PriceSource    = (*frankfurterSource)(nil)
+	_ PriceSource    = (*polygonFXSource)(nil)
+)
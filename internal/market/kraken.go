@@ -0,0 +1,955 @@
+package market
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"alpha_trading/internal/money"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+const krakenBaseURL = "https://api.kraken.com"
+
+// errKrakenUnsupported is returned for Alpaca-only features (server-side watchlists, portfolio history).
+var errKrakenUnsupported = errors.New("not supported by the Kraken provider")
+
+// krakenIntervals are the OHLC intervals (minutes) Kraken serves.
+var krakenIntervals = map[int]bool{1: true, 5: true, 15: true, 30: true, 60: true, 240: true, 1440: true, 10080: true, 21600: true}
+
+// KrakenProvider is a MarketProvider for a Kraken spot account.
+// Symbols use the canonical "BASE/QUOTE" form (e.g., "BTC/USD"); Kraken's legacy
+// asset codes (XBT, XXBT, ZUSD) are translated at the edges. Crypto trades around
+// the clock, so the clock is always open and every calendar day is a full session.
+type KrakenProvider struct {
+	apiKey  string
+	secret  []byte // Decoded API secret (HMAC key)
+	quote   string // Quote currency for equity, buying power and positions
+	baseURL string
+	http    *http.Client
+
+	nonceMu   sync.Mutex
+	lastNonce int64
+}
+
+// NewKrakenProvider reads KRAKEN_API_KEY, KRAKEN_API_SECRET and KRAKEN_QUOTE (default USD).
+func NewKrakenProvider() (*KrakenProvider, error) {
+	secret, err := base64.StdEncoding.DecodeString(os.Getenv("KRAKEN_API_SECRET"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid KRAKEN_API_SECRET (expected base64): %w", err)
+	}
+	quote := strings.ToUpper(os.Getenv("KRAKEN_QUOTE"))
+	if quote == "" {
+		quote = "USD"
+	}
+	return &KrakenProvider{
+		apiKey:  os.Getenv("KRAKEN_API_KEY"),
+		secret:  secret,
+		quote:   quote,
+		baseURL: krakenBaseURL,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// --- Symbol translation ---
+
+// krakenPair converts "BTC/USD" to Kraken's pair name "XBTUSD".
+func krakenPair(ticker string) string {
+	base, quote, ok := strings.Cut(strings.ToUpper(ticker), "/")
+	if !ok {
+		return strings.ToUpper(ticker)
+	}
+	return krakenCode(base) + quote
+}
+
+func krakenCode(asset string) string {
+	switch asset {
+	case "BTC":
+		return "XBT"
+	case "DOGE":
+		return "XDG"
+	}
+	return asset
+}
+
+// krakenAsset converts a Kraken asset code ("XXBT", "ZUSD", "SOL", "ETH.F") to its common name.
+func krakenAsset(code string) string {
+	code = strings.ToUpper(code)
+	if i := strings.Index(code, "."); i > 0 {
+		code = code[:i] // Earn/flex variants hold the same asset
+	}
+	if len(code) == 4 && (code[0] == 'X' || code[0] == 'Z') {
+		code = code[1:]
+	}
+	switch code {
+	case "XBT":
+		return "BTC"
+	case "XDG":
+		return "DOGE"
+	}
+	return code
+}
+
+// symbolFromPair converts a pair name ("XBTUSD", "XXBTZUSD", "XBT/USD") to "BTC/USD".
+func (k *KrakenProvider) symbolFromPair(pair string) string {
+	pair = strings.ToUpper(pair)
+	if base, quote, ok := strings.Cut(pair, "/"); ok {
+		return krakenAsset(base) + "/" + krakenAsset(quote)
+	}
+	for _, q := range []string{"Z" + k.quote, k.quote} {
+		if strings.HasSuffix(pair, q) && len(pair) > len(q) {
+			return krakenAsset(strings.TrimSuffix(pair, q)) + "/" + k.quote
+		}
+	}
+	return pair
+}
+
+// --- Transport ---
+
+type krakenEnvelope struct {
+	Error  []string        `json:"error"`
+	Result json.RawMessage `json:"result"`
+}
+
+func (k *KrakenProvider) decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kraken HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	var env krakenEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return fmt.Errorf("kraken: malformed response: %w", err)
+	}
+	if len(env.Error) > 0 {
+		return fmt.Errorf("kraken: %s", strings.Join(env.Error, "; "))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(env.Result, out)
+}
+
+func (k *KrakenProvider) public(ctx context.Context, method string, params url.Values, out interface{}) error {
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.baseURL+"/0/public/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return err
+	}
+	return k.decode(resp, out)
+}
+
+// private signs a request: API-Sign = HMAC-SHA512(path + SHA256(nonce + body), secret).
+func (k *KrakenProvider) private(ctx context.Context, method string, params url.Values, out interface{}) error {
+	if k.apiKey == "" || len(k.secret) == 0 {
+		return fmt.Errorf("kraken: KRAKEN_API_KEY / KRAKEN_API_SECRET not configured")
+	}
+	if params == nil {
+		params = url.Values{}
+	}
+	nonce := k.nextNonce()
+	params.Set("nonce", nonce)
+	body := params.Encode()
+	path := "/0/private/" + method
+
+	sha := sha256.Sum256([]byte(nonce + body))
+	mac := hmac.New(sha512.New, k.secret)
+	mac.Write(append([]byte(path), sha[:]...))
+
+	ctx, cancel := callContext(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.baseURL+path, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("API-Key", k.apiKey)
+	req.Header.Set("API-Sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return err
+	}
+	return k.decode(resp, out)
+}
+
+// nextNonce returns a strictly increasing nonce (Kraken rejects repeats).
+func (k *KrakenProvider) nextNonce() string {
+	k.nonceMu.Lock()
+	defer k.nonceMu.Unlock()
+	n := time.Now().UnixMilli()
+	if n <= k.lastNonce {
+		n = k.lastNonce + 1
+	}
+	k.lastNonce = n
+	return strconv.FormatInt(n, 10)
+}
+
+// --- Market data ---
+
+type krakenTicker struct {
+	Ask  []string `json:"a"` // price, whole lot volume, lot volume
+	Bid  []string `json:"b"`
+	Last []string `json:"c"` // price, lot volume
+}
+
+func (k *KrakenProvider) ticker(ctx context.Context, ticker string) (krakenTicker, error) {
+	var res map[string]krakenTicker
+	if err := k.public(ctx, "Ticker", url.Values{"pair": {krakenPair(ticker)}}, &res); err != nil {
+		return krakenTicker{}, err
+	}
+	for _, t := range res {
+		return t, nil
+	}
+	return krakenTicker{}, fmt.Errorf("kraken: no ticker for %s", ticker)
+}
+
+func firstFloat(v []string) float64 {
+	if len(v) == 0 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(v[0], 64)
+	return f
+}
+
+func lotSize(v []string) uint32 {
+	if len(v) < 3 {
+		return 0
+	}
+	f, _ := strconv.ParseFloat(v[2], 64)
+	return uint32(math.Round(f))
+}
+
+// GetPrice fetches the last trade price.
+func (k *KrakenProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	t, err := k.ticker(ctx, ticker)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(t.Last) == 0 {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(t.Last[0])
+}
+
+// GetQuote fetches the best bid/ask.
+func (k *KrakenProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	t, err := k.ticker(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	return &marketdata.Quote{
+		Timestamp: time.Now(),
+		BidPrice:  firstFloat(t.Bid),
+		BidSize:   lotSize(t.Bid),
+		AskPrice:  firstFloat(t.Ask),
+		AskSize:   lotSize(t.Ask),
+	}, nil
+}
+
+// GetSnapshot combines the ticker with the last two daily bars (UTC days).
+func (k *KrakenProvider) GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	t, err := k.ticker(ctx, ticker)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	snap := &marketdata.Snapshot{
+		LatestTrade: &marketdata.Trade{Timestamp: now, Price: firstFloat(t.Last)},
+		LatestQuote: &marketdata.Quote{
+			Timestamp: now,
+			BidPrice:  firstFloat(t.Bid), BidSize: lotSize(t.Bid),
+			AskPrice: firstFloat(t.Ask), AskSize: lotSize(t.Ask),
+		},
+	}
+	if bars, err := k.GetBars(ctx, ticker, 2); err == nil && len(bars) > 0 {
+		snap.DailyBar = &bars[len(bars)-1]
+		if len(bars) > 1 {
+			snap.PrevDailyBar = &bars[len(bars)-2]
+		}
+	}
+	return snap, nil
+}
+
+// GetSnapshots fetches one snapshot per ticker (each needs its own bar request).
+// Tickers that fail are missing from the map; the error is returned only if all fail.
+func (k *KrakenProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	out := make(map[string]*marketdata.Snapshot, len(tickers))
+	var lastErr error
+	for _, t := range tickers {
+		s, err := k.GetSnapshot(ctx, t)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		out[t] = s
+	}
+	if len(out) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return out, nil
+}
+
+// GetBars fetches the last `limit` daily bars.
+func (k *KrakenProvider) GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error) {
+	start := time.Now().AddDate(0, 0, -(limit + 2))
+	bars, err := k.GetBarsRange(ctx, ticker, "1D", start, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if len(bars) > limit {
+		return bars[len(bars)-limit:], nil
+	}
+	return bars, nil
+}
+
+// GetBarsRange fetches OHLC bars. Kraken serves at most 720 bars per call and only
+// the intervals in krakenIntervals (1Min, 5Min, 15Min, 30Min, 1H, 4H, 1D, 1W).
+func (k *KrakenProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error) {
+	tf, err := ParseTimeFrame(timeframe)
+	if err != nil {
+		return nil, err
+	}
+	minutes := tf.N
+	switch tf.Unit {
+	case marketdata.Hour:
+		minutes *= 60
+	case marketdata.Day:
+		minutes *= 1440
+	case marketdata.Week:
+		minutes *= 10080
+	}
+	if !krakenIntervals[minutes] {
+		return nil, fmt.Errorf("timeframe %q is not available on Kraken", timeframe)
+	}
+	if !end.IsZero() && !end.After(start) {
+		return nil, fmt.Errorf("invalid bar range: end %s is not after start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+
+	var res map[string]json.RawMessage
+	params := url.Values{
+		"pair":     {krakenPair(ticker)},
+		"interval": {strconv.Itoa(minutes)},
+		"since":    {strconv.FormatInt(start.Unix(), 10)},
+	}
+	if err := k.public(ctx, "OHLC", params, &res); err != nil {
+		return nil, err
+	}
+
+	var bars []marketdata.Bar
+	for key, raw := range res {
+		if key == "last" {
+			continue
+		}
+		// [time, open, high, low, close, vwap, volume, count]
+		var rows [][]interface{}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, fmt.Errorf("kraken: malformed OHLC: %w", err)
+		}
+		for _, r := range rows {
+			if len(r) < 8 {
+				continue
+			}
+			ts, _ := r[0].(float64)
+			bar := marketdata.Bar{
+				Timestamp:  time.Unix(int64(ts), 0).UTC(),
+				Open:       numField(r[1]),
+				High:       numField(r[2]),
+				Low:        numField(r[3]),
+				Close:      numField(r[4]),
+				VWAP:       numField(r[5]),
+				Volume:     uint64(math.Round(numField(r[6]))),
+				TradeCount: uint64(numField(r[7])),
+			}
+			if !end.IsZero() && bar.Timestamp.After(end) {
+				continue
+			}
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// numField reads an OHLC cell (Kraken sends prices as strings, counts as numbers).
+func numField(v interface{}) float64 {
+	switch x := v.(type) {
+	case string:
+		f, _ := strconv.ParseFloat(x, 64)
+		return f
+	case float64:
+		return x
+	}
+	return 0
+}
+
+// GetClock reports the market as always open; the "session" closes at UTC midnight.
+func (k *KrakenProvider) GetClock(ctx context.Context) (*alpaca.Clock, error) {
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	return &alpaca.Clock{Timestamp: now, IsOpen: true, NextOpen: now, NextClose: midnight}, nil
+}
+
+// GetCalendar returns every day between start and end as a full session.
+func (k *KrakenProvider) GetCalendar(ctx context.Context, start, end time.Time) ([]alpaca.CalendarDay, error) {
+	var days []alpaca.CalendarDay
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, alpaca.CalendarDay{Date: d.Format("2006-01-02"), Open: "00:00", Close: "23:59"})
+	}
+	return days, nil
+}
+
+// --- Assets ---
+
+type krakenPairInfo struct {
+	Altname      string `json:"altname"`
+	Wsname       string `json:"wsname"`
+	Quote        string `json:"quote"`
+	Status       string `json:"status"`
+	PairDecimals int32  `json:"pair_decimals"` // Price precision accepted by AddOrder
+	LotDecimals  int32  `json:"lot_decimals"`  // Volume precision accepted by AddOrder
+}
+
+func (k *KrakenProvider) pairAsset(p krakenPairInfo) alpaca.Asset {
+	symbol := k.symbolFromPair(p.Wsname)
+	if p.Wsname == "" {
+		symbol = k.symbolFromPair(p.Altname)
+	}
+	online := p.Status == "" || p.Status == "online"
+	status := alpaca.AssetActive
+	if !online {
+		status = alpaca.AssetInactive
+	}
+	return alpaca.Asset{
+		ID:           p.Altname,
+		Class:        alpaca.Crypto,
+		Exchange:     "KRAKEN",
+		Symbol:       symbol,
+		Name:         p.Wsname,
+		Status:       status,
+		Tradable:     online,
+		Fractionable: true,
+	}
+}
+
+// SearchAssets matches pairs quoted in KRAKEN_QUOTE by name. Returns at most 5 results.
+func (k *KrakenProvider) SearchAssets(ctx context.Context, query string) ([]alpaca.Asset, error) {
+	var res map[string]krakenPairInfo
+	if err := k.public(ctx, "AssetPairs", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+	q := strings.ToUpper(query)
+	var results []alpaca.Asset
+	for _, p := range res {
+		a := k.pairAsset(p)
+		if !strings.HasSuffix(a.Symbol, "/"+k.quote) {
+			continue
+		}
+		if strings.Contains(a.Symbol, q) || strings.Contains(p.Altname, q) {
+			results = append(results, a)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Symbol < results[j].Symbol })
+	if len(results) > 5 {
+		results = results[:5]
+	}
+	return results, nil
+}
+
+// GetAsset looks up a single pair (used to validate symbols before order placement).
+func (k *KrakenProvider) GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error) {
+	var res map[string]krakenPairInfo
+	if err := k.public(ctx, "AssetPairs", url.Values{"pair": {krakenPair(ticker)}}, &res); err != nil {
+		return nil, err
+	}
+	for _, p := range res {
+		a := k.pairAsset(p)
+		return &a, nil
+	}
+	return nil, fmt.Errorf("kraken: unknown pair %s", ticker)
+}
+
+// --- Account ---
+
+type krakenBalance struct {
+	Balance   decimal.Decimal `json:"balance"`
+	HoldTrade decimal.Decimal `json:"hold_trade"` // Reserved by open orders
+}
+
+func (k *KrakenProvider) balances(ctx context.Context) (map[string]krakenBalance, error) {
+	var res map[string]krakenBalance
+	if err := k.private(ctx, "BalanceEx", nil, &res); err != nil {
+		return nil, err
+	}
+	// Merge variants (e.g., "ETH" and "ETH.F") under the common name
+	merged := make(map[string]krakenBalance)
+	for code, b := range res {
+		name := krakenAsset(code)
+		m := merged[name]
+		m.Balance = m.Balance.Add(b.Balance)
+		m.HoldTrade = m.HoldTrade.Add(b.HoldTrade)
+		merged[name] = m
+	}
+	return merged, nil
+}
+
+// GetEquity returns the account value in KRAKEN_QUOTE ("eb": all balances converted).
+func (k *KrakenProvider) GetEquity(ctx context.Context) (decimal.Decimal, error) {
+	var res struct {
+		EquivalentBalance decimal.Decimal `json:"eb"`
+	}
+	if err := k.private(ctx, "TradeBalance", url.Values{"asset": {k.quote}}, &res); err != nil {
+		return decimal.Zero, err
+	}
+	return res.EquivalentBalance, nil
+}
+
+// GetBuyingPower returns the free KRAKEN_QUOTE cash (balance minus open-order holds).
+func (k *KrakenProvider) GetBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+	bals, err := k.balances(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	cash := bals[k.quote]
+	return cash.Balance.Sub(cash.HoldTrade), nil
+}
+
+// GetAccount builds an Alpaca-shaped account from the Kraken balances.
+// Kraken has no prior-day equity, so LastEquity mirrors Equity.
+func (k *KrakenProvider) GetAccount(ctx context.Context) (*alpaca.Account, error) {
+	equity, err := k.GetEquity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cash, err := k.GetBuyingPower(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &alpaca.Account{
+		ID:                   "kraken",
+		AccountNumber:        "KRAKEN",
+		Status:               "ACTIVE",
+		CryptoStatus:         "ACTIVE",
+		Currency:             k.quote,
+		BuyingPower:          cash,
+		NonMarginBuyingPower: cash,
+		Cash:                 cash,
+		PortfolioValue:       equity,
+		Equity:               equity,
+		LastEquity:           equity,
+		Multiplier:           decimal.NewFromInt(1),
+	}, nil
+}
+
+// GetPortfolioHistory is not offered by Kraken.
+func (k *KrakenProvider) GetPortfolioHistory(ctx context.Context, period string, timeframe string) (*alpaca.PortfolioHistory, error) {
+	return nil, fmt.Errorf("portfolio history: %w", errKrakenUnsupported)
+}
+
+// --- Orders & Positions ---
+
+type krakenOrder struct {
+	Status   string          `json:"status"` // pending, open, closed, canceled, expired
+	OpenTm   float64         `json:"opentm"`
+	CloseTm  float64         `json:"closetm"`
+	Vol      decimal.Decimal `json:"vol"`
+	VolExec  decimal.Decimal `json:"vol_exec"`
+	AvgPrice decimal.Decimal `json:"price"`
+	ClOrdID  string          `json:"cl_ord_id"`
+	Descr    struct {
+		Pair      string `json:"pair"`
+		Type      string `json:"type"`
+		OrderType string `json:"ordertype"`
+	} `json:"descr"`
+}
+
+// krakenClientID fits a client order ID into Kraken's cl_ord_id (free text of at most
+// 18 characters): intent IDs ("at-<unix nanos>") are shortened to "at<base 36>".
+func krakenClientID(id string) string {
+	if n, err := strconv.ParseInt(strings.TrimPrefix(id, "at-"), 10, 64); err == nil && strings.HasPrefix(id, "at-") {
+		return "at" + strconv.FormatInt(n, 36)
+	}
+	return id
+}
+
+// clientIDFromKraken reverses krakenClientID.
+func clientIDFromKraken(id string) string {
+	if strings.HasPrefix(id, "at") && !strings.HasPrefix(id, "at-") {
+		if n, err := strconv.ParseInt(strings.TrimPrefix(id, "at"), 36, 64); err == nil {
+			return "at-" + strconv.FormatInt(n, 10)
+		}
+	}
+	return id
+}
+
+// toAlpaca maps a Kraken order onto the Alpaca order fields the watcher reads.
+func (k *KrakenProvider) toAlpaca(id string, o krakenOrder) alpaca.Order {
+	qty := o.Vol
+	out := alpaca.Order{
+		ID:            id,
+		ClientOrderID: clientIDFromKraken(o.ClOrdID),
+		Symbol:        k.symbolFromPair(o.Descr.Pair),
+		AssetClass:    alpaca.Crypto,
+		Type:          alpaca.OrderType(o.Descr.OrderType),
+		Side:          alpaca.Side(o.Descr.Type),
+		TimeInForce:   alpaca.GTC,
+		Qty:           &qty,
+		FilledQty:     o.VolExec,
+		CreatedAt:     unixTime(o.OpenTm),
+		SubmittedAt:   unixTime(o.OpenTm),
+		UpdatedAt:     unixTime(math.Max(o.OpenTm, o.CloseTm)),
+	}
+	if o.VolExec.IsPositive() {
+		avg := o.AvgPrice
+		out.FilledAvgPrice = &avg
+	}
+
+	closed := unixTime(o.CloseTm)
+	switch o.Status {
+	case "pending":
+		out.Status = "pending_new"
+	case "open":
+		out.Status = "new"
+		if o.VolExec.IsPositive() {
+			out.Status = "partially_filled"
+		}
+	case "closed":
+		out.Status = "filled"
+		out.FilledAt = &closed
+	case "canceled":
+		out.Status = "canceled"
+		out.CanceledAt = &closed
+	case "expired":
+		out.Status = "expired"
+		out.ExpiredAt = &closed
+	default:
+		out.Status = o.Status
+	}
+	return out
+}
+
+func unixTime(ts float64) time.Time {
+	sec, frac := math.Modf(ts)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// PlaceOrder executes a market order, or a limit order when opts carry a LimitPrice. Side should be "buy" or "sell".
+func (k *KrakenProvider) PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (*alpaca.Order, error) {
+	var res struct {
+		TxID []string `json:"txid"`
+	}
+	params := url.Values{
+		"pair":      {krakenPair(ticker)},
+		"type":      {strings.ToLower(side)},
+		"ordertype": {"market"},
+		"volume":    {qty.String()},
+	}
+	// Crypto trades around the clock: ExtendedHours needs no flag, a limit price still applies.
+	// Kraken has no DAY orders; limits rest until cancelled (GTC), whatever TimeInForce says.
+	o := orderOptions(opts)
+	if o.OrderClass != "" {
+		return nil, fmt.Errorf("kraken: %s orders are not supported", o.OrderClass)
+	}
+	if o.TrailPercent.IsPositive() {
+		return nil, fmt.Errorf("kraken: trailing stop orders are not supported")
+	}
+	if o.Notional.IsPositive() {
+		volume, err := k.notionalVolume(ctx, ticker, o.Notional, o.LimitPrice)
+		if err != nil {
+			return nil, err
+		}
+		qty = volume
+		params.Set("volume", qty.String())
+	}
+	if o.LimitPrice.IsPositive() {
+		params.Set("ordertype", "limit")
+		params.Set("price", k.limitPrice(ctx, ticker, side, o.LimitPrice).String())
+	}
+	if o.ClientOrderID != "" {
+		params.Set("cl_ord_id", krakenClientID(o.ClientOrderID))
+	}
+	if err := k.private(ctx, "AddOrder", params, &res); err != nil {
+		return nil, err
+	}
+	if len(res.TxID) == 0 {
+		return nil, fmt.Errorf("kraken: order accepted without a txid")
+	}
+	if order, err := k.GetOrder(ctx, res.TxID[0]); err == nil {
+		return order, nil
+	}
+	// Not queryable yet: report it as submitted
+	return &alpaca.Order{
+		ID: res.TxID[0], Symbol: ticker, AssetClass: alpaca.Crypto, Type: alpaca.Market,
+		Side: alpaca.Side(side), Qty: &qty, Status: "new", SubmittedAt: time.Now(), ClientOrderID: o.ClientOrderID,
+	}, nil
+}
+
+// notionalVolume converts a dollar amount into the pair's volume at price (the limit,
+// or the last trade when zero), truncated to lot_decimals so it never overspends.
+func (k *KrakenProvider) notionalVolume(ctx context.Context, ticker string, notional, price decimal.Decimal) (decimal.Decimal, error) {
+	if !price.IsPositive() {
+		p, err := k.GetPrice(ctx, ticker)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("kraken: no price to size notional order: %w", err)
+		}
+		price = p
+	}
+	volume := money.Qty(ticker, notional.Div(price), true)
+	var res map[string]krakenPairInfo
+	if err := k.public(ctx, "AssetPairs", url.Values{"pair": {krakenPair(ticker)}}, &res); err == nil {
+		for _, info := range res {
+			volume = notional.Div(price).Truncate(info.LotDecimals)
+		}
+	}
+	if !volume.IsPositive() {
+		return decimal.Zero, fmt.Errorf("kraken: $%s buys no %s", notional.StringFixed(2), ticker)
+	}
+	return volume, nil
+}
+
+// limitPrice snaps p to the pair's price precision (pair_decimals), which is coarser
+// than the generic 8 decimals for most pairs (e.g., 1 decimal for XBT/USD).
+func (k *KrakenProvider) limitPrice(ctx context.Context, ticker, side string, p decimal.Decimal) decimal.Decimal {
+	var res map[string]krakenPairInfo
+	if err := k.public(ctx, "AssetPairs", url.Values{"pair": {krakenPair(ticker)}}, &res); err == nil {
+		for _, info := range res {
+			return money.SnapToTick(p, decimal.New(1, -info.PairDecimals), side)
+		}
+	}
+	return money.OrderPrice(ticker, side, p)
+}
+
+// GetOrder fetches an order by txid.
+func (k *KrakenProvider) GetOrder(ctx context.Context, orderID string) (*alpaca.Order, error) {
+	var res map[string]krakenOrder
+	if err := k.private(ctx, "QueryOrders", url.Values{"txid": {orderID}}, &res); err != nil {
+		return nil, err
+	}
+	o, ok := res[orderID]
+	if !ok {
+		return nil, fmt.Errorf("kraken: order %s not found", orderID)
+	}
+	order := k.toAlpaca(orderID, o)
+	return &order, nil
+}
+
+// GetOrderByClientID finds the open or closed order placed under clientOrderID
+// (sent as cl_ord_id, see krakenClientID).
+func (k *KrakenProvider) GetOrderByClientID(ctx context.Context, clientOrderID string) (*alpaca.Order, error) {
+	params := url.Values{"cl_ord_id": {krakenClientID(clientOrderID)}}
+	var open struct {
+		Open map[string]krakenOrder `json:"open"`
+	}
+	if err := k.private(ctx, "OpenOrders", params, &open); err != nil {
+		return nil, err
+	}
+	var closed struct {
+		Closed map[string]krakenOrder `json:"closed"`
+	}
+	if len(open.Open) == 0 {
+		if err := k.private(ctx, "ClosedOrders", params, &closed); err != nil {
+			return nil, err
+		}
+	}
+	for _, orders := range []map[string]krakenOrder{open.Open, closed.Closed} {
+		for id, o := range orders {
+			order := k.toAlpaca(id, o)
+			return &order, nil
+		}
+	}
+	return nil, fmt.Errorf("kraken: client order %s: %w", clientOrderID, ErrOrderNotFound)
+}
+
+// ListOrders fetches "open", "closed" or "all" orders (newest first).
+func (k *KrakenProvider) ListOrders(ctx context.Context, status string) ([]alpaca.Order, error) {
+	var orders []alpaca.Order
+	if status == "open" || status == "all" {
+		var res struct {
+			Open map[string]krakenOrder `json:"open"`
+		}
+		if err := k.private(ctx, "OpenOrders", nil, &res); err != nil {
+			return nil, err
+		}
+		for id, o := range res.Open {
+			orders = append(orders, k.toAlpaca(id, o))
+		}
+	}
+	if status == "closed" || status == "all" {
+		var res struct {
+			Closed map[string]krakenOrder `json:"closed"`
+		}
+		if err := k.private(ctx, "ClosedOrders", nil, &res); err != nil {
+			return nil, err
+		}
+		for id, o := range res.Closed {
+			orders = append(orders, k.toAlpaca(id, o))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].SubmittedAt.After(orders[j].SubmittedAt) })
+	return orders, nil
+}
+
+// ListPositions reports every non-cash balance as a long position against KRAKEN_QUOTE.
+// Spot balances carry no cost basis, so AvgEntryPrice is the current price.
+func (k *KrakenProvider) ListPositions(ctx context.Context) ([]alpaca.Position, error) {
+	bals, err := k.balances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var positions []alpaca.Position
+	for asset, b := range bals {
+		if asset == k.quote || !b.Balance.IsPositive() {
+			continue
+		}
+		symbol := asset + "/" + k.quote
+		price, err := k.GetPrice(ctx, symbol)
+		if err != nil || !price.IsPositive() {
+			continue // Dust in assets without a KRAKEN_QUOTE pair
+		}
+		value := b.Balance.Mul(price)
+		positions = append(positions, alpaca.Position{
+			AssetID:       asset,
+			Symbol:        symbol,
+			Exchange:      "KRAKEN",
+			AssetClass:    alpaca.Crypto,
+			Qty:           b.Balance,
+			QtyAvailable:  b.Balance.Sub(b.HoldTrade),
+			AvgEntryPrice: price,
+			Side:          "long",
+			MarketValue:   &value,
+			CostBasis:     value,
+			CurrentPrice:  &price,
+		})
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions, nil
+}
+
+// CarryRate returns the staking yield of ticker's base asset, in annual %: the lowest
+// Earn APR estimate for the asset, weighted by the share of the balance allocated to
+// Earn (the "ETH.B"/"ETH.F" variants). Zero when nothing is staked. Spot holdings pay
+// no funding, so the rate is never negative here.
+func (k *KrakenProvider) CarryRate(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	base, _, _ := strings.Cut(strings.ToUpper(ticker), "/")
+	var raw map[string]krakenBalance
+	if err := k.private(ctx, "BalanceEx", nil, &raw); err != nil {
+		return decimal.Zero, err
+	}
+	var total, earning decimal.Decimal
+	for code, b := range raw {
+		if krakenAsset(code) != base {
+			continue
+		}
+		total = total.Add(b.Balance)
+		if strings.Contains(code, ".") {
+			earning = earning.Add(b.Balance)
+		}
+	}
+	if !earning.IsPositive() {
+		return decimal.Zero, nil
+	}
+
+	var res struct {
+		Items []struct {
+			Asset       string `json:"asset"`
+			APREstimate *struct {
+				Low decimal.Decimal `json:"low"`
+			} `json:"apr_estimate"`
+		} `json:"items"`
+	}
+	if err := k.private(ctx, "Earn/Strategies", url.Values{"asset": {krakenCode(base)}}, &res); err != nil {
+		return decimal.Zero, err
+	}
+	var apr decimal.Decimal
+	found := false
+	for _, it := range res.Items {
+		if krakenAsset(it.Asset) != base || it.APREstimate == nil {
+			continue
+		}
+		if !found || it.APREstimate.Low.LessThan(apr) {
+			apr, found = it.APREstimate.Low, true
+		}
+	}
+	if !found {
+		return decimal.Zero, nil
+	}
+	return apr.Mul(earning).Div(total), nil
+}
+
+// ReplaceOrder is not supported: Kraken has no linked exits to patch, and crypto
+// levels are monitored locally.
+func (k *KrakenProvider) ReplaceOrder(ctx context.Context, orderID string, opts ReplaceOptions) (*alpaca.Order, error) {
+	return nil, fmt.Errorf("kraken: order replacement is not supported")
+}
+
+// GetNews is not supported: Kraken has no news feed.
+func (k *KrakenProvider) GetNews(ctx context.Context, ticker string, limit int) ([]marketdata.News, error) {
+	return nil, fmt.Errorf("news: %w", errKrakenUnsupported)
+}
+
+// GetCorporateActions returns nothing: crypto pairs have no splits or dividends.
+func (k *KrakenProvider) GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) ([]CorporateAction, error) {
+	return nil, nil
+}
+
+// CancelOrder cancels an order by txid.
+func (k *KrakenProvider) CancelOrder(ctx context.Context, orderID string) error {
+	return k.private(ctx, "CancelOrder", url.Values{"txid": {orderID}}, nil)
+}
+
+// --- Watchlists (Alpaca-only) ---
+
+// GetWatchlistByName is not offered by Kraken.
+func (k *KrakenProvider) GetWatchlistByName(ctx context.Context, name string) (*alpaca.Watchlist, error) {
+	return nil, fmt.Errorf("server-side watchlists: %w", errKrakenUnsupported)
+}
+
+// CreateWatchlist is not offered by Kraken.
+func (k *KrakenProvider) CreateWatchlist(ctx context.Context, name string, symbols []string) (*alpaca.Watchlist, error) {
+	return nil, fmt.Errorf("server-side watchlists: %w", errKrakenUnsupported)
+}
+
+// AddToWatchlist is not offered by Kraken.
+func (k *KrakenProvider) AddToWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	return fmt.Errorf("server-side watchlists: %w", errKrakenUnsupported)
+}
+
+// RemoveFromWatchlist is not offered by Kraken.
+func (k *KrakenProvider) RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	return fmt.Errorf("server-side watchlists: %w", errKrakenUnsupported)
+}
+
+// Compile-time check that the providers satisfy the interface.
+var (
+	_ MarketProvider = (*AlpacaProvider)(nil)
+	_ MarketProvider = (*KrakenProvider)(nil)
+	_ MarketProvider = (*FallbackProvider)(nil)
+	_ MarketProvider = (*RateLimitedProvider)(nil)
+	_ MarketProvider = (*CachedProvider)(nil)
+	_ MarketProvider = (*RetryProvider)(nil)
+)
@@ -0,0 +1,29 @@
+package market
+
+import "testing"
+
+func TestKrakenClientID(t *testing.T) {
+	cases := []struct {
+		id   string
+		want string // As sent in cl_ord_id
+	}{
+		{"at-1760602479123456789", "atddjlhp5x6hhx"},
+		{"at-1", "at1"},
+		{"manual-7", "manual-7"},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.id, func(t *testing.T) {
+			got := krakenClientID(tc.id)
+			if len(got) > 18 {
+				t.Fatalf("krakenClientID(%q) = %q is longer than 18 characters", tc.id, got)
+			}
+			if tc.want != "" && got != tc.want {
+				t.Fatalf("krakenClientID(%q) = %q, want %q", tc.id, got, tc.want)
+			}
+			if back := clientIDFromKraken(got); back != tc.id {
+				t.Fatalf("round trip of %q gave %q", tc.id, back)
+			}
+		})
+	}
+}
@@ -1,9 +1,16 @@
 package market
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"alpha_trading/internal/symbols"
+
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
 	"github.com/shopspring/decimal"
@@ -14,25 +21,99 @@ import (
 // satisfies the interface. This allows us to swap out Alpaca for Kraken,
 // or a Mock for testing, without changing the code that *uses* the provider.
 type MarketProvider interface {
-	GetPrice(ticker string) (decimal.Decimal, error)
-	GetEquity() (decimal.Decimal, error)
-	GetClock() (*alpaca.Clock, error)
-	SearchAssets(query string) ([]alpaca.Asset, error)
-	PlaceOrder(ticker string, qty decimal.Decimal, side string) (*alpaca.Order, error)
-	GetOrder(orderID string) (*alpaca.Order, error)
-	ListOrders(status string) ([]alpaca.Order, error)
-	ListPositions() ([]alpaca.Position, error)
-	CancelOrder(orderID string) error
-	GetBuyingPower() (decimal.Decimal, error)
-	GetBars(ticker string, limit int) ([]marketdata.Bar, error)
-	GetPortfolioHistory(period string, timeframe string) (*alpaca.PortfolioHistory, error)
-	GetAccount() (*alpaca.Account, error)
+	GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error)
+	GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error)
+	GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error)
+	GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error)
+	GetEquity(ctx context.Context) (decimal.Decimal, error)
+	GetClock(ctx context.Context) (*alpaca.Clock, error)
+	GetCalendar(ctx context.Context, start, end time.Time) ([]alpaca.CalendarDay, error)
+	SearchAssets(ctx context.Context, query string) ([]alpaca.Asset, error)
+	GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error)
+	PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (*alpaca.Order, error)
+	ReplaceOrder(ctx context.Context, orderID string, opts ReplaceOptions) (*alpaca.Order, error)
+	GetOrder(ctx context.Context, orderID string) (*alpaca.Order, error)
+	GetOrderByClientID(ctx context.Context, clientOrderID string) (*alpaca.Order, error)
+	ListOrders(ctx context.Context, status string) ([]alpaca.Order, error)
+	ListPositions(ctx context.Context) ([]alpaca.Position, error)
+	CancelOrder(ctx context.Context, orderID string) error
+	GetBuyingPower(ctx context.Context) (decimal.Decimal, error)
+	GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error)
+	GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error)
+	GetPortfolioHistory(ctx context.Context, period string, timeframe string) (*alpaca.PortfolioHistory, error)
+	GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) ([]CorporateAction, error)
+	GetNews(ctx context.Context, ticker string, limit int) ([]marketdata.News, error)
+	GetAccount(ctx context.Context) (*alpaca.Account, error)
+	GetWatchlistByName(ctx context.Context, name string) (*alpaca.Watchlist, error)
+	CreateWatchlist(ctx context.Context, name string, symbols []string) (*alpaca.Watchlist, error)
+	AddToWatchlist(ctx context.Context, watchlistID, symbol string) error
+	RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) error
+}
+
+// LastPriceSource serves last-known prices without an API call (a price cache today,
+// a streaming feed when one is wired in). Callers decide whether a price is fresh enough.
+type LastPriceSource interface {
+	LastPrice(ticker string) (price decimal.Decimal, at time.Time, ok bool)
+}
+
+// findCapability walks the wrapper chain (Unwrap) and returns the outermost provider
+// that implements the optional interface T.
+func findCapability[T any](p MarketProvider) (T, bool) {
+	for p != nil {
+		if c, ok := p.(T); ok {
+			return c, true
+		}
+		u, ok := p.(interface{ Unwrap() MarketProvider })
+		if !ok {
+			break
+		}
+		p = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// FindLastPriceSource returns the outermost provider in the chain that implements
+// LastPriceSource, or nil.
+func FindLastPriceSource(p MarketProvider) LastPriceSource {
+	src, _ := findCapability[LastPriceSource](p)
+	return src
+}
+
+// CarrySource reports what holding a crypto position earns or costs beyond its price:
+// the annual rate in % (positive = staking yield, negative = funding/borrow cost).
+// Zero means no carry.
+type CarrySource interface {
+	CarryRate(ctx context.Context, ticker string) (decimal.Decimal, error)
+}
+
+// FindCarrySource returns the outermost provider in the chain that implements
+// CarrySource, or nil.
+func FindCarrySource(p MarketProvider) CarrySource {
+	src, _ := findCapability[CarrySource](p)
+	return src
 }
 
 // AlpacaProvider is a concrete implementation of MarketProvider for the Alpaca API.
 type AlpacaProvider struct {
+	mu          sync.RWMutex       // Guards the clients against a concurrent Use (account switch)
 	mdClient    *marketdata.Client // Client for market data (prices)
 	tradeClient *alpaca.Client     // Client for trading data (account equity)
+	switched    chan struct{}      // Closed (and replaced) by Use, so long-lived streams reconnect
+	baseURL     string             // Trading endpoint of the account in use (paper or live)
+}
+
+// Alpaca trading endpoints.
+const (
+	AlpacaPaperURL = "https://paper-api.alpaca.markets"
+	AlpacaLiveURL  = "https://api.alpaca.markets"
+)
+
+// AlpacaCredentials identify one Alpaca account (paper or live).
+type AlpacaCredentials struct {
+	KeyID   string
+	Secret  string
+	BaseURL string
 }
 
 // NewAlpacaProvider is a "Constructor" function.
@@ -43,85 +124,234 @@ func NewAlpacaProvider() *AlpacaProvider {
 		// They automatically look for API keys in the environment variables we checked in config.
 		mdClient:    marketdata.NewClient(marketdata.ClientOpts{}),
 		tradeClient: alpaca.NewClient(alpaca.ClientOpts{}),
+		baseURL:     os.Getenv("APCA_API_BASE_URL"),
 	}
 }
 
-// GetPrice fetches the latest trade price for a ticker.
-// Note the receiver (a *AlpacaProvider) - this makes it a method of the struct.
-func (a *AlpacaProvider) GetPrice(ticker string) (decimal.Decimal, error) {
-	// We ask for the latest trade.
-	trade, err := a.mdClient.GetLatestTrade(ticker, marketdata.GetLatestTradeRequest{})
-	if err != nil {
-		return decimal.Zero, err // Return 0 and the error if something fails
+// NewAlpacaProviderFor builds a provider for explicit credentials (e.g., to probe
+// an account before switching to it).
+func NewAlpacaProviderFor(c AlpacaCredentials) *AlpacaProvider {
+	a := &AlpacaProvider{}
+	a.setClients(c)
+	return a
+}
+
+// Use points the provider at another account. The process environment is left
+// alone: callers ask Paper for the account in use.
+func (a *AlpacaProvider) Use(c AlpacaCredentials) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.setClients(c)
+	if a.switched != nil {
+		close(a.switched)
+		a.switched = make(chan struct{})
 	}
-	if trade == nil {
-		return decimal.Zero, nil // Or a specific error like "no trade found"
+}
+
+func (a *AlpacaProvider) setClients(c AlpacaCredentials) {
+	a.mdClient = marketdata.NewClient(marketdata.ClientOpts{APIKey: c.KeyID, APISecret: c.Secret})
+	a.tradeClient = alpaca.NewClient(alpaca.ClientOpts{APIKey: c.KeyID, APISecret: c.Secret, BaseURL: c.BaseURL})
+	a.baseURL = c.BaseURL
+}
+
+// Paper reports whether the account in use is on Alpaca's paper endpoint.
+func (a *AlpacaProvider) Paper() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return strings.Contains(strings.ToLower(a.baseURL), "paper")
+}
+
+// switchedChan returns the channel the next Use will close.
+func (a *AlpacaProvider) switchedChan() chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.switched == nil {
+		a.switched = make(chan struct{})
 	}
-	return decimal.NewFromFloat(trade.Price), nil // Return the price and nil error if successful
+	return a.switched
+}
+
+func (a *AlpacaProvider) md() *marketdata.Client {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.mdClient
+}
+
+func (a *AlpacaProvider) trade() *alpaca.Client {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.tradeClient
+}
+
+// GetPrice fetches the latest trade price for a ticker.
+// Note the receiver (a *AlpacaProvider) - this makes it a method of the struct.
+func (a *AlpacaProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	return await(ctx, func() (decimal.Decimal, error) {
+		if symbols.IsCrypto(ticker) {
+			p, err := a.cryptoPrice(ticker)
+			return decimal.NewFromFloat(p), err
+		}
+		// We ask for the latest trade.
+		trade, err := a.md().GetLatestTrade(ticker, marketdata.GetLatestTradeRequest{})
+		if err != nil {
+			return decimal.Zero, err // Return 0 and the error if something fails
+		}
+		if trade == nil {
+			return decimal.Zero, nil // Or a specific error like "no trade found"
+		}
+		return decimal.NewFromFloat(trade.Price), nil // Return the price and nil error if successful
+	})
+}
+
+// GetQuote fetches the latest NBBO quote (bid/ask) for a ticker.
+func (a *AlpacaProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	return await(ctx, func() (*marketdata.Quote, error) {
+		if symbols.IsCrypto(ticker) {
+			return a.cryptoQuote(ticker)
+		}
+		return a.md().GetLatestQuote(ticker, marketdata.GetLatestQuoteRequest{})
+	})
+}
+
+// GetSnapshot fetches latest trade, quote, minute bar, daily bar and previous daily bar in one call.
+func (a *AlpacaProvider) GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	return await(ctx, func() (*marketdata.Snapshot, error) {
+		if symbols.IsCrypto(ticker) {
+			return a.cryptoSnapshot(ticker)
+		}
+		return a.md().GetSnapshot(ticker, marketdata.GetSnapshotRequest{})
+	})
+}
+
+// GetSnapshots fetches snapshots for many tickers in one request per asset class.
+// Symbols the API has no data for are missing from the map.
+func (a *AlpacaProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	return await(ctx, func() (map[string]*marketdata.Snapshot, error) {
+		var equities, crypto []string
+		for _, t := range tickers {
+			if symbols.IsCrypto(t) {
+				crypto = append(crypto, t)
+			} else {
+				equities = append(equities, t)
+			}
+		}
+
+		out := make(map[string]*marketdata.Snapshot, len(tickers))
+		if len(equities) > 0 {
+			snaps, err := a.md().GetSnapshots(equities, marketdata.GetSnapshotRequest{})
+			if err != nil {
+				return nil, err
+			}
+			for t, s := range snaps {
+				if s != nil {
+					out[t] = s
+				}
+			}
+		}
+		if len(crypto) > 0 {
+			snaps, err := a.cryptoSnapshots(crypto)
+			if err != nil {
+				return nil, err
+			}
+			for t, s := range snaps {
+				out[t] = s
+			}
+		}
+		return out, nil
+	})
 }
 
 // GetEquity fetches the current total account equity.
-func (a *AlpacaProvider) GetEquity() (decimal.Decimal, error) {
-	acct, err := a.tradeClient.GetAccount()
-	if err != nil {
-		return decimal.Zero, err
-	}
-	// InexactFloat64 converts the decimal type to a standard float64.
-	return acct.Equity, nil
+func (a *AlpacaProvider) GetEquity(ctx context.Context) (decimal.Decimal, error) {
+	return await(ctx, func() (decimal.Decimal, error) {
+		acct, err := a.trade().GetAccount()
+		if err != nil {
+			return decimal.Zero, err
+		}
+		// InexactFloat64 converts the decimal type to a standard float64.
+		return acct.Equity, nil
+	})
 }
 
 // GetBuyingPower fetches the current buying power.
-func (a *AlpacaProvider) GetBuyingPower() (decimal.Decimal, error) {
-	acct, err := a.tradeClient.GetAccount()
-	if err != nil {
-		return decimal.Zero, err
-	}
-	return acct.BuyingPower, nil
+func (a *AlpacaProvider) GetBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+	return await(ctx, func() (decimal.Decimal, error) {
+		acct, err := a.trade().GetAccount()
+		if err != nil {
+			return decimal.Zero, err
+		}
+		return acct.BuyingPower, nil
+	})
 }
 
 // GetClock fetches the market clock (open/close status).
-func (a *AlpacaProvider) GetClock() (*alpaca.Clock, error) {
-	return a.tradeClient.GetClock()
+func (a *AlpacaProvider) GetClock(ctx context.Context) (*alpaca.Clock, error) {
+	return await(ctx, func() (*alpaca.Clock, error) {
+		return a.trade().GetClock()
+	})
+}
+
+// GetCalendar returns the trading sessions between start and end (inclusive).
+// Holidays are absent; half days carry an early close time.
+func (a *AlpacaProvider) GetCalendar(ctx context.Context, start, end time.Time) ([]alpaca.CalendarDay, error) {
+	return await(ctx, func() ([]alpaca.CalendarDay, error) {
+		return a.trade().GetCalendar(alpaca.GetCalendarRequest{Start: start, End: end})
+	})
 }
 
 // SearchAssets searches for assets matching the query string.
 // It fetches active US equities and filters them in memory.
 // Returns a maximum of 5 results.
-func (a *AlpacaProvider) SearchAssets(query string) ([]alpaca.Asset, error) {
-	status := "active"
-	class := "us_equity"
-	assets, err := a.tradeClient.GetAssets(alpaca.GetAssetsRequest{
-		Status:     status,
-		AssetClass: class,
-	})
-	if err != nil {
-		return nil, err
-	}
+func (a *AlpacaProvider) SearchAssets(ctx context.Context, query string) ([]alpaca.Asset, error) {
+	return await(ctx, func() ([]alpaca.Asset, error) {
+		status := "active"
+		class := "us_equity"
+		assets, err := a.trade().GetAssets(alpaca.GetAssetsRequest{
+			Status:     status,
+			AssetClass: class,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	var results []alpaca.Asset
-	queryLower := strings.ToLower(query)
+		var results []alpaca.Asset
+		queryLower := strings.ToLower(query)
 
-	for _, asset := range assets {
-		if strings.Contains(strings.ToLower(asset.Symbol), queryLower) ||
-			strings.Contains(strings.ToLower(asset.Name), queryLower) {
-			results = append(results, asset)
-			if len(results) >= 5 {
-				break
+		for _, asset := range assets {
+			if strings.Contains(strings.ToLower(asset.Symbol), queryLower) ||
+				strings.Contains(strings.ToLower(asset.Name), queryLower) {
+				results = append(results, asset)
+				if len(results) >= 5 {
+					break
+				}
 			}
 		}
-	}
-	return results, nil
+		return results, nil
+	})
+}
+
+// GetAsset fetches a single asset by symbol (used to validate symbols before order placement).
+// Crypto pairs are looked up without the slash (BTC/USD -> BTCUSD), which would split the URL path.
+func (a *AlpacaProvider) GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error) {
+	return await(ctx, func() (*alpaca.Asset, error) {
+		if symbols.IsCrypto(ticker) {
+			ticker = strings.ReplaceAll(ticker, "/", "")
+		}
+		return a.trade().GetAsset(ticker)
+	})
 }
 
 // GetBars fetches historical bars for a ticker.
-func (a *AlpacaProvider) GetBars(ticker string, limit int) ([]marketdata.Bar, error) {
+func (a *AlpacaProvider) GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error) {
 	// Request last 5 days to ensure we get at least one previous close (handling weekends/holidays)
-	start := time.Now().AddDate(0, 0, -5)
+	// Larger limits scale the window (~7 calendar days per 5 trading days, plus holiday slack).
+	days := 5
+	if scaled := limit*7/5 + 5; scaled > days {
+		days = scaled
+	}
+	start := time.Now().AddDate(0, 0, -days)
 
-	bars, err := a.mdClient.GetBars(ticker, marketdata.GetBarsRequest{
-		TimeFrame: marketdata.OneDay,
-		Start:     start,
-	})
+	bars, err := a.GetBarsRange(ctx, ticker, "1D", start, time.Time{})
 	if err != nil {
 		return nil, err
 	}
@@ -134,15 +364,141 @@ func (a *AlpacaProvider) GetBars(ticker string, limit int) ([]marketdata.Bar, er
 	return bars, nil
 }
 
+// GetBarsRange fetches bars at any supported timeframe (see ParseTimeFrame) between start and end.
+// A zero end means "up to now". Used by indicators, charts and backtests that need intraday data.
+func (a *AlpacaProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error) {
+	return await(ctx, func() ([]marketdata.Bar, error) {
+		tf, err := ParseTimeFrame(timeframe)
+		if err != nil {
+			return nil, err
+		}
+		if !end.IsZero() && !end.After(start) {
+			return nil, fmt.Errorf("invalid bar range: end %s is not after start %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+		}
+		if symbols.IsCrypto(ticker) {
+			return a.cryptoBars(ticker, tf, start, end)
+		}
+
+		return a.md().GetBars(ticker, marketdata.GetBarsRequest{
+			TimeFrame: tf,
+			Start:     start,
+			End:       end,
+		})
+	})
+}
+
+// ParseTimeFrame converts strings like "1Min", "5Min", "15Min", "1H", "1D" or "1W" to a bar timeframe.
+func ParseTimeFrame(s string) (marketdata.TimeFrame, error) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil || n <= 0 {
+		return marketdata.TimeFrame{}, fmt.Errorf("invalid timeframe %q", s)
+	}
+
+	var unit marketdata.TimeFrameUnit
+	max := 1
+	switch strings.ToLower(s[i:]) {
+	case "min", "t":
+		unit, max = marketdata.Min, 59
+	case "h", "hour":
+		unit, max = marketdata.Hour, 23
+	case "d", "day":
+		unit = marketdata.Day
+	case "w", "week":
+		unit = marketdata.Week
+	default:
+		return marketdata.TimeFrame{}, fmt.Errorf("invalid timeframe unit in %q (use Min, H, D or W)", s)
+	}
+	if n > max {
+		return marketdata.TimeFrame{}, fmt.Errorf("timeframe %q out of range (max %d%s)", s, max, unit)
+	}
+	return marketdata.NewTimeFrame(n, unit), nil
+}
+
+// CashInterest sums the interest credited to the account (INT activities) in [start, end).
+func (a *AlpacaProvider) CashInterest(ctx context.Context, start, end time.Time) (decimal.Decimal, error) {
+	return await(ctx, func() (decimal.Decimal, error) {
+		total := decimal.Zero
+		req := alpaca.GetAccountActivitiesRequest{
+			ActivityTypes: []string{"INT"},
+			After:         start,
+			Until:         end,
+			Direction:     "asc",
+			PageSize:      100,
+		}
+		for {
+			page, err := a.trade().GetAccountActivities(req)
+			if err != nil {
+				return decimal.Zero, err
+			}
+			for _, act := range page {
+				total = total.Add(act.NetAmount)
+			}
+			if len(page) < req.PageSize {
+				return total, nil
+			}
+			req.PageToken = page[len(page)-1].ID
+		}
+	})
+}
+
 // GetPortfolioHistory fetches the portfolio history for a specific period and timeframe.
-func (a *AlpacaProvider) GetPortfolioHistory(period string, timeframe string) (*alpaca.PortfolioHistory, error) {
-	return a.tradeClient.GetPortfolioHistory(alpaca.GetPortfolioHistoryRequest{
-		Period:    period,
-		TimeFrame: alpaca.TimeFrame(timeframe),
+func (a *AlpacaProvider) GetPortfolioHistory(ctx context.Context, period string, timeframe string) (*alpaca.PortfolioHistory, error) {
+	return await(ctx, func() (*alpaca.PortfolioHistory, error) {
+		return a.trade().GetPortfolioHistory(alpaca.GetPortfolioHistoryRequest{
+			Period:    period,
+			TimeFrame: alpaca.TimeFrame(timeframe),
+		})
 	})
 }
 
 // GetAccount fetches the full account object.
-func (a *AlpacaProvider) GetAccount() (*alpaca.Account, error) {
-	return a.tradeClient.GetAccount()
+func (a *AlpacaProvider) GetAccount(ctx context.Context) (*alpaca.Account, error) {
+	return await(ctx, func() (*alpaca.Account, error) {
+		return a.trade().GetAccount()
+	})
+}
+
+// GetWatchlistByName returns the server-side watchlist with the given name (including its assets),
+// or nil if no such list exists.
+func (a *AlpacaProvider) GetWatchlistByName(ctx context.Context, name string) (*alpaca.Watchlist, error) {
+	return await(ctx, func() (*alpaca.Watchlist, error) {
+		lists, err := a.trade().GetWatchlists()
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range lists {
+			if strings.EqualFold(l.Name, name) {
+				// The list endpoint omits assets; fetch the full watchlist.
+				return a.trade().GetWatchlist(l.ID)
+			}
+		}
+		return nil, nil
+	})
+}
+
+// CreateWatchlist creates a server-side watchlist.
+func (a *AlpacaProvider) CreateWatchlist(ctx context.Context, name string, symbols []string) (*alpaca.Watchlist, error) {
+	return await(ctx, func() (*alpaca.Watchlist, error) {
+		return a.trade().CreateWatchlist(alpaca.CreateWatchlistRequest{Name: name, Symbols: symbols})
+	})
+}
+
+// AddToWatchlist adds a symbol to a server-side watchlist.
+func (a *AlpacaProvider) AddToWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	return awaitErr(ctx, func() error {
+		_, err := a.trade().AddSymbolToWatchlist(watchlistID, alpaca.AddSymbolToWatchlistRequest{Symbol: symbol})
+		return err
+	})
+}
+
+// RemoveFromWatchlist removes a symbol from a server-side watchlist.
+func (a *AlpacaProvider) RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	return awaitErr(ctx, func() error {
+		return a.trade().RemoveSymbolFromWatchlist(watchlistID, alpaca.RemoveSymbolFromWatchlistRequest{Symbol: symbol})
+	})
 }
@@ -0,0 +1,287 @@
+package market
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+const metricsSamples = 200 // Recent latencies kept per method for percentiles
+
+// MethodStats is the record of one provider method since the last reset.
+type MethodStats struct {
+	Method    string
+	Calls     int
+	Errors    int
+	Total     time.Duration
+	Max       time.Duration
+	P50, P95  time.Duration // Over the last metricsSamples calls
+	LastError string
+	LastErrAt time.Time
+}
+
+// Avg is the mean latency.
+func (s MethodStats) Avg() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// ErrorRate is the share of failed calls, in percent.
+func (s MethodStats) ErrorRate() float64 {
+	if s.Calls == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Calls) * 100
+}
+
+type methodStats struct {
+	MethodStats
+	samples []time.Duration // Ring buffer
+	next    int
+}
+
+// MetricsProvider records call counts, latencies and errors per method. It wraps the
+// broker provider directly, so the numbers are the endpoints' own: rate-limit waits,
+// retries' backoff and cache hits are not included, and each retry attempt counts as a call.
+type MetricsProvider struct {
+	MarketProvider
+	slow  time.Duration // Calls at least this slow are logged; 0 = never
+	mu    sync.Mutex
+	since time.Time
+	stats map[string]*methodStats
+}
+
+// WithMetrics wraps p.
+func WithMetrics(p MarketProvider, slow time.Duration) *MetricsProvider {
+	return &MetricsProvider{MarketProvider: p, slow: slow, since: time.Now(), stats: make(map[string]*methodStats)}
+}
+
+// Unwrap returns the measured provider.
+func (m *MetricsProvider) Unwrap() MarketProvider {
+	return m.MarketProvider
+}
+
+// observe records a call that started at start and failed with *err (if non-nil).
+func (m *MetricsProvider) observe(method string, start time.Time, err *error) {
+	d := time.Since(start)
+	if m.slow > 0 && d >= m.slow {
+		log.Printf("[SLOW_CALL] %s took %s (err: %v)", method, d.Round(time.Millisecond), *err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.stats[method]
+	if !ok {
+		s = &methodStats{MethodStats: MethodStats{Method: method}}
+		m.stats[method] = s
+	}
+	s.Calls++
+	s.Total += d
+	s.Max = max(s.Max, d)
+	if *err != nil {
+		s.Errors++
+		s.LastError = (*err).Error()
+		s.LastErrAt = time.Now()
+	}
+	if len(s.samples) < metricsSamples {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % metricsSamples
+	}
+}
+
+// Snapshot returns the stats per method, the most total time first, and when
+// recording started.
+func (m *MetricsProvider) Snapshot() ([]MethodStats, time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]MethodStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		st := s.MethodStats
+		sorted := append([]time.Duration{}, s.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		st.P50 = percentileDuration(sorted, 0.50)
+		st.P95 = percentileDuration(sorted, 0.95)
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Total > out[j].Total })
+	return out, m.since
+}
+
+// Totals returns the calls, errors and time spent in calls since the last reset
+// (cheap enough to diff around a poll).
+func (m *MetricsProvider) Totals() (calls, errors int, total time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.stats {
+		calls += s.Calls
+		errors += s.Errors
+		total += s.Total
+	}
+	return calls, errors, total
+}
+
+// Reset clears the stats.
+func (m *MetricsProvider) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats = make(map[string]*methodStats)
+	m.since = time.Now()
+}
+
+// percentileDuration reads the q-quantile (nearest rank) of sorted durations.
+func percentileDuration(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(q*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(idx, 0), len(sorted)-1)]
+}
+
+// --- Instrumented calls ---
+
+func (m *MetricsProvider) GetPrice(ctx context.Context, ticker string) (v decimal.Decimal, err error) {
+	defer m.observe("GetPrice", time.Now(), &err)
+	return m.MarketProvider.GetPrice(ctx, ticker)
+}
+
+func (m *MetricsProvider) GetQuote(ctx context.Context, ticker string) (v *marketdata.Quote, err error) {
+	defer m.observe("GetQuote", time.Now(), &err)
+	return m.MarketProvider.GetQuote(ctx, ticker)
+}
+
+func (m *MetricsProvider) GetSnapshot(ctx context.Context, ticker string) (v *marketdata.Snapshot, err error) {
+	defer m.observe("GetSnapshot", time.Now(), &err)
+	return m.MarketProvider.GetSnapshot(ctx, ticker)
+}
+
+func (m *MetricsProvider) GetSnapshots(ctx context.Context, tickers []string) (v map[string]*marketdata.Snapshot, err error) {
+	defer m.observe("GetSnapshots", time.Now(), &err)
+	return m.MarketProvider.GetSnapshots(ctx, tickers)
+}
+
+func (m *MetricsProvider) GetEquity(ctx context.Context) (v decimal.Decimal, err error) {
+	defer m.observe("GetEquity", time.Now(), &err)
+	return m.MarketProvider.GetEquity(ctx)
+}
+
+func (m *MetricsProvider) GetClock(ctx context.Context) (v *alpaca.Clock, err error) {
+	defer m.observe("GetClock", time.Now(), &err)
+	return m.MarketProvider.GetClock(ctx)
+}
+
+func (m *MetricsProvider) GetCalendar(ctx context.Context, start, end time.Time) (v []alpaca.CalendarDay, err error) {
+	defer m.observe("GetCalendar", time.Now(), &err)
+	return m.MarketProvider.GetCalendar(ctx, start, end)
+}
+
+func (m *MetricsProvider) SearchAssets(ctx context.Context, query string) (v []alpaca.Asset, err error) {
+	defer m.observe("SearchAssets", time.Now(), &err)
+	return m.MarketProvider.SearchAssets(ctx, query)
+}
+
+func (m *MetricsProvider) GetAsset(ctx context.Context, ticker string) (v *alpaca.Asset, err error) {
+	defer m.observe("GetAsset", time.Now(), &err)
+	return m.MarketProvider.GetAsset(ctx, ticker)
+}
+
+func (m *MetricsProvider) PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (v *alpaca.Order, err error) {
+	defer m.observe("PlaceOrder", time.Now(), &err)
+	return m.MarketProvider.PlaceOrder(ctx, ticker, qty, side, opts...)
+}
+
+func (m *MetricsProvider) ReplaceOrder(ctx context.Context, orderID string, opts ReplaceOptions) (v *alpaca.Order, err error) {
+	defer m.observe("ReplaceOrder", time.Now(), &err)
+	return m.MarketProvider.ReplaceOrder(ctx, orderID, opts)
+}
+
+func (m *MetricsProvider) GetOrder(ctx context.Context, orderID string) (v *alpaca.Order, err error) {
+	defer m.observe("GetOrder", time.Now(), &err)
+	return m.MarketProvider.GetOrder(ctx, orderID)
+}
+
+func (m *MetricsProvider) GetOrderByClientID(ctx context.Context, clientOrderID string) (v *alpaca.Order, err error) {
+	defer m.observe("GetOrderByClientID", time.Now(), &err)
+	return m.MarketProvider.GetOrderByClientID(ctx, clientOrderID)
+}
+
+func (m *MetricsProvider) ListOrders(ctx context.Context, status string) (v []alpaca.Order, err error) {
+	defer m.observe("ListOrders", time.Now(), &err)
+	return m.MarketProvider.ListOrders(ctx, status)
+}
+
+func (m *MetricsProvider) ListPositions(ctx context.Context) (v []alpaca.Position, err error) {
+	defer m.observe("ListPositions", time.Now(), &err)
+	return m.MarketProvider.ListPositions(ctx)
+}
+
+func (m *MetricsProvider) CancelOrder(ctx context.Context, orderID string) (err error) {
+	defer m.observe("CancelOrder", time.Now(), &err)
+	return m.MarketProvider.CancelOrder(ctx, orderID)
+}
+
+func (m *MetricsProvider) GetBuyingPower(ctx context.Context) (v decimal.Decimal, err error) {
+	defer m.observe("GetBuyingPower", time.Now(), &err)
+	return m.MarketProvider.GetBuyingPower(ctx)
+}
+
+func (m *MetricsProvider) GetBars(ctx context.Context, ticker string, limit int) (v []marketdata.Bar, err error) {
+	defer m.observe("GetBars", time.Now(), &err)
+	return m.MarketProvider.GetBars(ctx, ticker, limit)
+}
+
+func (m *MetricsProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) (v []marketdata.Bar, err error) {
+	defer m.observe("GetBarsRange", time.Now(), &err)
+	return m.MarketProvider.GetBarsRange(ctx, ticker, timeframe, start, end)
+}
+
+func (m *MetricsProvider) GetPortfolioHistory(ctx context.Context, period string, timeframe string) (v *alpaca.PortfolioHistory, err error) {
+	defer m.observe("GetPortfolioHistory", time.Now(), &err)
+	return m.MarketProvider.GetPortfolioHistory(ctx, period, timeframe)
+}
+
+func (m *MetricsProvider) GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) (v []CorporateAction, err error) {
+	defer m.observe("GetCorporateActions", time.Now(), &err)
+	return m.MarketProvider.GetCorporateActions(ctx, symbols, start, end)
+}
+
+func (m *MetricsProvider) GetNews(ctx context.Context, ticker string, limit int) (v []marketdata.News, err error) {
+	defer m.observe("GetNews", time.Now(), &err)
+	return m.MarketProvider.GetNews(ctx, ticker, limit)
+}
+
+func (m *MetricsProvider) GetAccount(ctx context.Context) (v *alpaca.Account, err error) {
+	defer m.observe("GetAccount", time.Now(), &err)
+	return m.MarketProvider.GetAccount(ctx)
+}
+
+func (m *MetricsProvider) GetWatchlistByName(ctx context.Context, name string) (v *alpaca.Watchlist, err error) {
+	defer m.observe("GetWatchlistByName", time.Now(), &err)
+	return m.MarketProvider.GetWatchlistByName(ctx, name)
+}
+
+func (m *MetricsProvider) CreateWatchlist(ctx context.Context, name string, symbols []string) (v *alpaca.Watchlist, err error) {
+	defer m.observe("CreateWatchlist", time.Now(), &err)
+	return m.MarketProvider.CreateWatchlist(ctx, name, symbols)
+}
+
+func (m *MetricsProvider) AddToWatchlist(ctx context.Context, watchlistID, symbol string) (err error) {
+	defer m.observe("AddToWatchlist", time.Now(), &err)
+	return m.MarketProvider.AddToWatchlist(ctx, watchlistID, symbol)
+}
+
+func (m *MetricsProvider) RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) (err error) {
+	defer m.observe("RemoveFromWatchlist", time.Now(), &err)
+	return m.MarketProvider.RemoveFromWatchlist(ctx, watchlistID, symbol)
+}
+
+var _ MarketProvider = (*MetricsProvider)(nil)
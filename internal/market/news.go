@@ -0,0 +1,25 @@
+package market
+
+import (
+	"context"
+	"strings"
+
+	"alpha_trading/internal/symbols"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// GetNews fetches the latest limit articles about ticker, newest first.
+func (a *AlpacaProvider) GetNews(ctx context.Context, ticker string, limit int) ([]marketdata.News, error) {
+	return await(ctx, func() ([]marketdata.News, error) {
+		symbol := ticker
+		if symbols.IsCrypto(ticker) {
+			symbol = strings.ReplaceAll(ticker, "/", "") // The news feed tags pairs as BTCUSD
+		}
+		return a.md().GetNews(marketdata.GetNewsRequest{
+			Symbols:    []string{symbol},
+			Sort:       marketdata.SortDesc,
+			TotalLimit: limit,
+		})
+	})
+}
@@ -1,43 +1,289 @@
 package market
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
 	"github.com/shopspring/decimal"
 )
 
-// PlaceOrder executes a market order.
-// Side should be "buy" or "sell".
-// Side should be "buy" or "sell".
-func (a *AlpacaProvider) PlaceOrder(ticker string, qty decimal.Decimal, side string) (*alpaca.Order, error) {
-	req := alpaca.PlaceOrderRequest{
-		Symbol:      ticker,
-		Qty:         &qty,
-		Side:        alpaca.Side(side),
-		Type:        alpaca.Market,
-		TimeInForce: alpaca.Day,
+// OrderOptions adjusts PlaceOrder. The zero value is a regular-session market order.
+type OrderOptions struct {
+	LimitPrice    decimal.Decimal // Zero = market order
+	TimeInForce   string          // "day" (default) or "gtc"
+	ExtendedHours bool            // Eligible for pre/post-market (Alpaca requires a DAY limit order)
+
+	// Linked orders (Alpaca only). "oco" closes a position with a TP limit and an SL stop,
+	// one cancelling the other; "oto" attaches one exit leg (TP or SL) to an entry order.
+	OrderClass string          // "" (simple), "oco" or "oto"
+	TakeProfit decimal.Decimal // Take-profit leg limit price; zero = none
+	StopLoss   decimal.Decimal // Stop-loss leg stop price; zero = none
+
+	TrailPercent decimal.Decimal // Native trailing stop (Alpaca "trailing_stop"), in %; zero = none
+
+	// Notional buys a dollar amount instead of qty (fractional shares). Alpaca takes
+	// it as is (DAY, simple orders only); Kraken converts it to a volume at the price.
+	Notional decimal.Decimal // Zero = use qty
+
+	ClientOrderID string // Our own order ID (the intent journal); Kraken gets it shortened as cl_ord_id; "" = none
+}
+
+// ReplaceOptions are the fields ReplaceOrder changes on a resting order (including
+// one leg of an OCO/bracket). Zero values keep the current value.
+type ReplaceOptions struct {
+	Qty          decimal.Decimal
+	LimitPrice   decimal.Decimal // Limit orders and take-profit legs
+	StopPrice    decimal.Decimal // Stop orders and stop-loss legs
+	TrailPercent decimal.Decimal // Trailing stop orders, in %
+}
+
+// orderOptions returns the first option set, or the zero value.
+func orderOptions(opts []OrderOptions) OrderOptions {
+	if len(opts) == 0 {
+		return OrderOptions{}
 	}
-	return a.tradeClient.PlaceOrder(req)
+	return opts[0]
+}
+
+// PlaceOrder executes a market order, or a limit order when opts carry a LimitPrice.
+// Side should be "buy" or "sell". With a Notional amount qty is ignored. The limit is snapped to the equity tick (cents,
+// sub-penny below $1) so percentage math like 142.4999997 is not rejected.
+func (a *AlpacaProvider) PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (*alpaca.Order, error) {
+	return await(ctx, func() (*alpaca.Order, error) {
+		o := orderOptions(opts)
+		o.LimitPrice = money.OrderPrice(ticker, side, o.LimitPrice)
+		tif := alpaca.Day
+		if o.TimeInForce != "" {
+			tif = alpaca.TimeInForce(strings.ToLower(o.TimeInForce))
+		}
+		if symbols.IsCrypto(ticker) {
+			// Crypto trades 24/7: no DAY orders and no extended-hours flag
+			if tif == alpaca.Day {
+				tif = alpaca.GTC
+			}
+			o.ExtendedHours = false
+		}
+		if o.ExtendedHours && (!o.LimitPrice.IsPositive() || tif != alpaca.Day) {
+			return nil, fmt.Errorf("extended-hours orders require a DAY limit order")
+		}
+		req := alpaca.PlaceOrderRequest{
+			Symbol:        ticker,
+			Qty:           &qty,
+			Side:          alpaca.Side(side),
+			Type:          alpaca.Market,
+			TimeInForce:   tif,
+			ExtendedHours: o.ExtendedHours,
+			ClientOrderID: o.ClientOrderID,
+		}
+		if o.LimitPrice.IsPositive() {
+			req.Type = alpaca.Limit
+			req.LimitPrice = &o.LimitPrice
+		}
+		if o.Notional.IsPositive() {
+			if (tif != alpaca.Day && !symbols.IsCrypto(ticker)) || o.OrderClass != "" || o.TrailPercent.IsPositive() {
+				return nil, fmt.Errorf("notional orders must be simple DAY orders")
+			}
+			notional := money.Cash(o.Notional)
+			req.Qty, req.Notional = nil, &notional
+		}
+		if o.TrailPercent.IsPositive() {
+			if o.LimitPrice.IsPositive() || o.OrderClass != "" || o.ExtendedHours {
+				return nil, fmt.Errorf("trailing stop orders cannot carry a limit, legs or extended hours")
+			}
+			req.Type = alpaca.TrailingStop
+			req.TrailPercent = &o.TrailPercent
+		}
+		if err := linkOrder(&req, o); err != nil {
+			return nil, err
+		}
+		return a.trade().PlaceOrder(req)
+	})
+}
+
+// linkOrder adds the OCO/OTO legs to req. Leg prices snap to the tick on the side
+// of the exit (sell legs round up, buy legs down).
+func linkOrder(req *alpaca.PlaceOrderRequest, o OrderOptions) error {
+	if o.OrderClass == "" {
+		return nil
+	}
+	exit := "sell"
+	if req.Side == alpaca.Sell {
+		exit = "buy" // Legs close a short
+	}
+	if strings.EqualFold(o.OrderClass, "oco") {
+		exit = string(req.Side) // The OCO order itself is the exit
+	}
+	var tp, sl *decimal.Decimal
+	if o.TakeProfit.IsPositive() {
+		v := money.OrderPrice(req.Symbol, exit, o.TakeProfit)
+		tp = &v
+	}
+	if o.StopLoss.IsPositive() {
+		v := money.OrderPrice(req.Symbol, exit, o.StopLoss)
+		sl = &v
+	}
+
+	switch strings.ToLower(o.OrderClass) {
+	case "oco":
+		if tp == nil || sl == nil {
+			return fmt.Errorf("oco orders need both a take-profit and a stop-loss price")
+		}
+		if req.ExtendedHours {
+			return fmt.Errorf("oco orders cannot trade extended hours")
+		}
+		req.OrderClass = alpaca.OCO
+		req.Type = alpaca.Limit
+		req.LimitPrice = nil // Prices live in the legs
+	case "oto":
+		if (tp == nil) == (sl == nil) {
+			return fmt.Errorf("oto orders need exactly one of take-profit or stop-loss")
+		}
+		req.OrderClass = alpaca.OTO
+	default:
+		return fmt.Errorf("unsupported order class %q", o.OrderClass)
+	}
+	if tp != nil {
+		req.TakeProfit = &alpaca.TakeProfit{LimitPrice: tp}
+	}
+	if sl != nil {
+		req.StopLoss = &alpaca.StopLoss{StopPrice: sl}
+	}
+	return nil
+}
+
+// ReplaceOrder patches a resting order in place. Alpaca cancels the original and
+// returns its replacement, which carries a new ID.
+func (a *AlpacaProvider) ReplaceOrder(ctx context.Context, orderID string, opts ReplaceOptions) (*alpaca.Order, error) {
+	return await(ctx, func() (*alpaca.Order, error) {
+		var req alpaca.ReplaceOrderRequest
+		if opts.Qty.IsPositive() {
+			req.Qty = &opts.Qty
+		}
+		if opts.LimitPrice.IsPositive() {
+			req.LimitPrice = &opts.LimitPrice
+		}
+		if opts.StopPrice.IsPositive() {
+			req.StopPrice = &opts.StopPrice
+		}
+		if opts.TrailPercent.IsPositive() {
+			req.Trail = &opts.TrailPercent
+		}
+		if req.Qty == nil && req.LimitPrice == nil && req.StopPrice == nil && req.Trail == nil {
+			return nil, fmt.Errorf("replace order %s: nothing to change", orderID)
+		}
+		return a.trade().ReplaceOrder(orderID, req)
+	})
 }
 
 // GetOrder fetches a specific order by its ID.
-func (a *AlpacaProvider) GetOrder(orderID string) (*alpaca.Order, error) {
-	return a.tradeClient.GetOrder(orderID)
+func (a *AlpacaProvider) GetOrder(ctx context.Context, orderID string) (*alpaca.Order, error) {
+	return await(ctx, func() (*alpaca.Order, error) {
+		return a.trade().GetOrder(orderID)
+	})
+}
+
+// ErrOrderNotFound is returned by GetOrderByClientID when the broker has no such order.
+var ErrOrderNotFound = errors.New("order not found")
+
+// GetOrderByClientID fetches the order placed under our client order ID, whatever
+// its age (ListOrders only sees the latest page).
+func (a *AlpacaProvider) GetOrderByClientID(ctx context.Context, clientOrderID string) (*alpaca.Order, error) {
+	return await(ctx, func() (*alpaca.Order, error) {
+		o, err := a.trade().GetOrderByClientOrderID(clientOrderID)
+		var apiErr *alpaca.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil, fmt.Errorf("client order %s: %w", clientOrderID, ErrOrderNotFound)
+		}
+		return o, err
+	})
 }
 
 // ListOrders fetches orders with a specific status (e.g., "open", "all").
-func (a *AlpacaProvider) ListOrders(status string) ([]alpaca.Order, error) {
-	return a.tradeClient.GetOrders(alpaca.GetOrdersRequest{
-		Status: status,
-		Limit:  100, // Reasonable limit
+func (a *AlpacaProvider) ListOrders(ctx context.Context, status string) ([]alpaca.Order, error) {
+	return await(ctx, func() ([]alpaca.Order, error) {
+		return a.trade().GetOrders(alpaca.GetOrdersRequest{
+			Status: status,
+			Limit:  100, // Reasonable limit
+		})
+	})
+}
+
+// FilledOrders fetches orders closed since since that filled at least partly, oldest first.
+func (a *AlpacaProvider) FilledOrders(ctx context.Context, since time.Time) ([]alpaca.Order, error) {
+	return await(ctx, func() ([]alpaca.Order, error) {
+		orders, err := a.trade().GetOrders(alpaca.GetOrdersRequest{
+			Status:    "closed",
+			After:     since,
+			Limit:     500,
+			Direction: "asc",
+		})
+		if err != nil {
+			return nil, err
+		}
+		var filled []alpaca.Order
+		for _, o := range orders {
+			if o.FilledAvgPrice == nil || !o.FilledQty.IsPositive() {
+				continue
+			}
+			if o.AssetClass == alpaca.Crypto {
+				o.Symbol = symbols.Normalize(o.Symbol)
+			}
+			filled = append(filled, o)
+		}
+		return filled, nil
+	})
+}
+
+// OptionAssetClass is Alpaca's asset class for option contracts (not in the SDK enum).
+const OptionAssetClass alpaca.AssetClass = "us_option"
+
+// ListPositions fetches all open equity and crypto positions. Crypto positions come back
+// as "BTCUSD" and are normalized to the canonical pair ("BTC/USD") used everywhere else.
+// Options are left out (see ListOptionPositions): their quantity is in contracts.
+func (a *AlpacaProvider) ListPositions(ctx context.Context) ([]alpaca.Position, error) {
+	return await(ctx, func() ([]alpaca.Position, error) {
+		positions, err := a.trade().GetPositions()
+		var out []alpaca.Position
+		for _, p := range positions {
+			switch p.AssetClass {
+			case OptionAssetClass:
+				continue
+			case alpaca.Crypto:
+				p.Symbol = symbols.Normalize(p.Symbol)
+			}
+			out = append(out, p)
+		}
+		return out, err
 	})
 }
 
-// ListPositions fetches all open positions.
-func (a *AlpacaProvider) ListPositions() ([]alpaca.Position, error) {
-	return a.tradeClient.GetPositions()
+// ListOptionPositions fetches the open option positions.
+func (a *AlpacaProvider) ListOptionPositions(ctx context.Context) ([]alpaca.Position, error) {
+	return await(ctx, func() ([]alpaca.Position, error) {
+		positions, err := a.trade().GetPositions()
+		if err != nil {
+			return nil, err
+		}
+		var out []alpaca.Position
+		for _, p := range positions {
+			if p.AssetClass == OptionAssetClass {
+				out = append(out, p)
+			}
+		}
+		return out, nil
+	})
 }
 
 // CancelOrder cancels a specific order by ID.
-func (a *AlpacaProvider) CancelOrder(orderID string) error {
-	return a.tradeClient.CancelOrder(orderID)
+func (a *AlpacaProvider) CancelOrder(ctx context.Context, orderID string) error {
+	return awaitErr(ctx, func() error {
+		return a.trade().CancelOrder(orderID)
+	})
 }
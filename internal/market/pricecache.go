@@ -0,0 +1,161 @@
+package market
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"alpha_trading/internal/clock"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+const priceCachePruneSize = 500 // Drop expired entries once the cache grows past this
+
+// CachedProvider serves repeated GetPrice/GetQuote calls for the same ticker from
+// memory for a short TTL, so /status, /list, the risk check and sync polling the
+// same symbols within seconds share one API call. Errors and zero prices are not cached.
+// Calls made with a Fresh context always reach the provider (order-time and guardrail
+// prices); their result still refreshes the cache.
+type CachedProvider struct {
+	MarketProvider
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu     sync.Mutex
+	prices map[string]cachedPrice
+	quotes map[string]cachedQuote
+}
+
+type cachedPrice struct {
+	price decimal.Decimal
+	at    time.Time
+}
+
+type cachedQuote struct {
+	quote *marketdata.Quote
+	at    time.Time
+}
+
+// freshKey marks a context whose prices must not come from the cache.
+type freshKey struct{}
+
+// Fresh returns ctx marked so the price cache calls through to the provider.
+func Fresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshKey{}, true)
+}
+
+func isFresh(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshKey{}).(bool)
+	return fresh
+}
+
+// WithPriceCache wraps p with a TTL cache timed by clk (ttl <= 0 returns p unchanged).
+func WithPriceCache(p MarketProvider, ttl time.Duration, clk clock.Clock) MarketProvider {
+	if ttl <= 0 {
+		return p
+	}
+	return &CachedProvider{
+		MarketProvider: p,
+		ttl:            ttl,
+		clock:          clk,
+		prices:         make(map[string]cachedPrice),
+		quotes:         make(map[string]cachedQuote),
+	}
+}
+
+// Unwrap returns the cached provider.
+func (c *CachedProvider) Unwrap() MarketProvider {
+	return c.MarketProvider
+}
+
+func (c *CachedProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	c.mu.Lock()
+	if e, ok := c.prices[ticker]; ok && !isFresh(ctx) && c.since(e.at) < c.ttl {
+		c.mu.Unlock()
+		return e.price, nil
+	}
+	c.mu.Unlock()
+
+	price, err := c.MarketProvider.GetPrice(ctx, ticker)
+	if err == nil && price.IsPositive() {
+		c.mu.Lock()
+		c.prices[ticker] = cachedPrice{price: price, at: c.clock.Now()}
+		c.pruneLocked()
+		c.mu.Unlock()
+	}
+	return price, err
+}
+
+func (c *CachedProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	c.mu.Lock()
+	if e, ok := c.quotes[ticker]; ok && !isFresh(ctx) && c.since(e.at) < c.ttl {
+		c.mu.Unlock()
+		q := *e.quote // Callers get their own copy
+		return &q, nil
+	}
+	c.mu.Unlock()
+
+	q, err := c.MarketProvider.GetQuote(ctx, ticker)
+	if err == nil && q != nil && q.BidPrice > 0 && q.AskPrice > 0 {
+		stored := *q
+		c.mu.Lock()
+		c.quotes[ticker] = cachedQuote{quote: &stored, at: c.clock.Now()}
+		c.pruneLocked()
+		c.mu.Unlock()
+	}
+	return q, err
+}
+
+// GetSnapshots always calls through, and stores the trades and quotes it returns so the
+// GetPrice/GetQuote calls that follow for the same tickers are served from memory.
+func (c *CachedProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	snaps, err := c.MarketProvider.GetSnapshots(ctx, tickers)
+	if err != nil {
+		return snaps, err
+	}
+	now := c.clock.Now()
+	c.mu.Lock()
+	for t, s := range snaps {
+		if s.LatestTrade != nil && s.LatestTrade.Price > 0 {
+			c.prices[t] = cachedPrice{price: decimal.NewFromFloat(s.LatestTrade.Price), at: now}
+		}
+		if q := s.LatestQuote; q != nil && q.BidPrice > 0 && q.AskPrice > 0 {
+			stored := *q
+			c.quotes[t] = cachedQuote{quote: &stored, at: now}
+		}
+	}
+	c.pruneLocked()
+	c.mu.Unlock()
+	return snaps, nil
+}
+
+// LastPrice returns the last price this cache saw for ticker and when, even past the
+// TTL. It never calls the API (see LastPriceSource).
+func (c *CachedProvider) LastPrice(ticker string) (decimal.Decimal, time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.prices[ticker]
+	return e.price, e.at, ok
+}
+
+func (c *CachedProvider) since(t time.Time) time.Duration {
+	return c.clock.Now().Sub(t)
+}
+
+func (c *CachedProvider) pruneLocked() {
+	if len(c.prices)+len(c.quotes) < priceCachePruneSize {
+		return
+	}
+	for t, e := range c.prices {
+		if c.since(e.at) >= c.ttl {
+			delete(c.prices, t)
+		}
+	}
+	for t, e := range c.quotes {
+		if c.since(e.at) >= c.ttl {
+			delete(c.quotes, t)
+		}
+	}
+}
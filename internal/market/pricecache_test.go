@@ -0,0 +1,53 @@
+package market
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alpha_trading/internal/clock"
+
+	"github.com/shopspring/decimal"
+)
+
+// countingProvider quotes a fixed price and counts the calls that reach it.
+type countingProvider struct {
+	MarketProvider
+	calls int
+}
+
+func (p *countingProvider) GetPrice(context.Context, string) (decimal.Decimal, error) {
+	p.calls++
+	return decimal.NewFromInt(100), nil
+}
+
+func TestPriceCache(t *testing.T) {
+	cases := []struct {
+		name  string
+		wait  time.Duration
+		fresh bool
+		calls int // Provider calls after the second GetPrice
+	}{
+		{"within TTL", 4 * time.Second, false, 1},
+		{"at TTL", 5 * time.Second, false, 2},
+		{"fresh within TTL", time.Second, true, 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Date(2025, 11, 26, 15, 0, 0, 0, time.UTC))
+			inner := &countingProvider{}
+			p := WithPriceCache(inner, 5*time.Second, fake)
+
+			p.GetPrice(context.Background(), "AAPL")
+			fake.Advance(tc.wait)
+			ctx := context.Background()
+			if tc.fresh {
+				ctx = Fresh(ctx)
+			}
+			p.GetPrice(ctx, "AAPL")
+			if inner.calls != tc.calls {
+				t.Fatalf("provider calls = %d, want %d", inner.calls, tc.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package market
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// tokenBucket allows perMin calls per minute with bursts of up to a quarter of that.
+// A nil bucket never blocks (limit disabled).
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(perMin int) *tokenBucket {
+	if perMin <= 0 {
+		return nil
+	}
+	burst := float64(perMin) / 4
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: float64(perMin) / 60, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a token is available and takes it. A done ctx stops the wait early;
+// the call that follows then fails on the same ctx.
+func (b *tokenBucket) wait(ctx context.Context) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens-- // Reserve now; a negative balance is this caller's wait
+	delay := time.Duration(0)
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+	}
+}
+
+// RateLimitedProvider throttles a MarketProvider with one token bucket for market
+// data (prices, quotes, bars) and one for trading/account calls, so bursts such as
+// /status fetching every position in parallel queue up instead of hitting 429s.
+type RateLimitedProvider struct {
+	MarketProvider
+	data    *tokenBucket
+	trading *tokenBucket
+}
+
+// WithRateLimit wraps p. A limit of 0 disables throttling for that class.
+func WithRateLimit(p MarketProvider, dataPerMin, tradingPerMin int) *RateLimitedProvider {
+	return &RateLimitedProvider{MarketProvider: p, data: newTokenBucket(dataPerMin), trading: newTokenBucket(tradingPerMin)}
+}
+
+// Unwrap returns the throttled provider.
+func (r *RateLimitedProvider) Unwrap() MarketProvider {
+	return r.MarketProvider
+}
+
+// --- Market data ---
+
+func (r *RateLimitedProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetPrice(ctx, ticker)
+}
+
+func (r *RateLimitedProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetQuote(ctx, ticker)
+}
+
+func (r *RateLimitedProvider) GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetSnapshot(ctx, ticker)
+}
+
+func (r *RateLimitedProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetSnapshots(ctx, tickers)
+}
+
+func (r *RateLimitedProvider) GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetBars(ctx, ticker, limit)
+}
+
+func (r *RateLimitedProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetBarsRange(ctx, ticker, timeframe, start, end)
+}
+
+func (r *RateLimitedProvider) GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) ([]CorporateAction, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetCorporateActions(ctx, symbols, start, end)
+}
+
+func (r *RateLimitedProvider) GetNews(ctx context.Context, ticker string, limit int) ([]marketdata.News, error) {
+	r.data.wait(ctx)
+	return r.MarketProvider.GetNews(ctx, ticker, limit)
+}
+
+// --- Trading / account ---
+
+func (r *RateLimitedProvider) GetEquity(ctx context.Context) (decimal.Decimal, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetEquity(ctx)
+}
+
+func (r *RateLimitedProvider) GetClock(ctx context.Context) (*alpaca.Clock, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetClock(ctx)
+}
+
+func (r *RateLimitedProvider) GetCalendar(ctx context.Context, start, end time.Time) ([]alpaca.CalendarDay, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetCalendar(ctx, start, end)
+}
+
+func (r *RateLimitedProvider) SearchAssets(ctx context.Context, query string) ([]alpaca.Asset, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.SearchAssets(ctx, query)
+}
+
+func (r *RateLimitedProvider) GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetAsset(ctx, ticker)
+}
+
+func (r *RateLimitedProvider) PlaceOrder(ctx context.Context, ticker string, qty decimal.Decimal, side string, opts ...OrderOptions) (*alpaca.Order, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.PlaceOrder(ctx, ticker, qty, side, opts...)
+}
+
+func (r *RateLimitedProvider) ReplaceOrder(ctx context.Context, orderID string, opts ReplaceOptions) (*alpaca.Order, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.ReplaceOrder(ctx, orderID, opts)
+}
+
+func (r *RateLimitedProvider) GetOrder(ctx context.Context, orderID string) (*alpaca.Order, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetOrder(ctx, orderID)
+}
+
+func (r *RateLimitedProvider) GetOrderByClientID(ctx context.Context, clientOrderID string) (*alpaca.Order, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetOrderByClientID(ctx, clientOrderID)
+}
+
+func (r *RateLimitedProvider) ListOrders(ctx context.Context, status string) ([]alpaca.Order, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.ListOrders(ctx, status)
+}
+
+func (r *RateLimitedProvider) ListPositions(ctx context.Context) ([]alpaca.Position, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.ListPositions(ctx)
+}
+
+func (r *RateLimitedProvider) CancelOrder(ctx context.Context, orderID string) error {
+	r.trading.wait(ctx)
+	return r.MarketProvider.CancelOrder(ctx, orderID)
+}
+
+func (r *RateLimitedProvider) GetBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetBuyingPower(ctx)
+}
+
+func (r *RateLimitedProvider) GetPortfolioHistory(ctx context.Context, period string, timeframe string) (*alpaca.PortfolioHistory, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetPortfolioHistory(ctx, period, timeframe)
+}
+
+func (r *RateLimitedProvider) GetAccount(ctx context.Context) (*alpaca.Account, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetAccount(ctx)
+}
+
+func (r *RateLimitedProvider) GetWatchlistByName(ctx context.Context, name string) (*alpaca.Watchlist, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.GetWatchlistByName(ctx, name)
+}
+
+func (r *RateLimitedProvider) CreateWatchlist(ctx context.Context, name string, symbols []string) (*alpaca.Watchlist, error) {
+	r.trading.wait(ctx)
+	return r.MarketProvider.CreateWatchlist(ctx, name, symbols)
+}
+
+func (r *RateLimitedProvider) AddToWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	r.trading.wait(ctx)
+	return r.MarketProvider.AddToWatchlist(ctx, watchlistID, symbol)
+}
+
+func (r *RateLimitedProvider) RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	r.trading.wait(ctx)
+	return r.MarketProvider.RemoveFromWatchlist(ctx, watchlistID, symbol)
+}
@@ -0,0 +1,223 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+const retryMaxDelay = 5 * time.Second
+
+// RetryProvider retries transient failures (429, 5xx, timeouts, dropped connections)
+// with exponential backoff and jitter. Only calls that are safe to repeat retry:
+// reads and cancels. PlaceOrder, ReplaceOrder and CreateWatchlist never retry, since a request
+// that timed out may still have gone through.
+type RetryProvider struct {
+	MarketProvider
+	attempts  int
+	baseDelay time.Duration
+}
+
+// WithRetry wraps p. attempts <= 1 returns p unchanged.
+func WithRetry(p MarketProvider, attempts int, baseDelay time.Duration) MarketProvider {
+	if attempts <= 1 {
+		return p
+	}
+	return &RetryProvider{MarketProvider: p, attempts: attempts, baseDelay: baseDelay}
+}
+
+// Unwrap returns the retried provider.
+func (r *RetryProvider) Unwrap() MarketProvider {
+	return r.MarketProvider
+}
+
+// retry runs fn until it succeeds, fails permanently or runs out of attempts.
+// A done ctx (shutdown, caller deadline) ends the loop, also during the backoff.
+func retry[T any](ctx context.Context, r *RetryProvider, call string, fn func() (T, error)) (T, error) {
+	var (
+		v   T
+		err error
+	)
+	for attempt := 1; ; attempt++ {
+		v, err = fn()
+		if err == nil || !IsTransient(err) || attempt >= r.attempts || ctx.Err() != nil {
+			return v, err
+		}
+		delay := r.backoff(attempt)
+		log.Printf("[RETRY] %s attempt %d/%d failed (%v), retrying in %s", call, attempt, r.attempts, err, delay.Round(time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return v, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff is base * 2^(attempt-1), capped, with equal jitter (half fixed, half random).
+func (r *RetryProvider) backoff(attempt int) time.Duration {
+	d := r.baseDelay << (attempt - 1)
+	if d > retryMaxDelay || d <= 0 {
+		d = retryMaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// IsTransient reports whether err is worth retrying.
+func IsTransient(err error) bool {
+	var apiErr *alpaca.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	// Kraken and the fallback sources report errors as text
+	msg := err.Error()
+	for _, s := range []string{"EAPI:Rate limit", "EService:Unavailable", "EService:Busy", "EGeneral:Temporary", "HTTP 429", "HTTP 5"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRejection reports whether err is a definite broker rejection (an Alpaca 4xx), i.e.
+// the request was answered and nothing was created. Timeouts, network errors and 5xx
+// leave the outcome unknown.
+func IsRejection(err error) bool {
+	var apiErr *alpaca.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
+
+// --- Always retried (reads) ---
+
+func (r *RetryProvider) GetPrice(ctx context.Context, ticker string) (decimal.Decimal, error) {
+	return retry(ctx, r, "GetPrice("+ticker+")", func() (decimal.Decimal, error) { return r.MarketProvider.GetPrice(ctx, ticker) })
+}
+
+func (r *RetryProvider) GetQuote(ctx context.Context, ticker string) (*marketdata.Quote, error) {
+	return retry(ctx, r, "GetQuote("+ticker+")", func() (*marketdata.Quote, error) { return r.MarketProvider.GetQuote(ctx, ticker) })
+}
+
+func (r *RetryProvider) GetSnapshot(ctx context.Context, ticker string) (*marketdata.Snapshot, error) {
+	return retry(ctx, r, "GetSnapshot("+ticker+")", func() (*marketdata.Snapshot, error) { return r.MarketProvider.GetSnapshot(ctx, ticker) })
+}
+
+func (r *RetryProvider) GetSnapshots(ctx context.Context, tickers []string) (map[string]*marketdata.Snapshot, error) {
+	return retry(ctx, r, "GetSnapshots", func() (map[string]*marketdata.Snapshot, error) { return r.MarketProvider.GetSnapshots(ctx, tickers) })
+}
+
+func (r *RetryProvider) GetBars(ctx context.Context, ticker string, limit int) ([]marketdata.Bar, error) {
+	return retry(ctx, r, "GetBars("+ticker+")", func() ([]marketdata.Bar, error) { return r.MarketProvider.GetBars(ctx, ticker, limit) })
+}
+
+func (r *RetryProvider) GetBarsRange(ctx context.Context, ticker string, timeframe string, start, end time.Time) ([]marketdata.Bar, error) {
+	return retry(ctx, r, "GetBarsRange("+ticker+")", func() ([]marketdata.Bar, error) {
+		return r.MarketProvider.GetBarsRange(ctx, ticker, timeframe, start, end)
+	})
+}
+
+func (r *RetryProvider) GetCorporateActions(ctx context.Context, symbols []string, start, end time.Time) ([]CorporateAction, error) {
+	return retry(ctx, r, "GetCorporateActions", func() ([]CorporateAction, error) {
+		return r.MarketProvider.GetCorporateActions(ctx, symbols, start, end)
+	})
+}
+
+func (r *RetryProvider) GetNews(ctx context.Context, ticker string, limit int) ([]marketdata.News, error) {
+	return retry(ctx, r, "GetNews("+ticker+")", func() ([]marketdata.News, error) { return r.MarketProvider.GetNews(ctx, ticker, limit) })
+}
+
+func (r *RetryProvider) GetEquity(ctx context.Context) (decimal.Decimal, error) {
+	return retry(ctx, r, "GetEquity", func() (decimal.Decimal, error) { return r.MarketProvider.GetEquity(ctx) })
+}
+
+func (r *RetryProvider) GetBuyingPower(ctx context.Context) (decimal.Decimal, error) {
+	return retry(ctx, r, "GetBuyingPower", func() (decimal.Decimal, error) { return r.MarketProvider.GetBuyingPower(ctx) })
+}
+
+func (r *RetryProvider) GetAccount(ctx context.Context) (*alpaca.Account, error) {
+	return retry(ctx, r, "GetAccount", func() (*alpaca.Account, error) { return r.MarketProvider.GetAccount(ctx) })
+}
+
+func (r *RetryProvider) GetClock(ctx context.Context) (*alpaca.Clock, error) {
+	return retry(ctx, r, "GetClock", func() (*alpaca.Clock, error) { return r.MarketProvider.GetClock(ctx) })
+}
+
+func (r *RetryProvider) GetCalendar(ctx context.Context, start, end time.Time) ([]alpaca.CalendarDay, error) {
+	return retry(ctx, r, "GetCalendar", func() ([]alpaca.CalendarDay, error) { return r.MarketProvider.GetCalendar(ctx, start, end) })
+}
+
+func (r *RetryProvider) SearchAssets(ctx context.Context, query string) ([]alpaca.Asset, error) {
+	return retry(ctx, r, "SearchAssets", func() ([]alpaca.Asset, error) { return r.MarketProvider.SearchAssets(ctx, query) })
+}
+
+func (r *RetryProvider) GetAsset(ctx context.Context, ticker string) (*alpaca.Asset, error) {
+	return retry(ctx, r, "GetAsset("+ticker+")", func() (*alpaca.Asset, error) { return r.MarketProvider.GetAsset(ctx, ticker) })
+}
+
+func (r *RetryProvider) GetOrder(ctx context.Context, orderID string) (*alpaca.Order, error) {
+	return retry(ctx, r, "GetOrder("+orderID+")", func() (*alpaca.Order, error) { return r.MarketProvider.GetOrder(ctx, orderID) })
+}
+
+func (r *RetryProvider) GetOrderByClientID(ctx context.Context, clientOrderID string) (*alpaca.Order, error) {
+	return retry(ctx, r, "GetOrderByClientID("+clientOrderID+")", func() (*alpaca.Order, error) {
+		return r.MarketProvider.GetOrderByClientID(ctx, clientOrderID)
+	})
+}
+
+func (r *RetryProvider) ListOrders(ctx context.Context, status string) ([]alpaca.Order, error) {
+	return retry(ctx, r, "ListOrders", func() ([]alpaca.Order, error) { return r.MarketProvider.ListOrders(ctx, status) })
+}
+
+func (r *RetryProvider) ListPositions(ctx context.Context) ([]alpaca.Position, error) {
+	return retry(ctx, r, "ListPositions", func() ([]alpaca.Position, error) { return r.MarketProvider.ListPositions(ctx) })
+}
+
+func (r *RetryProvider) GetPortfolioHistory(ctx context.Context, period string, timeframe string) (*alpaca.PortfolioHistory, error) {
+	return retry(ctx, r, "GetPortfolioHistory", func() (*alpaca.PortfolioHistory, error) {
+		return r.MarketProvider.GetPortfolioHistory(ctx, period, timeframe)
+	})
+}
+
+func (r *RetryProvider) GetWatchlistByName(ctx context.Context, name string) (*alpaca.Watchlist, error) {
+	return retry(ctx, r, "GetWatchlistByName", func() (*alpaca.Watchlist, error) { return r.MarketProvider.GetWatchlistByName(ctx, name) })
+}
+
+// --- Idempotent writes (repeating them is harmless) ---
+
+func (r *RetryProvider) CancelOrder(ctx context.Context, orderID string) error {
+	_, err := retry(ctx, r, "CancelOrder("+orderID+")", func() (struct{}, error) { return struct{}{}, r.MarketProvider.CancelOrder(ctx, orderID) })
+	return err
+}
+
+func (r *RetryProvider) AddToWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	_, err := retry(ctx, r, fmt.Sprintf("AddToWatchlist(%s)", symbol), func() (struct{}, error) {
+		return struct{}{}, r.MarketProvider.AddToWatchlist(ctx, watchlistID, symbol)
+	})
+	return err
+}
+
+func (r *RetryProvider) RemoveFromWatchlist(ctx context.Context, watchlistID, symbol string) error {
+	_, err := retry(ctx, r, fmt.Sprintf("RemoveFromWatchlist(%s)", symbol), func() (struct{}, error) {
+		return struct{}{}, r.MarketProvider.RemoveFromWatchlist(ctx, watchlistID, symbol)
+	})
+	return err
+}
+
+// PlaceOrder, ReplaceOrder and CreateWatchlist are not overridden: they pass straight through (no retry).
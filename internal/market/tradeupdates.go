@@ -0,0 +1,94 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+const (
+	tradeStreamMinBackoff = time.Second
+	tradeStreamMaxBackoff = time.Minute
+	// The SDK has no hook for the HTTP 200: a stream that neither failed (auth, dial)
+	// nor delivered an event within this grace is taken as up.
+	tradeStreamUpGrace = 10 * time.Second
+)
+
+// StreamTradeUpdates delivers the account's order events (new, fill, partial_fill,
+// canceled, rejected, expired, ...) to handler until ctx is done. It reconnects with a
+// backoff after errors, resuming from the last event seen, and moves to the new account
+// after Use. connected reports whether the stream is up, so callers can fall back to
+// polling while it is not: true after the first event or tradeStreamUpGrace without
+// an error, false after every disconnect.
+func (a *AlpacaProvider) StreamTradeUpdates(ctx context.Context, handler func(alpaca.TradeUpdate), connected func(bool)) {
+	var last time.Time
+	backoff := tradeStreamMinBackoff
+	for {
+		sub, cancel := context.WithCancel(ctx)
+		switched := a.switchedChan()
+		go func() {
+			select {
+			case <-switched:
+				cancel() // Account switch: reconnect with the new credentials
+			case <-sub.Done():
+			}
+		}()
+
+		req := alpaca.StreamTradeUpdatesRequest{}
+		if !last.IsZero() {
+			req.Since = last.Add(time.Nanosecond)
+		}
+		var mu sync.Mutex
+		up, down := false, false
+		markUp := func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if !up && !down {
+				up = true
+				connected(true)
+			}
+		}
+		grace := time.AfterFunc(tradeStreamUpGrace, markUp)
+		start := time.Now()
+		err := a.trade().StreamTradeUpdates(sub, func(tu alpaca.TradeUpdate) {
+			markUp()
+			last = tu.At
+			handler(tu)
+		}, req)
+		grace.Stop()
+		mu.Lock()
+		down = true
+		mu.Unlock()
+		connected(false)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-switched:
+			log.Printf("Trade stream: account switched, reconnecting.")
+			backoff = tradeStreamMinBackoff
+			last = time.Time{} // Other account: its history is not ours to replay
+			continue
+		default:
+		}
+		if err == nil || errors.Is(err, context.Canceled) {
+			err = errors.New("stream closed")
+		}
+		if time.Since(start) > tradeStreamMaxBackoff {
+			backoff = tradeStreamMinBackoff // It was up for a while: not a tight failure loop
+		}
+		log.Printf("Trade stream: %v. Reconnecting in %s.", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, tradeStreamMaxBackoff)
+	}
+}
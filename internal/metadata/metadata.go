@@ -0,0 +1,116 @@
+package metadata
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Unclassified is the sector reported for tickers missing from the reference file.
+const Unclassified = "Unclassified"
+
+// AssetInfo holds static classification data for a ticker.
+type AssetInfo struct {
+	Ticker   string   `json:"ticker"`
+	Name     string   `json:"name"`
+	Sector   string   `json:"sector"`
+	Industry string   `json:"industry"`
+	Tags     []string `json:"tags,omitempty"` // Free-form labels (e.g., "lowvol") used by tag-based thresholds
+}
+
+// Store is an in-memory, read-only index of asset metadata.
+type Store struct {
+	assets map[string]AssetInfo
+}
+
+// Load reads the bundled reference file (a JSON array of AssetInfo).
+// A missing or invalid file yields an empty store so callers never need nil checks.
+func Load(path string) *Store {
+	s := &Store{assets: make(map[string]AssetInfo)}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Warning: Asset metadata file %s not loaded: %v", path, err)
+		return s
+	}
+
+	var list []AssetInfo
+	if err := json.Unmarshal(b, &list); err != nil {
+		log.Printf("Warning: Asset metadata file %s is invalid: %v", path, err)
+		return s
+	}
+
+	for _, a := range list {
+		a.Ticker = strings.ToUpper(strings.TrimSpace(a.Ticker))
+		if a.Ticker == "" {
+			continue
+		}
+		s.assets[a.Ticker] = a
+	}
+	log.Printf("Asset metadata loaded: %d tickers", len(s.assets))
+	return s
+}
+
+// Lookup returns the metadata for a ticker, if known.
+func (s *Store) Lookup(ticker string) (AssetInfo, bool) {
+	a, ok := s.assets[strings.ToUpper(ticker)]
+	return a, ok
+}
+
+// Sector returns the ticker's sector or Unclassified.
+func (s *Store) Sector(ticker string) string {
+	if a, ok := s.Lookup(ticker); ok && a.Sector != "" {
+		return a.Sector
+	}
+	return Unclassified
+}
+
+// Tags returns the ticker's labels, lowercased, followed by its lowercased sector
+// and industry, so rules can target either a custom tag or a classification.
+func (s *Store) Tags(ticker string) []string {
+	a, ok := s.Lookup(ticker)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, t := range append(append([]string{}, a.Tags...), a.Sector, a.Industry) {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Match returns tickers whose sector or industry contains the query (case-insensitive),
+// e.g. "biotech" matches the "Biotechnology" industry.
+func (s *Store) Match(query string) []string {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+
+	var out []string
+	for t, a := range s.assets {
+		if strings.Contains(strings.ToLower(a.Sector), q) || strings.Contains(strings.ToLower(a.Industry), q) {
+			out = append(out, t)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Sectors lists the distinct sectors present in the reference file.
+func (s *Store) Sectors() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, a := range s.assets {
+		if a.Sector != "" && !seen[a.Sector] {
+			seen[a.Sector] = true
+			out = append(out, a.Sector)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
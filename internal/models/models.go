@@ -12,27 +12,262 @@ import (
 // The text inside the backticks (e.g. `json:"ticker"`) are "struct tags".
 // They tell the JSON encoder/decoder which keys to map to these fields.
 type Position struct {
-	Ticker          string          `json:"ticker"`            // The stock symbol (e.g., "AAPL")
-	Quantity        decimal.Decimal `json:"quantity"`          // Number of shares held
-	EntryPrice      decimal.Decimal `json:"entry_price"`       // Price at which we bought
-	StopLoss        decimal.Decimal `json:"stop_loss"`         // Price at which we sell to limit loss
-	TakeProfit      decimal.Decimal `json:"take_profit"`       // Price at which we sell to take profit
-	Status          string          `json:"status"`            // e.g., "ACTIVE", "TRIGGERED_SL", "TRIGGERED_TS"
-	ThesisID        string          `json:"thesis_id"`         // ID linking to the trade thesis
-	HighWaterMark   decimal.Decimal `json:"high_water_mark"`   // Highest price reached since entry
-	TrailingStopPct decimal.Decimal `json:"trailing_stop_pct"` // Trailing Stop percentage (e.g., 5.0 for 5%)
-	OpenedAt        time.Time       `json:"opened_at"`         // Spec 66: Timestamp when position was opened
+	Ticker          string          `json:"ticker"`                    // The stock symbol (e.g., "AAPL")
+	Quantity        decimal.Decimal `json:"quantity"`                  // Number of shares held
+	EntryPrice      decimal.Decimal `json:"entry_price"`               // Price at which we bought
+	StopLoss        decimal.Decimal `json:"stop_loss"`                 // Price at which we sell to limit loss
+	TakeProfit      decimal.Decimal `json:"take_profit"`               // Price at which we sell to take profit
+	Status          string          `json:"status"`                    // e.g., "ACTIVE", "TRIGGERED_SL", "TRIGGERED_TS"
+	ThesisID        string          `json:"thesis_id"`                 // ID linking to the trade thesis
+	HighWaterMark   decimal.Decimal `json:"high_water_mark"`           // Highest price reached since entry
+	TrailingStopPct decimal.Decimal `json:"trailing_stop_pct"`         // Trailing Stop percentage (e.g., 5.0 for 5%)
+	OpenedAt        time.Time       `json:"opened_at"`                 // Spec 66: Timestamp when position was opened
+	BrokerOCOID     string          `json:"broker_oco_id,omitempty"`   // Linked SL/TP pair resting at the broker; local SL/TP checks are skipped while set
+	BrokerTrailID   string          `json:"broker_trail_id,omitempty"` // Native trailing stop resting at the broker; HWM is then kept for reporting only
+	Book            string          `json:"book,omitempty"`            // Virtual sub-portfolio (/book); "" = unassigned
+	Priority        string          `json:"priority,omitempty"`        // Check cadence (/priority): "hot", "slow"; "" = every poll
+	Carry           decimal.Decimal `json:"carry"`                     // Accrued carry in USD (crypto): staking yield (+) or funding/borrow cost (-)
+	CarryAt         time.Time       `json:"carry_at"`                  // Carry accrued up to here; zero = from OpenedAt
 }
 
 // PortfolioState tracks the state of the portfolio and system.
 // This struct matches the structure of our JSON storage file.
 type PortfolioState struct {
-	Version         string             `json:"version"`          // Schema version for future compatibility
-	LastSync        string             `json:"last_sync"`        // Timestamp of last file save
-	LastHeartbeat   string             `json:"last_heartbeat"`   // Timestamp of last "I'm alive" message
-	Positions       []Position         `json:"positions"`        // A slice (variable-length array) of Positions
-	FiscalLimit     decimal.Decimal    `json:"fiscal_limit"`     // Spec 65: Persisted Limit
-	AvailableBudget decimal.Decimal    `json:"available_budget"` // Spec 65: Persisted Available
-	CurrentExposure decimal.Decimal    `json:"current_exposure"` // Spec 65: Persisted Exposure
-	WatchlistPrices map[string]float64 `json:"watchlist_prices"` // Spec 72: Watchlist Prices
+	Version              string                     `json:"version"`                 // Schema version for future compatibility
+	LastSync             string                     `json:"last_sync"`               // Timestamp of last file save
+	LastHeartbeat        string                     `json:"last_heartbeat"`          // Timestamp of last "I'm alive" message
+	LastEODSession       string                     `json:"last_eod_session"`        // Trading session date (ET, YYYY-MM-DD) of the last EOD report
+	Positions            []Position                 `json:"positions"`               // A slice (variable-length array) of Positions
+	FiscalLimit          decimal.Decimal            `json:"fiscal_limit"`            // Spec 65: Persisted Limit
+	AvailableBudget      decimal.Decimal            `json:"available_budget"`        // Spec 65: Persisted Available
+	CurrentExposure      decimal.Decimal            `json:"current_exposure"`        // Spec 65: Persisted Exposure
+	WatchlistPrices      map[string]decimal.Decimal `json:"watchlist_prices"`        // Spec 72: Watchlist Prices
+	WatchlistPricesAt    map[string]time.Time       `json:"watchlist_prices_at"`     // When each watchlist price was observed
+	Watchlist            []WatchlistEntry           `json:"watchlist"`               // Tickers added at runtime via /watch or /scan
+	Benchmarks           []Benchmark                `json:"benchmarks"`              // Comparison portfolios for the EOD report
+	Settings             UserSettings               `json:"settings"`                // Runtime preferences set via /settings
+	Blocklist            []string                   `json:"blocklist"`               // Tickers no buy path may open (/block)
+	LastLatencyReport    string                     `json:"last_latency_report"`     // Timestamp of the last weekly latency report
+	LastDivergenceReport string                     `json:"last_divergence_report"`  // Timestamp of the last weekly paper/live divergence report
+	LastSlippageMonth    string                     `json:"last_slippage_month"`     // Month (YYYY-MM) covered by the last monthly slippage report
+	LastReturnsMonth     string                     `json:"last_returns_month"`      // Month (YYYY-MM) of the last monthly TWR/MWR report
+	LastStrategyReview   string                     `json:"last_strategy_review"`    // Timestamp of the last weekly AI strategy review
+	PendingOrders        []PendingOrder             `json:"pending_orders"`          // Confirmed buys still resting at the broker (limit, extended hours)
+	Books                []Book                     `json:"books"`                   // Virtual sub-portfolios inside the account (/book)
+	Options              []OptionPosition           `json:"options,omitempty"`       // Broker option positions (OPTIONS_ENABLED), refreshed every poll
+	LastCorporateActions string                     `json:"last_corporate_actions"`  // ET day (YYYY-MM-DD) of the last corporate-actions check
+	CorporateActions     []string                   `json:"corporate_actions"`       // Splits applied and notices sent ("split:NVDA:2024-06-10")
+	Goals                []Goal                     `json:"goals,omitempty"`         // Targets tracked by /goal
+	LastGoalCheck        string                     `json:"last_goal_check"`         // CET day (YYYY-MM-DD) goals were last evaluated
+	LastGoalReport       string                     `json:"last_goal_report"`        // Timestamp of the last weekly goal report
+	CashIdleSince        string                     `json:"cash_idle_since"`         // Timestamp cash first exceeded SWEEP_CASH_THRESHOLD ("" = below it)
+	DailyLossHalt        string                     `json:"daily_loss_halt"`         // ET session (YYYY-MM-DD) the daily loss circuit breaker tripped in
+	PeakEquity           decimal.Decimal            `json:"peak_equity"`             // Highest equity seen (max drawdown kill switch)
+	DrawdownHalt         string                     `json:"drawdown_halt"`           // Timestamp the kill switch engaged ("" = trading allowed)
+	LastStopReview       string                     `json:"last_stop_review"`        // ISO week (YYYY-Www) of the last weekly stop review
+	Env                  string                     `json:"env,omitempty"`           // Alpaca account the positions belong to ("paper" or "live")
+	EnvSnapshots         map[string]EnvSnapshot     `json:"env_snapshots,omitempty"` // The other account's state, parked by /env until it is used again
+}
+
+// EnvSnapshot is an Alpaca account's part of the state while another account is in
+// use, so a switch back restores its levels and open dates instead of re-importing.
+// The risk marks are per account too: one account's peak says nothing about the other.
+type EnvSnapshot struct {
+	Positions     []Position      `json:"positions"`
+	PendingOrders []PendingOrder  `json:"pending_orders"`
+	PeakEquity    decimal.Decimal `json:"peak_equity"`
+	DrawdownHalt  string          `json:"drawdown_halt"`
+	DailyLossHalt string          `json:"daily_loss_halt"`
+}
+
+// Goal is a user target the watcher tracks: an equity level (optionally by a date) or
+// a drawdown ceiling. Drawdown is measured on trading P/L, so deposits do not reset it.
+type Goal struct {
+	Kind        string          `json:"kind"`               // "equity" or "drawdown"
+	Target      decimal.Decimal `json:"target"`             // USD for equity, % for drawdown
+	Deadline    string          `json:"deadline,omitempty"` // YYYY-MM-DD (equity goals)
+	StartEquity decimal.Decimal `json:"start_equity"`       // Equity when the goal was set
+	CreatedAt   time.Time       `json:"created_at"`
+	Status      string          `json:"status,omitempty"` // "reached", "breached" or "missed" once alerted
+}
+
+// Book is a named sub-portfolio (e.g., "swing", "dividends") with its own budget and
+// risk limits, carved out of the single brokerage account. Positions join it via Position.Book.
+type Book struct {
+	Name          string          `json:"name"`
+	Budget        decimal.Decimal `json:"budget"`                    // Max cost basis of the book's ACTIVE positions
+	MaxPositions  int             `json:"max_positions,omitempty"`   // 0 = no limit
+	StopLossPct   float64         `json:"stop_loss_pct,omitempty"`   // Default SL for new positions; 0 = global default
+	TakeProfitPct float64         `json:"take_profit_pct,omitempty"` // Default TP for new positions; 0 = global default
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// OptionPosition is an option contract held at the broker. It is monitored, not traded:
+// the watcher reports it and warns before expiry, but never places orders for it.
+type OptionPosition struct {
+	Symbol          string          `json:"symbol"`     // OCC symbol, e.g. AAPL240119C00150000
+	Underlying      string          `json:"underlying"` // AAPL
+	Type            string          `json:"type"`       // "call" or "put"
+	Strike          decimal.Decimal `json:"strike"`
+	Expiry          string          `json:"expiry"`   // YYYY-MM-DD
+	Quantity        decimal.Decimal `json:"quantity"` // Contracts; negative when short
+	AvgEntryPrice   decimal.Decimal `json:"avg_entry_price"`
+	CurrentPrice    decimal.Decimal `json:"current_price"`
+	MarketValue     decimal.Decimal `json:"market_value"`
+	UnrealizedPL    decimal.Decimal `json:"unrealized_pl"`
+	LastExpiryAlert string          `json:"last_expiry_alert,omitempty"` // Day (YYYY-MM-DD) of the last expiry warning
+}
+
+// PendingOrder is a confirmed buy that had not filled when verification ended.
+// The watcher polls it and opens the position with these levels once it fills.
+type PendingOrder struct {
+	OrderID         string          `json:"order_id"`
+	Ticker          string          `json:"ticker"`
+	Qty             decimal.Decimal `json:"qty"`
+	LimitPrice      decimal.Decimal `json:"limit_price"` // Zero for market orders
+	StopLoss        decimal.Decimal `json:"stop_loss"`
+	TakeProfit      decimal.Decimal `json:"take_profit"`
+	TrailingStopPct decimal.Decimal `json:"trailing_stop_pct"`
+	ThesisID        string          `json:"thesis_id"`
+	Book            string          `json:"book,omitempty"`
+	SubmittedAt     time.Time       `json:"submitted_at"`
+}
+
+// OrderIntent is journaled before an order is sent and again with the outcome, so an
+// order placed just before a crash can be matched to the broker on the next start.
+type OrderIntent struct {
+	ID              string          `json:"id"` // Also sent as the broker's client order ID
+	Time            time.Time       `json:"time"`
+	Status          string          `json:"status"` // INTENT, PLACED, UNKNOWN or FAILED (last record per ID wins)
+	Ticker          string          `json:"ticker"`
+	Side            string          `json:"side"`
+	Qty             decimal.Decimal `json:"qty"`
+	Notional        decimal.Decimal `json:"notional"`
+	Source          string          `json:"source"` // MANUAL, AI, ROTATION, SL/TP/TS, PROTECT, SWEEP
+	OrderID         string          `json:"order_id,omitempty"`
+	StopLoss        decimal.Decimal `json:"stop_loss"` // Buys: the levels of the linked proposal
+	TakeProfit      decimal.Decimal `json:"take_profit"`
+	TrailingStopPct decimal.Decimal `json:"trailing_stop_pct"`
+	ThesisID        string          `json:"thesis_id,omitempty"`
+	Book            string          `json:"book,omitempty"`
+}
+
+// UserSettings holds preferences changed at runtime via /settings.
+// Empty values mean "use the default".
+type UserSettings struct {
+	StatusLayout  string          `json:"status_layout"`  // "table", "cards" or "minimal"
+	StatusColumns []string        `json:"status_columns"` // Visible /status columns (e.g., "price", "day", "hwm")
+	Language      string          `json:"language"`       // Report language ("en", "es")
+	QuietHours    string          `json:"quiet_hours"`    // CET window for routine notifications, e.g. "22-07"
+	DefaultQty    decimal.Decimal `json:"default_qty"`    // Used by /buy when qty is omitted
+	Favorites     []string        `json:"favorites"`      // Tickers priced by a bare /price
+	VacationMode  bool            `json:"vacation_mode"`  // Unanswered trigger alerts follow VACATION_POLICY
+	EODSections   []string        `json:"eod_sections"`   // Enabled EOD report sections, in display order (nil = defaults)
+	EODCompact    bool            `json:"eod_compact"`    // Send a short EOD report on days without trades
+
+	// Written by /setup (or an applied strategy review); override the env config at startup when set.
+	DefaultStopLossPct     float64   `json:"default_stop_loss_pct,omitempty"`
+	DefaultTakeProfitPct   float64   `json:"default_take_profit_pct,omitempty"`
+	DefaultTrailingStopPct float64   `json:"default_trailing_stop_pct,omitempty"`
+	AutoStatus             *bool     `json:"auto_status,omitempty"`
+	SetupCompletedAt       time.Time `json:"setup_completed_at"`
+}
+
+// Benchmark is a hypothetical comparison portfolio (e.g., "SPY" or a 60/40 mix).
+// Weights are relative and normalized at calculation time.
+type Benchmark struct {
+	Name    string                     `json:"name"`
+	Weights map[string]decimal.Decimal `json:"weights"` // Ticker -> Weight
+}
+
+// WatchlistEntry is a ticker tracked at runtime (scan -> watch -> alert pipeline).
+// Unlike WATCHLIST_TICKERS, these entries live in the state file and can be
+// added or removed from Telegram without a restart.
+type WatchlistEntry struct {
+	Ticker         string          `json:"ticker"`
+	AddedAt        time.Time       `json:"added_at"`
+	Source         string          `json:"source"`          // e.g., "SCAN:biotech", "MANUAL"
+	ReferencePrice decimal.Decimal `json:"reference_price"` // Price when added, baseline for move alerts
+	AlertBelow     decimal.Decimal `json:"alert_below"`     // Level alert when the price falls to it (zero = none)
+	AlertAbove     decimal.Decimal `json:"alert_above"`     // Level alert when the price rises to it (zero = none)
+}
+
+// ArchivedTrade is a closed position moved out of the live state into the trade archive.
+type ArchivedTrade struct {
+	Ticker     string          `json:"ticker"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	ExitPrice  decimal.Decimal `json:"exit_price"` // Zero if the exit fill is unknown
+	PL         decimal.Decimal `json:"pl"`         // Price P/L plus Carry
+	Carry      decimal.Decimal `json:"carry"`      // Position.Carry at close
+	OpenedAt   time.Time       `json:"opened_at"`
+	ClosedAt   time.Time       `json:"closed_at"`
+	Reason     string          `json:"reason"` // e.g., "MANUAL", "SL", "TP", "TS", "ROTATION", "RECONCILED"
+	ThesisID   string          `json:"thesis_id"`
+	Book       string          `json:"book,omitempty"` // Position.Book at close
+}
+
+// AuditEvent is one step in a trade's decision trail (audit_log.jsonl), used by /why.
+type AuditEvent struct {
+	Time   time.Time       `json:"time"`
+	Ticker string          `json:"ticker"`
+	Event  string          `json:"event"` // PROPOSED, CANCELLED, FILLED, TRIGGER, CLOSED
+	Actor  string          `json:"actor"` // USER, AI, SYSTEM
+	Price  decimal.Decimal `json:"price"`
+	Detail string          `json:"detail,omitempty"`
+}
+
+// LatencyRecord times one trigger from detection to fill (latency_log.jsonl).
+type LatencyRecord struct {
+	Ticker      string    `json:"ticker"`
+	Trigger     string    `json:"trigger"`      // SL, TP, TS
+	DetectedAt  time.Time `json:"detected_at"`  // First poll that saw the breach
+	AlertedAt   time.Time `json:"alerted_at"`   // First alert sent
+	ConfirmedAt time.Time `json:"confirmed_at"` // Confirmation tap (or vacation policy)
+	FilledAt    time.Time `json:"filled_at"`
+	Auto        bool      `json:"auto"` // Executed by vacation policy, not by a tap
+}
+
+// EquitySample is one poll's account equity (equity_log.jsonl).
+type EquitySample struct {
+	Time   time.Time       `json:"time"`
+	Equity decimal.Decimal `json:"equity"`
+}
+
+// SpreadSample is one observed bid/ask spread (spread_log.jsonl).
+type SpreadSample struct {
+	Time   time.Time       `json:"time"`
+	Ticker string          `json:"ticker"`
+	Bid    decimal.Decimal `json:"bid"`
+	Ask    decimal.Decimal `json:"ask"`
+	Bps    decimal.Decimal `json:"bps"` // (Ask - Bid) / mid
+}
+
+// SlippageRecord compares a fill against the price the decision was made on (slippage_log.jsonl).
+type SlippageRecord struct {
+	Time           time.Time       `json:"time"`
+	Ticker         string          `json:"ticker"`
+	Side           string          `json:"side"`       // buy, sell
+	Source         string          `json:"source"`     // MANUAL, AI, ROTATION, SL, TP, TS
+	OrderType      string          `json:"order_type"` // Broker order type (market)
+	Qty            decimal.Decimal `json:"qty"`
+	DecisionPrice  decimal.Decimal `json:"decision_price"` // Trigger/quote price at decision time
+	FillPrice      decimal.Decimal `json:"fill_price"`
+	Bps            decimal.Decimal `json:"bps"`                       // Positive = worse than decision price
+	Broker         string          `json:"broker,omitempty"`          // Provider that filled it (alpaca/paper, alpaca/live, kraken)
+	QuoteMid       decimal.Decimal `json:"quote_mid,omitempty"`       // Bid/ask midpoint just before the order was sent (0 = no quote)
+	ImprovementBps decimal.Decimal `json:"improvement_bps,omitempty"` // Fill vs QuoteMid; positive = better than mid
+}
+
+// StateEvent is one position mutation in the append-only event log (state_events.jsonl).
+// Position is the full position after the change, so replaying the log in order
+// rebuilds the positions at any point in time.
+type StateEvent struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"` // position_opened, sl_updated, tp_updated, ts_updated, hwm_raised, qty_changed, status_changed, position_closed, snapshot
+	Ticker   string    `json:"ticker"`
+	Old      string    `json:"old,omitempty"` // Previous value of the changed field
+	New      string    `json:"new,omitempty"`
+	Position *Position `json:"position,omitempty"` // Full position after the change; nil for hwm_raised (New is the HWM)
+	Detail   string    `json:"detail,omitempty"`
 }
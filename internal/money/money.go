@@ -0,0 +1,93 @@
+// Package money holds the rounding rules for prices, quantities and cash.
+//
+// Rules:
+//   - Prices (order, SL/TP trigger levels) snap to the instrument's tick:
+//     $0.01 at or above $1 and $0.0001 below $1 for US equities (Reg NMS Rule 612),
+//     8 decimals for crypto. Prices submitted with an order round in the direction
+//     that never worsens the order: buys down, sells up.
+//   - Quantities always round down, so a resized order never overspends:
+//     crypto to 6 decimals, stocks to whole shares (or 2 decimals when fractional).
+//   - Cash (budgets, exposure, P/L in state) rounds to the cent.
+//   - Display shows cents, or 4 decimals for sub-dollar prices.
+package money
+
+import (
+	"strings"
+
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	CryptoQtyPlaces     = 6
+	FractionalQtyPlaces = 2
+	cryptoPricePlaces   = 8
+)
+
+var (
+	one      = decimal.NewFromInt(1)
+	cent     = decimal.New(1, -2)
+	subPenny = decimal.New(1, -4)
+)
+
+// Tick returns the minimum price increment for ticker at price.
+func Tick(ticker string, price decimal.Decimal) decimal.Decimal {
+	if symbols.IsCrypto(ticker) {
+		return decimal.New(1, -cryptoPricePlaces)
+	}
+	if price.Abs().LessThan(one) {
+		return subPenny
+	}
+	return cent
+}
+
+// Price rounds p to the nearest valid tick for ticker.
+func Price(ticker string, p decimal.Decimal) decimal.Decimal {
+	tick := Tick(ticker, p)
+	return p.Div(tick).Round(0).Mul(tick)
+}
+
+// OrderPrice rounds a limit or stop price for submission: down to the tick for
+// buys (never pay more than computed), up for sells (never accept less).
+// The tick is picked from the unrounded price, so 0.99996 stays on the
+// sub-penny grid and 1.004 on the cent grid.
+func OrderPrice(ticker, side string, p decimal.Decimal) decimal.Decimal {
+	return SnapToTick(p, Tick(ticker, p), side)
+}
+
+// SnapToTick rounds p to a multiple of tick, down for "buy" and up for "sell".
+func SnapToTick(p, tick decimal.Decimal, side string) decimal.Decimal {
+	if !tick.IsPositive() {
+		return p
+	}
+	steps := p.Div(tick)
+	if strings.EqualFold(side, "sell") {
+		return steps.Ceil().Mul(tick)
+	}
+	return steps.Floor().Mul(tick)
+}
+
+// Qty rounds q down to an orderable quantity for ticker.
+func Qty(ticker string, q decimal.Decimal, fractional bool) decimal.Decimal {
+	switch {
+	case symbols.IsCrypto(ticker):
+		return q.Truncate(CryptoQtyPlaces)
+	case fractional:
+		return q.Truncate(FractionalQtyPlaces)
+	}
+	return q.Floor()
+}
+
+// Cash rounds an amount of money to the cent.
+func Cash(d decimal.Decimal) decimal.Decimal {
+	return d.Round(2)
+}
+
+// USD formats d for display ("$12.34", or "$0.0123" for sub-dollar values).
+func USD(d decimal.Decimal) string {
+	if !d.IsZero() && d.Abs().LessThan(one) {
+		return "$" + d.StringFixed(4)
+	}
+	return "$" + d.StringFixed(2)
+}
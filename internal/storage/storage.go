@@ -1,17 +1,46 @@
 package storage
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
 )
 
 // StateFile defines where we save our data on disk.
 const StateFile = "portfolio_state.json"
 
+// ArchiveFile holds closed positions (kept out of the live state).
+const ArchiveFile = "trade_archive.json"
+
+// AuditFile is the append-only decision trail (one JSON event per line).
+const AuditFile = "audit_log.jsonl"
+
+// LatencyFile records trigger-to-fill timings (one JSON record per line).
+const LatencyFile = "latency_log.jsonl"
+
+// SlippageFile records decision vs fill prices per executed order (one JSON record per line).
+const SlippageFile = "slippage_log.jsonl"
+
+// SpreadFile records bid/ask spreads sampled every poll (one JSON record per line).
+const SpreadFile = "spread_log.jsonl"
+
+// EquityFile is the intraday equity curve sampled every poll (one JSON record per line).
+const EquityFile = "equity_log.jsonl"
+
+// EventFile is the append-only log of position mutations (one JSON event per line).
+const EventFile = "state_events.jsonl"
+
+// IntentFile journals orders around PlaceOrder (one JSON record per line).
+const IntentFile = "order_intents.jsonl"
+
 // LoadState reads the portfolio state from disk.
 // It returns the PortfolioState struct and an error if one occurred.
 func LoadState() (models.PortfolioState, error) {
@@ -58,6 +87,15 @@ func LoadState() (models.PortfolioState, error) {
 	if s.Positions == nil {
 		s.Positions = []models.Position{}
 	}
+	if s.Watchlist == nil {
+		s.Watchlist = []models.WatchlistEntry{}
+	}
+	if s.Benchmarks == nil {
+		s.Benchmarks = []models.Benchmark{}
+	}
+	if s.Blocklist == nil {
+		s.Blocklist = []string{}
+	}
 
 	return s, nil
 }
@@ -92,59 +130,23 @@ func migrateState(s *models.PortfolioState) bool {
 	return updated
 }
 
-// SaveState writes the current state to disk using an atomic write pattern.
-// 1. Audit: Check for High Water Mark regressions (Spec 52).
-// 2. Write to a temporary file.
-// 3. Sync to ensure data is on disk.
-// 4. Rename temporary file to destination (atomic operation).
+// SaveState writes the state synchronously (startup template and migrations).
+// The running Watcher saves through a StateWriter instead.
 func SaveState(s models.PortfolioState) {
-	// --- Spec 52: High Water Mark (HWM) Monotonicity Guardrail ---
-	// "Every time saveState() is called, the bot should verify that for all active positions, NewHWM >= OldHWM."
-
-	// 1. Load the current state from disk to compare against.
-	// We ignore errors here (e.g., file not found on first run) because we can't audit what doesn't exist.
-	oldState, err := LoadState()
-	if err == nil {
-		oldPositions := make(map[string]models.Position)
-		for _, p := range oldState.Positions {
-			oldPositions[p.Ticker] = p
-		}
-
-		for _, newPos := range s.Positions {
-			// Skip checks for positions that aren't active or are new
-			oldPos, exists := oldPositions[newPos.Ticker]
-			if !exists {
-				continue
-			}
-
-			// Check for Regression: NewHWM < OldHWM
-			// We validly allow HWM to be equal, or greater.
-			// IsZero check ensures we don't trigger on initial empty states if logic allows.
-			if !oldPos.HighWaterMark.IsZero() && newPos.HighWaterMark.LessThan(oldPos.HighWaterMark) {
-				log.Printf("[CRITICAL_STATE_REGRESSION] High Water Mark decreased for %s! Old: %s, New: %s. Stack Trace Follows:",
-					newPos.Ticker, oldPos.HighWaterMark.String(), newPos.HighWaterMark.String())
-
-				// Print primitive stack trace or just the error location
-				// Since log.Lshortfile is enabled globally, we get line number.
-				// Spec asks for stack trace.
-				// We can just log the error loudly. Real stack trace might be noise, but let's be explicit about the error.
-			}
-		}
-	} else {
-		// It's acceptable for LoadState to fail if the file doesn't exist yet (Genesis).
-		// But if it exists and fails, we log it.
-		if !os.IsNotExist(err) {
-			log.Printf("WARNING: Could not load old state for HWM Audit: %v", err)
-		}
-	}
-
 	// MarshalIndent makes the JSON human-readable (pretty-printed) with 2-space indentation.
 	b, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		log.Printf("ERROR: Failed to marshal state: %v", err)
 		return
 	}
+	writeStateFile(b)
+}
 
+// writeStateFile replaces the state file using an atomic write pattern.
+// 1. Write to a temporary file.
+// 2. Sync to ensure data is on disk.
+// 3. Rename temporary file to destination (atomic operation).
+func writeStateFile(b []byte) {
 	// Create a temporary file in the same directory to ensure atomic rename works across filesystems
 	// "portfolio_state.json.tmp"
 	tmpFile := StateFile + ".tmp"
@@ -177,3 +179,418 @@ func SaveState(s models.PortfolioState) {
 		log.Printf("ERROR: Failed to replace state file (atomic rename): %v", err)
 	}
 }
+
+// BackupState copies the current state file to a timestamped backup
+// (e.g., "portfolio_state.json.bak-20240102-150405") and returns its path.
+func BackupState() (string, error) {
+	b, err := os.ReadFile(StateFile)
+	if err != nil {
+		return "", err
+	}
+	path := StateFile + ".bak-" + time.Now().Format("20060102-150405")
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// LoadArchive reads the closed-trade archive. A missing file is an empty archive.
+func LoadArchive() ([]models.ArchivedTrade, error) {
+	b, err := os.ReadFile(ArchiveFile)
+	if os.IsNotExist(err) {
+		return []models.ArchivedTrade{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var trades []models.ArchivedTrade
+	if err := json.Unmarshal(b, &trades); err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// AppendArchive adds a closed trade to the archive (atomic rewrite, same pattern as SaveState).
+func AppendArchive(t models.ArchivedTrade) error {
+	trades, err := LoadArchive()
+	if err != nil {
+		return err
+	}
+	trades = append(trades, t)
+
+	b, err := json.MarshalIndent(trades, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := ArchiveFile + ".tmp"
+	if err := os.WriteFile(tmpFile, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, ArchiveFile)
+}
+
+// appendJSONLine appends v as one JSON line to path (JSONL logs).
+func appendJSONLine(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// AppendAudit appends one event to the audit log.
+func AppendAudit(e models.AuditEvent) error {
+	return appendJSONLine(AuditFile, e)
+}
+
+// LoadAudit reads the audit events for ticker (all tickers if empty), oldest first.
+// Malformed lines are skipped. A missing file is an empty log.
+func LoadAudit(ticker string) ([]models.AuditEvent, error) {
+	b, err := os.ReadFile(AuditFile)
+	if os.IsNotExist(err) {
+		return []models.AuditEvent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	events := []models.AuditEvent{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e models.AuditEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if ticker == "" || e.Ticker == ticker {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// AppendLatency appends one trigger-to-fill timing record.
+func AppendLatency(r models.LatencyRecord) error {
+	return appendJSONLine(LatencyFile, r)
+}
+
+// LoadLatency reads the timing records filled at or after since. Malformed lines are skipped.
+func LoadLatency(since time.Time) ([]models.LatencyRecord, error) {
+	b, err := os.ReadFile(LatencyFile)
+	if os.IsNotExist(err) {
+		return []models.LatencyRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := []models.LatencyRecord{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r models.LatencyRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue
+		}
+		if !r.FilledAt.Before(since) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// AppendSlippage appends one decision-vs-fill record.
+func AppendSlippage(r models.SlippageRecord) error {
+	return appendJSONLine(SlippageFile, r)
+}
+
+// LoadSlippage reads the records in [start, end). Malformed lines are skipped.
+func LoadSlippage(start, end time.Time) ([]models.SlippageRecord, error) {
+	b, err := os.ReadFile(SlippageFile)
+	if os.IsNotExist(err) {
+		return []models.SlippageRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := []models.SlippageRecord{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var r models.SlippageRecord
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue
+		}
+		if !r.Time.Before(start) && r.Time.Before(end) {
+			records = append(records, r)
+		}
+	}
+	return records, nil
+}
+
+// AppendSpreads appends one poll's spread samples.
+func AppendSpreads(samples []models.SpreadSample) error {
+	for _, s := range samples {
+		if err := appendJSONLine(SpreadFile, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadSpreads reads the samples taken at or after since, oldest first. Malformed lines are skipped.
+func LoadSpreads(since time.Time) ([]models.SpreadSample, error) {
+	b, err := os.ReadFile(SpreadFile)
+	if os.IsNotExist(err) {
+		return []models.SpreadSample{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	samples := []models.SpreadSample{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var s models.SpreadSample
+		if err := json.Unmarshal(line, &s); err != nil {
+			continue
+		}
+		if !s.Time.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// AppendEquity appends one equity sample.
+func AppendEquity(s models.EquitySample) error {
+	return appendJSONLine(EquityFile, s)
+}
+
+// LoadEquity reads the samples taken at or after since, oldest first. Malformed lines are skipped.
+func LoadEquity(since time.Time) ([]models.EquitySample, error) {
+	b, err := os.ReadFile(EquityFile)
+	if os.IsNotExist(err) {
+		return []models.EquitySample{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	samples := []models.EquitySample{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var s models.EquitySample
+		if err := json.Unmarshal(line, &s); err != nil {
+			continue
+		}
+		if !s.Time.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// intentMu serializes journal appends with DropIntents' rewrite.
+var intentMu sync.Mutex
+
+// AppendIntent appends one order intent record.
+func AppendIntent(i models.OrderIntent) error {
+	intentMu.Lock()
+	defer intentMu.Unlock()
+	return appendJSONLine(IntentFile, i)
+}
+
+// LoadIntents reads the intent journal, keeping the last record of each intent, in
+// the order they were first written. A missing file is an empty journal.
+func LoadIntents() ([]models.OrderIntent, error) {
+	b, err := os.ReadFile(IntentFile)
+	if os.IsNotExist(err) {
+		return []models.OrderIntent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	intents := []models.OrderIntent{}
+	index := make(map[string]int)
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var i models.OrderIntent
+		if err := json.Unmarshal(line, &i); err != nil {
+			continue
+		}
+		if n, ok := index[i.ID]; ok {
+			intents[n] = i
+			continue
+		}
+		index[i.ID] = len(intents)
+		intents = append(intents, i)
+	}
+	return intents, nil
+}
+
+// DropIntents removes the records of the given intents (reconciled or moot) from the
+// journal, keeping everything else, including records appended meanwhile.
+func DropIntents(ids map[string]bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	intentMu.Lock()
+	defer intentMu.Unlock()
+	b, err := os.ReadFile(IntentFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var kept [][]byte
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var i models.OrderIntent
+		if err := json.Unmarshal(line, &i); err == nil && ids[i.ID] {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if len(kept) == 0 {
+		return ClearIntents()
+	}
+	tmpFile := IntentFile + ".tmp"
+	if err := os.WriteFile(tmpFile, append(bytes.Join(kept, []byte("\n")), '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, IntentFile)
+}
+
+// ClearIntents empties the intent journal once every intent has been reconciled.
+func ClearIntents() error {
+	if err := os.Remove(IntentFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// eventLogMaxBytes is the size past which the event log rotates (see RotateEvents).
+const eventLogMaxBytes = 4 << 20
+
+// AppendEvents appends position mutation events in order, in a single write.
+func AppendEvents(events []models.StateEvent) error {
+	var buf bytes.Buffer
+	for _, e := range events {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(b, '\n'))
+	}
+	f, err := os.OpenFile(EventFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(buf.Bytes())
+	return err
+}
+
+// RotateEvents keeps the event log bounded: past eventLogMaxBytes it moves to
+// EventFile.1 (replacing the previous one) and a new log starts with a snapshot event
+// per position, so a replay of the new file alone rebuilds positions.
+func RotateEvents(positions []models.Position) error {
+	info, err := os.Stat(EventFile)
+	if err != nil || info.Size() < eventLogMaxBytes {
+		return nil
+	}
+	if err := os.Rename(EventFile, EventFile+".1"); err != nil {
+		return err
+	}
+	now := time.Now()
+	snapshot := make([]models.StateEvent, 0, len(positions))
+	for i := range positions {
+		p := positions[i]
+		snapshot = append(snapshot, models.StateEvent{Time: now, Type: "snapshot", Ticker: p.Ticker, Position: &p, Detail: "log rotated"})
+	}
+	return AppendEvents(snapshot)
+}
+
+// LoadEvents reads the events recorded at or before until (all if zero), oldest first.
+// A time before the current log's first event is served from the rotated log.
+// Malformed lines are skipped. A missing file is an empty log.
+func LoadEvents(until time.Time) ([]models.StateEvent, error) {
+	events, err := loadEventFile(EventFile, until)
+	if err == nil && !until.IsZero() && len(events) == 0 {
+		return loadEventFile(EventFile+".1", until)
+	}
+	return events, err
+}
+
+func loadEventFile(path string, until time.Time) ([]models.StateEvent, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return []models.StateEvent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	events := []models.StateEvent{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var e models.StateEvent
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if until.IsZero() || !e.Time.After(until) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// ReplayEvents folds the events into the resulting positions, in opening order.
+// Events carry the full position after the change, so the last one per ticker wins;
+// hwm_raised only carries the new HWM, and position_closed removes the position.
+func ReplayEvents(events []models.StateEvent) []models.Position {
+	positions := []models.Position{}
+	index := make(map[string]int)
+	for _, e := range events {
+		i, open := index[e.Ticker]
+		switch {
+		case e.Position == nil:
+			if hwm, err := decimal.NewFromString(e.New); err == nil && open && e.Type == "hwm_raised" {
+				positions[i].HighWaterMark = hwm
+			}
+		case e.Type == "position_closed":
+			if !open {
+				continue
+			}
+			positions = append(positions[:i], positions[i+1:]...)
+			delete(index, e.Ticker)
+			for t, j := range index {
+				if j > i {
+					index[t] = j - 1
+				}
+			}
+		case open:
+			positions[i] = *e.Position
+		default:
+			index[e.Ticker] = len(positions)
+			positions = append(positions, *e.Position)
+		}
+	}
+	return positions
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// StateWriter persists the portfolio state from a single goroutine.
+// Save marshals a snapshot immediately (callers keep mutating their copy) and
+// queues it; saves arriving within the debounce window collapse into one write
+// of the latest snapshot. Position events queued with Events are appended in the
+// same write, in one batch. The HWM audit (Spec 52, see Audit) compares against
+// the last saved state kept in memory instead of re-reading the file.
+type StateWriter struct {
+	debounce time.Duration
+	kick     chan struct{}
+	flush    chan chan struct{}
+
+	mu        sync.Mutex
+	pending   []byte                     // Latest unsaved snapshot (nil when clean)
+	events    []models.StateEvent        // Unwritten position events, oldest first
+	positions []models.Position          // Positions after the last queued event (rotation snapshot)
+	hwm       map[string]decimal.Decimal // High water marks of the last saved state
+}
+
+// NewStateWriter starts the writer goroutine. initial seeds the HWM audit cache
+// (normally the state just loaded from disk).
+func NewStateWriter(initial models.PortfolioState, debounce time.Duration) *StateWriter {
+	sw := &StateWriter{
+		debounce: debounce,
+		kick:     make(chan struct{}, 1),
+		flush:    make(chan chan struct{}),
+		hwm:      hwmByTicker(initial),
+	}
+	go sw.run()
+	return sw
+}
+
+// HWMRegression is a position whose High Water Mark went down since the last save.
+type HWMRegression struct {
+	Ticker   string
+	Old, New decimal.Decimal
+	CallSite string // Frames that led to the save (outside storage and saveState*)
+}
+
+// Audit checks s against the last saved state and logs every HWM regression.
+// "Every time saveState() is called, the bot should verify that for all active positions, NewHWM >= OldHWM."
+func (sw *StateWriter) Audit(s models.PortfolioState) []HWMRegression {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	var regs []HWMRegression
+	for _, p := range s.Positions {
+		old, exists := sw.hwm[p.Ticker]
+		if !exists || old.IsZero() || !p.HighWaterMark.LessThan(old) {
+			continue
+		}
+		r := HWMRegression{Ticker: p.Ticker, Old: old, New: p.HighWaterMark, CallSite: callSite()}
+		log.Printf("[CRITICAL_STATE_REGRESSION] High Water Mark decreased for %s! Old: %s, New: %s. Call site: %s",
+			r.Ticker, r.Old.String(), r.New.String(), r.CallSite)
+		regs = append(regs, r)
+	}
+	return regs
+}
+
+// RebaseHWM accepts a deliberate HWM change for ticker (a stock split rescales every
+// price level), so the next Audit does not report it as a regression.
+func (sw *StateWriter) RebaseHWM(ticker string, hwm decimal.Decimal) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.hwm[ticker] = hwm
+}
+
+// Save snapshots s, then schedules the write.
+func (sw *StateWriter) Save(s models.PortfolioState) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		log.Printf("ERROR: Failed to marshal state: %v", err)
+		return
+	}
+
+	sw.mu.Lock()
+	sw.hwm = hwmByTicker(s)
+	sw.pending = b
+	sw.mu.Unlock()
+
+	select {
+	case sw.kick <- struct{}{}:
+	default: // A write is already scheduled; it will pick up this snapshot
+	}
+}
+
+// Events queues position events for the next write; positions is the state they
+// lead to.
+func (sw *StateWriter) Events(events []models.StateEvent, positions []models.Position) {
+	sw.mu.Lock()
+	sw.events = append(sw.events, events...)
+	sw.positions = append([]models.Position(nil), positions...)
+	sw.mu.Unlock()
+
+	select {
+	case sw.kick <- struct{}{}:
+	default:
+	}
+}
+
+// Flush writes any pending snapshot now and waits for it (shutdown, file readers).
+func (sw *StateWriter) Flush() {
+	done := make(chan struct{})
+	sw.flush <- done
+	<-done
+}
+
+func (sw *StateWriter) run() {
+	for {
+		select {
+		case <-sw.kick:
+			timer := time.NewTimer(sw.debounce)
+			select {
+			case <-timer.C:
+				sw.write()
+			case done := <-sw.flush:
+				timer.Stop()
+				sw.write()
+				close(done)
+			}
+		case done := <-sw.flush:
+			sw.write()
+			close(done)
+		}
+	}
+}
+
+func (sw *StateWriter) write() {
+	sw.mu.Lock()
+	b := sw.pending
+	events, positions := sw.events, sw.positions
+	sw.pending, sw.events = nil, nil
+	sw.mu.Unlock()
+
+	if b != nil {
+		writeStateFile(b)
+	}
+	if len(events) == 0 {
+		return
+	}
+	if err := AppendEvents(events); err != nil {
+		log.Printf("ERROR: Failed to write %d state events (retried with the next save): %v", len(events), err)
+		sw.mu.Lock()
+		sw.events = append(events, sw.events...)
+		sw.mu.Unlock()
+		return
+	}
+	if err := RotateEvents(positions); err != nil {
+		log.Printf("ERROR: Failed to rotate %s: %v", EventFile, err)
+	}
+}
+
+func hwmByTicker(s models.PortfolioState) map[string]decimal.Decimal {
+	m := make(map[string]decimal.Decimal, len(s.Positions))
+	for _, p := range s.Positions {
+		m[p.Ticker] = p.HighWaterMark
+	}
+	return m
+}
+
+// callSite captures the stack of the current save, skipping storage internals
+// and the watcher's saveState wrappers, as "func (file:line) <- ...".
+func callSite() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []string
+	for {
+		f, more := frames.Next()
+		name := f.Function[strings.LastIndex(f.Function, "/")+1:]
+		if !strings.HasPrefix(name, "storage.") && !strings.Contains(name, ".saveState") && !strings.HasPrefix(name, "runtime.") {
+			out = append(out, fmt.Sprintf("%s (%s:%d)", name, filepath.Base(f.File), f.Line))
+		}
+		if !more || len(out) == 4 {
+			break
+		}
+	}
+	return strings.Join(out, " <- ")
+}
@@ -0,0 +1,140 @@
+package symbols
+
+import (
+	"strings"
+)
+
+// Style selects the symbol convention expected by a data/trading provider.
+type Style int
+
+const (
+	// StyleAlpaca uses dots for share classes (BRK.B) and slashes for crypto pairs (BTC/USD).
+	StyleAlpaca Style = iota
+	// StyleDash uses dashes for both (BRK-B, BTC-USD), as Yahoo/Polygon-style feeds do.
+	StyleDash
+)
+
+// cryptoBases are the crypto assets recognized when parsing concatenated pairs (BTCUSD).
+var cryptoBases = map[string]bool{
+	"BTC": true, "ETH": true, "SOL": true, "LTC": true, "DOGE": true,
+	"AVAX": true, "LINK": true, "DOT": true, "UNI": true, "XRP": true,
+}
+
+// cryptoQuotes are the quote currencies recognized in pairs.
+var cryptoQuotes = []string{"USDT", "USDC", "USD", "EUR", "BTC"}
+
+// fxCurrencies are the currencies recognized in forex pairs (EUR/USD, EURUSD).
+var fxCurrencies = map[string]bool{
+	"USD": true, "EUR": true, "GBP": true, "JPY": true, "CHF": true, "CAD": true,
+	"AUD": true, "NZD": true, "SEK": true, "NOK": true, "DKK": true, "PLN": true,
+	"CZK": true, "HUF": true, "MXN": true, "SGD": true, "HKD": true, "CNH": true,
+}
+
+// aliases maps common names / legacy tickers to canonical symbols.
+var aliases = map[string]string{
+	"GOOGLE":   "GOOGL",
+	"FACEBOOK": "META",
+	"FB":       "META",
+	"BITCOIN":  "BTC/USD",
+	"ETHEREUM": "ETH/USD",
+	"XBT":      "BTC/USD",
+	"XBTUSD":   "BTC/USD",
+	"BRKB":     "BRK.B",
+	"BRKA":     "BRK.A",
+}
+
+// RegisterAliases adds user-defined aliases ("ALIAS=SYMBOL" entries), overriding built-ins.
+func RegisterAliases(entries []string) {
+	for _, e := range entries {
+		parts := strings.SplitN(e, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		from := strings.ToUpper(strings.TrimSpace(parts[0]))
+		to := Normalize(parts[1])
+		if from != "" && to != "" {
+			aliases[from] = to
+		}
+	}
+}
+
+// Normalize converts user input into the canonical (Alpaca) form.
+// Examples: "$aapl" -> "AAPL", "brk-b" -> "BRK.B", "btc-usd"/"BTCUSD" -> "BTC/USD", "google" -> "GOOGL",
+// "eurusd"/"EUR-USD" -> "EUR/USD".
+func Normalize(input string) string {
+	s := strings.ToUpper(strings.TrimSpace(input))
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		return ""
+	}
+
+	if alias, ok := aliases[s]; ok {
+		return alias
+	}
+
+	// Crypto pairs with a separator: BTC-USD, BTC_USD, BTC/USD
+	for _, sep := range []string{"/", "-", "_"} {
+		if idx := strings.Index(s, sep); idx > 0 {
+			base, quote := s[:idx], s[idx+1:]
+			if cryptoBases[base] && isCryptoQuote(quote) {
+				return base + "/" + quote
+			}
+			if isForexPair(base, quote) {
+				return base + "/" + quote
+			}
+			// Share classes: BRK-B, BRK/B, BRK_B -> BRK.B
+			if len(quote) == 1 {
+				return base + "." + quote
+			}
+		}
+	}
+
+	// Concatenated crypto pairs: BTCUSD -> BTC/USD
+	for _, q := range cryptoQuotes {
+		if strings.HasSuffix(s, q) && cryptoBases[strings.TrimSuffix(s, q)] {
+			return strings.TrimSuffix(s, q) + "/" + q
+		}
+	}
+
+	// Concatenated forex pairs: EURUSD -> EUR/USD (listed tickers have at most 5 letters)
+	if len(s) == 6 && isForexPair(s[:3], s[3:]) {
+		return s[:3] + "/" + s[3:]
+	}
+
+	// Note: bare bases (e.g., "BTC") are left alone, they collide with listed ETF tickers.
+	return s
+}
+
+func isCryptoQuote(q string) bool {
+	for _, c := range cryptoQuotes {
+		if q == c {
+			return true
+		}
+	}
+	return false
+}
+
+func isForexPair(base, quote string) bool {
+	return base != quote && fxCurrencies[base] && fxCurrencies[quote]
+}
+
+// IsForex reports whether a canonical symbol is a forex pair. FX pairs can be watched
+// (FX_PROVIDER) but not traded.
+func IsForex(symbol string) bool {
+	base, quote, ok := strings.Cut(symbol, "/")
+	return ok && isForexPair(base, quote)
+}
+
+// IsCrypto reports whether a canonical symbol is a crypto pair.
+func IsCrypto(symbol string) bool {
+	idx := strings.Index(symbol, "/")
+	return idx > 0 && cryptoBases[symbol[:idx]]
+}
+
+// Format renders a canonical symbol for a provider's convention.
+func Format(symbol string, style Style) string {
+	if style == StyleDash {
+		return strings.NewReplacer("/", "-", ".", "-").Replace(symbol)
+	}
+	return symbol
+}
@@ -23,7 +23,7 @@ func Notify(text string) {
 
 	payload := map[string]string{
 		"chat_id":    chatID,
-		"text":       text,
+		"text":       labelMarkdown(text),
 		"parse_mode": "Markdown",
 	}
 
@@ -0,0 +1,67 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// maxMessageLen is Telegram's text limit per message.
+const maxMessageLen = 4096
+
+// SendPlain sends a plain-text message (no Markdown, so partial text cannot break parsing)
+// and returns its message ID for later edits.
+func SendPlain(text string) (int, error) {
+	var res struct {
+		MessageID int `json:"message_id"`
+	}
+	err := call("sendMessage", map[string]interface{}{"text": clip(labelPlain(text))}, &res)
+	return res.MessageID, err
+}
+
+// EditPlain replaces the text of a message sent with SendPlain.
+func EditPlain(messageID int, text string) error {
+	return call("editMessageText", map[string]interface{}{"message_id": messageID, "text": clip(labelPlain(text))}, nil)
+}
+
+func clip(text string) string {
+	if len(text) > maxMessageLen {
+		return text[:maxMessageLen-3] + "..."
+	}
+	return text
+}
+
+// call posts a Bot API method for the configured chat and decodes its result.
+func call(method string, payload map[string]interface{}, out interface{}) error {
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	chatID := os.Getenv("TELEGRAM_CHAT_ID")
+	if token == "" || chatID == "" {
+		return fmt.Errorf("telegram credentials missing")
+	}
+	payload["chat_id"] = chatID
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(fmt.Sprintf("https://api.telegram.org/bot%s/%s", token, method), "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var env struct {
+		Ok          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("telegram %s: %v", method, err)
+	}
+	if !env.Ok {
+		return fmt.Errorf("telegram %s: %s", method, env.Description)
+	}
+	if out != nil {
+		return json.Unmarshal(env.Result, out)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// DownloadFile fetches an uploaded file (e.g., a document sent to the bot) by its
+// file ID. Files larger than maxBytes are refused.
+func DownloadFile(fileID string, maxBytes int64) ([]byte, error) {
+	var file struct {
+		FilePath string `json:"file_path"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := call("getFile", map[string]interface{}{"file_id": fileID}, &file); err != nil {
+		return nil, err
+	}
+	if file.FileSize > maxBytes {
+		return nil, fmt.Errorf("file too large (%d bytes, max %d)", file.FileSize, maxBytes)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("https://api.telegram.org/file/bot%s/%s", os.Getenv("TELEGRAM_BOT_TOKEN"), file.FilePath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("telegram file download: %s", resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxBytes {
+		return nil, fmt.Errorf("file too large (max %d bytes)", maxBytes)
+	}
+	return b, nil
+}
+
+// SendDocument uploads content as a file to the configured chat.
+func SendDocument(fileName string, content []byte, caption string) error {
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	chatID := os.Getenv("TELEGRAM_CHAT_ID")
+	if token == "" || chatID == "" {
+		return fmt.Errorf("telegram credentials missing")
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("chat_id", chatID)
+	if caption != "" {
+		mw.WriteField("caption", clip(labelPlain(caption)))
+	}
+	part, err := mw.CreateFormFile("document", fileName)
+	if err != nil {
+		return err
+	}
+	part.Write(content)
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	resp, err := http.Post(fmt.Sprintf("https://api.telegram.org/bot%s/sendDocument", token), mw.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var env struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return fmt.Errorf("telegram sendDocument: %v", err)
+	}
+	if !env.Ok {
+		return fmt.Errorf("telegram sendDocument: %s", env.Description)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package telegram
+
+import "strings"
+
+// Set when several watcher instances share one bot and chat (SHARD_NAME).
+var (
+	instanceLabel  string // Shown in front of every message this process sends
+	callbackPrefix string // Prepended to button data so the primary can route the press back
+)
+
+// SetInstance labels outgoing messages with name. A secondary instance (one whose
+// updates arrive through the primary's listener) also prefixes its button data with
+// "name:", which the primary strips when it relays the press.
+func SetInstance(name string, secondary bool) {
+	instanceLabel = name
+	callbackPrefix = ""
+	if secondary && name != "" {
+		callbackPrefix = name + ":"
+	}
+}
+
+// SplitCallback splits relayed button data into the instance name and the original data.
+// ok is false for data without an instance prefix.
+func SplitCallback(data string) (instance, rest string, ok bool) {
+	instance, rest, ok = strings.Cut(data, ":")
+	return instance, rest, ok && instance != ""
+}
+
+// labelMarkdown prefixes a Markdown message with the instance label.
+func labelMarkdown(text string) string {
+	if instanceLabel == "" || text == "" {
+		return text
+	}
+	return "*[" + instanceLabel + "]* " + text
+}
+
+// labelPlain prefixes a plain-text message with the instance label.
+func labelPlain(text string) string {
+	if instanceLabel == "" || text == "" {
+		return text
+	}
+	return "[" + instanceLabel + "] " + text
+}
@@ -15,7 +15,12 @@ import (
 type Update struct {
 	UpdateID int `json:"update_id"`
 	Message  struct {
-		Text string `json:"text"`
+		Text     string `json:"text"`
+		Caption  string `json:"caption"` // Text sent with a document
+		Document struct {
+			FileID   string `json:"file_id"`
+			FileName string `json:"file_name"`
+		} `json:"document"`
 		Chat struct {
 			ID int64 `json:"id"`
 		} `json:"chat"`
@@ -47,8 +52,14 @@ type UpdateResponse struct {
 // CommandHandler defines the callback signature for processing commands
 type CommandHandler func(command string) string
 
+// DocumentHandler processes an uploaded file with its caption.
+type DocumentHandler func(caption, fileName string, content []byte) string
+
+// maxDocumentBytes caps uploads the listener downloads (CSV imports are small).
+const maxDocumentBytes = 1 << 20
+
 // StartListener begins long-polling for updates.
-func StartListener(cmdHandler CommandHandler, cbHandler CallbackHandler) {
+func StartListener(cmdHandler CommandHandler, cbHandler CallbackHandler, docHandler DocumentHandler) {
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	authChatIDStr := os.Getenv("TELEGRAM_CHAT_ID")
 
@@ -116,6 +127,14 @@ func StartListener(cmdHandler CommandHandler, cbHandler CallbackHandler) {
 				log.Printf("Callback received: %s", text)
 				response := cbHandler(update.CallbackQuery.ID, text)
 				Notify(response) // Or handle specific answerCallback logic
+			} else if doc := update.Message.Document; doc.FileID != "" {
+				log.Printf("Document received: %s (caption: %q)", doc.FileName, update.Message.Caption)
+				content, err := DownloadFile(doc.FileID, maxDocumentBytes)
+				if err != nil {
+					Notify(fmt.Sprintf("❌ Could not download %s: %v", doc.FileName, err))
+					continue
+				}
+				Notify(docHandler(strings.TrimSpace(update.Message.Caption), doc.FileName, content))
 			} else {
 				// Process Command
 				text = strings.TrimSpace(text)
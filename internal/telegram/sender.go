@@ -25,6 +25,13 @@ func SendInteractiveMessage(text string, buttons []Button) {
 	}
 
 	// Construct Inline Keyboard
+	if callbackPrefix != "" {
+		prefixed := make([]Button, len(buttons))
+		for i, b := range buttons {
+			prefixed[i] = Button{Text: b.Text, CallbackData: callbackPrefix + b.CallbackData}
+		}
+		buttons = prefixed
+	}
 	var inlineKeyboard [][]Button
 	// For now, we put all buttons in one row (slice of slice)
 	inlineKeyboard = append(inlineKeyboard, buttons)
@@ -38,7 +45,7 @@ func SendInteractiveMessage(text string, buttons []Button) {
 	apiURL := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", token)
 	data := map[string]string{
 		"chat_id":      chatID,
-		"text":         text,
+		"text":         labelMarkdown(text),
 		"parse_mode":   "Markdown",
 		"reply_markup": string(keyboardJSON),
 	}
@@ -0,0 +1,91 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// aiCacheEntry is the last analysis and the snapshot fingerprint it was made from.
+type aiCacheEntry struct {
+	scope     string                     // Ticker focus or universe ("" = global review)
+	positions string                     // Held positions, levels and budget
+	prices    map[string]decimal.Decimal // Prices the model saw
+	analysis  ai.AIAnalysis
+	at        time.Time
+}
+
+// snapshotFingerprint captures what makes two snapshots materially different.
+func (w *Watcher) snapshotFingerprint(scope string, snapshot *ai.PortfolioSnapshot) aiCacheEntry {
+	fp := aiCacheEntry{scope: scope, prices: make(map[string]decimal.Decimal)}
+
+	var sig []string
+	if positions, ok := snapshot.Positions.([]models.Position); ok {
+		for _, p := range positions {
+			sig = append(sig, fmt.Sprintf("%s:%s:%s:%s:%s:%s", p.Ticker, p.Status, p.Quantity, p.StopLoss, p.TakeProfit, p.TrailingStopPct))
+			if p.Status != "ACTIVE" {
+				continue
+			}
+			if price, err := w.provider.GetPrice(w.ctx, p.Ticker); err == nil {
+				fp.prices[p.Ticker] = price
+			}
+		}
+	}
+	sort.Strings(sig)
+	fp.positions = strings.Join(sig, ",") + "|budget:" + snapshot.AvailableBudget.StringFixed(0)
+
+	for t, price := range snapshot.WatchlistPrices {
+		fp.prices[t] = price
+	}
+	for _, u := range snapshot.UniverseData {
+		fp.prices[u.Ticker] = u.Price
+	}
+	return fp
+}
+
+// cachedAnalysis returns the previous analysis if the snapshot has not materially
+// changed: same scope and positions, every price within AI_CACHE_TOLERANCE_PCT.
+func (w *Watcher) cachedAnalysis(fp aiCacheEntry) (*ai.AIAnalysis, time.Time, bool) {
+	w.mu.RLock()
+	last := w.lastAI
+	w.mu.RUnlock()
+
+	if last == nil || w.config.AICacheTolerancePct <= 0 {
+		return nil, time.Time{}, false
+	}
+	if w.since(last.at) > time.Duration(w.config.AICacheMaxAgeMins)*time.Minute {
+		return nil, time.Time{}, false
+	}
+	if last.scope != fp.scope || last.positions != fp.positions || len(last.prices) != len(fp.prices) {
+		return nil, time.Time{}, false
+	}
+	tolerance := decimal.NewFromFloat(w.config.AICacheTolerancePct)
+	for t, old := range last.prices {
+		now, ok := fp.prices[t]
+		if !ok || !old.IsPositive() {
+			return nil, time.Time{}, false
+		}
+		if now.Sub(old).Abs().Div(old).Mul(decimal.NewFromInt(100)).GreaterThan(tolerance) {
+			return nil, time.Time{}, false
+		}
+	}
+	analysis := last.analysis // Copy: handleAIResult rewrites ActionCommand
+	return &analysis, last.at, true
+}
+
+// storeAnalysis remembers a fresh model answer for later reuse.
+func (w *Watcher) storeAnalysis(fp aiCacheEntry, analysis ai.AIAnalysis) {
+	fp.analysis = analysis
+	fp.at = w.clock.Now()
+	w.mu.Lock()
+	w.lastAI = &fp
+	w.mu.Unlock()
+	log.Printf("AI cache: stored analysis (%d prices, scope %q)", len(fp.prices), fp.scope)
+}
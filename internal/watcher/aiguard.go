@@ -0,0 +1,127 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// aiCommandArity is the allowed verb set for AI action commands and the
+// min/max number of fields (verb and ticker included) for each.
+var aiCommandArity = map[string][2]int{
+	"/buy":    {3, 5}, // /buy <ticker> <qty> [sl] [tp]
+	"/sell":   {2, 2}, // /sell <ticker>
+	"/update": {4, 5}, // /update <ticker> <sl> <tp> [ts_pct]
+}
+
+// sanitizeAICommand rewrites analysis.ActionCommand so only well-formed commands
+// on known tickers survive: an allowed verb, a ticker present in the snapshot or
+// watchlist, numeric arguments only, and /buy quantities capped to the available
+// budget. Returns the rejected commands (each with its reason).
+func (w *Watcher) sanitizeAICommand(analysis *ai.AIAnalysis, snapshot *ai.PortfolioSnapshot) []string {
+	known := w.aiKnownTickers(snapshot)
+
+	var kept, rejected []string
+	for _, cmd := range strings.Split(analysis.ActionCommand, ";") {
+		cmd = strings.TrimSpace(cmd)
+		if cmd == "" {
+			continue
+		}
+		clean, reason := w.sanitizeAIStep(cmd, known, snapshot.AvailableBudget)
+		if reason != "" {
+			rejected = append(rejected, fmt.Sprintf("`%s`: %s", cmd, reason))
+			continue
+		}
+		kept = append(kept, clean)
+	}
+
+	if len(rejected) > 0 {
+		log.Printf("[AI_GUARD_REJECTION] Raw output: %q | Rejected: %s", analysis.ActionCommand, strings.Join(rejected, "; "))
+	}
+	analysis.ActionCommand = strings.Join(kept, "; ")
+	return rejected
+}
+
+// sanitizeAIStep validates one command. Returns the normalized command or a rejection reason.
+func (w *Watcher) sanitizeAIStep(cmd string, known map[string]bool, budget decimal.Decimal) (string, string) {
+	parts := strings.Fields(cmd)
+	verb := strings.ToLower(parts[0])
+	arity, ok := aiCommandArity[verb]
+	if !ok {
+		return "", "verb not allowed"
+	}
+	if len(parts) < arity[0] || len(parts) > arity[1] {
+		return "", "wrong number of arguments"
+	}
+
+	ticker := symbols.Normalize(parts[1])
+	if !known[ticker] {
+		return "", fmt.Sprintf("ticker %s not in snapshot or watchlist", ticker)
+	}
+
+	args := make([]decimal.Decimal, 0, len(parts)-2)
+	for _, a := range parts[2:] {
+		v, err := decimal.NewFromString(a)
+		if err != nil || v.IsNegative() {
+			return "", fmt.Sprintf("invalid argument %q", a)
+		}
+		args = append(args, v)
+	}
+
+	if verb == "/buy" {
+		qty := args[0]
+		if !qty.IsPositive() {
+			return "", "quantity must be positive"
+		}
+		price, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker)
+		if err != nil || !price.IsPositive() {
+			return "", "no price to size the order"
+		}
+		if qty.Mul(price).GreaterThan(budget) {
+			capped := money.Qty(ticker, budget.Div(price), false)
+			if !capped.IsPositive() {
+				return "", fmt.Sprintf("cost exceeds available budget ($%s)", budget.StringFixed(2))
+			}
+			log.Printf("AI Guard: capped %s qty %s -> %s (budget $%s)", ticker, qty, capped, budget.StringFixed(2))
+			parts[2] = capped.String()
+		}
+	}
+
+	parts[0], parts[1] = verb, ticker
+	return strings.Join(parts, " "), ""
+}
+
+// aiKnownTickers is the set of symbols the AI may act on: held positions,
+// the watchlist, and the symbols sent in the snapshot.
+func (w *Watcher) aiKnownTickers(snapshot *ai.PortfolioSnapshot) map[string]bool {
+	known := make(map[string]bool)
+	w.mu.RLock()
+	for _, p := range w.state.Positions {
+		known[p.Ticker] = true
+	}
+	for _, t := range w.watchlistTickers() {
+		known[t] = true
+	}
+	w.mu.RUnlock()
+
+	if positions, ok := snapshot.Positions.([]models.Position); ok {
+		for _, p := range positions {
+			known[p.Ticker] = true
+		}
+	}
+	for t := range snapshot.WatchlistPrices {
+		known[symbols.Normalize(t)] = true
+	}
+	for _, u := range snapshot.UniverseData {
+		known[u.Ticker] = true
+	}
+	return known
+}
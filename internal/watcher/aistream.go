@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/clock"
+	"alpha_trading/internal/telegram"
+)
+
+// aiStreamEditInterval throttles message edits (Telegram rate-limits edits per chat).
+const aiStreamEditInterval = 1500 * time.Millisecond
+
+// reportStream shows a streamed AI answer as progressive edits of one Telegram message.
+// Only the "analysis" text is shown while streaming; the full report (with buttons)
+// still follows from handleAIResult once the answer is complete.
+type reportStream struct {
+	clock    clock.Clock
+	header   string
+	msgID    int
+	lastEdit time.Time
+	lastText string
+}
+
+// newReportStream posts the placeholder message. Returns nil if streaming is off or
+// the message could not be sent (the caller then uses the blocking call).
+func (w *Watcher) newReportStream(isManual bool, title string) *reportStream {
+	if !isManual || !w.config.AIStreamReports {
+		return nil
+	}
+	header := fmt.Sprintf("🤖 AI REVIEW (%s)\n", title)
+	id, err := telegram.SendPlain(header + "⏳ Thinking...")
+	if err != nil {
+		log.Printf("AI Stream: placeholder failed, falling back to blocking call: %v", err)
+		return nil
+	}
+	return &reportStream{clock: w.clock, header: header, msgID: id}
+}
+
+// update receives the accumulated raw model output.
+func (s *reportStream) update(raw string) {
+	if s.clock.Now().Sub(s.lastEdit) < aiStreamEditInterval {
+		return
+	}
+	text := strings.TrimSpace(ai.PartialField(raw, "analysis"))
+	if text == "" || text == s.lastText {
+		return
+	}
+	s.edit(s.header + text + " ▌")
+	s.lastText = text
+}
+
+// finish replaces the placeholder with the final analysis text (or the failure).
+func (s *reportStream) finish(analysis *ai.AIAnalysis, err error) {
+	if err != nil {
+		s.edit(s.header + "⚠️ Analysis failed (details follow).")
+		return
+	}
+	s.edit(fmt.Sprintf("%s%s\n\n✅ Complete (%s). Report follows.", s.header, strings.TrimSpace(analysis.Analysis), analysis.Model))
+}
+
+func (s *reportStream) edit(text string) {
+	s.lastEdit = s.clock.Now()
+	if err := telegram.EditPlain(s.msgID, text); err != nil {
+		log.Printf("AI Stream: edit failed: %v", err)
+	}
+}
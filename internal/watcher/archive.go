@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+const historyDefaultDays = 30
+
+// archiveTrade moves a closed position into the trade archive.
+// A zero exitPrice means the fill is unknown (P/L is then left at zero); otherwise
+// the P/L includes the position's accrued carry.
+func (w *Watcher) archiveTrade(pos models.Position, exitPrice decimal.Decimal, reason string) {
+	pl := decimal.Zero
+	if exitPrice.IsPositive() {
+		pl = exitPrice.Sub(pos.EntryPrice).Mul(pos.Quantity).Add(pos.Carry)
+	}
+	t := models.ArchivedTrade{
+		Ticker:     pos.Ticker,
+		Quantity:   pos.Quantity,
+		EntryPrice: pos.EntryPrice,
+		ExitPrice:  exitPrice,
+		PL:         pl,
+		Carry:      pos.Carry,
+		OpenedAt:   pos.OpenedAt,
+		ClosedAt:   w.clock.Now(),
+		Reason:     reason,
+		ThesisID:   pos.ThesisID,
+		Book:       pos.Book,
+	}
+	if err := storage.AppendArchive(t); err != nil {
+		log.Printf("ERROR: Failed to archive %s: %v", pos.Ticker, err)
+	}
+}
+
+// handleHistoryCommand queries the trade archive.
+// /history         -> last 30 days
+// /history 90d     -> last N days
+// /history TICKER  -> all archived trades for a symbol
+func (w *Watcher) handleHistoryCommand(parts []string) string {
+	trades, err := storage.LoadArchive()
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read trade archive: %v", err)
+	}
+
+	days := historyDefaultDays
+	ticker := ""
+	if len(parts) >= 2 {
+		arg := strings.ToLower(parts[1])
+		if n, err := strconv.Atoi(strings.TrimSuffix(arg, "d")); err == nil && strings.HasSuffix(arg, "d") && n > 0 {
+			days = n
+		} else {
+			ticker = symbols.Normalize(parts[1])
+		}
+	}
+
+	cutoff := w.clock.Now().AddDate(0, 0, -days)
+	var selected []models.ArchivedTrade
+	for _, t := range trades {
+		if ticker != "" {
+			if t.Ticker == ticker {
+				selected = append(selected, t)
+			}
+		} else if t.ClosedAt.After(cutoff) {
+			selected = append(selected, t)
+		}
+	}
+
+	title := fmt.Sprintf("last %d days", days)
+	if ticker != "" {
+		title = ticker
+	}
+	if len(selected) == 0 {
+		return fmt.Sprintf("📜 No archived trades (%s).", title)
+	}
+
+	// Newest first
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ClosedAt.After(selected[j].ClosedAt) })
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📜 *TRADE HISTORY (%s)*\n", title))
+	total := decimal.Zero
+	for _, t := range selected {
+		exit := "?"
+		pl := "?"
+		if t.ExitPrice.IsPositive() {
+			exit = money.USD(t.ExitPrice)
+			pl = plString(t.PL)
+			total = total.Add(t.PL)
+			if !t.Carry.IsZero() {
+				pl += fmt.Sprintf(" (carry %s)", plString(t.Carry))
+			}
+		}
+		hold := "?"
+		if !t.OpenedAt.IsZero() {
+			hold = formatHold(t.ClosedAt.Sub(t.OpenedAt))
+		}
+		sb.WriteString(fmt.Sprintf("\n• *%s* %s (%s)\n  %s @ $%s → %s | P/L: %s | Held: %s\n",
+			t.Ticker, t.ClosedAt.Format("2006-01-02"), t.Reason, t.Quantity.String(), t.EntryPrice.StringFixed(2), exit, pl, hold))
+	}
+	sb.WriteString(fmt.Sprintf("\nTrades: %d | Realized P/L: %s", len(selected), plString(total)))
+	return sb.String()
+}
+
+// formatHold renders a holding period as days/hours.
+func formatHold(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%.1fh", d.Hours())
+	}
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
@@ -0,0 +1,204 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// Audit event types (see models.AuditEvent).
+const (
+	auditProposed  = "PROPOSED"
+	auditCancelled = "CANCELLED"
+	auditFilled    = "FILLED"
+	auditTrigger   = "TRIGGER"
+	auditClosed    = "CLOSED"
+	auditAdjusted  = "ADJUSTED"  // Levels rescaled by a corporate action
+	auditEdited    = "EDITED"    // Field changed by hand (/state set)
+	auditImported  = "IMPORTED"  // Levels set from a CSV import (/import)
+	auditChecklist = "CHECKLIST" // Pre-trade checklist answers (TRADE_CHECKLIST)
+)
+
+// recordAudit appends a step to the decision trail. Failures are logged, never fatal.
+func (w *Watcher) recordAudit(ticker, event, actor string, price decimal.Decimal, detail string) {
+	e := models.AuditEvent{
+		Time:   w.clock.Now(),
+		Ticker: ticker,
+		Event:  event,
+		Actor:  actor,
+		Price:  price,
+		Detail: detail,
+	}
+	if err := storage.AppendAudit(e); err != nil {
+		log.Printf("ERROR: Failed to write audit event for %s: %v", ticker, err)
+	}
+}
+
+// thesisOrigin describes who opened a position from its ThesisID prefix.
+func thesisOrigin(thesisID string) string {
+	switch {
+	case strings.HasPrefix(thesisID, "AI_"):
+		return "AI proposal (approved by user)"
+	case strings.HasPrefix(thesisID, "ROTATION_"):
+		return "AI rotation (approved by user)"
+	case strings.HasPrefix(thesisID, "MANUAL_"):
+		return "Manual /buy"
+	case strings.HasPrefix(thesisID, "IMPORTED"):
+		return "Imported from broker (no local proposal)"
+	}
+	return "Unknown"
+}
+
+// lastTradeEvents returns the events of the most recent trade: from the proposal
+// that led to the last fill through everything after it. Without a fill, the
+// trail starts at the last proposal.
+func lastTradeEvents(events []models.AuditEvent) []models.AuditEvent {
+	start := -1
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Event == auditFilled {
+			start = i
+			break
+		}
+	}
+	for i := start - 1; i >= 0; i-- {
+		if events[i].Event == auditProposed {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		for i := len(events) - 1; i >= 0; i-- {
+			if events[i].Event == auditProposed {
+				start = i
+				break
+			}
+		}
+	}
+	if start == -1 {
+		return nil
+	}
+	return events[start:]
+}
+
+// handleWhyCommand reconstructs the decision trail of the latest trade in a symbol.
+// /why TICKER
+func (w *Watcher) handleWhyCommand(parts []string) string {
+	if len(parts) < 2 {
+		return "Usage: /why <ticker>"
+	}
+	ticker := symbols.Normalize(parts[1])
+
+	events, err := storage.LoadAudit(ticker)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read audit log: %v", err)
+	}
+	trail := lastTradeEvents(events)
+
+	// Closing context from the archive (covers trades older than the audit log)
+	var lastClosed *models.ArchivedTrade
+	if trades, err := storage.LoadArchive(); err == nil {
+		for i := len(trades) - 1; i >= 0; i-- {
+			if trades[i].Ticker == ticker {
+				lastClosed = &trades[i]
+				break
+			}
+		}
+	}
+
+	var open *models.Position
+	w.mu.RLock()
+	for _, p := range w.state.Positions {
+		if p.Ticker == ticker && p.Status == "ACTIVE" {
+			pos := p
+			open = &pos
+			break
+		}
+	}
+	w.mu.RUnlock()
+
+	if len(trail) == 0 && lastClosed == nil && open == nil {
+		return fmt.Sprintf("🔍 No recorded trades for %s.", ticker)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 *WHY: %s*\n", ticker))
+
+	if len(trail) > 0 {
+		first := trail[0]
+		sb.WriteString(fmt.Sprintf("Proposed by: %s @ $%s\n", first.Actor, first.Price.StringFixed(2)))
+		sb.WriteString("\n*Timeline (CET)*\n")
+		for _, e := range trail {
+			line := fmt.Sprintf("• %s %s (%s)", e.Time.In(config.CetLoc).Format("01-02 15:04"), e.Event, e.Actor)
+			if e.Price.IsPositive() {
+				line += " @ $" + e.Price.StringFixed(2)
+			}
+			if e.Detail != "" {
+				line += " — " + e.Detail
+			}
+			sb.WriteString(line + "\n")
+		}
+	} else {
+		sb.WriteString("ℹ️ No audit trail recorded (trade predates the audit log).\n")
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case open != nil:
+		sb.WriteString(fmt.Sprintf("Origin: %s\nStatus: OPEN (%s @ $%s, SL $%s, TP $%s)",
+			thesisOrigin(open.ThesisID), open.Quantity.String(), open.EntryPrice.StringFixed(2),
+			open.StopLoss.StringFixed(2), open.TakeProfit.StringFixed(2)))
+	case lastClosed != nil:
+		pl := "?"
+		if lastClosed.ExitPrice.IsPositive() {
+			pl = plString(lastClosed.PL)
+		}
+		sb.WriteString(fmt.Sprintf("Origin: %s\nClosed by: %s on %s | P/L: %s",
+			thesisOrigin(lastClosed.ThesisID), lastClosed.Reason, lastClosed.ClosedAt.In(config.CetLoc).Format("2006-01-02"), pl))
+	default:
+		sb.WriteString("Status: never filled")
+	}
+	return sb.String()
+}
+
+// closeActor maps a close reason to the party that initiated the exit.
+func closeActor(reason string) string {
+	switch reason {
+	case "MANUAL":
+		return "USER"
+	case "ROTATION":
+		return "AI"
+	}
+	return "SYSTEM"
+}
+
+// auditAIProposal records a PROPOSED event for every /buy and /sell in an AI action command.
+func (w *Watcher) auditAIProposal(analysis *ai.AIAnalysis) {
+	detail := fmt.Sprintf("model %s, confidence %.2f, risk %s", analysis.Model, analysis.ConfidenceScore, analysis.RiskAssessment)
+	if summary := strings.TrimSpace(analysis.Analysis); summary != "" {
+		if len(summary) > 120 {
+			summary = summary[:120] + "…"
+		}
+		detail += ": " + summary
+	}
+	for _, cmd := range strings.Split(analysis.ActionCommand, ";") {
+		parts := strings.Fields(cmd)
+		if len(parts) < 2 {
+			continue
+		}
+		verb := strings.ToLower(parts[0])
+		if verb != "/buy" && verb != "/sell" {
+			continue
+		}
+		ticker := symbols.Normalize(parts[1])
+		price, _ := w.provider.GetPrice(w.ctx, ticker)
+		w.recordAudit(ticker, auditProposed, "AI", price, strings.TrimSpace(cmd)+" ("+detail+")")
+	}
+}
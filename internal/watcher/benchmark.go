@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// handleBenchmarkCommand manages comparison portfolios.
+// /benchmark                                   -> list
+// /benchmark add <name> <TICKER[=weight]> ...  -> register (equal weights if omitted)
+// /benchmark remove <name>                     -> delete
+func (w *Watcher) handleBenchmarkCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.getBenchmarks()
+	}
+
+	switch strings.ToLower(parts[1]) {
+	case "add":
+		if len(parts) < 4 {
+			return "Usage: /benchmark add <name> <TICKER[=weight]> [TICKER[=weight] ...]"
+		}
+		return w.addBenchmark(parts[2], parts[3:])
+	case "remove", "rm":
+		if len(parts) < 3 {
+			return "Usage: /benchmark remove <name>"
+		}
+		return w.removeBenchmark(parts[2])
+	default:
+		return "Usage: /benchmark [add|remove] <name> ..."
+	}
+}
+
+func (w *Watcher) addBenchmark(name string, specs []string) string {
+	weights := make(map[string]decimal.Decimal)
+	for _, spec := range specs {
+		ticker := spec
+		weight := decimal.NewFromInt(1)
+		if idx := strings.Index(spec, "="); idx != -1 {
+			ticker = spec[:idx]
+			wgt, err := decimal.NewFromString(spec[idx+1:])
+			if err != nil || !wgt.IsPositive() {
+				return fmt.Sprintf("⚠️ Invalid weight in '%s'.", spec)
+			}
+			weight = wgt
+		}
+		ticker = strings.ToUpper(strings.TrimSpace(ticker))
+		if ticker == "" {
+			return fmt.Sprintf("⚠️ Invalid component '%s'.", spec)
+		}
+		weights[ticker] = weight
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, b := range w.state.Benchmarks {
+		if strings.EqualFold(b.Name, name) {
+			w.state.Benchmarks[i].Weights = weights
+			w.saveStateLocked()
+			return fmt.Sprintf("🔁 Benchmark '%s' updated: %s", b.Name, formatWeights(weights))
+		}
+	}
+
+	w.state.Benchmarks = append(w.state.Benchmarks, models.Benchmark{Name: name, Weights: weights})
+	w.saveStateLocked()
+	return fmt.Sprintf("📐 Benchmark '%s' registered: %s", name, formatWeights(weights))
+}
+
+func (w *Watcher) removeBenchmark(name string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, b := range w.state.Benchmarks {
+		if strings.EqualFold(b.Name, name) {
+			w.state.Benchmarks = append(w.state.Benchmarks[:i], w.state.Benchmarks[i+1:]...)
+			w.saveStateLocked()
+			return fmt.Sprintf("🗑️ Benchmark '%s' removed.", b.Name)
+		}
+	}
+	return fmt.Sprintf("⚠️ Benchmark '%s' not found.", name)
+}
+
+func (w *Watcher) getBenchmarks() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if len(w.state.Benchmarks) == 0 {
+		return "📐 No benchmarks registered. Example: /benchmark add 60-40 SPY=60 AGG=40"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📐 *BENCHMARKS*\n")
+	for _, b := range w.state.Benchmarks {
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", b.Name, formatWeights(b.Weights)))
+	}
+	return sb.String()
+}
+
+// benchmarkDailyReturn computes the hypothetical daily % change of a weighted basket
+// from the last two daily closes of each component. Weights are normalized so they sum to 1.
+func (w *Watcher) benchmarkDailyReturn(b models.Benchmark) (decimal.Decimal, error) {
+	totalWeight := decimal.Zero
+	for _, wgt := range b.Weights {
+		totalWeight = totalWeight.Add(wgt)
+	}
+	if !totalWeight.IsPositive() {
+		return decimal.Zero, fmt.Errorf("benchmark %s has no weights", b.Name)
+	}
+
+	ret := decimal.Zero
+	for ticker, wgt := range b.Weights {
+		bars, err := w.provider.GetBars(w.ctx, ticker, 2)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("bars for %s: %v", ticker, err)
+		}
+		if len(bars) < 2 || bars[0].Close == 0 {
+			return decimal.Zero, fmt.Errorf("insufficient bars for %s", ticker)
+		}
+		prev := decimal.NewFromFloat(bars[0].Close)
+		last := decimal.NewFromFloat(bars[1].Close)
+		change := last.Sub(prev).Div(prev)
+		ret = ret.Add(change.Mul(wgt.Div(totalWeight)))
+	}
+	return ret.Mul(decimal.NewFromInt(100)), nil
+}
+
+// buildBenchmarkSection renders the EOD comparison block against the portfolio's own daily change.
+func (w *Watcher) buildBenchmarkSection(portfolioPct decimal.Decimal) string {
+	w.mu.RLock()
+	benchmarks := make([]models.Benchmark, len(w.state.Benchmarks))
+	copy(benchmarks, w.state.Benchmarks)
+	w.mu.RUnlock()
+
+	if len(benchmarks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("*Benchmarks (Day)*\n")
+	sb.WriteString(fmt.Sprintf("• Mine: %s%%\n", portfolioPct.StringFixed(2)))
+	for _, b := range benchmarks {
+		pct, err := w.benchmarkDailyReturn(b)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("• %s: ⚠️ N/A\n", b.Name))
+			continue
+		}
+		alpha := portfolioPct.Sub(pct)
+		sb.WriteString(fmt.Sprintf("• %s: %s%% (Δ %s%%)\n", b.Name, pct.StringFixed(2), alpha.StringFixed(2)))
+	}
+	return sb.String()
+}
+
+// formatWeights renders weights as normalized percentages in a stable (sorted) order.
+func formatWeights(weights map[string]decimal.Decimal) string {
+	total := decimal.Zero
+	tickers := make([]string, 0, len(weights))
+	for t, wgt := range weights {
+		total = total.Add(wgt)
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+
+	var out []string
+	for _, t := range tickers {
+		pct := decimal.Zero
+		if total.IsPositive() {
+			pct = weights[t].Div(total).Mul(decimal.NewFromInt(100))
+		}
+		out = append(out, fmt.Sprintf("%s %s%%", t, pct.StringFixed(0)))
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,343 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"alpha_trading/internal/budget"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// unassignedBook labels positions outside every book in reports.
+const unassignedBook = "(none)"
+
+// handleBookCommand manages virtual sub-portfolios.
+// /book                                              -> all books: budget use, P/L
+// /book <name>                                       -> one book with its positions
+// /book add <name> <budget> [max=N] [sl=%] [tp=%]    -> create or update
+// /book remove <name>                                -> delete (positions become unassigned)
+// /book assign <ticker> <name|none>                  -> move a position
+func (w *Watcher) handleBookCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.getBooks()
+	}
+
+	switch strings.ToLower(parts[1]) {
+	case "add":
+		if len(parts) < 4 {
+			return "Usage: /book add <name> <budget> [max=N] [sl=%] [tp=%]"
+		}
+		return w.addBook(parts[2], parts[3], parts[4:])
+	case "remove", "rm":
+		if len(parts) < 3 {
+			return "Usage: /book remove <name>"
+		}
+		return w.removeBook(parts[2])
+	case "assign":
+		if len(parts) < 4 {
+			return "Usage: /book assign <ticker> <name|none>"
+		}
+		return w.assignBook(symbols.Normalize(parts[2]), parts[3])
+	default:
+		return w.getBook(parts[1])
+	}
+}
+
+// bookLocked finds a book by name (case-insensitive). Caller holds w.mu.
+func (w *Watcher) bookLocked(name string) (models.Book, bool) {
+	for _, b := range w.state.Books {
+		if strings.EqualFold(b.Name, name) {
+			return b, true
+		}
+	}
+	return models.Book{}, false
+}
+
+func (w *Watcher) addBook(name, budgetArg string, opts []string) string {
+	name = strings.ToLower(name)
+	if name == "none" || strings.ContainsAny(name, "=$") {
+		return fmt.Sprintf("⚠️ Invalid book name '%s'.", name)
+	}
+	limit, err := decimal.NewFromString(strings.TrimPrefix(budgetArg, "$"))
+	if err != nil || !limit.IsPositive() {
+		return "⚠️ Budget must be a positive amount."
+	}
+	b := models.Book{Name: name, Budget: money.Cash(limit), CreatedAt: w.clock.Now()}
+	for _, opt := range opts {
+		kv := strings.SplitN(strings.ToLower(opt), "=", 2)
+		if len(kv) != 2 {
+			return fmt.Sprintf("⚠️ Invalid option '%s' (use max=N, sl=%%, tp=%%).", opt)
+		}
+		switch kv[0] {
+		case "max":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil || n < 0 {
+				return "⚠️ max must be a whole number (0 = no limit)."
+			}
+			b.MaxPositions = n
+		case "sl", "tp":
+			pct, err := strconv.ParseFloat(strings.TrimSuffix(kv[1], "%"), 64)
+			if err != nil || pct <= 0 || pct >= 100 {
+				return fmt.Sprintf("⚠️ %s must be a percentage between 0 and 100.", kv[0])
+			}
+			if kv[0] == "sl" {
+				b.StopLossPct = pct
+			} else {
+				b.TakeProfitPct = pct
+			}
+		default:
+			return fmt.Sprintf("⚠️ Unknown option '%s' (use max=N, sl=%%, tp=%%).", opt)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, existing := range w.state.Books {
+		if existing.Name == name {
+			b.CreatedAt = existing.CreatedAt
+			w.state.Books[i] = b
+			w.saveStateLocked()
+			return fmt.Sprintf("🔁 Book '%s' updated: %s", name, formatBookLimits(b))
+		}
+	}
+	w.state.Books = append(w.state.Books, b)
+	w.saveStateLocked()
+	return fmt.Sprintf("📚 Book '%s' created: %s\nAssign positions with /book assign <ticker> %s, or buy into it with /buy ... book=%s.", name, formatBookLimits(b), name, name)
+}
+
+func (w *Watcher) removeBook(name string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, b := range w.state.Books {
+		if !strings.EqualFold(b.Name, name) {
+			continue
+		}
+		w.state.Books = append(w.state.Books[:i], w.state.Books[i+1:]...)
+		moved := 0
+		for j, p := range w.state.Positions {
+			if p.Book == b.Name {
+				w.state.Positions[j].Book = ""
+				moved++
+			}
+		}
+		w.saveStateLocked()
+		return fmt.Sprintf("🗑️ Book '%s' removed. %d position(s) unassigned.", b.Name, moved)
+	}
+	return fmt.Sprintf("⚠️ Book '%s' not found.", name)
+}
+
+func (w *Watcher) assignBook(ticker, name string) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	target := ""
+	if !strings.EqualFold(name, "none") {
+		b, ok := w.bookLocked(name)
+		if !ok {
+			return fmt.Sprintf("⚠️ Book '%s' not found. Create it with /book add.", name)
+		}
+		target = b.Name
+	}
+	for i, p := range w.state.Positions {
+		if p.Ticker != ticker || p.Status != "ACTIVE" {
+			continue
+		}
+		if target != "" {
+			b, _ := w.bookLocked(target)
+			if msg := bookLimitViolation(b, w.state.Positions, p.Quantity.Mul(p.EntryPrice), ticker); msg != "" {
+				return msg
+			}
+		}
+		w.state.Positions[i].Book = target
+		w.saveStateLocked()
+		if target == "" {
+			return fmt.Sprintf("✅ %s removed from its book.", ticker)
+		}
+		return fmt.Sprintf("✅ %s assigned to book '%s'.", ticker, target)
+	}
+	return fmt.Sprintf("⚠️ No active position found for %s.", ticker)
+}
+
+// bookLimitViolation checks a new cost against the book's budget and position cap.
+// ticker already in the book does not count twice. Returns "" when it fits.
+func bookLimitViolation(b models.Book, positions []models.Position, cost decimal.Decimal, ticker string) string {
+	var others []models.Position
+	count := 0
+	for _, p := range positions {
+		if p.Ticker == ticker {
+			continue
+		}
+		others = append(others, p)
+		if p.Book == b.Name && p.Status == "ACTIVE" {
+			count++
+		}
+	}
+	exposure := budget.BookExposure(others, b.Name)
+	if exposure.Add(cost).GreaterThan(b.Budget) {
+		return fmt.Sprintf("❌ Book Budget Violation (%s):\nUsage: ($%s + $%s) = $%s > Budget: $%s",
+			b.Name, exposure.StringFixed(2), money.Cash(cost).StringFixed(2), exposure.Add(cost).StringFixed(2), b.Budget.StringFixed(2))
+	}
+	if b.MaxPositions > 0 && count >= b.MaxPositions {
+		return fmt.Sprintf("❌ Book '%s' is full (%d/%d positions).", b.Name, count, b.MaxPositions)
+	}
+	return ""
+}
+
+func formatBookLimits(b models.Book) string {
+	parts := []string{"budget " + money.USD(b.Budget)}
+	if b.MaxPositions > 0 {
+		parts = append(parts, fmt.Sprintf("max %d positions", b.MaxPositions))
+	}
+	if b.StopLossPct > 0 {
+		parts = append(parts, fmt.Sprintf("SL %.1f%%", b.StopLossPct))
+	}
+	if b.TakeProfitPct > 0 {
+		parts = append(parts, fmt.Sprintf("TP %.1f%%", b.TakeProfitPct))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// bookPerformance is one book's slice of the account.
+type bookPerformance struct {
+	Positions  []models.Position
+	Exposure   decimal.Decimal // Cost basis of ACTIVE positions
+	Value      decimal.Decimal // Market value (cost basis where no price)
+	Unrealized decimal.Decimal
+	Realized   decimal.Decimal // Archived trades closed while in the book
+	Trades     int
+	Wins       int
+}
+
+// bookPerformances groups positions and archived trades by book ("" = unassigned).
+func (w *Watcher) bookPerformances() map[string]*bookPerformance {
+	w.mu.RLock()
+	var active []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			active = append(active, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	perf := make(map[string]*bookPerformance)
+	get := func(book string) *bookPerformance {
+		if perf[book] == nil {
+			perf[book] = &bookPerformance{}
+		}
+		return perf[book]
+	}
+	for _, p := range active {
+		bp := get(p.Book)
+		cost := p.Quantity.Mul(p.EntryPrice)
+		value := cost
+		if price, err := w.provider.GetPrice(w.ctx, p.Ticker); err == nil && price.IsPositive() {
+			value = p.Quantity.Mul(price)
+		}
+		bp.Positions = append(bp.Positions, p)
+		bp.Exposure = bp.Exposure.Add(cost)
+		bp.Value = bp.Value.Add(value)
+		bp.Unrealized = bp.Unrealized.Add(value.Sub(cost).Add(p.Carry))
+	}
+	if trades, err := storage.LoadArchive(); err == nil {
+		for _, t := range trades {
+			if !t.ExitPrice.IsPositive() {
+				continue // Exit fill unknown: no P/L
+			}
+			bp := get(t.Book)
+			bp.Realized = bp.Realized.Add(t.PL)
+			bp.Trades++
+			if t.PL.IsPositive() {
+				bp.Wins++
+			}
+		}
+	}
+	return perf
+}
+
+func (w *Watcher) getBooks() string {
+	w.mu.RLock()
+	books := append([]models.Book(nil), w.state.Books...)
+	w.mu.RUnlock()
+	if len(books) == 0 {
+		return "📚 No books defined. Example: /book add swing 500 max=3 sl=4 tp=10"
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].Name < books[j].Name })
+
+	perf := w.bookPerformances()
+	var sb strings.Builder
+	sb.WriteString("📚 *BOOKS*\n")
+	for _, b := range books {
+		bp := perf[b.Name]
+		if bp == nil {
+			bp = &bookPerformance{}
+		}
+		sb.WriteString(fmt.Sprintf("\n*%s* (%s)\n", b.Name, formatBookLimits(b)))
+		sb.WriteString(formatBookPerformance(bp, b.Budget))
+	}
+	if bp := perf[""]; bp != nil {
+		sb.WriteString(fmt.Sprintf("\n*%s*\n", unassignedBook))
+		sb.WriteString(formatBookPerformance(bp, decimal.Zero))
+	}
+	return sb.String()
+}
+
+func (w *Watcher) getBook(name string) string {
+	w.mu.RLock()
+	b, ok := w.bookLocked(name)
+	w.mu.RUnlock()
+	if !ok {
+		return fmt.Sprintf("⚠️ Book '%s' not found. Usage: /book [<name> | add | remove | assign]", name)
+	}
+	bp := w.bookPerformances()[b.Name]
+	if bp == nil {
+		bp = &bookPerformance{}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📚 *BOOK %s*\n%s\n", strings.ToUpper(b.Name), formatBookLimits(b)))
+	sb.WriteString(formatBookPerformance(bp, b.Budget))
+	for _, p := range bp.Positions {
+		sb.WriteString(fmt.Sprintf("• %s x%s @ $%s (SL $%s | TP $%s)\n",
+			p.Ticker, p.Quantity.String(), p.EntryPrice.StringFixed(2), p.StopLoss.StringFixed(2), p.TakeProfit.StringFixed(2)))
+	}
+	return sb.String()
+}
+
+// formatBookPerformance renders budget use and P/L. A zero limit (unassigned) omits the budget.
+func formatBookPerformance(bp *bookPerformance, limit decimal.Decimal) string {
+	var sb strings.Builder
+	if limit.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Budget: $%s / $%s (Available: $%s)\n",
+			money.Cash(bp.Exposure).StringFixed(2), limit.StringFixed(2), decimal.Max(decimal.Zero, limit.Sub(bp.Exposure)).StringFixed(2)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Invested: $%s\n", money.Cash(bp.Exposure).StringFixed(2)))
+	}
+	winRate := "n/a"
+	if bp.Trades > 0 {
+		winRate = fmt.Sprintf("%d%%", bp.Wins*100/bp.Trades)
+	}
+	sb.WriteString(fmt.Sprintf("Positions: %d | Unrealized: %s | Realized: %s (%d trades, win rate %s)\n",
+		len(bp.Positions), plString(bp.Unrealized), plString(bp.Realized), bp.Trades, winRate))
+	return sb.String()
+}
+
+// splitBookArg removes a "book=<name>" token from a command, returning the rest and the name.
+func splitBookArg(parts []string) ([]string, string) {
+	var rest []string
+	book := ""
+	for i, p := range parts {
+		if i > 0 && strings.HasPrefix(strings.ToLower(p), "book=") {
+			book = strings.ToLower(p[len("book="):])
+			continue
+		}
+		rest = append(rest, p)
+	}
+	return rest, book
+}
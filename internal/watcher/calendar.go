@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"time"
+
+	"alpha_trading/internal/symbols"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+const (
+	eodCalendarLookbackDays = 7                // Covers long weekends plus consecutive holidays
+	sessionEventGrace       = 30 * time.Second // Lets the broker clock flip before the poll after an open/close
+	regularClose            = "16:00"          // ET; half days close earlier
+)
+
+// easternLoc is the exchange timezone used by the trading calendar.
+// Falls back to a fixed EST offset if tzdata is unavailable.
+func easternLoc() *time.Location {
+	if loc, err := time.LoadLocation("America/New_York"); err == nil {
+		return loc
+	}
+	return time.FixedZone("EST", -5*3600)
+}
+
+// sessionClose returns the close time of a calendar day (ET, "HH:MM").
+func sessionClose(day alpaca.CalendarDay) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04", day.Date+" "+day.Close, easternLoc())
+}
+
+// sessionOpen returns the open time of a calendar day (ET, "HH:MM").
+func sessionOpen(day alpaca.CalendarDay) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04", day.Date+" "+day.Open, easternLoc())
+}
+
+// lastClosedSession returns the most recent session in days whose close is at or
+// before now, with its date and close time. Holidays are simply absent from the
+// calendar and half days carry their early close, so no date heuristics are needed.
+func lastClosedSession(days []alpaca.CalendarDay, now time.Time) (string, time.Time, bool) {
+	var (
+		session string
+		closeAt time.Time
+	)
+	for _, d := range days {
+		c, err := sessionClose(d)
+		if err != nil || c.After(now) {
+			continue
+		}
+		if c.After(closeAt) {
+			session, closeAt = d.Date, c
+		}
+	}
+	return session, closeAt, session != ""
+}
+
+// nextSessionEvent returns the first session open or close in days that is after now.
+func nextSessionEvent(days []alpaca.CalendarDay, now time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, d := range days {
+		open, err1 := sessionOpen(d)
+		closeAt, err2 := sessionClose(d)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for _, t := range []time.Time{open, closeAt} {
+			if t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// earlyClose reports whether t, a session close, is before the regular 16:00 ET close.
+func earlyClose(t time.Time) bool {
+	return t.In(easternLoc()).Format("15:04") < regularClose
+}
+
+// NextPollDelay is how long the main loop waits before the next poll: interval, cut
+// short so that a poll lands right after the next session open or close on the trading
+// calendar. Half days then get their EOD report and end-of-session checks at the early
+// close rather than up to an interval later; holidays have no events and change nothing.
+func (w *Watcher) NextPollDelay(interval time.Duration) time.Duration {
+	now := w.clock.Now()
+	days, err := w.provider.GetCalendar(w.ctx, now, now.Add(interval))
+	if err != nil {
+		return interval
+	}
+	if at, ok := nextSessionEvent(days, now); ok {
+		if d := at.Add(sessionEventGrace).Sub(now); d < interval {
+			return d
+		}
+	}
+	return interval
+}
+
+// lastClosedCryptoDay returns yesterday's date (ET) and the midnight that closed it.
+// Crypto trades around the clock, so its "session" is simply the calendar day.
+func lastClosedCryptoDay(now time.Time) (string, time.Time) {
+	et := now.In(easternLoc())
+	midnight := time.Date(et.Year(), et.Month(), et.Day(), 0, 0, 0, 0, easternLoc())
+	return midnight.AddDate(0, 0, -1).Format("2006-01-02"), midnight
+}
+
+// holdsCrypto reports whether any active position trades 24/7.
+func (w *Watcher) holdsCrypto() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && symbols.IsCrypto(p.Ticker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+// Calendars as the broker returns them: holidays are absent, half days close early.
+var (
+	// Thanksgiving 2025: Thursday closed, Friday closes at 13:00
+	thanksgivingWeek = []alpaca.CalendarDay{
+		{Date: "2025-11-24", Open: "09:30", Close: "16:00"},
+		{Date: "2025-11-25", Open: "09:30", Close: "16:00"},
+		{Date: "2025-11-26", Open: "09:30", Close: "16:00"},
+		{Date: "2025-11-28", Open: "09:30", Close: "13:00"},
+	}
+	// Labor Day 2025: a Monday holiday after the weekend
+	laborDayWeekend = []alpaca.CalendarDay{
+		{Date: "2025-08-28", Open: "09:30", Close: "16:00"},
+		{Date: "2025-08-29", Open: "09:30", Close: "16:00"},
+		{Date: "2025-09-02", Open: "09:30", Close: "16:00"},
+	}
+	// Christmas 2025: Christmas Eve closes at 13:00, Christmas Day closed
+	christmasWeek = []alpaca.CalendarDay{
+		{Date: "2025-12-22", Open: "09:30", Close: "16:00"},
+		{Date: "2025-12-23", Open: "09:30", Close: "16:00"},
+		{Date: "2025-12-24", Open: "09:30", Close: "13:00"},
+		{Date: "2025-12-26", Open: "09:30", Close: "16:00"},
+	}
+)
+
+// et parses "2006-01-02 15:04:05" in exchange time.
+func et(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.ParseInLocation("2006-01-02 15:04:05", s, easternLoc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLastClosedSession(t *testing.T) {
+	cases := []struct {
+		name        string
+		days        []alpaca.CalendarDay
+		now         string
+		wantSession string
+		wantClose   string // ET "15:04"
+	}{
+		{"Wednesday before Thanksgiving, after close", thanksgivingWeek, "2025-11-26 16:00:30", "2025-11-26", "16:00"},
+		{"Thanksgiving Day", thanksgivingWeek, "2025-11-27 12:00:00", "2025-11-26", "16:00"},
+		{"Black Friday before the half-day close", thanksgivingWeek, "2025-11-28 12:59:59", "2025-11-26", "16:00"},
+		{"Black Friday at the half-day close", thanksgivingWeek, "2025-11-28 13:00:00", "2025-11-28", "13:00"},
+		{"Black Friday evening", thanksgivingWeek, "2025-11-28 16:30:00", "2025-11-28", "13:00"},
+		{"Saturday after Thanksgiving", thanksgivingWeek, "2025-11-29 10:00:00", "2025-11-28", "13:00"},
+		{"Labor Day", laborDayWeekend, "2025-09-01 18:00:00", "2025-08-29", "16:00"},
+		{"Tuesday after Labor Day, before close", laborDayWeekend, "2025-09-02 11:00:00", "2025-08-29", "16:00"},
+		{"Tuesday after Labor Day, after close", laborDayWeekend, "2025-09-02 16:01:00", "2025-09-02", "16:00"},
+		{"Christmas Eve before the early close", christmasWeek, "2025-12-24 12:30:00", "2025-12-23", "16:00"},
+		{"Christmas Eve after the early close", christmasWeek, "2025-12-24 13:05:00", "2025-12-24", "13:00"},
+		{"Christmas Day", christmasWeek, "2025-12-25 09:00:00", "2025-12-24", "13:00"},
+		{"before the first session", christmasWeek, "2025-12-22 10:00:00", "", ""},
+		{"empty calendar", nil, "2025-12-22 10:00:00", "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			session, closeAt, ok := lastClosedSession(tc.days, et(t, tc.now))
+			if ok != (tc.wantSession != "") || session != tc.wantSession {
+				t.Fatalf("session = %q (ok %v), want %q", session, ok, tc.wantSession)
+			}
+			if ok && closeAt.In(easternLoc()).Format("15:04") != tc.wantClose {
+				t.Fatalf("close = %s, want %s", closeAt.In(easternLoc()).Format("15:04"), tc.wantClose)
+			}
+		})
+	}
+}
+
+func TestNextSessionEvent(t *testing.T) {
+	cases := []struct {
+		name string
+		days []alpaca.CalendarDay
+		now  string
+		want string // ET "2006-01-02 15:04"; "" = none
+	}{
+		{"Wednesday evening skips Thanksgiving", thanksgivingWeek, "2025-11-26 17:00:00", "2025-11-28 09:30"},
+		{"Black Friday morning lands on the early close", thanksgivingWeek, "2025-11-28 10:00:00", "2025-11-28 13:00"},
+		{"after the last close", thanksgivingWeek, "2025-11-28 13:00:00", ""},
+		{"Friday evening skips the Monday holiday", laborDayWeekend, "2025-08-29 16:30:00", "2025-09-02 09:30"},
+		{"on the Monday holiday", laborDayWeekend, "2025-09-01 12:00:00", "2025-09-02 09:30"},
+		{"Christmas Eve morning", christmasWeek, "2025-12-24 11:00:00", "2025-12-24 13:00"},
+		{"Christmas Eve at the early close", christmasWeek, "2025-12-24 13:00:00", "2025-12-26 09:30"},
+		{"before an open", christmasWeek, "2025-12-23 08:00:00", "2025-12-23 09:30"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := nextSessionEvent(tc.days, et(t, tc.now))
+			got := ""
+			if ok {
+				got = next.In(easternLoc()).Format("2006-01-02 15:04")
+			}
+			if got != tc.want {
+				t.Fatalf("next event = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEarlyClose(t *testing.T) {
+	cases := []struct {
+		name string
+		day  alpaca.CalendarDay
+		want bool
+	}{
+		{"Black Friday", thanksgivingWeek[3], true},
+		{"Christmas Eve", christmasWeek[2], true},
+		{"regular day", christmasWeek[1], false},
+		{"day after a Monday holiday", laborDayWeekend[2], false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			closeAt, err := sessionClose(tc.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := earlyClose(closeAt); got != tc.want {
+				t.Fatalf("earlyClose(%s) = %v, want %v", closeAt, got, tc.want)
+			}
+			// The broker clock reports closes in UTC or with an offset; only the instant counts
+			if got := earlyClose(closeAt.UTC()); got != tc.want {
+				t.Fatalf("earlyClose(%s UTC) = %v, want %v", closeAt.UTC(), got, tc.want)
+			}
+		})
+	}
+}
+
+// An EOD run after a restart on a holiday finds the session before it. It reports it
+// only when LastEODSession is older (the report was missed while down), never twice.
+func TestEODAfterHolidayRestart(t *testing.T) {
+	cases := []struct {
+		name     string
+		days     []alpaca.CalendarDay
+		now      string
+		lastSent string
+		wantSend string // Session reported; "" = none
+	}{
+		{"Thanksgiving, Wednesday already reported", thanksgivingWeek, "2025-11-27 10:00:00", "2025-11-26", ""},
+		{"Thanksgiving, Wednesday missed", thanksgivingWeek, "2025-11-27 10:00:00", "2025-11-25", "2025-11-26"},
+		{"Labor Day, Friday already reported", laborDayWeekend, "2025-09-01 09:00:00", "2025-08-29", ""},
+		{"Labor Day, Friday missed", laborDayWeekend, "2025-09-01 09:00:00", "2025-08-28", "2025-08-29"},
+		{"Christmas Day, half day missed", christmasWeek, "2025-12-25 08:00:00", "2025-12-23", "2025-12-24"},
+		{"Christmas Day, first run", christmasWeek, "2025-12-25 08:00:00", "", "2025-12-24"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := et(t, tc.now)
+			// Same window as checkEOD
+			var days []alpaca.CalendarDay
+			from := now.AddDate(0, 0, -eodCalendarLookbackDays).Format("2006-01-02")
+			for _, d := range tc.days {
+				if d.Date >= from && d.Date <= now.Format("2006-01-02") {
+					days = append(days, d)
+				}
+			}
+			session, _, ok := lastClosedSession(days, now)
+			got := ""
+			if ok && session > tc.lastSent {
+				got = session
+			}
+			if got != tc.wantSend {
+				t.Fatalf("reported %q, want %q", got, tc.wantSend)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package watcher
 
 import (
+	"alpha_trading/internal/market"
 	"alpha_trading/internal/models"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
 	"fmt"
 	"log"
 	"strings"
@@ -12,6 +15,13 @@ import (
 
 // HandleCallback processes button clicks from Telegram.
 func (w *Watcher) HandleCallback(callbackID, data string) string {
+	// Buttons sent by a secondary instance carry its prefix (crypto:EXECUTE_BUY_...)
+	if name, rest, ok := telegram.SplitCallback(data); ok {
+		if peer := w.shardPeer(name); peer != nil {
+			return w.relay(peer, "/callback", shardRelay{CallbackID: callbackID, Data: rest})
+		}
+	}
+
 	parts := strings.Split(data, "_")
 	if len(parts) < 3 {
 		return "⚠️ Invalid callback data."
@@ -22,6 +32,56 @@ func (w *Watcher) HandleCallback(callbackID, data string) string {
 		return w.handleBuyCallback(data)
 	}
 
+	// Special Case for the /setup wizard
+	if strings.HasPrefix(data, "SETUP_") {
+		return w.handleSetupCallback(data)
+	}
+
+	// Special Case for Watchlist flow (/scan buttons)
+	if strings.HasPrefix(data, "WATCH_") {
+		return w.handleWatchCallback(data)
+	}
+
+	// Special Case for the weekly strategy review
+	if strings.HasPrefix(data, "STRATEGY_") {
+		return w.handleStrategyCallback(data)
+	}
+
+	// Special Case for the /env live switch
+	if strings.HasPrefix(data, "ENV_") {
+		return w.handleEnvCallback(data)
+	}
+
+	// Special Case for the weekly stop review (APPLY ALL)
+	if strings.HasPrefix(data, "STOPS_") {
+		return w.handleStopsCallback(data)
+	}
+
+	// Special Case for the pre-trade checklist (TRADE_CHECKLIST)
+	if strings.HasPrefix(data, "CHECK_") {
+		return w.handleChecklistCallback(data)
+	}
+
+	// Special Case for the PDT guard (SELL ANYWAY)
+	if strings.HasPrefix(data, "PDT_") {
+		return w.handlePDTCallback(data)
+	}
+
+	// Special Case for the kill switch /resume confirmation
+	if strings.HasPrefix(data, "RESUME_") {
+		return w.handleResumeCallback(data)
+	}
+
+	// Special Case for CSV position imports
+	if strings.HasPrefix(data, "IMPORT_") {
+		return w.handleImportCallback(data)
+	}
+
+	// Special Case for config bundle imports
+	if strings.HasPrefix(data, "CFGIMPORT_") {
+		return w.handleConfigImportCallback(data)
+	}
+
 	// Special Case for AI flow (Spec 64)
 	if strings.HasPrefix(data, "AI_") {
 		return w.handleAICallback(data)
@@ -40,8 +100,36 @@ func (w *Watcher) HandleCallback(callbackID, data string) string {
 
 	// Always cleanup pending action at end (Point 6)
 	delete(w.pendingActions, ticker)
+	w.mu.Unlock()
 
-	// 1.5 Find Position (Used for TP Guardrail & Execution)
+	if action == "CANCEL" {
+		w.recordAudit(ticker, auditCancelled, "USER", pending.TriggerPrice, trigger+" alert dismissed")
+		return fmt.Sprintf("❌ Action for %s cancelled by user.", ticker)
+	}
+
+	if action == "CONFIRM" {
+		// 1. Temporal Gate
+		ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
+		if w.since(pending.Timestamp) > ttl {
+			return fmt.Sprintf("⏳ TIMEOUT: Confirmation for %s is too old (> %ds). Action aborted.", ticker, w.config.ConfirmationTTLSec)
+		}
+
+		pending.Trigger = trigger
+		return w.executeTriggerSell(pending, false)
+	}
+
+	return "Unknown action."
+}
+
+// executeTriggerSell runs a confirmed SL/TP/TS exit: guardrails, market sell,
+// verification and archive. auto marks an unattended exit (vacation policy), which
+// skips the Spec 18 deviation gate: a further move is no reason to hold a stop.
+func (w *Watcher) executeTriggerSell(pending PendingAction, auto bool) string {
+	ticker, trigger, triggerPrice := pending.Ticker, pending.Trigger, pending.TriggerPrice
+	confirmedAt := w.clock.Now()
+
+	w.mu.Lock()
+	// Find Position (Used for TP Guardrail & Execution)
 	// Make a copy for validation outside lock
 	var position models.Position
 	activeFound := false
@@ -55,119 +143,114 @@ func (w *Watcher) HandleCallback(callbackID, data string) string {
 	}
 	w.mu.Unlock()
 
-	if action == "CANCEL" {
-		return fmt.Sprintf("❌ Action for %s cancelled by user.", ticker)
+	if !activeFound {
+		msg := fmt.Sprintf("❌ Execution Failed: Could not find active position for %s.", ticker)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
 	}
 
-	if action == "CONFIRM" {
-		// 1. Temporal Gate
-		ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
-		if time.Since(pending.Timestamp) > ttl {
-			return fmt.Sprintf("⏳ TIMEOUT: Confirmation for %s is too old (> %ds). Action aborted.", ticker, w.config.ConfirmationTTLSec)
-		}
+	// 2. Refresh Price
+	currentPrice, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker)
+	if err != nil {
+		log.Printf("Error fetching price for checks: %v", err)
+		return fmt.Sprintf("⚠️ Error fetching current price for %s. Aborted.", ticker)
+	}
 
-		if !activeFound {
-			msg := fmt.Sprintf("❌ Execution Failed: Could not find active position for %s.", ticker)
-			log.Printf("[FATAL_TRADE_ERROR] %s", msg)
-			return msg
-		}
+	// 3. TP Price Protection Guardrail (Spec 36)
+	if trigger == "TP" {
+		// Gate: FreshPrice < (Position.TP * 0.995)
+		// Guardrail: 0.5% slippage below Target
+		thresholdRatio := decimal.NewFromFloat(0.995)
+		thresholdPrice := position.TakeProfit.Mul(thresholdRatio)
 
-		// 2. Refresh Price
-		currentPrice, err := w.provider.GetPrice(ticker)
-		if err != nil {
-			log.Printf("Error fetching price for checks: %v", err)
-			return fmt.Sprintf("⚠️ Error fetching current price for %s. Aborted.", ticker)
+		if currentPrice.LessThan(thresholdPrice) {
+			return fmt.Sprintf("⚠️ TP GUARDRAIL: Price $%s has slipped below 99.5%% of TP ($%s). Manual review required.", currentPrice.StringFixed(2), position.TakeProfit.StringFixed(2))
 		}
+	}
 
-		// 3. TP Price Protection Guardrail (Spec 36)
-		if trigger == "TP" {
-			// Gate: FreshPrice < (Position.TP * 0.995)
-			// Guardrail: 0.5% slippage below Target
-			thresholdRatio := decimal.NewFromFloat(0.995)
-			thresholdPrice := position.TakeProfit.Mul(thresholdRatio)
-
-			if currentPrice.LessThan(thresholdPrice) {
-				return fmt.Sprintf("⚠️ TP GUARDRAIL: Price $%s has slipped below 99.5%% of TP ($%s). Manual review required.", currentPrice.StringFixed(2), position.TakeProfit.StringFixed(2))
-			}
-		}
+	// 4. Standard Deviation Gate (Spec 18)
+	// deviation = abs(current - trigger_from_pending) / trigger_from_pending
+	deviation := currentPrice.Sub(triggerPrice).Div(triggerPrice)
+	if deviation.IsNegative() {
+		deviation = deviation.Neg() // Abs
+	}
 
-		// 4. Standard Deviation Gate (Spec 18)
-		// deviation = abs(current - trigger_from_pending) / trigger_from_pending
-		deviation := currentPrice.Sub(pending.TriggerPrice).Div(pending.TriggerPrice)
-		if deviation.IsNegative() {
-			deviation = deviation.Neg() // Abs
-		}
+	maxDev := decimal.NewFromFloat(w.config.ConfirmationMaxDeviationPct)
+	if !auto && deviation.GreaterThan(maxDev) {
+		displayDev := deviation.Mul(decimal.NewFromInt(100)).StringFixed(2)
+		displayMax := maxDev.Mul(decimal.NewFromInt(100)).StringFixed(2)
+		return fmt.Sprintf("⚠️ PRICE DEVIATION: Price changed by %s%% (Max %s%%). Action aborted for safety.", displayDev, displayMax)
+	}
 
-		maxDev := decimal.NewFromFloat(w.config.ConfirmationMaxDeviationPct)
-		if deviation.GreaterThan(maxDev) {
-			displayDev := deviation.Mul(decimal.NewFromInt(100)).StringFixed(2)
-			displayMax := maxDev.Mul(decimal.NewFromInt(100)).StringFixed(2)
-			return fmt.Sprintf("⚠️ PRICE DEVIATION: Price changed by %s%% (Max %s%%). Action aborted for safety.", displayDev, displayMax)
-		}
+	// 5. Execution (Sell)
+	qty := position.Quantity
+	if qty.IsZero() {
+		msg := fmt.Sprintf("❌ Execution Failed: Quantity is zero for %s.", ticker)
+		return msg
+	}
 
-		// 5. Execution (Sell)
-		qty := position.Quantity
-		if qty.IsZero() {
-			msg := fmt.Sprintf("❌ Execution Failed: Quantity is zero for %s.", ticker)
-			return msg
-		}
+	// Spec 54: Sequential Order Clearance
+	if err := w.ensureSequentialClearance(ticker); err != nil {
+		log.Printf("Warning: Sequential clearance failed for %s: %v", ticker, err)
+		// Proceed but warn? Or abort? Spec says "ONLY then is the bot permitted".
+		// But if it times out, we might be stuck. Let's abort to be safe strict compliance.
+		return fmt.Sprintf("❌ Execution Aborted: Could not clear pending orders for %s (Timeout).", ticker)
+	}
 
-		// Spec 54: Sequential Order Clearance
-		if err := w.ensureSequentialClearance(ticker); err != nil {
-			log.Printf("Warning: Sequential clearance failed for %s: %v", ticker, err)
-			// Proceed but warn? Or abort? Spec says "ONLY then is the bot permitted".
-			// But if it times out, we might be stuck. Let's abort to be safe strict compliance.
-			return fmt.Sprintf("❌ Execution Aborted: Could not clear pending orders for %s (Timeout).", ticker)
-		}
+	// Outside the regular session an equity exit goes in as an extended-hours limit order
+	mid := w.quoteMid(ticker)
+	order, err := w.placeOrder(models.OrderIntent{Ticker: ticker, Qty: qty, Side: "sell", Source: trigger}, w.sellOrderOptions(ticker, currentPrice)...)
+	if err != nil {
+		msg := fmt.Sprintf("❌ Execution Failed for %s: %v", ticker, err)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
+	}
 
-		order, err := w.provider.PlaceOrder(ticker, qty, "sell")
-		if err != nil {
-			msg := fmt.Sprintf("❌ Execution Failed for %s: %v", ticker, err)
-			log.Printf("[FATAL_TRADE_ERROR] %s", msg)
-			return msg
-		}
+	// Spec 53: Execution Verification
+	verifiedOrder, err := w.verifyOrderExecution(order.ID)
+	if err != nil {
+		// Spec 53 says: Send [CRITICAL] alert.
+		// Re-sync is already triggered inside verifyOrderExecution if status was fail.
+		msg := fmt.Sprintf("🚨 Critical: Order Verification Failed: %v", err)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
+	}
 
-		// Spec 53: Execution Verification
-		verifiedOrder, err := w.verifyOrderExecution(order.ID)
-		if err != nil {
-			// Spec 53 says: Send [CRITICAL] alert.
-			// Re-sync is already triggered inside verifyOrderExecution if status was fail.
-			msg := fmt.Sprintf("🚨 Critical: Order Verification Failed: %v", err)
-			log.Printf("[FATAL_TRADE_ERROR] %s", msg)
-			return msg
-		}
+	status := strings.ToLower(verifiedOrder.Status)
+	// Double check status just in case
+	if status == "canceled" || status == "rejected" || status == "expired" {
+		return fmt.Sprintf("❌ Execution Failed: Order Status %s.", status)
+	}
 
-		status := strings.ToLower(verifiedOrder.Status)
-		// Double check status just in case
-		if status == "canceled" || status == "rejected" || status == "expired" {
-			return fmt.Sprintf("❌ Execution Failed: Order Status %s.", status)
+	// 5. Update State (Only if we are confident)
+	if status == "filled" {
+		w.mu.Lock()
+		// Find position again by Ticker (index might have shifted if other things happened)
+		foundIndex := -1
+		for i, p := range w.state.Positions {
+			if p.Ticker == ticker && p.Status == "ACTIVE" {
+				foundIndex = i
+				break
+			}
 		}
 
-		// 5. Update State (Only if we are confident)
-		if status == "filled" {
-			w.mu.Lock()
-			// Find position again by Ticker (index might have shifted if other things happened)
-			foundIndex := -1
-			for i, p := range w.state.Positions {
-				if p.Ticker == ticker && p.Status == "ACTIVE" {
-					foundIndex = i
-					break
-				}
-			}
+		w.mu.Unlock()
 
-			if foundIndex != -1 {
-				w.state.Positions[foundIndex].Status = "EXECUTED"
-				w.saveStateLocked()
+		// Move the closed position to the trade archive (/history) instead of leaving an EXECUTED record
+		if foundIndex != -1 {
+			exitPrice := decimal.Zero
+			if verifiedOrder.FilledAvgPrice != nil {
+				exitPrice = *verifiedOrder.FilledAvgPrice
 			}
-			w.mu.Unlock()
-
-			return fmt.Sprintf("✅ ORDER PLACED: Sold %s at Market (Filled).", ticker)
+			w.purgePosition(ticker, exitPrice, trigger)
 		}
+		w.recordLatency(pending, confirmedAt, verifiedOrder.FilledAt, auto)
+		w.recordSlippage(ticker, "sell", trigger, triggerPrice, mid, verifiedOrder)
 
-		return fmt.Sprintf("⚠️ Order Placed but not yet Filled (Status: %s). Position remains ACTIVE.", status)
+		return fmt.Sprintf("✅ ORDER PLACED: Sold %s at %s (Filled).", ticker, orderKind(order))
 	}
 
-	return "Unknown action."
+	return fmt.Sprintf("⚠️ Order Placed but not yet Filled (Status: %s). Position remains ACTIVE.", status)
 }
 
 func (w *Watcher) handleBuyCallback(data string) string {
@@ -190,75 +273,154 @@ func (w *Watcher) handleBuyCallback(data string) string {
 
 	// 1. Temporal Gate (Spec 39)
 	ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
-	if time.Since(proposal.Timestamp) > ttl {
+	if w.since(proposal.Timestamp) > ttl {
+		w.recordAudit(ticker, auditCancelled, "SYSTEM", proposal.Price, "proposal expired")
 		return fmt.Sprintf("⏳ TIMEOUT: Proposal for %s expired (> %ds). Action aborted.", ticker, w.config.ConfirmationTTLSec)
 	}
 
 	if action == "CANCEL" {
+		w.recordAudit(ticker, auditCancelled, "USER", proposal.Price, "purchase cancelled")
 		return fmt.Sprintf("❌ Purchase of %s cancelled.", ticker)
 	}
 
 	if action == "EXECUTE" {
-		// Spec 54: Sequential Order Clearance (Safeguard)
-		if err := w.ensureSequentialClearance(ticker); err != nil {
-			return fmt.Sprintf("❌ Buy Aborted: Could not clear pending orders for %s.", ticker)
+		// Large orders also need the PIN (/pin) before anything is sent to the broker
+		if proposal.Confirm == confirmPIN {
+			return w.armProposal(proposal)
 		}
+		return w.executeProposal(proposal)
+	}
 
-		// 1. Execute Buy
-		order, err := w.provider.PlaceOrder(ticker, proposal.Qty, "buy")
-		if err != nil {
-			msg := fmt.Sprintf("❌ Buy Execution Failed: %v", err)
-			log.Printf("[FATAL_TRADE_ERROR] %s", msg)
-			return msg
-		}
+	return "Unknown buy action."
+}
+
+// executeProposal places a confirmed buy proposal: compliance re-check, order, verification
+// and the new position (or fill tracking when the order rests).
+func (w *Watcher) executeProposal(proposal PendingProposal) string {
+	if proposal.Sweep {
+		return w.executeSweep(proposal)
+	}
+	if proposal.Rotation != nil {
+		return w.runRotation(*proposal.Rotation)
+	}
+	ticker := proposal.Ticker
+	// Compliance is re-checked at execution (e.g., the open blackout may have started)
+	if msg, ok := w.checkCompliance(ticker, proposal.Qty, proposal.Price); !ok {
+		return msg
+	}
+
+	// Spec 54: Sequential Order Clearance (Safeguard)
+	if err := w.ensureSequentialClearance(ticker); err != nil {
+		return fmt.Sprintf("❌ Buy Aborted: Could not clear pending orders for %s.", ticker)
+	}
 
-		// Spec 53: Execution Verification
-		verifiedOrder, err := w.verifyOrderExecution(order.ID)
+	// 0.5 Short of buying power: sell from the cash sweep (SWEEP_ETF) first
+	if notes := w.redeemSweepFor(proposal.TotalCost); len(notes) > 0 {
+		telegram.Notify(strings.Join(notes, "\n"))
+	}
+
+	// 1. Execute Buy (extended-hours proposals without a limit are priced off a fresh ask)
+	opts := market.OrderOptions{LimitPrice: proposal.LimitPrice, TimeInForce: proposal.TimeInForce, ExtendedHours: proposal.ExtendedHours, Notional: proposal.Notional}
+	if proposal.ExtendedHours && !proposal.LimitPrice.IsPositive() {
+		o, err := w.extendedLimitOrder(ticker, "buy", proposal.Price)
 		if err != nil {
-			msg := fmt.Sprintf("🚨 Critical: Buy Verification Failed: %v", err)
-			log.Printf("[FATAL_TRADE_ERROR] %s", msg)
-			return msg
+			return fmt.Sprintf("❌ Buy Aborted: %v", err)
 		}
+		opts.LimitPrice = o.LimitPrice
+	}
+	// Broker protection: the stop rides along as an OTO leg until the OCO pair replaces it on fill
+	// (not on notional buys: Alpaca only takes those as simple orders)
+	if w.config.BrokerProtectionEnabled && !proposal.ExtendedHours && proposal.Notional.IsZero() &&
+		brokerExitEligible(models.Position{Ticker: ticker, Quantity: proposal.Qty, StopLoss: proposal.StopLoss, TakeProfit: proposal.TakeProfit}, brokerExitOCO) == "" {
+		opts.OrderClass, opts.StopLoss = "oto", proposal.StopLoss
+	}
+	mid := w.quoteMid(ticker)
+	thesisID := fmt.Sprintf("MANUAL_%d", w.clock.Now().Unix())
+	order, err := w.placeOrder(models.OrderIntent{
+		Ticker: ticker, Qty: proposal.Qty, Side: "buy", Source: "MANUAL",
+		StopLoss: proposal.StopLoss, TakeProfit: proposal.TakeProfit, TrailingStopPct: proposal.TrailingStopPct,
+		ThesisID: thesisID, Book: proposal.Book,
+	}, opts)
+	if err != nil {
+		msg := fmt.Sprintf("❌ Buy Execution Failed: %v", err)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
+	}
 
-		status := strings.ToLower(verifiedOrder.Status)
-		if status == "canceled" || status == "rejected" {
-			return fmt.Sprintf("❌ Buy Failed: Order Status '%s'.", status)
-		}
+	// Spec 53: Execution Verification
+	verifiedOrder, err := w.verifyOrderExecution(order.ID)
+	if err != nil {
+		msg := fmt.Sprintf("🚨 Critical: Buy Verification Failed: %v", err)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
+	}
 
-		if status == "filled" {
-			// 3. Add to State
-			newPos := models.Position{
-				Ticker:          ticker,
-				Quantity:        proposal.Qty,
-				EntryPrice:      proposal.Price, // Approx, ideally use verifiedOrder.FilledAvgPrice if available
-				StopLoss:        proposal.StopLoss,
-				TakeProfit:      proposal.TakeProfit,
-				Status:          "ACTIVE",
-				HighWaterMark:   proposal.Price,
-				TrailingStopPct: proposal.TrailingStopPct,
-				ThesisID:        fmt.Sprintf("MANUAL_%d", time.Now().Unix()),
-				OpenedAt:        time.Now(),
-			}
+	status := strings.ToLower(verifiedOrder.Status)
+	if status == "canceled" || status == "rejected" {
+		return fmt.Sprintf("❌ Buy Failed: Order Status '%s'.", status)
+	}
 
-			// Refine EntryPrice if available
-			if verifiedOrder.FilledAvgPrice != nil {
-				newPos.EntryPrice = *verifiedOrder.FilledAvgPrice
-				newPos.HighWaterMark = *verifiedOrder.FilledAvgPrice
-			}
+	if status == "filled" {
+		// Notional buys only learn their quantity at the fill
+		qty := proposal.Qty
+		if verifiedOrder.FilledQty.IsPositive() {
+			qty = verifiedOrder.FilledQty
+		}
 
-			w.mu.Lock()
-			w.state.Positions = append(w.state.Positions, newPos)
-			w.saveStateLocked()
-			w.mu.Unlock()
+		// 3. Add to State
+		newPos := models.Position{
+			Ticker:          ticker,
+			Quantity:        qty,
+			EntryPrice:      proposal.Price, // Approx, ideally use verifiedOrder.FilledAvgPrice if available
+			StopLoss:        proposal.StopLoss,
+			TakeProfit:      proposal.TakeProfit,
+			Status:          "ACTIVE",
+			HighWaterMark:   proposal.Price,
+			TrailingStopPct: proposal.TrailingStopPct,
+			ThesisID:        thesisID,
+			OpenedAt:        w.clock.Now(),
+			Book:            proposal.Book,
+		}
 
-			return fmt.Sprintf("✅ PURCHASED: %s %s @ Market (Filled).\nStatus: %s\nSL: $%s | TP: $%s\nTracking Active.",
-				proposal.Qty.StringFixed(2), ticker, status, proposal.StopLoss.StringFixed(2), proposal.TakeProfit.StringFixed(2))
+		// Refine EntryPrice if available
+		if verifiedOrder.FilledAvgPrice != nil {
+			newPos.EntryPrice = *verifiedOrder.FilledAvgPrice
+			newPos.HighWaterMark = *verifiedOrder.FilledAvgPrice
 		}
 
-		return fmt.Sprintf("⚠️ Buy Order Placed but not yet Filled (Status: %s). Position NOT yet tracked. Check /refresh later.", status)
+		w.mu.Lock()
+		w.state.Positions = append(w.state.Positions, newPos)
+		w.saveStateLocked()
+		w.mu.Unlock()
+		w.recordAudit(ticker, auditFilled, "USER", newPos.EntryPrice, fmt.Sprintf("bought %s", qty.String()))
+		w.recordSlippage(ticker, "buy", "MANUAL", proposal.Price, mid, verifiedOrder)
+		w.protectAtBroker(ticker)
+
+		return fmt.Sprintf("✅ PURCHASED: %s %s @ %s (Filled).\nStatus: %s\nSL: $%s | TP: $%s\nTracking Active.",
+			qty.String(), ticker, orderKind(order), status, proposal.StopLoss.StringFixed(2), proposal.TakeProfit.StringFixed(2))
 	}
 
-	return "Unknown buy action."
+	// Resting (limit, extended hours, or just slow): the poll loop opens the position on fill
+	w.trackPendingOrder(verifiedOrder, proposal, thesisID)
+	return fmt.Sprintf("⏳ Buy Order Placed (%s, Status: %s). Not filled yet: you will be notified when it fills, and the position opens with SL $%s | TP $%s.",
+		orderKind(verifiedOrder), status, proposal.StopLoss.StringFixed(2), proposal.TakeProfit.StringFixed(2))
+}
+
+// armAIBuy holds a PIN-tier AI buy. The AI EXECUTE tap counts as the proposal's EXECUTE,
+// so the proposal (default levels around price) is armed right away and waits for /pin.
+func (w *Watcher) armAIBuy(ticker string, qty, price decimal.Decimal) string {
+	sl, tp := w.defaultLevels(ticker, price)
+	return w.armProposal(PendingProposal{
+		Ticker:          ticker,
+		Qty:             qty,
+		Price:           price,
+		TotalCost:       price.Mul(qty),
+		StopLoss:        sl,
+		TakeProfit:      tp,
+		TrailingStopPct: decimal.NewFromFloat(w.config.DefaultTrailingStopPct),
+		Confirm:         confirmPIN,
+		Timestamp:       w.clock.Now(),
+	})
 }
 
 // handleAICallback processes AI_EXEC_ and AI_DISMISS_ buttons.
@@ -290,6 +452,7 @@ func (w *Watcher) handleAICallback(data string) string {
 	w.mu.Unlock()
 
 	if !isExec {
+		w.recordAudit(pending.Ticker, auditCancelled, "USER", decimal.Zero, "AI proposal dismissed")
 		return fmt.Sprintf("❌ AI Proposal for %s dismissed.", pending.Ticker)
 	}
 
@@ -301,15 +464,28 @@ func (w *Watcher) handleAICallback(data string) string {
 	// Spec 67: Support multi-command rotation (split by semicolon)
 	commands := strings.Split(rawCmd, ";")
 	var resultsBuilder strings.Builder
+	soldInBatch := false // Later buys depend on proceeds that may not have settled yet
 
 	// Spec 81: Sequential Execution Threading
 	// We must execute sequentially and VERIFY each step.
-	for i, cmd := range commands {
-		cmd = strings.TrimSpace(cmd)
+	for i := 0; i < len(commands); i++ {
+		cmd := strings.TrimSpace(commands[i])
 		if cmd == "" {
 			continue
 		}
 
+		// A sell immediately followed by a buy is a rotation: the buy is funded
+		// from the verified sale proceeds, with rollback if the second leg fails.
+		if rot, ok := rotationAt(commands, i); ok {
+			if resultsBuilder.Len() > 0 {
+				resultsBuilder.WriteString("\n---\n")
+			}
+			resultsBuilder.WriteString(w.executeRotation(rot))
+			soldInBatch = true
+			i++ // Buy leg consumed
+			continue
+		}
+
 		parts := strings.Fields(cmd)
 		if len(parts) == 0 {
 			continue
@@ -322,16 +498,45 @@ func (w *Watcher) handleAICallback(data string) string {
 			if len(parts) < 3 {
 				output = fmt.Sprintf("❌ Invalid AI Buy: %s", cmd)
 			} else {
-				ticker := strings.ToUpper(parts[1])
+				ticker := symbols.Normalize(parts[1])
 				qtyStr := parts[2]
 				qty, _ := decimal.NewFromString(qtyStr) // risk.go already validated format
 
-				// 1. Sequential Clearance
-				if err := w.ensureSequentialClearance(ticker); err != nil {
+				// Settlement awareness: wait for / resize to settled funds after an earlier sell
+				var fundingNotes []string
+				if soldInBatch {
+					if price, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker); err == nil && price.IsPositive() {
+						qty, fundingNotes = w.fundDependentBuy(ticker, price, qty, decimal.Zero)
+					}
+				}
+
+				// 1. Symbol Gate + Sequential Clearance
+				if msg, ok := w.validateSymbol(ticker); !ok {
+					output = msg
+				} else if !qty.IsPositive() {
+					output = fmt.Sprintf("❌ Buy Skipped (%s): no settled funds available.", ticker)
+				} else if msg, ok := w.checkCompliance(ticker, qty, decimal.Zero); !ok {
+					output = msg
+				} else if decisionPrice, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker); err != nil || !decisionPrice.IsPositive() {
+					// Without a price neither the confirmation tier nor the default levels are known
+					log.Printf("[%s] AI buy aborted: no decision price (%v)", ticker, err)
+					output = fmt.Sprintf("❌ Buy Aborted (%s): price unavailable, the order size cannot be checked.", ticker)
+				} else if w.confirmTier(decisionPrice.Mul(qty)) == confirmPIN {
+					// The AI button stands in for EXECUTE; large orders still need the PIN
+					output = w.armAIBuy(ticker, qty, decisionPrice)
+				} else if err := w.ensureSequentialClearance(ticker); err != nil {
 					output = fmt.Sprintf("⚠️ Clearance failed: %v", err)
 				} else {
+					mid := w.quoteMid(ticker)
+					thesisID := fmt.Sprintf("AI_%d", w.clock.Now().Unix())
+					sl, tp := w.defaultLevels(ticker, decisionPrice)
+					tsPct := decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
+
 					// 2. Place Order
-					order, err := w.provider.PlaceOrder(ticker, qty, "buy")
+					order, err := w.placeOrder(models.OrderIntent{
+						Ticker: ticker, Qty: qty, Side: "buy", Source: "AI",
+						StopLoss: sl, TakeProfit: tp, TrailingStopPct: tsPct, ThesisID: thesisID,
+					})
 					if err != nil {
 						output = fmt.Sprintf("❌ Buy Failed (%s): %v", ticker, err)
 					} else {
@@ -342,34 +547,53 @@ func (w *Watcher) handleAICallback(data string) string {
 						} else {
 							// 4. Update State
 							if strings.EqualFold(verified.Status, "filled") {
+								// Default levels (Spec 41) around the fill; /update refines them
+								entry := *verified.FilledAvgPrice
+								sl, tp = w.defaultLevels(ticker, entry)
 								newPos := models.Position{
-									Ticker: ticker, Quantity: qty, EntryPrice: *verified.FilledAvgPrice,
-									Status: "ACTIVE", HighWaterMark: *verified.FilledAvgPrice,
-									OpenedAt: time.Now(), ThesisID: fmt.Sprintf("AI_%d", time.Now().Unix()),
-									// Defaults (Sync/Update will handle exacts)
-									StopLoss: decimal.Zero, TakeProfit: decimal.Zero, TrailingStopPct: decimal.Zero,
+									Ticker: ticker, Quantity: qty, EntryPrice: entry,
+									Status: "ACTIVE", HighWaterMark: entry,
+									OpenedAt: w.clock.Now(), ThesisID: thesisID,
+									StopLoss: sl, TakeProfit: tp, TrailingStopPct: tsPct,
 								}
 								w.mu.Lock()
 								w.state.Positions = append(w.state.Positions, newPos)
 								w.saveStateLocked()
 								w.mu.Unlock()
-								output = fmt.Sprintf("✅ PURCHASED: %s %s @ $%s", qty, ticker, verified.FilledAvgPrice.StringFixed(2))
+								w.recordAudit(ticker, auditFilled, "AI", newPos.EntryPrice, fmt.Sprintf("bought %s via AI execute", qty.String()))
+								w.recordSlippage(ticker, "buy", "AI", decisionPrice, mid, verified)
+								w.protectAtBroker(ticker)
+								output = fmt.Sprintf("✅ PURCHASED: %s %s @ $%s | SL: $%s | TP: $%s",
+									qty, ticker, entry.StringFixed(2), sl.StringFixed(2), tp.StringFixed(2))
 							} else {
+								// Resting: the poll loop opens the position (with these levels) on fill
+								w.trackPendingOrder(verified, PendingProposal{Ticker: ticker, Qty: qty, StopLoss: sl, TakeProfit: tp, TrailingStopPct: tsPct}, thesisID)
 								output = fmt.Sprintf("⚠️ Buy Pending (%s): Status %s", ticker, verified.Status)
 							}
 						}
 					}
 				}
+				if len(fundingNotes) > 0 {
+					output = strings.Join(fundingNotes, "\n") + "\n" + output
+				}
+			}
+		} else if cmdType == "/sell" && len(parts) >= 2 {
+			// Same path as /sell (PDT guard, then a verified sell), but only a sell that
+			// went through makes later buys wait for its proceeds
+			ticker := symbols.Normalize(parts[1])
+			if msg, ok := w.guardPDTSell(ticker); !ok {
+				output = msg
+			} else {
+				var sold bool
+				output, sold = w.sellPosition(ticker)
+				soldInBatch = soldInBatch || sold
 			}
 		} else {
-			// Delegate /sell, /update to standard handlers which are synchronous enough
-			// But wait, /sell also needs verification if used in batch?
-			// handleSellCommand does verification!
-			// handleUpdateCommand updates state immediately.
+			// Delegate /update to the standard handler, which updates state immediately.
 			output = w.HandleCommand(cmd)
 		}
 
-		if i > 0 {
+		if resultsBuilder.Len() > 0 {
 			resultsBuilder.WriteString("\n---\n")
 		}
 		resultsBuilder.WriteString(fmt.Sprintf("Cmd: `%s`\nResult: %s", cmd, output))
@@ -379,7 +603,7 @@ func (w *Watcher) handleAICallback(data string) string {
 		// HandleCommand(/sell) awaits.
 		// So we are good. Just a small safety buffer.
 		if len(commands) > 1 {
-			time.Sleep(200 * time.Millisecond)
+			w.clock.Sleep(200 * time.Millisecond)
 		}
 	}
 
@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// Carry: what holding a crypto position earns or costs beyond its price, accrued per
+// position on every poll and counted in its P/L (/status, /history, books, EOD).
+// The annual rate comes from CRYPTO_CARRY_RATES ("ETH/USD=3.5,BTC/USD=-8": staking
+// yield +, funding/borrow cost -) or, when the ticker is not listed there, from the
+// provider (market.CarrySource, e.g. Kraken Earn), fetched once per CET day.
+
+const hoursPerYear = 365 * 24
+
+// carryEntry caches a ticker's provider carry rate for one CET day.
+type carryEntry struct {
+	day  string
+	rate decimal.Decimal
+}
+
+// configuredCarryRate looks ticker up in CRYPTO_CARRY_RATES. Malformed entries are
+// logged and skipped.
+func (w *Watcher) configuredCarryRate(ticker string) (decimal.Decimal, bool) {
+	for _, spec := range w.config.CryptoCarryRates {
+		name, value, ok := strings.Cut(spec, "=")
+		if !ok || symbols.Normalize(strings.TrimSpace(name)) != ticker {
+			continue
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil {
+			log.Printf("CRYPTO_CARRY_RATES: ignoring %q: %v", spec, err)
+			continue
+		}
+		return rate, true
+	}
+	return decimal.Zero, false
+}
+
+// carryRate returns ticker's annual carry in %; zero for non-crypto tickers and when
+// no rate is known.
+func (w *Watcher) carryRate(ticker string) decimal.Decimal {
+	if !symbols.IsCrypto(ticker) {
+		return decimal.Zero
+	}
+	if rate, ok := w.configuredCarryRate(ticker); ok {
+		return rate
+	}
+	src := market.FindCarrySource(w.provider)
+	if src == nil {
+		return decimal.Zero
+	}
+	day := w.clock.Now().In(config.CetLoc).Format("2006-01-02")
+	w.mu.RLock()
+	e, ok := w.carryCache[ticker]
+	w.mu.RUnlock()
+	if ok && e.day == day {
+		return e.rate
+	}
+	rate, err := src.CarryRate(w.ctx, ticker)
+	if err != nil {
+		log.Printf("[%s] Carry rate unavailable: %v", ticker, err)
+		rate = decimal.Zero
+	}
+	w.mu.Lock()
+	w.carryCache[ticker] = carryEntry{day: day, rate: rate}
+	w.mu.Unlock()
+	return rate
+}
+
+// carryAccrual is the carry earned by value at an annual rate (%) over elapsed.
+func carryAccrual(value, rate decimal.Decimal, elapsed time.Duration) decimal.Decimal {
+	if elapsed <= 0 {
+		return decimal.Zero
+	}
+	return value.Mul(rate).Div(decimal.NewFromInt(100)).
+		Mul(decimal.NewFromFloat(elapsed.Hours())).Div(decimal.NewFromInt(hoursPerYear))
+}
+
+// accrueCarry adds the carry since the last accrual to each active crypto position.
+// Rates and prices are fetched outside the lock; a position without either is only
+// moved forward (CarryAt), so an outage does not back-date a later rate.
+func (w *Watcher) accrueCarry() {
+	w.mu.RLock()
+	var tickers []string
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && symbols.IsCrypto(p.Ticker) {
+			tickers = append(tickers, p.Ticker)
+		}
+	}
+	w.mu.RUnlock()
+	if len(tickers) == 0 {
+		return
+	}
+
+	rates := make(map[string]decimal.Decimal)
+	prices := make(map[string]decimal.Decimal)
+	for _, t := range tickers {
+		rate := w.carryRate(t)
+		if rate.IsZero() {
+			continue
+		}
+		price, err := w.provider.GetPrice(w.ctx, t)
+		if err != nil || !price.IsPositive() {
+			continue
+		}
+		rates[t], prices[t] = rate, price
+	}
+
+	now := w.clock.Now()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range w.state.Positions {
+		p := &w.state.Positions[i]
+		if p.Status != "ACTIVE" || !symbols.IsCrypto(p.Ticker) {
+			continue
+		}
+		from := p.CarryAt
+		if from.IsZero() {
+			from = p.OpenedAt
+		}
+		if !from.IsZero() {
+			if rate, ok := rates[p.Ticker]; ok {
+				p.Carry = p.Carry.Add(carryAccrual(p.Quantity.Mul(prices[p.Ticker]), rate, now.Sub(from)))
+			}
+		}
+		p.CarryAt = now
+	}
+	w.saveStateLocked()
+}
@@ -0,0 +1,60 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"alpha_trading/internal/config"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCarryAccrual(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		rate    string
+		elapsed time.Duration
+		want    string // Rounded to the cent
+	}{
+		{"one year of staking", "10000", "4", hoursPerYear * time.Hour, "400"},
+		{"one day of staking", "36500", "5", 24 * time.Hour, "5"},
+		{"funding cost is negative", "10000", "-8.76", time.Hour, "-0.1"},
+		{"zero rate", "10000", "0", 24 * time.Hour, "0"},
+		{"no time elapsed", "10000", "4", 0, "0"},
+		{"clock went backwards", "10000", "4", -time.Hour, "0"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := carryAccrual(decimal.RequireFromString(tc.value), decimal.RequireFromString(tc.rate), tc.elapsed).Round(2)
+			if !got.Equal(decimal.RequireFromString(tc.want)) {
+				t.Fatalf("carryAccrual = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfiguredCarryRate(t *testing.T) {
+	w := &Watcher{config: &config.Config{CryptoCarryRates: []string{"eth/usd=3.5", " BTC-USD = -8 ", "SOL/USD=abc", "DOGE/USD"}}}
+	cases := []struct {
+		ticker string
+		want   string // "" = not configured
+	}{
+		{"ETH/USD", "3.5"},
+		{"BTC/USD", "-8"},
+		{"SOL/USD", ""},
+		{"DOGE/USD", ""},
+		{"AVAX/USD", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.ticker, func(t *testing.T) {
+			rate, ok := w.configuredCarryRate(tc.ticker)
+			if ok != (tc.want != "") {
+				t.Fatalf("configured = %v, want %v", ok, tc.want != "")
+			}
+			if ok && !rate.Equal(decimal.RequireFromString(tc.want)) {
+				t.Fatalf("rate = %s, want %s", rate, tc.want)
+			}
+		})
+	}
+}
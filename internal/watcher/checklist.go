@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/telegram"
+)
+
+// The pre-trade checklist (TRADE_CHECKLIST): before a /buy proposal is shown, each
+// question is asked in turn with YES/NO buttons. All YES shows the proposal; a NO
+// cancels it. The answers go into the audit trail (/why) either way.
+
+const checklistTTL = 5 * time.Minute // The whole checklist must be answered within this
+
+// pendingTradeChecklist is a built proposal held back until its checklist is answered.
+type pendingTradeChecklist struct {
+	id       string
+	at       time.Time
+	proposal PendingProposal
+	msg      string   // Proposal text, shown once every answer is YES
+	answers  []string // "question: yes" per answered question
+}
+
+// startChecklist holds the proposal back and asks the first question. Returns false
+// (nothing sent) when no checklist is configured.
+func (w *Watcher) startChecklist(proposal PendingProposal, msg string) bool {
+	if len(w.checklistQuestions()) == 0 {
+		return false
+	}
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingChecklist = &pendingTradeChecklist{id: id, at: w.clock.Now(), proposal: proposal, msg: msg}
+	w.mu.Unlock()
+	w.askChecklist(proposal.Ticker, id, 0)
+	return true
+}
+
+// checklistQuestions returns the configured questions, blanks dropped.
+func (w *Watcher) checklistQuestions() []string {
+	var out []string
+	for _, q := range w.config.TradeChecklist {
+		if q = strings.TrimSpace(q); q != "" {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// askChecklist sends question n with its YES/NO buttons.
+func (w *Watcher) askChecklist(ticker, id string, n int) {
+	questions := w.checklistQuestions()
+	telegram.SendInteractiveMessage(fmt.Sprintf("📋 *CHECKLIST* %s (%d/%d)\n%s", ticker, n+1, len(questions), questions[n]),
+		[]telegram.Button{
+			{Text: "✅ YES", CallbackData: fmt.Sprintf("CHECK_YES_%s_%d", id, n)},
+			{Text: "❌ NO", CallbackData: fmt.Sprintf("CHECK_NO_%s_%d", id, n)},
+		})
+}
+
+// handleChecklistCallback records one answer (CHECK_<YES|NO>_<id>_<n>), then asks the
+// next question, cancels on NO, or shows the proposal after the last YES.
+func (w *Watcher) handleChecklistCallback(data string) string {
+	parts := strings.SplitN(data, "_", 4)
+	if len(parts) < 4 {
+		return "⚠️ Invalid checklist callback data."
+	}
+	questions := w.checklistQuestions()
+
+	w.mu.Lock()
+	pending := w.pendingChecklist
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > checklistTTL {
+		w.mu.Unlock()
+		return "⚠️ Checklist expired. Send the /buy again."
+	}
+	n := len(pending.answers)
+	if parts[3] != fmt.Sprintf("%d", n) || n >= len(questions) {
+		w.mu.Unlock()
+		return "" // A repeated tap on an answered question
+	}
+	yes := parts[1] == "YES"
+	answer := "no"
+	if yes {
+		answer = "yes"
+	}
+	pending.answers = append(pending.answers, questions[n]+": "+answer)
+	done := !yes || len(pending.answers) == len(questions)
+	if done {
+		w.pendingChecklist = nil
+	}
+	w.mu.Unlock()
+
+	proposal := pending.proposal
+	if !done {
+		w.askChecklist(proposal.Ticker, pending.id, n+1)
+		return ""
+	}
+	w.recordAudit(proposal.Ticker, auditChecklist, "USER", proposal.Price, strings.Join(pending.answers, "; "))
+	if !yes {
+		log.Printf("Checklist: %s proposal cancelled (%s)", proposal.Ticker, questions[n])
+		w.recordAudit(proposal.Ticker, auditCancelled, "USER", proposal.Price, "checklist answered no")
+		return fmt.Sprintf("❌ Purchase of %s cancelled: \"%s\" answered NO.", proposal.Ticker, questions[n])
+	}
+	return w.presentProposal(proposal, pending.msg+"\n\n📋 Checklist: all YES.")
+}
@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// The daily loss circuit breaker (MAX_DAILY_LOSS_PCT): once the day's P/L falls below
+// -MAX_DAILY_LOSS_PCT, every buy path is blocked (checkCompliance) and the automatic AI
+// analysis is paused until the next session. Exits keep running.
+
+// sessionDay is the ET date the breaker is scoped to.
+func (w *Watcher) sessionDay() string {
+	return w.clock.Now().In(easternLoc()).Format("2006-01-02")
+}
+
+// dailyLossHalted reports whether the breaker tripped in the current session.
+func (w *Watcher) dailyLossHalted() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state.DailyLossHalt != "" && w.state.DailyLossHalt == w.sessionDay()
+}
+
+// dayPL returns the portfolio's P/L since the previous close, from the broker's 1D
+// portfolio history (base value) and the current equity.
+func (w *Watcher) dayPL() (pl, pct decimal.Decimal, err error) {
+	h, err := w.provider.GetPortfolioHistory(w.ctx, "1D", "15Min")
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	base := h.BaseValue
+	if !base.IsPositive() && len(h.Equity) > 0 {
+		base = h.Equity[0]
+	}
+	if !base.IsPositive() {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("no base equity in the portfolio history")
+	}
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil || !equity.IsPositive() {
+		return decimal.Zero, decimal.Zero, fmt.Errorf("equity unavailable: %v", err)
+	}
+	pl = equity.Sub(base)
+	return pl, pl.Div(base).Mul(decimal.NewFromInt(100)), nil
+}
+
+// checkDailyLoss trips the breaker when the day's loss reaches MAX_DAILY_LOSS_PCT, and
+// clears a trip from an earlier session.
+func (w *Watcher) checkDailyLoss() {
+	if w.config.MaxDailyLossPct <= 0 {
+		return
+	}
+	today := w.sessionDay()
+	w.mu.Lock()
+	halt := w.state.DailyLossHalt
+	if halt != "" && halt != today {
+		w.state.DailyLossHalt = ""
+		w.saveStateLocked()
+		log.Printf("Circuit Breaker: reset for session %s", today)
+	}
+	w.mu.Unlock()
+	if halt == today || (!w.wasMarketOpen && !w.holdsCrypto()) {
+		return
+	}
+
+	pl, pct, err := w.dayPL()
+	if err != nil {
+		log.Printf("Circuit Breaker: day P/L unavailable: %v", err)
+		return
+	}
+	if pct.GreaterThan(decimal.NewFromFloat(-w.config.MaxDailyLossPct)) {
+		return
+	}
+
+	w.mu.Lock()
+	w.state.DailyLossHalt = today
+	cancelled := len(w.pendingProposals)
+	w.pendingProposals = make(map[string]PendingProposal) // Unconfirmed buys die with the session
+	w.saveStateLocked()
+	w.mu.Unlock()
+	log.Printf("[CIRCUIT_BREAKER] Day P/L %s%% ($%s) breached -%.2f%%", pct.StringFixed(2), pl.StringFixed(2), w.config.MaxDailyLossPct)
+
+	msg := fmt.Sprintf("🚨 *CIRCUIT BREAKER TRIPPED*\nDay P/L: %s%% ($%s), past the -%.2f%% limit (MAX_DAILY_LOSS_PCT).\n\n"+
+		"⛔ New buys are blocked and the automatic AI analysis is paused until the next session. Stops, take-profits and sells keep working.",
+		pct.StringFixed(2), pl.StringFixed(2), w.config.MaxDailyLossPct)
+	if cancelled > 0 {
+		msg += fmt.Sprintf("\n%d open buy proposal(s) cancelled.", cancelled)
+	}
+	telegram.Notify(msg)
+}
@@ -8,6 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"alpha_trading/internal/budget"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
 	"alpha_trading/internal/telegram"
 
 	"github.com/shopspring/decimal"
@@ -21,6 +26,14 @@ type CommandDoc struct {
 
 // HandleCommand processes inbound Telegram commands safely.
 func (w *Watcher) HandleCommand(cmd string) string {
+	// Instance prefix (/crypto status): relay to that instance, or strip our own
+	if peer, rest := w.shardTarget(cmd); rest != "" {
+		if peer != nil {
+			return w.relay(peer, "/command", shardRelay{Text: rest})
+		}
+		cmd = rest
+	}
+
 	parts := strings.Fields(cmd)
 	if len(parts) == 0 {
 		return ""
@@ -36,11 +49,15 @@ func (w *Watcher) HandleCommand(cmd string) string {
 		return w.getList()
 	case "/price":
 		if len(parts) < 2 {
-			return "Usage: /price <ticker>"
+			return w.getFavoritePrices()
 		}
-		return w.getPrice(strings.ToUpper(parts[1]))
+		return w.getPrice(symbols.Normalize(parts[1]))
 	case "/market":
 		return w.getMarketStatus()
+	case "/news":
+		return w.handleNewsCommand(parts)
+	case "/info":
+		return w.handleInfoCommand(parts)
 	case "/search":
 		if len(parts) < 2 {
 			return "Usage: /search <query>"
@@ -54,8 +71,79 @@ func (w *Watcher) HandleCommand(cmd string) string {
 	case "/buy":
 		w.SyncWithBroker() // Spec 68 JIT
 		return w.handleBuyCommand(parts)
+	case "/protect":
+		return w.handleProtectCommand(parts)
+	case "/buylimit":
+		w.SyncWithBroker() // Spec 68 JIT
+		return w.handleBuyLimitCommand(parts)
 	case "/scan":
 		return w.handleScanCommand(parts)
+	case "/history":
+		return w.handleHistoryCommand(parts)
+	case "/why":
+		return w.handleWhyCommand(parts)
+	case "/slippage":
+		return w.handleSlippageCommand(parts)
+	case "/returns":
+		return w.handleReturnsCommand(parts)
+	case "/goal", "/goals":
+		return w.handleGoalCommand(parts)
+	case "/optimize":
+		return w.handleOptimizeCommand(parts)
+	case "/events":
+		return w.handleEventsCommand(parts)
+	case "/env":
+		return w.handleEnvCommand(parts)
+	case "/review":
+		return w.handleReviewCommand()
+	case "/stats":
+		return w.handleStatsCommand(parts)
+	case "/latency":
+		return w.handleLatencyCommand(parts)
+	case "/pin":
+		return w.handlePinCommand(parts)
+	case "/divergence":
+		return w.handleDivergenceCommand(parts)
+	case "/block", "/unblock":
+		return w.handleBlockCommand(parts)
+	case "/import":
+		return w.handleImportCommand(cmd)
+	case "/export-config":
+		return w.handleExportConfigCommand()
+	case "/import-config":
+		return w.handleImportConfigCommand(cmd)
+	case "/state":
+		return w.handleStateCommand(parts)
+	case "/doctor":
+		return w.handleDoctorCommand(parts)
+	case "/setup":
+		return w.handleSetupCommand()
+	case "/settings":
+		return w.handleSettingsCommand(parts)
+	case "/watch":
+		return w.handleWatchCommand(parts)
+	case "/priority":
+		return w.handlePriorityCommand(parts)
+	case "/spreads":
+		return w.handleSpreadsCommand(parts)
+	case "/hedge":
+		return w.handleHedgeCommand(parts)
+	case "/size":
+		return w.handleSizeCommand(parts)
+	case "/sweep":
+		return w.handleSweepCommand(parts)
+	case "/resume":
+		return w.handleResumeCommand()
+	case "/stops":
+		return w.sendStopReview()
+	case "/benchmark":
+		return w.handleBenchmarkCommand(parts)
+	case "/book":
+		return w.handleBookCommand(parts)
+	case "/project":
+		return w.handleProjectCommand()
+	case "/risk":
+		return w.handleRiskCommand()
 	case "/portfolio":
 		return w.handlePortfolioCommand()
 	case "/sell":
@@ -78,55 +166,88 @@ func (w *Watcher) HandleCommand(cmd string) string {
 }
 
 func (w *Watcher) handleScanCommand(parts []string) string {
+	available := "biotech, metals, energy, defense"
+	if known := w.metadata.Sectors(); len(known) > 0 {
+		available += "\nSectors: " + strings.Join(known, ", ")
+	}
+
 	if len(parts) < 2 {
-		return "Usage: /scan <sector>\nAvailable: biotech, metals, energy, defense"
+		return "Usage: /scan <sector>\nAvailable: " + available
 	}
 
-	sectorKey := strings.ToLower(parts[1])
-	tickers, exists := sectors[sectorKey]
-	if !exists {
-		return fmt.Sprintf("⚠️ Unknown sector '%s'.\nAvailable: biotech, metals, energy, defense", sectorKey)
+	// Multi-word sectors are allowed (e.g., "/scan health care")
+	sectorKey := strings.ToLower(strings.Join(parts[1:], " "))
+	tickers := w.scanUniverse(sectorKey)
+	if len(tickers) == 0 {
+		return fmt.Sprintf("⚠️ Unknown sector '%s'.\nAvailable: %s", sectorKey, available)
 	}
 
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("🔋 *SECTOR REPORT: %s*\n", strings.ToUpper(sectorKey)))
 
+	var buttons []telegram.Button
 	for _, ticker := range tickers {
-		price, err := w.provider.GetPrice(ticker)
+		price, err := w.provider.GetPrice(w.ctx, ticker)
 		if err != nil {
 			sb.WriteString(fmt.Sprintf("• %s: ⚠️ Err\n", ticker))
 			continue
 		}
 		sb.WriteString(fmt.Sprintf("• %s: $%s\n", ticker, price.StringFixed(2)))
+		buttons = append(buttons, telegram.Button{Text: fmt.Sprintf("➕ %s", ticker), CallbackData: fmt.Sprintf("WATCH_ADD_%s", ticker)})
 	}
 
-	return sb.String()
+	if len(buttons) == 0 {
+		return sb.String()
+	}
+
+	// Scan -> Watch pipeline: one-tap add to the runtime watchlist
+	sb.WriteString("\nTap to ADD TO WATCHLIST:")
+	telegram.SendInteractiveMessage(sb.String(), buttons)
+	return "" // Message sent interactively
 }
 
 func (w *Watcher) handleBuyCommand(parts []string) string {
 	// 1. Parsing & Default Logic (Spec 41)
-	// /buy AAPL 1 [sl] [tp]
+	// /buy AAPL 1 [sl] [tp] [ext]
+	// /buy AAPL $50 [sl] [tp] [ext]  -> notional (fractional shares)
+	// book=<name> anywhere after the ticker buys into a virtual book (/book)
+	parts, book := splitBookArg(parts)
+	extended := false
+	if n := len(parts); n > 2 && strings.ToLower(parts[n-1]) == "ext" {
+		extended, parts = true, parts[:n-1] // Extended-hours limit order
+	}
+	if len(parts) == 2 {
+		// Default quantity from /settings qty
+		w.mu.RLock()
+		defaultQty := w.state.Settings.DefaultQty
+		w.mu.RUnlock()
+		if defaultQty.IsPositive() {
+			parts = append(parts, defaultQty.String())
+		}
+	}
 	if len(parts) < 3 {
-		return "Usage: /buy <ticker> <qty> [sl] [tp]"
+		return "Usage: /buy <ticker> <qty|$amount> [sl] [tp] [ext] [book=<name>]"
 	}
 
-	ticker := strings.ToUpper(parts[1])
+	ticker := symbols.Normalize(parts[1])
+	if symbols.IsForex(ticker) {
+		return fmt.Sprintf("⚠️ %s is a forex pair: watch-only. Use `/watch add %s below=<rate> above=<rate>` for alerts.", ticker, strings.ReplaceAll(ticker, "/", ""))
+	}
 
-	// 1.5 Validation Gate (Duplicate Order Check) - Restored
-	openOrders, err := w.provider.ListOrders("open")
-	if err == nil {
-		for _, o := range openOrders {
-			if o.Symbol == ticker {
-				return fmt.Sprintf("⚠️ Order already pending for %s. Cancel it on Alpaca before placing a new one.", ticker)
-			}
+	order := buyOrder{Book: book}
+	var qty decimal.Decimal
+	if amount, ok := strings.CutPrefix(parts[2], "$"); ok {
+		notional, err := decimal.NewFromString(amount)
+		if err != nil || !notional.IsPositive() {
+			return "⚠️ Invalid amount format."
 		}
+		order.Notional = money.Cash(notional)
 	} else {
-		log.Printf("Warning: Failed to list open orders: %v", err)
-	}
-
-	qty, err1 := decimal.NewFromString(parts[2])
-	if err1 != nil {
-		return "⚠️ Invalid quantity format."
+		q, err := decimal.NewFromString(parts[2])
+		if err != nil || !q.IsPositive() {
+			return "⚠️ Invalid quantity format."
+		}
+		qty = q
 	}
 
 	// Optional SL
@@ -147,37 +268,167 @@ func (w *Watcher) handleBuyCommand(parts []string) string {
 		return "⚠️ Invalid price format."
 	}
 
+	order.ExtendedHours = extended
+	return w.proposeBuy(ticker, qty, sl, tp, order)
+}
+
+// handleBuyLimitCommand proposes a limit buy. The limit also serves as the entry
+// reference for default SL/TP, compliance and budget checks.
+// /buylimit AAPL 2 185.50 [day|gtc] [ext]
+func (w *Watcher) handleBuyLimitCommand(parts []string) string {
+	const usage = "Usage: /buylimit <ticker> <qty> <limit> [day|gtc] [ext] [book=<name>]"
+	parts, book := splitBookArg(parts)
+	if len(parts) < 4 {
+		return usage
+	}
+	order := buyOrder{TimeInForce: "day", Book: book}
+	for _, opt := range parts[4:] {
+		switch strings.ToLower(opt) {
+		case "day", "gtc":
+			order.TimeInForce = strings.ToLower(opt)
+		case "ext":
+			order.ExtendedHours = true
+		default:
+			return usage
+		}
+	}
+	if order.ExtendedHours && order.TimeInForce != "day" {
+		return "⚠️ Extended-hours orders must be DAY orders (drop gtc or ext)."
+	}
+
+	qty, err := decimal.NewFromString(parts[2])
+	if err != nil || !qty.IsPositive() {
+		return "⚠️ Invalid quantity format."
+	}
+	limit, err := decimal.NewFromString(parts[3])
+	if err != nil || !limit.IsPositive() {
+		return "⚠️ Invalid limit price."
+	}
+	ticker := symbols.Normalize(parts[1])
+	order.LimitPrice = money.OrderPrice(ticker, "buy", limit)
+
+	return w.proposeBuy(ticker, qty, decimal.Zero, decimal.Zero, order)
+}
+
+// buyOrder is how a proposal is submitted. The zero value is a regular-session market order.
+type buyOrder struct {
+	LimitPrice    decimal.Decimal // /buylimit; zero = market
+	TimeInForce   string          // "day" (default) or "gtc"
+	ExtendedHours bool            // /buy ... ext or /buylimit ... ext
+	Notional      decimal.Decimal // /buy AAPL $50; qty is then an estimate at the current price
+	Book          string          // book=<name>: virtual sub-portfolio with its own budget
+}
+
+// proposeBuy runs the buy gates (symbol, duplicate order, compliance, buying power,
+// budget) and sends the EXECUTE/CANCEL proposal. Zero sl/tp use the defaults.
+func (w *Watcher) proposeBuy(ticker string, qty, sl, tp decimal.Decimal, order buyOrder) string {
+	if order.ExtendedHours && symbols.IsCrypto(ticker) {
+		order.ExtendedHours = false // Crypto trades around the clock
+	}
+
+	// 1.1 Book Gate: the named book must exist (its limits apply below)
+	var book models.Book
+	if order.Book != "" {
+		w.mu.RLock()
+		b, ok := w.bookLocked(order.Book)
+		w.mu.RUnlock()
+		if !ok {
+			return fmt.Sprintf("⚠️ Book '%s' not found. Create it with /book add.", order.Book)
+		}
+		book = b
+	}
+
+	// 1.2 Symbol Gate: catch bad symbols (with suggestions) before any order logic
+	if msg, ok := w.validateSymbol(ticker); !ok {
+		return msg
+	}
+	if w.isSweepTicker(ticker) {
+		return fmt.Sprintf("💤 %s is the cash-sweep ETF and is not tracked as a position. Use /sweep now.", ticker)
+	}
+
+	// 1.5 Validation Gate (Duplicate Order Check) - Restored
+	openOrders, err := w.provider.ListOrders(w.ctx, "open")
+	if err == nil {
+		for _, o := range openOrders {
+			if o.Symbol == ticker {
+				return fmt.Sprintf("⚠️ Order already pending for %s. Cancel it on Alpaca before placing a new one.", ticker)
+			}
+		}
+	} else {
+		log.Printf("Warning: Failed to list open orders: %v", err)
+	}
+
 	// 2. Price Check Gate (needed for Default Calc)
-	price, err := w.provider.GetPrice(ticker)
+	price, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker)
 	if err != nil {
 		return fmt.Sprintf("⚠️ Could not fetch price for %s.", ticker)
 	}
+	if order.LimitPrice.IsPositive() {
+		// A limit order is sized, budgeted and protected at its worst-case fill
+		price = order.LimitPrice
+	}
+	if order.Notional.IsPositive() {
+		qty = money.Qty(ticker, order.Notional.Div(price), true)
+		if !qty.IsPositive() {
+			return fmt.Sprintf("⚠️ $%s buys less than the minimum quantity of %s ($%s).", order.Notional.StringFixed(2), ticker, price.StringFixed(2))
+		}
+	}
+
+	// 2.05 Fractional Gate: Alpaca only takes fractional and notional orders on fractionable assets
+	if !symbols.IsCrypto(ticker) && (order.Notional.IsPositive() || !qty.Equal(qty.Floor())) {
+		if asset, err := w.provider.GetAsset(w.ctx, ticker); err == nil && asset != nil && !asset.Fractionable {
+			return fmt.Sprintf("⚠️ %s does not support fractional shares. Use a whole quantity.", ticker)
+		}
+	}
+
+	// 2.1 Compliance Gate (declarative pre-trade rules)
+	if msg, ok := w.checkCompliance(ticker, qty, price); !ok {
+		return msg
+	}
 
-	// Default Logic (Spec 41)
+	// Default Logic (Spec 41); a book's own SL/TP % take precedence
+	slPct, tpPct := w.config.DefaultStopLossPct, w.config.DefaultTakeProfitPct
+	if book.StopLossPct > 0 {
+		slPct = book.StopLossPct
+	}
+	if book.TakeProfitPct > 0 {
+		tpPct = book.TakeProfitPct
+	}
 	if sl.IsZero() {
 		// Entry * (1 - DefaultSL/100)
-		multiplier := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(w.config.DefaultStopLossPct).Div(decimal.NewFromInt(100)))
+		multiplier := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(slPct).Div(decimal.NewFromInt(100)))
 		sl = price.Mul(multiplier)
 	}
 
 	if tp.IsZero() {
 		// Entry * (1 + DefaultTP/100)
-		multiplier := decimal.NewFromInt(1).Add(decimal.NewFromFloat(w.config.DefaultTakeProfitPct).Div(decimal.NewFromInt(100)))
+		multiplier := decimal.NewFromInt(1).Add(decimal.NewFromFloat(tpPct).Div(decimal.NewFromInt(100)))
 		tp = price.Mul(multiplier)
 	}
+	sl, tp = money.Price(ticker, sl), money.Price(ticker, tp) // Snap to tick (also user-supplied levels)
 
 	// Default Trailing Stop (Spec 41 Safety)
 	tsPct := decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
 
 	totalCost := price.Mul(qty)
-	buyingPower, err := w.provider.GetBuyingPower()
+	if order.Notional.IsPositive() {
+		totalCost = order.Notional // The broker spends exactly this, whatever the fill price
+	}
+	buyingPower, err := w.provider.GetBuyingPower(w.ctx)
 	if err != nil {
 		log.Printf("Error fetching BP: %v", err)
 		return "⚠️ Error checking buying power."
 	}
 
+	sweepNote := ""
 	if totalCost.GreaterThan(buyingPower) {
-		return fmt.Sprintf("❌ Insufficient Buying Power.\nRequired: $%s\nAvailable: $%s", totalCost.StringFixed(2), buyingPower.StringFixed(2))
+		// The cash sweep is sold back at execution to cover the difference
+		_, swept, _ := w.sweepHolding()
+		if totalCost.GreaterThan(buyingPower.Add(swept)) {
+			return fmt.Sprintf("❌ Insufficient Buying Power.\nRequired: $%s\nAvailable: $%s", totalCost.StringFixed(2), buyingPower.StringFixed(2))
+		}
+		sweepNote = fmt.Sprintf("💤 Buying power is $%s: about $%s of %s is sold from the cash sweep at execution.",
+			buyingPower.StringFixed(2), totalCost.Sub(buyingPower).StringFixed(2), w.config.SweepETF)
 	}
 
 	// --- Spec 63: Fiscal Budget Hard-Stop ---
@@ -213,30 +464,30 @@ func (w *Watcher) handleBuyCommand(parts []string) string {
 	// This caps the *Invested Capital* (Exposure) to $300, ignoring uninvested Cash.
 
 	w.mu.RLock()
-	var currentExposure decimal.Decimal
-	for _, p := range w.state.Positions {
-		if p.Status == "ACTIVE" {
-			// Cost = Qty * EntryPrice
-			cost := p.Quantity.Mul(p.EntryPrice)
-			currentExposure = currentExposure.Add(cost)
-		}
-	}
+	b := w.budgetLocked()
 	w.mu.RUnlock()
 
-	projectedExposure := currentExposure.Add(totalCost)
-	budgetLimit := decimal.NewFromFloat(w.config.FiscalBudgetLimit)
-
-	if projectedExposure.GreaterThan(budgetLimit) {
+	if !b.Allows(totalCost) {
+		projectedExposure := b.Exposure.Add(totalCost)
 		return fmt.Sprintf("❌ Budget Violation (Spec 63):\n"+
 			"Usage: ($%s + $%s) = $%s > Limit: $%s\n"+
 			"Details: %s @ $%s (x%s)",
-			currentExposure.StringFixed(2), totalCost.StringFixed(2), projectedExposure.StringFixed(2), budgetLimit.StringFixed(2),
+			b.Exposure.StringFixed(2), totalCost.StringFixed(2), projectedExposure.StringFixed(2), b.RealCap.StringFixed(2),
 			ticker, price.StringFixed(2), qty.StringFixed(2))
 	}
 
+	// Book limits apply on top of the account-wide budget
+	if book.Name != "" {
+		w.mu.RLock()
+		violation := bookLimitViolation(book, w.state.Positions, totalCost, "")
+		w.mu.RUnlock()
+		if violation != "" {
+			return violation
+		}
+	}
+
 	// Store Proposal
-	w.mu.Lock()
-	w.pendingProposals[ticker] = PendingProposal{
+	proposal := PendingProposal{
 		Ticker:          ticker,
 		Qty:             qty,
 		Price:           price,
@@ -244,9 +495,30 @@ func (w *Watcher) handleBuyCommand(parts []string) string {
 		StopLoss:        sl,
 		TakeProfit:      tp,
 		TrailingStopPct: tsPct,
-		Timestamp:       time.Now(),
+		LimitPrice:      order.LimitPrice,
+		TimeInForce:     order.TimeInForce,
+		ExtendedHours:   order.ExtendedHours,
+		Notional:        order.Notional,
+		Book:            book.Name,
+		Confirm:         w.confirmTier(totalCost),
+	}
+	orderType := "Market"
+	switch {
+	case order.LimitPrice.IsPositive():
+		orderType = fmt.Sprintf("Limit $%s, %s", order.LimitPrice.String(), strings.ToUpper(order.TimeInForce))
+		if order.ExtendedHours {
+			orderType += ", extended hours"
+		}
+	case order.ExtendedHours:
+		orderType = fmt.Sprintf("Limit, extended hours (ask + %.2f%% at execution)", w.config.ExtHoursLimitPct)
+	}
+	size := "x" + qty.String()
+	qtyLabel := qty.String()
+	if order.Notional.IsPositive() {
+		size = "$" + order.Notional.StringFixed(2)
+		qtyLabel = fmt.Sprintf("~%s ($%s notional)", qty.String(), order.Notional.StringFixed(2))
 	}
-	w.mu.Unlock()
+	w.recordAudit(ticker, auditProposed, "USER", price, fmt.Sprintf("/buy %s (SL $%s, TP $%s, %s)", size, sl.StringFixed(2), tp.StringFixed(2), strings.ToLower(orderType)))
 
 	// Response with Buttons
 	msg := fmt.Sprintf("📝 *TRADE PROPOSAL*\n"+
@@ -256,10 +528,67 @@ func (w *Watcher) handleBuyCommand(parts []string) string {
 		"Total: $%s\n"+
 		"SL: $%s | TP: $%s\n"+
 		"TS: %s%%\n"+
-		"Confirm Execution?\n\n"+
-		"⏱️ Valid for %d seconds.",
-		ticker, qty.StringFixed(2), price.StringFixed(2), totalCost.StringFixed(2), sl.StringFixed(2), tp.StringFixed(2), tsPct.StringFixed(2),
-		w.config.ConfirmationTTLSec)
+		"Order: %s",
+		ticker, qtyLabel, price.StringFixed(2), totalCost.StringFixed(2), sl.StringFixed(2), tp.StringFixed(2), tsPct.StringFixed(2),
+		orderType)
+	if book.Name != "" {
+		w.mu.RLock()
+		left := book.Budget.Sub(budget.BookExposure(w.state.Positions, book.Name)).Sub(totalCost)
+		w.mu.RUnlock()
+		msg += fmt.Sprintf("\n\n📚 Book: %s ($%s left after this buy)", book.Name, left.StringFixed(2))
+	}
+
+	if sweepNote != "" {
+		msg += "\n\n" + sweepNote
+	}
+
+	// Advisory sizing (never alters the proposal)
+	if bp, err := w.getBuyingPowerBreakdown(); err == nil && totalCost.GreaterThan(bp.NonMargin) {
+		msg += fmt.Sprintf("\n\n⏳ Cost exceeds non-margin buying power ($%s, plus $%s on margin). The order may be delayed or rejected until sale proceeds settle.",
+			bp.NonMargin.StringFixed(2), bp.Margin.StringFixed(2))
+	}
+	if advice := w.kellyAdvice(price); advice != "" {
+		msg += "\n\n" + advice
+	}
+	if warn := w.spreadWarning(ticker, price.Sub(sl).Div(price).Mul(decimal.NewFromInt(100))); warn != "" {
+		msg += "\n\n" + warn
+	}
+	if w.config.SectorCapMode == "warn" {
+		if warn := w.sectorCapCheck(ticker, totalCost); warn != "" {
+			msg += "\n\n⚠️ Sector cap: " + warn
+		}
+	}
+	if warn := w.correlationWarning(ticker, nil); warn != "" {
+		msg += "\n\n" + warn
+	}
+
+	// TRADE_CHECKLIST holds the proposal back until every question is answered
+	if w.startChecklist(proposal, msg) {
+		return ""
+	}
+	return w.presentProposal(proposal, msg)
+}
+
+// presentProposal stores the proposal and sends it with the EXECUTE/CANCEL buttons,
+// or executes it at once when it is within CONFIRM_AUTO_MAX. The TTL starts here.
+func (w *Watcher) presentProposal(proposal PendingProposal, msg string) string {
+	ticker := proposal.Ticker
+	proposal.Timestamp = w.clock.Now()
+	if proposal.Confirm != confirmAuto {
+		w.mu.Lock()
+		w.pendingProposals[ticker] = proposal
+		w.mu.Unlock()
+	}
+
+	// Small orders skip the button (CONFIRM_AUTO_MAX)
+	if proposal.Confirm == confirmAuto {
+		telegram.Notify(msg + "\n\n⚡ Auto-confirmed (within CONFIRM_AUTO_MAX). Executing...")
+		return w.executeProposal(proposal)
+	}
+	msg += fmt.Sprintf("\n\nConfirm Execution?\n⏱️ Valid for %d seconds.", w.config.ConfirmationTTLSec)
+	if proposal.Confirm == confirmPIN {
+		msg += "\n🔐 Large order: after EXECUTE, send /pin <PIN> to place it."
+	}
 
 	buttons := []telegram.Button{
 		{Text: "✅ EXECUTE", CallbackData: fmt.Sprintf("EXECUTE_BUY_%s", ticker)},
@@ -276,6 +605,13 @@ func (w *Watcher) getHelp() string {
 	for _, cmd := range w.commands {
 		sb.WriteString(fmt.Sprintf("🔹 *%s*\n%s\n`%s`\n\n", cmd.Name, cmd.Description, cmd.Example))
 	}
+	if len(w.peers) > 0 {
+		names := make([]string, len(w.peers))
+		for i, p := range w.peers {
+			names[i] = "/" + p.name
+		}
+		sb.WriteString(fmt.Sprintf("🔀 *Instances*: %s\nPrefix any command to run it there, e.g. `%s status` (`%s` alone shows its help).\n", strings.Join(names, ", "), names[0], names[0]))
+	}
 	return sb.String()
 }
 
@@ -283,8 +619,18 @@ func (w *Watcher) handleSellCommand(parts []string) string {
 	if len(parts) < 2 {
 		return "Usage: /sell <ticker>"
 	}
-	ticker := strings.ToUpper(parts[1])
+	ticker := symbols.Normalize(parts[1])
+	if msg, ok := w.guardPDTSell(ticker); !ok {
+		return msg
+	}
+	msg, _ := w.sellPosition(ticker)
+	return msg
+}
 
+// sellPosition market-sells the broker position in ticker and purges it from state.
+// sold reports whether a sell order was placed and verified.
+func (w *Watcher) sellPosition(ticker string) (string, bool) {
+	sold := false
 	msg := []string{fmt.Sprintf("📉 *Manual Universal Exit: %s*", ticker)}
 
 	// 1. Sequential Clearance (Spec 54)
@@ -296,7 +642,7 @@ func (w *Watcher) handleSellCommand(parts []string) string {
 	}
 
 	// 2. Check Active Positions & Execute Sell
-	positions, err := w.provider.ListPositions()
+	positions, err := w.provider.ListPositions(w.ctx)
 	positionFound := false
 	if err != nil {
 		msg = append(msg, fmt.Sprintf("⚠️ Failed to list positions: %v", err))
@@ -306,7 +652,12 @@ func (w *Watcher) handleSellCommand(parts []string) string {
 				positionFound = true
 
 				// Execute Sell
-				order, err := w.provider.PlaceOrder(ticker, p.Qty, "sell")
+				ref := decimal.Zero
+				if p.CurrentPrice != nil {
+					ref = *p.CurrentPrice
+				}
+				mid := w.quoteMid(ticker)
+				order, err := w.placeOrder(models.OrderIntent{Ticker: ticker, Qty: p.Qty, Side: "sell", Source: "MANUAL"}, w.sellOrderOptions(ticker, ref)...)
 				if err != nil {
 					msg = append(msg, fmt.Sprintf("❌ Failed to sell position: %v", err))
 					log.Printf("[FATAL_TRADE_ERROR] Manual sell failed for %s: %v", ticker, err)
@@ -316,37 +667,20 @@ func (w *Watcher) handleSellCommand(parts []string) string {
 					if vErr != nil {
 						msg = append(msg, fmt.Sprintf("⚠️ Order placed but verification failed: %v", vErr))
 					} else {
-						msg = append(msg, fmt.Sprintf("✅ Triggered Market Sell (Status: %s).", verified.Status))
-
-						// --- Spec 57: State Purity Enforcement (Archive & Delete) ---
-						w.mu.Lock()
-						// Find and capture position data for archive
-						var positionData string
-						deleteIndex := -1
-						for i, pos := range w.state.Positions {
-							if pos.Ticker == ticker && pos.Status == "ACTIVE" {
-								// Capture as JSON for audit
-								// We use a simplified struct or just marshal what we have
-								// Spec says "Extract the full position object"
-								b, _ := json.Marshal(pos)
-								positionData = string(b)
-								deleteIndex = i
-								break
-							}
+						sold = true
+						msg = append(msg, fmt.Sprintf("✅ Triggered %s Sell (Status: %s).", orderKind(order), verified.Status))
+						if p.CurrentPrice != nil {
+							w.recordSlippage(ticker, "sell", "MANUAL", *p.CurrentPrice, mid, verified)
 						}
 
-						// Archive to log
-						if positionData != "" {
-							w.saveDailyPerformance(fmt.Sprintf("ARCHIVED_POSITION: %s", positionData))
+						// --- Spec 57: State Purity Enforcement (Archive & Delete) ---
+						exitPrice := decimal.Zero
+						if verified.FilledAvgPrice != nil {
+							exitPrice = *verified.FilledAvgPrice
 						}
-
-						// Delete from state
-						if deleteIndex != -1 {
-							w.state.Positions = append(w.state.Positions[:deleteIndex], w.state.Positions[deleteIndex+1:]...)
+						if _, purged := w.purgePosition(ticker, exitPrice, "MANUAL"); purged {
 							msg = append(msg, "✅ Local state purged (Spec 57).")
 						}
-						w.mu.Unlock()
-						w.saveState()
 					}
 				}
 				break
@@ -364,7 +698,29 @@ func (w *Watcher) handleSellCommand(parts []string) string {
 	// If it's not on exchange, we can't sell it.
 	// We rely on /refresh to clean up "ghost" local positions.
 
-	return strings.Join(msg, "\n")
+	return strings.Join(msg, "\n"), sold
+}
+
+// purgePosition archives the ACTIVE position for ticker (performance log + trade archive) and
+// deletes it from state (Spec 57). Returns the removed position and whether one was found.
+func (w *Watcher) purgePosition(ticker string, exitPrice decimal.Decimal, reason string) (models.Position, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, pos := range w.state.Positions {
+		if pos.Ticker == ticker && pos.Status == "ACTIVE" {
+			// Capture the full position object as JSON for audit
+			b, _ := json.Marshal(pos)
+			w.saveDailyPerformance(fmt.Sprintf("ARCHIVED_POSITION: %s", string(b)))
+			w.archiveTrade(pos, exitPrice, reason)
+			w.recordAudit(ticker, auditClosed, closeActor(reason), exitPrice, fmt.Sprintf("reason %s, qty %s", reason, pos.Quantity.String()))
+
+			w.state.Positions = append(w.state.Positions[:i], w.state.Positions[i+1:]...)
+			w.saveStateLocked()
+			return pos, true
+		}
+	}
+	return models.Position{}, false
 }
 
 func (w *Watcher) handleUpdateCommand(parts []string) string {
@@ -373,7 +729,7 @@ func (w *Watcher) handleUpdateCommand(parts []string) string {
 		return "Usage: /update <ticker> <sl> <tp> [ts_pct]"
 	}
 
-	ticker := strings.ToUpper(parts[1])
+	ticker := symbols.Normalize(parts[1])
 	sl, err1 := decimal.NewFromString(parts[2])
 	tp, err2 := decimal.NewFromString(parts[3])
 
@@ -386,10 +742,11 @@ func (w *Watcher) handleUpdateCommand(parts []string) string {
 	if err1 != nil || err2 != nil || err3 != nil {
 		return "⚠️ Invalid number format."
 	}
+	sl, tp = money.Price(ticker, sl), money.Price(ticker, tp)
 
 	// --- Spec 51: Intent Mutation Guardrails ---
 	// 1. Context: Get Market Price (Network Call outside lock)
-	currentPrice, err := w.provider.GetPrice(ticker)
+	currentPrice, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker)
 	if err != nil {
 		return fmt.Sprintf("⚠️ Validation Failed: Could not fetch market price for %s to verify safety.", ticker)
 	}
@@ -446,19 +803,28 @@ func (w *Watcher) handleUpdateCommand(parts []string) string {
 
 	// Spec 51: Explicit confirmation format
 	w.saveStateLocked()
-	return fmt.Sprintf("✅ Parameters Updated for %s.\nNew Floor (SL): $%s | New Ceiling (TP): $%s",
+	msg := fmt.Sprintf("✅ Parameters Updated for %s.\nNew Floor (SL): $%s | New Ceiling (TP): $%s",
 		ticker, sl.StringFixed(2), tp.StringFixed(2))
+	// Both need w.mu, held until this handler returns
+	if brokerExitID(w.state.Positions[foundIndex]) != "" {
+		go w.syncBrokerExit(ticker)
+		msg += "\n🛡️ Patching the broker-side exit with the new levels."
+	} else if w.config.BrokerProtectionEnabled {
+		go w.protectAtBroker(ticker)
+		msg += "\n🛡️ Attaching a broker-side exit with the new levels."
+	}
+	return msg
 }
 
 func (w *Watcher) handleRefreshCommand() string {
-	count, discovered, err := w.syncState()
+	count, drift, err := w.syncWithDrift()
 	if err != nil {
 		return fmt.Sprintf("❌ Failed to sync state: %v", err)
 	}
 
 	msg := fmt.Sprintf("🔄 Strict Mirror Sync Complete: Local state aligned with Alpaca (%d active positions).", count)
-	if len(discovered) > 0 {
-		msg += fmt.Sprintf("\n⚠️ Imported & Protected: %s", strings.Join(discovered, ", "))
+	if !drift.empty() {
+		msg += "\n" + drift.format()
 	}
 
 	return msg
@@ -468,7 +834,8 @@ func (w *Watcher) handleRefreshCommand() string {
 // It reads the local portfolio_state.json and returns it as a code block.
 // Refined Logic: Chunks content if > 3900 chars (Spec 50 Refinement).
 func (w *Watcher) handlePortfolioCommand() string {
-	// 1. Read the file
+	// 1. Read the file (after any pending debounced save)
+	w.FlushState()
 	data, err := os.ReadFile("portfolio_state.json")
 	if err != nil {
 		log.Printf("Error reading portfolio_state.json: %v", err)
@@ -502,18 +869,31 @@ func (w *Watcher) handlePortfolioCommand() string {
 		telegram.Notify(msg)
 
 		// Small sleep to ensure ordering (Telegram API race condition mitigation)
-		time.Sleep(200 * time.Millisecond)
+		w.clock.Sleep(200 * time.Millisecond)
 	}
 
 	return "" // Handled proactively
 }
 
 // handleAnalyzeCommand implements Spec 64.
+// /analyze [force] [ticker | watchlist | holdings | <sector>]
 func (w *Watcher) handleAnalyzeCommand(parts []string) string {
-	// Parse optional ticker: /analyze [ticker]
-	ticker := ""
+	// "force" bypasses the analysis cache
+	force := len(parts) > 1 && strings.ToLower(parts[1]) == "force"
+	if force {
+		parts = append(parts[:1], parts[2:]...)
+	}
+
+	// Parse optional scope: a named universe or a single ticker
+	ticker, universe := "", ""
+	var universeTickers []string
 	if len(parts) > 1 {
-		ticker = strings.ToUpper(parts[1])
+		universe, universeTickers = w.resolveAnalyzeScope(strings.Join(parts[1:], " "))
+		if universe == "" {
+			ticker = symbols.Normalize(parts[1])
+		} else if len(universeTickers) == 0 {
+			return fmt.Sprintf("⚠️ Universe '%s' is empty. Nothing to analyze.", universe)
+		}
 	}
 
 	w.mu.Lock()
@@ -524,7 +904,7 @@ func (w *Watcher) handleAnalyzeCommand(parts []string) string {
 	// Since this bot is single-tenant (TELEGRAM_CHAT_ID check in listener), global is "per user".
 	lastRun, exists := w.lastAnalyzeTime["GLOBAL"]
 	if exists {
-		elapsed := time.Since(lastRun)
+		elapsed := w.since(lastRun)
 		if elapsed < 10*time.Minute {
 			remaining := (10 * time.Minute) - elapsed
 			return fmt.Sprintf("⏳ Analysis cooling down. Next available in %.0fs.", remaining.Seconds())
@@ -532,14 +912,20 @@ func (w *Watcher) handleAnalyzeCommand(parts []string) string {
 	}
 
 	// Update timestamp
-	w.lastAnalyzeTime["GLOBAL"] = time.Now()
+	w.lastAnalyzeTime["GLOBAL"] = w.clock.Now()
 
 	// Trigger Async
-	go w.runAIAnalysis(ticker, true)
+	go w.runAIAnalysis(ticker, universe, universeTickers, true, force)
 
 	contextMsg := "Global Review"
 	if ticker != "" {
 		contextMsg = fmt.Sprintf("Focus: %s", ticker)
+	} else if universe != "" {
+		contextMsg = fmt.Sprintf("Universe: %s, %d symbols", universe, len(universeTickers))
+	}
+
+	if force {
+		contextMsg += ", forced"
 	}
 
 	return fmt.Sprintf("⏳ AI Analysis Initiated (%s)... Stand by for report.", contextMsg)
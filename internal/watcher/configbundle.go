@@ -0,0 +1,382 @@
+package watcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+)
+
+const (
+	configBundleKind    = "alpha_watcher_config"
+	configBundleVersion = 1
+)
+
+// configBundle is the portable part of the bot's setup: the preferences and lists managed
+// from Telegram. Positions, history and account data stay behind, and secrets (API keys,
+// tokens, PIN) live in .env, which is never read into the bundle.
+// On import, a section missing from the bundle is left unchanged.
+type configBundle struct {
+	Kind       string                   `json:"kind"`
+	Version    int                      `json:"version"`
+	ExportedAt time.Time                `json:"exported_at"`
+	Settings   *models.UserSettings     `json:"settings,omitempty"`
+	Watchlist  *[]models.WatchlistEntry `json:"watchlist,omitempty"` // Reference prices are the move-alert baselines
+	Benchmarks *[]models.Benchmark      `json:"benchmarks,omitempty"`
+	Books      *[]models.Book           `json:"books,omitempty"`
+	Goals      *[]models.Goal           `json:"goals,omitempty"`
+	Blocklist  *[]string                `json:"blocklist,omitempty"`
+}
+
+// pendingConfigImport is a previewed bundle awaiting the confirm button.
+type pendingConfigImport struct {
+	id     string
+	at     time.Time
+	bundle configBundle
+}
+
+// handleExportConfigCommand sends the config bundle as a JSON file. /export-config
+func (w *Watcher) handleExportConfigCommand() string {
+	w.mu.RLock()
+	settings := w.state.Settings
+	watchlist := append([]models.WatchlistEntry{}, w.state.Watchlist...)
+	benchmarks := append([]models.Benchmark{}, w.state.Benchmarks...)
+	books := append([]models.Book{}, w.state.Books...)
+	goals := append([]models.Goal{}, w.state.Goals...)
+	blocklist := append([]string{}, w.state.Blocklist...)
+	w.mu.RUnlock()
+
+	bundle := configBundle{
+		Kind:       configBundleKind,
+		Version:    configBundleVersion,
+		ExportedAt: w.clock.Now().UTC(),
+		Settings:   &settings,
+		Watchlist:  &watchlist,
+		Benchmarks: &benchmarks,
+		Books:      &books,
+		Goals:      &goals,
+		Blocklist:  &blocklist,
+	}
+	b, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("❌ Could not encode the config: %v", err)
+	}
+
+	name := fmt.Sprintf("alpha_watcher_config_%s.json", w.clock.Now().In(config.CetLoc).Format("20060102"))
+	if err := telegram.SendDocument(name, b, "Import on another bot with /import-config."); err != nil {
+		log.Printf("Config Export Error: %v", err)
+		return fmt.Sprintf("❌ Could not send the config file: %v", err)
+	}
+	log.Printf("Config Export: %s (%d bytes)", name, len(b))
+	return fmt.Sprintf("📤 Config exported: %s\nNo secrets are included; copy `.env` separately when migrating.", bundleSummary(bundle))
+}
+
+// handleImportConfigCommand previews a bundle pasted below the command.
+func (w *Watcher) handleImportConfigCommand(cmd string) string {
+	body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(cmd), "/import-config"))
+	if body == "" {
+		return "📥 *IMPORT CONFIG*\nSend the file from /export-config with the caption `/import-config`, or paste its JSON below the command.\nSections in the bundle replace the current ones; sections it leaves out are kept."
+	}
+	return w.previewConfigImport([]byte(body))
+}
+
+// previewConfigImport validates a bundle and asks for confirmation.
+func (w *Watcher) previewConfigImport(content []byte) string {
+	bundle, err := parseConfigBundle(content, w.clock.Now())
+	if err != nil {
+		return fmt.Sprintf("❌ Invalid config bundle: %v", err)
+	}
+	if bundleSummary(bundle) == "nothing" {
+		return "⚠️ The bundle has no sections to import."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📥 *CONFIG IMPORT PREVIEW*\nExported %s\nReplaces: %s", bundle.ExportedAt.In(config.CetLoc).Format("2006-01-02 15:04 MST"), bundleSummary(bundle)))
+	w.mu.RLock()
+	if kept := keptBooks(w.state, bundle); len(kept) > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️ Books still used by positions are kept: %s", strings.Join(kept, ", ")))
+	}
+	w.mu.RUnlock()
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingConfig = &pendingConfigImport{id: id, at: w.clock.Now(), bundle: bundle}
+	w.mu.Unlock()
+
+	sb.WriteString(fmt.Sprintf("\n\nApplying backs up the state file first. This button expires in %d minutes.", int(importConfirmTTL.Minutes())))
+	telegram.SendInteractiveMessage(sb.String(), []telegram.Button{
+		{Text: "📥 IMPORT", CallbackData: "CFGIMPORT_APPLY_" + id},
+		{Text: "❌ CANCEL", CallbackData: "CFGIMPORT_CANCEL_" + id},
+	})
+	return ""
+}
+
+// parseConfigBundle decodes a bundle strictly and normalizes it. Any invalid entry
+// rejects the whole bundle, so an import never applies half a file.
+func parseConfigBundle(content []byte, now time.Time) (configBundle, error) {
+	var b configBundle
+	d := json.NewDecoder(bytes.NewReader(content))
+	d.DisallowUnknownFields()
+	if err := d.Decode(&b); err != nil {
+		return b, err
+	}
+	if b.Kind != configBundleKind {
+		return b, fmt.Errorf("not an /export-config file (kind %q)", b.Kind)
+	}
+	if b.Version < 1 || b.Version > configBundleVersion {
+		return b, fmt.Errorf("unsupported version %d (this bot reads up to %d)", b.Version, configBundleVersion)
+	}
+
+	if s := b.Settings; s != nil {
+		s.StatusLayout = strings.ToLower(s.StatusLayout)
+		if s.StatusLayout != "" && !contains(statusLayouts, s.StatusLayout) {
+			return b, fmt.Errorf("settings: unknown status layout %q", s.StatusLayout)
+		}
+		for _, c := range s.StatusColumns {
+			if !contains(statusColumns, c) {
+				return b, fmt.Errorf("settings: unknown status column %q", c)
+			}
+		}
+		if s.Language != "" && !contains(supportedLanguages, s.Language) {
+			return b, fmt.Errorf("settings: unsupported language %q", s.Language)
+		}
+		if s.QuietHours != "" {
+			if _, _, err := parseQuietHours(s.QuietHours); err != nil {
+				return b, fmt.Errorf("settings: %v", err)
+			}
+		}
+		if s.DefaultQty.IsNegative() {
+			return b, fmt.Errorf("settings: negative default qty")
+		}
+		if s.EODSections != nil {
+			if _, err := parseEODSections(strings.Join(s.EODSections, ",")); err != nil {
+				return b, fmt.Errorf("settings: %v", err)
+			}
+		}
+		for _, pct := range []float64{s.DefaultStopLossPct, s.DefaultTakeProfitPct, s.DefaultTrailingStopPct} {
+			if pct < 0 || pct >= 100 {
+				return b, fmt.Errorf("settings: default percentage %.2f out of range", pct)
+			}
+		}
+		for i, t := range s.Favorites {
+			s.Favorites[i] = symbols.Normalize(t)
+		}
+	}
+
+	if b.Watchlist != nil {
+		seen := make(map[string]bool)
+		var list []models.WatchlistEntry
+		for _, e := range *b.Watchlist {
+			e.Ticker = symbols.Normalize(e.Ticker)
+			if e.Ticker == "" || e.ReferencePrice.IsNegative() {
+				return b, fmt.Errorf("watchlist: invalid entry %+v", e)
+			}
+			if seen[e.Ticker] {
+				continue
+			}
+			seen[e.Ticker] = true
+			if e.AddedAt.IsZero() {
+				e.AddedAt = now
+			}
+			if e.Source == "" {
+				e.Source = "IMPORT"
+			}
+			list = append(list, e)
+		}
+		*b.Watchlist = list
+	}
+
+	if b.Benchmarks != nil {
+		seen := make(map[string]bool)
+		for _, bm := range *b.Benchmarks {
+			key := strings.ToLower(bm.Name)
+			if key == "" || seen[key] {
+				return b, fmt.Errorf("benchmarks: empty or duplicate name %q", bm.Name)
+			}
+			seen[key] = true
+			if len(bm.Weights) == 0 {
+				return b, fmt.Errorf("benchmarks: %s has no components", bm.Name)
+			}
+			for t, wgt := range bm.Weights {
+				if t == "" || !wgt.IsPositive() {
+					return b, fmt.Errorf("benchmarks: %s has an invalid component %s=%s", bm.Name, t, wgt.String())
+				}
+			}
+		}
+	}
+
+	if b.Books != nil {
+		seen := make(map[string]bool)
+		for i, bk := range *b.Books {
+			bk.Name = strings.ToLower(bk.Name)
+			if bk.Name == "" || bk.Name == "none" || strings.ContainsAny(bk.Name, "=$ ") || seen[bk.Name] {
+				return b, fmt.Errorf("books: invalid or duplicate name %q", bk.Name)
+			}
+			seen[bk.Name] = true
+			if !bk.Budget.IsPositive() || bk.MaxPositions < 0 ||
+				bk.StopLossPct < 0 || bk.StopLossPct >= 100 || bk.TakeProfitPct < 0 || bk.TakeProfitPct >= 100 {
+				return b, fmt.Errorf("books: %s has invalid limits", bk.Name)
+			}
+			(*b.Books)[i] = bk
+		}
+	}
+
+	if b.Goals != nil {
+		for _, g := range *b.Goals {
+			if (g.Kind != goalEquity && g.Kind != goalDrawdown) || !g.Target.IsPositive() {
+				return b, fmt.Errorf("goals: invalid %s goal (target %s)", g.Kind, g.Target.String())
+			}
+		}
+	}
+
+	if b.Blocklist != nil {
+		var list []string
+		for _, t := range *b.Blocklist {
+			if t = symbols.Normalize(t); t != "" && !contains(list, t) {
+				list = append(list, t)
+			}
+		}
+		*b.Blocklist = list
+	}
+	return b, nil
+}
+
+// bundleSummary lists the sections a bundle carries, with their sizes.
+func bundleSummary(b configBundle) string {
+	var parts []string
+	if b.Settings != nil {
+		parts = append(parts, "settings")
+	}
+	count := func(name string, n int) {
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+	}
+	if b.Watchlist != nil {
+		count("watchlist", len(*b.Watchlist))
+	}
+	if b.Benchmarks != nil {
+		count("benchmarks", len(*b.Benchmarks))
+	}
+	if b.Books != nil {
+		count("books", len(*b.Books))
+	}
+	if b.Goals != nil {
+		count("goals", len(*b.Goals))
+	}
+	if b.Blocklist != nil {
+		count("blocked", len(*b.Blocklist))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keptBooks returns the current books a bundle's book list drops while positions or
+// pending orders still reference them.
+func keptBooks(s models.PortfolioState, b configBundle) []string {
+	if b.Books == nil {
+		return nil
+	}
+	incoming := make(map[string]bool)
+	for _, bk := range *b.Books {
+		incoming[bk.Name] = true
+	}
+	used := make(map[string]bool)
+	for _, p := range s.Positions {
+		if p.Status == "ACTIVE" && p.Book != "" {
+			used[p.Book] = true
+		}
+	}
+	for _, o := range s.PendingOrders {
+		if o.Book != "" {
+			used[o.Book] = true
+		}
+	}
+	var kept []string
+	for _, bk := range s.Books {
+		if used[bk.Name] && !incoming[bk.Name] {
+			kept = append(kept, bk.Name)
+		}
+	}
+	sort.Strings(kept)
+	return kept
+}
+
+// handleConfigImportCallback applies (CFGIMPORT_APPLY_<id>) or discards a previewed bundle.
+func (w *Watcher) handleConfigImportCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid config import callback data."
+	}
+
+	w.mu.Lock()
+	pending := w.pendingConfig
+	w.pendingConfig = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > importConfirmTTL {
+		return "⚠️ Config import expired. Send the file again."
+	}
+	if parts[1] != "APPLY" {
+		return "❌ Config import cancelled. Nothing was changed."
+	}
+
+	w.FlushState()
+	backup, err := storage.BackupState()
+	if err != nil {
+		return fmt.Sprintf("❌ Backup failed (%v). Nothing was changed.", err)
+	}
+
+	b := pending.bundle
+	w.mu.Lock()
+	kept := keptBooks(w.state, b)
+	if b.Settings != nil {
+		w.state.Settings = *b.Settings
+	}
+	if b.Watchlist != nil {
+		w.state.Watchlist = *b.Watchlist
+	}
+	if b.Benchmarks != nil {
+		w.state.Benchmarks = *b.Benchmarks
+	}
+	if b.Books != nil {
+		books := *b.Books
+		for _, name := range kept {
+			if bk, ok := w.bookLocked(name); ok {
+				books = append(books, bk)
+			}
+		}
+		w.state.Books = books
+	}
+	if b.Goals != nil {
+		w.state.Goals = *b.Goals
+	}
+	if b.Blocklist != nil {
+		w.state.Blocklist = *b.Blocklist
+	}
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	if b.Settings != nil {
+		w.applySettingsOverrides()
+	}
+	log.Printf("Config Import: %s (backup %s)", bundleSummary(b), backup)
+
+	msg := fmt.Sprintf("✅ Config imported: %s\n💾 Backup: `%s`", bundleSummary(b), backup)
+	if len(kept) > 0 {
+		msg += fmt.Sprintf("\n⚠️ Kept books still in use: %s", strings.Join(kept, ", "))
+	}
+	if b.Watchlist != nil {
+		msg += "\nRun /watch sync to mirror the watchlist to the broker."
+	}
+	return msg
+}
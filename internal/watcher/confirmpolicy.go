@@ -0,0 +1,123 @@
+package watcher
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// Confirmation tiers for buy proposals, chosen by order size.
+const (
+	confirmAuto   = "auto"   // Executes right away, no button
+	confirmButton = "button" // EXECUTE button (default)
+	confirmPIN    = "pin"    // EXECUTE button, then /pin <PIN>
+
+	pinMaxAttempts = 3 // Wrong PINs before the proposal is dropped
+)
+
+// sizeThreshold resolves a CONFIRM_* value to dollars: "250" is an amount, "2%" a share
+// of equity. ok=false when unset, malformed or the equity is unknown.
+func (w *Watcher) sizeThreshold(raw string) (decimal.Decimal, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return decimal.Zero, false
+	}
+	pct := strings.HasSuffix(raw, "%")
+	v, err := decimal.NewFromString(strings.TrimSuffix(raw, "%"))
+	if err != nil || !v.IsPositive() {
+		log.Printf("WARNING: ignoring confirmation threshold %q", raw)
+		return decimal.Zero, false
+	}
+	if !pct {
+		return v, true
+	}
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil || !equity.IsPositive() {
+		return decimal.Zero, false
+	}
+	return equity.Mul(v).Div(decimal.NewFromInt(100)), true
+}
+
+// confirmTier picks the confirmation a buy of cost needs. The PIN tier wins over auto
+// when thresholds overlap, and falls back to the button when no CONFIRM_PIN is set.
+func (w *Watcher) confirmTier(cost decimal.Decimal) string {
+	if min, ok := w.sizeThreshold(w.config.ConfirmPINMin); ok && !cost.LessThan(min) {
+		if w.config.ConfirmPIN != "" {
+			return confirmPIN
+		}
+		log.Printf("WARNING: CONFIRM_PIN_MIN reached but CONFIRM_PIN is not set; using button confirmation.")
+		return confirmButton
+	}
+	if max, ok := w.sizeThreshold(w.config.ConfirmAutoMax); ok && !cost.GreaterThan(max) {
+		return confirmAuto
+	}
+	return confirmButton
+}
+
+// armProposal puts a PIN-tier proposal back after its EXECUTE tap and asks for the PIN.
+func (w *Watcher) armProposal(p PendingProposal) string {
+	p.ArmedAt = w.clock.Now()
+	w.mu.Lock()
+	w.pendingProposals[p.Ticker] = p
+	w.mu.Unlock()
+	left := time.Duration(w.config.ConfirmationTTLSec)*time.Second - w.since(p.Timestamp)
+	return fmt.Sprintf("🔐 Large order ($%s). Send `/pin <PIN> %s` within %s to execute.",
+		p.TotalCost.StringFixed(2), p.Ticker, left.Round(time.Second))
+}
+
+// handlePinCommand completes an armed PIN-tier proposal. /pin <PIN> [TICKER]
+// The ticker is only needed when more than one proposal is armed.
+func (w *Watcher) handlePinCommand(parts []string) string {
+	if len(parts) < 2 {
+		return "Usage: /pin <PIN> [TICKER]"
+	}
+	ticker := ""
+	if len(parts) >= 3 {
+		ticker = symbols.Normalize(parts[2])
+	}
+
+	w.mu.Lock()
+	var armed []string
+	for t, p := range w.pendingProposals {
+		if !p.ArmedAt.IsZero() && (ticker == "" || t == ticker) {
+			armed = append(armed, t)
+		}
+	}
+	if len(armed) == 0 {
+		w.mu.Unlock()
+		return "⚠️ No order is waiting for a PIN. Tap EXECUTE on the proposal first."
+	}
+	if len(armed) > 1 {
+		w.mu.Unlock()
+		return fmt.Sprintf("⚠️ Several orders are waiting for a PIN (%s). Use /pin <PIN> <TICKER>.", strings.Join(armed, ", "))
+	}
+	proposal := w.pendingProposals[armed[0]]
+	if subtle.ConstantTimeCompare([]byte(parts[1]), []byte(w.config.ConfirmPIN)) != 1 {
+		proposal.PINFailures++
+		if proposal.PINFailures >= pinMaxAttempts {
+			delete(w.pendingProposals, proposal.Ticker)
+			w.mu.Unlock()
+			log.Printf("[SECURITY] %s proposal dropped after %d wrong PINs.", proposal.Ticker, pinMaxAttempts)
+			w.recordAudit(proposal.Ticker, auditCancelled, "SYSTEM", proposal.Price, "wrong PIN limit reached")
+			return fmt.Sprintf("🚫 Wrong PIN %d times. The %s proposal was cancelled.", pinMaxAttempts, proposal.Ticker)
+		}
+		w.pendingProposals[proposal.Ticker] = proposal
+		w.mu.Unlock()
+		return fmt.Sprintf("❌ Wrong PIN (%d/%d).", proposal.PINFailures, pinMaxAttempts)
+	}
+	delete(w.pendingProposals, proposal.Ticker)
+	w.mu.Unlock()
+
+	ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
+	if w.since(proposal.Timestamp) > ttl {
+		w.recordAudit(proposal.Ticker, auditCancelled, "SYSTEM", proposal.Price, "proposal expired")
+		return fmt.Sprintf("⏳ TIMEOUT: Proposal for %s expired (> %ds). Action aborted.", proposal.Ticker, w.config.ConfirmationTTLSec)
+	}
+	return w.executeProposal(proposal)
+}
@@ -0,0 +1,219 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	corpActionLookbackDays  = 5  // Catch up on splits missed while the bot was down
+	corpActionLookaheadDays = 7  // Announce upcoming splits and dividends this far ahead
+	corpActionKeepDays      = 60 // Keys older than this are pruned from state
+)
+
+// checkCorporateActions looks up splits and dividends for held tickers once per ET day.
+// The first poll of the day runs well before the open, so a split is applied before
+// any price on the new basis can reach the SL/TP/TS checks.
+func (w *Watcher) checkCorporateActions() {
+	if !w.config.CorporateActionsEnabled {
+		return
+	}
+	now := w.clock.Now()
+	today := now.In(easternLoc()).Format("2006-01-02")
+
+	w.mu.Lock()
+	if w.state.LastCorporateActions == today {
+		w.mu.Unlock()
+		return
+	}
+	var tickers []string
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && !symbols.IsCrypto(p.Ticker) {
+			tickers = append(tickers, p.Ticker)
+		}
+	}
+	w.state.LastCorporateActions = today
+	w.pruneCorporateActionsLocked(now)
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	if len(tickers) == 0 {
+		return
+	}
+	actions, err := w.provider.GetCorporateActions(w.ctx, tickers, now.AddDate(0, 0, -corpActionLookbackDays), now.AddDate(0, 0, corpActionLookaheadDays))
+	if err != nil {
+		log.Printf("Corporate actions check failed: %v", err)
+		w.mu.Lock()
+		w.state.LastCorporateActions = "" // Retry next poll
+		w.mu.Unlock()
+		return
+	}
+	if msgs := w.applyCorporateActions(actions, today); len(msgs) > 0 {
+		telegram.Notify("🏢 *CORPORATE ACTIONS*\n\n" + strings.Join(msgs, "\n\n"))
+	}
+}
+
+// applyCorporateActions rescales positions for splits that went ex on or before today
+// and returns one message per split applied or upcoming action announced.
+func (w *Watcher) applyCorporateActions(actions []market.CorporateAction, today string) []string {
+	var broker map[string]alpaca.Position
+	for _, a := range actions {
+		if a.Type == market.ActionSplit && a.ExDate.Format("2006-01-02") <= today {
+			// Quantity and cost basis come from the broker when it has already booked the split
+			if positions, err := w.provider.ListPositions(w.ctx); err == nil {
+				broker = make(map[string]alpaca.Position, len(positions))
+				for _, p := range positions {
+					broker[p.Symbol] = p
+				}
+			}
+			break
+		}
+	}
+
+	var msgs []string
+	var resync []string
+	w.mu.Lock()
+	for _, a := range actions {
+		ex := a.ExDate.Format("2006-01-02")
+		key := fmt.Sprintf("%s:%s:%s", a.Type, a.Symbol, ex)
+		idx := -1
+		for i, p := range w.state.Positions {
+			if p.Ticker == a.Symbol && p.Status == "ACTIVE" {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+
+		switch {
+		case a.Type == market.ActionSplit && ex <= today:
+			if contains(w.state.CorporateActions, key) {
+				continue
+			}
+			w.state.CorporateActions = append(w.state.CorporateActions, key)
+			pos := &w.state.Positions[idx]
+			if !pos.OpenedAt.IsZero() && pos.OpenedAt.In(easternLoc()).Format("2006-01-02") >= ex {
+				continue // Bought on the new basis
+			}
+			bp, onBroker := broker[a.Symbol]
+			msgs = append(msgs, w.applySplitLocked(pos, a, bp, onBroker))
+			if pos.BrokerOCOID != "" || pos.BrokerTrailID != "" {
+				resync = append(resync, pos.Ticker)
+			}
+		case a.Type == market.ActionSplit:
+			if notice := "notice:" + key; !contains(w.state.CorporateActions, notice) {
+				w.state.CorporateActions = append(w.state.CorporateActions, notice)
+				msgs = append(msgs, fmt.Sprintf("✂️ *%s* splits %s on %s. SL, TP and HWM will be rescaled automatically that morning.",
+					a.Symbol, splitRatio(a.Ratio), ex))
+			}
+		case a.Type == market.ActionDividend && ex >= today:
+			if notice := "notice:" + key; !contains(w.state.CorporateActions, notice) {
+				w.state.CorporateActions = append(w.state.CorporateActions, notice)
+				msgs = append(msgs, w.dividendNoticeLocked(w.state.Positions[idx], a, ex))
+			}
+		}
+	}
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	// Exits resting at the broker carry the old levels
+	for _, t := range resync {
+		go w.syncBrokerExit(t)
+	}
+	return msgs
+}
+
+// applySplitLocked rescales one position for a split of a.Ratio new shares per old share.
+// Caller holds w.mu.
+func (w *Watcher) applySplitLocked(pos *models.Position, a market.CorporateAction, bp alpaca.Position, onBroker bool) string {
+	before := *pos
+	perShare := func(v decimal.Decimal) decimal.Decimal {
+		if v.IsZero() {
+			return v
+		}
+		return money.Price(pos.Ticker, v.Div(a.Ratio))
+	}
+
+	if onBroker && !bp.Qty.Equal(pos.Quantity) {
+		pos.Quantity, pos.EntryPrice = bp.Qty, bp.AvgEntryPrice
+	} else {
+		// Broker not updated yet (or unavailable): scale ourselves, sync corrects the rest
+		pos.Quantity = pos.Quantity.Mul(a.Ratio)
+		pos.EntryPrice = pos.EntryPrice.Div(a.Ratio)
+	}
+	pos.StopLoss = perShare(pos.StopLoss)
+	pos.TakeProfit = perShare(pos.TakeProfit)
+	pos.HighWaterMark = perShare(pos.HighWaterMark)
+	w.writer.RebaseHWM(pos.Ticker, pos.HighWaterMark) // Not a regression: the unit changed
+
+	// Anything measured on the old basis is stale
+	delete(w.pendingActions, pos.Ticker)
+	delete(w.atrCache, pos.Ticker)
+
+	log.Printf("[SPLIT] %s %s: qty %s -> %s, SL %s -> %s, TP %s -> %s, HWM %s -> %s",
+		pos.Ticker, splitRatio(a.Ratio), before.Quantity, pos.Quantity, before.StopLoss, pos.StopLoss,
+		before.TakeProfit, pos.TakeProfit, before.HighWaterMark, pos.HighWaterMark)
+	w.recordAudit(pos.Ticker, auditAdjusted, "SYSTEM", decimal.Zero, fmt.Sprintf("split %s applied", splitRatio(a.Ratio)))
+	return fmt.Sprintf("✂️ *%s* split %s (ex %s) applied.\nQty: %s → %s | Entry: $%s → $%s\nSL: $%s → $%s | TP: $%s → $%s\nHWM: $%s → $%s",
+		pos.Ticker, splitRatio(a.Ratio), a.ExDate.Format("2006-01-02"),
+		before.Quantity.String(), pos.Quantity.String(), before.EntryPrice.StringFixed(2), pos.EntryPrice.StringFixed(2),
+		before.StopLoss.StringFixed(2), pos.StopLoss.StringFixed(2), before.TakeProfit.StringFixed(2), pos.TakeProfit.StringFixed(2),
+		before.HighWaterMark.StringFixed(2), pos.HighWaterMark.StringFixed(2))
+}
+
+// dividendNoticeLocked warns about an upcoming ex-dividend date: the price opens lower
+// by about the dividend, which can reach a tight stop. Caller holds w.mu.
+func (w *Watcher) dividendNoticeLocked(pos models.Position, a market.CorporateAction, ex string) string {
+	msg := fmt.Sprintf("💵 *%s* pays $%s/share (ex %s", pos.Ticker, a.Rate.String(), ex)
+	if !a.PayDate.IsZero() {
+		msg += ", paid " + a.PayDate.Format("2006-01-02")
+	}
+	msg += fmt.Sprintf("). About $%s for %s shares.", money.Cash(a.Rate.Mul(pos.Quantity)).StringFixed(2), pos.Quantity.String())
+	if src := market.FindLastPriceSource(w.provider); src != nil && pos.StopLoss.IsPositive() {
+		price, _, ok := src.LastPrice(pos.Ticker)
+		if !ok || !price.IsPositive() {
+			return msg
+		}
+		room := price.Sub(pos.StopLoss)
+		if room.LessThan(a.Rate.Mul(decimal.NewFromInt(2))) {
+			msg += fmt.Sprintf("\n⚠️ The stop ($%s) is within twice the dividend of the last price ($%s): the ex-date drop alone may trigger it.",
+				pos.StopLoss.StringFixed(2), price.StringFixed(2))
+		}
+	}
+	return msg
+}
+
+// pruneCorporateActionsLocked drops keys whose date is older than corpActionKeepDays.
+// Caller holds w.mu.
+func (w *Watcher) pruneCorporateActionsLocked(now time.Time) {
+	cutoff := now.AddDate(0, 0, -corpActionKeepDays).Format("2006-01-02")
+	var keep []string
+	for _, k := range w.state.CorporateActions {
+		if i := strings.LastIndex(k, ":"); i >= 0 && k[i+1:] < cutoff {
+			continue
+		}
+		keep = append(keep, k)
+	}
+	w.state.CorporateActions = keep
+}
+
+// splitRatio renders a ratio as "2:1" (forward) or "1:10" (reverse).
+func splitRatio(r decimal.Decimal) string {
+	if r.LessThan(decimal.NewFromInt(1)) && r.IsPositive() {
+		return "1:" + decimal.NewFromInt(1).Div(r).Round(4).String()
+	}
+	return r.Round(4).String() + ":1"
+}
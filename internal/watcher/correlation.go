@@ -0,0 +1,93 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+// correlationTo returns the Pearson correlation of two series' paired daily returns.
+func correlationTo(a, b map[string]float64) (float64, bool) {
+	xs, ys := pairedReturns(a, b)
+	if len(xs) < hedgeMinReturns {
+		return 0, false
+	}
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(ys))
+	var cov, vx, vy float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+		vx += (xs[i] - mx) * (xs[i] - mx)
+		vy += (ys[i] - my) * (ys[i] - my)
+	}
+	if vx == 0 || vy == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(vx*vy), true
+}
+
+// correlationWarning compares ticker's daily returns with each active position's and
+// with extra (the other buys of an AI batch). Returns a warning naming those at or
+// above CORRELATION_THRESHOLD, or "" (also when off or without enough history).
+func (w *Watcher) correlationWarning(ticker string, extra []string) string {
+	threshold := w.config.CorrelationThreshold
+	if threshold <= 0 {
+		return ""
+	}
+	seen := map[string]bool{ticker: true}
+	var peers []string
+	w.mu.RLock()
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && !seen[p.Ticker] {
+			seen[p.Ticker] = true
+			peers = append(peers, p.Ticker)
+		}
+	}
+	w.mu.RUnlock()
+	for _, t := range extra {
+		if !seen[t] {
+			seen[t] = true
+			peers = append(peers, t)
+		}
+	}
+	if len(peers) == 0 {
+		return ""
+	}
+
+	closes, err := w.closesByDate(ticker)
+	if err != nil {
+		log.Printf("Correlation: no bars for %s: %v", ticker, err)
+		return ""
+	}
+	type hit struct {
+		ticker string
+		r      float64
+	}
+	var hits []hit
+	for _, t := range peers {
+		other, err := w.closesByDate(t)
+		if err != nil {
+			continue
+		}
+		if r, ok := correlationTo(closes, other); ok && r >= threshold {
+			hits = append(hits, hit{t, r})
+		}
+	}
+	if len(hits) == 0 {
+		return ""
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].r > hits[j].r })
+	names := make([]string, len(hits))
+	for i, h := range hits {
+		names[i] = fmt.Sprintf("%s (%.2f)", h.ticker, h.r)
+	}
+	return fmt.Sprintf("🔗 %s moves with %s: daily-return correlation over %d sessions is at or above %.2f.",
+		ticker, strings.Join(names, ", "), hedgeHistoryDays, threshold)
+}
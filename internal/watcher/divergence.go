@@ -0,0 +1,245 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	divergenceReportDays = 7
+	divergenceListMax    = 5 // Unmatched fills listed per side
+)
+
+// divergenceMatch pairs a live fill with the paper fill of the same signal.
+type divergenceMatch struct {
+	live, paper alpaca.Order
+	bps         decimal.Decimal // Live vs paper fill price; > 0 = live filled worse
+	lag         time.Duration   // Live fill time - paper fill time
+}
+
+// accountPL is one account's P/L over the report window.
+type accountPL struct {
+	pl  decimal.Decimal
+	pct decimal.Decimal // pl / starting equity
+}
+
+func fillTime(o alpaca.Order) time.Time {
+	if o.FilledAt != nil {
+		return *o.FilledAt
+	}
+	return o.UpdatedAt
+}
+
+// matchFills pairs each live fill with the closest unused paper fill of the same symbol
+// and side within window. Both slices must be oldest first.
+func matchFills(live, paper []alpaca.Order, window time.Duration) ([]divergenceMatch, []alpaca.Order, []alpaca.Order) {
+	used := make([]bool, len(paper))
+	var matches []divergenceMatch
+	var liveOnly []alpaca.Order
+	for _, l := range live {
+		best := -1
+		var bestGap time.Duration
+		for i, p := range paper {
+			if used[i] || p.Symbol != l.Symbol || p.Side != l.Side {
+				continue
+			}
+			gap := fillTime(l).Sub(fillTime(p))
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap <= window && (best < 0 || gap < bestGap) {
+				best, bestGap = i, gap
+			}
+		}
+		if best < 0 {
+			liveOnly = append(liveOnly, l)
+			continue
+		}
+		used[best] = true
+		p := paper[best]
+		diff := l.FilledAvgPrice.Sub(*p.FilledAvgPrice)
+		if l.Side == alpaca.Sell {
+			diff = diff.Neg()
+		}
+		matches = append(matches, divergenceMatch{
+			live:  l,
+			paper: p,
+			bps:   diff.Div(*p.FilledAvgPrice).Mul(decimal.NewFromInt(10000)),
+			lag:   fillTime(l).Sub(fillTime(p)),
+		})
+	}
+	var paperOnly []alpaca.Order
+	for i, p := range paper {
+		if !used[i] {
+			paperOnly = append(paperOnly, p)
+		}
+	}
+	return matches, liveOnly, paperOnly
+}
+
+// windowPL sums the daily P/L of an account over the last days (deposits excluded).
+func windowPL(ctx context.Context, p *market.AlpacaProvider, days int) (accountPL, error) {
+	h, err := p.GetPortfolioHistory(ctx, fmt.Sprintf("%dD", days), "1D")
+	if err != nil {
+		return accountPL{}, err
+	}
+	var out accountPL
+	for _, v := range h.ProfitLoss {
+		out.pl = out.pl.Add(v)
+	}
+	for _, e := range h.Equity {
+		if e.IsPositive() {
+			out.pct = out.pl.Div(e).Mul(decimal.NewFromInt(100))
+			break
+		}
+	}
+	return out, nil
+}
+
+// divergenceReport compares paper and live fills and P/L over the last days.
+func (w *Watcher) divergenceReport(days int) string {
+	paperCreds, okPaper := w.alpacaCredentials(envPaper)
+	liveCreds, okLive := w.alpacaCredentials(envLive)
+	if !okPaper || !okLive {
+		return "⚠️ The divergence report needs both paper and live credentials (APCA_PAPER_* and APCA_LIVE_*)."
+	}
+	paper := market.NewAlpacaProviderFor(paperCreds)
+	live := market.NewAlpacaProviderFor(liveCreds)
+
+	since := w.clock.Now().AddDate(0, 0, -days)
+	paperFills, err := paper.FilledOrders(w.ctx, since)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read paper orders: %v", err)
+	}
+	liveFills, err := live.FilledOrders(w.ctx, since)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read live orders: %v", err)
+	}
+
+	window := time.Duration(w.config.DivergenceMatchMins) * time.Minute
+	matches, liveOnly, paperOnly := matchFills(liveFills, paperFills, window)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚖️ *PAPER VS LIVE (last %d days)*\nMatched fills: %d | Live only: %d | Paper only: %d\n",
+		days, len(matches), len(liveOnly), len(paperOnly)))
+
+	if len(matches) > 0 {
+		sumBps, cost, qtyRatio := decimal.Zero, decimal.Zero, decimal.Zero
+		var lag time.Duration
+		type symbolGap struct {
+			n    int
+			bps  decimal.Decimal
+			cost decimal.Decimal
+		}
+		bySymbol := map[string]*symbolGap{}
+		for _, m := range matches {
+			c := m.bps.Div(decimal.NewFromInt(10000)).Mul(*m.paper.FilledAvgPrice).Mul(m.live.FilledQty)
+			sumBps = sumBps.Add(m.bps)
+			cost = cost.Add(c)
+			qtyRatio = qtyRatio.Add(m.live.FilledQty.Div(m.paper.FilledQty))
+			lag += m.lag
+			g, ok := bySymbol[m.live.Symbol]
+			if !ok {
+				g = &symbolGap{}
+				bySymbol[m.live.Symbol] = g
+			}
+			g.n++
+			g.bps = g.bps.Add(m.bps)
+			g.cost = g.cost.Add(c)
+		}
+		n := decimal.NewFromInt(int64(len(matches)))
+		sb.WriteString(fmt.Sprintf("Avg fill gap: %s bps | Slippage cost: $%s\nAvg live lag: %s | Live/paper qty: %s\n(bps > 0 = live filled worse than paper)\n",
+			sumBps.Div(n).StringFixed(1), cost.StringFixed(2),
+			(lag / time.Duration(len(matches))).Round(time.Second), qtyRatio.Div(n).StringFixed(2)))
+
+		syms := make([]string, 0, len(bySymbol))
+		for s := range bySymbol {
+			syms = append(syms, s)
+		}
+		sort.Slice(syms, func(i, j int) bool { return bySymbol[syms[i]].cost.GreaterThan(bySymbol[syms[j]].cost) })
+		sb.WriteString("\n*By Symbol*\n")
+		for _, s := range syms {
+			g := bySymbol[s]
+			sb.WriteString(fmt.Sprintf("`%-9s n=%-3d avg %6s bps | $%s`\n",
+				s, g.n, g.bps.Div(decimal.NewFromInt(int64(g.n))).StringFixed(1), g.cost.StringFixed(2)))
+		}
+	}
+
+	writeUnmatched := func(title string, orders []alpaca.Order) {
+		if len(orders) == 0 {
+			return
+		}
+		sb.WriteString(fmt.Sprintf("\n*%s*\n", title))
+		for i, o := range orders {
+			if i == divergenceListMax {
+				sb.WriteString(fmt.Sprintf("… and %d more\n", len(orders)-i))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("• %s %s %s @ $%s (%s)\n", strings.ToUpper(string(o.Side)), o.Symbol,
+				o.FilledQty.String(), o.FilledAvgPrice.StringFixed(2), fillTime(o).In(config.CetLoc).Format("01-02 15:04")))
+		}
+	}
+	writeUnmatched("Live Only", liveOnly)
+	writeUnmatched("Paper Only", paperOnly)
+
+	paperPL, errPaper := windowPL(w.ctx, paper, days)
+	livePL, errLive := windowPL(w.ctx, live, days)
+	sb.WriteString("\n*P/L*\n")
+	if errPaper != nil || errLive != nil {
+		sb.WriteString("Unavailable (portfolio history error).\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Paper: %s (%s%%)\nLive:  %s (%s%%)\nGap:   %s pts\n",
+			plString(paperPL.pl), paperPL.pct.StringFixed(2), plString(livePL.pl), livePL.pct.StringFixed(2),
+			livePL.pct.Sub(paperPL.pct).StringFixed(2)))
+	}
+	sb.WriteString(fmt.Sprintf("\nFills match on symbol and side within %dm.", w.config.DivergenceMatchMins))
+	return sb.String()
+}
+
+// handleDivergenceCommand compares the paper and live accounts. /divergence [days]
+func (w *Watcher) handleDivergenceCommand(parts []string) string {
+	if w.alpacaProvider() == nil {
+		return fmt.Sprintf("⚠️ /divergence is only available with the Alpaca provider (current: %s).", w.config.MarketProvider)
+	}
+	days := divergenceReportDays
+	if len(parts) >= 2 {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(parts[1]), "d"))
+		if err != nil || n <= 0 {
+			return "Usage: /divergence [days]"
+		}
+		days = n
+	}
+	return w.divergenceReport(days)
+}
+
+// checkWeeklyDivergenceReport sends the paper/live comparison once every 7 days.
+func (w *Watcher) checkWeeklyDivergenceReport() {
+	if !w.config.DivergenceReportEnabled || w.alpacaProvider() == nil {
+		return
+	}
+	w.mu.RLock()
+	last := w.state.LastDivergenceReport
+	w.mu.RUnlock()
+
+	if t, err := time.Parse(time.RFC3339, last); err == nil && w.since(t) < divergenceReportDays*24*time.Hour {
+		return
+	}
+
+	w.mu.Lock()
+	w.state.LastDivergenceReport = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	// Both accounts are queried over the network: keep the poll loop moving
+	go func() { w.notifyRoutine(w.divergenceReport(divergenceReportDays)) }()
+}
@@ -0,0 +1,174 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/telegram"
+)
+
+// StateIssue is an impossible or inconsistent record found in the state file.
+type StateIssue struct {
+	Ticker  string
+	Problem string
+}
+
+// handleDoctorCommand reports state integrity issues.
+// /doctor      -> report only
+// /doctor fix  -> back up the state file, then auto-repair
+func (w *Watcher) handleDoctorCommand(parts []string) string {
+	fix := len(parts) > 1 && strings.ToLower(parts[1]) == "fix"
+
+	w.mu.RLock()
+	issues := validateState(w.state)
+	w.mu.RUnlock()
+
+	if len(issues) == 0 {
+		return "🩺 *DOCTOR*: State is healthy. No issues found."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🩺 *DOCTOR*: %d issue(s) found\n", len(issues)))
+	for _, i := range issues {
+		sb.WriteString(fmt.Sprintf("• %s: %s\n", i.Ticker, i.Problem))
+	}
+	if !fix {
+		sb.WriteString("\nRun `/doctor fix` to back up the state file and repair.")
+		return sb.String()
+	}
+
+	w.FlushState()
+	backup, err := storage.BackupState()
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\n❌ Backup failed (%v). Nothing was changed.", err))
+		return sb.String()
+	}
+
+	w.mu.Lock()
+	actions := w.repairStateLocked()
+	w.saveStateLocked()
+	remaining := validateState(w.state)
+	w.mu.Unlock()
+
+	log.Printf("Doctor: Repaired state (backup %s): %v", backup, actions)
+	sb.WriteString(fmt.Sprintf("\n💾 Backup: `%s`\n🔧 *Repairs*\n", backup))
+	for _, a := range actions {
+		sb.WriteString(fmt.Sprintf("• %s\n", a))
+	}
+	if len(remaining) > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️ %d issue(s) need manual attention.", len(remaining)))
+	} else {
+		sb.WriteString("✅ State is healthy.")
+	}
+	return sb.String()
+}
+
+// validateState detects impossible states: non-positive quantity or entry, SL >= TP,
+// duplicate ACTIVE tickers, and tickers with both ACTIVE and closed records.
+func validateState(s models.PortfolioState) []StateIssue {
+	var issues []StateIssue
+	active := make(map[string]int)
+	inactive := make(map[string]bool)
+
+	for _, p := range s.Positions {
+		if p.Status != "ACTIVE" {
+			inactive[p.Ticker] = true
+			continue
+		}
+		active[p.Ticker]++
+		if !p.Quantity.IsPositive() {
+			issues = append(issues, StateIssue{p.Ticker, fmt.Sprintf("non-positive quantity (%s)", p.Quantity.String())})
+		}
+		if !p.EntryPrice.IsPositive() {
+			issues = append(issues, StateIssue{p.Ticker, fmt.Sprintf("non-positive entry price (%s)", p.EntryPrice.String())})
+		}
+		if !p.StopLoss.IsZero() && !p.TakeProfit.IsZero() && !p.StopLoss.LessThan(p.TakeProfit) {
+			issues = append(issues, StateIssue{p.Ticker, fmt.Sprintf("SL $%s >= TP $%s", p.StopLoss.StringFixed(2), p.TakeProfit.StringFixed(2))})
+		}
+	}
+	for t, n := range active {
+		if n > 1 {
+			issues = append(issues, StateIssue{t, fmt.Sprintf("%d duplicate ACTIVE records", n)})
+		}
+		if inactive[t] {
+			issues = append(issues, StateIssue{t, "both ACTIVE and closed records"})
+		}
+	}
+	return issues
+}
+
+// repairStateLocked fixes what validateState finds. Caller holds w.mu.
+// - Drops ACTIVE records with non-positive quantity (the broker is the source of truth; /refresh re-imports).
+// - Keeps the first ACTIVE record per ticker and drops duplicates.
+// - Drops closed records that shadow an ACTIVE position.
+// - Resets inverted SL/TP to the defaults around the entry price.
+func (w *Watcher) repairStateLocked() []string {
+	var actions []string
+	activeSeen := make(map[string]bool)
+	hasActive := make(map[string]bool)
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && p.Quantity.IsPositive() {
+			hasActive[p.Ticker] = true
+		}
+	}
+
+	kept := make([]models.Position, 0, len(w.state.Positions))
+	for _, p := range w.state.Positions {
+		if p.Status != "ACTIVE" {
+			if hasActive[p.Ticker] {
+				actions = append(actions, fmt.Sprintf("%s: removed stale %s record", p.Ticker, p.Status))
+				continue
+			}
+			kept = append(kept, p)
+			continue
+		}
+		if !p.Quantity.IsPositive() {
+			actions = append(actions, fmt.Sprintf("%s: removed record with quantity %s", p.Ticker, p.Quantity.String()))
+			continue
+		}
+		if activeSeen[p.Ticker] {
+			actions = append(actions, fmt.Sprintf("%s: removed duplicate ACTIVE record", p.Ticker))
+			continue
+		}
+		activeSeen[p.Ticker] = true
+
+		if !p.StopLoss.IsZero() && !p.TakeProfit.IsZero() && !p.StopLoss.LessThan(p.TakeProfit) && p.EntryPrice.IsPositive() {
+			p.StopLoss, p.TakeProfit = w.defaultLevels(p.Ticker, p.EntryPrice)
+			actions = append(actions, fmt.Sprintf("%s: reset SL/TP to defaults ($%s / $%s)", p.Ticker, p.StopLoss.StringFixed(2), p.TakeProfit.StringFixed(2)))
+		}
+		kept = append(kept, p)
+	}
+	w.state.Positions = kept
+
+	if len(actions) == 0 {
+		actions = append(actions, "nothing auto-repairable")
+	}
+	return actions
+}
+
+// checkStateIntegrity runs the validator on every poll and alerts (max once per 24h) when issues exist.
+func (w *Watcher) checkStateIntegrity() {
+	w.mu.RLock()
+	issues := validateState(w.state)
+	w.mu.RUnlock()
+
+	if len(issues) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	last, ok := w.lastAlerts["STATE_INTEGRITY"]
+	if ok && w.since(last) < 24*time.Hour {
+		w.mu.Unlock()
+		return
+	}
+	w.lastAlerts["STATE_INTEGRITY"] = w.clock.Now()
+	w.mu.Unlock()
+
+	log.Printf("[STATE_INTEGRITY] %d issue(s) detected: %v", len(issues), issues)
+	telegram.Notify(fmt.Sprintf("🩺 *STATE INTEGRITY*: %d issue(s) detected. Run /doctor for details.", len(issues)))
+}
@@ -0,0 +1,130 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// downtimePollMultiple: a gap longer than this many poll intervals since the
+// last recorded sync counts as downtime.
+const downtimePollMultiple = 2
+
+// CheckDowntime reports what happened while the watcher was offline.
+// Call once at startup, before the first Poll (which overwrites LastSync).
+// It lists broker fills during the gap and the stops/targets that would have
+// triggered according to hourly bars, instead of resuming silently.
+func (w *Watcher) CheckDowntime() {
+	w.mu.RLock()
+	lastSync := w.state.LastSync
+	var active []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			active = append(active, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	since, err := time.Parse(time.RFC3339, lastSync)
+	if err != nil {
+		return // Fresh state: nothing to backfill
+	}
+	gap := w.since(since)
+	if gap <= time.Duration(downtimePollMultiple*w.config.PollIntervalMins)*time.Minute {
+		return
+	}
+	log.Printf("Downtime detected: last sync %s (%s ago). Backfilling...", lastSync, gap.Round(time.Minute))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔌 *DOWNTIME DETECTED*\nOffline for %s (last sync %s CET).\n",
+		formatHold(gap), since.In(config.CetLoc).Format("2006-01-02 15:04")))
+
+	// 1. Fills that happened while we were away
+	sb.WriteString("\n*Fills During Gap*\n")
+	orders, err := w.provider.ListOrders(w.ctx, "closed")
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("⚠️ Could not fetch order history: %v\n", err))
+	} else {
+		fills := 0
+		for _, o := range orders {
+			if o.FilledAt == nil || o.FilledAt.Before(since) {
+				continue
+			}
+			price := "?"
+			if o.FilledAvgPrice != nil {
+				price = o.FilledAvgPrice.StringFixed(2)
+			}
+			sb.WriteString(fmt.Sprintf("• %s %s %s %s @ $%s\n",
+				o.FilledAt.In(config.CetLoc).Format("01-02 15:04"), strings.ToUpper(string(o.Side)), o.FilledQty.String(), o.Symbol, price))
+			fills++
+		}
+		if fills == 0 {
+			sb.WriteString("None.\n")
+		}
+	}
+
+	// 2. Triggers that would have fired (hourly bars over the gap)
+	sb.WriteString("\n*Missed Triggers*\n")
+	missed := 0
+	for _, p := range active {
+		for _, m := range w.missedTriggers(p, since) {
+			sb.WriteString("• " + m + "\n")
+			missed++
+		}
+	}
+	if missed == 0 {
+		sb.WriteString("None.\n")
+	} else {
+		sb.WriteString("\nPositions are still ACTIVE locally; live checks resume with this poll.")
+	}
+
+	telegram.Notify(strings.TrimRight(sb.String(), "\n"))
+}
+
+// missedTriggers scans hourly bars since the given time for SL/TP/TS breaches.
+// Only the first breach per trigger is reported.
+func (w *Watcher) missedTriggers(pos models.Position, since time.Time) []string {
+	bars, err := w.provider.GetBarsRange(w.ctx, pos.Ticker, "1H", since, w.clock.Now())
+	if err != nil {
+		return []string{fmt.Sprintf("%s: bar history unavailable (%v)", pos.Ticker, err)}
+	}
+
+	var out []string
+	seen := map[string]bool{}
+	hwm := pos.HighWaterMark
+	report := func(trigger string, level decimal.Decimal, at time.Time, extreme float64) {
+		if seen[trigger] {
+			return
+		}
+		seen[trigger] = true
+		out = append(out, fmt.Sprintf("%s: %s $%s would have triggered at %s CET (bar extreme $%.2f)",
+			pos.Ticker, trigger, level.StringFixed(2), at.In(config.CetLoc).Format("01-02 15:04"), extreme))
+	}
+
+	for _, b := range bars {
+		low, high := decimal.NewFromFloat(b.Low), decimal.NewFromFloat(b.High)
+		if !pos.StopLoss.IsZero() && low.LessThanOrEqual(pos.StopLoss) {
+			report("SL", pos.StopLoss, b.Timestamp, b.Low)
+		}
+		if !pos.TakeProfit.IsZero() && high.GreaterThanOrEqual(pos.TakeProfit) {
+			report("TP", pos.TakeProfit, b.Timestamp, b.High)
+		}
+		if pos.TrailingStopPct.IsPositive() && hwm.IsPositive() {
+			trail := hwm.Mul(decimal.NewFromInt(100).Sub(pos.TrailingStopPct).Div(decimal.NewFromInt(100)))
+			if low.LessThanOrEqual(trail) {
+				report("TS", trail, b.Timestamp, b.Low)
+			}
+		}
+		if high.GreaterThan(hwm) {
+			hwm = high
+		}
+	}
+	return out
+}
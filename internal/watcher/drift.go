@@ -0,0 +1,126 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// driftReport is what a broker sync had to correct in local state.
+type driftReport struct {
+	discovered []string // Held at the broker, unknown locally (imported)
+	vanished   []string // Tracked locally, gone at the broker (archived)
+	qty        []string // "AAPL 10 → 12"
+}
+
+func (d driftReport) empty() bool {
+	return len(d.discovered) == 0 && len(d.vanished) == 0 && len(d.qty) == 0
+}
+
+// format summarizes the corrections, one line per kind.
+func (d driftReport) format() string {
+	var lines []string
+	if len(d.discovered) > 0 {
+		lines = append(lines, "➕ Unknown at broker, imported with default levels: "+strings.Join(d.discovered, ", "))
+	}
+	if len(d.vanished) > 0 {
+		lines = append(lines, "➖ Gone at broker, archived: "+strings.Join(d.vanished, ", "))
+	}
+	if len(d.qty) > 0 {
+		lines = append(lines, "🔢 Quantity corrected: "+strings.Join(d.qty, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diffPositions compares the active positions before and after a sync. pending lists
+// tickers with a tracked buy order: their appearance is a fill, not drift. Quantity
+// differences within tolerancePct (of the local quantity) are ignored.
+func diffPositions(before, after []models.Position, pending map[string]bool, tolerancePct float64) driftReport {
+	local := make(map[string]models.Position)
+	for _, p := range before {
+		if p.Status == "ACTIVE" {
+			local[p.Ticker] = p
+		}
+	}
+	tolerance := decimal.NewFromFloat(tolerancePct).Div(decimal.NewFromInt(100))
+
+	var d driftReport
+	seen := make(map[string]bool)
+	for _, p := range after {
+		if p.Status != "ACTIVE" {
+			continue
+		}
+		seen[p.Ticker] = true
+		old, ok := local[p.Ticker]
+		if !ok {
+			if !pending[p.Ticker] {
+				d.discovered = append(d.discovered, p.Ticker)
+			}
+			continue
+		}
+		diff := p.Quantity.Sub(old.Quantity).Abs()
+		if diff.IsPositive() && diff.GreaterThan(old.Quantity.Abs().Mul(tolerance)) {
+			d.qty = append(d.qty, fmt.Sprintf("%s %s → %s", p.Ticker, old.Quantity.String(), p.Quantity.String()))
+		}
+	}
+	for _, p := range before {
+		if p.Status == "ACTIVE" && !seen[p.Ticker] {
+			d.vanished = append(d.vanished, p.Ticker)
+		}
+	}
+	return d
+}
+
+// syncWithDrift runs SyncWithBroker and reports what it corrected.
+func (w *Watcher) syncWithDrift() (int, driftReport, error) {
+	// Orders whose placement timed out, before the sync would import them blind
+	w.resolveUnknownIntents()
+
+	w.mu.RLock()
+	before := append([]models.Position(nil), w.state.Positions...)
+	pending := make(map[string]bool)
+	for _, o := range w.state.PendingOrders {
+		pending[o.Ticker] = true
+	}
+	w.mu.RUnlock()
+
+	state, err := w.SyncWithBroker()
+	if err != nil {
+		return 0, driftReport{}, err
+	}
+	d := diffPositions(before, state.Positions, pending, w.config.DriftQtyTolerancePct)
+	if !d.empty() {
+		log.Printf("[DRIFT] %s", strings.ReplaceAll(d.format(), "\n", " | "))
+	}
+	return len(state.Positions), d, nil
+}
+
+// checkScheduledSync reconciles with the broker every SYNC_INTERVAL_MINS and alerts
+// when local state had drifted from it.
+func (w *Watcher) checkScheduledSync() {
+	if w.config.SyncIntervalMins <= 0 {
+		return
+	}
+	w.mu.Lock()
+	if w.since(w.lastScheduledSync) < time.Duration(w.config.SyncIntervalMins)*time.Minute {
+		w.mu.Unlock()
+		return
+	}
+	w.lastScheduledSync = w.clock.Now()
+	w.mu.Unlock()
+
+	_, d, err := w.syncWithDrift()
+	if err != nil {
+		log.Printf("Scheduled sync failed: %v", err)
+		return
+	}
+	if !d.empty() {
+		telegram.Notify("🧭 *STATE DRIFT CORRECTED*\nLocal state differed from the broker. The scheduled sync fixed:\n" + d.format())
+	}
+}
@@ -0,0 +1,268 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+const (
+	envPaper      = "paper"
+	envLive       = "live"
+	envConfirmTTL = 60 * time.Second // The final SWITCH TO LIVE button expires after this
+)
+
+// pendingEnvSwitch is a live switch that passed the account-number check and awaits the button.
+type pendingEnvSwitch struct {
+	id string
+	at time.Time
+}
+
+// alpacaProvider returns the Alpaca provider behind any wrappers (nil for other brokers).
+func (w *Watcher) alpacaProvider() *market.AlpacaProvider {
+	p := w.provider
+	for {
+		wrapper, ok := p.(interface{ Unwrap() market.MarketProvider })
+		if !ok {
+			break
+		}
+		p = wrapper.Unwrap()
+	}
+	a, _ := p.(*market.AlpacaProvider)
+	return a
+}
+
+// alpacaCredentials returns the configured credentials for env (ok=false if missing).
+func (w *Watcher) alpacaCredentials(env string) (market.AlpacaCredentials, bool) {
+	c := market.AlpacaCredentials{KeyID: w.config.AlpacaPaperKeyID, Secret: w.config.AlpacaPaperSecret, BaseURL: market.AlpacaPaperURL}
+	if env == envLive {
+		c = market.AlpacaCredentials{KeyID: w.config.AlpacaLiveKeyID, Secret: w.config.AlpacaLiveSecret, BaseURL: market.AlpacaLiveURL}
+	}
+	return c, c.KeyID != "" && c.Secret != ""
+}
+
+func (w *Watcher) currentEnv() string {
+	if w.isPaperTrading() {
+		return envPaper
+	}
+	return envLive
+}
+
+// handleEnvCommand shows or switches the Alpaca account the Watcher trades.
+// /env                   -> current account
+// /env paper             -> switch to paper (no confirmation needed)
+// /env live              -> show the live account and ask for its last 4 digits
+// /env live <last4>      -> final SWITCH TO LIVE button (60s)
+func (w *Watcher) handleEnvCommand(parts []string) string {
+	alp := w.alpacaProvider()
+	if alp == nil {
+		return fmt.Sprintf("⚠️ /env is only available with the Alpaca provider (current: %s).", w.config.MarketProvider)
+	}
+	if len(parts) < 2 {
+		return w.getEnvStatus()
+	}
+
+	target := strings.ToLower(parts[1])
+	if target != envPaper && target != envLive {
+		return "Usage: /env [paper | live]"
+	}
+	if target == w.currentEnv() {
+		return fmt.Sprintf("ℹ️ Already on the %s account.", strings.ToUpper(target))
+	}
+	creds, ok := w.alpacaCredentials(target)
+	if !ok {
+		return fmt.Sprintf("⚠️ No %s credentials configured (APCA_%s_KEY_ID / APCA_%s_SECRET_KEY).", target, strings.ToUpper(target), strings.ToUpper(target))
+	}
+	if msg := w.envSwitchBlocked(); msg != "" {
+		return msg
+	}
+
+	acct, err := probeAccount(w.ctx, creds)
+	if err != nil {
+		return fmt.Sprintf("❌ %s account check failed: %v", strings.ToUpper(target), err)
+	}
+
+	if target == envPaper {
+		return w.switchEnv(envPaper, creds)
+	}
+
+	last4 := accountSuffix(acct.AccountNumber)
+	if len(parts) < 3 {
+		return fmt.Sprintf("🚨 *SWITCH TO LIVE TRADING*\nAccount: %s | Equity: $%s | Buying Power: $%s\n\nOrders placed after the switch use *real money*. Local positions will be re-synced from the live account.\n\nTo continue, send `/env live <last 4 digits of the account number>`.",
+			acct.AccountNumber, acct.Equity.StringFixed(2), acct.BuyingPower.StringFixed(2))
+	}
+	if parts[2] != last4 {
+		log.Printf("[ENV] Live switch rejected: account suffix mismatch.")
+		return "❌ Account number does not match. Live switch aborted."
+	}
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingEnv = &pendingEnvSwitch{id: id, at: w.clock.Now()}
+	w.mu.Unlock()
+
+	telegram.SendInteractiveMessage(fmt.Sprintf("🚨 *FINAL CONFIRMATION*\nSwitch the Watcher to LIVE account %s (equity $%s)?\nThis button expires in %d seconds.",
+		acct.AccountNumber, acct.Equity.StringFixed(2), int(envConfirmTTL.Seconds())),
+		[]telegram.Button{
+			{Text: "🚨 SWITCH TO LIVE", CallbackData: "ENV_LIVE_" + id},
+			{Text: "❌ CANCEL", CallbackData: "ENV_CANCEL_" + id},
+		})
+	return ""
+}
+
+// handleEnvCallback completes a live switch (ENV_LIVE_<id>) or cancels it.
+func (w *Watcher) handleEnvCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid env callback data."
+	}
+
+	w.mu.Lock()
+	pending := w.pendingEnv
+	w.pendingEnv = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > envConfirmTTL {
+		return "⚠️ Live switch expired. Start again with /env live."
+	}
+	if parts[1] != "LIVE" {
+		return "❌ Live switch cancelled. Still on PAPER."
+	}
+	if msg := w.envSwitchBlocked(); msg != "" {
+		return msg
+	}
+	creds, ok := w.alpacaCredentials(envLive)
+	if !ok {
+		return "⚠️ Live credentials are no longer configured."
+	}
+	if _, err := probeAccount(w.ctx, creds); err != nil {
+		return fmt.Sprintf("❌ LIVE account check failed: %v", err)
+	}
+	return w.switchEnv(envLive, creds)
+}
+
+// envSwitchBlocked refuses a switch while confirmations for the current account are open:
+// a tap after the switch would trade on the other account.
+func (w *Watcher) envSwitchBlocked() string {
+	w.mu.RLock()
+	open := len(w.pendingActions) + len(w.pendingProposals)
+	w.mu.RUnlock()
+	if open > 0 {
+		return fmt.Sprintf("⚠️ %d trade confirmation(s) are still open. Resolve them or let them expire before switching accounts.", open)
+	}
+	return ""
+}
+
+// switchEnv points the provider at the new account and mirrors its positions into local
+// state. The account left behind is parked (see swapEnvLocked), not archived by the sync.
+func (w *Watcher) switchEnv(env string, creds market.AlpacaCredentials) string {
+	from := w.currentEnv()
+	w.alpacaProvider().Use(creds)
+
+	w.mu.Lock()
+	w.swapEnvLocked(from, env)
+	w.triggerStreaks = make(map[string]int)
+	w.triggerFirstSeen = make(map[string]time.Time)
+	w.lastAI = nil
+	w.mu.Unlock()
+
+	log.Printf("[ENV] Switched Alpaca account: %s -> %s", from, env)
+	count, _, err := w.syncState()
+	if err != nil {
+		telegram.Notify(fmt.Sprintf("🚨 Switched to %s but the position sync failed: %v\nRun /refresh before trading.", strings.ToUpper(env), err))
+		return ""
+	}
+	icon := "🧪"
+	if env == envLive {
+		icon = "🚨"
+	}
+	return fmt.Sprintf("%s Now trading on the *%s* account (%d active positions synced). Restarting returns to the APCA_API_* account.", icon, strings.ToUpper(env), count)
+}
+
+// swapEnvLocked parks the from account's positions, pending orders and risk marks (peak
+// equity, kill switch, daily loss breaker) in the state and restores the to account's.
+// An account used for the first time starts empty: its peak is seeded by the next
+// drawdown check. Caller holds w.mu.
+func (w *Watcher) swapEnvLocked(from, to string) {
+	if w.state.EnvSnapshots == nil {
+		w.state.EnvSnapshots = make(map[string]models.EnvSnapshot)
+	}
+	w.state.EnvSnapshots[from] = models.EnvSnapshot{
+		Positions:     w.state.Positions,
+		PendingOrders: w.state.PendingOrders,
+		PeakEquity:    w.state.PeakEquity,
+		DrawdownHalt:  w.state.DrawdownHalt,
+		DailyLossHalt: w.state.DailyLossHalt,
+	}
+	snap := w.state.EnvSnapshots[to]
+	delete(w.state.EnvSnapshots, to)
+	if snap.Positions == nil {
+		snap.Positions = []models.Position{}
+	}
+	w.state.Positions, w.state.PendingOrders = snap.Positions, snap.PendingOrders
+	w.state.PeakEquity, w.state.DrawdownHalt, w.state.DailyLossHalt = snap.PeakEquity, snap.DrawdownHalt, snap.DailyLossHalt
+	w.state.Env = to
+	w.saveStateLocked()
+}
+
+// alignEnvState runs at startup: a restart returns to the APCA_API_* account, so a state
+// saved on the other account (after /env) swaps back before the first sync.
+func (w *Watcher) alignEnvState() {
+	if w.alpacaProvider() == nil {
+		return
+	}
+	env := w.currentEnv()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state.Env {
+	case env:
+	case "":
+		w.state.Env = env
+		w.saveStateLocked()
+	default:
+		log.Printf("[ENV] State was saved on the %s account; restoring the %s account's positions", w.state.Env, env)
+		w.swapEnvLocked(w.state.Env, env)
+	}
+}
+
+func (w *Watcher) getEnvStatus() string {
+	acct, err := w.provider.GetAccount(w.ctx)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read account: %v", err)
+	}
+	configured := func(env string) string {
+		if _, ok := w.alpacaCredentials(env); ok {
+			return "configured"
+		}
+		return "not configured"
+	}
+	return fmt.Sprintf("🔐 *ENVIRONMENT*\nCurrent: *%s* (Account %s, Equity $%s)\nPaper credentials: %s\nLive credentials: %s\n\nSwitch with /env paper or /env live.",
+		strings.ToUpper(w.currentEnv()), acct.AccountNumber, acct.Equity.StringFixed(2), configured(envPaper), configured(envLive))
+}
+
+// probeAccount checks that credentials work and the account can trade.
+func probeAccount(ctx context.Context, creds market.AlpacaCredentials) (*alpaca.Account, error) {
+	acct, err := market.NewAlpacaProviderFor(creds).GetAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if acct.Status != "ACTIVE" || acct.TradingBlocked || acct.AccountBlocked {
+		return nil, fmt.Errorf("account %s cannot trade (status %s)", acct.AccountNumber, acct.Status)
+	}
+	return acct, nil
+}
+
+func accountSuffix(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
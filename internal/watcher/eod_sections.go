@@ -0,0 +1,194 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// EOD report sections (Settings.EODSections).
+const (
+	eodAccount    = "account"
+	eodAssets     = "assets"
+	eodActivity   = "activity"
+	eodChart      = "chart"
+	eodOptions    = "options"
+	eodSectors    = "sectors"
+	eodRisk       = "risk"
+	eodBenchmarks = "benchmarks"
+	eodAI         = "ai"
+)
+
+// eodSections lists every selectable EOD section.
+var eodSections = []string{eodAccount, eodAssets, eodActivity, eodChart, eodOptions, eodSectors, eodRisk, eodBenchmarks, eodAI}
+
+// defaultEODSections is the report as it was before sections were configurable
+// (plus options, which only render when option positions are monitored, and risk).
+// The chart and the AI commentary are opt-in (the latter costs an AI call per day).
+var defaultEODSections = []string{eodAccount, eodAssets, eodActivity, eodOptions, eodSectors, eodRisk, eodBenchmarks}
+
+const (
+	eodCommentaryPrompt = "eod_commentary.md"
+	eodChartWidth       = 24 // Sparkline points
+)
+
+// eodSectionsLocked returns the enabled sections in display order. Caller holds w.mu.
+// A nil slice means "never customized".
+func (w *Watcher) eodSectionsLocked() []string {
+	if w.state.Settings.EODSections == nil {
+		return defaultEODSections
+	}
+	return w.state.Settings.EODSections
+}
+
+// parseEODSections parses "account,assets,ai" into an ordered list without duplicates.
+func parseEODSections(arg string) ([]string, error) {
+	out := []string{}
+	for _, s := range strings.Split(strings.ToLower(arg), ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !contains(eodSections, s) {
+			return nil, fmt.Errorf("unknown EOD section %q (available: %s)", s, strings.Join(eodSections, ", "))
+		}
+		if !contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("at least one EOD section is required")
+	}
+	return out, nil
+}
+
+// handleEODSettings manages the EOD report layout.
+// /settings eod                      -> show sections and compact mode
+// /settings eod <s1,s2,...>          -> enable these sections, in this order
+// /settings eod compact <on|off>     -> short report on days without trades
+// /settings eod reset                -> default sections, compact off
+func (w *Watcher) handleEODSettings(parts []string) string {
+	usage := fmt.Sprintf("Usage: /settings eod [<%s> (comma-separated, in order) | compact <on|off> | reset]", strings.Join(eodSections, ","))
+	if len(parts) < 3 {
+		w.mu.RLock()
+		sections := w.eodSectionsLocked()
+		compact := w.state.Settings.EODCompact
+		w.mu.RUnlock()
+		return fmt.Sprintf("📊 EOD Sections: %s\nCompact on quiet days: %t\n\n%s", strings.Join(sections, " → "), compact, usage)
+	}
+
+	switch strings.ToLower(parts[2]) {
+	case "compact":
+		if len(parts) < 4 || (parts[3] != "on" && parts[3] != "off") {
+			return usage
+		}
+		w.mu.Lock()
+		w.state.Settings.EODCompact = parts[3] == "on"
+		w.saveStateLocked()
+		w.mu.Unlock()
+		if parts[3] == "off" {
+			return "✅ Compact EOD report off. The full report is sent every day."
+		}
+		return "✅ Compact EOD report on. Days without trades get the account summary only."
+	case "reset":
+		w.mu.Lock()
+		w.state.Settings.EODSections = nil
+		w.state.Settings.EODCompact = false
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return fmt.Sprintf("✅ EOD sections reset: %s", strings.Join(defaultEODSections, " → "))
+	}
+
+	sections, err := parseEODSections(strings.Join(parts[2:], ","))
+	if err != nil {
+		return fmt.Sprintf("⚠️ %v\n%s", err, usage)
+	}
+	w.mu.Lock()
+	w.state.Settings.EODSections = sections
+	w.saveStateLocked()
+	w.mu.Unlock()
+	msg := fmt.Sprintf("✅ EOD sections: %s", strings.Join(sections, " → "))
+	if contains(sections, eodAI) && w.config.GeminiAPIKey == "" {
+		msg += "\n⚠️ AI is not configured (GEMINI_API_KEY); the commentary will be skipped."
+	}
+	return msg
+}
+
+// sparkline renders values as a row of block characters, downsampled to width points.
+func sparkline(values []decimal.Decimal, width int) string {
+	if len(values) < 2 {
+		return ""
+	}
+	if len(values) > width {
+		sampled := make([]decimal.Decimal, width)
+		for i := range sampled {
+			sampled[i] = values[(i+1)*len(values)/width-1] // Last value of each bucket
+		}
+		values = sampled
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = decimal.Min(lo, v)
+		hi = decimal.Max(hi, v)
+	}
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	span := hi.Sub(lo)
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if span.IsPositive() {
+			idx = int(v.Sub(lo).Div(span).Mul(decimal.NewFromInt(int64(len(blocks) - 1))).Round(0).IntPart())
+		}
+		sb.WriteRune(blocks[idx])
+	}
+	return sb.String()
+}
+
+// formatEquityChart renders the intraday equity curve with its range.
+func (w *Watcher) formatEquityChart(equity []decimal.Decimal) string {
+	var points []decimal.Decimal
+	for _, e := range equity {
+		if e.IsPositive() { // Alpaca pads minutes without data with zeros
+			points = append(points, e)
+		}
+	}
+	line := sparkline(points, eodChartWidth)
+	if line == "" {
+		return ""
+	}
+	lo, hi := points[0], points[0]
+	for _, p := range points {
+		lo = decimal.Min(lo, p)
+		hi = decimal.Max(hi, p)
+	}
+	return fmt.Sprintf("*%s*\n`%s`\n%s: %s – %s", w.tr("Equity Curve"), line, w.tr("Range"), money.USD(lo), money.USD(hi))
+}
+
+// eodCommentary asks the AI to comment on the rendered report.
+// Returns "" when AI is off, over the daily limit or failing (the report goes out without it).
+func (w *Watcher) eodCommentary(report string) string {
+	if w.config.GeminiAPIKey == "" {
+		return ""
+	}
+	if !w.reserveAICall() {
+		log.Printf("EOD commentary skipped: daily AI call limit reached (%d).", w.config.AIDailyCallLimit)
+		return ""
+	}
+	sysInstr, err := os.ReadFile(eodCommentaryPrompt)
+	if err != nil {
+		log.Printf("EOD commentary: prompt missing: %v", err)
+		return ""
+	}
+	c, err := ai.NewClient().Commentary(string(sysInstr), report)
+	if err != nil {
+		log.Printf("EOD commentary failed: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("*%s*\n%s\n_Model: %s_", w.tr("AI Commentary"), strings.TrimSpace(c.Commentary), c.Model)
+}
@@ -0,0 +1,116 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/storage"
+
+	"github.com/shopspring/decimal"
+)
+
+// intradayEquity is today's (CET) equity curve, sampled every poll and persisted
+// to equity_log.jsonl, so intraday extremes survive restarts and do not depend on
+// the granularity of the broker's portfolio history.
+type intradayEquity struct {
+	day     string
+	samples []models.EquitySample
+}
+
+// intradayStats summarizes the curve.
+type intradayStats struct {
+	Open, High, Low, Last decimal.Decimal
+	HighAt, LowAt         time.Time
+	MaxDrawdownPct        decimal.Decimal // Largest peak-to-trough decline so far, in % (positive)
+	Samples               int
+}
+
+// equityTodayLocked returns today's curve, reloading it from the log on the first
+// use of a new day (or after a restart). Caller holds w.mu.
+func (w *Watcher) equityTodayLocked() *intradayEquity {
+	now := w.clock.Now().In(config.CetLoc)
+	day := now.Format("2006-01-02")
+	if w.equityToday != nil && w.equityToday.day == day {
+		return w.equityToday
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, config.CetLoc)
+	samples, err := storage.LoadEquity(start)
+	if err != nil {
+		log.Printf("Warning: Could not read %s: %v", storage.EquityFile, err)
+	}
+	w.equityToday = &intradayEquity{day: day, samples: samples}
+	return w.equityToday
+}
+
+// recordEquity adds a sample to today's curve and the log. Non-positive values
+// (failed or unfunded reads) are ignored.
+func (w *Watcher) recordEquity(equity decimal.Decimal) {
+	if !equity.IsPositive() {
+		return
+	}
+	s := models.EquitySample{Time: w.clock.Now(), Equity: money.Cash(equity)}
+	w.mu.Lock()
+	curve := w.equityTodayLocked()
+	curve.samples = append(curve.samples, s)
+	w.mu.Unlock()
+
+	if err := storage.AppendEquity(s); err != nil {
+		log.Printf("Warning: Could not append equity sample: %v", err)
+	}
+}
+
+// sampleEquity records the account equity once per poll.
+func (w *Watcher) sampleEquity() {
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil {
+		log.Printf("Equity sample skipped: %v", err)
+		return
+	}
+	w.recordEquity(equity)
+}
+
+// intradayEquityStats summarizes today's curve. ok is false with fewer than 2 samples.
+func (w *Watcher) intradayEquityStats() (intradayStats, []decimal.Decimal, bool) {
+	w.mu.Lock()
+	samples := append([]models.EquitySample(nil), w.equityTodayLocked().samples...)
+	w.mu.Unlock()
+
+	if len(samples) < 2 {
+		return intradayStats{}, nil, false
+	}
+	first := samples[0]
+	st := intradayStats{
+		Open: first.Equity, High: first.Equity, Low: first.Equity,
+		HighAt: first.Time, LowAt: first.Time, Samples: len(samples),
+	}
+	peak := first.Equity
+	curve := make([]decimal.Decimal, len(samples))
+	for i, s := range samples {
+		curve[i] = s.Equity
+		if s.Equity.GreaterThan(st.High) {
+			st.High, st.HighAt = s.Equity, s.Time
+		}
+		if s.Equity.LessThan(st.Low) {
+			st.Low, st.LowAt = s.Equity, s.Time
+		}
+		peak = decimal.Max(peak, s.Equity)
+		if dd := peak.Sub(s.Equity).Div(peak).Mul(decimal.NewFromInt(100)); dd.GreaterThan(st.MaxDrawdownPct) {
+			st.MaxDrawdownPct = dd
+		}
+	}
+	st.Last = samples[len(samples)-1].Equity
+	return st, curve, true
+}
+
+// formatIntradayEquity renders the high/low/drawdown line for /status and the EOD report.
+func (w *Watcher) formatIntradayEquity(st intradayStats) string {
+	return fmt.Sprintf("%s: %s %s (%s) | %s %s (%s) | %s -%s%%",
+		w.tr("Intraday"),
+		w.tr("High"), money.USD(st.High), st.HighAt.In(config.CetLoc).Format("15:04"),
+		w.tr("Low"), money.USD(st.Low), st.LowAt.In(config.CetLoc).Format("15:04"),
+		w.tr("Max DD"), st.MaxDrawdownPct.StringFixed(2))
+}
@@ -0,0 +1,227 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// State event types (see models.StateEvent).
+const (
+	eventOpened        = "position_opened"
+	eventClosed        = "position_closed"
+	eventSLUpdated     = "sl_updated"
+	eventTPUpdated     = "tp_updated"
+	eventTSUpdated     = "ts_updated"
+	eventHWMRaised     = "hwm_raised"
+	eventQtyChanged    = "qty_changed"
+	eventStatusChanged = "status_changed"
+)
+
+const eventsListLimit = 20
+
+// positionEvents diffs two position sets into the events that turn prev into next.
+func positionEvents(prev, next []models.Position, now time.Time, detail string) []models.StateEvent {
+	old := make(map[string]models.Position)
+	for _, p := range prev {
+		old[p.Ticker] = p
+	}
+	var events []models.StateEvent
+	add := func(typ string, p models.Position, from, to string) {
+		e := models.StateEvent{Time: now, Type: typ, Ticker: p.Ticker, Old: from, New: to, Detail: detail}
+		if typ != eventHWMRaised { // The most frequent change: the new value is enough
+			e.Position = &p
+		}
+		events = append(events, e)
+	}
+	seen := make(map[string]bool)
+	for _, p := range next {
+		seen[p.Ticker] = true
+		o, ok := old[p.Ticker]
+		if !ok {
+			add(eventOpened, p, "", p.Quantity.String())
+			continue
+		}
+		decimalField := func(typ string, a, b decimal.Decimal) {
+			if !a.Equal(b) {
+				add(typ, p, a.String(), b.String())
+			}
+		}
+		decimalField(eventQtyChanged, o.Quantity, p.Quantity)
+		decimalField(eventSLUpdated, o.StopLoss, p.StopLoss)
+		decimalField(eventTPUpdated, o.TakeProfit, p.TakeProfit)
+		decimalField(eventTSUpdated, o.TrailingStopPct, p.TrailingStopPct)
+		decimalField(eventHWMRaised, o.HighWaterMark, p.HighWaterMark)
+		if o.Status != p.Status {
+			add(eventStatusChanged, p, o.Status, p.Status)
+		}
+	}
+	for _, p := range prev {
+		if !seen[p.Ticker] {
+			add(eventClosed, p, p.Quantity.String(), "")
+		}
+	}
+	return events
+}
+
+// recordStateEventsLocked queues the position changes since the last save on the state
+// writer, which appends them off the lock with the state file. Caller holds w.mu.
+func (w *Watcher) recordStateEventsLocked() {
+	events := positionEvents(w.loggedPositions, w.state.Positions, w.clock.Now(), "")
+	if len(events) == 0 {
+		return
+	}
+	w.loggedPositions = append([]models.Position(nil), w.state.Positions...)
+	w.writer.Events(events, w.loggedPositions)
+}
+
+// initEventLog aligns the event log with the loaded state at startup. A state file
+// that failed to load is rebuilt from the log; otherwise any drift (first run,
+// manual edits) is recorded as "resync" events so the replay matches the file.
+func (w *Watcher) initEventLog(loadErr error) {
+	events, err := storage.LoadEvents(time.Time{})
+	if err != nil {
+		log.Printf("WARNING: Could not read state event log: %v", err)
+		w.loggedPositions = append([]models.Position(nil), w.state.Positions...)
+		return
+	}
+	replayed := storage.ReplayEvents(events)
+
+	if loadErr != nil && len(replayed) > 0 {
+		log.Printf("RECOVERY: Rebuilt %d positions from %s (%d events).", len(replayed), storage.EventFile, len(events))
+		w.state.Positions = replayed
+		w.loggedPositions = append([]models.Position(nil), replayed...)
+		return
+	}
+
+	w.loggedPositions = replayed
+	if drift := positionEvents(replayed, w.state.Positions, w.clock.Now(), "resync"); len(drift) > 0 {
+		log.Printf("State event log out of sync with %s: recording %d resync events.", storage.StateFile, len(drift))
+		if err := storage.AppendEvents(drift); err != nil {
+			log.Printf("ERROR: Failed to write resync events: %v", err)
+		}
+		w.loggedPositions = append([]models.Position(nil), w.state.Positions...)
+	}
+}
+
+// handleEventsCommand inspects the position event log.
+// /events [TICKER]            -> latest events
+// /events at YYYY-MM-DD [HH:MM] -> positions rebuilt at that CET time
+// /events verify              -> replay vs live state
+func (w *Watcher) handleEventsCommand(parts []string) string {
+	if len(parts) >= 2 {
+		switch strings.ToLower(parts[1]) {
+		case "at":
+			return w.eventsAt(parts[2:])
+		case "verify":
+			return w.verifyEvents()
+		}
+	}
+
+	ticker := ""
+	if len(parts) >= 2 {
+		ticker = symbols.Normalize(parts[1])
+	}
+	w.writer.Flush() // Queued events first
+	events, err := storage.LoadEvents(time.Time{})
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read event log: %v", err)
+	}
+	var selected []models.StateEvent
+	for _, e := range events {
+		if ticker == "" || e.Ticker == ticker {
+			selected = append(selected, e)
+		}
+	}
+	if len(selected) == 0 {
+		return "📒 No state events recorded."
+	}
+	if len(selected) > eventsListLimit {
+		selected = selected[len(selected)-eventsListLimit:]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📒 *STATE EVENTS* (last %d)\n", len(selected)))
+	for _, e := range selected {
+		line := fmt.Sprintf("• %s %s %s", e.Time.In(config.CetLoc).Format("01-02 15:04"), e.Ticker, e.Type)
+		if e.Old != "" || e.New != "" {
+			line += fmt.Sprintf(" %s → %s", orDash(e.Old), orDash(e.New))
+		}
+		if e.Detail != "" {
+			line += " (" + e.Detail + ")"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func (w *Watcher) eventsAt(args []string) string {
+	if len(args) == 0 {
+		return "Usage: /events at YYYY-MM-DD [HH:MM] (CET)"
+	}
+	stamp, layout := args[0]+" 23:59", "2006-01-02 15:04"
+	if len(args) >= 2 {
+		stamp = args[0] + " " + args[1]
+	}
+	at, err := time.ParseInLocation(layout, stamp, config.CetLoc)
+	if err != nil {
+		return "⚠️ Invalid time. Usage: /events at YYYY-MM-DD [HH:MM] (CET)"
+	}
+	w.writer.Flush()
+	events, err := storage.LoadEvents(at)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read event log: %v", err)
+	}
+	positions := storage.ReplayEvents(events)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⏪ *POSITIONS AT %s CET* (%d events)\n", at.Format("2006-01-02 15:04"), len(events)))
+	if len(positions) == 0 {
+		sb.WriteString("No open positions.")
+		return sb.String()
+	}
+	for _, p := range positions {
+		sb.WriteString(fmt.Sprintf("• *%s* %s @ $%s | SL $%s | TP $%s | HWM $%s | %s\n",
+			p.Ticker, p.Quantity.String(), p.EntryPrice.StringFixed(2), p.StopLoss.StringFixed(2),
+			p.TakeProfit.StringFixed(2), p.HighWaterMark.StringFixed(2), p.Status))
+	}
+	return sb.String()
+}
+
+func (w *Watcher) verifyEvents() string {
+	w.writer.Flush() // Queued events first
+	events, err := storage.LoadEvents(time.Time{})
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read event log: %v", err)
+	}
+	replayed := storage.ReplayEvents(events)
+
+	w.mu.RLock()
+	drift := positionEvents(replayed, w.state.Positions, w.clock.Now(), "")
+	w.mu.RUnlock()
+
+	if len(drift) == 0 {
+		return fmt.Sprintf("✅ Event log replay matches live state (%d events, %d positions).", len(events), len(replayed))
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⚠️ Replay differs from live state (%d differences):\n", len(drift)))
+	for _, e := range drift {
+		sb.WriteString(fmt.Sprintf("• %s %s %s → %s\n", e.Ticker, e.Type, orDash(e.Old), orDash(e.New)))
+	}
+	return sb.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
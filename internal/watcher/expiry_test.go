@@ -0,0 +1,115 @@
+package watcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"alpha_trading/internal/clock"
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+
+	"github.com/shopspring/decimal"
+)
+
+// newExpiryWatcher is a watcher with just enough state for the confirmation TTLs,
+// running on a fake clock.
+func newExpiryWatcher(ttlSec int) (*Watcher, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2025, 11, 26, 15, 0, 0, 0, time.UTC))
+	return &Watcher{
+		clock:          fake,
+		config:         &config.Config{ConfirmationTTLSec: ttlSec},
+		pendingActions: make(map[string]PendingAction),
+	}, fake
+}
+
+func TestTriggerConfirmationExpires(t *testing.T) {
+	w, fake := newExpiryWatcher(60)
+	w.pendingActions["AAPL"] = PendingAction{Ticker: "AAPL", Action: "SELL", Timestamp: fake.Now()}
+
+	fake.Advance(61 * time.Second)
+	got := w.HandleCallback("cb", "CONFIRM_SL_AAPL")
+	if !strings.HasPrefix(got, "⏳ TIMEOUT") {
+		t.Fatalf("confirm after the TTL: got %q, want a TIMEOUT", got)
+	}
+	if _, ok := w.pendingActions["AAPL"]; ok {
+		t.Fatal("expired action still pending")
+	}
+	if got := w.HandleCallback("cb", "CONFIRM_SL_AAPL"); !strings.Contains(got, "expired or not found") {
+		t.Fatalf("second tap: got %q", got)
+	}
+}
+
+// fixedPriceProvider quotes one price for every ticker; nothing else is implemented.
+type fixedPriceProvider struct {
+	market.MarketProvider
+	price decimal.Decimal
+}
+
+func (p fixedPriceProvider) GetPrice(context.Context, string) (decimal.Decimal, error) {
+	return p.price, nil
+}
+
+func TestStopAlertOutlivesTTLUntilLastReminder(t *testing.T) {
+	w, fake := newExpiryWatcher(60)
+	w.config.StopReAlertMins, w.config.StopReAlertMax = 5, 2
+	w.provider = fixedPriceProvider{price: decimal.NewFromInt(95)}
+	w.lastAlerts = make(map[string]time.Time)
+
+	alertedAt := fake.Now()
+	w.pendingActions["AAPL"] = PendingAction{Ticker: "AAPL", Action: "SELL", Trigger: "SL", TriggerPrice: decimal.NewFromInt(100),
+		Timestamp: alertedAt, ReAlerts: w.scheduleStopReAlert("AAPL", alertedAt, 1)}
+
+	// The poll cleanup runs past the TTL, before each reminder fires.
+	for attempt := 1; attempt <= 2; attempt++ {
+		fake.Advance(61 * time.Second)
+		w.expirePendingActionsLocked()
+		if _, ok := w.pendingActions["AAPL"]; !ok {
+			t.Fatalf("action dropped before reminder %d", attempt)
+		}
+		fake.Advance(5*time.Minute - 61*time.Second)
+		if got := w.pendingActions["AAPL"]; !got.Timestamp.Equal(fake.Now()) {
+			t.Fatalf("reminder %d did not re-arm the action (timestamp %v)", attempt, got.Timestamp)
+		}
+	}
+
+	fake.Advance(30 * time.Second)
+	w.expirePendingActionsLocked()
+	if _, ok := w.pendingActions["AAPL"]; !ok {
+		t.Fatal("last reminder's action dropped within its TTL")
+	}
+	fake.Advance(31 * time.Second)
+	w.expirePendingActionsLocked()
+	if _, ok := w.pendingActions["AAPL"]; ok {
+		t.Fatal("action still pending after the last reminder's TTL")
+	}
+}
+
+func TestPDTConfirmationTTL(t *testing.T) {
+	cases := []struct {
+		name string
+		wait time.Duration
+		data string
+		want string
+	}{
+		{"keep within TTL", pdtConfirmTTL - time.Second, "PDT_KEEP_1", "✅ Kept AAPL"},
+		{"keep at TTL", pdtConfirmTTL, "PDT_KEEP_1", "✅ Kept AAPL"},
+		{"keep after TTL", pdtConfirmTTL + time.Second, "PDT_KEEP_1", "⚠️ Confirmation expired"},
+		{"sell after TTL", 5 * time.Minute, "PDT_SELL_1", "⚠️ Confirmation expired"},
+		{"stale button", time.Second, "PDT_KEEP_0", "⚠️ Confirmation expired"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w, fake := newExpiryWatcher(60)
+			w.pendingPDT = &pendingPDTSell{id: "1", at: fake.Now(), ticker: "AAPL"}
+			fake.Advance(tc.wait)
+			if got := w.HandleCallback("cb", tc.data); !strings.HasPrefix(got, tc.want) {
+				t.Fatalf("got %q, want prefix %q", got, tc.want)
+			}
+			if w.pendingPDT != nil {
+				t.Fatal("held sell not cleared")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"fmt"
+	"time"
+
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// Extended session bounds (ET) for Alpaca pre-market and after-hours trading.
+const (
+	preMarketStart  = "04:00"
+	afterHoursClose = "20:00"
+)
+
+// inExtendedSession reports whether now falls in the pre-market (04:00 to open) or
+// after-hours (close to 20:00) window of a trading day in days. Half days carry
+// their early close, so after-hours starts at 13:00 on those.
+func inExtendedSession(days []alpaca.CalendarDay, now time.Time) bool {
+	for _, d := range days {
+		open, err1 := sessionOpen(d)
+		closeAt, err2 := sessionClose(d)
+		start, err3 := time.ParseInLocation("2006-01-02 15:04", d.Date+" "+preMarketStart, easternLoc())
+		end, err4 := time.ParseInLocation("2006-01-02 15:04", d.Date+" "+afterHoursClose, easternLoc())
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+			continue
+		}
+		if (!now.Before(start) && now.Before(open)) || (!now.Before(closeAt) && now.Before(end)) {
+			return true
+		}
+	}
+	return false
+}
+
+// extendedSessionActive reports whether equities can only trade extended-hours right now.
+func (w *Watcher) extendedSessionActive() bool {
+	clock, err := w.provider.GetClock(w.ctx)
+	if err != nil || clock.IsOpen {
+		return false
+	}
+	now := w.clock.Now()
+	days, err := w.provider.GetCalendar(w.ctx, now, now)
+	if err != nil {
+		return false
+	}
+	return inExtendedSession(days, now)
+}
+
+// extendedLimitOrder builds extended-hours limit options from a fresh quote: buys
+// pay up to ask + EXT_HOURS_LIMIT_PCT, sells accept down to bid - EXT_HOURS_LIMIT_PCT.
+// fallback is used when the quote side is empty (thin pre/post-market books).
+func (w *Watcher) extendedLimitOrder(ticker, side string, fallback decimal.Decimal) (market.OrderOptions, error) {
+	ref := fallback
+	if q, err := w.provider.GetQuote(w.ctx, ticker); err == nil && q != nil {
+		if side == "buy" && q.AskPrice > 0 {
+			ref = decimal.NewFromFloat(q.AskPrice)
+		} else if side == "sell" && q.BidPrice > 0 {
+			ref = decimal.NewFromFloat(q.BidPrice)
+		}
+	}
+	if !ref.IsPositive() {
+		return market.OrderOptions{}, fmt.Errorf("no reference price for %s", ticker)
+	}
+	buffer := decimal.NewFromFloat(w.config.ExtHoursLimitPct).Div(decimal.NewFromInt(100))
+	if side == "sell" {
+		buffer = buffer.Neg()
+	}
+	limit := money.OrderPrice(ticker, side, ref.Mul(decimal.NewFromInt(1).Add(buffer)))
+	return market.OrderOptions{LimitPrice: limit, ExtendedHours: true}, nil
+}
+
+// sellOrderOptions returns extended-hours limit options when an equity exit happens
+// outside the regular session but inside pre/post-market; otherwise a market order.
+func (w *Watcher) sellOrderOptions(ticker string, price decimal.Decimal) []market.OrderOptions {
+	if symbols.IsCrypto(ticker) || !w.extendedSessionActive() {
+		return nil
+	}
+	opts, err := w.extendedLimitOrder(ticker, "sell", price)
+	if err != nil {
+		return nil
+	}
+	return []market.OrderOptions{opts}
+}
+
+// orderKind describes a placed order for confirmation messages.
+func orderKind(o *alpaca.Order) string {
+	if o != nil && o.LimitPrice != nil {
+		kind := "Limit $" + o.LimitPrice.String()
+		if o.ExtendedHours {
+			kind += " (extended hours)"
+		}
+		return kind
+	}
+	return "Market"
+}
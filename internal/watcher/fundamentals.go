@@ -0,0 +1,197 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	fundamentalsBarsLookback = 365 * 24 * time.Hour // 52-week range from daily bars
+	fundamentalsVolumeBars   = 63                   // ~3 months of sessions for the average volume
+)
+
+// fundEntry caches a ticker's fundamentals.
+type fundEntry struct {
+	at   time.Time
+	data market.Fundamentals
+}
+
+// loadFundamentalsSource builds the FUNDAMENTALS_PROVIDER source. Without one, only
+// the fields derived from daily bars (52-week range, average volume) are available.
+func loadFundamentalsSource(name string) market.FundamentalsSource {
+	if name == "" {
+		return nil
+	}
+	src, err := market.NewFundamentalsSource(name)
+	if err != nil {
+		log.Printf("Warning: Fundamentals provider not loaded: %v", err)
+		return nil
+	}
+	log.Printf("Fundamentals Provider: %s", src.Name())
+	return src
+}
+
+// fundamentalsFor returns a ticker's fundamentals, cached for FUNDAMENTALS_CACHE_HOURS.
+// Market cap and P/E come from the fundamentals source (equities only); the 52-week
+// range and average volume fall back to a year of daily bars when it has none.
+func (w *Watcher) fundamentalsFor(ticker string) (market.Fundamentals, error) {
+	ttl := time.Duration(w.config.FundamentalsCacheHours) * time.Hour
+	w.mu.RLock()
+	e, ok := w.fundCache[ticker]
+	w.mu.RUnlock()
+	if ok && w.since(e.at) < ttl {
+		return e.data, nil
+	}
+
+	var fd market.Fundamentals
+	var srcErr error
+	if w.fundamentals != nil && !symbols.IsCrypto(ticker) {
+		var f *market.Fundamentals
+		if f, srcErr = w.fundamentals.GetFundamentals(w.ctx, ticker); srcErr == nil {
+			fd = *f
+		} else {
+			log.Printf("Fundamentals Warning: %s from %s: %v", ticker, w.fundamentals.Name(), srcErr)
+		}
+	}
+	if fd.AvgVolume == 0 || fd.High52w == 0 || fd.Low52w == 0 {
+		bars, err := w.provider.GetBarsRange(w.ctx, ticker, "1D", w.clock.Now().Add(-fundamentalsBarsLookback), time.Time{})
+		if err != nil {
+			if srcErr != nil || fd == (market.Fundamentals{}) {
+				return fd, err
+			}
+		} else {
+			fillFromBars(&fd, bars)
+		}
+	}
+
+	w.mu.Lock()
+	w.fundCache[ticker] = fundEntry{at: w.clock.Now(), data: fd}
+	w.mu.Unlock()
+	return fd, nil
+}
+
+// fillFromBars sets the bar-derived fields the source left empty.
+func fillFromBars(fd *market.Fundamentals, bars []marketdata.Bar) {
+	if len(bars) == 0 {
+		return
+	}
+	high, low := bars[0].High, bars[0].Low
+	for _, b := range bars {
+		high = max(high, b.High)
+		low = min(low, b.Low)
+	}
+	if fd.High52w == 0 {
+		fd.High52w = high
+	}
+	if fd.Low52w == 0 {
+		fd.Low52w = low
+	}
+	if fd.AvgVolume == 0 {
+		recent := bars[max(0, len(bars)-fundamentalsVolumeBars):]
+		total := 0.0
+		for _, b := range recent {
+			total += float64(b.Volume)
+		}
+		fd.AvgVolume = total / float64(len(recent))
+	}
+}
+
+// compactNumber formats large values as 1.23T / 4.56B / 7.89M / 12.3K.
+func compactNumber(v float64) string {
+	switch {
+	case v >= 1e12:
+		return fmt.Sprintf("%.2fT", v/1e12)
+	case v >= 1e9:
+		return fmt.Sprintf("%.2fB", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.2fM", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1fK", v/1e3)
+	}
+	return fmt.Sprintf("%.0f", v)
+}
+
+// handleInfoCommand shows a ticker's classification and fundamentals. /info <ticker>
+func (w *Watcher) handleInfoCommand(parts []string) string {
+	if len(parts) < 2 {
+		return "Usage: /info <ticker>"
+	}
+	ticker := symbols.Normalize(parts[1])
+	fd, err := w.fundamentalsFor(ticker)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not fetch data for %s: %v", ticker, err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("ℹ️ *%s*", ticker))
+	if a, ok := w.metadata.Lookup(ticker); ok {
+		if a.Name != "" {
+			sb.WriteString(" — " + a.Name)
+		}
+		if a.Sector != "" {
+			sb.WriteString(fmt.Sprintf("\n%s / %s", a.Sector, a.Industry))
+		}
+	}
+	sb.WriteString("\n")
+
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err == nil && price.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Price: %s\n", money.USD(price)))
+	}
+	na := func(v float64, f func(float64) string) string {
+		if v == 0 {
+			return "n/a"
+		}
+		return f(v)
+	}
+	sb.WriteString(fmt.Sprintf("Market Cap: %s\n", na(fd.MarketCap, func(v float64) string { return "$" + compactNumber(v) })))
+	sb.WriteString(fmt.Sprintf("P/E (TTM): %s\n", na(fd.PE, func(v float64) string { return fmt.Sprintf("%.1f", v) })))
+	sb.WriteString(fmt.Sprintf("Avg Volume: %s\n", na(fd.AvgVolume, compactNumber)))
+	if fd.High52w > 0 && fd.Low52w > 0 {
+		sb.WriteString(fmt.Sprintf("52W Range: %s – %s\n", money.USD(decimal.NewFromFloat(fd.Low52w)), money.USD(decimal.NewFromFloat(fd.High52w))))
+		if p := price.InexactFloat64(); p > 0 {
+			sb.WriteString(fmt.Sprintf("From High: %.1f%% | From Low: +%.1f%%\n", (p/fd.High52w-1)*100, (p/fd.Low52w-1)*100))
+		}
+	}
+	if w.fundamentals == nil && !symbols.IsCrypto(ticker) {
+		sb.WriteString("\n⚠️ Market cap and P/E need a fundamentals source (FUNDAMENTALS_PROVIDER).")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// snapshotFundamentals returns the fundamentals of the held tickers and the focus
+// ticker (AI_FUNDAMENTALS_ENABLED). Tickers whose data cannot be fetched are left out.
+func (w *Watcher) snapshotFundamentals(focus string) map[string]ai.Fundamentals {
+	if !w.config.AIFundamentalsEnabled {
+		return nil
+	}
+	out := make(map[string]ai.Fundamentals)
+	for _, t := range w.snapshotTickers(focus) {
+		fd, err := w.fundamentalsFor(t)
+		if err != nil {
+			log.Printf("Snapshot Warning: fundamentals for %s: %v", t, err)
+			continue
+		}
+		out[t] = ai.Fundamentals{
+			MarketCap: fd.MarketCap,
+			PE:        fd.PE,
+			AvgVolume: fd.AvgVolume,
+			High52w:   fd.High52w,
+			Low52w:    fd.Low52w,
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -0,0 +1,367 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	goalEquity   = "equity"
+	goalDrawdown = "drawdown"
+
+	goalTrendDays  = 30 // Recent window the trajectory is projected from
+	goalReportDays = 7
+	goalBarWidth   = 10
+)
+
+// goalMarket is what every goal is measured against, fetched once per evaluation.
+type goalMarket struct {
+	equity     decimal.Decimal
+	dailyRate  float64 // Recent trading return per calendar day (flows excluded)
+	trendOK    bool
+	drawdowns  map[int]goalDrawdownStats // Per goal index (drawdown goals only)
+	historyErr error
+}
+
+type goalDrawdownStats struct {
+	current decimal.Decimal // % below the peak since the goal was set
+	max     decimal.Decimal // Worst % since the goal was set
+}
+
+// progressBar renders a fraction (0..1) as "▓▓▓▓░░░░░░".
+func progressBar(frac float64) string {
+	frac = math.Max(0, math.Min(1, frac))
+	filled := int(math.Round(frac * goalBarWidth))
+	return strings.Repeat("▓", filled) + strings.Repeat("░", goalBarWidth-filled)
+}
+
+// parseGoalDeadline accepts YYYY-MM-DD, YYYY-MM (end of month) or a month name (end of
+// its next occurrence, this month included).
+func parseGoalDeadline(s string, now time.Time) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if t, err := time.ParseInLocation("2006-01-02", s, config.CetLoc); err == nil {
+		return t.Format("2006-01-02"), nil
+	}
+	if t, err := time.ParseInLocation("2006-01", s, config.CetLoc); err == nil {
+		return t.AddDate(0, 1, -1).Format("2006-01-02"), nil
+	}
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			year := now.Year()
+			if m < now.Month() {
+				year++
+			}
+			return time.Date(year, m+1, 0, 0, 0, 0, 0, config.CetLoc).Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("invalid deadline %q (use YYYY-MM-DD, YYYY-MM or a month name)", s)
+}
+
+// handleGoalCommand manages goals.
+// /goal                                   -> progress of every goal
+// /goal equity <amount> [by] [deadline]   -> reach an equity level (deadline optional)
+// /goal drawdown <pct>                    -> keep drawdown under pct
+// /goal remove <n>                        -> delete goal n (as numbered in /goal)
+func (w *Watcher) handleGoalCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.goalsReport()
+	}
+	usage := "Usage: /goal [equity <amount> [by <deadline>] | drawdown <pct> | remove <n>]"
+
+	switch strings.ToLower(parts[1]) {
+	case goalEquity:
+		if len(parts) < 3 {
+			return usage
+		}
+		target, err := decimal.NewFromString(strings.NewReplacer("$", "", ",", "").Replace(parts[2]))
+		if err != nil || !target.IsPositive() {
+			return "⚠️ Invalid equity target."
+		}
+		deadline := ""
+		if rest := parts[3:]; len(rest) > 0 {
+			if strings.EqualFold(rest[0], "by") {
+				rest = rest[1:]
+			}
+			if len(rest) > 0 {
+				if deadline, err = parseGoalDeadline(strings.Join(rest, " "), w.clock.Now().In(config.CetLoc)); err != nil {
+					return "⚠️ " + err.Error()
+				}
+			}
+		}
+		equity, err := w.provider.GetEquity(w.ctx)
+		if err != nil {
+			return fmt.Sprintf("❌ Could not read equity: %v", err)
+		}
+		return w.addGoal(models.Goal{Kind: goalEquity, Target: target, Deadline: deadline, StartEquity: equity, CreatedAt: w.clock.Now()})
+	case goalDrawdown, "dd":
+		if len(parts) < 3 {
+			return usage
+		}
+		pct, err := decimal.NewFromString(strings.TrimSuffix(parts[2], "%"))
+		if err != nil || !pct.IsPositive() || pct.GreaterThanOrEqual(decimal.NewFromInt(100)) {
+			return "⚠️ Invalid drawdown limit (0-100%)."
+		}
+		equity, _ := w.provider.GetEquity(w.ctx)
+		return w.addGoal(models.Goal{Kind: goalDrawdown, Target: pct, StartEquity: equity, CreatedAt: w.clock.Now()})
+	case "remove", "rm":
+		if len(parts) < 3 {
+			return usage
+		}
+		n, err := strconv.Atoi(parts[2])
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if err != nil || n < 1 || n > len(w.state.Goals) {
+			return fmt.Sprintf("⚠️ No goal #%s. See /goal.", parts[2])
+		}
+		removed := w.state.Goals[n-1]
+		w.state.Goals = append(w.state.Goals[:n-1], w.state.Goals[n:]...)
+		w.saveStateLocked()
+		return fmt.Sprintf("🗑️ Removed goal: %s", goalTitle(removed))
+	default:
+		return usage
+	}
+}
+
+func (w *Watcher) addGoal(g models.Goal) string {
+	w.mu.Lock()
+	w.state.Goals = append(w.state.Goals, g)
+	w.saveStateLocked()
+	w.mu.Unlock()
+	return fmt.Sprintf("🎯 Goal set: %s\n\n%s", goalTitle(g), w.goalsReport())
+}
+
+// goalTitle describes a goal in one line.
+func goalTitle(g models.Goal) string {
+	if g.Kind == goalDrawdown {
+		return fmt.Sprintf("max %s%% drawdown", g.Target.String())
+	}
+	title := fmt.Sprintf("reach $%s equity", g.Target.StringFixed(0))
+	if g.Deadline != "" {
+		title += " by " + g.Deadline
+	}
+	return title
+}
+
+// goalMarketData fetches the equity and the daily history the goals are measured on.
+// Without history (non-Alpaca providers) only the equity is available.
+func (w *Watcher) goalMarketData(goals []models.Goal) (goalMarket, error) {
+	var m goalMarket
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil {
+		return m, err
+	}
+	m.equity = equity
+
+	now := w.clock.Now()
+	since := now.AddDate(0, 0, -goalTrendDays)
+	for _, g := range goals {
+		if g.Kind == goalDrawdown && g.CreatedAt.Before(since) {
+			since = g.CreatedAt
+		}
+	}
+	h, err := w.provider.GetPortfolioHistory(w.ctx, fmt.Sprintf("%dD", int(now.Sub(since).Hours()/24)+5), "1D")
+	if err != nil {
+		m.historyErr = err
+		return m, nil
+	}
+
+	// Trajectory: recent flow-neutral return spread over calendar days
+	recent := equityPoints(h, now.AddDate(0, 0, -goalTrendDays), now.Add(time.Hour))
+	if len(recent) >= 2 {
+		span := recent[len(recent)-1].at.Sub(recent[0].at).Hours() / 24
+		growth := 1 + timeWeightedReturn(recent).InexactFloat64()/100
+		if span > 0 && growth > 0 {
+			m.dailyRate = math.Pow(growth, 1/span) - 1
+			m.trendOK = true
+		}
+	}
+
+	// Drawdown on a P/L-only index, so deposits and withdrawals neither create nor hide one
+	m.drawdowns = make(map[int]goalDrawdownStats)
+	for i, g := range goals {
+		if g.Kind != goalDrawdown {
+			continue
+		}
+		points := equityPoints(h, g.CreatedAt, now.Add(time.Hour))
+		index, peak := 1.0, 1.0
+		var st goalDrawdownStats
+		for j := 1; j < len(points); j++ {
+			if prev := points[j-1].equity; prev.IsPositive() {
+				index *= 1 + points[j].pl.Div(prev).InexactFloat64()
+			}
+			peak = math.Max(peak, index)
+			dd := decimal.NewFromFloat((peak - index) / peak * 100)
+			if dd.GreaterThan(st.max) {
+				st.max = dd
+			}
+			st.current = dd
+		}
+		m.drawdowns[i] = st
+	}
+	return m, nil
+}
+
+// goalLines renders one goal's progress and returns the status it has reached ("" if
+// still open).
+func goalLines(g models.Goal, idx int, m goalMarket, now time.Time) (string, string) {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%d. %s*", idx+1, goalTitle(g)))
+
+	if g.Kind == goalDrawdown {
+		st, ok := m.drawdowns[idx]
+		if !ok {
+			sb.WriteString("\nDrawdown unavailable (needs the broker's equity history).")
+			return sb.String(), ""
+		}
+		limit := g.Target.InexactFloat64()
+		sb.WriteString(fmt.Sprintf("\n%s %s%% of %s%% used | worst %s%%",
+			progressBar(st.current.InexactFloat64()/limit), st.current.StringFixed(1), g.Target.String(), st.max.StringFixed(1)))
+		if st.max.GreaterThanOrEqual(g.Target) {
+			sb.WriteString("\n🚨 Limit breached.")
+			return sb.String(), "breached"
+		}
+		return sb.String(), ""
+	}
+
+	eq, target := m.equity.InexactFloat64(), g.Target.InexactFloat64()
+	start := g.StartEquity.InexactFloat64()
+	frac := 1.0
+	if target > start {
+		frac = (eq - start) / (target - start)
+	}
+	sb.WriteString(fmt.Sprintf("\n%s %.0f%% | $%s of $%s", progressBar(frac), math.Max(0, math.Min(1, frac))*100,
+		m.equity.StringFixed(2), g.Target.StringFixed(2)))
+	if eq >= target {
+		sb.WriteString("\n✅ Reached.")
+		return sb.String(), "reached"
+	}
+
+	daysLeft := -1.0
+	if g.Deadline != "" {
+		if d, err := time.ParseInLocation("2006-01-02", g.Deadline, config.CetLoc); err == nil {
+			daysLeft = d.AddDate(0, 0, 1).Sub(now).Hours() / 24
+		}
+	}
+	if g.Deadline != "" && daysLeft <= 0 {
+		sb.WriteString("\n⌛ Deadline passed.")
+		return sb.String(), "missed"
+	}
+
+	if !m.trendOK {
+		sb.WriteString("\nTrajectory unavailable (no recent equity history).")
+		return sb.String(), ""
+	}
+	monthly := (math.Pow(1+m.dailyRate, 30) - 1) * 100
+	sb.WriteString(fmt.Sprintf("\nLast %dd pace: %+.2f%%/month", goalTrendDays, monthly))
+	if daysLeft > 0 {
+		projected := eq * math.Pow(1+m.dailyRate, daysLeft)
+		needed := (math.Pow(target/eq, 30/daysLeft) - 1) * 100
+		verdict := "✅ on track"
+		if projected < target {
+			verdict = "⚠️ behind"
+		}
+		sb.WriteString(fmt.Sprintf("\nProjected by %s: $%.2f (%s) | needs %+.2f%%/month", g.Deadline, projected, verdict, needed))
+	} else if m.dailyRate > 0 {
+		eta := now.AddDate(0, 0, int(math.Ceil(math.Log(target/eq)/math.Log(1+m.dailyRate))))
+		sb.WriteString(fmt.Sprintf("\nAt this pace: reached around %s", eta.In(config.CetLoc).Format("2006-01-02")))
+	} else {
+		sb.WriteString("\nAt this pace: not reached (no gains in the window)")
+	}
+	return sb.String(), ""
+}
+
+// goalsReport shows the progress of every goal.
+func (w *Watcher) goalsReport() string {
+	w.mu.RLock()
+	goals := append([]models.Goal(nil), w.state.Goals...)
+	w.mu.RUnlock()
+	if len(goals) == 0 {
+		return "🎯 No goals set. Example: /goal equity 5000 by december | /goal drawdown 10"
+	}
+	m, err := w.goalMarketData(goals)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read equity: %v", err)
+	}
+
+	now := w.clock.Now()
+	var sections []string
+	for i, g := range goals {
+		lines, _ := goalLines(g, i, m, now)
+		sections = append(sections, lines)
+	}
+	out := "🎯 *GOALS*\n\n" + strings.Join(sections, "\n\n")
+	if m.historyErr != nil {
+		out += "\n\n_Equity history unavailable: projections and drawdown are not shown._"
+	} else {
+		out += "\n\n_Pace and drawdown use trading P/L only: deposits and withdrawals are excluded._"
+	}
+	return out
+}
+
+// checkGoals evaluates the goals once per CET day, alerting the first time one is reached,
+// breached or missed, and sends the full progress report every goalReportDays.
+func (w *Watcher) checkGoals() {
+	now := w.clock.Now()
+	today := now.In(config.CetLoc).Format("2006-01-02")
+
+	w.mu.Lock()
+	if len(w.state.Goals) == 0 || w.state.LastGoalCheck == today {
+		w.mu.Unlock()
+		return
+	}
+	w.state.LastGoalCheck = today
+	goals := append([]models.Goal(nil), w.state.Goals...)
+	weekly := true
+	if t, err := time.Parse(time.RFC3339, w.state.LastGoalReport); err == nil && now.Sub(t) < goalReportDays*24*time.Hour {
+		weekly = false
+	}
+	if weekly {
+		w.state.LastGoalReport = now.In(config.CetLoc).Format(time.RFC3339)
+	}
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	m, err := w.goalMarketData(goals)
+	if err != nil {
+		log.Printf("Goal check failed: %v", err)
+		return
+	}
+
+	var alerts []string
+	w.mu.Lock()
+	for i, g := range goals {
+		lines, status := goalLines(g, i, m, now)
+		if status == "" || status == g.Status {
+			continue
+		}
+		// Match by creation time: the slice may have changed while the lock was released
+		for j := range w.state.Goals {
+			if w.state.Goals[j].CreatedAt.Equal(g.CreatedAt) && w.state.Goals[j].Kind == g.Kind {
+				w.state.Goals[j].Status = status
+			}
+		}
+		alerts = append(alerts, lines)
+	}
+	if len(alerts) > 0 {
+		w.saveStateLocked()
+	}
+	w.mu.Unlock()
+
+	if len(alerts) > 0 {
+		telegram.Notify("🎯 *GOAL UPDATE*\n\n" + strings.Join(alerts, "\n\n"))
+	}
+	if weekly {
+		w.notifyRoutine(w.goalsReport())
+	}
+}
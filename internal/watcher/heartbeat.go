@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/logger"
+	"alpha_trading/internal/models"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// reserveAICall counts an AI call against AI_DAILY_CALL_LIMIT (CET day).
+// Returns false when the limit is already used up.
+func (w *Watcher) reserveAICall() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	today := w.clock.Now().In(config.CetLoc).Format("2006-01-02")
+	if w.aiCallsDay != today {
+		w.aiCallsDay = today
+		w.aiCallsToday = 0
+	}
+	if w.config.AIDailyCallLimit > 0 && w.aiCallsToday >= w.config.AIDailyCallLimit {
+		return false
+	}
+	w.aiCallsToday++
+	return true
+}
+
+// heartbeatDetails is appended to the 24h heartbeat: the operational facts
+// that are not visible in the /status dashboard. clock may be nil.
+func (w *Watcher) heartbeatDetails(clock *alpaca.Clock) string {
+	w.mu.RLock()
+	var active []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			active = append(active, p)
+		}
+	}
+	aiUsed := 0
+	if w.aiCallsDay == w.clock.Now().In(config.CetLoc).Format("2006-01-02") {
+		aiUsed = w.aiCallsToday
+	}
+	lastHB := w.state.LastHeartbeat
+	w.mu.RUnlock()
+
+	var sb strings.Builder
+	sb.WriteString("💓 *HEARTBEAT*\n")
+
+	// 1. Riskiest position: smallest distance to its stop
+	riskiest, minDist := "", decimal.Zero
+	for _, p := range active {
+		if p.StopLoss.IsZero() {
+			continue
+		}
+		price, err := w.provider.GetPrice(w.ctx, p.Ticker)
+		if err != nil || !price.IsPositive() {
+			continue
+		}
+		dist := price.Sub(p.StopLoss).Div(price).Mul(decimal.NewFromInt(100))
+		if riskiest == "" || dist.LessThan(minDist) {
+			riskiest, minDist = p.Ticker, dist
+		}
+	}
+	if riskiest != "" {
+		sb.WriteString(fmt.Sprintf("Closest to Stop: %s (%s%% away)\n", riskiest, minDist.StringFixed(1)))
+	} else {
+		sb.WriteString("Closest to Stop: N/A\n")
+	}
+
+	// 2. Pending broker orders
+	if orders, err := w.provider.ListOrders(w.ctx, "open"); err == nil {
+		sb.WriteString(fmt.Sprintf("Pending Orders: %d\n", len(orders)))
+	} else {
+		sb.WriteString("Pending Orders: ERR\n")
+	}
+
+	// 3. AI budget
+	if w.config.AIDailyCallLimit > 0 {
+		sb.WriteString(fmt.Sprintf("AI Calls Today: %d / %d\n", aiUsed, w.config.AIDailyCallLimit))
+	} else {
+		sb.WriteString(fmt.Sprintf("AI Calls Today: %d (no limit)\n", aiUsed))
+	}
+
+	if w.drawdownHalted() {
+		sb.WriteString("🛑 Kill Switch: engaged, buys blocked until /resume\n")
+	} else if w.dailyLossHalted() {
+		sb.WriteString("⛔ Circuit Breaker: tripped, buys blocked until the next session\n")
+	}
+
+	// 4. Last error
+	if line, at := logger.LastError(); line != "" {
+		if len(line) > 160 {
+			line = line[:160] + "…"
+		}
+		sb.WriteString(fmt.Sprintf("Last Error (%s ago): `%s`\n", w.since(at).Round(time.Minute), line))
+	} else {
+		sb.WriteString("Last Error: none since startup\n")
+	}
+
+	// 5. Next scheduled jobs (CET)
+	now := w.clock.Now().In(config.CetLoc)
+	sb.WriteString("\n*Next Jobs (CET)*\n")
+	sb.WriteString(fmt.Sprintf("• Poll: %s\n", now.Add(time.Duration(w.config.PollIntervalMins)*time.Minute).Format("01-02 15:04")))
+	if clock != nil {
+		if clock.IsOpen {
+			note := ""
+			if earlyClose(clock.NextClose) {
+				note = " (early close)"
+			}
+			sb.WriteString(fmt.Sprintf("• EOD Report: %s%s\n", clock.NextClose.In(config.CetLoc).Format("01-02 15:04"), note))
+		} else {
+			sb.WriteString(fmt.Sprintf("• Pre-Open Checklist / AI Window: %s\n", clock.NextOpen.Add(-protectionCheckLead).In(config.CetLoc).Format("01-02 15:04")))
+			sb.WriteString(fmt.Sprintf("• Market Open: %s\n", clock.NextOpen.In(config.CetLoc).Format("01-02 15:04")))
+		}
+	}
+	if hb, err := time.Parse(time.RFC3339, lastHB); err == nil {
+		sb.WriteString(fmt.Sprintf("• Heartbeat: %s", hb.Add(24*time.Hour).In(config.CetLoc).Format("01-02 15:04")))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
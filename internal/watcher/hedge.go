@@ -0,0 +1,279 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	hedgeBenchmark   = "SPY"
+	hedgeHistoryDays = 60 // Daily bars behind each beta
+	hedgeMinReturns  = 20 // Common daily returns needed for a usable beta
+)
+
+// hedgeHolding is one position's contribution to the portfolio's market exposure.
+type hedgeHolding struct {
+	Ticker string
+	Value  decimal.Decimal // Current market value
+	Beta   decimal.Decimal // vs hedgeBenchmark
+}
+
+// betaDollars is the position's SPY-equivalent exposure (value x beta).
+func (h hedgeHolding) betaDollars() decimal.Decimal {
+	return h.Value.Mul(h.Beta)
+}
+
+// closesByDate maps each daily bar's session date to its close.
+func (w *Watcher) closesByDate(ticker string) (map[string]float64, error) {
+	bars, err := w.provider.GetBars(w.ctx, ticker, hedgeHistoryDays+1)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64, len(bars))
+	for _, b := range bars {
+		if b.Close > 0 {
+			out[b.Timestamp.Format("2006-01-02")] = b.Close
+		}
+	}
+	return out, nil
+}
+
+// pairedReturns returns the daily returns of a and b over the sessions both series
+// traded (crypto's weekend bars drop out).
+func pairedReturns(a, b map[string]float64) (ra, rb []float64) {
+	var dates []string
+	for d := range a {
+		if _, ok := b[d]; ok {
+			dates = append(dates, d)
+		}
+	}
+	sort.Strings(dates)
+	for i := 1; i < len(dates); i++ {
+		prev, cur := dates[i-1], dates[i]
+		ra = append(ra, a[cur]/a[prev]-1)
+		rb = append(rb, b[cur]/b[prev]-1)
+	}
+	return ra, rb
+}
+
+// betaTo returns cov(r, rBench) / var(rBench) over the paired daily returns.
+func betaTo(closes, bench map[string]float64) (float64, bool) {
+	xs, ys := pairedReturns(bench, closes)
+	if len(xs) < hedgeMinReturns {
+		return 0, false
+	}
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= float64(len(xs))
+	my /= float64(len(ys))
+	var cov, variance float64
+	for i := range xs {
+		cov += (xs[i] - mx) * (ys[i] - my)
+		variance += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if variance == 0 {
+		return 0, false
+	}
+	return cov / variance, true
+}
+
+// portfolioBeta measures each active position against SPY. Positions without enough
+// shared history (new listings, forex pairs) are returned in skipped.
+func (w *Watcher) portfolioBeta() (holdings []hedgeHolding, skipped []string, err error) {
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+	if len(positions) == 0 {
+		return nil, nil, nil
+	}
+
+	bench, err := w.closesByDate(hedgeBenchmark)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s bars: %v", hedgeBenchmark, err)
+	}
+	tickers := make([]string, len(positions))
+	for i, p := range positions {
+		tickers[i] = p.Ticker
+	}
+	snaps := w.getSnapshots(tickers)
+
+	for _, p := range positions {
+		snap, ok := snaps[p.Ticker]
+		if !ok || symbols.IsForex(p.Ticker) {
+			skipped = append(skipped, p.Ticker)
+			continue
+		}
+		beta := 1.0
+		if p.Ticker != hedgeBenchmark {
+			closes, err := w.closesByDate(p.Ticker)
+			if err != nil {
+				skipped = append(skipped, p.Ticker)
+				continue
+			}
+			if beta, ok = betaTo(closes, bench); !ok {
+				skipped = append(skipped, p.Ticker)
+				continue
+			}
+		}
+		holdings = append(holdings, hedgeHolding{Ticker: p.Ticker, Value: p.Quantity.Mul(snap.Last), Beta: decimal.NewFromFloat(beta)})
+	}
+	return holdings, skipped, nil
+}
+
+// hedgePlan sizes a hedge leg for a target exposure: notional / |beta| of the instrument.
+type hedgePlan struct {
+	Ticker    string
+	Beta      decimal.Decimal
+	Price     decimal.Decimal
+	Qty       decimal.Decimal
+	Notional  decimal.Decimal
+	SpreadUSD decimal.Decimal // Round trip, at the current quote
+	MonthUSD  decimal.Decimal // Carry: expense ratio or borrow fee, per month
+}
+
+func (w *Watcher) planHedge(ticker string, beta, exposure, annualPct decimal.Decimal) (hedgePlan, error) {
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil || !price.IsPositive() {
+		return hedgePlan{}, fmt.Errorf("no price for %s", ticker)
+	}
+	plan := hedgePlan{Ticker: ticker, Beta: beta, Price: price}
+	plan.Qty = money.Qty(ticker, exposure.Div(beta.Abs()).Div(price), false)
+	plan.Notional = plan.Qty.Mul(price)
+	if q, err := w.provider.GetQuote(w.ctx, ticker); err == nil && q != nil {
+		if bps, ok := spreadBps(decimal.NewFromFloat(q.BidPrice), decimal.NewFromFloat(q.AskPrice)); ok {
+			plan.SpreadUSD = plan.Notional.Mul(bps).Div(decimal.NewFromInt(10000))
+		}
+	}
+	plan.MonthUSD = plan.Notional.Mul(annualPct).Div(decimal.NewFromInt(100 * 12))
+	return plan, nil
+}
+
+// hedgeETFBeta measures HEDGE_ETF against SPY (about -1 for SH, -2 for SDS).
+func (w *Watcher) hedgeETFBeta() (decimal.Decimal, error) {
+	bench, err := w.closesByDate(hedgeBenchmark)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	closes, err := w.closesByDate(w.config.HedgeETF)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	beta, ok := betaTo(closes, bench)
+	if !ok || beta >= 0 {
+		return decimal.Zero, fmt.Errorf("%s does not move inversely to %s", w.config.HedgeETF, hedgeBenchmark)
+	}
+	return decimal.NewFromFloat(beta), nil
+}
+
+// handleHedgeCommand reports the portfolio's beta to SPY and sizes a hedge.
+// /hedge [pct]       -> beta report with an inverse-ETF and a short-SPY hedge for pct% (default 100) of it
+// /hedge buy [pct]   -> propose the inverse-ETF hedge (normal EXECUTE/CANCEL flow)
+func (w *Watcher) handleHedgeCommand(parts []string) string {
+	const usage = "Usage: /hedge [buy] [pct]"
+	args := parts[1:]
+	buy := len(args) > 0 && strings.EqualFold(args[0], "buy")
+	if buy {
+		args = args[1:]
+	}
+	ratio := decimal.NewFromInt(100)
+	if len(args) > 0 {
+		r, err := decimal.NewFromString(strings.TrimSuffix(args[0], "%"))
+		if err != nil || !r.IsPositive() || r.GreaterThan(decimal.NewFromInt(200)) || len(args) > 1 {
+			return usage
+		}
+		ratio = r
+	}
+
+	holdings, skipped, err := w.portfolioBeta()
+	if err != nil {
+		return fmt.Sprintf("⚠️ Could not measure beta: %v", err)
+	}
+	if len(holdings) == 0 {
+		return "ℹ️ No active positions with enough price history to measure beta."
+	}
+	exposure := decimal.Zero
+	value := decimal.Zero
+	for _, h := range holdings {
+		exposure = exposure.Add(h.betaDollars())
+		value = value.Add(h.Value)
+	}
+	target := exposure.Mul(ratio).Div(decimal.NewFromInt(100))
+
+	if buy {
+		if !target.IsPositive() {
+			return fmt.Sprintf("✅ Beta-weighted exposure is $%s: nothing to hedge.", exposure.StringFixed(2))
+		}
+		etfBeta, err := w.hedgeETFBeta()
+		if err != nil {
+			return fmt.Sprintf("⚠️ Cannot size the %s hedge: %v", w.config.HedgeETF, err)
+		}
+		plan, err := w.planHedge(w.config.HedgeETF, etfBeta, target, decimal.NewFromFloat(w.config.HedgeETFExpensePct))
+		if err != nil {
+			return fmt.Sprintf("⚠️ %v", err)
+		}
+		if !plan.Qty.IsPositive() {
+			return fmt.Sprintf("ℹ️ The hedge ($%s) is less than one share of %s ($%s).", target.StringFixed(2), plan.Ticker, plan.Price.StringFixed(2))
+		}
+		return w.proposeBuy(plan.Ticker, plan.Qty, decimal.Zero, decimal.Zero, buyOrder{})
+	}
+
+	sort.Slice(holdings, func(i, j int) bool {
+		return holdings[i].betaDollars().Abs().GreaterThan(holdings[j].betaDollars().Abs())
+	})
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🛡️ *PORTFOLIO BETA* (vs %s, %dd)\n```\n", hedgeBenchmark, hedgeHistoryDays))
+	sb.WriteString(fmt.Sprintf("%-9s %9s %5s %9s\n", "Ticker", "Value", "Beta", "Beta $"))
+	for _, h := range holdings {
+		sb.WriteString(fmt.Sprintf("%-9s %9s %5s %9s\n", h.Ticker, h.Value.StringFixed(0), h.Beta.StringFixed(2), h.betaDollars().StringFixed(0)))
+	}
+	sb.WriteString("```")
+	sb.WriteString(fmt.Sprintf("\nInvested: $%s | Beta-weighted: $%s", value.StringFixed(2), exposure.StringFixed(2)))
+	if value.IsPositive() {
+		sb.WriteString(fmt.Sprintf(" (beta %s)", exposure.Div(value).StringFixed(2)))
+	}
+	if equity, err := w.provider.GetEquity(w.ctx); err == nil && equity.IsPositive() {
+		sb.WriteString(fmt.Sprintf("\nAccount beta: %s (on $%s equity)", exposure.Div(equity).StringFixed(2), equity.StringFixed(2)))
+	}
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("\nNot measured: %s", strings.Join(skipped, ", ")))
+	}
+	if !target.IsPositive() {
+		sb.WriteString("\n\n✅ The portfolio is market-neutral or net short: no hedge needed.")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("\n\n*Hedge %s%%* ($%s of %s exposure)", ratio.String(), target.StringFixed(2), hedgeBenchmark))
+	if etfBeta, err := w.hedgeETFBeta(); err != nil {
+		sb.WriteString(fmt.Sprintf("\n• %s: %v", w.config.HedgeETF, err))
+	} else if plan, err := w.planHedge(w.config.HedgeETF, etfBeta, target, decimal.NewFromFloat(w.config.HedgeETFExpensePct)); err != nil {
+		sb.WriteString(fmt.Sprintf("\n• %s: %v", w.config.HedgeETF, err))
+	} else {
+		sb.WriteString(fmt.Sprintf("\n• Buy %s %s @ $%s = $%s (beta %s)\n  Costs: ~$%s spread round trip, ~$%s/month expense ratio (%.2f%%/yr)",
+			plan.Qty.String(), plan.Ticker, plan.Price.StringFixed(2), plan.Notional.StringFixed(2), plan.Beta.StringFixed(2),
+			plan.SpreadUSD.StringFixed(2), plan.MonthUSD.StringFixed(2), w.config.HedgeETFExpensePct))
+	}
+	if plan, err := w.planHedge(hedgeBenchmark, decimal.NewFromInt(1), target, decimal.NewFromFloat(w.config.HedgeBorrowPct)); err != nil {
+		sb.WriteString(fmt.Sprintf("\n• Short %s: %v", hedgeBenchmark, err))
+	} else {
+		sb.WriteString(fmt.Sprintf("\n• Short %s %s @ $%s = $%s\n  Costs: ~$%s spread round trip, ~$%s/month borrow (%.2f%%/yr). Needs a margin account; the watcher does not track short positions.",
+			plan.Qty.String(), plan.Ticker, plan.Price.StringFixed(2), plan.Notional.StringFixed(2),
+			plan.SpreadUSD.StringFixed(2), plan.MonthUSD.StringFixed(2), w.config.HedgeBorrowPct))
+	}
+	sb.WriteString(fmt.Sprintf("\n\nPropose the %s hedge: `/hedge buy %s`. It opens like any position, with the default SL/TP: a rally stops it out.", w.config.HedgeETF, ratio.String()))
+	return sb.String()
+}
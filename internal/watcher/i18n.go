@@ -0,0 +1,50 @@
+package watcher
+
+// translations maps English report labels to other languages (Settings.Language).
+// Missing keys fall back to English.
+var translations = map[string]map[string]string{
+	"es": {
+		"Market":                                 "Mercado",
+		"Opens in":                               "Abre en",
+		"Closes in":                              "Cierra en",
+		"Equity":                                 "Patrimonio",
+		"Budget":                                 "Presupuesto",
+		"Available":                              "Disponible",
+		"Uptime":                                 "Tiempo activo",
+		"PENDING ORDERS":                         "ÓRDENES PENDIENTES",
+		"MARKET CLOSE REPORT":                    "INFORME DE CIERRE",
+		"Account Summary":                        "Resumen de Cuenta",
+		"End Equity":                             "Patrimonio Final",
+		"Daily Change":                           "Cambio Diario",
+		"Activity Today":                         "Actividad de Hoy",
+		"No trades closed today.":                "No se cerraron operaciones hoy.",
+		"No active positions carried overnight.": "No hay posiciones abiertas esta noche.",
+		"No trades today.":                       "Sin operaciones hoy.",
+		"positions held":                         "posiciones abiertas",
+		"Equity Curve":                           "Curva de Patrimonio",
+		"Range":                                  "Rango",
+		"AI Commentary":                          "Comentario IA",
+		"Intraday":                               "Intradía",
+		"High":                                   "Máx",
+		"Low":                                    "Mín",
+		"Max DD":                                 "Caída Máx",
+		"Options":                                "Opciones",
+		"At Risk":                                "En Riesgo",
+		"Value at Risk":                          "Valor en Riesgo",
+		"Carry":                                  "Devengo",
+	},
+}
+
+var supportedLanguages = []string{"en", "es"}
+
+// tr translates a report label into the configured language.
+func (w *Watcher) tr(key string) string {
+	w.mu.RLock()
+	lang := w.state.Settings.Language
+	w.mu.RUnlock()
+
+	if t, ok := translations[lang][key]; ok {
+		return t
+	}
+	return key
+}
@@ -0,0 +1,355 @@
+package watcher
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	importConfirmTTL = 10 * time.Minute
+	importQtyTol     = 0.0001 // Fractional-share rounding between the CSV and the broker
+	importEntryWarn  = 1.0    // % difference to the broker's average entry worth a warning
+)
+
+// importColumns maps accepted header names to fields. Without a header row the
+// columns are ticker, qty, entry, stop, target.
+var importColumns = map[string]string{
+	"ticker": "ticker", "symbol": "ticker",
+	"qty": "qty", "quantity": "qty", "shares": "qty",
+	"entry": "entry", "entry_price": "entry", "avg_entry": "entry", "cost": "entry",
+	"stop": "stop", "stop_loss": "stop", "sl": "stop",
+	"target": "target", "take_profit": "target", "tp": "target",
+	"trailing": "trailing", "trailing_stop_pct": "trailing", "ts": "trailing",
+	"book":   "book",
+	"opened": "opened", "opened_at": "opened",
+}
+
+// importRow is one validated CSV line.
+type importRow struct {
+	Ticker      string
+	Qty         decimal.Decimal
+	Entry       decimal.Decimal
+	StopLoss    decimal.Decimal
+	TakeProfit  decimal.Decimal
+	TrailingPct decimal.Decimal // Zero = keep
+	Book        string          // "" = keep
+	OpenedAt    time.Time       // Zero = keep
+}
+
+// pendingImport is a previewed CSV import awaiting the confirm button.
+type pendingImport struct {
+	id   string
+	at   time.Time
+	rows []importRow
+}
+
+// HandleDocument processes a file uploaded to the bot. A CSV with no caption or with
+// the caption /import is previewed as a position import; a file with the caption
+// /import-config (or a .json file) as a config bundle.
+func (w *Watcher) HandleDocument(caption, fileName string, content []byte) string {
+	if peer, rest := w.shardTarget(caption); rest != "" {
+		if peer != nil {
+			return w.relay(peer, "/document", shardRelay{Caption: rest, FileName: fileName, Content: content})
+		}
+		caption = rest
+	}
+	if strings.HasPrefix(caption, "/import-config") || (caption == "" && strings.HasSuffix(strings.ToLower(fileName), ".json")) {
+		return w.previewConfigImport(content)
+	}
+	if caption != "" && !strings.HasPrefix(caption, "/import") {
+		return "⚠️ Unknown upload. Send a CSV with the caption /import to import positions, or a config file with /import-config."
+	}
+	if !strings.HasSuffix(strings.ToLower(fileName), ".csv") && !strings.HasSuffix(strings.ToLower(fileName), ".txt") {
+		return fmt.Sprintf("⚠️ %s is not a CSV file.", fileName)
+	}
+	return w.previewImport(string(content))
+}
+
+// handleImportCommand previews an import pasted below the command:
+// /import
+// ticker,qty,entry,stop,target
+// AAPL,10,180,170,210
+func (w *Watcher) handleImportCommand(cmd string) string {
+	body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(cmd), "/import"))
+	if body == "" {
+		return "📥 *IMPORT POSITIONS*\nSend a CSV file with the caption `/import`, or paste the rows below the command:\n```\n/import\nticker,qty,entry,stop,target\nAAPL,10,180.50,170,210\n```\nOptional columns: `trailing`, `book`, `opened` (YYYY-MM-DD). Every ticker must be held at the broker."
+	}
+	return w.previewImport(body)
+}
+
+// parseImportCSV reads the rows. Lines that cannot be parsed are reported, not fatal.
+func parseImportCSV(text string) ([]importRow, []string) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	cols := []string{"ticker", "qty", "entry", "stop", "target"}
+	var rows []importRow
+	var problems []string
+	for first := true; ; first = false {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			problems = append(problems, err.Error()) // Carries the line number
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		if first {
+			if _, ok := importColumns[strings.ToLower(strings.TrimSpace(rec[0]))]; ok {
+				cols = make([]string, len(rec))
+				for i, h := range rec {
+					cols[i] = importColumns[strings.ToLower(strings.TrimSpace(h))] // Unknown headers are ignored
+				}
+				continue
+			}
+		}
+		row, err := parseImportRecord(cols, rec)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, problems
+}
+
+// parseImportRecord maps one record onto an importRow. ticker, qty, stop and target are required.
+func parseImportRecord(cols, rec []string) (importRow, error) {
+	var row importRow
+	for i, v := range rec {
+		if i >= len(cols) || cols[i] == "" {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		var err error
+		switch cols[i] {
+		case "ticker":
+			row.Ticker = symbols.Normalize(v)
+		case "qty":
+			row.Qty, err = decimal.NewFromString(v)
+		case "entry":
+			row.Entry, err = decimal.NewFromString(strings.TrimPrefix(v, "$"))
+		case "stop":
+			row.StopLoss, err = decimal.NewFromString(strings.TrimPrefix(v, "$"))
+		case "target":
+			row.TakeProfit, err = decimal.NewFromString(strings.TrimPrefix(v, "$"))
+		case "trailing":
+			row.TrailingPct, err = decimal.NewFromString(strings.TrimSuffix(v, "%"))
+		case "book":
+			row.Book = strings.ToLower(v)
+		case "opened":
+			row.OpenedAt, err = time.ParseInLocation("2006-01-02", v, easternLoc())
+		}
+		if err != nil {
+			return row, fmt.Errorf("invalid %s %q", cols[i], v)
+		}
+	}
+	switch {
+	case row.Ticker == "":
+		return row, fmt.Errorf("missing ticker")
+	case !row.Qty.IsPositive():
+		return row, fmt.Errorf("%s: missing or non-positive qty", row.Ticker)
+	case !row.StopLoss.IsPositive() || !row.TakeProfit.IsPositive():
+		return row, fmt.Errorf("%s: missing stop or target", row.Ticker)
+	case !row.TakeProfit.GreaterThan(row.StopLoss):
+		return row, fmt.Errorf("%s: target must be above stop", row.Ticker)
+	}
+	row.StopLoss = money.Price(row.Ticker, row.StopLoss)
+	row.TakeProfit = money.Price(row.Ticker, row.TakeProfit)
+	return row, nil
+}
+
+// previewImport validates the rows against the broker's holdings and current prices,
+// then asks for confirmation. Rows that fail are listed and left out.
+func (w *Watcher) previewImport(text string) string {
+	rows, problems := parseImportCSV(text)
+
+	positions, err := w.provider.ListPositions(w.ctx)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read broker holdings: %v", err)
+	}
+	type holding struct{ qty, entry decimal.Decimal }
+	held := make(map[string]holding)
+	for _, p := range positions {
+		held[symbols.Normalize(p.Symbol)] = holding{p.Qty, p.AvgEntryPrice}
+	}
+	w.mu.RLock()
+	books := make(map[string]bool)
+	for _, b := range w.state.Books {
+		books[b.Name] = true
+	}
+	w.mu.RUnlock()
+
+	var ok []importRow
+	var warnings []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		h, isHeld := held[row.Ticker]
+		switch {
+		case seen[row.Ticker]:
+			problems = append(problems, fmt.Sprintf("%s: duplicate row", row.Ticker))
+			continue
+		case !isHeld:
+			problems = append(problems, fmt.Sprintf("%s: not held at the broker", row.Ticker))
+			continue
+		case row.Qty.Sub(h.qty).Abs().GreaterThan(decimal.NewFromFloat(importQtyTol)):
+			problems = append(problems, fmt.Sprintf("%s: qty %s does not match the broker's %s", row.Ticker, row.Qty.String(), h.qty.String()))
+			continue
+		case row.Book != "" && !books[row.Book]:
+			problems = append(problems, fmt.Sprintf("%s: unknown book %q (create it with /book add)", row.Ticker, row.Book))
+			continue
+		}
+		// Same safety gates as /update: the stop must be below and the target above the market
+		if price, err := w.provider.GetPrice(w.ctx, row.Ticker); err == nil && price.IsPositive() {
+			if !row.StopLoss.LessThan(price) || !row.TakeProfit.GreaterThan(price) {
+				problems = append(problems, fmt.Sprintf("%s: stop $%s / target $%s must bracket the price $%s", row.Ticker, row.StopLoss.StringFixed(2), row.TakeProfit.StringFixed(2), price.StringFixed(2)))
+				continue
+			}
+		}
+		if row.Entry.IsPositive() && h.entry.IsPositive() {
+			diff := row.Entry.Sub(h.entry).Div(h.entry).Abs().Mul(decimal.NewFromInt(100))
+			if diff.GreaterThan(decimal.NewFromFloat(importEntryWarn)) {
+				warnings = append(warnings, fmt.Sprintf("%s: entry $%s differs from the broker's average $%s by %s%%; the broker's is kept", row.Ticker, row.Entry.StringFixed(2), h.entry.StringFixed(2), diff.StringFixed(1)))
+			}
+		}
+		seen[row.Ticker] = true
+		ok = append(ok, row)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📥 *IMPORT PREVIEW*: %d row(s) ready, %d rejected\n", len(ok), len(problems)))
+	for _, row := range ok {
+		sb.WriteString(fmt.Sprintf("\n✅ %s x%s | SL $%s | TP $%s", row.Ticker, row.Qty.String(), row.StopLoss.StringFixed(2), row.TakeProfit.StringFixed(2)))
+		if row.TrailingPct.IsPositive() {
+			sb.WriteString(fmt.Sprintf(" | TS %s%%", row.TrailingPct.String()))
+		}
+		if row.Book != "" {
+			sb.WriteString(" | book " + row.Book)
+		}
+	}
+	for _, p := range problems {
+		sb.WriteString("\n❌ " + p)
+	}
+	for _, warn := range warnings {
+		sb.WriteString("\n⚠️ " + warn)
+	}
+	if len(ok) == 0 {
+		return sb.String()
+	}
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingImport = &pendingImport{id: id, at: w.clock.Now(), rows: ok}
+	w.mu.Unlock()
+
+	sb.WriteString(fmt.Sprintf("\n\nApplying backs up the state file and sets these levels on the positions. This button expires in %d minutes.", int(importConfirmTTL.Minutes())))
+	telegram.SendInteractiveMessage(sb.String(), []telegram.Button{
+		{Text: "📥 IMPORT", CallbackData: "IMPORT_APPLY_" + id},
+		{Text: "❌ CANCEL", CallbackData: "IMPORT_CANCEL_" + id},
+	})
+	return ""
+}
+
+// handleImportCallback applies (IMPORT_APPLY_<id>) or discards a previewed import.
+func (w *Watcher) handleImportCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid import callback data."
+	}
+
+	w.mu.Lock()
+	pending := w.pendingImport
+	w.pendingImport = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > importConfirmTTL {
+		return "⚠️ Import expired. Send the CSV again."
+	}
+	if parts[1] != "APPLY" {
+		return "❌ Import cancelled. Nothing was changed."
+	}
+
+	// Every row is held at the broker: a sync makes sure each has an ACTIVE record
+	if _, err := w.SyncWithBroker(); err != nil {
+		return fmt.Sprintf("❌ Broker sync failed (%v). Nothing was changed.", err)
+	}
+	w.FlushState()
+	backup, err := storage.BackupState()
+	if err != nil {
+		return fmt.Sprintf("❌ Backup failed (%v). Nothing was changed.", err)
+	}
+
+	var done []importRow
+	var applied, missing, protect, patch []string
+	w.mu.Lock()
+	for _, row := range pending.rows {
+		idx := -1
+		for i, p := range w.state.Positions {
+			if p.Ticker == row.Ticker && p.Status == "ACTIVE" {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			missing = append(missing, row.Ticker)
+			continue
+		}
+		p := &w.state.Positions[idx]
+		p.StopLoss, p.TakeProfit = row.StopLoss, row.TakeProfit
+		if row.TrailingPct.IsPositive() {
+			p.TrailingStopPct = row.TrailingPct
+		}
+		if row.Book != "" {
+			p.Book = row.Book
+		}
+		if !row.OpenedAt.IsZero() {
+			p.OpenedAt = row.OpenedAt
+		}
+		done = append(done, row)
+		applied = append(applied, row.Ticker)
+		if brokerExitID(*p) != "" {
+			patch = append(patch, row.Ticker)
+		} else if w.config.BrokerProtectionEnabled {
+			protect = append(protect, row.Ticker)
+		}
+	}
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	for _, row := range done {
+		w.recordAudit(row.Ticker, auditImported, "USER", decimal.Zero, fmt.Sprintf("CSV import: SL %s TP %s", row.StopLoss.StringFixed(2), row.TakeProfit.StringFixed(2)))
+	}
+	for _, t := range patch {
+		go w.syncBrokerExit(t)
+	}
+	for _, t := range protect {
+		go w.protectAtBroker(t)
+	}
+	log.Printf("Import: applied %v, missing %v (backup %s)", applied, missing, backup)
+
+	msg := fmt.Sprintf("✅ Imported %d position(s): %s\n💾 Backup: `%s`", len(applied), strings.Join(applied, ", "), backup)
+	if len(missing) > 0 {
+		msg += fmt.Sprintf("\n⚠️ No longer held, skipped: %s", strings.Join(missing, ", "))
+	}
+	if len(patch)+len(protect) > 0 {
+		msg += "\n🛡️ Updating the broker-side exits with the new levels."
+	}
+	return msg
+}
@@ -0,0 +1,244 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// Order intents: every order is journaled (order_intents.jsonl) before PlaceOrder and
+// again with its outcome, under an ID that is also its client order ID. On start the
+// journal is matched against the broker's orders, so a crash between placement and
+// the state update cannot leave an untracked position (or a sold one still tracked).
+// Orders whose placement timed out (UNKNOWN) are matched again on every sync.
+
+// Intent statuses (see models.OrderIntent).
+const (
+	intentPending = "INTENT" // Written before PlaceOrder; the order may or may not exist
+	intentPlaced  = "PLACED"
+	intentUnknown = "UNKNOWN" // Timeout or network error: the order may exist, reconciled by client order ID
+	intentFailed  = "FAILED"  // Rejected by the broker (4xx): nothing to reconcile
+)
+
+// intentLookupDelay gives an order whose PlaceOrder call was abandoned (timeout) time
+// to land before it is looked up by client order ID.
+const intentLookupDelay = 2 * time.Second
+
+// journalIntent appends an intent record. Failures are logged, never fatal.
+func (w *Watcher) journalIntent(in models.OrderIntent) {
+	if err := storage.AppendIntent(in); err != nil {
+		log.Printf("ERROR: Failed to journal order intent %s (%s %s): %v", in.ID, in.Side, in.Ticker, err)
+	}
+}
+
+// placeOrder journals the intent, places the order under the intent ID, and journals
+// the outcome. intent carries the ticker, qty, side, source and any proposal levels.
+func (w *Watcher) placeOrder(intent models.OrderIntent, opts ...market.OrderOptions) (*alpaca.Order, error) {
+	o := market.OrderOptions{}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	intent.ID = fmt.Sprintf("at-%d", w.clock.Now().UnixNano())
+	intent.Time = w.clock.Now()
+	intent.Status = intentPending
+	intent.Notional = o.Notional
+	o.ClientOrderID = intent.ID
+	w.journalIntent(intent)
+
+	order, err := w.provider.PlaceOrder(w.ctx, intent.Ticker, intent.Qty, intent.Side, o)
+	if err != nil && !market.IsRejection(err) {
+		// The order may have reached the broker anyway: look for it under our ID
+		log.Printf("Order Intents: %s %s %s outcome unknown (%v), looking it up", intent.Side, intent.Ticker, intent.Qty.String(), err)
+		w.clock.Sleep(intentLookupDelay)
+		if found, ok := w.findIntentOrder(intent.ID); ok {
+			order, err = found, nil
+		}
+	}
+	intent.Time = w.clock.Now()
+	switch {
+	case err == nil:
+		intent.Status, intent.OrderID = intentPlaced, order.ID
+	case market.IsRejection(err):
+		intent.Status = intentFailed
+	default:
+		intent.Status = intentUnknown
+		err = fmt.Errorf("order outcome unknown, it is reconciled at the next sync: %w", err)
+	}
+	w.journalIntent(intent)
+	return order, err
+}
+
+// findIntentOrder looks up the broker order placed under client order ID id.
+func (w *Watcher) findIntentOrder(id string) (*alpaca.Order, bool) {
+	o, err := w.provider.GetOrderByClientID(w.ctx, id)
+	if err != nil {
+		if !errors.Is(err, market.ErrOrderNotFound) {
+			log.Printf("Order Intents: lookup of %s failed: %v", id, err)
+		}
+		return nil, false
+	}
+	return o, true
+}
+
+// intentOrder fetches the broker order of a journaled intent: by client order ID,
+// then by the broker ID the journal recorded. A nil order with a nil error means the
+// broker has none.
+func (w *Watcher) intentOrder(in models.OrderIntent) (*alpaca.Order, error) {
+	o, err := w.provider.GetOrderByClientID(w.ctx, in.ID)
+	if errors.Is(err, market.ErrOrderNotFound) && in.OrderID != "" {
+		o, err = w.provider.GetOrder(w.ctx, in.OrderID)
+	}
+	if errors.Is(err, market.ErrOrderNotFound) {
+		return nil, nil
+	}
+	return o, err
+}
+
+// reconcileIntents matches the whole journal against the broker at startup and
+// repairs the state where an order outran it.
+func (w *Watcher) reconcileIntents() {
+	w.reconcileJournal(func(models.OrderIntent) bool { return true })
+}
+
+// resolveUnknownIntents reconciles the orders whose placement timed out this session
+// (UNKNOWN). PLACED and INTENT records belong to callers still handling them.
+func (w *Watcher) resolveUnknownIntents() {
+	w.reconcileJournal(func(in models.OrderIntent) bool { return in.Status == intentUnknown })
+}
+
+// reconcileJournal looks up the due intents one by one and drops them from the
+// journal once resolved (FAILED ones are moot). An intent whose lookup fails stays for
+// the next reconciliation.
+func (w *Watcher) reconcileJournal(due func(models.OrderIntent) bool) {
+	intents, err := storage.LoadIntents()
+	if err != nil {
+		log.Printf("Order Intents: journal unreadable: %v", err)
+		return
+	}
+	resolved := make(map[string]bool)
+	var open []models.OrderIntent
+	for _, in := range intents {
+		switch {
+		case in.Status == intentFailed:
+			resolved[in.ID] = true
+		case due(in):
+			open = append(open, in)
+		}
+	}
+
+	var notes []string
+	if len(open) > 0 {
+		positions, err := w.provider.ListPositions(w.ctx)
+		if err != nil {
+			log.Printf("Order Intents: could not list positions, keeping the journal: %v", err)
+			return
+		}
+		held := make(map[string]bool)
+		for _, p := range positions {
+			held[p.Symbol] = p.Qty.IsPositive()
+		}
+		for _, in := range open {
+			order, err := w.intentOrder(in)
+			if err != nil {
+				log.Printf("Order Intents: %s %s %s lookup failed, kept for the next reconciliation: %v", in.Side, in.Ticker, in.Qty.String(), err)
+				continue
+			}
+			resolved[in.ID] = true
+			if order == nil {
+				if in.Status == intentPending || in.Status == intentUnknown {
+					log.Printf("Order Intents: %s %s %s never reached the broker", in.Side, in.Ticker, in.Qty.String())
+				}
+				continue
+			}
+			if note := w.reconcileIntent(in, order, held[in.Ticker]); note != "" {
+				notes = append(notes, note)
+			}
+		}
+	}
+	if err := storage.DropIntents(resolved); err != nil {
+		log.Printf("Order Intents: could not update the journal: %v", err)
+	}
+	if len(notes) > 0 {
+		log.Printf("Order Intents: %d repair(s)", len(notes))
+		telegram.Notify("🧾 *ORDER RECOVERY*\nOrders the state did not know about (placed before a shutdown or after a timeout):\n" + strings.Join(notes, "\n"))
+	}
+}
+
+// reconcileIntent repairs the state for one journaled order. Buys the state does not
+// know (resting, or filled into a broker position) are tracked as pending orders with
+// the proposal's levels (defaults when it had none); filled sells of a position still
+// tracked are closed. Returns a note for each repair, "" when the state was in sync.
+func (w *Watcher) reconcileIntent(in models.OrderIntent, order *alpaca.Order, held bool) string {
+	if w.isSweepTicker(in.Ticker) || in.Source == "PROTECT" {
+		return "" // Not tracked as positions / re-attached by the broker exit checks
+	}
+	status := strings.ToLower(order.Status)
+	resting := status == "new" || status == "accepted" || status == "partially_filled" || status == "pending_new"
+
+	if in.Side == "sell" {
+		if held || !order.FilledQty.IsPositive() {
+			return ""
+		}
+		if _, purged := w.purgePosition(in.Ticker, orderFillPrice(order), in.Source); purged {
+			return fmt.Sprintf("• %s: %s sell filled @ $%s. Position closed.", in.Ticker, in.Source, orderFillPrice(order).StringFixed(2))
+		}
+		return ""
+	}
+
+	if !held && !resting {
+		return ""
+	}
+	w.mu.Lock()
+	tracked := false
+	for _, p := range w.state.Positions {
+		if p.Ticker == in.Ticker && p.Status == "ACTIVE" {
+			tracked = true
+		}
+	}
+	if _, ok := w.pendingOrderLocked(in.Ticker); ok {
+		tracked = true
+	}
+	if tracked {
+		w.mu.Unlock()
+		return ""
+	}
+
+	sl, tp, tsPct := in.StopLoss, in.TakeProfit, in.TrailingStopPct
+	if sl.IsZero() {
+		sl, tp = w.defaultLevels(in.Ticker, orderFillPrice(order))
+		tsPct = decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
+	}
+	thesisID := in.ThesisID
+	if thesisID == "" {
+		thesisID = fmt.Sprintf("%s_%d", in.Source, in.Time.Unix())
+	}
+	po := models.PendingOrder{
+		OrderID:         order.ID,
+		Ticker:          in.Ticker,
+		Qty:             in.Qty,
+		StopLoss:        sl,
+		TakeProfit:      tp,
+		TrailingStopPct: tsPct,
+		ThesisID:        thesisID,
+		Book:            in.Book,
+		SubmittedAt:     in.Time,
+	}
+	if order.LimitPrice != nil {
+		po.LimitPrice = *order.LimitPrice
+	}
+	w.state.PendingOrders = append(w.state.PendingOrders, po)
+	w.saveStateLocked()
+	w.mu.Unlock()
+	return fmt.Sprintf("• %s: %s buy of %s (%s) is tracked again with SL $%s | TP $%s.",
+		in.Ticker, in.Source, in.Qty.String(), status, sl.StringFixed(2), tp.StringFixed(2))
+}
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"log"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/market"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+// intradayLookback is how far back to ask for n bars of tf. Sessions cover only part of
+// the day, so the window is stretched and padded to reach across nights and weekends.
+func intradayLookback(tf marketdata.TimeFrame, n int) time.Duration {
+	unit := time.Minute
+	switch tf.Unit {
+	case marketdata.Hour:
+		unit = time.Hour
+	case marketdata.Day:
+		unit = 24 * time.Hour
+	case marketdata.Week:
+		unit = 7 * 24 * time.Hour
+	}
+	return time.Duration(tf.N*n*3)*unit + 4*24*time.Hour
+}
+
+// snapshotTickers lists the held tickers plus the focus ticker (if any), without duplicates.
+func (w *Watcher) snapshotTickers(focus string) []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var tickers []string
+	seen := make(map[string]bool)
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && !seen[p.Ticker] {
+			seen[p.Ticker] = true
+			tickers = append(tickers, p.Ticker)
+		}
+	}
+	if focus != "" && !seen[focus] {
+		tickers = append(tickers, focus)
+	}
+	return tickers
+}
+
+// intradayBars returns the last AI_INTRADAY_BARS bars of AI_INTRADAY_TIMEFRAME for the
+// held tickers and the focus ticker. Tickers whose bars cannot be fetched are left out.
+func (w *Watcher) intradayBars(focus string) map[string][]ai.Bar {
+	n := w.config.AIIntradayBars
+	if n <= 0 || w.config.AIIntradayTimeframe == "" {
+		return nil
+	}
+	tf, err := market.ParseTimeFrame(w.config.AIIntradayTimeframe)
+	if err != nil {
+		log.Printf("WARNING: AI_INTRADAY_TIMEFRAME: %v", err)
+		return nil
+	}
+
+	tickers := w.snapshotTickers(focus)
+	start := w.clock.Now().Add(-intradayLookback(tf, n))
+	out := make(map[string][]ai.Bar, len(tickers))
+	for _, t := range tickers {
+		bars, err := w.provider.GetBarsRange(w.ctx, t, w.config.AIIntradayTimeframe, start, time.Time{})
+		if err != nil {
+			log.Printf("Snapshot Warning: intraday bars for %s: %v", t, err)
+			continue
+		}
+		if len(bars) > n {
+			bars = bars[len(bars)-n:]
+		}
+		for _, b := range bars {
+			out[t] = append(out[t], ai.Bar{
+				Time:   b.Timestamp.UTC().Format(time.RFC3339),
+				Open:   b.Open,
+				High:   b.High,
+				Low:    b.Low,
+				Close:  b.Close,
+				Volume: b.Volume,
+			})
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
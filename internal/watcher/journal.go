@@ -0,0 +1,133 @@
+package watcher
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TradeRecord is a completed round trip (buy lots matched FIFO against a sell fill).
+type TradeRecord struct {
+	Ticker     string
+	Qty        decimal.Decimal
+	EntryPrice decimal.Decimal // Weighted average of the matched buy lots
+	ExitPrice  decimal.Decimal
+	PL         decimal.Decimal
+	OpenedAt   time.Time
+	ClosedAt   time.Time
+}
+
+// TradeStats summarizes the journal for sizing decisions.
+type TradeStats struct {
+	Trades      int
+	WinRate     decimal.Decimal // 0..1
+	PayoffRatio decimal.Decimal // AvgWin / AvgLoss
+}
+
+type lot struct {
+	qty    decimal.Decimal
+	price  decimal.Decimal
+	filled time.Time
+}
+
+// buildTradeJournal reconstructs completed trades from the broker's closed orders.
+// The broker is the source of truth for fills, so this works even for positions
+// that were purged from local state (Spec 57).
+func (w *Watcher) buildTradeJournal() ([]TradeRecord, error) {
+	orders, err := w.provider.ListOrders(w.ctx, "closed")
+	if err != nil {
+		return nil, err
+	}
+
+	// Only filled orders, oldest first
+	sort.Slice(orders, func(i, j int) bool {
+		if orders[i].FilledAt == nil || orders[j].FilledAt == nil {
+			return orders[j].FilledAt != nil
+		}
+		return orders[i].FilledAt.Before(*orders[j].FilledAt)
+	})
+
+	lots := make(map[string][]lot)
+	var journal []TradeRecord
+
+	for _, o := range orders {
+		if o.FilledAt == nil || o.FilledAvgPrice == nil || !o.FilledQty.IsPositive() {
+			continue
+		}
+		price := *o.FilledAvgPrice
+		qty := o.FilledQty
+
+		if strings.EqualFold(string(o.Side), "buy") {
+			lots[o.Symbol] = append(lots[o.Symbol], lot{qty: qty, price: price, filled: *o.FilledAt})
+			continue
+		}
+
+		// Sell: consume lots FIFO
+		remaining := qty
+		cost := decimal.Zero
+		matched := decimal.Zero
+		var openedAt time.Time
+		queue := lots[o.Symbol]
+		for len(queue) > 0 && remaining.IsPositive() {
+			take := decimal.Min(queue[0].qty, remaining)
+			if openedAt.IsZero() {
+				openedAt = queue[0].filled
+			}
+			cost = cost.Add(take.Mul(queue[0].price))
+			matched = matched.Add(take)
+			remaining = remaining.Sub(take)
+			queue[0].qty = queue[0].qty.Sub(take)
+			if !queue[0].qty.IsPositive() {
+				queue = queue[1:]
+			}
+		}
+		lots[o.Symbol] = queue
+
+		if !matched.IsPositive() {
+			continue // Sell of a position bought before the order window
+		}
+
+		entry := cost.Div(matched)
+		journal = append(journal, TradeRecord{
+			Ticker:     o.Symbol,
+			Qty:        matched,
+			EntryPrice: entry,
+			ExitPrice:  price,
+			PL:         price.Sub(entry).Mul(matched),
+			OpenedAt:   openedAt,
+			ClosedAt:   *o.FilledAt,
+		})
+	}
+
+	return journal, nil
+}
+
+// computeTradeStats derives win rate and payoff ratio from a journal.
+func computeTradeStats(journal []TradeRecord) TradeStats {
+	stats := TradeStats{Trades: len(journal)}
+	if len(journal) == 0 {
+		return stats
+	}
+
+	wins, losses := 0, 0
+	sumWin, sumLoss := decimal.Zero, decimal.Zero
+	for _, t := range journal {
+		if t.PL.IsPositive() {
+			wins++
+			sumWin = sumWin.Add(t.PL)
+		} else if t.PL.IsNegative() {
+			losses++
+			sumLoss = sumLoss.Add(t.PL.Abs())
+		}
+	}
+
+	stats.WinRate = decimal.NewFromInt(int64(wins)).Div(decimal.NewFromInt(int64(len(journal))))
+	if wins > 0 && losses > 0 {
+		avgWin := sumWin.Div(decimal.NewFromInt(int64(wins)))
+		avgLoss := sumLoss.Div(decimal.NewFromInt(int64(losses)))
+		stats.PayoffRatio = avgWin.Div(avgLoss)
+	}
+	return stats
+}
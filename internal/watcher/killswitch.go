@@ -0,0 +1,157 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// The max drawdown kill switch (MAX_DRAWDOWN_PCT): the peak equity is tracked in state,
+// and a fall of MAX_DRAWDOWN_PCT from it halts buys and the automatic AI analysis like
+// the daily loss breaker, but with no expiry: only /resume, confirmed with a button,
+// lifts it. The peak and both halts belong to the Alpaca account in use: /env parks
+// them with its positions (swapEnvLocked).
+
+const resumeConfirmTTL = 60 * time.Second // The RESUME button expires after this
+
+// pendingResumeConfirm is a /resume awaiting its button.
+type pendingResumeConfirm struct {
+	id string
+	at time.Time
+}
+
+// drawdownHalted reports whether the kill switch is engaged.
+func (w *Watcher) drawdownHalted() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state.DrawdownHalt != ""
+}
+
+// tradingHalt returns the rule and reason of the halt in force (kill switch first),
+// or "" when buying is allowed.
+func (w *Watcher) tradingHalt() (rule, reason string) {
+	if w.drawdownHalted() {
+		return "drawdown", fmt.Sprintf("max drawdown kill switch engaged (MAX_DRAWDOWN_PCT %.2f%%); /resume to lift it", w.config.MaxDrawdownPct)
+	}
+	if w.dailyLossHalted() {
+		return "daily_loss", fmt.Sprintf("daily loss circuit breaker tripped (MAX_DAILY_LOSS_PCT %.2f%%); buys resume next session", w.config.MaxDailyLossPct)
+	}
+	return "", ""
+}
+
+// checkDrawdown raises the peak equity and engages the kill switch when equity falls
+// MAX_DRAWDOWN_PCT below it.
+func (w *Watcher) checkDrawdown() {
+	if w.config.MaxDrawdownPct <= 0 {
+		return
+	}
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil || !equity.IsPositive() {
+		log.Printf("Kill Switch: equity unavailable: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	if equity.GreaterThan(w.state.PeakEquity) {
+		w.state.PeakEquity = equity
+		w.saveStateLocked()
+	}
+	peak := w.state.PeakEquity
+	if w.state.DrawdownHalt != "" {
+		w.mu.Unlock()
+		return
+	}
+	dd := peak.Sub(equity).Div(peak).Mul(decimal.NewFromInt(100))
+	if dd.LessThan(decimal.NewFromFloat(w.config.MaxDrawdownPct)) {
+		w.mu.Unlock()
+		return
+	}
+	w.state.DrawdownHalt = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
+	cancelled := len(w.pendingProposals)
+	w.pendingProposals = make(map[string]PendingProposal)
+	w.saveStateLocked()
+	w.mu.Unlock()
+	log.Printf("[KILL_SWITCH] Equity $%s is %s%% below the $%s peak (limit %.2f%%)", equity.StringFixed(2), dd.StringFixed(2), peak.StringFixed(2), w.config.MaxDrawdownPct)
+
+	msg := fmt.Sprintf("🛑 *KILL SWITCH ENGAGED*\nEquity: $%s, %s%% below the $%s peak (MAX_DRAWDOWN_PCT %.2f%%).\n\n"+
+		"⛔ New buys are blocked and the automatic AI analysis is paused. Stops, take-profits and sells keep working.\n"+
+		"This does not expire: review the account, then send /resume.",
+		equity.StringFixed(2), dd.StringFixed(2), peak.StringFixed(2), w.config.MaxDrawdownPct)
+	if cancelled > 0 {
+		msg += fmt.Sprintf("\n%d open buy proposal(s) cancelled.", cancelled)
+	}
+	telegram.Notify(msg)
+}
+
+// handleResumeCommand asks to lift the kill switch with a RESUME button.
+func (w *Watcher) handleResumeCommand() string {
+	w.mu.RLock()
+	halt, peak := w.state.DrawdownHalt, w.state.PeakEquity
+	w.mu.RUnlock()
+	if halt == "" {
+		if w.dailyLossHalted() {
+			return "ℹ️ The kill switch is not engaged. The daily loss circuit breaker is, and it lifts by itself at the next session."
+		}
+		return "✅ Trading is not halted."
+	}
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil {
+		return fmt.Sprintf("⚠️ Could not fetch equity: %v", err)
+	}
+
+	since := halt
+	if t, err := time.Parse(time.RFC3339, halt); err == nil {
+		since = t.In(config.CetLoc).Format("01-02 15:04")
+	}
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingResume = &pendingResumeConfirm{id: id, at: w.clock.Now()}
+	w.mu.Unlock()
+
+	telegram.SendInteractiveMessage(fmt.Sprintf("🛑 *RESUME TRADING?*\nHalted since %s CET. Equity: $%s | Peak: $%s\n\n"+
+		"Resuming unblocks buys and the AI analysis, and resets the peak to the current equity: the switch re-engages after a further %.2f%% fall.\nThis button expires in %d seconds.",
+		since, equity.StringFixed(2), peak.StringFixed(2), w.config.MaxDrawdownPct, int(resumeConfirmTTL.Seconds())),
+		[]telegram.Button{
+			{Text: "▶️ RESUME", CallbackData: "RESUME_YES_" + id},
+			{Text: "❌ STAY HALTED", CallbackData: "RESUME_NO_" + id},
+		})
+	return ""
+}
+
+// handleResumeCallback lifts the kill switch (RESUME_YES_<id>) or keeps it.
+func (w *Watcher) handleResumeCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid resume callback data."
+	}
+
+	w.mu.Lock()
+	pending := w.pendingResume
+	w.pendingResume = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > resumeConfirmTTL {
+		return "⚠️ Resume expired. Send /resume again."
+	}
+	if parts[1] != "YES" {
+		return "🛑 Still halted."
+	}
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil || !equity.IsPositive() {
+		return "⚠️ Could not fetch equity. Still halted."
+	}
+
+	w.mu.Lock()
+	w.state.DrawdownHalt = ""
+	w.state.PeakEquity = equity
+	w.saveStateLocked()
+	w.mu.Unlock()
+	log.Printf("[KILL_SWITCH] Lifted by the user; peak reset to $%s", equity.StringFixed(2))
+	return fmt.Sprintf("▶️ Trading resumed. Peak reset to $%s.", equity.StringFixed(2))
+}
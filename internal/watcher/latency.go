@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+)
+
+const latencyReportDays = 7
+
+// recordLatency stores the timing of an executed trigger. filledAt may be nil
+// (the confirmation time is used then).
+func (w *Watcher) recordLatency(pending PendingAction, confirmedAt time.Time, filledAt *time.Time, auto bool) {
+	r := models.LatencyRecord{
+		Ticker:      pending.Ticker,
+		Trigger:     pending.Trigger,
+		DetectedAt:  pending.DetectedAt,
+		AlertedAt:   pending.AlertedAt,
+		ConfirmedAt: confirmedAt,
+		FilledAt:    confirmedAt,
+		Auto:        auto,
+	}
+	if filledAt != nil {
+		r.FilledAt = *filledAt
+	}
+	if r.AlertedAt.IsZero() {
+		r.AlertedAt = pending.Timestamp
+	}
+	if r.DetectedAt.IsZero() {
+		r.DetectedAt = r.AlertedAt
+	}
+	if err := storage.AppendLatency(r); err != nil {
+		log.Printf("ERROR: Failed to record latency for %s: %v", r.Ticker, err)
+		return
+	}
+	log.Printf("[LATENCY] %s %s: detect->alert %s | alert->confirm %s | confirm->fill %s",
+		r.Ticker, r.Trigger, r.AlertedAt.Sub(r.DetectedAt).Round(time.Second),
+		r.ConfirmedAt.Sub(r.AlertedAt).Round(time.Second), r.FilledAt.Sub(r.ConfirmedAt).Round(time.Second))
+}
+
+// percentile returns the nearest-rank percentile (p in 0-100) of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p/100*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// latencyReport summarizes p50/p95 per stage over the last days.
+func (w *Watcher) latencyReport(days int) string {
+	records, err := storage.LoadLatency(w.clock.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read latency log: %v", err)
+	}
+	if len(records) == 0 {
+		return fmt.Sprintf("⏱️ No executed triggers in the last %d days.", days)
+	}
+
+	stages := []struct {
+		name string
+		span func(r models.LatencyRecord) time.Duration
+	}{
+		{"Detect → Alert", func(r models.LatencyRecord) time.Duration { return r.AlertedAt.Sub(r.DetectedAt) }},
+		{"Alert → Confirm", func(r models.LatencyRecord) time.Duration { return r.ConfirmedAt.Sub(r.AlertedAt) }},
+		{"Confirm → Fill", func(r models.LatencyRecord) time.Duration { return r.FilledAt.Sub(r.ConfirmedAt) }},
+		{"Total", func(r models.LatencyRecord) time.Duration { return r.FilledAt.Sub(r.DetectedAt) }},
+	}
+
+	auto := 0
+	for _, r := range records {
+		if r.Auto {
+			auto++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("⏱️ *TRIGGER LATENCY (last %d days)*\nExecutions: %d (%d by vacation policy)\n", days, len(records), auto))
+	for _, st := range stages {
+		var spans []time.Duration
+		for _, r := range records {
+			spans = append(spans, st.span(r))
+		}
+		sort.Slice(spans, func(i, j int) bool { return spans[i] < spans[j] })
+		sb.WriteString(fmt.Sprintf("`%-15s p50 %8s | p95 %8s`\n", st.name,
+			percentile(spans, 50).Round(time.Second), percentile(spans, 95).Round(time.Second)))
+	}
+	sb.WriteString(fmt.Sprintf("\nPoll interval: %dm (bounds Detect → Alert).", w.config.PollIntervalMins))
+	return sb.String()
+}
+
+// handleLatencyCommand shows the latency report. /latency [days]
+func (w *Watcher) handleLatencyCommand(parts []string) string {
+	days := latencyReportDays
+	if len(parts) >= 2 {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(parts[1]), "d"))
+		if err != nil || n <= 0 {
+			return "Usage: /latency [days]"
+		}
+		days = n
+	}
+	return w.latencyReport(days)
+}
+
+// checkWeeklyLatencyReport sends the latency report once every 7 days (if there were
+// executions). The week is only marked as sent once the report went out, so a report
+// muted by quiet hours is retried on a later poll.
+func (w *Watcher) checkWeeklyLatencyReport() {
+	w.mu.RLock()
+	last := w.state.LastLatencyReport
+	w.mu.RUnlock()
+
+	if t, err := time.Parse(time.RFC3339, last); err == nil && w.since(t) < latencyReportDays*24*time.Hour {
+		return
+	}
+
+	if records, err := storage.LoadLatency(w.clock.Now().AddDate(0, 0, -latencyReportDays)); err == nil && len(records) > 0 {
+		if !w.notifyRoutine(w.latencyReport(latencyReportDays)) {
+			return
+		}
+	}
+
+	w.mu.Lock()
+	w.state.LastLatencyReport = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
+	w.saveStateLocked()
+	w.mu.Unlock()
+}
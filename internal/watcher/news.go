@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/symbols"
+)
+
+const (
+	newsDefaultCount = 5
+	newsMaxCount     = 20
+	newsAIMaxAge     = 72 * time.Hour // Older headlines are left out of the AI snapshot
+)
+
+// newsText strips the characters Telegram's Markdown would read as formatting.
+var newsText = strings.NewReplacer("*", "", "_", " ", "`", "'", "[", "(", "]", ")")
+
+// handleNewsCommand lists the latest headlines for a ticker. /news <ticker> [count]
+func (w *Watcher) handleNewsCommand(parts []string) string {
+	if len(parts) < 2 {
+		return "Usage: /news <ticker> [count]"
+	}
+	ticker := symbols.Normalize(parts[1])
+	count := newsDefaultCount
+	if len(parts) >= 3 {
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n <= 0 {
+			return "Usage: /news <ticker> [count]"
+		}
+		count = min(n, newsMaxCount)
+	}
+
+	articles, err := w.provider.GetNews(w.ctx, ticker, count)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not fetch news for %s: %v", ticker, err)
+	}
+	if len(articles) == 0 {
+		return fmt.Sprintf("📰 No recent news for %s.", ticker)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📰 *%s NEWS*\n", ticker))
+	for _, a := range articles {
+		sb.WriteString(fmt.Sprintf("\n• `%s` %s", a.CreatedAt.In(config.CetLoc).Format("01-02 15:04"), newsText.Replace(a.Headline)))
+		if a.URL != "" {
+			sb.WriteString(fmt.Sprintf(" [link](%s)", a.URL))
+		}
+	}
+	return sb.String()
+}
+
+// newsHeadlines returns up to AI_NEWS_HEADLINES recent headlines for the held tickers
+// and the focus ticker. Tickers whose news cannot be fetched are left out.
+func (w *Watcher) newsHeadlines(focus string) map[string][]ai.Headline {
+	n := w.config.AINewsHeadlines
+	if n <= 0 {
+		return nil
+	}
+	cutoff := w.clock.Now().Add(-newsAIMaxAge)
+	out := make(map[string][]ai.Headline)
+	for _, t := range w.snapshotTickers(focus) {
+		articles, err := w.provider.GetNews(w.ctx, t, n)
+		if err != nil {
+			log.Printf("Snapshot Warning: news for %s: %v", t, err)
+			continue
+		}
+		for _, a := range articles {
+			if a.CreatedAt.Before(cutoff) {
+				continue
+			}
+			out[t] = append(out[t], ai.Headline{Time: a.CreatedAt.UTC().Format(time.RFC3339), Headline: a.Headline})
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
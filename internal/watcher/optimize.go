@@ -0,0 +1,232 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"alpha_trading/internal/backtest"
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+)
+
+const (
+	optimizeDefaultBars = 250 // ~1 trading year
+	optimizeMaxBars     = 1000
+	optimizeTrainFrac   = 0.7 // First 70% fits, last 30% validates
+	optimizeTop         = 5
+
+	walkDefaultBars  = 500 // ~2 trading years
+	walkDefaultFolds = 4
+	walkMaxFolds     = 10
+	walkTrainSegs    = 2 // Train window = 2 segments, test window = the next one
+)
+
+// handleOptimizeCommand sweeps SL/TP/TS ranges through the backtester.
+// /optimize [bars]              -> single train/validate split
+// /optimize walk [bars] [folds] -> rolling walk-forward with a stability report
+func (w *Watcher) handleOptimizeCommand(parts []string) string {
+	if len(parts) >= 2 && strings.ToLower(parts[1]) == "walk" {
+		return w.handleWalkForward(parts[2:])
+	}
+	bars := optimizeDefaultBars
+	if len(parts) >= 2 {
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 20 || n > optimizeMaxBars {
+			return fmt.Sprintf("Usage: /optimize [bars] (20-%d daily bars, default %d)", optimizeMaxBars, optimizeDefaultBars)
+		}
+		bars = n
+	}
+
+	grid, err := w.optimizeGrid()
+	if err != nil {
+		return fmt.Sprintf("⚠️ %v", err)
+	}
+	tickers := w.historicalTickers()
+	if len(tickers) == 0 {
+		return "📉 No historical tickers (trade archive and positions are empty)."
+	}
+
+	go func() {
+		telegram.Notify(w.optimizeReport(tickers, bars, grid))
+	}()
+	return fmt.Sprintf("⏳ Optimizing %d parameter sets over %d tickers (%d daily bars)...", len(grid), len(tickers), bars)
+}
+
+func (w *Watcher) optimizeGrid() ([]backtest.Params, error) {
+	sl, err := backtest.ParseRange(w.config.OptimizeSLRange)
+	if err != nil {
+		return nil, fmt.Errorf("OPTIMIZE_SL_RANGE: %v", err)
+	}
+	tp, err := backtest.ParseRange(w.config.OptimizeTPRange)
+	if err != nil {
+		return nil, fmt.Errorf("OPTIMIZE_TP_RANGE: %v", err)
+	}
+	ts, err := backtest.ParseRange(w.config.OptimizeTSRange)
+	if err != nil {
+		return nil, fmt.Errorf("OPTIMIZE_TS_RANGE: %v", err)
+	}
+	return backtest.Grid(sl, tp, ts), nil
+}
+
+// historicalTickers lists every symbol traded (trade archive) or held now.
+func (w *Watcher) historicalTickers() []string {
+	seen := make(map[string]bool)
+	if trades, err := storage.LoadArchive(); err == nil {
+		for _, t := range trades {
+			seen[t.Ticker] = true
+		}
+	}
+	w.mu.RLock()
+	for _, p := range w.state.Positions {
+		seen[p.Ticker] = true
+	}
+	w.mu.RUnlock()
+
+	var out []string
+	for t := range seen {
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// loadSeries fetches daily bars per ticker, skipping symbols without enough history.
+func (w *Watcher) loadSeries(tickers []string, bars int) (map[string][]marketdata.Bar, []string) {
+	series := make(map[string][]marketdata.Bar)
+	var skipped []string
+	for _, t := range tickers {
+		b, err := w.provider.GetBars(w.ctx, t, bars)
+		if err != nil || len(b) < 20 {
+			log.Printf("Optimize: skipping %s (%d bars, err: %v)", t, len(b), err)
+			skipped = append(skipped, t)
+			continue
+		}
+		series[t] = b
+	}
+	return series, skipped
+}
+
+func (w *Watcher) optimizeReport(tickers []string, bars int, grid []backtest.Params) string {
+	series, skipped := w.loadSeries(tickers, bars)
+	if len(series) == 0 {
+		return "❌ Optimize: no price history available."
+	}
+
+	candidates := backtest.Optimize(series, grid, optimizeTrainFrac, optimizeTop)
+	current := backtest.Params{
+		StopLossPct:     w.config.DefaultStopLossPct,
+		TakeProfitPct:   w.config.DefaultTakeProfitPct,
+		TrailingStopPct: w.config.DefaultTrailingStopPct,
+	}
+	train, test := backtest.Split(series, optimizeTrainFrac)
+	baseIn, baseOut := backtest.Evaluate(train, current), backtest.Evaluate(test, current)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧪 *PARAMETER OPTIMIZATION* (%d tickers, %d bars, %d sets)\n", len(series), bars, len(grid)))
+	sb.WriteString(fmt.Sprintf("Fit on the first %.0f%% of each series, validated on the rest.\n", optimizeTrainFrac*100))
+	sb.WriteString("\n*Top Sets (in-sample → out-of-sample)*\n")
+	for i, c := range candidates {
+		sb.WriteString(fmt.Sprintf("%d. %s\n   %s → %s\n", i+1, c.InSample.Params, formatBacktest(c.InSample), formatBacktest(c.OutSample)))
+	}
+	sb.WriteString(fmt.Sprintf("\n*Current* (%s)\n   %s → %s\n", current, formatBacktest(baseIn), formatBacktest(baseOut)))
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("\nSkipped (no history): %s", strings.Join(skipped, ", ")))
+	}
+	sb.WriteString("\n_Always-invested daily-bar simulation; ignores fees, slippage and the AI entries._")
+	return sb.String()
+}
+
+func (w *Watcher) handleWalkForward(args []string) string {
+	bars, folds := walkDefaultBars, walkDefaultFolds
+	if len(args) >= 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 60 || n > optimizeMaxBars {
+			return fmt.Sprintf("Usage: /optimize walk [bars] [folds] (60-%d bars, default %d; 2-%d folds, default %d)", optimizeMaxBars, walkDefaultBars, walkMaxFolds, walkDefaultFolds)
+		}
+		bars = n
+	}
+	if len(args) >= 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 2 || n > walkMaxFolds {
+			return fmt.Sprintf("Usage: /optimize walk [bars] [folds] (2-%d folds)", walkMaxFolds)
+		}
+		folds = n
+	}
+
+	grid, err := w.optimizeGrid()
+	if err != nil {
+		return fmt.Sprintf("⚠️ %v", err)
+	}
+	tickers := w.historicalTickers()
+	if len(tickers) == 0 {
+		return "📉 No historical tickers (trade archive and positions are empty)."
+	}
+
+	go func() {
+		telegram.Notify(w.walkForwardReport(tickers, bars, folds, grid))
+	}()
+	return fmt.Sprintf("⏳ Walk-forward: %d folds × %d parameter sets over %d tickers (%d daily bars)...", folds, len(grid), len(tickers), bars)
+}
+
+func (w *Watcher) walkForwardReport(tickers []string, bars, folds int, grid []backtest.Params) string {
+	series, skipped := w.loadSeries(tickers, bars)
+	if len(series) == 0 {
+		return "❌ Walk-forward: no price history available."
+	}
+
+	current := backtest.Params{
+		StopLossPct:     w.config.DefaultStopLossPct,
+		TakeProfitPct:   w.config.DefaultTakeProfitPct,
+		TrailingStopPct: w.config.DefaultTrailingStopPct,
+	}
+	results := backtest.WalkForward(series, grid, current, folds, walkTrainSegs)
+	if len(results) == 0 {
+		return fmt.Sprintf("❌ Walk-forward: %d bars is too short for %d folds.", bars, folds)
+	}
+	s := backtest.Summarize(results)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🚶 *WALK-FORWARD* (%d tickers, %d bars, %d folds)\n", len(series), bars, len(results)))
+	sb.WriteString("Each fold fits on the previous two windows and trades the next one blind.\n")
+	sb.WriteString("\n*Folds (test window: fitted set → out-of-sample | current)*\n")
+	for i, f := range results {
+		sb.WriteString(fmt.Sprintf("%d. %s → %s\n   %s\n   %s | %+.1f%%\n", i+1,
+			f.TestStart.In(config.CetLoc).Format("2006-01-02"), f.TestEnd.In(config.CetLoc).Format("2006-01-02"),
+			f.Best.InSample.Params, formatBacktest(f.Best.OutSample), f.Baseline.ReturnPct))
+	}
+
+	sb.WriteString("\n*Stability*\n")
+	sb.WriteString(fmt.Sprintf("SL %s | TP %s | TS %s\n", spread(s.SL), spread(s.TP), spread(s.TS)))
+	sb.WriteString(fmt.Sprintf("Most chosen: %s (%d/%d folds)\n", s.Mode, s.ModeCount, len(results)))
+	sb.WriteString(fmt.Sprintf("Mean return: in-sample %+.1f%% → out-of-sample %+.1f%% (current %+.1f%%)\n", s.MeanIn, s.MeanOut, s.MeanBaseline))
+	switch {
+	case s.MeanIn <= 0:
+		sb.WriteString("Efficiency: n/a (no in-sample edge)")
+	case s.Efficiency >= 0.5:
+		sb.WriteString(fmt.Sprintf("Efficiency: %.2f ✅ holds up out-of-sample", s.Efficiency))
+	default:
+		sb.WriteString(fmt.Sprintf("Efficiency: %.2f ⚠️ likely curve-fit", s.Efficiency))
+	}
+	if len(skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("\n\nSkipped (no history): %s", strings.Join(skipped, ", ")))
+	}
+	return sb.String()
+}
+
+// spread renders a min/max pair as "5.0%" or "3.0–8.0%".
+func spread(r [2]float64) string {
+	if r[0] == r[1] {
+		return fmt.Sprintf("%.1f%%", r[0])
+	}
+	return fmt.Sprintf("%.1f–%.1f%%", r[0], r[1])
+}
+
+func formatBacktest(r backtest.Result) string {
+	return fmt.Sprintf("%+.1f%% (%d trades, %.0f%% win, DD %.1f%%)", r.ReturnPct, r.Trades, r.WinRate()*100, r.MaxDDPct)
+}
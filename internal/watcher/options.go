@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// occSymbol matches an OCC option symbol: root, YYMMDD expiry, C/P, strike x 1000 (8 digits).
+var occSymbol = regexp.MustCompile(`^([A-Z0-9.]{1,6})(\d{6})([CP])(\d{8})$`)
+
+// parseOCC splits an OCC option symbol (e.g. AAPL240119C00150000) into its parts.
+func parseOCC(symbol string) (models.OptionPosition, bool) {
+	m := occSymbol.FindStringSubmatch(strings.ToUpper(strings.ReplaceAll(symbol, " ", "")))
+	if m == nil {
+		return models.OptionPosition{}, false
+	}
+	expiry, err := time.Parse("060102", m[2])
+	if err != nil {
+		return models.OptionPosition{}, false
+	}
+	strike, err := decimal.NewFromString(m[4])
+	if err != nil {
+		return models.OptionPosition{}, false
+	}
+	kind := "call"
+	if m[3] == "P" {
+		kind = "put"
+	}
+	return models.OptionPosition{
+		Symbol:     m[0],
+		Underlying: m[1],
+		Type:       kind,
+		Strike:     strike.Div(decimal.NewFromInt(1000)),
+		Expiry:     expiry.Format("2006-01-02"),
+	}, true
+}
+
+// daysToExpiry counts calendar days from today (ET) to the option's expiry date.
+func daysToExpiry(expiry string, now time.Time) (int, bool) {
+	exp, err := time.ParseInLocation("2006-01-02", expiry, easternLoc())
+	if err != nil {
+		return 0, false
+	}
+	et := now.In(easternLoc())
+	today := time.Date(et.Year(), et.Month(), et.Day(), 0, 0, 0, 0, easternLoc())
+	return int(exp.Sub(today).Hours() / 24), true
+}
+
+// optionFromBroker maps a broker option position, keeping the alert day from prev.
+func optionFromBroker(p alpaca.Position, prev map[string]models.OptionPosition) (models.OptionPosition, bool) {
+	o, ok := parseOCC(p.Symbol)
+	if !ok {
+		return o, false
+	}
+	o.Quantity = p.Qty
+	if p.Side == "short" && o.Quantity.IsPositive() {
+		o.Quantity = o.Quantity.Neg()
+	}
+	o.AvgEntryPrice = p.AvgEntryPrice
+	if p.CurrentPrice != nil {
+		o.CurrentPrice = *p.CurrentPrice
+	}
+	if p.MarketValue != nil {
+		o.MarketValue = *p.MarketValue
+	}
+	if p.UnrealizedPL != nil {
+		o.UnrealizedPL = *p.UnrealizedPL
+	}
+	o.LastExpiryAlert = prev[o.Symbol].LastExpiryAlert
+	return o, true
+}
+
+// refreshOptions mirrors the broker's option positions into state and warns about
+// contracts expiring within OPTIONS_EXPIRY_ALERT_DAYS (once per day each).
+func (w *Watcher) refreshOptions() {
+	if !w.config.OptionsEnabled {
+		return
+	}
+	alp := w.alpacaProvider()
+	if alp == nil {
+		return
+	}
+	positions, err := alp.ListOptionPositions(w.ctx)
+	if err != nil {
+		log.Printf("Options: could not list positions: %v", err)
+		return
+	}
+
+	now := w.clock.Now()
+	today := now.In(easternLoc()).Format("2006-01-02")
+	var alerts []string
+
+	w.mu.Lock()
+	prev := make(map[string]models.OptionPosition, len(w.state.Options))
+	for _, o := range w.state.Options {
+		prev[o.Symbol] = o
+	}
+	var options []models.OptionPosition
+	for _, p := range positions {
+		o, ok := optionFromBroker(p, prev)
+		if !ok {
+			log.Printf("Options: skipping unrecognized symbol %s", p.Symbol)
+			continue
+		}
+		if days, ok := daysToExpiry(o.Expiry, now); ok && days <= w.config.OptionsExpiryAlertDays && o.LastExpiryAlert != today {
+			o.LastExpiryAlert = today
+			alerts = append(alerts, fmt.Sprintf("• %s x%s %s", optionLabel(o), o.Quantity.String(), expiryIn(days)))
+		}
+		options = append(options, o)
+	}
+	w.state.Options = options
+	if len(options) > 0 || len(prev) > 0 {
+		w.saveStateLocked()
+	}
+	w.mu.Unlock()
+
+	if len(alerts) > 0 {
+		telegram.Notify(fmt.Sprintf("⏰ *OPTIONS EXPIRING*\n%s\n\nClose or roll them at the broker: the watcher does not trade options.", strings.Join(alerts, "\n")))
+	}
+}
+
+// optionLabel renders a contract as "AAPL 150C 2024-01-19".
+func optionLabel(o models.OptionPosition) string {
+	return fmt.Sprintf("%s %s%s %s", o.Underlying, o.Strike.String(), strings.ToUpper(o.Type[:1]), o.Expiry)
+}
+
+func expiryIn(days int) string {
+	switch {
+	case days < 0:
+		return "expired"
+	case days == 0:
+		return "expires today"
+	case days == 1:
+		return "expires in 1 day"
+	}
+	return fmt.Sprintf("expires in %d days", days)
+}
+
+// formatOptionPositions lists the tracked option positions ("" when none).
+func (w *Watcher) formatOptionPositions() string {
+	w.mu.RLock()
+	options := append([]models.OptionPosition(nil), w.state.Options...)
+	w.mu.RUnlock()
+	if len(options) == 0 {
+		return ""
+	}
+
+	now := w.clock.Now()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*%s*", w.tr("Options")))
+	for _, o := range options {
+		expiry := ""
+		if days, ok := daysToExpiry(o.Expiry, now); ok {
+			expiry = " | " + expiryIn(days)
+			if days <= w.config.OptionsExpiryAlertDays {
+				expiry += " ⏰"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\n• %s x%s @ $%s | P/L: %s%s",
+			optionLabel(o), o.Quantity.String(), o.CurrentPrice.StringFixed(2), plString(o.UnrealizedPL), expiry))
+	}
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// The Pattern Day Trader guard (PDT_GUARD): a margin account under $25k that makes a
+// 4th day trade within five sessions gets flagged. With the broker's day trade count
+// at 3, a discretionary sell (/sell, AI batch, rotation) of a position opened today
+// is blocked or needs a second confirmation. Stop, take-profit and trailing exits are
+// never held back: a flag costs less than an unprotected position.
+
+const (
+	pdtDaytradeLimit = 3                // Day trades allowed in five sessions
+	pdtConfirmTTL    = 60 * time.Second // The SELL ANYWAY button expires after this
+)
+
+var pdtEquityMin = decimal.NewFromInt(25000) // The rule does not apply at or above this
+
+// pendingPDTSell is a /sell held back by the guard, awaiting SELL ANYWAY.
+type pendingPDTSell struct {
+	id     string
+	at     time.Time
+	ticker string
+}
+
+// pdtRisk returns why selling ticker now would be a flaggable day trade, or "".
+func (w *Watcher) pdtRisk(ticker string) string {
+	if w.config.PDTGuard == "off" || symbols.IsCrypto(ticker) {
+		return ""
+	}
+	today := w.clock.Now().In(easternLoc()).Format("2006-01-02")
+	w.mu.RLock()
+	openedToday := false
+	for _, p := range w.state.Positions {
+		if p.Ticker == ticker && p.Status == "ACTIVE" && p.OpenedAt.In(easternLoc()).Format("2006-01-02") == today {
+			openedToday = true
+		}
+	}
+	w.mu.RUnlock()
+	if !openedToday {
+		return ""
+	}
+
+	acct, err := w.provider.GetAccount(w.ctx)
+	if err != nil {
+		log.Printf("PDT Guard: account unavailable: %v", err)
+		return ""
+	}
+	if acct.DaytradeCount < pdtDaytradeLimit || !acct.Equity.LessThan(pdtEquityMin) {
+		return ""
+	}
+	return fmt.Sprintf("%s was bought today and the account already has %d day trades in the last five sessions (equity $%s, under $%s): selling now would flag it as a Pattern Day Trader",
+		ticker, acct.DaytradeCount, acct.Equity.StringFixed(2), pdtEquityMin.StringFixed(0))
+}
+
+// guardPDTSell applies PDT_GUARD to a /sell. ok means the sell may go ahead; otherwise
+// msg says why it was blocked, or that it is held until SELL ANYWAY is tapped.
+func (w *Watcher) guardPDTSell(ticker string) (string, bool) {
+	reason := w.pdtRisk(ticker)
+	if reason == "" {
+		return "", true
+	}
+	log.Printf("[PDT_GUARD] %s", reason)
+	if w.config.PDTGuard == "block" {
+		return fmt.Sprintf("⛔ *PDT GUARD*\n%s.\n\nSell tomorrow, or set PDT_GUARD=confirm to allow it with a second tap. Stops still execute.", reason), false
+	}
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingPDT = &pendingPDTSell{id: id, at: w.clock.Now(), ticker: ticker}
+	w.mu.Unlock()
+	telegram.SendInteractiveMessage(fmt.Sprintf("⚠️ *PDT GUARD*\n%s.\n\nSell anyway?\nThis button expires in %d seconds.", reason, int(pdtConfirmTTL.Seconds())),
+		[]telegram.Button{
+			{Text: "📉 SELL ANYWAY", CallbackData: "PDT_SELL_" + id},
+			{Text: "❌ KEEP", CallbackData: "PDT_KEEP_" + id},
+		})
+	return fmt.Sprintf("⏸️ Sell of %s held by the PDT guard until SELL ANYWAY is tapped.", ticker), false
+}
+
+// handlePDTCallback runs the held-back sell (PDT_SELL_<id>) or drops it.
+func (w *Watcher) handlePDTCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid PDT callback data."
+	}
+	w.mu.Lock()
+	pending := w.pendingPDT
+	w.pendingPDT = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > pdtConfirmTTL {
+		return "⚠️ Confirmation expired. Send the /sell again."
+	}
+	if parts[1] != "SELL" {
+		return fmt.Sprintf("✅ Kept %s. No day trade made.", pending.ticker)
+	}
+	log.Printf("[PDT_GUARD] Sell of %s confirmed by the user", pending.ticker)
+	msg, _ := w.sellPosition(pending.ticker)
+	return msg
+}
@@ -0,0 +1,147 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// trackPendingOrder records a confirmed buy that has not filled yet, with the
+// levels from its proposal, so checkPendingOrders can open the position later.
+func (w *Watcher) trackPendingOrder(order *alpaca.Order, proposal PendingProposal, thesisID string) {
+	po := models.PendingOrder{
+		OrderID:         order.ID,
+		Ticker:          proposal.Ticker,
+		Qty:             proposal.Qty,
+		StopLoss:        proposal.StopLoss,
+		TakeProfit:      proposal.TakeProfit,
+		TrailingStopPct: proposal.TrailingStopPct,
+		ThesisID:        thesisID,
+		Book:            proposal.Book,
+		SubmittedAt:     w.clock.Now(),
+	}
+	if order.LimitPrice != nil {
+		po.LimitPrice = *order.LimitPrice
+	}
+	w.mu.Lock()
+	w.state.PendingOrders = append(w.state.PendingOrders, po)
+	w.saveStateLocked()
+	w.mu.Unlock()
+}
+
+// pendingOrderLocked returns the tracked order for ticker, if any. Caller holds w.mu.
+func (w *Watcher) pendingOrderLocked(ticker string) (models.PendingOrder, bool) {
+	for _, po := range w.state.PendingOrders {
+		if po.Ticker == ticker {
+			return po, true
+		}
+	}
+	return models.PendingOrder{}, false
+}
+
+// checkPendingOrders polls every tracked order once. Fills open the position with
+// the confirmed levels; cancelled, expired and rejected orders are dropped with a notice.
+func (w *Watcher) checkPendingOrders() {
+	w.mu.RLock()
+	orders := append([]models.PendingOrder(nil), w.state.PendingOrders...)
+	w.mu.RUnlock()
+
+	for _, po := range orders {
+		order, err := w.provider.GetOrder(w.ctx, po.OrderID)
+		if err != nil {
+			log.Printf("Pending order %s (%s): status check failed: %v", po.OrderID, po.Ticker, err)
+			continue
+		}
+		filled := decimal.Zero
+		if order.FilledQty.IsPositive() {
+			filled = order.FilledQty
+		}
+
+		switch status := strings.ToLower(order.Status); status {
+		case "filled":
+			w.settlePendingOrder(po, order, filled)
+			telegram.Notify(fmt.Sprintf("✅ *ORDER FILLED*: %s %s @ $%s (%s)\nSL: $%s | TP: $%s\nTracking Active.",
+				filled.String(), po.Ticker, orderFillPrice(order).StringFixed(2), orderKind(order), po.StopLoss.StringFixed(2), po.TakeProfit.StringFixed(2)))
+		case "canceled", "expired", "rejected":
+			if filled.IsPositive() {
+				w.settlePendingOrder(po, order, filled) // Partial fill before the order ended
+			} else {
+				w.dropPendingOrder(po.OrderID)
+				w.recordAudit(po.Ticker, auditCancelled, "SYSTEM", po.LimitPrice, "order "+status)
+			}
+			telegram.Notify(fmt.Sprintf("⚠️ *ORDER %s*: %s buy of %s (%s). Filled: %s.",
+				strings.ToUpper(status), po.Ticker, po.Qty.String(), orderKind(order), filled.String()))
+		}
+	}
+}
+
+// settlePendingOrder opens (or keeps) the position for a filled tracked order and
+// stops tracking it. A JIT sync may already have imported the position with the
+// same levels (see SyncWithBroker).
+func (w *Watcher) settlePendingOrder(po models.PendingOrder, order *alpaca.Order, filled decimal.Decimal) {
+	entry := orderFillPrice(order)
+	w.mu.Lock()
+	exists := false
+	for _, p := range w.state.Positions {
+		if p.Ticker == po.Ticker && p.Status == "ACTIVE" {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		w.state.Positions = append(w.state.Positions, models.Position{
+			Ticker:          po.Ticker,
+			Quantity:        filled,
+			EntryPrice:      entry,
+			StopLoss:        po.StopLoss,
+			TakeProfit:      po.TakeProfit,
+			Status:          "ACTIVE",
+			HighWaterMark:   entry,
+			TrailingStopPct: po.TrailingStopPct,
+			ThesisID:        po.ThesisID,
+			OpenedAt:        w.clock.Now(),
+			Book:            po.Book,
+		})
+	}
+	w.removePendingOrderLocked(po.OrderID)
+	w.saveStateLocked()
+	w.mu.Unlock()
+	w.recordAudit(po.Ticker, auditFilled, "USER", entry, fmt.Sprintf("bought %s (%s)", filled.String(), orderKind(order)))
+	w.protectAtBroker(po.Ticker)
+}
+
+// dropPendingOrder stops tracking an order.
+func (w *Watcher) dropPendingOrder(orderID string) {
+	w.mu.Lock()
+	w.removePendingOrderLocked(orderID)
+	w.saveStateLocked()
+	w.mu.Unlock()
+}
+
+// removePendingOrderLocked deletes an order from the tracked list. Caller holds w.mu.
+func (w *Watcher) removePendingOrderLocked(orderID string) {
+	kept := []models.PendingOrder{}
+	for _, po := range w.state.PendingOrders {
+		if po.OrderID != orderID {
+			kept = append(kept, po)
+		}
+	}
+	w.state.PendingOrders = kept
+}
+
+// orderFillPrice is the average fill price, or the limit when the broker has not reported one.
+func orderFillPrice(o *alpaca.Order) decimal.Decimal {
+	if o.FilledAvgPrice != nil {
+		return *o.FilledAvgPrice
+	}
+	if o.LimitPrice != nil {
+		return *o.LimitPrice
+	}
+	return decimal.Zero
+}
@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/compliance"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// checkCompliance evaluates the pre-trade rules (COMPLIANCE_RULES_FILE) for a buy.
+// A zero price is fetched first. Returns the rejection message and false on any rule hit.
+// Protective exits (SL/TP/TS, /sell) are never subject to these rules.
+func (w *Watcher) checkCompliance(ticker string, qty, price decimal.Decimal) (string, bool) {
+	if price.IsZero() {
+		if p, err := w.provider.GetPrice(market.Fresh(w.ctx), ticker); err == nil {
+			price = p
+		}
+	}
+
+	name := ""
+	if asset, err := w.provider.GetAsset(w.ctx, ticker); err == nil && asset != nil {
+		name = asset.Name
+	} else if info, ok := w.metadata.Lookup(ticker); ok {
+		name = info.Name
+	}
+
+	violations := w.rules.Evaluate(compliance.Order{
+		Ticker:    ticker,
+		AssetName: name,
+		Value:     qty.Mul(price),
+		SinceOpen: w.timeSinceOpen(),
+	})
+	if w.isBlocked(ticker) {
+		violations = append(violations, compliance.Violation{Rule: "blocklist", Reason: ticker + " is blocked (/unblock to allow)"})
+	}
+	if rule, reason := w.tradingHalt(); rule != "" {
+		violations = append(violations, compliance.Violation{Rule: rule, Reason: reason})
+	}
+	if w.config.SectorCapMode != "warn" {
+		if reason := w.sectorCapCheck(ticker, qty.Mul(price)); reason != "" {
+			violations = append(violations, compliance.Violation{Rule: "sector_cap", Reason: reason})
+		}
+	}
+	if len(violations) == 0 {
+		return "", true
+	}
+
+	msg := compliance.Format(ticker, violations)
+	log.Printf("[COMPLIANCE_REJECTION] %s: %v", ticker, violations)
+	w.recordAudit(ticker, auditCancelled, "SYSTEM", price, "compliance: "+violations[0].Reason)
+	return msg, false
+}
+
+// isBlocked reports whether ticker is on the /block list.
+func (w *Watcher) isBlocked(ticker string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return contains(w.state.Blocklist, ticker)
+}
+
+// handleBlockCommand manages the persisted blocklist.
+// /block            -> list blocked tickers
+// /block TICKER     -> block
+// /unblock TICKER   -> unblock
+func (w *Watcher) handleBlockCommand(parts []string) string {
+	block := strings.ToLower(parts[0]) == "/block"
+	if len(parts) < 2 {
+		if !block {
+			return "Usage: /unblock <ticker>"
+		}
+		w.mu.RLock()
+		list := append([]string(nil), w.state.Blocklist...)
+		w.mu.RUnlock()
+		return fmt.Sprintf("⛔ *BLOCKLIST*\n%s", joinOrNone(list))
+	}
+	ticker := symbols.Normalize(parts[1])
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	listed := contains(w.state.Blocklist, ticker)
+	if block {
+		if listed {
+			return fmt.Sprintf("ℹ️ %s is already blocked.", ticker)
+		}
+		w.state.Blocklist = append(w.state.Blocklist, ticker)
+		w.saveStateLocked()
+		return fmt.Sprintf("⛔ %s blocked. Manual, AI and rotation buys will be rejected.", ticker)
+	}
+	if !listed {
+		return fmt.Sprintf("ℹ️ %s is not blocked.", ticker)
+	}
+	kept := []string{}
+	for _, t := range w.state.Blocklist {
+		if t != ticker {
+			kept = append(kept, t)
+		}
+	}
+	w.state.Blocklist = kept
+	w.saveStateLocked()
+	return fmt.Sprintf("✅ %s unblocked.", ticker)
+}
+
+// timeSinceOpen is the time elapsed since today's session open, or -1 when the
+// market is closed or the session cannot be determined.
+func (w *Watcher) timeSinceOpen() time.Duration {
+	clock, err := w.provider.GetClock(w.ctx)
+	if err != nil || !clock.IsOpen {
+		return -1
+	}
+	now := w.clock.Now()
+	days, err := w.provider.GetCalendar(w.ctx, now, now)
+	if err != nil {
+		return -1
+	}
+	today := now.In(easternLoc()).Format("2006-01-02")
+	for _, d := range days {
+		if d.Date != today {
+			continue
+		}
+		if open, err := sessionOpen(d); err == nil {
+			return now.Sub(open)
+		}
+	}
+	return -1
+}
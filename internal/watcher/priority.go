@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/symbols"
+)
+
+// Position check priorities (/priority). Normal positions are checked every poll.
+const (
+	priorityHot  = "hot"  // Also checked every HOT_POLL_INTERVAL_SEC between polls
+	prioritySlow = "slow" // Checked every SLOW_POLL_EVERY polls only
+)
+
+var priorityIcons = map[string]string{priorityHot: "🔥", prioritySlow: "🐢"}
+
+// riskDue reports whether pos is checked in this risk pass. A hot pass checks only hot
+// positions, and equities only while the market is open. A poll skips slow positions
+// except every SLOW_POLL_EVERY-th one (the first poll checks everything).
+// Caller holds w.mu.
+func (w *Watcher) riskDue(pos models.Position, hotPass bool) bool {
+	if hotPass {
+		return pos.Priority == priorityHot && (w.wasMarketOpen || symbols.IsCrypto(pos.Ticker))
+	}
+	if pos.Priority == prioritySlow && w.config.SlowPollEvery > 1 {
+		return (w.polls-1)%w.config.SlowPollEvery == 0
+	}
+	return true
+}
+
+// hasHotPositions reports whether any active position is marked hot.
+func (w *Watcher) hasHotPositions() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && p.Priority == priorityHot {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHot runs the risk check for hot positions only (HOT_POLL_INTERVAL_SEC). It must
+// run on the poll goroutine: it reads the market state the poll keeps.
+func (w *Watcher) CheckHot() {
+	if !w.hasHotPositions() {
+		return
+	}
+	w.checkRisk(true)
+}
+
+// handlePriorityCommand sets how often a position is checked.
+// /priority                               -> list non-normal priorities
+// /priority <ticker> <hot|normal|slow>    -> set
+func (w *Watcher) handlePriorityCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.getPriorities()
+	}
+	if len(parts) < 3 {
+		return "Usage: /priority <ticker> <hot|normal|slow>"
+	}
+	ticker := symbols.Normalize(parts[1])
+	level := strings.ToLower(parts[2])
+	switch level {
+	case priorityHot, prioritySlow:
+	case "normal":
+		level = ""
+	default:
+		return "Usage: /priority <ticker> <hot|normal|slow>"
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, p := range w.state.Positions {
+		if p.Ticker != ticker || p.Status != "ACTIVE" {
+			continue
+		}
+		w.state.Positions[i].Priority = level
+		w.saveStateLocked()
+		log.Printf("Priority: %s set to %q", ticker, level)
+		switch level {
+		case priorityHot:
+			if w.config.HotPollIntervalSec <= 0 {
+				return fmt.Sprintf("🔥 %s marked hot, but HOT_POLL_INTERVAL_SEC is 0: it is checked every poll like the others.", ticker)
+			}
+			return fmt.Sprintf("🔥 %s is hot: checked every %ds while its market is open, plus every poll.", ticker, w.config.HotPollIntervalSec)
+		case prioritySlow:
+			return fmt.Sprintf("🐢 %s is slow: checked every %d polls (%d min). Its stops are not watched in between.", ticker, max(w.config.SlowPollEvery, 1), max(w.config.SlowPollEvery, 1)*w.config.PollIntervalMins)
+		}
+		return fmt.Sprintf("✅ %s is checked every poll.", ticker)
+	}
+	return fmt.Sprintf("⚠️ No active position for %s.", ticker)
+}
+
+func (w *Watcher) getPriorities() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	var lines []string
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && p.Priority != "" {
+			lines = append(lines, fmt.Sprintf("%s %s (%s)", priorityIcons[p.Priority], p.Ticker, p.Priority))
+		}
+	}
+	if len(lines) == 0 {
+		return "⏱️ All positions are checked every poll. Mark one with `/priority <ticker> hot|slow`."
+	}
+	return fmt.Sprintf("⏱️ *CHECK PRIORITIES*\n%s\n\nHot: every %ds while open. Slow: every %d polls. Others: every poll (%d min).",
+		strings.Join(lines, "\n"), w.config.HotPollIntervalSec, max(w.config.SlowPollEvery, 1), w.config.PollIntervalMins)
+}
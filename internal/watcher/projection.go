@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	projectionHistoryDays = 60   // Daily bars sampled for the return distribution
+	projectionHorizonDays = 21   // ~1 trading month
+	projectionPaths       = 2000 // Simulated paths (kept small for the e2-micro)
+)
+
+// handleProjectCommand runs a Monte Carlo projection of 1-month portfolio outcomes.
+// It bootstraps whole historical days (all holdings together) so cross-asset correlation is preserved.
+func (w *Watcher) handleProjectCommand() string {
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	if len(positions) == 0 {
+		return "ℹ️ No active positions to project."
+	}
+
+	equity, err := w.provider.GetEquity(w.ctx)
+	if err != nil || !equity.IsPositive() {
+		return "⚠️ Could not fetch account equity for projection."
+	}
+
+	// 1. Gather daily return series and current market values
+	var returns [][]float64
+	var values []float64
+	invested := 0.0
+	minLen := math.MaxInt
+	for _, p := range positions {
+		bars, err := w.provider.GetBars(w.ctx, p.Ticker, projectionHistoryDays+1)
+		if err != nil || len(bars) < 10 {
+			return fmt.Sprintf("⚠️ Insufficient price history for %s.", p.Ticker)
+		}
+
+		series := make([]float64, 0, len(bars)-1)
+		for i := 1; i < len(bars); i++ {
+			if bars[i-1].Close == 0 {
+				continue
+			}
+			series = append(series, bars[i].Close/bars[i-1].Close-1)
+		}
+		if len(series) < minLen {
+			minLen = len(series)
+		}
+
+		last := bars[len(bars)-1].Close
+		value := p.Quantity.InexactFloat64() * last
+		invested += value
+		returns = append(returns, series)
+		values = append(values, value)
+	}
+
+	// Align series on the most recent common window
+	for i := range returns {
+		returns[i] = returns[i][len(returns[i])-minLen:]
+	}
+
+	startEquity := equity.InexactFloat64()
+	cash := startEquity - invested
+	ddLimit := w.config.MaxDrawdownPct / 100
+
+	// 2. Simulate
+	rng := rand.New(rand.NewSource(w.clock.Now().UnixNano()))
+	finals := make([]float64, projectionPaths)
+	breaches := 0
+	current := make([]float64, len(values))
+	for path := 0; path < projectionPaths; path++ {
+		copy(current, values)
+		peak := startEquity
+		breached := false
+		total := startEquity
+		for day := 0; day < projectionHorizonDays; day++ {
+			idx := rng.Intn(minLen)
+			total = cash
+			for i := range current {
+				current[i] *= 1 + returns[i][idx]
+				total += current[i]
+			}
+			if total > peak {
+				peak = total
+			}
+			if ddLimit > 0 && (peak-total)/peak >= ddLimit {
+				breached = true
+			}
+		}
+		finals[path] = total
+		if breached {
+			breaches++
+		}
+	}
+	sort.Float64s(finals)
+
+	pctChange := func(v float64) string {
+		return decimal.NewFromFloat((v - startEquity) / startEquity * 100).StringFixed(2)
+	}
+	percentile := func(p float64) float64 {
+		return finals[int(p*float64(len(finals)-1))]
+	}
+
+	// 3. Report
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎲 *MONTE CARLO PROJECTION (%d days)*\n", projectionHorizonDays))
+	sb.WriteString(fmt.Sprintf("Paths: %d | History: %d days | Holdings: %d\n\n", projectionPaths, minLen, len(positions)))
+	sb.WriteString(fmt.Sprintf("Start Equity: $%s\n", equity.StringFixed(2)))
+	for _, p := range []float64{0.05, 0.25, 0.50, 0.75, 0.95} {
+		v := percentile(p)
+		sb.WriteString(fmt.Sprintf("P%-2d: $%s (%s%%)\n", int(p*100), decimal.NewFromFloat(v).StringFixed(2), pctChange(v)))
+	}
+	probBreach := decimal.NewFromFloat(float64(breaches) / float64(projectionPaths) * 100)
+	sb.WriteString(fmt.Sprintf("\nP(drawdown ≥ %.1f%%): %s%%", w.config.MaxDrawdownPct, probBreach.StringFixed(1)))
+	sb.WriteString("\n_Bootstrapped from historical daily returns. Not a forecast._")
+	return sb.String()
+}
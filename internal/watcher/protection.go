@@ -0,0 +1,415 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+const protectionCheckLead = 1 * time.Hour // Run within the hour before the open
+
+// checkMorningProtection runs the protection checklist once per day in the hour before the open.
+func (w *Watcher) checkMorningProtection(clock *alpaca.Clock) {
+	if clock == nil || clock.IsOpen || w.until(clock.NextOpen) > protectionCheckLead {
+		return
+	}
+
+	key := "PROTECTION_CHECK_" + clock.NextOpen.In(config.CetLoc).Format("2006-01-02")
+	w.mu.Lock()
+	if _, done := w.lastAlerts[key]; done {
+		w.mu.Unlock()
+		return
+	}
+	w.lastAlerts[key] = w.clock.Now()
+	w.mu.Unlock()
+
+	telegram.Notify(w.buildProtectionChecklist())
+}
+
+// buildProtectionChecklist verifies every ACTIVE position has a usable SL/TP and,
+// when BROKER_PROTECTION_ENABLED is set, a protective sell order resting at the broker.
+func (w *Watcher) buildProtectionChecklist() string {
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	if len(positions) == 0 {
+		return "🛡️ *PROTECTION CHECKLIST*\nℹ️ No active positions."
+	}
+
+	protected := make(map[string]bool)
+	var ordersErr error
+	if w.config.BrokerProtectionEnabled {
+		var orders []alpaca.Order
+		orders, ordersErr = w.provider.ListOrders(w.ctx, "open")
+		for _, o := range orders {
+			markProtective(protected, o)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🛡️ *PROTECTION CHECKLIST*\n")
+	gaps := 0
+	for _, p := range positions {
+		price, err := w.provider.GetPrice(w.ctx, p.Ticker)
+		if err != nil || price.IsZero() {
+			log.Printf("Protection Check: no price for %s: %v", p.Ticker, err)
+		}
+
+		issues := protectionIssues(p, price)
+		if w.config.BrokerProtectionEnabled {
+			if ordersErr != nil {
+				issues = append(issues, "broker orders unavailable")
+			} else if !protected[p.Ticker] {
+				issues = append(issues, "no broker-side stop order")
+			}
+		}
+
+		if len(issues) == 0 {
+			sb.WriteString(fmt.Sprintf("✅ %s\n", p.Ticker))
+			continue
+		}
+		gaps++
+		sb.WriteString(fmt.Sprintf("❌ %s: %s\n", p.Ticker, strings.Join(issues, "; ")))
+	}
+
+	if gaps == 0 {
+		sb.WriteString("\nAll positions protected.")
+	} else {
+		sb.WriteString(fmt.Sprintf("\n⚠️ %d position(s) need attention. Fix with `/update <ticker> <sl> <tp>`.", gaps))
+	}
+	return sb.String()
+}
+
+// protectionIssues checks the local SL/TP levels against the current price (zero price skips the relative checks).
+func protectionIssues(p models.Position, price decimal.Decimal) []string {
+	var issues []string
+	if p.StopLoss.IsZero() {
+		issues = append(issues, "SL not set")
+	} else if price.IsPositive() && !p.StopLoss.LessThan(price) {
+		issues = append(issues, fmt.Sprintf("SL $%s not below price $%s", p.StopLoss.StringFixed(2), price.StringFixed(2)))
+	}
+	if p.TakeProfit.IsZero() {
+		issues = append(issues, "TP not set")
+	} else if price.IsPositive() && !p.TakeProfit.GreaterThan(price) {
+		issues = append(issues, fmt.Sprintf("TP $%s not above price $%s", p.TakeProfit.StringFixed(2), price.StringFixed(2)))
+	}
+	if !p.StopLoss.IsZero() && !p.TakeProfit.IsZero() && !p.StopLoss.LessThan(p.TakeProfit) {
+		issues = append(issues, "SL >= TP")
+	}
+	return issues
+}
+
+// markProtective records tickers covered by a resting sell stop (including bracket legs).
+func markProtective(protected map[string]bool, o alpaca.Order) {
+	if o.Side == alpaca.Sell {
+		switch o.Type {
+		case alpaca.Stop, alpaca.StopLimit, alpaca.TrailingStop:
+			protected[o.Symbol] = true
+		}
+	}
+	for _, leg := range o.Legs {
+		markProtective(protected, leg)
+	}
+}
+
+// Broker-side exit kinds. Alpaca lets one resting order hold a position's shares,
+// so a position carries either an OCO pair or a native trailing stop, not both.
+const (
+	brokerExitOCO   = "oco"
+	brokerExitTrail = "trailing stop"
+)
+
+// brokerExitKind picks the broker exit for p: a native trailing stop when
+// BROKER_TRAILING_STOP is set and the position trails, otherwise the OCO pair.
+func (w *Watcher) brokerExitKind(p models.Position) string {
+	if w.config.BrokerTrailingStop && p.TrailingStopPct.IsPositive() {
+		return brokerExitTrail
+	}
+	return brokerExitOCO
+}
+
+// brokerExitID returns the ID of the broker exit resting for p ("" when none).
+func brokerExitID(p models.Position) string {
+	if p.BrokerTrailID != "" {
+		return p.BrokerTrailID
+	}
+	return p.BrokerOCOID
+}
+
+// brokerExitEligible reports why a position cannot carry a broker-side exit of kind ("" when it can).
+// Alpaca only links orders and trails for whole-share equity positions.
+func brokerExitEligible(p models.Position, kind string) string {
+	switch {
+	case symbols.IsCrypto(p.Ticker):
+		return "crypto is monitored locally"
+	case !p.Quantity.Equal(p.Quantity.Floor()):
+		return "fractional quantity"
+	case kind == brokerExitTrail && !p.TrailingStopPct.IsPositive():
+		return "trailing stop not set"
+	case kind == brokerExitOCO && (p.StopLoss.IsZero() || p.TakeProfit.IsZero()):
+		return "SL/TP not set"
+	}
+	return ""
+}
+
+// attachBrokerExit replaces any open orders for ticker with the broker-side exit
+// (an OCO pair of TP limit + SL stop, or a native trailing stop; GTC) for the whole
+// position and records its ID. From then on the broker executes that exit in real
+// time; the poll loop keeps watching the other levels locally.
+func (w *Watcher) attachBrokerExit(ticker string) (*alpaca.Order, string, error) {
+	w.mu.RLock()
+	var pos models.Position
+	found := false
+	for _, p := range w.state.Positions {
+		if p.Ticker == ticker && p.Status == "ACTIVE" {
+			pos, found = p, true
+			break
+		}
+	}
+	w.mu.RUnlock()
+	if !found {
+		return nil, "", fmt.Errorf("no active position for %s", ticker)
+	}
+	kind := w.brokerExitKind(pos)
+	if reason := brokerExitEligible(pos, kind); reason != "" {
+		return nil, kind, fmt.Errorf("%s: %s", ticker, reason)
+	}
+
+	// One exit per share: the previous exit (or an OTO stop leg from the buy) goes first
+	if err := w.ensureSequentialClearance(ticker); err != nil {
+		return nil, kind, fmt.Errorf("could not clear open orders for %s: %v", ticker, err)
+	}
+	opts := market.OrderOptions{OrderClass: "oco", TimeInForce: "gtc", TakeProfit: pos.TakeProfit, StopLoss: pos.StopLoss}
+	if kind == brokerExitTrail {
+		opts = market.OrderOptions{TimeInForce: "gtc", TrailPercent: pos.TrailingStopPct}
+	}
+	order, err := w.placeOrder(models.OrderIntent{Ticker: ticker, Qty: pos.Quantity, Side: "sell", Source: "PROTECT"}, opts)
+	if err != nil {
+		w.setBrokerExit(ticker, kind, "") // The old exit was cancelled: monitor locally again
+		return nil, kind, err
+	}
+	w.setBrokerExit(ticker, kind, order.ID)
+	log.Printf("[BROKER_EXIT] %s: attached %s %s (SL $%s, TP $%s, TS %s%%)", ticker, kind, order.ID,
+		pos.StopLoss.StringFixed(2), pos.TakeProfit.StringFixed(2), pos.TrailingStopPct.String())
+	return order, kind, nil
+}
+
+// setBrokerExit records (or clears, with "") the broker exit of the active position.
+// Recording one kind clears the other: they cannot rest together.
+func (w *Watcher) setBrokerExit(ticker, kind, orderID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, p := range w.state.Positions {
+		if p.Ticker != ticker || p.Status != "ACTIVE" {
+			continue
+		}
+		w.state.Positions[i].BrokerOCOID, w.state.Positions[i].BrokerTrailID = "", ""
+		if kind == brokerExitTrail {
+			w.state.Positions[i].BrokerTrailID = orderID
+		} else {
+			w.state.Positions[i].BrokerOCOID = orderID
+		}
+	}
+	w.saveStateLocked()
+}
+
+// protectAtBroker attaches (or replaces) the broker exit after a fill or a level change
+// when BROKER_PROTECTION_ENABLED is set or the position is already linked (/protect).
+// Failures alert and leave local monitoring on.
+func (w *Watcher) protectAtBroker(ticker string) {
+	w.mu.RLock()
+	linked := false
+	for _, p := range w.state.Positions {
+		if p.Ticker == ticker && p.Status == "ACTIVE" && brokerExitID(p) != "" {
+			linked = true
+		}
+	}
+	w.mu.RUnlock()
+	if !w.config.BrokerProtectionEnabled && !linked {
+		return
+	}
+	if _, kind, err := w.attachBrokerExit(ticker); err != nil {
+		log.Printf("[BROKER_EXIT] %s: %v", ticker, err)
+		telegram.Notify(fmt.Sprintf("⚠️ Broker %s not attached for %s: %v\nLevels stay monitored locally.", kind, ticker, err))
+	}
+}
+
+// patchBrokerExit pushes the position's current levels to its resting broker exit
+// with in-place replacements: the OCO stop leg gets the new SL, the TP limit the new
+// TP, a native trailing stop the new trail %. Replaced orders get new IDs, which are
+// recorded. Returns what changed (empty when the broker already had the levels).
+func (w *Watcher) patchBrokerExit(ticker string) ([]string, error) {
+	w.mu.RLock()
+	var pos models.Position
+	for _, p := range w.state.Positions {
+		if p.Ticker == ticker && p.Status == "ACTIVE" {
+			pos = p
+		}
+	}
+	w.mu.RUnlock()
+	id := brokerExitID(pos)
+	if id == "" {
+		return nil, fmt.Errorf("%s has no broker exit", ticker)
+	}
+	if (w.brokerExitKind(pos) == brokerExitTrail) != (pos.BrokerTrailID != "") {
+		return nil, fmt.Errorf("%s: exit kind changed", ticker) // Trail added or removed: needs a new exit
+	}
+
+	order, err := w.provider.GetOrder(w.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if status := strings.ToLower(order.Status); status != "new" && status != "accepted" && status != "held" {
+		return nil, fmt.Errorf("%s exit order is %s", ticker, order.Status)
+	}
+
+	var changed []string
+	if pos.BrokerTrailID != "" {
+		if order.TrailPercent == nil || !order.TrailPercent.Equal(pos.TrailingStopPct) {
+			replaced, err := w.provider.ReplaceOrder(w.ctx, order.ID, market.ReplaceOptions{TrailPercent: pos.TrailingStopPct})
+			if err != nil {
+				return nil, err
+			}
+			w.setBrokerExit(ticker, brokerExitTrail, replaced.ID)
+			changed = append(changed, "TS "+pos.TrailingStopPct.String()+"%")
+		}
+		return changed, nil
+	}
+
+	// OCO: the parent is the TP limit, the stop is its leg
+	sl := money.OrderPrice(ticker, "sell", pos.StopLoss)
+	tp := money.OrderPrice(ticker, "sell", pos.TakeProfit)
+	for _, leg := range order.Legs {
+		if leg.StopPrice != nil && !leg.StopPrice.Equal(sl) {
+			if _, err := w.provider.ReplaceOrder(w.ctx, leg.ID, market.ReplaceOptions{StopPrice: sl}); err != nil {
+				return changed, err
+			}
+			changed = append(changed, "SL "+money.USD(sl))
+		}
+	}
+	if order.LimitPrice != nil && !order.LimitPrice.Equal(tp) {
+		replaced, err := w.provider.ReplaceOrder(w.ctx, order.ID, market.ReplaceOptions{LimitPrice: tp})
+		if err != nil {
+			return changed, err
+		}
+		w.setBrokerExit(ticker, brokerExitOCO, replaced.ID)
+		changed = append(changed, "TP "+money.USD(tp))
+	}
+	return changed, nil
+}
+
+// syncBrokerExit follows a level change (/update): a linked exit is patched in place,
+// falling back to cancel-and-reattach when the broker refuses the replacement.
+func (w *Watcher) syncBrokerExit(ticker string) {
+	changed, err := w.patchBrokerExit(ticker)
+	if err == nil {
+		if len(changed) > 0 {
+			log.Printf("[BROKER_EXIT] %s: patched %s", ticker, strings.Join(changed, ", "))
+			telegram.Notify(fmt.Sprintf("🛡️ Broker exit for %s updated: %s", ticker, strings.Join(changed, ", ")))
+		}
+		return
+	}
+	log.Printf("[BROKER_EXIT] %s: patch failed (%v), re-attaching", ticker, err)
+	w.protectAtBroker(ticker)
+}
+
+// checkBrokerExits keeps broker protection in place each poll: eligible positions
+// without an exit get one, and an exit that ended without filling (cancelled,
+// expired, rejected) is re-attached. A filled exit is left to the broker sync.
+func (w *Watcher) checkBrokerExits() {
+	if !w.config.BrokerProtectionEnabled {
+		return
+	}
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && brokerExitEligible(p, w.brokerExitKind(p)) == "" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	for _, p := range positions {
+		if id := brokerExitID(p); id != "" {
+			order, err := w.provider.GetOrder(w.ctx, id)
+			if err != nil {
+				log.Printf("[BROKER_EXIT] %s: status check failed: %v", p.Ticker, err)
+				continue
+			}
+			switch strings.ToLower(order.Status) {
+			case "canceled", "expired", "rejected":
+				log.Printf("[BROKER_EXIT] %s: order %s ended (%s), re-attaching", p.Ticker, id, order.Status)
+			default:
+				continue
+			}
+		}
+		key := "BROKER_EXIT_" + p.Ticker
+		if _, kind, err := w.attachBrokerExit(p.Ticker); err != nil {
+			log.Printf("[BROKER_EXIT] %s: %v", p.Ticker, err)
+			w.mu.Lock()
+			last, alerted := w.lastAlerts[key]
+			if !alerted || w.since(last) > 24*time.Hour {
+				w.lastAlerts[key] = w.clock.Now()
+				go telegram.Notify(fmt.Sprintf("⚠️ Broker %s not attached for %s: %v\nLevels stay monitored locally.", kind, p.Ticker, err))
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// handleProtectCommand attaches broker-side exits on demand.
+// /protect TICKER | all
+func (w *Watcher) handleProtectCommand(parts []string) string {
+	if len(parts) < 2 {
+		return "Usage: /protect <ticker|all>"
+	}
+	var tickers []string
+	if strings.EqualFold(parts[1], "all") {
+		w.mu.RLock()
+		for _, p := range w.state.Positions {
+			if p.Status == "ACTIVE" {
+				tickers = append(tickers, p.Ticker)
+			}
+		}
+		w.mu.RUnlock()
+	} else {
+		tickers = []string{symbols.Normalize(parts[1])}
+	}
+	if len(tickers) == 0 {
+		return "ℹ️ No active positions."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🛡️ *BROKER PROTECTION*\n")
+	for _, t := range tickers {
+		order, kind, err := w.attachBrokerExit(t)
+		if err != nil {
+			sb.WriteString(fmt.Sprintf("❌ %v\n", err))
+			continue
+		}
+		what := "SL/TP pair"
+		if kind == brokerExitTrail {
+			what = "native trailing stop"
+		}
+		sb.WriteString(fmt.Sprintf("✅ %s: %s resting at the broker (%s)\n", t, what, order.Status))
+	}
+	sb.WriteString("\nThe broker executes the attached exit in real time; the other levels are still watched locally.")
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// reAlertHeaders escalate with each unanswered reminder (the last one repeats).
+var reAlertHeaders = []string{"⏰ REMINDER", "🚨 URGENT", "🆘 CRITICAL"}
+
+// scheduleStopReAlert arms the next reminder for an unconfirmed SL/TS alert and
+// returns the reminders still due, this one included (0 when none was scheduled).
+// attempt is 1-based; nothing is scheduled past STOP_REALERT_MAX or when disabled.
+func (w *Watcher) scheduleStopReAlert(ticker string, alertedAt time.Time, attempt int) int {
+	if w.config.StopReAlertMins <= 0 || attempt > w.config.StopReAlertMax {
+		return 0
+	}
+	delay := time.Duration(w.config.StopReAlertMins) * time.Minute
+	w.clock.AfterFunc(delay, func() { w.reAlertStop(ticker, alertedAt, attempt) })
+	return w.config.StopReAlertMax - attempt + 1
+}
+
+// expirePendingActionsLocked drops confirmations past their TTL so an ignored alert
+// does not block new ones. A stop alert keeps its action while reminders are due
+// (they re-arm it); once it lapses, the user is told the stop was not executed.
+// Caller holds w.mu.
+func (w *Watcher) expirePendingActionsLocked() {
+	ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
+	for ticker, action := range w.pendingActions {
+		if w.since(action.Timestamp) <= ttl || (action.ReAlerts > 0 && !w.state.Settings.VacationMode) {
+			continue
+		}
+		delete(w.pendingActions, ticker)
+		if action.Trigger == "SL" || action.Trigger == "TS" {
+			log.Printf("Expired unconfirmed %s alert for %s", action.Trigger, ticker)
+			telegram.Notify(fmt.Sprintf("⌛ *%s CONFIRMATION EXPIRED*\nAsset: %s\nThe stop was NOT executed. The position is still open.",
+				triggerActionNames[action.Trigger], ticker))
+		}
+	}
+}
+
+// reAlertStop re-sends an unanswered stop confirmation with a fresh price.
+// The pending action is refreshed so the new buttons are valid for a full TTL.
+// Skipped in vacation mode, where the expiry policy takes over.
+func (w *Watcher) reAlertStop(ticker string, alertedAt time.Time, attempt int) {
+	w.mu.RLock()
+	pending, exists := w.pendingActions[ticker]
+	vacation := w.state.Settings.VacationMode
+	w.mu.RUnlock()
+	if !exists || !pending.Timestamp.Equal(alertedAt) || vacation {
+		return // Answered, replaced, or handled by vacation policy
+	}
+
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil || price.IsZero() {
+		log.Printf("Re-alert: price refresh failed for %s: %v", ticker, err)
+		price = pending.TriggerPrice
+	}
+	move := price.Sub(pending.TriggerPrice).Div(pending.TriggerPrice).Mul(decimal.NewFromInt(100))
+
+	now := w.clock.Now()
+	w.mu.Lock()
+	if current, ok := w.pendingActions[ticker]; !ok || !current.Timestamp.Equal(alertedAt) {
+		w.mu.Unlock()
+		return // Answered while we were fetching the price
+	}
+	pending.Timestamp = now
+	pending.TriggerPrice = price
+	pending.ReAlerts = max(w.config.StopReAlertMax-attempt, 0) // Scheduled below
+	w.pendingActions[ticker] = pending
+	w.lastAlerts[ticker] = now
+	w.mu.Unlock()
+
+	header := reAlertHeaders[len(reAlertHeaders)-1]
+	if attempt <= len(reAlertHeaders) {
+		header = reAlertHeaders[attempt-1]
+	}
+	final := ""
+	if attempt >= w.config.StopReAlertMax {
+		final = "\nLast reminder for this alert."
+	}
+
+	msg := fmt.Sprintf("%s (%d/%d): *%s UNCONFIRMED*\nAsset: %s\nPrice: $%s (%s%% since last alert)\nUnconfirmed for: %s\nAction: SELL REQUIRED%s\n\n⏱️ Valid for %d seconds.",
+		header, attempt, w.config.StopReAlertMax, triggerActionNames[pending.Trigger], ticker,
+		price.StringFixed(2), move.StringFixed(2), time.Duration(attempt*w.config.StopReAlertMins)*time.Minute, final, w.config.ConfirmationTTLSec)
+	telegram.SendInteractiveMessage(msg, []telegram.Button{
+		{Text: "✅ CONFIRM", CallbackData: fmt.Sprintf("CONFIRM_%s_%s", pending.Trigger, ticker)},
+		{Text: "❌ CANCEL", CallbackData: fmt.Sprintf("CANCEL_%s_%s", pending.Trigger, ticker)},
+	})
+	log.Printf("Re-alert %d/%d sent for %s %s", attempt, w.config.StopReAlertMax, strings.ToUpper(pending.Trigger), ticker)
+
+	w.scheduleTriggerExpiry(ticker, now)
+	w.scheduleStopReAlert(ticker, now, attempt+1)
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"alpha_trading/internal/budget"
 	"alpha_trading/internal/models"
 	"alpha_trading/internal/telegram"
 
@@ -16,7 +17,7 @@ import (
 )
 
 func (w *Watcher) getMarketStatus() string {
-	clock, err := w.provider.GetClock()
+	clock, err := w.provider.GetClock(w.ctx)
 	if err != nil {
 		log.Printf("Error fetching market clock: %v", err)
 		return "⚠️ Error: Could not fetch market status."
@@ -35,7 +36,7 @@ func (w *Watcher) getMarketStatus() string {
 	}
 
 	// Format time until next event
-	until := time.Until(eventTime.Round(time.Minute)).Round(time.Minute)
+	until := w.until(eventTime.Round(time.Minute)).Round(time.Minute)
 
 	return fmt.Sprintf("🏛️ *MARKET STATUS*\nState: %s\n%s: %s (in %s)",
 		status, nextSession, eventTime.Format("15:04 MST"), until)
@@ -54,18 +55,8 @@ func (w *Watcher) getStatus() string {
 
 	// Parallel Fetching
 	var wg sync.WaitGroup
-	var mu sync.Mutex // For results map
 
-	type detailedPos struct {
-		Ticker    string
-		Qty       decimal.Decimal
-		Current   decimal.Decimal
-		PrevClose decimal.Decimal
-		Entry     decimal.Decimal
-		SL        decimal.Decimal
-		HWM       decimal.Decimal
-	}
-	posDetails := make(map[string]detailedPos)
+	posDetails := make(map[string]statusDetail)
 
 	var clock *alpaca.Clock
 	var equity decimal.Decimal
@@ -75,39 +66,40 @@ func (w *Watcher) getStatus() string {
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		clock, errClock = w.provider.GetClock()
+		clock, errClock = w.provider.GetClock(w.ctx)
 	}()
 	go func() {
 		defer wg.Done()
-		equity, errEquity = w.provider.GetEquity()
+		equity, errEquity = w.provider.GetEquity(w.ctx)
 	}()
 
-	// 2. Fetch Position Data
-	for _, p := range activePositions {
-		wg.Add(1)
-		go func(pos models.Position) {
-			defer wg.Done()
-			current, _ := w.provider.GetPrice(pos.Ticker)
-			bars, _ := w.provider.GetBars(pos.Ticker, 1)
-
-			prevClose := decimal.Zero
-			if len(bars) > 0 {
-				prevClose = decimal.NewFromFloat(bars[len(bars)-1].Close)
-			}
-
-			mu.Lock()
-			posDetails[pos.Ticker] = detailedPos{
+	// 2. Fetch Position Data: one batched snapshot gives every live price and previous close
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tickers := make([]string, 0, len(activePositions))
+		for _, p := range activePositions {
+			tickers = append(tickers, p.Ticker)
+		}
+		snaps := w.getSnapshots(tickers)
+		for _, pos := range activePositions {
+			snap := snaps[pos.Ticker]
+			posDetails[pos.Ticker] = statusDetail{
 				Ticker:    pos.Ticker,
 				Qty:       pos.Quantity,
 				Entry:     pos.EntryPrice,
-				Current:   current,
-				PrevClose: prevClose,
+				Current:   snap.Last,
+				PrevClose: snap.PrevClose,
 				SL:        pos.StopLoss,
+				TP:        pos.TakeProfit,
+				TrailPct:  pos.TrailingStopPct,
 				HWM:       pos.HighWaterMark,
+				DayHigh:   snap.High,
+				DayLow:    snap.Low,
+				Carry:     pos.Carry,
 			}
-			mu.Unlock()
-		}(p)
-	}
+		}
+	}()
 
 	wg.Wait()
 
@@ -123,72 +115,35 @@ func (w *Watcher) getStatus() string {
 		if clock.IsOpen {
 			statusIcon = "🟢"
 			statusText = "OPEN"
-			until := time.Until(clock.NextClose).Round(time.Minute)
-			timeMsg = fmt.Sprintf("Closes in: %s", until)
+			until := w.until(clock.NextClose).Round(time.Minute)
+			timeMsg = fmt.Sprintf("%s: %s", w.tr("Closes in"), until)
 		} else {
-			until := time.Until(clock.NextOpen).Round(time.Minute)
-			timeMsg = fmt.Sprintf("Opens in: %s", until)
+			until := w.until(clock.NextOpen).Round(time.Minute)
+			timeMsg = fmt.Sprintf("%s: %s", w.tr("Opens in"), until)
 		}
 	} else {
 		statusText = "Unknown"
 	}
 
-	sb.WriteString(fmt.Sprintf("Market: %s %s\n%s\n\n", statusIcon, statusText, timeMsg))
+	sb.WriteString(fmt.Sprintf("%s: %s %s\n%s\n\n", w.tr("Market"), statusIcon, statusText, timeMsg))
 
-	// Positions Table
+	// Positions (layout/columns selectable via /settings)
 	if len(activePositions) > 0 {
-		sb.WriteString("`Ticker | Price | DayP/L | TotP/L`\n")
-		sb.WriteString("`--------------------------------`\n")
-
-		totalDayPL := decimal.Zero
-		totalUnrealizedPL := decimal.Zero
+		w.mu.RLock()
+		layout := w.statusLayoutLocked()
+		cols := w.statusColumnsLocked()
+		w.mu.RUnlock()
 
+		details := make([]statusDetail, 0, len(activePositions))
 		for _, p := range activePositions {
-			d := posDetails[p.Ticker]
-			if d.Current.IsZero() {
-				sb.WriteString(fmt.Sprintf("`%-6s | ERR   |   -    |   -   `\n", d.Ticker))
-				continue
-			}
-
-			// Day P/L
-			dayPL := decimal.Zero
-			dayPLStr := "   -  "
-			if !d.PrevClose.IsZero() {
-				// (Current - PrevClose) * Qty
-				dayPL = d.Current.Sub(d.PrevClose).Mul(d.Qty)
-				totalDayPL = totalDayPL.Add(dayPL)
-				icon := "🟢"
-				if dayPL.IsNegative() {
-					icon = "🔴"
-				}
-				dayPLStr = fmt.Sprintf("%s%s", icon, dayPL.StringFixed(2))
-			}
-
-			// Total P/L
-			// (Current - Entry) * Qty
-			totPL := d.Current.Sub(d.Entry).Mul(d.Qty)
-			totalUnrealizedPL = totalUnrealizedPL.Add(totPL)
-			totIcon := "🟢"
-			if totPL.IsNegative() {
-				totIcon = "🔴"
-			}
-
-			sb.WriteString(fmt.Sprintf("`%-6s | %-6s | %s | %s%s`\n",
-				d.Ticker, d.Current.StringFixed(2), dayPLStr, totIcon, totPL.StringFixed(2)))
-
-			// Context line
-			distSL := "N/A"
-			slPriceStr := "N/A"
-			if !d.Current.IsZero() && !d.SL.IsZero() {
-				// (Current - SL) / Current * 100
-				pct := d.Current.Sub(d.SL).Div(d.Current).Mul(decimal.NewFromInt(100))
-				distSL = fmt.Sprintf("%s%%", pct.StringFixed(1))
-				slPriceStr = "$" + d.SL.StringFixed(2)
-			}
-			sb.WriteString(fmt.Sprintf("      ↳ SL: %s (%s) | HWM: $%s\n", slPriceStr, distSL, d.HWM.StringFixed(2)))
+			details = append(details, posDetails[p.Ticker])
 		}
+		sb.WriteString(renderStatusPositions(details, layout, cols, equity))
 		sb.WriteString("\n")
 	}
+	if opts := w.formatOptionPositions(); opts != "" {
+		sb.WriteString(opts + "\n\n")
+	}
 
 	// Footer
 	equityStr := "$" + equity.StringFixed(2)
@@ -196,13 +151,13 @@ func (w *Watcher) getStatus() string {
 		equityStr = "Err"
 	}
 
-	uptime := time.Since(startTime).Round(time.Second)
+	uptime := w.since(w.startedAt).Round(time.Second)
 
 	// Pending Orders (Preserve Spec 26)
 	pendingMsg := ""
-	openOrders, err := w.provider.ListOrders("open")
+	openOrders, err := w.provider.ListOrders(w.ctx, "open")
 	if err == nil && len(openOrders) > 0 {
-		pendingMsg = "\n⏳ *PENDING ORDERS*:\n"
+		pendingMsg = fmt.Sprintf("\n⏳ *%s*:\n", w.tr("PENDING ORDERS"))
 		for _, o := range openOrders {
 			// Alpaca Order Qty is *decimal.Decimal usually?
 			// Let's assume it is String or we use Qty directly if it prints.
@@ -218,20 +173,26 @@ func (w *Watcher) getStatus() string {
 		}
 	}
 
-	var currentExposure decimal.Decimal
-	for _, p := range activePositions {
-		if d, ok := posDetails[p.Ticker]; ok && !d.Current.IsZero() {
-			cost := d.Qty.Mul(d.Entry) // Use Entry Price for Cost Basis (Spec 63)
-			currentExposure = currentExposure.Add(cost)
-		}
+	capEquity := equity
+	if errEquity != nil {
+		capEquity = decimal.Zero // Unknown: cap by the fiscal limit alone
 	}
-	fiscalLimit := decimal.NewFromFloat(w.config.FiscalBudgetLimit)
-	availableBudget := fiscalLimit.Sub(currentExposure)
+	b := budget.Compute(activePositions, decimal.NewFromFloat(w.config.FiscalBudgetLimit), capEquity)
 
-	sb.WriteString(fmt.Sprintf("Equity: %s\n", equityStr))
-	sb.WriteString(fmt.Sprintf("Budget: $%s / $%s (Available: $%s)\n",
-		currentExposure.StringFixed(2), fiscalLimit.StringFixed(2), availableBudget.StringFixed(2)))
-	sb.WriteString(fmt.Sprintf("Uptime: %s%s", uptime, pendingMsg))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", w.tr("Equity"), equityStr))
+	if st, _, ok := w.intradayEquityStats(); ok {
+		sb.WriteString(w.formatIntradayEquity(st) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s: $%s / $%s (%s: $%s)\n", w.tr("Budget"),
+		b.Exposure.StringFixed(2), b.RealCap.StringFixed(2), w.tr("Available"), b.Available.StringFixed(2)))
+	if len(activePositions) > 0 {
+		prices := make(map[string]decimal.Decimal, len(posDetails))
+		for t, d := range posDetails {
+			prices[t] = d.Current
+		}
+		sb.WriteString(w.formatRisk(w.computeRisk(activePositions, prices), capEquity) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s: %s%s", w.tr("Uptime"), uptime, pendingMsg))
 
 	return sb.String()
 }
@@ -276,7 +237,7 @@ func (w *Watcher) getListSafe() string {
 		}
 		activeFound = true
 
-		price, err := w.provider.GetPrice(pos.Ticker)
+		price, err := w.provider.GetPrice(w.ctx, pos.Ticker)
 		priceStr := fmt.Sprintf("$%s", price.StringFixed(2))
 		distSL := "N/A"
 
@@ -301,41 +262,85 @@ func (w *Watcher) getListSafe() string {
 	return sb.String()
 }
 
-// checkEOD handles the Market Close detection and Reporting (Spec 49)
+// checkEOD handles the Market Close detection and Reporting (Spec 49).
+// The report is keyed to the trading calendar: once the latest session's close
+// time has passed (early on half days, never on holidays) and that session has
+// not been reported yet, the report is sent, even if the bot was down at the close.
+// If the calendar is unavailable it falls back to the Open -> Closed transition.
+// While crypto is held, days without a session are reported at midnight ET.
 func (w *Watcher) checkEOD() {
-	clock, err := w.provider.GetClock()
+	clock, err := w.provider.GetClock(w.ctx)
 	if err != nil {
 		log.Printf("Error fetching market clock: %v", err)
 		return
 	}
+	wasOpen := w.wasMarketOpen
+	w.wasMarketOpen = clock.IsOpen
+	if clock.IsOpen {
+		return
+	}
+
+	now := w.clock.Now()
+	days, err := w.provider.GetCalendar(w.ctx, now.AddDate(0, 0, -eodCalendarLookbackDays), now)
+	if err != nil {
+		log.Printf("Warning: Trading calendar unavailable, using clock transition for EOD: %v", err)
+		if wasOpen {
+			log.Println("📉 MARKET CLOSED. Generating EOD Report (Spec 49)...")
+			w.markEODSent(now.In(easternLoc()).Format("2006-01-02"))
+			go w.generateAndSendEODReport()
+		}
+		return
+	}
 
-	// EOD Trigger: Transition from Open -> Closed
-	// Only trigger if we mistakenly thought it was open (or tracked it as open) and now it is closed.
-	if w.wasMarketOpen && !clock.IsOpen {
-		log.Println("📉 MARKET CLOSED. Generating EOD Report (Spec 49)...")
-		go w.generateAndSendEODReport()
+	session, closeAt, ok := lastClosedSession(days, now)
+	// Crypto trades 24/7: while any is held, weekends and holidays get a report at midnight ET
+	if w.holdsCrypto() {
+		if day, midnight := lastClosedCryptoDay(now); day > session {
+			session, closeAt, ok = day, midnight, true
+		}
 	}
-	w.wasMarketOpen = clock.IsOpen
+	if !ok {
+		return
+	}
+	w.mu.RLock()
+	lastSent := w.state.LastEODSession
+	w.mu.RUnlock()
+	if session <= lastSent {
+		return
+	}
+
+	log.Printf("📉 MARKET CLOSED (Session %s, Close %s ET). Generating EOD Report (Spec 49)...", session, closeAt.In(easternLoc()).Format("15:04"))
+	w.markEODSent(session)
+	go w.generateAndSendEODReport()
+	go w.maybeWeeklyStopReview(session, clock.NextOpen)
+}
+
+// markEODSent persists the session date of the last EOD report.
+func (w *Watcher) markEODSent(session string) {
+	w.mu.Lock()
+	w.state.LastEODSession = session
+	w.saveStateLocked()
+	w.mu.Unlock()
 }
 
 // generateAndSendEODReport implements Spec 49
 func (w *Watcher) generateAndSendEODReport() {
 	// 1. Fetch Data
 	// Pillar 1: Current Positions (Unrealized)
-	positions, err := w.provider.ListPositions()
+	positions, err := w.provider.ListPositions(w.ctx)
 	if err != nil {
 		log.Printf("EOD Error: Failed to list positions: %v", err)
 		return
 	}
 
 	// Pillar 2: Historical (Equity Curve) - Get 1D history
-	history, err := w.provider.GetPortfolioHistory("1D", "1Min")
+	history, err := w.provider.GetPortfolioHistory(w.ctx, "1D", "1Min")
 	if err != nil {
 		log.Printf("EOD Error: Failed to get history: %v", err)
 	}
 
 	// Pillar 3: Realized Today
-	closedOrders, err := w.provider.ListOrders("closed")
+	closedOrders, err := w.provider.ListOrders(w.ctx, "closed")
 	if err != nil {
 		log.Printf("EOD Error: Failed to list closed orders: %v", err)
 	}
@@ -347,7 +352,7 @@ func (w *Watcher) generateAndSendEODReport() {
 		endEquity = history.Equity[len(history.Equity)-1]
 	} else {
 		// Fallback if history fails
-		endEquity, _ = w.provider.GetEquity()
+		endEquity, _ = w.provider.GetEquity(w.ctx)
 	}
 
 	// Calculate Daily Change
@@ -359,7 +364,7 @@ func (w *Watcher) generateAndSendEODReport() {
 	// Filter Realized Orders (Today Only)
 	var realizedToday []string
 	loc, _ := time.LoadLocation("Europe/Madrid") // Or use config.CetLoc if exported
-	now := time.Now().In(loc)
+	now := w.clock.Now().In(loc)
 	y, m, d := now.Date()
 
 	for _, o := range closedOrders {
@@ -382,60 +387,161 @@ func (w *Watcher) generateAndSendEODReport() {
 		}
 	}
 
-	// 3. Report Formatting
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("📊 *MARKET CLOSE REPORT - %s*\n\n", now.Format("2006-01-02")))
-
-	// Section A: Account
-	icon := "🟢"
-	if dailyChangePct.IsNegative() {
-		icon = "🔴"
-	}
-	sb.WriteString("*Account Summary*\n")
-	sb.WriteString(fmt.Sprintf("End Equity: $%s\n", endEquity.StringFixed(2)))
-	sb.WriteString(fmt.Sprintf("Daily Change: %s%s%%\n\n", icon, dailyChangePct.StringFixed(2)))
-
-	// Section B: Per Asset Table (Unrealized)
-	if len(positions) > 0 {
-		sb.WriteString("`Ticker | Day % | Tot %`\n")
-		sb.WriteString("`---------------------`\n")
-		for _, p := range positions {
-			dayChange := decimal.Zero
-			if p.ChangeToday != nil {
-				dayChange = p.ChangeToday.Mul(decimal.NewFromInt(100))
+	// 3. Report Formatting (sections and order from Settings.EODSections)
+	w.mu.RLock()
+	sections := w.eodSectionsLocked()
+	compact := w.state.Settings.EODCompact && len(realizedToday) == 0
+	carry := make(map[string]decimal.Decimal) // Accrued crypto carry, part of the P/L
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && !p.Carry.IsZero() {
+			carry[p.Ticker] = carry[p.Ticker].Add(p.Carry)
+		}
+	}
+	w.mu.RUnlock()
+
+	render := map[string]func() string{
+		// Section A: Account
+		eodAccount: func() string {
+			icon := "🟢"
+			if dailyChangePct.IsNegative() {
+				icon = "🔴"
 			}
-			entry := p.AvgEntryPrice
-			current := *p.CurrentPrice // Assume safe
-			totPct := decimal.Zero
-			if !entry.IsZero() {
-				totPct = current.Sub(entry).Div(entry).Mul(decimal.NewFromInt(100))
+			summary := fmt.Sprintf("*%s*\n%s: $%s\n%s: %s%s%%", w.tr("Account Summary"),
+				w.tr("End Equity"), endEquity.StringFixed(2), w.tr("Daily Change"), icon, dailyChangePct.StringFixed(2))
+			if st, _, ok := w.intradayEquityStats(); ok {
+				summary += "\n" + w.formatIntradayEquity(st)
 			}
+			return summary
+		},
+		// Section B: Per Asset Table (Unrealized)
+		eodAssets: func() string {
+			if len(positions) == 0 {
+				return "ℹ️ " + w.tr("No active positions carried overnight.")
+			}
+			var tb strings.Builder
+			tb.WriteString("`Ticker | Day % | Tot %`\n")
+			tb.WriteString("`---------------------`")
+			for _, p := range positions {
+				dayChange := decimal.Zero
+				if p.ChangeToday != nil {
+					dayChange = p.ChangeToday.Mul(decimal.NewFromInt(100))
+				}
+				entry := p.AvgEntryPrice
+				current := *p.CurrentPrice // Assume safe
+				totPct := decimal.Zero
+				if !entry.IsZero() {
+					totPct = current.Sub(entry).Div(entry).Mul(decimal.NewFromInt(100))
+				}
 
-			sb.WriteString(fmt.Sprintf("`%-6s | %5s%%| %5s%%`\n",
-				p.Symbol, dayChange.StringFixed(2), totPct.StringFixed(2)))
-		}
-		sb.WriteString("\n")
-	} else {
-		sb.WriteString("ℹ️ No active positions carried overnight.\n\n")
+				tb.WriteString(fmt.Sprintf("\n`%-6s | %5s%%| %5s%%`",
+					p.Symbol, dayChange.StringFixed(2), totPct.StringFixed(2)))
+			}
+			var carried []string
+			for _, p := range positions {
+				if c, ok := carry[p.Symbol]; ok {
+					carried = append(carried, fmt.Sprintf("%s %s", p.Symbol, plString(c)))
+				}
+			}
+			if len(carried) > 0 {
+				tb.WriteString(fmt.Sprintf("\n%s: %s", w.tr("Carry"), strings.Join(carried, " | ")))
+			}
+			return tb.String()
+		},
+		// Section C: Realized
+		eodActivity: func() string {
+			if len(realizedToday) == 0 {
+				return "ℹ️ " + w.tr("No trades closed today.")
+			}
+			var ab strings.Builder
+			ab.WriteString(fmt.Sprintf("*%s*", w.tr("Activity Today")))
+			// Limit length carefully
+			if len(realizedToday) > 10 {
+				for i := 0; i < 5; i++ {
+					ab.WriteString(fmt.Sprintf("\n• %s", realizedToday[i]))
+				}
+				ab.WriteString(fmt.Sprintf("\n...and %d more.", len(realizedToday)-5))
+			} else {
+				for _, line := range realizedToday {
+					ab.WriteString(fmt.Sprintf("\n• %s", line))
+				}
+			}
+			return ab.String()
+		},
+		// Section D: Intraday Equity Curve (local samples first, broker history as the fallback)
+		eodChart: func() string {
+			if _, curve, ok := w.intradayEquityStats(); ok {
+				return w.formatEquityChart(curve)
+			}
+			if history == nil {
+				return ""
+			}
+			return w.formatEquityChart(history.Equity)
+		},
+		// Section D2: Option positions (OPTIONS_ENABLED)
+		eodOptions: w.formatOptionPositions,
+		// Section E: Sector Exposure
+		eodSectors: func() string {
+			values := make(map[string]decimal.Decimal)
+			for _, p := range positions {
+				if p.MarketValue != nil {
+					values[p.Symbol] = *p.MarketValue
+				}
+			}
+			return w.formatSectorExposure(values)
+		},
+		// Section E2: Value at risk (stops and historical VaR)
+		eodRisk: func() string {
+			prices := make(map[string]decimal.Decimal)
+			for _, p := range positions {
+				if p.CurrentPrice != nil {
+					prices[p.Symbol] = *p.CurrentPrice
+				}
+			}
+			w.mu.RLock()
+			var active []models.Position
+			for _, p := range w.state.Positions {
+				if p.Status == "ACTIVE" {
+					active = append(active, p)
+				}
+			}
+			w.mu.RUnlock()
+			return w.riskSection(active, prices, endEquity)
+		},
+		// Section F: Benchmarks (hypothetical comparison portfolios)
+		eodBenchmarks: func() string { return w.buildBenchmarkSection(dailyChangePct) },
 	}
 
-	// Section C: Realized
-	if len(realizedToday) > 0 {
-		sb.WriteString("*Activity Today*\n")
-		// Limit length carefully
-		if len(realizedToday) > 10 {
-			for i := 0; i < 5; i++ {
-				sb.WriteString(fmt.Sprintf("• %s\n", realizedToday[i]))
-			}
-			sb.WriteString(fmt.Sprintf("...and %d more.\n", len(realizedToday)-5))
-		} else {
-			for _, line := range realizedToday {
-				sb.WriteString(fmt.Sprintf("• %s\n", line))
+	// Compact mode: a quiet day needs the account line, not the full tables
+	if compact {
+		sections = []string{eodAccount}
+	}
+
+	var blocks []string
+	aiAt := -1
+	for _, name := range sections {
+		if name == eodAI {
+			aiAt = len(blocks)
+			continue
+		}
+		if fn, ok := render[name]; ok {
+			if block := strings.TrimSpace(fn()); block != "" {
+				blocks = append(blocks, block)
 			}
 		}
-	} else {
-		sb.WriteString("ℹ️ No trades closed today.")
 	}
+	if compact {
+		blocks = append(blocks, fmt.Sprintf("ℹ️ %s %d %s.", w.tr("No trades today."), len(positions), w.tr("positions held")))
+	}
+	// Section G: AI Commentary, written from the other sections and placed where configured
+	if aiAt >= 0 {
+		if c := w.eodCommentary(strings.Join(blocks, "\n\n")); c != "" {
+			blocks = append(blocks[:aiAt], append([]string{c}, blocks[aiAt:]...)...)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 *%s - %s*\n\n", w.tr("MARKET CLOSE REPORT"), now.Format("2006-01-02")))
+	sb.WriteString(strings.Join(blocks, "\n\n"))
 
 	report := sb.String()
 
@@ -453,7 +559,7 @@ func (w *Watcher) saveDailyPerformance(report string) {
 	}
 	defer f.Close()
 
-	if _, err := f.WriteString(fmt.Sprintf("\n--- %s ---\n%s\n", time.Now().Format("2006-01-02 15:04:05"), report)); err != nil {
+	if _, err := f.WriteString(fmt.Sprintf("\n--- %s ---\n%s\n", w.clock.Now().Format("2006-01-02 15:04:05"), report)); err != nil {
 		log.Printf("Error writing to daily log: %v", err)
 	}
 }
@@ -0,0 +1,249 @@
+package watcher
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// equityPoint is one daily close of the account with the external cash that moved in
+// (deposit > 0) or out (withdrawal < 0) since the previous close.
+type equityPoint struct {
+	at     time.Time
+	equity decimal.Decimal
+	pl     decimal.Decimal // Trading P/L of the day, cash flows excluded
+	flow   decimal.Decimal // equity change not explained by pl
+}
+
+// periodReturns are the two views of one period's performance.
+type periodReturns struct {
+	start, end    equityPoint
+	days          int
+	pl            decimal.Decimal
+	deposits      decimal.Decimal
+	withdrawals   decimal.Decimal // Positive amount
+	twr           decimal.Decimal // Time-weighted, % (flows neutralized)
+	mwr           decimal.Decimal // Money-weighted (IRR over the period), %
+	mwrAnnualized decimal.Decimal // %
+	mwrOK         bool            // false when the IRR did not converge
+}
+
+// equityPoints turns a daily portfolio history into points in [start, end), plus the
+// close before start as the base. Days before the account held any equity are dropped.
+func equityPoints(h *alpaca.PortfolioHistory, start, end time.Time) []equityPoint {
+	n := len(h.Timestamp)
+	if len(h.Equity) < n || len(h.ProfitLoss) < n {
+		n = min(len(h.Equity), len(h.ProfitLoss))
+	}
+	var points []equityPoint
+	for i := 0; i < n; i++ {
+		at := time.Unix(h.Timestamp[i], 0)
+		if !at.Before(end) {
+			break
+		}
+		if !h.Equity[i].IsPositive() {
+			continue
+		}
+		p := equityPoint{at: at, equity: h.Equity[i], pl: h.ProfitLoss[i]}
+		if len(points) > 0 {
+			p.flow = p.equity.Sub(points[len(points)-1].equity).Sub(p.pl)
+		}
+		if at.Before(start) {
+			// Only the last close before the period is kept, as its base
+			points = []equityPoint{{at: p.at, equity: p.equity}}
+			continue
+		}
+		if len(points) == 0 {
+			p.flow, p.pl = decimal.Zero, decimal.Zero // First funded day: its equity is the base
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// timeWeightedReturn chain-links the daily returns: each day's P/L over the previous
+// close, so deposits and withdrawals do not move the result.
+func timeWeightedReturn(points []equityPoint) decimal.Decimal {
+	growth := decimal.NewFromInt(1)
+	for i := 1; i < len(points); i++ {
+		prev := points[i-1].equity
+		if !prev.IsPositive() {
+			continue
+		}
+		growth = growth.Mul(decimal.NewFromInt(1).Add(points[i].pl.Div(prev)))
+	}
+	return growth.Sub(decimal.NewFromInt(1)).Mul(decimal.NewFromInt(100))
+}
+
+// moneyWeightedDaily solves for the daily rate r that makes the start equity plus the
+// flows, compounded to the end, equal the end equity (the IRR). Bisection keeps it
+// robust for the small, noisy series a monthly window produces.
+func moneyWeightedDaily(points []equityPoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	origin := points[0].at
+	end := points[len(points)-1]
+	T := end.at.Sub(origin).Hours() / 24
+	if T <= 0 {
+		return 0, false
+	}
+	// Future value at the end of every contribution minus the end equity
+	excess := func(r float64) float64 {
+		fv := points[0].equity.InexactFloat64() * math.Pow(1+r, T)
+		for _, p := range points[1:] {
+			if !p.flow.IsZero() {
+				fv += p.flow.InexactFloat64() * math.Pow(1+r, T-p.at.Sub(origin).Hours()/24)
+			}
+		}
+		return fv - end.equity.InexactFloat64()
+	}
+
+	lo, hi := -0.5, 0.5 // Daily rates: far beyond any real month
+	if excess(lo) > 0 || excess(hi) < 0 {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (lo + hi) / 2
+		if excess(mid) > 0 {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
+
+// cashBenchmark is what the same money would have earned at annualPct, accruing daily
+// on each previous close: the period return in % and the dollars.
+func cashBenchmark(points []equityPoint, annualPct float64) (decimal.Decimal, decimal.Decimal) {
+	if len(points) < 2 {
+		return decimal.Zero, decimal.Zero
+	}
+	rate := 1 + annualPct/100
+	usd := 0.0
+	for i := 1; i < len(points); i++ {
+		days := points[i].at.Sub(points[i-1].at).Hours() / 24
+		usd += points[i-1].equity.InexactFloat64() * (math.Pow(rate, days/365) - 1)
+	}
+	days := points[len(points)-1].at.Sub(points[0].at).Hours() / 24
+	return decimal.NewFromFloat((math.Pow(rate, days/365) - 1) * 100), decimal.NewFromFloat(usd)
+}
+
+// computeReturns summarizes the points (the first one is the base close).
+func computeReturns(points []equityPoint) (periodReturns, bool) {
+	if len(points) < 2 {
+		return periodReturns{}, false
+	}
+	r := periodReturns{start: points[0], end: points[len(points)-1]}
+	r.days = int(math.Round(r.end.at.Sub(r.start.at).Hours() / 24))
+	for _, p := range points[1:] {
+		r.pl = r.pl.Add(p.pl)
+		if p.flow.IsPositive() {
+			r.deposits = r.deposits.Add(p.flow)
+		} else {
+			r.withdrawals = r.withdrawals.Add(p.flow.Neg())
+		}
+	}
+	r.twr = timeWeightedReturn(points)
+	if daily, ok := moneyWeightedDaily(points); ok {
+		r.mwrOK = true
+		r.mwr = decimal.NewFromFloat((math.Pow(1+daily, float64(r.days)) - 1) * 100)
+		r.mwrAnnualized = decimal.NewFromFloat((math.Pow(1+daily, 365) - 1) * 100)
+	}
+	return r, true
+}
+
+// returnsReport renders TWR and MWR for [start, end) from the broker's daily history.
+func (w *Watcher) returnsReport(title string, start, end time.Time) string {
+	// Reach back to the close before start; the period unit of the history API is days
+	days := int(w.since(start).Hours()/24) + 5
+	h, err := w.provider.GetPortfolioHistory(w.ctx, fmt.Sprintf("%dD", days), "1D")
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read portfolio history: %v", err)
+	}
+	points := equityPoints(h, start, end)
+	r, ok := computeReturns(points)
+	if !ok {
+		return fmt.Sprintf("📈 Not enough equity history for %s.", title)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📈 *RETURNS (%s)*\n", title))
+	sb.WriteString(fmt.Sprintf("Equity: $%s (%s) → $%s (%s)\n",
+		r.start.equity.StringFixed(2), r.start.at.In(config.CetLoc).Format("01-02"),
+		r.end.equity.StringFixed(2), r.end.at.In(config.CetLoc).Format("01-02")))
+	sb.WriteString(fmt.Sprintf("Trading P/L: %s\n", plString(r.pl)))
+	if r.deposits.IsPositive() || r.withdrawals.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Deposits: $%s | Withdrawals: $%s\n", r.deposits.StringFixed(2), r.withdrawals.StringFixed(2)))
+	}
+	sb.WriteString(fmt.Sprintf("\n*Time-weighted (TWR)*: %s%%\n", r.twr.StringFixed(2)))
+	if r.mwrOK {
+		sb.WriteString(fmt.Sprintf("*Money-weighted (MWR)*: %s%% (%s%% annualized)\n", r.mwr.StringFixed(2), r.mwrAnnualized.StringFixed(1)))
+	} else {
+		sb.WriteString("*Money-weighted (MWR)*: n/a (no solution for these flows)\n")
+	}
+
+	// The honest benchmark: what the same money would have earned sitting in cash
+	cashPct, cashUSD := cashBenchmark(points, w.config.RiskFreeRatePct)
+	sb.WriteString(fmt.Sprintf("\n*Vs Cash (%.2f%%/yr)*\nCash would have made: %s%% ($%s)\nExcess over cash: %s pts | %s\n",
+		w.config.RiskFreeRatePct, cashPct.StringFixed(2), cashUSD.StringFixed(2),
+		r.twr.Sub(cashPct).StringFixed(2), plString(r.pl.Sub(cashUSD))))
+	if alp := w.alpacaProvider(); alp != nil {
+		if interest, err := alp.CashInterest(w.ctx, r.start.at, end); err == nil && !interest.IsZero() {
+			sb.WriteString(fmt.Sprintf("Interest credited on idle cash: $%s\n", interest.StringFixed(2)))
+		}
+	}
+	if r.twr.GreaterThan(cashPct) {
+		sb.WriteString("🟢 Active trading beat holding cash.\n")
+	} else {
+		sb.WriteString("🔴 Holding cash would have done better.\n")
+	}
+	sb.WriteString("\n_TWR measures the strategy and ignores when cash came in. MWR is the return on your money, so it rewards adding before good days._")
+	return sb.String()
+}
+
+// handleReturnsCommand shows a month's TWR and MWR. /returns [YYYY-MM] (default: current month)
+func (w *Watcher) handleReturnsCommand(parts []string) string {
+	month := w.clock.Now().In(config.CetLoc).Format("2006-01")
+	if len(parts) >= 2 {
+		month = parts[1]
+	}
+	start, end, err := monthRange(month)
+	if err != nil || start.After(w.clock.Now()) {
+		return "Usage: /returns [YYYY-MM]"
+	}
+	return w.returnsReport(month, start, end)
+}
+
+// checkMonthlyReturnsReport sends the previous month's returns on the first poll of a new month.
+func (w *Watcher) checkMonthlyReturnsReport() {
+	if w.alpacaProvider() == nil {
+		return // Needs the broker's daily equity history
+	}
+	current := w.clock.Now().In(config.CetLoc).Format("2006-01")
+	w.mu.Lock()
+	last := w.state.LastReturnsMonth
+	if last == current {
+		w.mu.Unlock()
+		return
+	}
+	w.state.LastReturnsMonth = current
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	if last == "" {
+		return // First run: start tracking from this month
+	}
+	curStart, _, _ := monthRange(current)
+	prev := curStart.AddDate(0, -1, 0).Format("2006-01")
+	if start, end, err := monthRange(prev); err == nil {
+		w.notifyRoutine(w.returnsReport(prev, start, end))
+	}
+}
@@ -0,0 +1,266 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/ai"
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	strategyReviewDays   = 7
+	strategyReviewPrompt = "strategy_review.md"
+	strategyReviewTTL    = 24 * time.Hour // The apply button expires after this
+)
+
+// pendingStrategyReview is the last review awaiting an APPLY/DISMISS tap.
+type pendingStrategyReview struct {
+	id     string
+	review ai.StrategyReview
+	at     time.Time
+}
+
+// strategyParamValues maps the review's parameter names to the current config defaults.
+func (w *Watcher) strategyParamValues() map[string]float64 {
+	return map[string]float64{
+		"stop_loss_pct":     w.config.DefaultStopLossPct,
+		"take_profit_pct":   w.config.DefaultTakeProfitPct,
+		"trailing_stop_pct": w.config.DefaultTrailingStopPct,
+	}
+}
+
+// checkWeeklyStrategyReview runs the AI strategy review once every 7 days.
+func (w *Watcher) checkWeeklyStrategyReview() {
+	if w.config.GeminiAPIKey == "" {
+		return
+	}
+	w.mu.RLock()
+	last := w.state.LastStrategyReview
+	w.mu.RUnlock()
+
+	if t, err := time.Parse(time.RFC3339, last); err == nil && w.since(t) < strategyReviewDays*24*time.Hour {
+		return
+	}
+
+	w.mu.Lock()
+	w.state.LastStrategyReview = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	go w.runStrategyReview(false)
+}
+
+// handleReviewCommand runs the strategy review on demand. /review
+func (w *Watcher) handleReviewCommand() string {
+	if w.config.GeminiAPIKey == "" {
+		return "⚠️ AI is not configured (GEMINI_API_KEY)."
+	}
+	go w.runStrategyReview(true)
+	return fmt.Sprintf("⏳ Strategy review of the last %d days started... Stand by for report.", strategyReviewDays)
+}
+
+// runStrategyReview gathers the week's journal, stats and missed signals, asks the
+// model for suggestions and sends them with an APPLY button when there is anything to apply.
+func (w *Watcher) runStrategyReview(isManual bool) {
+	if !w.reserveAICall() {
+		log.Printf("Strategy review skipped: daily AI call limit reached (%d).", w.config.AIDailyCallLimit)
+		if isManual {
+			telegram.Notify(fmt.Sprintf("⚠️ AI daily call limit reached (%d). Try again tomorrow.", w.config.AIDailyCallLimit))
+		}
+		return
+	}
+
+	sysInstr, err := os.ReadFile(strategyReviewPrompt)
+	if err != nil {
+		log.Printf("Strategy review: prompt missing: %v", err)
+		return
+	}
+
+	input := w.strategyReviewInput(w.clock.Now().AddDate(0, 0, -strategyReviewDays))
+	review, err := ai.NewClient().ReviewStrategy(string(sysInstr), input)
+	if err != nil {
+		log.Printf("Strategy review failed: %v", err)
+		telegram.Notify(fmt.Sprintf("⚠️ Strategy Review Failed:\n```\n%v\n```", err))
+		return
+	}
+	w.filterStrategyReview(review, input)
+
+	msg := formatStrategyReview(review, input)
+	if len(review.ParameterChanges) == 0 && len(review.DropTickers) == 0 {
+		telegram.Notify(msg)
+		return
+	}
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingReview = &pendingStrategyReview{id: id, review: *review, at: w.clock.Now()}
+	w.mu.Unlock()
+
+	telegram.SendInteractiveMessage(msg+"\n\nApply the suggested settings?", []telegram.Button{
+		{Text: "✅ APPLY", CallbackData: "STRATEGY_APPLY_" + id},
+		{Text: "❌ DISMISS", CallbackData: "STRATEGY_DISMISS_" + id},
+	})
+}
+
+// strategyReviewInput builds the review payload for trades closed since start.
+func (w *Watcher) strategyReviewInput(start time.Time) ai.StrategyReviewInput {
+	now := w.clock.Now()
+	input := ai.StrategyReviewInput{
+		PeriodStart: start.In(config.CetLoc).Format("2006-01-02"),
+		PeriodEnd:   now.In(config.CetLoc).Format("2006-01-02"),
+		Parameters:  w.strategyParamValues(),
+	}
+
+	var week []TradeRecord
+	if journal, err := w.buildTradeJournal(); err == nil {
+		for _, t := range journal {
+			if t.ClosedAt.After(start) {
+				week = append(week, t)
+			}
+		}
+	} else {
+		log.Printf("Strategy review: journal unavailable: %v", err)
+	}
+	stats := computeTradeStats(week)
+	realized := decimal.Zero
+	for _, t := range week {
+		realized = realized.Add(t.PL)
+	}
+	input.Journal = week
+	input.Stats = map[string]interface{}{
+		"trades":       stats.Trades,
+		"win_rate":     stats.WinRate.Round(2),
+		"payoff_ratio": stats.PayoffRatio.Round(2),
+		"realized_pl":  realized.Round(2),
+	}
+
+	// Missed signals: anything dismissed, cancelled or left to expire
+	if events, err := storage.LoadAudit(""); err == nil {
+		for _, e := range events {
+			if e.Event != auditCancelled || e.Time.Before(start) {
+				continue
+			}
+			line := fmt.Sprintf("%s %s: %s (%s)", e.Time.In(config.CetLoc).Format("2006-01-02"), e.Ticker, e.Detail, e.Actor)
+			if e.Price.IsPositive() {
+				line += " @ $" + e.Price.StringFixed(2)
+			}
+			input.MissedSignals = append(input.MissedSignals, line)
+		}
+	}
+
+	w.mu.RLock()
+	input.Watchlist = w.watchlistTickers()
+	input.Positions = w.state.Positions
+	w.mu.RUnlock()
+	return input
+}
+
+// filterStrategyReview drops suggestions that cannot be applied: unknown parameters,
+// out-of-range values, no-op changes and tickers not on the runtime watchlist.
+func (w *Watcher) filterStrategyReview(review *ai.StrategyReview, input ai.StrategyReviewInput) {
+	var params []ai.ParameterChange
+	for _, c := range review.ParameterChanges {
+		current, ok := input.Parameters[c.Parameter]
+		if !ok || c.Suggested <= 0 || c.Suggested >= 100 || c.Suggested == current {
+			log.Printf("Strategy review: ignoring parameter suggestion %+v", c)
+			continue
+		}
+		c.Current = current
+		params = append(params, c)
+	}
+	review.ParameterChanges = params
+
+	var drops []ai.TickerNote
+	for _, d := range review.DropTickers {
+		if contains(input.Watchlist, d.Ticker) {
+			drops = append(drops, d)
+		} else {
+			log.Printf("Strategy review: ignoring drop of %s (not on watchlist)", d.Ticker)
+		}
+	}
+	review.DropTickers = drops
+}
+
+func formatStrategyReview(review *ai.StrategyReview, input ai.StrategyReviewInput) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧠 *STRATEGY REVIEW (%s → %s)*\n", input.PeriodStart, input.PeriodEnd))
+	if review.Summary != "" {
+		sb.WriteString(review.Summary + "\n")
+	}
+	if len(review.ParameterChanges) > 0 {
+		sb.WriteString("\n*Parameter Tweaks*\n")
+		for _, c := range review.ParameterChanges {
+			sb.WriteString(fmt.Sprintf("• %s: %.1f%% → %.1f%% (%s)\n", c.Parameter, c.Current, c.Suggested, c.Reason))
+		}
+	}
+	if len(review.DropTickers) > 0 {
+		sb.WriteString("\n*Drop From Watchlist*\n")
+		for _, d := range review.DropTickers {
+			sb.WriteString(fmt.Sprintf("• %s (%s)\n", d.Ticker, d.Reason))
+		}
+	}
+	if len(review.Notes) > 0 {
+		sb.WriteString("\n*Notes*\n")
+		for _, n := range review.Notes {
+			sb.WriteString("• " + n + "\n")
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nModel: %s", review.Model))
+	return sb.String()
+}
+
+// handleStrategyCallback applies or dismisses the pending review (STRATEGY_APPLY_<id>).
+func (w *Watcher) handleStrategyCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid strategy callback data."
+	}
+
+	w.mu.Lock()
+	pending := w.pendingReview
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > strategyReviewTTL {
+		w.mu.Unlock()
+		return "⚠️ Strategy review expired or already processed."
+	}
+	w.pendingReview = nil
+	w.mu.Unlock()
+
+	if parts[1] != "APPLY" {
+		return "❌ Strategy suggestions dismissed."
+	}
+
+	var applied []string
+	w.mu.Lock()
+	for _, c := range pending.review.ParameterChanges {
+		switch c.Parameter {
+		case "stop_loss_pct":
+			w.state.Settings.DefaultStopLossPct = c.Suggested
+		case "take_profit_pct":
+			w.state.Settings.DefaultTakeProfitPct = c.Suggested
+		case "trailing_stop_pct":
+			w.state.Settings.DefaultTrailingStopPct = c.Suggested
+		default:
+			continue
+		}
+		applied = append(applied, fmt.Sprintf("%s=%.1f%%", c.Parameter, c.Suggested))
+	}
+	w.saveStateLocked()
+	w.mu.Unlock()
+	w.applySettingsOverrides()
+
+	for _, d := range pending.review.DropTickers {
+		w.removeFromWatchlist(d.Ticker)
+		applied = append(applied, "dropped "+d.Ticker)
+	}
+
+	log.Printf("Strategy review applied: %s", strings.Join(applied, ", "))
+	return fmt.Sprintf("✅ Applied: %s\nNew defaults affect new positions only. Saved to portfolio_state.json (overrides .env on restart).", joinOrNone(applied))
+}
@@ -8,6 +8,7 @@ import (
 
 	"alpha_trading/internal/ai"
 	"alpha_trading/internal/config"
+	"alpha_trading/internal/symbols"
 	"alpha_trading/internal/telegram"
 
 	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
@@ -16,9 +17,13 @@ import (
 
 type PendingAction struct {
 	Ticker       string
-	Action       string // "SELL" (for now)
+	Action       string    // "SELL" (for now)
+	Trigger      string    // "SL", "TP" or "TS" for trigger alerts; empty for AI proposals
+	DetectedAt   time.Time // First breaching check (latency tracking)
+	AlertedAt    time.Time // First alert sent; Timestamp is refreshed by re-alerts
 	TriggerPrice decimal.Decimal
 	Timestamp    time.Time
+	ReAlerts     int // Stop reminders still scheduled: the action outlives its TTL until the last one
 }
 
 type PendingProposal struct {
@@ -29,17 +34,48 @@ type PendingProposal struct {
 	StopLoss        decimal.Decimal
 	TakeProfit      decimal.Decimal
 	TrailingStopPct decimal.Decimal
+	LimitPrice      decimal.Decimal // /buylimit; zero = market (or ask-based limit when ExtendedHours)
+	TimeInForce     string          // "day" or "gtc" for limit orders
+	ExtendedHours   bool            // Submit as an extended-hours limit order
+	Notional        decimal.Decimal // Dollar amount to buy; Qty is then the estimate at Price
+	Book            string          // Virtual sub-portfolio the position joins ("" = unassigned)
+	Confirm         string          // Confirmation tier (confirmAuto, confirmButton, confirmPIN)
+	ArmedAt         time.Time       // EXECUTE tapped on a PIN-tier proposal, waiting for /pin
+	PINFailures     int
+	Sweep           bool      // Cash sweep into SWEEP_ETF: no position is opened
+	Rotation        *Rotation // PIN-tier AI rotation: the PIN releases both legs
 	Timestamp       time.Time
 }
 
-// checkRisk iterates positions and checks for triggers.
-func (w *Watcher) checkRisk() {
+// checkRisk iterates positions and checks for triggers. A hot pass (between polls)
+// checks only hot positions (/priority).
+func (w *Watcher) checkRisk(hotPass bool) {
+	// One batched snapshot for the positions due, fetched before taking the write lock
+	w.mu.RLock()
+	var tickers, stagnant []string
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && w.riskDue(p, hotPass) {
+			tickers = append(tickers, p.Ticker)
+			if w.stagnationDue(p) {
+				stagnant = append(stagnant, p.Ticker)
+			}
+		}
+	}
+	w.mu.RUnlock()
+	if hotPass && len(tickers) == 0 {
+		return
+	}
+	snaps := w.getSnapshots(tickers)
+	for _, t := range stagnant {
+		w.dailyATR(t) // Daily bars for the dead money guard, cached for the day
+	}
+
 	w.mu.Lock()
 	// defer w.mu.Unlock() removed to prevent double-unlock with manual Unlock() below
 
 	// --- QUEUED ORDER CHECK (Empty Portfolio) ---
-	if len(w.state.Positions) == 0 {
-		openOrders, err := w.provider.ListOrders("open")
+	if len(w.state.Positions) == 0 && !hotPass {
+		openOrders, err := w.provider.ListOrders(w.ctx, "open")
 		if err == nil && len(openOrders) > 0 {
 			var sb strings.Builder
 			sb.WriteString("⏳ *WAITING FOR MARKET OPEN*\n")
@@ -55,74 +91,69 @@ func (w *Watcher) checkRisk() {
 	}
 
 	// --- PENDING ACTION CLEANUP ---
-	// Remove expired actions so we don't block new alerts forever if user ignores them.
-	ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
-	for ticker, action := range w.pendingActions {
-		if time.Since(action.Timestamp) > ttl {
-			delete(w.pendingActions, ticker)
-			// Optional: Log or notify?
-			// log.Printf("Expired pending action for %s", ticker)
-		}
-	}
+	w.expirePendingActionsLocked()
 
 	// --- POSITION CHECK LOGIC ---
 	for i, pos := range w.state.Positions {
-		if pos.Status != "ACTIVE" {
+		if pos.Status != "ACTIVE" || !w.riskDue(pos, hotPass) {
 			continue
 		}
 
-		price, err := w.provider.GetPrice(pos.Ticker)
-		if err != nil {
-			log.Printf("ERROR: Fetching price for %s: %v", pos.Ticker, err)
-			continue
+		snap := snaps[pos.Ticker]
+		price := snap.Last
+		if !price.IsPositive() {
+			var err error
+			price, err = w.provider.GetPrice(w.ctx, pos.Ticker)
+			if err != nil {
+				log.Printf("ERROR: Fetching price for %s: %v", pos.Ticker, err)
+				continue
+			}
 		}
 
+		// Noise filter: evaluate triggers against the quote instead of the last print (TRIGGER_PRICE_SOURCE)
+		stopPrice, targetPrice := w.triggerPrices(pos.Ticker, price, snap.Bid, snap.Ask)
+
 		// Update High Water Mark if applicable
 		// Spec 52: HWM Monotonicity: HWM = max(stored_HWM, current_price)
-		if pos.HighWaterMark.IsZero() || price.GreaterThan(pos.HighWaterMark) {
-			log.Printf("[%s] New High Water Mark: $%s (Old: $%s)", pos.Ticker, price.StringFixed(2), pos.HighWaterMark.StringFixed(2))
-			w.state.Positions[i].HighWaterMark = price
-			pos.HighWaterMark = price // Update local copy for calculations below
+		if pos.HighWaterMark.IsZero() || targetPrice.GreaterThan(pos.HighWaterMark) {
+			log.Printf("[%s] New High Water Mark: $%s (Old: $%s)", pos.Ticker, targetPrice.StringFixed(2), pos.HighWaterMark.StringFixed(2))
+			w.state.Positions[i].HighWaterMark = targetPrice
+			pos.HighWaterMark = targetPrice // Update local copy for calculations below
 		}
 
 		// Spec 66: Temporal Stagnation Check (Dead Money Guard)
-		if !pos.OpenedAt.IsZero() {
-			hoursOpen := time.Since(pos.OpenedAt).Hours()
-			if hoursOpen > float64(w.config.MaxStagnationHours) {
-				// Calculate P/L %
-				diff := price.Sub(pos.EntryPrice)
-				pct := diff.Div(pos.EntryPrice).Mul(decimal.NewFromInt(100))
-
-				// Trigger if "Flat" (Absolute change < 1.0%)
-				if pct.Abs().LessThan(decimal.NewFromFloat(1.0)) {
-					key := fmt.Sprintf("%s_STAGNATION", pos.Ticker)
-					// Alert once every 24h
-					if last, ok := w.lastAlerts[key]; !ok || time.Since(last) > 24*time.Hour {
-						telegram.Notify(fmt.Sprintf("⏳ STAGNATION ALERT: %s has been flat for %d days (%.2f%%). Consider manual liquidation to free up budget.",
-							pos.Ticker, int(hoursOpen/24), pct.InexactFloat64()))
-						w.lastAlerts[key] = time.Now()
-					}
-				}
-			}
-		}
+		w.checkStagnationLocked(pos, price)
 
 		log.Printf("[%s] Current: $%s | SL: $%s | TP: $%s | HWM: $%s", pos.Ticker, price.StringFixed(2), pos.StopLoss.StringFixed(2), pos.TakeProfit.StringFixed(2), pos.HighWaterMark.StringFixed(2))
 
 		// Check Trailing Stop
-		triggeredTS := false
+		touchedTS := false
+		trailingTriggerPrice := decimal.Zero
 		if pos.TrailingStopPct.GreaterThan(decimal.Zero) && pos.HighWaterMark.GreaterThan(decimal.Zero) {
 			// trailingTrigger = HWM * (1 - pct/100)
 			multiplier := decimal.NewFromInt(100).Sub(pos.TrailingStopPct).Div(decimal.NewFromInt(100))
-			trailingTriggerPrice := pos.HighWaterMark.Mul(multiplier)
+			trailingTriggerPrice = pos.HighWaterMark.Mul(multiplier)
 
-			if price.LessThanOrEqual(trailingTriggerPrice) {
-				triggeredTS = true
-				log.Printf("[%s] Trailing Stop Triggered! Price $%s <= Trigger $%s", pos.Ticker, price.StringFixed(2), trailingTriggerPrice.StringFixed(2))
+			if stopPrice.LessThanOrEqual(trailingTriggerPrice) {
+				touchedTS = true
+				log.Printf("[%s] Trailing Stop Triggered! Price $%s <= Trigger $%s", pos.Ticker, stopPrice.StringFixed(2), trailingTriggerPrice.StringFixed(2))
 			}
 		}
 
-		triggeredSL := !pos.StopLoss.IsZero() && price.LessThanOrEqual(pos.StopLoss)
-		triggeredTP := !pos.TakeProfit.IsZero() && price.GreaterThanOrEqual(pos.TakeProfit)
+		// A broker OCO executes SL/TP itself: only the trailing stop stays local
+		brokerLinked := pos.BrokerOCOID != ""
+		touchedSL := !brokerLinked && !pos.StopLoss.IsZero() && stopPrice.LessThanOrEqual(pos.StopLoss)
+		touchedTP := !brokerLinked && !pos.TakeProfit.IsZero() && targetPrice.GreaterThanOrEqual(pos.TakeProfit)
+		touchedTS = touchedTS && pos.BrokerTrailID == "" // Native trailing stop: HWM is for reporting only
+
+		// Hysteresis: a touch only counts once it breaches by the configured band
+		// or persists across consecutive checks (reduces whipsaw on wide spreads).
+		triggered := map[string]bool{
+			"TP": w.confirmTrigger(pos.Ticker, "TP", touchedTP, breachBps(pos.TakeProfit, targetPrice, false)),
+			"SL": w.confirmTrigger(pos.Ticker, "SL", touchedSL, breachBps(pos.StopLoss, stopPrice, true)),
+			"TS": w.confirmTrigger(pos.Ticker, "TS", touchedTS, breachBps(trailingTriggerPrice, stopPrice, true)),
+		}
+		triggeredSL, triggeredTP, triggeredTS := triggered["SL"], triggered["TP"], triggered["TS"]
 
 		// Check triggers (Stop Loss / Take Profit / Trailing Stop)
 		if triggeredSL || triggeredTP || triggeredTS {
@@ -136,37 +167,43 @@ func (w *Watcher) checkRisk() {
 			// Since PollInterval is usually 60m, this effectively limits to once per poll.
 			// But if Interval is small, this helps.
 			if lastAlert, ok := w.lastAlerts[pos.Ticker]; ok {
-				if time.Since(lastAlert) < 15*time.Minute {
+				if w.since(lastAlert) < 15*time.Minute {
 					continue
 				}
 			}
 
 			// 3. Precedence Logic (Spec 36)
-			// TP > SL > TS (SL is hard stop, usually takes precedence over TS if both hit)
-			actionType := "STOP LOSS"
+			// Default TP > SL > TS; overridable via TRIGGER_PRECEDENCE.
 			triggerType := "SL"
-
-			if triggeredTP {
-				actionType = "TAKE PROFIT"
-				triggerType = "TP"
-			} else if triggeredSL {
-				actionType = "STOP LOSS"
-				triggerType = "SL"
-			} else if triggeredTS {
-				actionType = "TRAILING STOP"
-				triggerType = "TS"
+			for _, t := range w.triggerPrecedence() {
+				if triggered[t] {
+					triggerType = t
+					break
+				}
 			}
+			actionType := triggerActionNames[triggerType]
 
 			// Create Pending Action
+			alertedAt := w.clock.Now()
+			reAlerts := 0
+			if triggerType != "TP" {
+				reAlerts = w.scheduleStopReAlert(pos.Ticker, alertedAt, 1) // Escalate if the stop goes unanswered
+			}
 			w.pendingActions[pos.Ticker] = PendingAction{
 				Ticker:       pos.Ticker,
 				Action:       "SELL", // Always sell for TP/SL/TS
+				Trigger:      triggerType,
 				TriggerPrice: price,
-				Timestamp:    time.Now(),
+				Timestamp:    alertedAt,
+				DetectedAt:   w.triggerDetectedAt(pos.Ticker, triggerType),
+				AlertedAt:    alertedAt,
+				ReAlerts:     reAlerts,
 			}
+			w.scheduleTriggerExpiry(pos.Ticker, alertedAt) // Vacation mode fallback
 
 			// Update Last Alert
-			w.lastAlerts[pos.Ticker] = time.Now()
+			w.lastAlerts[pos.Ticker] = w.clock.Now()
+			w.recordAudit(pos.Ticker, auditTrigger, "SYSTEM", price, actionType+" alert sent")
 
 			// Send Interactive Message
 			msg := fmt.Sprintf("🚨 *POLL ALERT: %s*\nAsset: %s\nPrice: $%s\nAction: SELL REQUIRED\n\n⏱️ Valid for %d seconds.",
@@ -183,7 +220,7 @@ func (w *Watcher) checkRisk() {
 
 	// Spec 32: Automated Operational Awareness
 
-	w.state.LastSync = time.Now().In(config.CetLoc).Format(time.RFC3339)
+	w.state.LastSync = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
 	w.mu.Unlock() // Unlock before save to prevent deadlock if saveState acquires lock
 	w.saveState()
 }
@@ -191,7 +228,7 @@ func (w *Watcher) checkRisk() {
 // ensureSequentialClearance ensures all open orders for a ticker are canceled and cleared (Spec 54).
 func (w *Watcher) ensureSequentialClearance(ticker string) error {
 	// 1. Initial Check
-	orders, err := w.provider.ListOrders("open")
+	orders, err := w.provider.ListOrders(w.ctx, "open")
 	if err != nil {
 		return fmt.Errorf("failed to list orders: %v", err)
 	}
@@ -200,7 +237,7 @@ func (w *Watcher) ensureSequentialClearance(ticker string) error {
 	for _, o := range orders {
 		if o.Symbol == ticker {
 			hasOrders = true
-			if err := w.provider.CancelOrder(o.ID); err != nil {
+			if err := w.provider.CancelOrder(w.ctx, o.ID); err != nil {
 				log.Printf("Warning: Failed to cancel order %s: %v", o.ID, err)
 			}
 		}
@@ -212,8 +249,8 @@ func (w *Watcher) ensureSequentialClearance(ticker string) error {
 
 	// 2. Poll until cleared (Max 5 retries, 500ms apart)
 	for i := 0; i < 5; i++ {
-		time.Sleep(500 * time.Millisecond)
-		orders, err = w.provider.ListOrders("open")
+		w.clock.Sleep(500 * time.Millisecond)
+		orders, err = w.provider.ListOrders(w.ctx, "open")
 		if err != nil {
 			continue
 		}
@@ -236,40 +273,56 @@ func (w *Watcher) ensureSequentialClearance(ticker string) error {
 
 // verifyOrderExecution polls for order status validation (Spec 53).
 func (w *Watcher) verifyOrderExecution(orderID string) (*alpaca.Order, error) {
+	// Event-driven when the trade stream is up: the fill/cancel/reject arrives as it happens
+	if order, streamed := w.orders.wait(orderID, orderVerifyTimeout); streamed {
+		if order == nil {
+			// No terminal event in time (still working, or an event was lost): the broker has the last word
+			var err error
+			if order, err = w.provider.GetOrder(w.ctx, orderID); err != nil {
+				return nil, err
+			}
+		}
+		return w.settleOrder(order)
+	}
+
 	// Query every 1 second for 5 seconds
 	for i := 0; i < 5; i++ {
-		time.Sleep(1 * time.Second)
-		order, err := w.provider.GetOrder(orderID)
+		w.clock.Sleep(1 * time.Second)
+		order, err := w.provider.GetOrder(w.ctx, orderID)
 		if err != nil {
 			log.Printf("Verification poll failed: %v", err)
 			continue
 		}
-
-		status := strings.ToLower(order.Status)
-		if status == "filled" {
-			return order, nil
-		}
-
-		if status == "canceled" || status == "rejected" || status == "expired" {
-			// Spec 56: Re-Sync Enforcement on Execution Failure
-			log.Printf("🚨 Order %s failed with status %s. Triggering Re-Sync.", orderID, status)
-			if count, _, syncErr := w.syncState(); syncErr != nil {
-				log.Printf("CRITICAL: Re-Sync failed after order failure: %v", syncErr)
-			} else {
-				log.Printf("Re-Sync complete. active positions: %d", count)
-			}
-			return order, fmt.Errorf("order terminated with status: %s", status)
+		if terminal(order.Status) {
+			return w.settleOrder(order)
 		}
 	}
 
 	// If we get here, it's still pending/accepted/new.
 	// We return the last known state.
-	return w.provider.GetOrder(orderID)
+	return w.provider.GetOrder(w.ctx, orderID)
+}
+
+// settleOrder turns an order's state into the verification result: failed orders
+// trigger a re-sync and an error, anything else is returned as is.
+func (w *Watcher) settleOrder(order *alpaca.Order) (*alpaca.Order, error) {
+	status := strings.ToLower(order.Status)
+	if status == "canceled" || status == "rejected" || status == "expired" {
+		// Spec 56: Re-Sync Enforcement on Execution Failure
+		log.Printf("🚨 Order %s failed with status %s. Triggering Re-Sync.", order.ID, status)
+		if count, _, syncErr := w.syncState(); syncErr != nil {
+			log.Printf("CRITICAL: Re-Sync failed after order failure: %v", syncErr)
+		} else {
+			log.Printf("Re-Sync complete. active positions: %d", count)
+		}
+		return order, fmt.Errorf("order terminated with status: %s", status)
+	}
+	return order, nil
 }
 
 // handleAIResult processes the AI analysis (Spec 60, 61, 62).
 func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.PortfolioSnapshot, isManual bool) {
-	log.Printf("🤖 AI Analysis: Recommends %s (Confidence: %.2f)", analysis.Recommendation, analysis.ConfidenceScore)
+	log.Printf("🤖 AI Analysis (%s): Recommends %s (Confidence: %.2f)", analysis.Model, analysis.Recommendation, analysis.ConfidenceScore)
 
 	// Tier 3: Low Priority (Log only)
 	if analysis.ConfidenceScore < 0.70 { // Spec 59 Guardrail
@@ -280,6 +333,21 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 		return
 	}
 
+	// Output sanitization: drop unknown verbs/tickers, cap buys to the budget
+	if rejected := w.sanitizeAICommand(analysis, snapshot); len(rejected) > 0 {
+		msg := fmt.Sprintf("🛡️ AI Guard stripped %d command(s):\n%s", len(rejected), strings.Join(rejected, "\n"))
+		if analysis.ActionCommand == "" && analysis.Recommendation != "HOLD" {
+			log.Printf("AI Recommendation %s dropped: no valid commands left.", analysis.Recommendation)
+			if isManual {
+				telegram.Notify(msg + "\nNo valid commands left. Proposal dropped.")
+			}
+			return
+		}
+		if isManual {
+			telegram.Notify(msg)
+		}
+	}
+
 	// Spec 79: Multi-Buy Permission (Spec 75 Decommissioned)
 	// We allow multiple /buy commands.
 
@@ -289,6 +357,9 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 
 	totalBatchCost := decimal.Zero
 	commands := strings.Split(analysis.ActionCommand, ";")
+	var complianceHits []string
+	var warnings []string // Advisory only: SECTOR_CAP_MODE=warn, correlated buys
+	var batchTickers []string
 
 	// Pre-calculation loop
 	for _, cmd := range commands {
@@ -296,7 +367,7 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 		parts := strings.Fields(cmd)
 		if len(parts) >= 3 && strings.ToLower(parts[0]) == "/buy" {
 			// /buy TICKER QTY
-			bTicker := strings.ToUpper(parts[1])
+			bTicker := symbols.Normalize(parts[1])
 			qtyStr := parts[2]
 
 			qty, err := decimal.NewFromString(qtyStr)
@@ -306,7 +377,7 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 			}
 
 			// JIT Price Check
-			price, err := w.provider.GetPrice(bTicker)
+			price, err := w.provider.GetPrice(w.ctx, bTicker)
 			if err != nil {
 				log.Printf("AI Batch Error: Price fetch failed for %s", bTicker)
 				continue
@@ -314,9 +385,33 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 
 			cost := qty.Mul(price)
 			totalBatchCost = totalBatchCost.Add(cost)
+
+			if msg, ok := w.checkCompliance(bTicker, qty, price); !ok {
+				complianceHits = append(complianceHits, msg)
+			}
+			if w.config.SectorCapMode == "warn" {
+				if warn := w.sectorCapCheck(bTicker, cost); warn != "" {
+					warnings = append(warnings, "⚠️ Sector cap: "+warn)
+				}
+			}
+			// Against the holdings and the batch's earlier buys
+			if warn := w.correlationWarning(bTicker, batchTickers); warn != "" {
+				warnings = append(warnings, warn)
+			}
+			batchTickers = append(batchTickers, bTicker)
 		}
 	}
 
+	// Compliance: one rule hit rejects the whole batch (same policy as Spec 80)
+	if len(complianceHits) > 0 {
+		msg := fmt.Sprintf("❌ Batch Rejection (Compliance):\n%s\nCommand: %s", strings.Join(complianceHits, "\n"), analysis.ActionCommand)
+		log.Printf("[AI_COMPLIANCE_REJECTION] %s", msg)
+		if isManual {
+			telegram.Notify(msg)
+		}
+		return
+	}
+
 	// Check against Budget
 	// AvailableBudget is updated by JIT Sync in buildPortfolioSnapshot
 	// BUT, we need to be sure. w.state is locked? No.
@@ -338,7 +433,7 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 	ticker := ""
 	parts := strings.Fields(analysis.ActionCommand)
 	if len(parts) > 1 {
-		ticker = strings.ToUpper(parts[1])
+		ticker = symbols.Normalize(parts[1])
 	}
 
 	// Spec 62: Telemetry
@@ -359,15 +454,18 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 
 	// Tier 1: Actionable
 	msg := fmt.Sprintf("🤖 *AI STRATEGY REPORT: %s*\n"+
-		"Conviction: %.2f | Risk: %s\n"+
+		"Conviction: %.2f | Risk: %s | Model: %s\n"+
 		"Critique: %s\n"+
 		"Recommendation: %s\n"+
 		"Command: `%s`",
-		ticker, analysis.ConfidenceScore, analysis.RiskAssessment, analysis.Analysis, analysis.Recommendation, analysis.ActionCommand)
+		ticker, analysis.ConfidenceScore, analysis.RiskAssessment, analysis.Model, analysis.Analysis, analysis.Recommendation, analysis.ActionCommand)
 
 	if totalBatchCost.GreaterThan(decimal.Zero) {
 		msg += fmt.Sprintf("\n💰 **Total Batch Cost**: $%s", totalBatchCost.StringFixed(2))
 	}
+	for _, warn := range warnings {
+		msg += "\n" + warn
+	}
 
 	// Route based on Recommendation
 	switch analysis.Recommendation {
@@ -391,15 +489,16 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 		// "buttons expire after 300s".
 
 		// Implementation: Store the command payload mapped to a unique ID.
-		actionID := fmt.Sprintf("AI_%d_%s", time.Now().UnixNano(), ticker)
+		actionID := fmt.Sprintf("AI_%d_%s", w.clock.Now().UnixNano(), ticker)
 
 		w.mu.Lock()
 		w.pendingActions[actionID] = PendingAction{
 			Ticker:    ticker,
 			Action:    analysis.ActionCommand, // Hijacking Action field to store command
-			Timestamp: time.Now(),
+			Timestamp: w.clock.Now(),
 		}
 		w.mu.Unlock()
+		w.auditAIProposal(analysis)
 
 		buttons := []telegram.Button{
 			{Text: "✅ EXECUTE AI", CallbackData: fmt.Sprintf("AI_EXEC_%s", actionID)},
@@ -433,7 +532,7 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 				}
 			}
 
-			currentPrice, _ := w.provider.GetPrice(ticker)
+			currentPrice, _ := w.provider.GetPrice(w.ctx, ticker)
 
 			// 1. Monotonicity
 			if newSL.GreaterThan(currentSL) {
@@ -442,7 +541,7 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 				if newSL.LessThan(bufferPrice) {
 					// 3. Frequency
 					lastUpd, ok := w.lastAlerts[ticker+"_UPDATE"]
-					if !ok || time.Since(lastUpd) > 4*time.Hour {
+					if !ok || w.since(lastUpd) > 4*time.Hour {
 						safe = true
 					} else {
 						reason = "Frequency Limit (4h)"
@@ -465,13 +564,13 @@ func (w *Watcher) handleAIResult(analysis *ai.AIAnalysis, snapshot *ai.Portfolio
 			} else {
 				// Downgrade to Manual
 				msg += fmt.Sprintf("\n\n⚠️ Auto-Update Blocked: %s. Manual Confirmation Required.", reason)
-				actionID := fmt.Sprintf("AI_%d_%s", time.Now().UnixNano(), ticker)
+				actionID := fmt.Sprintf("AI_%d_%s", w.clock.Now().UnixNano(), ticker)
 
 				w.mu.Lock()
 				w.pendingActions[actionID] = PendingAction{
 					Ticker:    ticker,
 					Action:    analysis.ActionCommand,
-					Timestamp: time.Now(),
+					Timestamp: w.clock.Now(),
 				}
 				w.mu.Unlock()
 				buttons := []telegram.Button{
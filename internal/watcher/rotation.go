@@ -0,0 +1,277 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rotation is a two-leg capital rotation: liquidate SellTicker and fund BuyTicker
+// from the actual sale proceeds (Spec 67 rotations, executed as one transaction).
+type Rotation struct {
+	SellTicker string
+	BuyTicker  string
+	BuyQty     decimal.Decimal // Requested qty; capped by proceeds
+}
+
+// rotationAt reports whether commands[i] is a "/sell A" immediately followed by "/buy B qty".
+// Such pairs are executed as a single rotation instead of two independent commands.
+func rotationAt(commands []string, i int) (Rotation, bool) {
+	if i+1 >= len(commands) {
+		return Rotation{}, false
+	}
+	sell := strings.Fields(strings.TrimSpace(commands[i]))
+	buy := strings.Fields(strings.TrimSpace(commands[i+1]))
+	if len(sell) < 2 || strings.ToLower(sell[0]) != "/sell" {
+		return Rotation{}, false
+	}
+	if len(buy) < 3 || strings.ToLower(buy[0]) != "/buy" {
+		return Rotation{}, false
+	}
+	qty, err := decimal.NewFromString(buy[2])
+	if err != nil || !qty.IsPositive() {
+		return Rotation{}, false
+	}
+	return Rotation{
+		SellTicker: symbols.Normalize(sell[1]),
+		BuyTicker:  symbols.Normalize(buy[1]),
+		BuyQty:     qty,
+	}, true
+}
+
+// executeRotation checks the confirmation tier of both legs before anything trades.
+// When either leg is PIN-tier the whole rotation waits for one /pin (keyed by the buy
+// ticker); an expired or wrong PIN leaves both positions untouched.
+func (w *Watcher) executeRotation(r Rotation) string {
+	buyPrice, err := w.provider.GetPrice(market.Fresh(w.ctx), r.BuyTicker)
+	if err != nil || !buyPrice.IsPositive() {
+		return fmt.Sprintf("❌ Rotation aborted: could not price %s. Nothing was traded.", r.BuyTicker)
+	}
+	buyCost := buyPrice.Mul(r.BuyQty)
+	// The sell leg at the current price (the entry when it cannot be priced)
+	sellQty, sellPrice := decimal.Zero, decimal.Zero
+	w.mu.RLock()
+	for _, p := range w.state.Positions {
+		if p.Ticker == r.SellTicker && p.Status == "ACTIVE" {
+			sellQty, sellPrice = p.Quantity, p.EntryPrice
+		}
+	}
+	w.mu.RUnlock()
+	if price, err := w.provider.GetPrice(market.Fresh(w.ctx), r.SellTicker); err == nil && price.IsPositive() {
+		sellPrice = price
+	}
+	sellValue := sellQty.Mul(sellPrice)
+
+	if w.confirmTier(buyCost) != confirmPIN && w.confirmTier(sellValue) != confirmPIN {
+		return w.runRotation(r)
+	}
+	sl, tp := w.defaultLevels(r.BuyTicker, buyPrice)
+	return fmt.Sprintf("🔄 *ROTATION: %s → %s* (sell ~$%s, buy ~$%s)\n", r.SellTicker, r.BuyTicker,
+		sellValue.StringFixed(2), buyCost.StringFixed(2)) +
+		w.armProposal(PendingProposal{
+			Ticker:          r.BuyTicker,
+			Qty:             r.BuyQty,
+			Price:           buyPrice,
+			TotalCost:       decimal.Max(buyCost, sellValue),
+			StopLoss:        sl,
+			TakeProfit:      tp,
+			TrailingStopPct: decimal.NewFromFloat(w.config.DefaultTrailingStopPct),
+			Confirm:         confirmPIN,
+			Rotation:        &r,
+			Timestamp:       w.clock.Now(),
+		}) + "\nNothing is traded until then."
+}
+
+// runRotation runs the rotation legs sequentially:
+// 1. Sell leg (clearance, market sell, verification, state purge).
+// 2. Wait for the proceeds to settle (see fundDependentBuy).
+// 3. Buy leg sized from actual proceeds and settled funds (never more than requested).
+// If the buy leg fails, the capital sits in cash: we alert and offer a one-tap re-entry of the sold asset.
+func (w *Watcher) runRotation(r Rotation) string {
+	var out []string
+	out = append(out, fmt.Sprintf("🔄 *ROTATION: %s → %s*", r.SellTicker, r.BuyTicker))
+
+	// Pre-flight: never sell into a buy leg the compliance rules would reject
+	if msg, ok := w.checkCompliance(r.BuyTicker, r.BuyQty, decimal.Zero); !ok {
+		return msg + "\nRotation aborted. Nothing was traded."
+	}
+
+	// A rotation never makes a flaggable day trade: that needs /sell and its confirmation
+	if reason := w.pdtRisk(r.SellTicker); reason != "" {
+		return fmt.Sprintf("⛔ Rotation aborted: %s. Nothing was traded.", reason)
+	}
+
+	// --- Leg 1: Sell ---
+	if err := w.ensureSequentialClearance(r.SellTicker); err != nil {
+		return fmt.Sprintf("❌ Rotation aborted: could not clear pending orders for %s: %v", r.SellTicker, err)
+	}
+
+	positions, err := w.provider.ListPositions(w.ctx)
+	if err != nil {
+		return fmt.Sprintf("❌ Rotation aborted: failed to list positions: %v", err)
+	}
+	sellQty, sellDecision := decimal.Zero, decimal.Zero
+	for _, p := range positions {
+		if p.Symbol == r.SellTicker {
+			sellQty = p.Qty
+			if p.CurrentPrice != nil {
+				sellDecision = *p.CurrentPrice
+			}
+			break
+		}
+	}
+	if !sellQty.IsPositive() {
+		return fmt.Sprintf("❌ Rotation aborted: no position in %s on exchange. Nothing was traded.", r.SellTicker)
+	}
+
+	sellMid := w.quoteMid(r.SellTicker)
+	sellOrder, err := w.placeOrder(models.OrderIntent{Ticker: r.SellTicker, Qty: sellQty, Side: "sell", Source: "ROTATION"})
+	if err != nil {
+		log.Printf("[FATAL_TRADE_ERROR] Rotation sell failed for %s: %v", r.SellTicker, err)
+		return fmt.Sprintf("❌ Rotation aborted: sell leg failed (%v). Nothing was traded.", err)
+	}
+	sold, err := w.verifyOrderExecution(sellOrder.ID)
+	if err != nil || !strings.EqualFold(sold.Status, "filled") || sold.FilledAvgPrice == nil {
+		status := "unknown"
+		if sold != nil {
+			status = sold.Status
+		}
+		return fmt.Sprintf("🚨 Rotation halted: sell leg for %s not filled (Status: %s, Err: %v). Buy leg NOT placed.", r.SellTicker, status, err)
+	}
+
+	proceeds := sold.FilledQty.Mul(*sold.FilledAvgPrice)
+	w.recordSlippage(r.SellTicker, "sell", "ROTATION", sellDecision, sellMid, sold)
+	archived, _ := w.purgePosition(r.SellTicker, *sold.FilledAvgPrice, "ROTATION")
+	out = append(out, fmt.Sprintf("✅ Sold %s %s @ $%s (Proceeds: $%s)",
+		sold.FilledQty.String(), r.SellTicker, sold.FilledAvgPrice.StringFixed(2), proceeds.StringFixed(2)))
+
+	// --- Leg 2: Buy (sized from settled proceeds) ---
+	price, err := w.provider.GetPrice(market.Fresh(w.ctx), r.BuyTicker)
+	if err != nil || price.IsZero() {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, fmt.Sprintf("could not price %s", r.BuyTicker))
+	}
+
+	qty, notes := w.fundDependentBuy(r.BuyTicker, price, r.BuyQty, proceeds)
+	out = append(out, notes...)
+	if !qty.IsPositive() {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, "proceeds too small for the buy leg")
+	}
+
+	if msg, ok := w.validateSymbol(r.BuyTicker); !ok {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, msg)
+	}
+	if err := w.ensureSequentialClearance(r.BuyTicker); err != nil {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, fmt.Sprintf("clearance failed: %v", err))
+	}
+
+	buyMid := w.quoteMid(r.BuyTicker)
+	intentSL, intentTP := w.defaultLevels(r.BuyTicker, price)
+	buyOrder, err := w.placeOrder(models.OrderIntent{
+		Ticker: r.BuyTicker, Qty: qty, Side: "buy", Source: "ROTATION",
+		StopLoss: intentSL, TakeProfit: intentTP, TrailingStopPct: decimal.NewFromFloat(w.config.DefaultTrailingStopPct),
+	})
+	if err != nil {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, fmt.Sprintf("buy order rejected: %v", err))
+	}
+	bought, err := w.verifyOrderExecution(buyOrder.ID)
+	if err != nil || !strings.EqualFold(bought.Status, "filled") || bought.FilledAvgPrice == nil {
+		return w.rotationRollback(out, r, archived, sold.FilledQty, fmt.Sprintf("buy leg not filled (Err: %v)", err))
+	}
+
+	entry := *bought.FilledAvgPrice
+	w.recordSlippage(r.BuyTicker, "buy", "ROTATION", price, buyMid, bought)
+	sl, tp := w.defaultLevels(r.BuyTicker, entry)
+	w.mu.Lock()
+	w.state.Positions = append(w.state.Positions, models.Position{
+		Ticker:          r.BuyTicker,
+		Quantity:        bought.FilledQty,
+		EntryPrice:      entry,
+		StopLoss:        sl,
+		TakeProfit:      tp,
+		Status:          "ACTIVE",
+		HighWaterMark:   entry,
+		TrailingStopPct: decimal.NewFromFloat(w.config.DefaultTrailingStopPct),
+		ThesisID:        fmt.Sprintf("ROTATION_%d", w.clock.Now().Unix()),
+		OpenedAt:        w.clock.Now(),
+	})
+	w.saveStateLocked()
+	w.mu.Unlock()
+	w.recordAudit(r.BuyTicker, auditFilled, "AI", entry, fmt.Sprintf("bought %s via rotation from %s", bought.FilledQty.String(), r.SellTicker))
+	w.protectAtBroker(r.BuyTicker)
+
+	out = append(out, fmt.Sprintf("✅ Bought %s %s @ $%s | SL: $%s | TP: $%s",
+		bought.FilledQty.String(), r.BuyTicker, entry.StringFixed(2), sl.StringFixed(2), tp.StringFixed(2)))
+	return strings.Join(out, "\n")
+}
+
+// rotationRollback handles a failed buy leg. Capital is in cash, so we alert loudly and
+// stage a re-entry proposal for the sold asset (same qty and levels) behind the normal buy buttons.
+func (w *Watcher) rotationRollback(out []string, r Rotation, archived models.Position, soldQty decimal.Decimal, reason string) string {
+	log.Printf("[FATAL_TRADE_ERROR] Rotation %s -> %s buy leg failed: %s", r.SellTicker, r.BuyTicker, reason)
+	out = append(out, fmt.Sprintf("🚨 Buy leg FAILED: %s\nCapital from %s is now in cash.", reason, r.SellTicker))
+
+	price, err := w.provider.GetPrice(market.Fresh(w.ctx), r.SellTicker)
+	if err != nil || price.IsZero() {
+		out = append(out, fmt.Sprintf("⚠️ Re-entry not staged (no price for %s). Use /buy manually.", r.SellTicker))
+		return strings.Join(out, "\n")
+	}
+
+	sl, tp := archived.StopLoss, archived.TakeProfit
+	if sl.IsZero() || tp.IsZero() || !sl.LessThan(price) || !tp.GreaterThan(price) {
+		sl, tp = w.defaultLevels(r.SellTicker, price)
+	}
+	tsPct := archived.TrailingStopPct
+	if tsPct.IsZero() {
+		tsPct = decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
+	}
+
+	// A rollback is an offer, never auto-executed; large ones still need the PIN
+	confirm := w.confirmTier(price.Mul(soldQty))
+	if confirm == confirmAuto {
+		confirm = confirmButton
+	}
+
+	w.mu.Lock()
+	w.pendingProposals[r.SellTicker] = PendingProposal{
+		Ticker:          r.SellTicker,
+		Qty:             soldQty,
+		Price:           price,
+		TotalCost:       price.Mul(soldQty),
+		StopLoss:        sl,
+		TakeProfit:      tp,
+		TrailingStopPct: tsPct,
+		Confirm:         confirm,
+		Timestamp:       w.clock.Now(),
+	}
+	w.mu.Unlock()
+
+	pinNote := ""
+	if confirm == confirmPIN {
+		pinNote = "\n🔐 Large order: after RE-BUY, send /pin <PIN> to place it."
+	}
+	telegram.SendInteractiveMessage(fmt.Sprintf("↩️ *ROTATION ROLLBACK*\nRe-enter %s %s @ ~$%s?\n\n⏱️ Valid for %d seconds.%s",
+		soldQty.String(), r.SellTicker, price.StringFixed(2), w.config.ConfirmationTTLSec, pinNote),
+		[]telegram.Button{
+			{Text: "↩️ RE-BUY", CallbackData: fmt.Sprintf("EXECUTE_BUY_%s", r.SellTicker)},
+			{Text: "❌ STAY IN CASH", CallbackData: fmt.Sprintf("CANCEL_BUY_%s", r.SellTicker)},
+		})
+
+	return strings.Join(out, "\n")
+}
+
+// defaultLevels returns the default SL/TP around a price (Spec 41), snapped to the tick.
+func (w *Watcher) defaultLevels(ticker string, price decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	one := decimal.NewFromInt(1)
+	hundred := decimal.NewFromInt(100)
+	sl := price.Mul(one.Sub(decimal.NewFromFloat(w.config.DefaultStopLossPct).Div(hundred)))
+	tp := price.Mul(one.Add(decimal.NewFromFloat(w.config.DefaultTakeProfitPct).Div(hundred)))
+	return money.Price(ticker, sl), money.Price(ticker, tp)
+}
@@ -0,0 +1,173 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/metadata"
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// scanUniverse resolves a /scan key to tickers: real classifications from the
+// asset metadata file first, then the legacy hard-coded sector lists.
+func (w *Watcher) scanUniverse(key string) []string {
+	seen := make(map[string]bool)
+	var tickers []string
+	for _, t := range w.metadata.Match(key) {
+		seen[t] = true
+		tickers = append(tickers, t)
+	}
+	for _, t := range sectors[strings.ToLower(key)] {
+		if !seen[t] {
+			seen[t] = true
+			tickers = append(tickers, t)
+		}
+	}
+	return tickers
+}
+
+// sectorOf classifies a ticker: its asset metadata sector, else the legacy /scan
+// sector list it appears in, else Unclassified.
+func (w *Watcher) sectorOf(ticker string) string {
+	if s := w.metadata.Sector(ticker); s != metadata.Unclassified {
+		return s
+	}
+	for name, list := range sectors {
+		for _, t := range list {
+			if t == ticker {
+				return name
+			}
+		}
+	}
+	return metadata.Unclassified
+}
+
+// sectorCap returns a sector's cap from SECTOR_CAPS ("Technology=30%,energy=2000",
+// % of equity; "*" covers sectors without their own entry) and its raw setting.
+func (w *Watcher) sectorCap(sector string) (decimal.Decimal, string, bool) {
+	fallback := ""
+	for _, spec := range w.config.SectorCaps {
+		name, raw, ok := strings.Cut(spec, "=")
+		if !ok {
+			continue
+		}
+		name, raw = strings.TrimSpace(name), strings.TrimSpace(raw)
+		if strings.EqualFold(name, sector) {
+			v, ok := w.sizeThreshold(raw)
+			return v, raw, ok
+		}
+		if name == "*" {
+			fallback = raw
+		}
+	}
+	if fallback == "" {
+		return decimal.Zero, "", false
+	}
+	v, ok := w.sizeThreshold(fallback)
+	return v, fallback, ok
+}
+
+// sectorCapCheck describes how a buy costing cost would push ticker's sector over its
+// cap (market value of the sector's active positions plus the buy). "" = within.
+func (w *Watcher) sectorCapCheck(ticker string, cost decimal.Decimal) string {
+	if len(w.config.SectorCaps) == 0 {
+		return ""
+	}
+	sector := w.sectorOf(ticker)
+	limit, raw, ok := w.sectorCap(sector)
+	if !ok {
+		return ""
+	}
+	current := decimal.Zero
+	for t, v := range w.activeMarketValues() {
+		if w.sectorOf(t) == sector {
+			current = current.Add(v)
+		}
+	}
+	after := current.Add(cost)
+	if !after.GreaterThan(limit) {
+		return ""
+	}
+	return fmt.Sprintf("%s exposure would reach $%s, over its $%s cap (%s)", sector, after.StringFixed(2), limit.StringFixed(2), raw)
+}
+
+// sectorExposure groups market values by sector. Returns the per-sector totals and the grand total.
+func (w *Watcher) sectorExposure(values map[string]decimal.Decimal) (map[string]decimal.Decimal, decimal.Decimal) {
+	bySector := make(map[string]decimal.Decimal)
+	total := decimal.Zero
+	for ticker, v := range values {
+		sector := w.sectorOf(ticker)
+		bySector[sector] = bySector[sector].Add(v)
+		total = total.Add(v)
+	}
+	return bySector, total
+}
+
+// formatSectorExposure renders sector totals sorted by size with % of invested capital.
+func (w *Watcher) formatSectorExposure(values map[string]decimal.Decimal) string {
+	bySector, total := w.sectorExposure(values)
+	if len(bySector) == 0 || !total.IsPositive() {
+		return ""
+	}
+
+	names := make([]string, 0, len(bySector))
+	for s := range bySector {
+		names = append(names, s)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return bySector[names[i]].GreaterThan(bySector[names[j]])
+	})
+
+	var sb strings.Builder
+	sb.WriteString("*Sector Exposure*\n")
+	for _, s := range names {
+		pct := bySector[s].Div(total).Mul(decimal.NewFromInt(100))
+		capNote := ""
+		if limit, raw, ok := w.sectorCap(s); ok {
+			capNote = fmt.Sprintf(" | cap $%s (%s)", limit.StringFixed(2), raw)
+			if bySector[s].GreaterThan(limit) {
+				capNote += " ⚠️"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("• %s: $%s (%s%%)%s\n", s, bySector[s].StringFixed(2), pct.StringFixed(1), capNote))
+	}
+	return sb.String()
+}
+
+// activeMarketValues prices active positions (falls back to cost basis when the price is unavailable).
+func (w *Watcher) activeMarketValues() map[string]decimal.Decimal {
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	values := make(map[string]decimal.Decimal)
+	for _, p := range positions {
+		price, err := w.provider.GetPrice(w.ctx, p.Ticker)
+		if err != nil || price.IsZero() {
+			price = p.EntryPrice
+		}
+		values[p.Ticker] = values[p.Ticker].Add(p.Quantity.Mul(price))
+	}
+	return values
+}
+
+// handleRiskCommand renders the portfolio risk overview.
+func (w *Watcher) handleRiskCommand() string {
+	values := w.activeMarketValues()
+	if len(values) == 0 {
+		return "ℹ️ No active positions."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🛡️ *RISK OVERVIEW*\n\n")
+	sb.WriteString(w.formatSectorExposure(values))
+	return sb.String()
+}
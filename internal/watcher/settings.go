@@ -0,0 +1,296 @@
+package watcher
+
+import (
+	"fmt"
+	"github.com/shopspring/decimal"
+	"log"
+	"strconv"
+	"strings"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+)
+
+const (
+	layoutTable   = "table"
+	layoutCards   = "cards"
+	layoutMinimal = "minimal"
+)
+
+var statusLayouts = []string{layoutTable, layoutCards, layoutMinimal}
+
+// statusColumns lists the selectable /status columns in display order.
+var statusColumns = []string{"price", "day", "total", "sl", "hwm", "weight", "carry", "ladder"}
+
+var defaultStatusColumns = []string{"price", "day", "total", "sl", "hwm", "ladder"}
+
+// handleSettingsCommand manages runtime preferences (persisted in the state file).
+// /settings                      -> show current settings
+// /settings layout <name>        -> table | cards | minimal
+// /settings show|hide <column>   -> toggle a /status column
+// /settings lang <code>          -> report language (en | es)
+// /settings quiet <HH-HH|off>    -> CET window that mutes routine notifications
+// /settings qty <n|off>          -> default /buy quantity
+// /settings fav add|remove <T>   -> favorite tickers for a bare /price
+// /settings vacation <on|off>    -> unanswered trigger alerts follow VACATION_POLICY
+// /settings eod ...              -> EOD report sections, order and compact mode
+// /settings reset                -> restore defaults
+func (w *Watcher) handleSettingsCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.getSettings()
+	}
+
+	switch strings.ToLower(parts[1]) {
+	case "layout":
+		if len(parts) < 3 || !contains(statusLayouts, strings.ToLower(parts[2])) {
+			return fmt.Sprintf("Usage: /settings layout <%s>", strings.Join(statusLayouts, "|"))
+		}
+		w.mu.Lock()
+		w.state.Settings.StatusLayout = strings.ToLower(parts[2])
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return fmt.Sprintf("✅ Status layout set to *%s*.", strings.ToLower(parts[2]))
+	case "show", "hide":
+		if len(parts) < 3 || !contains(statusColumns, strings.ToLower(parts[2])) {
+			return fmt.Sprintf("Usage: /settings %s <%s>", strings.ToLower(parts[1]), strings.Join(statusColumns, "|"))
+		}
+		col := strings.ToLower(parts[2])
+		show := strings.ToLower(parts[1]) == "show"
+
+		w.mu.Lock()
+		current := w.statusColumnsLocked()
+		updated := []string{}
+		for _, c := range statusColumns { // Keep canonical order
+			visible := contains(current, c)
+			if c == col {
+				visible = show
+			}
+			if visible {
+				updated = append(updated, c)
+			}
+		}
+		w.state.Settings.StatusColumns = updated
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return fmt.Sprintf("✅ Status columns: %s", joinOrNone(updated))
+	case "lang", "language":
+		if len(parts) < 3 || !contains(supportedLanguages, strings.ToLower(parts[2])) {
+			return fmt.Sprintf("Usage: /settings lang <%s>", strings.Join(supportedLanguages, "|"))
+		}
+		w.mu.Lock()
+		w.state.Settings.Language = strings.ToLower(parts[2])
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return fmt.Sprintf("✅ Language set to %s.", strings.ToLower(parts[2]))
+	case "quiet":
+		if len(parts) < 3 {
+			return "Usage: /settings quiet <HH-HH|off> (CET, e.g. 22-07)"
+		}
+		window := strings.ToLower(parts[2])
+		if window == "off" {
+			window = ""
+		} else if _, _, err := parseQuietHours(window); err != nil {
+			return fmt.Sprintf("⚠️ %v", err)
+		}
+		w.mu.Lock()
+		w.state.Settings.QuietHours = window
+		w.saveStateLocked()
+		w.mu.Unlock()
+		if window == "" {
+			return "✅ Quiet hours disabled."
+		}
+		return fmt.Sprintf("✅ Quiet hours set to %s CET. Trade alerts still go through.", window)
+	case "qty":
+		if len(parts) < 3 {
+			return "Usage: /settings qty <n|off>"
+		}
+		qty := decimal.Zero
+		if strings.ToLower(parts[2]) != "off" {
+			q, err := decimal.NewFromString(parts[2])
+			if err != nil || !q.IsPositive() {
+				return "⚠️ Quantity must be a positive number."
+			}
+			qty = q
+		}
+		w.mu.Lock()
+		w.state.Settings.DefaultQty = qty
+		w.saveStateLocked()
+		w.mu.Unlock()
+		if qty.IsZero() {
+			return "✅ Default quantity cleared. /buy requires a quantity."
+		}
+		return fmt.Sprintf("✅ Default /buy quantity set to %s.", qty.String())
+	case "fav", "favorites":
+		if len(parts) < 4 || (parts[2] != "add" && parts[2] != "remove") {
+			return "Usage: /settings fav add|remove <ticker>"
+		}
+		ticker := symbols.Normalize(parts[3])
+		w.mu.Lock()
+		favs := w.state.Settings.Favorites
+		if parts[2] == "add" && !contains(favs, ticker) {
+			favs = append(favs, ticker)
+		} else if parts[2] == "remove" {
+			kept := []string{}
+			for _, f := range favs {
+				if f != ticker {
+					kept = append(kept, f)
+				}
+			}
+			favs = kept
+		}
+		w.state.Settings.Favorites = favs
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return fmt.Sprintf("✅ Favorites: %s", joinOrNone(favs))
+	case "vacation":
+		if len(parts) < 3 || (parts[2] != "on" && parts[2] != "off") {
+			return "Usage: /settings vacation <on|off>"
+		}
+		w.mu.Lock()
+		w.state.Settings.VacationMode = parts[2] == "on"
+		w.saveStateLocked()
+		w.mu.Unlock()
+		if parts[2] == "off" {
+			return "✅ Vacation mode off. Expired alerts wait for you again."
+		}
+		return fmt.Sprintf("🏖️ Vacation mode on. Expired trigger alerts follow the policy: %s", strings.Join(w.config.VacationPolicy, ", "))
+	case "eod":
+		return w.handleEODSettings(parts)
+	case "reset":
+		w.mu.Lock()
+		prev := w.state.Settings
+		w.state.Settings = models.UserSettings{
+			// /setup and strategy review choices are configuration, not preferences: keep them
+			DefaultStopLossPct:     prev.DefaultStopLossPct,
+			DefaultTakeProfitPct:   prev.DefaultTakeProfitPct,
+			DefaultTrailingStopPct: prev.DefaultTrailingStopPct,
+			AutoStatus:             prev.AutoStatus,
+			SetupCompletedAt:       prev.SetupCompletedAt,
+		}
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return "✅ Settings reset to defaults."
+	default:
+		return "Usage: /settings [layout | show | hide | lang | quiet | qty | fav | vacation | eod | reset]"
+	}
+}
+
+func (w *Watcher) getSettings() string {
+	w.mu.RLock()
+	layout := w.statusLayoutLocked()
+	cols := w.statusColumnsLocked()
+	eod := w.eodSectionsLocked()
+	s := w.state.Settings
+	w.mu.RUnlock()
+
+	lang := s.Language
+	if lang == "" {
+		lang = "en"
+	}
+	quiet := s.QuietHours
+	if quiet == "" {
+		quiet = "off"
+	}
+	qty := "none"
+	if s.DefaultQty.IsPositive() {
+		qty = s.DefaultQty.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚙️ *SETTINGS*\n")
+	sb.WriteString(fmt.Sprintf("Status Layout: %s (%s)\n", layout, strings.Join(statusLayouts, ", ")))
+	sb.WriteString(fmt.Sprintf("Status Columns: %s (available: %s)\n", joinOrNone(cols), strings.Join(statusColumns, ", ")))
+	sb.WriteString(fmt.Sprintf("Language: %s\n", lang))
+	sb.WriteString(fmt.Sprintf("Quiet Hours (CET): %s\n", quiet))
+	sb.WriteString(fmt.Sprintf("Default Qty: %s\n", qty))
+	sb.WriteString(fmt.Sprintf("Favorites: %s\n", joinOrNone(s.Favorites)))
+	sb.WriteString(fmt.Sprintf("Vacation Mode: %t (policy: %s)\n", s.VacationMode, strings.Join(w.config.VacationPolicy, ", ")))
+	sb.WriteString(fmt.Sprintf("EOD Sections: %s (compact: %t)", strings.Join(eod, ", "), s.EODCompact))
+	return sb.String()
+}
+
+// statusLayoutLocked returns the configured layout or the default. Caller holds w.mu.
+func (w *Watcher) statusLayoutLocked() string {
+	if contains(statusLayouts, w.state.Settings.StatusLayout) {
+		return w.state.Settings.StatusLayout
+	}
+	return layoutTable
+}
+
+// statusColumnsLocked returns the visible columns or the defaults. Caller holds w.mu.
+// A nil slice means "never customized"; an empty one means every optional column is hidden.
+func (w *Watcher) statusColumnsLocked() []string {
+	if w.state.Settings.StatusColumns == nil {
+		return defaultStatusColumns
+	}
+	return w.state.Settings.StatusColumns
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQuietHours parses "HH-HH" (CET). The window may wrap midnight (e.g., "22-07").
+func parseQuietHours(window string) (int, int, error) {
+	bounds := strings.Split(window, "-")
+	if len(bounds) != 2 {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q (use HH-HH, e.g. 22-07)", window)
+	}
+	start, err1 := strconv.Atoi(bounds[0])
+	end, err2 := strconv.Atoi(bounds[1])
+	if err1 != nil || err2 != nil || start < 0 || start > 23 || end < 0 || end > 23 || start == end {
+		return 0, 0, fmt.Errorf("invalid quiet hours %q (hours 0-23, start != end)", window)
+	}
+	return start, end, nil
+}
+
+// quietHoursLocked reports whether routine notifications are muted right now. Caller holds w.mu.
+func (w *Watcher) quietHoursLocked() bool {
+	start, end, err := parseQuietHours(w.state.Settings.QuietHours)
+	if err != nil {
+		return false
+	}
+	hour := w.clock.Now().In(config.CetLoc).Hour()
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// notifyRoutine sends a non-critical notification unless quiet hours are active.
+// Trade alerts and errors should keep using telegram.Notify directly.
+func (w *Watcher) notifyRoutine(msg string) bool {
+	w.mu.RLock()
+	quiet := w.quietHoursLocked()
+	w.mu.RUnlock()
+
+	if quiet {
+		log.Printf("Quiet hours: suppressed notification: %.80s", msg)
+		return false
+	}
+	telegram.Notify(msg)
+	return true
+}
+
+// getFavoritePrices answers a bare /price with the favorite tickers.
+func (w *Watcher) getFavoritePrices() string {
+	w.mu.RLock()
+	favs := append([]string(nil), w.state.Settings.Favorites...)
+	w.mu.RUnlock()
+
+	if len(favs) == 0 {
+		return "Usage: /price <ticker> (or set favorites with /settings fav add <ticker>)"
+	}
+	var out []string
+	for _, t := range favs {
+		out = append(out, w.getPrice(t))
+	}
+	return strings.Join(out, "\n\n")
+}
@@ -0,0 +1,97 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"alpha_trading/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+const settlementPollInterval = 1 * time.Second
+
+// BuyingPowerBreakdown splits account buying power into what can be spent without
+// margin and what only margin covers. Sale proceeds show up in buying power before
+// they settle, so a dependent buy sized against the total can be rejected by the
+// broker. The account endpoint has no settled-cash field; NonMargin is the closest
+// proxy it offers, not settled cash itself.
+type BuyingPowerBreakdown struct {
+	Total     decimal.Decimal
+	NonMargin decimal.Decimal // min(non-marginable buying power, buying power)
+	Margin    decimal.Decimal // Total - NonMargin: spendable only on margin
+}
+
+// getBuyingPowerBreakdown reads the account endpoint and splits buying power at the
+// non-marginable figure.
+func (w *Watcher) getBuyingPowerBreakdown() (BuyingPowerBreakdown, error) {
+	acct, err := w.provider.GetAccount(w.ctx)
+	if err != nil {
+		return BuyingPowerBreakdown{}, err
+	}
+
+	nonMargin := decimal.Min(acct.NonMarginBuyingPower, acct.BuyingPower)
+	if nonMargin.IsNegative() {
+		nonMargin = decimal.Zero
+	}
+	margin := acct.BuyingPower.Sub(nonMargin)
+	if margin.IsNegative() {
+		margin = decimal.Zero
+	}
+	return BuyingPowerBreakdown{
+		Total:     acct.BuyingPower,
+		NonMargin: nonMargin,
+		Margin:    margin,
+	}, nil
+}
+
+// waitForSettledFunds polls until non-margin buying power covers required or SETTLEMENT_WAIT_SEC elapses.
+// Returns the last observed breakdown and how long we waited.
+func (w *Watcher) waitForSettledFunds(required decimal.Decimal) (BuyingPowerBreakdown, time.Duration) {
+	start := w.clock.Now()
+	deadline := start.Add(time.Duration(w.config.SettlementWaitSec) * time.Second)
+
+	bp, err := w.getBuyingPowerBreakdown()
+	for (err != nil || bp.NonMargin.LessThan(required)) && w.clock.Now().Before(deadline) {
+		w.clock.Sleep(settlementPollInterval)
+		bp, err = w.getBuyingPowerBreakdown()
+	}
+	if err != nil {
+		log.Printf("Warning: Buying power poll failed: %v", err)
+	}
+	return bp, w.since(start)
+}
+
+// fundDependentBuy prepares a buy that depends on funds from a prior sell.
+// It waits for settlement, then resizes qty to what is actually available
+// (non-margin buying power, further capped by maxSpend when positive). The returned lines
+// describe the wait and any resize for the execution report.
+func (w *Watcher) fundDependentBuy(ticker string, price, qty, maxSpend decimal.Decimal) (decimal.Decimal, []string) {
+	var notes []string
+	required := price.Mul(qty)
+	if maxSpend.IsPositive() && required.GreaterThan(maxSpend) {
+		required = maxSpend
+	}
+
+	bp, waited := w.waitForSettledFunds(required)
+	if waited >= settlementPollInterval {
+		notes = append(notes, fmt.Sprintf("⏳ Waited %s for settlement (Non-margin: $%s | Margin: $%s).",
+			waited.Round(time.Second), bp.NonMargin.StringFixed(2), bp.Margin.StringFixed(2)))
+	}
+
+	budget := bp.NonMargin
+	if maxSpend.IsPositive() {
+		budget = decimal.Min(budget, maxSpend)
+	}
+	if price.Mul(qty).GreaterThan(budget) {
+		resized := money.Qty(ticker, budget.Div(price), true)
+		if resized.IsNegative() {
+			resized = decimal.Zero
+		}
+		notes = append(notes, fmt.Sprintf("📏 Buy resized %s → %s to fit available funds ($%s).",
+			qty.String(), resized.String(), budget.StringFixed(2)))
+		qty = resized
+	}
+	return qty, notes
+}
@@ -0,0 +1,164 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"alpha_trading/internal/telegram"
+)
+
+// setupRiskChoices are the default stop-loss percentages offered by /setup.
+var setupRiskChoices = []string{"3", "5", "8", "10"}
+
+const setupSeedLimit = 5 // Tickers added to the watchlist per seeded sector
+
+// handleSetupCommand starts the first-run wizard. Each step is a button message;
+// answers arrive as SETUP_<STEP>_<VALUE> callbacks (see handleSetupCallback).
+// Step 1 validates broker connectivity and asks to confirm the trading mode.
+func (w *Watcher) handleSetupCommand() string {
+	acct, err := w.provider.GetAccount(w.ctx)
+	if err != nil {
+		return fmt.Sprintf("❌ *SETUP*: Broker connectivity check failed: %v\nCheck APCA_API_KEY_ID / APCA_API_SECRET_KEY / APCA_API_BASE_URL and retry /setup.", err)
+	}
+
+	mode := "LIVE"
+	warning := "\n🚨 Real money. Orders placed by this bot will execute against your live account."
+	if w.isPaperTrading() {
+		mode = "PAPER"
+		warning = ""
+	}
+
+	telegram.SendInteractiveMessage(fmt.Sprintf("🧭 *SETUP (1/4): Trading Mode*\n✅ Broker connected (Account %s, Status %s, Equity $%s)\nDetected mode: *%s*%s\n\nContinue in this mode?",
+		acct.AccountNumber, acct.Status, acct.Equity.StringFixed(2), mode, warning),
+		[]telegram.Button{
+			{Text: "✅ CONFIRM " + mode, CallbackData: "SETUP_MODE_OK"},
+			{Text: "❌ ABORT", CallbackData: "SETUP_MODE_ABORT"},
+		})
+	return "🧭 Setup started."
+}
+
+// handleSetupCallback advances the wizard: MODE -> RISK -> AUTO -> SEED.
+func (w *Watcher) handleSetupCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid setup callback data."
+	}
+	step, value := parts[1], parts[2]
+
+	switch step {
+	case "MODE":
+		if value != "OK" {
+			return "❌ Setup aborted. Update APCA_API_BASE_URL (paper: https://paper-api.alpaca.markets) and run /setup again."
+		}
+		var buttons []telegram.Button
+		for _, pct := range setupRiskChoices {
+			buttons = append(buttons, telegram.Button{Text: pct + "%", CallbackData: "SETUP_RISK_" + pct})
+		}
+		telegram.SendInteractiveMessage(fmt.Sprintf("🧭 *SETUP (2/4): Default Risk*\nDefault stop-loss distance for new positions (current: %.1f%%).", w.config.DefaultStopLossPct), buttons)
+		return "✅ Mode confirmed."
+
+	case "RISK":
+		pct, err := strconv.ParseFloat(value, 64)
+		if err != nil || pct <= 0 || pct >= 100 {
+			return "⚠️ Invalid risk value."
+		}
+		w.mu.Lock()
+		w.state.Settings.DefaultStopLossPct = pct
+		w.saveStateLocked()
+		w.mu.Unlock()
+		w.applySettingsOverrides()
+
+		telegram.SendInteractiveMessage("🧭 *SETUP (3/4): Auto-Status*\nPush the /status dashboard after every poll during market hours?",
+			[]telegram.Button{
+				{Text: "🔔 ON", CallbackData: "SETUP_AUTO_ON"},
+				{Text: "🔕 OFF (daily heartbeat)", CallbackData: "SETUP_AUTO_OFF"},
+			})
+		return fmt.Sprintf("✅ Default stop-loss set to %.1f%%.", pct)
+
+	case "AUTO":
+		enabled := value == "ON"
+		w.mu.Lock()
+		w.state.Settings.AutoStatus = &enabled
+		w.saveStateLocked()
+		w.mu.Unlock()
+		w.applySettingsOverrides()
+
+		sectors := w.metadata.Sectors()
+		var buttons []telegram.Button
+		for i, s := range sectors {
+			buttons = append(buttons, telegram.Button{Text: s, CallbackData: fmt.Sprintf("SETUP_SEED_%d", i)})
+		}
+		buttons = append(buttons, telegram.Button{Text: "⏭️ SKIP", CallbackData: "SETUP_SEED_SKIP"})
+		telegram.SendInteractiveMessage("🧭 *SETUP (4/4): Watchlist Seed*\nPick a sector to seed the watchlist (you can add more later with /watch add).", buttons)
+		return fmt.Sprintf("✅ Auto-status %s.", strings.ToLower(value))
+
+	case "SEED":
+		var added []string
+		if value != "SKIP" {
+			sectors := w.metadata.Sectors()
+			i, err := strconv.Atoi(value)
+			if err != nil || i < 0 || i >= len(sectors) {
+				return "⚠️ Invalid sector choice."
+			}
+			for _, t := range w.scanUniverse(sectors[i]) {
+				if len(added) >= setupSeedLimit {
+					break
+				}
+				if _, ok := w.addLocalWatch(t, "SETUP"); ok {
+					added = append(added, t)
+				}
+			}
+		}
+		return w.finishSetup(added)
+	}
+	return "⚠️ Unknown setup step."
+}
+
+// finishSetup stamps completion and summarizes the resulting configuration.
+func (w *Watcher) finishSetup(seeded []string) string {
+	w.mu.Lock()
+	w.state.Settings.SetupCompletedAt = w.clock.Now()
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	mode := "LIVE"
+	if w.isPaperTrading() {
+		mode = "PAPER"
+	}
+	log.Printf("Setup completed: Mode=%s, SL=%.1f%%, AutoStatus=%t, Seeded=%v", mode, w.config.DefaultStopLossPct, w.config.AutoStatusEnabled, seeded)
+
+	return fmt.Sprintf("🎉 *SETUP COMPLETE*\nMode: %s\nDefault SL: %.1f%%\nAuto-Status: %t\nWatchlist Seed: %s\n\nSaved to portfolio_state.json (overrides .env on restart). Run /setup again anytime.",
+		mode, w.config.DefaultStopLossPct, w.config.AutoStatusEnabled, joinOrNone(seeded))
+}
+
+// applySettingsOverrides applies config values chosen in /setup over the env defaults.
+func (w *Watcher) applySettingsOverrides() {
+	w.mu.RLock()
+	s := w.state.Settings
+	w.mu.RUnlock()
+
+	if s.DefaultStopLossPct > 0 {
+		w.config.DefaultStopLossPct = s.DefaultStopLossPct
+	}
+	if s.DefaultTakeProfitPct > 0 {
+		w.config.DefaultTakeProfitPct = s.DefaultTakeProfitPct
+	}
+	if s.DefaultTrailingStopPct > 0 {
+		w.config.DefaultTrailingStopPct = s.DefaultTrailingStopPct
+	}
+	if s.AutoStatus != nil {
+		w.config.AutoStatusEnabled = *s.AutoStatus
+	}
+}
+
+// isPaperTrading reports whether the account in use is Alpaca's paper API (the
+// configured APCA_API_BASE_URL for other providers).
+func (w *Watcher) isPaperTrading() bool {
+	if alp := w.alpacaProvider(); alp != nil {
+		return alp.Paper()
+	}
+	return strings.Contains(strings.ToLower(os.Getenv("APCA_API_BASE_URL")), "paper")
+}
@@ -0,0 +1,274 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/telegram"
+)
+
+// Several watcher processes (e.g., an equities and a crypto instance) can share one bot.
+// Telegram delivers updates to a single poller, so the primary runs the listener and
+// relays "/<name> ..." commands, button presses and uploads to the secondaries over
+// HTTP (SHARD_PEERS / SHARD_LISTEN_ADDR). Every instance sends its own messages,
+// labelled with its SHARD_NAME.
+
+const (
+	shardRelayTimeout  = 10 * time.Second
+	shardSecretHeader  = "X-Shard-Secret"
+	shardMaxBody       = 4 << 20 // A relayed 1MB upload, base64-encoded, with headroom
+	shardRelayQueueLen = 32
+)
+
+// shardPeer is a secondary instance reachable over the relay.
+type shardPeer struct {
+	name string
+	url  string
+}
+
+// shardRelay is the body of a relayed command, button press or upload.
+type shardRelay struct {
+	Text       string `json:"text,omitempty"`
+	CallbackID string `json:"callback_id,omitempty"`
+	Data       string `json:"data,omitempty"`
+	Caption    string `json:"caption,omitempty"`
+	FileName   string `json:"file_name,omitempty"`
+	Content    []byte `json:"content,omitempty"`
+}
+
+// validShardName reports whether name can be a routing prefix: lowercase letters and
+// digits, not shadowing a command.
+func (w *Watcher) validShardName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	for _, c := range w.commands {
+		if c.Name == "/"+name {
+			return false
+		}
+	}
+	return true
+}
+
+// loadShardPeers parses SHARD_PEERS ("crypto=http://127.0.0.1:8091"). Invalid entries
+// are logged and skipped.
+func (w *Watcher) loadShardPeers() []shardPeer {
+	var peers []shardPeer
+	for _, spec := range w.config.ShardPeers {
+		name, url, ok := strings.Cut(strings.TrimSpace(spec), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		url = strings.TrimRight(strings.TrimSpace(url), "/")
+		if !ok || !w.validShardName(name) || name == w.config.ShardName || !strings.HasPrefix(url, "http") {
+			log.Printf("CRITICAL: Invalid SHARD_PEERS entry %q (use name=http://host:port; names are lowercase letters/digits, not a command)", spec)
+			continue
+		}
+		peers = append(peers, shardPeer{name: name, url: url})
+		log.Printf("Shard Peer: /%s -> %s", name, url)
+	}
+	return peers
+}
+
+func (w *Watcher) shardPeer(name string) *shardPeer {
+	for i := range w.peers {
+		if w.peers[i].name == name {
+			return &w.peers[i]
+		}
+	}
+	return nil
+}
+
+// shardTarget reads an instance prefix: "/crypto status" -> (crypto peer, "/status").
+// This instance's own prefix is stripped (peer nil). Without a prefix, rest is "".
+func (w *Watcher) shardTarget(text string) (peer *shardPeer, rest string) {
+	text = strings.TrimSpace(text)
+	first, remainder, _ := strings.Cut(text, " ")
+	if i := strings.IndexAny(first, "\n"); i >= 0 {
+		first, remainder = first[:i], first[i:]+" "+remainder
+	}
+	name := strings.TrimPrefix(first, "/")
+	if name == first {
+		return nil, ""
+	}
+	if name != w.config.ShardName || name == "" {
+		if peer = w.shardPeer(name); peer == nil {
+			return nil, ""
+		}
+	}
+	rest = strings.TrimSpace(remainder)
+	if rest == "" {
+		rest = "/help"
+	} else if !strings.HasPrefix(rest, "/") {
+		rest = "/" + rest
+	}
+	return peer, rest
+}
+
+// relay posts a command, button press or upload to a secondary instance. The secondary
+// answers in the chat itself; "" means the relay was accepted.
+func (w *Watcher) relay(peer *shardPeer, path string, body shardRelay) string {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not relay to /%s: %v", peer.name, err)
+	}
+	ctx, cancel := context.WithTimeout(w.ctx, shardRelayTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer.url+path, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Sprintf("❌ Could not relay to /%s: %v", peer.name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(shardSecretHeader, w.config.ShardSecret)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Printf("Shard Relay Error: /%s%s: %v", peer.name, path, err)
+		return fmt.Sprintf("⚠️ Instance /%s is unreachable: %v", peer.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		log.Printf("Shard Relay Error: /%s%s: %s %s", peer.name, path, resp.Status, msg)
+		return fmt.Sprintf("⚠️ Instance /%s rejected the request (%s).", peer.name, resp.Status)
+	}
+	return ""
+}
+
+// peerHeartbeats fetches each secondary's heartbeat for the primary's merged heartbeat.
+func (w *Watcher) peerHeartbeats() string {
+	var sb strings.Builder
+	for _, p := range w.peers {
+		sb.WriteString(fmt.Sprintf("\n\n🔀 */%s*\n", p.name))
+		text, err := w.fetchPeerHeartbeat(p)
+		if err != nil {
+			log.Printf("Shard Heartbeat Error: /%s: %v", p.name, err)
+			sb.WriteString(fmt.Sprintf("⚠️ Unreachable: %v", err))
+			continue
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+func (w *Watcher) fetchPeerHeartbeat(p shardPeer) (string, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, shardRelayTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/heartbeat", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(shardSecretHeader, w.config.ShardSecret)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 8<<10))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s", resp.Status)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// isSecondary reports whether this instance receives its updates from a primary.
+func (w *Watcher) isSecondary() bool {
+	return w.config.ShardListenAddr != ""
+}
+
+// StartShardServer serves relayed updates on a secondary instance (SHARD_LISTEN_ADDR)
+// until ctx is done. Relayed updates run one at a time, in arrival order, as the
+// listener would run them.
+func (w *Watcher) StartShardServer(ctx context.Context) {
+	if !w.isSecondary() {
+		return
+	}
+	queue := make(chan func(), shardRelayQueueLen)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case job := <-queue:
+				job()
+			}
+		}
+	}()
+
+	accept := func(handle func(shardRelay) string) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || !w.shardAuthorized(r) {
+				http.Error(rw, "forbidden", http.StatusForbidden)
+				return
+			}
+			var body shardRelay
+			if err := json.NewDecoder(io.LimitReader(r.Body, shardMaxBody)).Decode(&body); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			select {
+			case queue <- func() {
+				if reply := handle(body); reply != "" {
+					telegram.Notify(reply)
+				}
+			}:
+				rw.WriteHeader(http.StatusAccepted)
+			default:
+				http.Error(rw, "busy", http.StatusServiceUnavailable)
+			}
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/command", accept(func(b shardRelay) string {
+		log.Printf("Relayed command received: %s", b.Text)
+		return w.HandleCommand(b.Text)
+	}))
+	mux.HandleFunc("/callback", accept(func(b shardRelay) string {
+		log.Printf("Relayed callback received: %s", b.Data)
+		return w.HandleCallback(b.CallbackID, b.Data)
+	}))
+	mux.HandleFunc("/document", accept(func(b shardRelay) string {
+		log.Printf("Relayed document received: %s (caption: %q)", b.FileName, b.Caption)
+		return w.HandleDocument(b.Caption, b.FileName, b.Content)
+	}))
+	mux.HandleFunc("/heartbeat", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !w.shardAuthorized(r) {
+			http.Error(rw, "forbidden", http.StatusForbidden)
+			return
+		}
+		clock, _ := w.provider.GetClock(w.ctx)
+		io.WriteString(rw, w.heartbeatDetails(clock))
+	})
+
+	srv := &http.Server{Addr: w.config.ShardListenAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	go func() {
+		log.Printf("Shard Relay: /%s listening on %s", w.config.ShardName, w.config.ShardListenAddr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("CRITICAL: Shard relay stopped: %v", err)
+			telegram.Notify(fmt.Sprintf("🚨 Instance /%s cannot receive commands: %v", w.config.ShardName, err))
+		}
+	}()
+}
+
+func (w *Watcher) shardAuthorized(r *http.Request) bool {
+	got := r.Header.Get(shardSecretHeader)
+	return w.config.ShardSecret != "" && subtle.ConstantTimeCompare([]byte(got), []byte(w.config.ShardSecret)) == 1
+}
@@ -0,0 +1,142 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/budget"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// kellyMinTrades is the minimum journal size before the advisor produces a number.
+const kellyMinTrades = 5
+
+// kellyAdvice returns an advisory sizing line for a buy proposal.
+// Kelly: f* = W - (1 - W) / R, scaled by KELLY_FRACTION and applied to min(Equity, FiscalLimit).
+// It never changes the proposal itself.
+func (w *Watcher) kellyAdvice(price decimal.Decimal) string {
+	if w.config.KellyFraction <= 0 || !price.IsPositive() {
+		return ""
+	}
+
+	journal, err := w.buildTradeJournal()
+	if err != nil {
+		log.Printf("Kelly Advisor: Failed to build journal: %v", err)
+		return ""
+	}
+
+	stats := computeTradeStats(journal)
+	if stats.Trades < kellyMinTrades || stats.PayoffRatio.IsZero() {
+		return fmt.Sprintf("🧮 Kelly (advisory): insufficient history (%d/%d closed trades).", stats.Trades, kellyMinTrades)
+	}
+
+	one := decimal.NewFromInt(1)
+	kelly := stats.WinRate.Sub(one.Sub(stats.WinRate).Div(stats.PayoffRatio))
+	summary := fmt.Sprintf("%d trades, %s%% win, %sx payoff",
+		stats.Trades, stats.WinRate.Mul(decimal.NewFromInt(100)).StringFixed(0), stats.PayoffRatio.StringFixed(2))
+
+	if !kelly.IsPositive() {
+		return fmt.Sprintf("🧮 Kelly (advisory): no edge — suggests 0 size (%s).", summary)
+	}
+
+	equity, _ := w.provider.GetEquity(w.ctx) // Zero on error: capped by the fiscal limit alone
+	capital := budget.RealCap(decimal.NewFromFloat(w.config.FiscalBudgetLimit), equity)
+
+	fraction := kelly.Mul(decimal.NewFromFloat(w.config.KellyFraction))
+	dollars := capital.Mul(fraction)
+	qty := dollars.Div(price)
+
+	return fmt.Sprintf("🧮 Kelly (advisory): %s%% of capital ≈ $%s ≈ %s sh (%s).",
+		fraction.Mul(decimal.NewFromInt(100)).StringFixed(1), dollars.StringFixed(2), qty.StringFixed(2), summary)
+}
+
+// handleSizeCommand sizes a buy so that hitting the stop loses the risk amount:
+// qty = risk$ / (entry - stop). The risk defaults to RISK_PER_TRADE_PCT of capital
+// (min of equity and FISCAL_BUDGET_LIMIT); the stop defaults to the one /buy would set.
+// A second line sizes against a volatility stop SIZE_ATR_MULTIPLE daily ATRs below entry.
+// /size <ticker> [risk$] [stop]
+func (w *Watcher) handleSizeCommand(parts []string) string {
+	const usage = "Usage: /size <ticker> [risk$] [stop]"
+	if len(parts) < 2 || len(parts) > 4 {
+		return usage
+	}
+	ticker := symbols.Normalize(parts[1])
+	if msg, ok := w.validateSymbol(ticker); !ok {
+		return msg
+	}
+
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil || !price.IsPositive() {
+		return fmt.Sprintf("⚠️ Could not fetch price for %s.", ticker)
+	}
+	equity, _ := w.provider.GetEquity(w.ctx) // Zero on error: capped by the fiscal limit alone
+	capital := budget.RealCap(decimal.NewFromFloat(w.config.FiscalBudgetLimit), equity)
+
+	risk := capital.Mul(decimal.NewFromFloat(w.config.RiskPerTradePct)).Div(decimal.NewFromInt(100))
+	riskLabel := fmt.Sprintf("%.2f%% of $%s", w.config.RiskPerTradePct, capital.StringFixed(2))
+	if len(parts) >= 3 {
+		r, err := decimal.NewFromString(strings.TrimPrefix(parts[2], "$"))
+		if err != nil || !r.IsPositive() {
+			return "⚠️ Invalid risk amount."
+		}
+		risk, riskLabel = r, "given"
+	}
+	if !risk.IsPositive() {
+		return "⚠️ No risk budget: set RISK_PER_TRADE_PCT or pass the amount, e.g. /size AAPL 50."
+	}
+
+	stop := price.Mul(decimal.NewFromInt(1).Sub(decimal.NewFromFloat(w.config.DefaultStopLossPct).Div(decimal.NewFromInt(100))))
+	stopLabel := fmt.Sprintf("default -%.1f%%", w.config.DefaultStopLossPct)
+	if len(parts) == 4 {
+		s, err := decimal.NewFromString(parts[3])
+		if err != nil || !s.IsPositive() {
+			return "⚠️ Invalid stop price."
+		}
+		stop, stopLabel = s, "given"
+	}
+	stop = money.Price(ticker, stop)
+	if !stop.LessThan(price) {
+		return fmt.Sprintf("⚠️ The stop ($%s) must be below the price ($%s).", stop.StringFixed(2), price.StringFixed(2))
+	}
+
+	atr := w.dailyATR(ticker)
+	w.mu.Lock()
+	b := w.budgetLocked()
+	w.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📏 *POSITION SIZE: %s* @ $%s\nRisk: $%s (%s)\n", ticker, price.StringFixed(2), risk.StringFixed(2), riskLabel))
+	sb.WriteString(w.sizeLine(ticker, price, stop, risk, stopLabel, b))
+	if atr.IsPositive() && w.config.SizeATRMultiple > 0 {
+		dist := atr.Mul(decimal.NewFromFloat(w.config.SizeATRMultiple))
+		sb.WriteString(fmt.Sprintf("\nATR(%d): $%s (%s%%)", atrPeriod, atr.StringFixed(2), atr.Div(price).Mul(decimal.NewFromInt(100)).StringFixed(2)))
+		if atrStop := money.Price(ticker, price.Sub(dist)); atrStop.IsPositive() {
+			sb.WriteString(w.sizeLine(ticker, price, atrStop, risk, fmt.Sprintf("%.1f ATR", w.config.SizeATRMultiple), b))
+		}
+		if price.Sub(stop).LessThan(atr) {
+			sb.WriteString("\n⚠️ The stop is closer than one day's range: ordinary noise can hit it.")
+		}
+	}
+	return sb.String()
+}
+
+// sizeLine is one /size alternative: the quantity that loses risk at stop, its cost
+// against the remaining budget, and the /buy command that places it.
+func (w *Watcher) sizeLine(ticker string, price, stop, risk decimal.Decimal, label string, b budget.Metrics) string {
+	dist := price.Sub(stop)
+	qty := money.Qty(ticker, risk.Div(dist), false)
+	head := fmt.Sprintf("\n\n*Stop $%s* (%s, -%s%%)", stop.StringFixed(2), label, dist.Div(price).Mul(decimal.NewFromInt(100)).StringFixed(2))
+	if !qty.IsPositive() {
+		return head + fmt.Sprintf("\nRisk buys less than one unit (%s at risk per share).", money.USD(dist))
+	}
+	cost := qty.Mul(price)
+	line := head + fmt.Sprintf("\nQty: %s ≈ $%s, loses $%s at the stop", qty.String(), cost.StringFixed(2), qty.Mul(dist).StringFixed(2))
+	if !b.Allows(cost) {
+		line += fmt.Sprintf("\n❌ Over the remaining budget ($%s)", b.Available.StringFixed(2))
+	}
+	return line + fmt.Sprintf("\n`/buy %s %s %s`", ticker, qty.String(), stop.String())
+}
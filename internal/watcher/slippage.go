@@ -0,0 +1,242 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+	"github.com/shopspring/decimal"
+)
+
+// quoteMid returns the bid/ask midpoint right before an order goes out, for the price
+// improvement stat. Zero when the book is missing, one-sided or crossed.
+func (w *Watcher) quoteMid(ticker string) decimal.Decimal {
+	q, err := w.provider.GetQuote(market.Fresh(w.ctx), ticker)
+	if err != nil || q == nil || q.BidPrice <= 0 || q.AskPrice <= 0 || q.BidPrice > q.AskPrice {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(q.BidPrice).Add(decimal.NewFromFloat(q.AskPrice)).Div(decimal.NewFromInt(2))
+}
+
+// brokerLabel names the venue a fill came from: the provider, plus the account for Alpaca.
+func (w *Watcher) brokerLabel() string {
+	if w.alpacaProvider() != nil {
+		return "alpaca/" + w.currentEnv()
+	}
+	return w.config.MarketProvider
+}
+
+// recordSlippage logs how far a fill landed from the decision price and from the quoted
+// mid at order time. Skipped when the fill or decision price is unknown.
+func (w *Watcher) recordSlippage(ticker, side, source string, decision, mid decimal.Decimal, order *alpaca.Order) {
+	if order == nil || order.FilledAvgPrice == nil || !decision.IsPositive() {
+		return
+	}
+	fill := *order.FilledAvgPrice
+	// Buys slip when paying more, sells when receiving less
+	diff := fill.Sub(decision)
+	if side == "sell" {
+		diff = diff.Neg()
+	}
+	r := models.SlippageRecord{
+		Time:          w.clock.Now(),
+		Ticker:        ticker,
+		Side:          side,
+		Source:        source,
+		OrderType:     string(order.Type),
+		Qty:           order.FilledQty,
+		DecisionPrice: decision,
+		FillPrice:     fill,
+		Bps:           diff.Div(decision).Mul(decimal.NewFromInt(10000)),
+		Broker:        w.brokerLabel(),
+	}
+	if mid.IsPositive() {
+		// Buys improve when paying less than mid, sells when receiving more
+		better := mid.Sub(fill)
+		if side == "sell" {
+			better = better.Neg()
+		}
+		r.QuoteMid = mid
+		r.ImprovementBps = better.Div(mid).Mul(decimal.NewFromInt(10000))
+	}
+	if err := storage.AppendSlippage(r); err != nil {
+		log.Printf("ERROR: Failed to record slippage for %s: %v", ticker, err)
+	}
+}
+
+// slippageGroup aggregates records sharing a key (symbol or source/order type).
+type slippageGroup struct {
+	key   string
+	count int
+	bps   decimal.Decimal // Sum, for the average
+	worst decimal.Decimal
+	cost  decimal.Decimal // USD lost (negative = price improvement)
+}
+
+func groupSlippage(records []models.SlippageRecord, keyOf func(models.SlippageRecord) string) []slippageGroup {
+	byKey := map[string]*slippageGroup{}
+	for _, r := range records {
+		k := keyOf(r)
+		g, ok := byKey[k]
+		if !ok {
+			g = &slippageGroup{key: k, worst: r.Bps}
+			byKey[k] = g
+		}
+		g.count++
+		g.bps = g.bps.Add(r.Bps)
+		if r.Bps.GreaterThan(g.worst) {
+			g.worst = r.Bps
+		}
+		g.cost = g.cost.Add(r.Bps.Div(decimal.NewFromInt(10000)).Mul(r.DecisionPrice).Mul(r.Qty))
+	}
+	var out []slippageGroup
+	for _, g := range byKey {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].cost.GreaterThan(out[j].cost) })
+	return out
+}
+
+// slippageReport renders the report for [start, end).
+func (w *Watcher) slippageReport(title string, start, end time.Time) string {
+	records, err := storage.LoadSlippage(start, end)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read slippage log: %v", err)
+	}
+	if len(records) == 0 {
+		return fmt.Sprintf("📐 No executed orders recorded for %s.", title)
+	}
+
+	writeGroups := func(sb *strings.Builder, groups []slippageGroup) {
+		for _, g := range groups {
+			avg := g.bps.Div(decimal.NewFromInt(int64(g.count)))
+			sb.WriteString(fmt.Sprintf("`%-14s n=%-3d avg %6s bps | worst %6s | $%s`\n",
+				g.key, g.count, avg.StringFixed(1), g.worst.StringFixed(1), g.cost.StringFixed(2)))
+		}
+	}
+
+	total := decimal.Zero
+	for _, r := range records {
+		total = total.Add(r.Bps.Div(decimal.NewFromInt(10000)).Mul(r.DecisionPrice).Mul(r.Qty))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📐 *SLIPPAGE REPORT (%s)*\nOrders: %d | Net Cost: $%s\n(bps > 0 = filled worse than the decision price)\n", title, len(records), total.StringFixed(2)))
+	sb.WriteString("\n*By Symbol*\n")
+	writeGroups(&sb, groupSlippage(records, func(r models.SlippageRecord) string { return r.Ticker }))
+	sb.WriteString("\n*By Source / Order Type*\n")
+	writeGroups(&sb, groupSlippage(records, func(r models.SlippageRecord) string { return r.Source + "/" + r.OrderType }))
+	if improvement := priceImprovement(records); improvement != "" {
+		sb.WriteString("\n*Price Improvement vs Quoted Mid*\n")
+		sb.WriteString(improvement)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// priceImprovement summarizes fills against the quoted mid, one line per broker.
+// Records without a mid (older log lines, no quote) are left out; "" when none remain.
+func priceImprovement(records []models.SlippageRecord) string {
+	type brokerStats struct {
+		n, better, worse int
+		bps, usd         decimal.Decimal
+	}
+	byBroker := map[string]*brokerStats{}
+	var brokers []string
+	for _, r := range records {
+		if !r.QuoteMid.IsPositive() {
+			continue
+		}
+		key := r.Broker
+		if key == "" {
+			key = "unknown"
+		}
+		s, ok := byBroker[key]
+		if !ok {
+			s = &brokerStats{}
+			byBroker[key] = s
+			brokers = append(brokers, key)
+		}
+		s.n++
+		s.bps = s.bps.Add(r.ImprovementBps)
+		s.usd = s.usd.Add(r.ImprovementBps.Div(decimal.NewFromInt(10000)).Mul(r.QuoteMid).Mul(r.Qty))
+		switch {
+		case r.ImprovementBps.IsPositive():
+			s.better++
+		case r.ImprovementBps.IsNegative():
+			s.worse++
+		}
+	}
+	sort.Strings(brokers)
+
+	var sb strings.Builder
+	for _, b := range brokers {
+		s := byBroker[b]
+		sb.WriteString(fmt.Sprintf("`%-14s n=%-3d avg %6s bps | better %d / worse %d | $%s`\n",
+			b, s.n, s.bps.Div(decimal.NewFromInt(int64(s.n))).StringFixed(1), s.better, s.worse, s.usd.StringFixed(2)))
+	}
+	if sb.Len() > 0 {
+		sb.WriteString("(bps > 0 = filled better than mid)\n")
+	}
+	return sb.String()
+}
+
+// monthRange returns [first day of month, first day of next month) in CET.
+func monthRange(month string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", month, config.CetLoc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// handleSlippageCommand shows a month's report. /slippage [YYYY-MM] (default: current month)
+func (w *Watcher) handleSlippageCommand(parts []string) string {
+	month := w.clock.Now().In(config.CetLoc).Format("2006-01")
+	if len(parts) >= 2 {
+		month = parts[1]
+	}
+	start, end, err := monthRange(month)
+	if err != nil {
+		return "Usage: /slippage [YYYY-MM]"
+	}
+	return w.slippageReport(month, start, end)
+}
+
+// checkMonthlySlippageReport sends the previous month's report on the first poll of a new
+// month. The month is only marked as done once the report went out, so a report muted
+// by quiet hours is retried on a later poll.
+func (w *Watcher) checkMonthlySlippageReport() {
+	current := w.clock.Now().In(config.CetLoc).Format("2006-01")
+	w.mu.RLock()
+	last := w.state.LastSlippageMonth
+	w.mu.RUnlock()
+	if last == current {
+		return
+	}
+
+	// On the first run (no month yet) tracking just starts from this month
+	if last != "" {
+		curStart, _, _ := monthRange(current)
+		prev := curStart.AddDate(0, -1, 0).Format("2006-01")
+		if start, end, err := monthRange(prev); err == nil {
+			if records, err := storage.LoadSlippage(start, end); err == nil && len(records) > 0 {
+				if !w.notifyRoutine(w.slippageReport(prev, start, end)) {
+					return
+				}
+			}
+		}
+	}
+
+	w.mu.Lock()
+	w.state.LastSlippageMonth = current
+	w.saveStateLocked()
+	w.mu.Unlock()
+}
@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"alpha_trading/internal/models"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// PriceSnapshot is the per-symbol market view built from a single snapshot call.
+// Zero values mean the field was not available.
+type PriceSnapshot struct {
+	Last      decimal.Decimal
+	Bid       decimal.Decimal
+	Ask       decimal.Decimal
+	Open      decimal.Decimal // Today's (or the latest session's) open
+	High      decimal.Decimal // Today's (or the latest session's) range
+	Low       decimal.Decimal
+	PrevClose decimal.Decimal
+	Session   string // Date of the daily bar (YYYY-MM-DD)
+}
+
+// DayChangePct returns the % change of Last vs the previous close.
+func (s PriceSnapshot) DayChangePct() decimal.Decimal {
+	if s.PrevClose.IsZero() {
+		return decimal.Zero
+	}
+	return s.Last.Sub(s.PrevClose).Div(s.PrevClose).Mul(decimal.NewFromInt(100))
+}
+
+// GapPct returns the % opening gap vs the previous close.
+func (s PriceSnapshot) GapPct() decimal.Decimal {
+	if s.PrevClose.IsZero() || s.Open.IsZero() {
+		return decimal.Zero
+	}
+	return s.Open.Sub(s.PrevClose).Div(s.PrevClose).Mul(decimal.NewFromInt(100))
+}
+
+// priceSnapshotFrom converts a provider snapshot (ok=false without a latest trade).
+func priceSnapshotFrom(snap *marketdata.Snapshot) (PriceSnapshot, bool) {
+	var s PriceSnapshot
+	if snap == nil || snap.LatestTrade == nil {
+		return s, false
+	}
+	s.Last = decimal.NewFromFloat(snap.LatestTrade.Price)
+	if q := snap.LatestQuote; q != nil {
+		s.Bid = decimal.NewFromFloat(q.BidPrice)
+		s.Ask = decimal.NewFromFloat(q.AskPrice)
+	}
+	if b := snap.DailyBar; b != nil {
+		s.Open = decimal.NewFromFloat(b.Open)
+		s.High = decimal.NewFromFloat(b.High)
+		s.Low = decimal.NewFromFloat(b.Low)
+		s.Session = b.Timestamp.Format("2006-01-02")
+	}
+	if b := snap.PrevDailyBar; b != nil {
+		s.PrevClose = decimal.NewFromFloat(b.Close)
+	}
+	return s, true
+}
+
+// getSnapshot fetches everything we need for a symbol in one API call.
+// If the snapshot endpoint fails (e.g., crypto symbols), it falls back to GetPrice + GetBars.
+func (w *Watcher) getSnapshot(ticker string) (PriceSnapshot, error) {
+	snap, err := w.provider.GetSnapshot(w.ctx, ticker)
+	if s, ok := priceSnapshotFrom(snap); err == nil && ok {
+		return s, nil
+	}
+	var s PriceSnapshot
+	if err != nil {
+		log.Printf("Snapshot unavailable for %s (%v). Falling back to trade + bars.", ticker, err)
+	}
+
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil {
+		return s, err
+	}
+	s.Last = price
+	if bars, err := w.provider.GetBars(w.ctx, ticker, 1); err == nil && len(bars) > 0 {
+		s.PrevClose = decimal.NewFromFloat(bars[len(bars)-1].Close)
+	}
+	return s, nil
+}
+
+// getSnapshots fetches many symbols with one batched request. Symbols the batch could
+// not serve fall back to getSnapshot one by one; those that still fail are left out.
+func (w *Watcher) getSnapshots(tickers []string) map[string]PriceSnapshot {
+	out := make(map[string]PriceSnapshot, len(tickers))
+	if len(tickers) == 0 {
+		return out
+	}
+	snaps, err := w.provider.GetSnapshots(w.ctx, tickers)
+	if err != nil {
+		log.Printf("Batched snapshot failed (%v). Fetching %d symbols one by one.", err, len(tickers))
+	}
+	for _, t := range tickers {
+		if s, ok := priceSnapshotFrom(snaps[t]); ok {
+			out[t] = s
+			continue
+		}
+		if s, err := w.getSnapshot(t); err == nil {
+			out[t] = s
+		}
+	}
+	return out
+}
+
+// checkGaps alerts once per session when a held position opens with a gap beyond GAP_ALERT_PCT.
+// Gaps through the stop are flagged explicitly since the stop will fill below its level.
+func (w *Watcher) checkGaps() {
+	threshold := decimal.NewFromFloat(w.config.GapAlertPct)
+	if !threshold.IsPositive() {
+		return
+	}
+
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+
+	for _, p := range positions {
+		snap, err := w.getSnapshot(p.Ticker)
+		if err != nil || snap.Session == "" {
+			continue
+		}
+		gap := snap.GapPct()
+		if gap.Abs().LessThan(threshold) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s_GAP_%s", p.Ticker, snap.Session)
+		w.mu.Lock()
+		_, alerted := w.lastAlerts[key]
+		if !alerted {
+			w.lastAlerts[key] = w.clock.Now()
+		}
+		w.mu.Unlock()
+		if alerted {
+			continue
+		}
+
+		icon := "🟢"
+		if gap.IsNegative() {
+			icon = "🔴"
+		}
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("%s *GAP ALERT: %s*\nOpen $%s vs Prev Close $%s (%s%%)",
+			icon, p.Ticker, snap.Open.StringFixed(2), snap.PrevClose.StringFixed(2), gap.StringFixed(2)))
+		if !p.StopLoss.IsZero() && snap.Open.LessThanOrEqual(p.StopLoss) {
+			sb.WriteString(fmt.Sprintf("\n⚠️ Opened BELOW stop ($%s). Expect slippage on exit.", p.StopLoss.StringFixed(2)))
+		}
+		if !w.notifyRoutine(sb.String()) {
+			// Muted by quiet hours: allow the alert again on a later poll
+			w.mu.Lock()
+			delete(w.lastAlerts, key)
+			w.mu.Unlock()
+		}
+	}
+}
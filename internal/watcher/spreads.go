@@ -0,0 +1,245 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	spreadMinSamples  = 5                  // History needed before it replaces the current quote
+	spreadReflagAfter = 7 * 24 * time.Hour // A flagged ticker is not flagged again for a week
+)
+
+// spreadStats summarizes a ticker's sampled spreads (bps of mid).
+type spreadStats struct {
+	Ticker  string
+	Samples int
+	Median  decimal.Decimal
+	P90     decimal.Decimal
+}
+
+// sampleSpreads records the bid/ask spread of every held and watched ticker, once per
+// poll (SPREAD_TRACKING_ENABLED). Equity quotes are only sampled while the market is
+// open: closed-market books are wide and stale. Forex rates carry no real spread.
+func (w *Watcher) sampleSpreads() {
+	if !w.config.SpreadTrackingEnabled {
+		return
+	}
+	w.mu.RLock()
+	seen := make(map[string]bool)
+	var tickers []string
+	add := func(t string) {
+		if seen[t] || symbols.IsForex(t) || (!w.wasMarketOpen && !symbols.IsCrypto(t)) {
+			return
+		}
+		seen[t] = true
+		tickers = append(tickers, t)
+	}
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			add(p.Ticker)
+		}
+	}
+	for _, t := range w.watchlistTickers() {
+		add(symbols.Normalize(t))
+	}
+	w.mu.RUnlock()
+	if len(tickers) == 0 {
+		return
+	}
+
+	now := w.clock.Now()
+	var samples []models.SpreadSample
+	for t, snap := range w.getSnapshots(tickers) {
+		if bps, ok := spreadBps(snap.Bid, snap.Ask); ok {
+			samples = append(samples, models.SpreadSample{Time: now, Ticker: t, Bid: snap.Bid, Ask: snap.Ask, Bps: bps})
+		}
+	}
+	if err := storage.AppendSpreads(samples); err != nil {
+		log.Printf("ERROR: Failed to record spreads: %v", err)
+	}
+}
+
+// spreadBps returns (ask - bid) / mid in basis points. ok is false for a missing,
+// one-sided or crossed book.
+func spreadBps(bid, ask decimal.Decimal) (decimal.Decimal, bool) {
+	if !bid.IsPositive() || !ask.IsPositive() || ask.LessThan(bid) {
+		return decimal.Zero, false
+	}
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+	return ask.Sub(bid).Div(mid).Mul(decimal.NewFromInt(10000)), true
+}
+
+// loadSpreadStats summarizes the samples of the last SPREAD_LOOKBACK_DAYS per ticker.
+func (w *Watcher) loadSpreadStats() (map[string]spreadStats, error) {
+	since := w.clock.Now().AddDate(0, 0, -w.config.SpreadLookbackDays)
+	samples, err := storage.LoadSpreads(since)
+	if err != nil {
+		return nil, err
+	}
+	byTicker := make(map[string][]decimal.Decimal)
+	for _, s := range samples {
+		byTicker[s.Ticker] = append(byTicker[s.Ticker], s.Bps)
+	}
+	out := make(map[string]spreadStats, len(byTicker))
+	for t, bps := range byTicker {
+		sort.Slice(bps, func(i, j int) bool { return bps[i].LessThan(bps[j]) })
+		out[t] = spreadStats{Ticker: t, Samples: len(bps), Median: nearestRank(bps, 50), P90: nearestRank(bps, 90)}
+	}
+	return out, nil
+}
+
+// nearestRank returns the nearest-rank percentile (p in 0-100) of sorted values.
+func nearestRank(sorted []decimal.Decimal, p float64) decimal.Decimal {
+	if len(sorted) == 0 {
+		return decimal.Zero
+	}
+	i := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// spreadStopRatio is the spread as a % of the stop distance (stopPct, in %).
+func spreadStopRatio(bps, stopPct decimal.Decimal) decimal.Decimal {
+	if !stopPct.IsPositive() {
+		return decimal.Zero
+	}
+	return bps.Div(stopPct.Mul(decimal.NewFromInt(100))).Mul(decimal.NewFromInt(100))
+}
+
+// spreadWarning is the liquidity guard on a buy proposal: it warns when the ticker's
+// typical spread (median of its sampled history, or the current quote while the history
+// is short) is at least SPREAD_STOP_RATIO_PCT of the stop distance. "" = fine.
+func (w *Watcher) spreadWarning(ticker string, stopPct decimal.Decimal) string {
+	if w.config.SpreadStopRatioPct <= 0 || !stopPct.IsPositive() {
+		return ""
+	}
+	var bps decimal.Decimal
+	basis := ""
+	if stats, err := w.loadSpreadStats(); err == nil && stats[ticker].Samples >= spreadMinSamples {
+		st := stats[ticker]
+		bps = st.Median
+		basis = fmt.Sprintf("median of %d quotes over %dd", st.Samples, w.config.SpreadLookbackDays)
+	} else if q, err := w.provider.GetQuote(w.ctx, ticker); err == nil && q != nil {
+		current, ok := spreadBps(decimal.NewFromFloat(q.BidPrice), decimal.NewFromFloat(q.AskPrice))
+		if !ok {
+			return ""
+		}
+		bps, basis = current, "current quote only"
+	} else {
+		return ""
+	}
+
+	ratio := spreadStopRatio(bps, stopPct)
+	if ratio.LessThan(decimal.NewFromFloat(w.config.SpreadStopRatioPct)) {
+		return ""
+	}
+	return fmt.Sprintf("💧 *Wide spread*: %s bps (%s) is %s%% of the %s%% stop distance. Entry and exit together cost about that much before the price moves. Consider a wider stop, a limit order, or a more liquid symbol.",
+		bps.StringFixed(0), basis, ratio.StringFixed(0), stopPct.StringFixed(1))
+}
+
+// checkSpreads warns once a day about held or watched tickers whose typical spread is
+// at least SPREAD_STOP_RATIO_PCT of the default stop distance (DEFAULT_STOP_LOSS_PCT).
+func (w *Watcher) checkSpreads() {
+	if !w.config.SpreadTrackingEnabled || w.config.SpreadStopRatioPct <= 0 || w.config.DefaultStopLossPct <= 0 {
+		return
+	}
+	today := w.clock.Now().In(config.CetLoc).Format("2006-01-02")
+	w.mu.Lock()
+	if w.spreadCheckDay == today {
+		w.mu.Unlock()
+		return
+	}
+	w.spreadCheckDay = today
+	w.mu.Unlock()
+
+	stats, err := w.loadSpreadStats()
+	if err != nil {
+		log.Printf("Spread check skipped: %v", err)
+		return
+	}
+	stopPct := decimal.NewFromFloat(w.config.DefaultStopLossPct)
+	limit := decimal.NewFromFloat(w.config.SpreadStopRatioPct)
+
+	var flagged []spreadStats
+	w.mu.Lock()
+	for _, st := range stats {
+		if st.Samples < spreadMinSamples || spreadStopRatio(st.Median, stopPct).LessThan(limit) {
+			continue
+		}
+		key := st.Ticker + "_SPREAD"
+		if last, ok := w.lastAlerts[key]; ok && w.since(last) < spreadReflagAfter {
+			continue
+		}
+		w.lastAlerts[key] = w.clock.Now()
+		flagged = append(flagged, st)
+	}
+	w.mu.Unlock()
+	if len(flagged) == 0 {
+		return
+	}
+	sort.Slice(flagged, func(i, j int) bool { return flagged[i].Median.GreaterThan(flagged[j].Median) })
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("💧 *WIDE SPREADS* (vs the %s%% default stop)\n", stopPct.StringFixed(1)))
+	for _, st := range flagged {
+		sb.WriteString(fmt.Sprintf("• %s: typically %s bps (p90 %s), %s%% of the stop distance\n",
+			st.Ticker, st.Median.StringFixed(0), st.P90.StringFixed(0), spreadStopRatio(st.Median, stopPct).StringFixed(0)))
+	}
+	sb.WriteString("\nA stop this tight loses much of its room to the spread. Use wider stops or limit orders for these. Details: /spreads")
+	w.notifyRoutine(sb.String())
+}
+
+// handleSpreadsCommand shows the sampled spread history. /spreads [ticker]
+func (w *Watcher) handleSpreadsCommand(parts []string) string {
+	stats, err := w.loadSpreadStats()
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read the spread log: %v", err)
+	}
+	if len(parts) >= 2 {
+		ticker := symbols.Normalize(parts[1])
+		st, ok := stats[ticker]
+		if !ok {
+			return fmt.Sprintf("💧 No spreads sampled for %s in the last %dd.", ticker, w.config.SpreadLookbackDays)
+		}
+		stats = map[string]spreadStats{ticker: st}
+	}
+	if len(stats) == 0 {
+		if !w.config.SpreadTrackingEnabled {
+			return "💧 Spread tracking is off (SPREAD_TRACKING_ENABLED=false)."
+		}
+		return "💧 No spreads sampled yet. Held and watched tickers are sampled every poll while their market is open."
+	}
+
+	list := make([]spreadStats, 0, len(stats))
+	for _, st := range stats {
+		list = append(list, st)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Median.GreaterThan(list[j].Median) })
+
+	stopPct := decimal.NewFromFloat(w.config.DefaultStopLossPct)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("💧 *SPREADS* (last %dd, bps of mid)\n```\n", w.config.SpreadLookbackDays))
+	sb.WriteString(fmt.Sprintf("%-9s %5s %5s %5s %6s\n", "Ticker", "N", "Med", "p90", "%Stop"))
+	for _, st := range list {
+		flag := ""
+		if st.Samples >= spreadMinSamples && w.config.SpreadStopRatioPct > 0 &&
+			!spreadStopRatio(st.Median, stopPct).LessThan(decimal.NewFromFloat(w.config.SpreadStopRatioPct)) {
+			flag = " !"
+		}
+		sb.WriteString(fmt.Sprintf("%-9s %5d %5s %5s %5s%%%s\n", st.Ticker, st.Samples, st.Median.StringFixed(0), st.P90.StringFixed(0),
+			spreadStopRatio(st.Median, stopPct).StringFixed(0), flag))
+	}
+	sb.WriteString("```")
+	sb.WriteString(fmt.Sprintf("\n%%Stop: median spread as a share of the %s%% default stop. `!` marks %s%% or more.", stopPct.StringFixed(1), decimal.NewFromFloat(w.config.SpreadStopRatioPct).String()))
+	return sb.String()
+}
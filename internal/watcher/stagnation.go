@@ -0,0 +1,176 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/symbols"
+	"alpha_trading/internal/telegram"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/marketdata"
+	"github.com/shopspring/decimal"
+)
+
+// atrPeriod is the lookback (daily bars) of the stagnation ATR.
+const atrPeriod = 14
+
+// stagnationRule is a flatness threshold: a position older than Hours whose move
+// from entry is below ATRMultiple daily ATRs is dead money.
+type stagnationRule struct {
+	ATRMultiple float64
+	Hours       int
+}
+
+// atrEntry caches a ticker's daily ATR for one CET day.
+type atrEntry struct {
+	day string
+	atr decimal.Decimal
+}
+
+// stagnationRules parses STAGNATION_RULES ("lowvol=0.3:240,crypto=0.8:48") into
+// tag -> rule. Hours may be omitted ("lowvol=0.3") to keep MAX_STAGNATION_HOURS.
+func (w *Watcher) stagnationRules() map[string]stagnationRule {
+	rules := map[string]stagnationRule{}
+	for _, item := range w.config.StagnationRules {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			log.Printf("Warning: Ignoring invalid STAGNATION_RULES entry %q", item)
+			continue
+		}
+		vals := strings.SplitN(kv[1], ":", 2)
+		mult, err := strconv.ParseFloat(strings.TrimSpace(vals[0]), 64)
+		hours := w.config.MaxStagnationHours
+		if err == nil && len(vals) == 2 {
+			hours, err = strconv.Atoi(strings.TrimSpace(vals[1]))
+		}
+		if err != nil || mult <= 0 || hours <= 0 {
+			log.Printf("Warning: Ignoring invalid STAGNATION_RULES entry %q", item)
+			continue
+		}
+		rules[strings.ToLower(strings.TrimSpace(kv[0]))] = stagnationRule{ATRMultiple: mult, Hours: hours}
+	}
+	return rules
+}
+
+// stagnationRuleFor resolves the rule for ticker: its metadata tags, sector and
+// industry first (in that order), then "crypto" for crypto pairs, then the
+// default (MAX_STAGNATION_HOURS, STAGNATION_ATR_MULTIPLE).
+func (w *Watcher) stagnationRuleFor(ticker string) (stagnationRule, string) {
+	rules := w.stagnationRules()
+	tags := w.metadata.Tags(ticker)
+	if symbols.IsCrypto(ticker) {
+		tags = append(tags, "crypto")
+	}
+	for _, t := range tags {
+		if r, ok := rules[t]; ok {
+			return r, t
+		}
+	}
+	return stagnationRule{ATRMultiple: w.config.StagnationATRMultiple, Hours: w.config.MaxStagnationHours}, ""
+}
+
+// averageTrueRange is the simple mean of the last period true ranges.
+// Returns zero when there are not enough bars.
+func averageTrueRange(bars []marketdata.Bar, period int) float64 {
+	if len(bars) < 2 {
+		return 0
+	}
+	start := 1
+	if len(bars)-1 > period {
+		start = len(bars) - period
+	}
+	sum := 0.0
+	for i := start; i < len(bars); i++ {
+		prev := bars[i-1].Close
+		tr := math.Max(bars[i].High-bars[i].Low, math.Max(math.Abs(bars[i].High-prev), math.Abs(bars[i].Low-prev)))
+		sum += tr
+	}
+	return sum / float64(len(bars)-start)
+}
+
+// dailyATR returns ticker's daily ATR, fetched at most once per CET day. The bars
+// are fetched outside the lock. Zero means unavailable.
+func (w *Watcher) dailyATR(ticker string) decimal.Decimal {
+	day := w.clock.Now().In(config.CetLoc).Format("2006-01-02")
+	w.mu.RLock()
+	e, ok := w.atrCache[ticker]
+	w.mu.RUnlock()
+	if ok && e.day == day {
+		return e.atr
+	}
+	atr := decimal.Zero
+	if bars, err := w.provider.GetBars(w.ctx, ticker, atrPeriod+1); err == nil {
+		atr = decimal.NewFromFloat(averageTrueRange(bars, atrPeriod))
+	} else {
+		log.Printf("[%s] Stagnation: ATR unavailable: %v", ticker, err)
+	}
+	w.mu.Lock()
+	w.atrCache[ticker] = atrEntry{day: day, atr: atr}
+	w.mu.Unlock()
+	return atr
+}
+
+// cachedATRLocked returns today's ATR from the cache (see dailyATR); zero when it was
+// not fetched. Caller must hold w.mu.
+func (w *Watcher) cachedATRLocked(ticker string) decimal.Decimal {
+	if e, ok := w.atrCache[ticker]; ok && e.day == w.clock.Now().In(config.CetLoc).Format("2006-01-02") {
+		return e.atr
+	}
+	return decimal.Zero
+}
+
+// stagnationDue reports whether pos is old enough for the dead money guard, whose
+// ATR checkRisk then fetches before taking the write lock. Caller holds w.mu (read).
+func (w *Watcher) stagnationDue(pos models.Position) bool {
+	if pos.OpenedAt.IsZero() || !pos.EntryPrice.IsPositive() {
+		return false
+	}
+	rule, _ := w.stagnationRuleFor(pos.Ticker)
+	return w.since(pos.OpenedAt).Hours() > float64(rule.Hours)
+}
+
+// checkStagnationLocked is the Spec 66 dead money guard. Flatness is measured in
+// ATR units so a quiet utility and a volatile miner are judged on their own scale;
+// without an ATR it falls back to the legacy ±1% band. The ATR comes from the cache
+// filled by checkRisk. Caller must hold w.mu.
+func (w *Watcher) checkStagnationLocked(pos models.Position, price decimal.Decimal) {
+	if pos.OpenedAt.IsZero() || !pos.EntryPrice.IsPositive() {
+		return
+	}
+	rule, tag := w.stagnationRuleFor(pos.Ticker)
+	hoursOpen := w.since(pos.OpenedAt).Hours()
+	if hoursOpen <= float64(rule.Hours) {
+		return
+	}
+
+	diff := price.Sub(pos.EntryPrice)
+	pct := diff.Div(pos.EntryPrice).Mul(decimal.NewFromInt(100))
+	measure := fmt.Sprintf("%.2f%%", pct.InexactFloat64())
+	if atr := w.cachedATRLocked(pos.Ticker); atr.IsPositive() {
+		units := diff.Abs().Div(atr)
+		if !units.LessThan(decimal.NewFromFloat(rule.ATRMultiple)) {
+			return
+		}
+		measure += fmt.Sprintf(", %.2f ATR < %.2f", units.InexactFloat64(), rule.ATRMultiple)
+	} else if !pct.Abs().LessThan(decimal.NewFromFloat(1.0)) {
+		return
+	}
+	if tag != "" {
+		measure += ", rule: " + tag
+	}
+
+	key := fmt.Sprintf("%s_STAGNATION", pos.Ticker)
+	// Alert once every 24h
+	// Routine alert: skipped (not recorded) during quiet hours
+	if last, ok := w.lastAlerts[key]; (!ok || w.since(last) > 24*time.Hour) && !w.quietHoursLocked() {
+		telegram.Notify(fmt.Sprintf("⏳ STAGNATION ALERT: %s has been flat for %d days (%s). Consider manual liquidation to free up budget.",
+			pos.Ticker, int(hoursOpen/24), measure))
+		w.lastAlerts[key] = w.clock.Now()
+	}
+}
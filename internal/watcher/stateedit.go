@@ -0,0 +1,316 @@
+package watcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/storage"
+
+	"github.com/shopspring/decimal"
+)
+
+const stateGetMaxLen = 3500 // Stay under Telegram's 4096-character message limit
+
+// handleStateCommand reads or edits one field of the state file.
+// /state get [path]        -> show a field (no path lists the top-level fields)
+// /state set <path> <value> -> back up the state file, then change the field
+// Paths are dot-separated JSON keys; list items are addressed by index or, for
+// records with a ticker, by ticker (ACTIVE first): positions.AAPL.stop_loss.
+func (w *Watcher) handleStateCommand(parts []string) string {
+	usage := "Usage: /state get [path] | /state set <path> <value>\nExample: `/state set positions.AAPL.stop_loss 182.5`"
+	if len(parts) < 2 {
+		return usage
+	}
+	switch strings.ToLower(parts[1]) {
+	case "get":
+		path := ""
+		if len(parts) >= 3 {
+			path = parts[2]
+		}
+		return w.stateGet(path)
+	case "set":
+		if len(parts) < 4 {
+			return usage
+		}
+		return w.stateSet(parts[2], strings.Join(parts[3:], " "))
+	}
+	return usage
+}
+
+// stateTree converts the state to generic JSON values.
+func stateTree(s models.PortfolioState) (map[string]interface{}, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(string(b)))
+	d.UseNumber() // Keep decimals exact
+	if err := d.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// splitStatePath splits "positions.AAPL.stop_loss" into its keys.
+func splitStatePath(path string) ([]string, error) {
+	keys := strings.Split(strings.Trim(path, "."), ".")
+	for _, k := range keys {
+		if k == "" {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+	}
+	return keys, nil
+}
+
+// listIndex resolves a list key: a numeric index or a ticker (ACTIVE records first).
+func listIndex(list []interface{}, key string) (int, error) {
+	if i, err := strconv.Atoi(key); err == nil {
+		if i < 0 || i >= len(list) {
+			return 0, fmt.Errorf("index %d out of range (%d items)", i, len(list))
+		}
+		return i, nil
+	}
+	found := -1
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok || !strings.EqualFold(fmt.Sprint(m["ticker"]), key) {
+			continue
+		}
+		if m["status"] == "ACTIVE" {
+			return i, nil
+		}
+		if found < 0 {
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, fmt.Errorf("no item %q", key)
+	}
+	return found, nil
+}
+
+// lookupState walks keys from node and returns the value found.
+func lookupState(node interface{}, keys []string) (interface{}, error) {
+	for i, k := range keys {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[k]
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", strings.Join(keys[:i+1], "."))
+			}
+			node = v
+		case []interface{}:
+			idx, err := listIndex(n, k)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", strings.Join(keys[:i], "."), err)
+			}
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("%q is not an object or list", strings.Join(keys[:i], "."))
+		}
+	}
+	return node, nil
+}
+
+// assignState sets the value at keys below root. The last key may be a new map entry
+// (e.g., a watchlist price); whether the state type accepts it is checked by the caller.
+func assignState(root map[string]interface{}, keys []string, value interface{}) error {
+	parent, err := lookupState(root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	if parent == nil && len(keys) > 1 {
+		// An empty map is stored as null: start one (the schema check rejects non-maps)
+		parent = map[string]interface{}{}
+		if err := assignState(root, keys[:len(keys)-1], parent); err != nil {
+			return err
+		}
+	}
+	last := keys[len(keys)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		idx, err := listIndex(p, last)
+		if err != nil {
+			return err
+		}
+		p[idx] = value
+	default:
+		return fmt.Errorf("%q is not an object or list", strings.Join(keys[:len(keys)-1], "."))
+	}
+	return nil
+}
+
+// parseStateValue reads value as JSON (numbers, true/false, null, quoted strings,
+// objects); anything else is taken as a plain string.
+func parseStateValue(value string) interface{} {
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(value))
+	d.UseNumber()
+	if err := d.Decode(&v); err == nil && !d.More() {
+		return v
+	}
+	return value
+}
+
+// sameJSON reports whether a and b encode the same value. Numbers compare by value
+// (decimals are stored as quoted strings) and timestamps by instant.
+func sameJSON(a, b interface{}) bool {
+	sa, sb := fmt.Sprint(a), fmt.Sprint(b)
+	if da, err := decimal.NewFromString(sa); err == nil {
+		if db, err := decimal.NewFromString(sb); err == nil {
+			return da.Equal(db)
+		}
+	}
+	if ta, err := time.Parse(time.RFC3339Nano, sa); err == nil {
+		if tb, err := time.Parse(time.RFC3339Nano, sb); err == nil {
+			return ta.Equal(tb)
+		}
+	}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func (w *Watcher) stateGet(path string) string {
+	w.mu.RLock()
+	tree, err := stateTree(w.state)
+	w.mu.RUnlock()
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read state: %v", err)
+	}
+	if path == "" {
+		keys := make([]string, 0, len(tree))
+		for k := range tree {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return "🗂 *STATE FIELDS*\n`" + strings.Join(keys, "`, `") + "`\n\nUse `/state get <field>` (e.g., `positions.AAPL`)."
+	}
+	keys, err := splitStatePath(path)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	v, err := lookupState(tree, keys)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("❌ Could not encode %s: %v", path, err)
+	}
+	out := string(b)
+	if len(out) > stateGetMaxLen {
+		out = out[:stateGetMaxLen] + "\n… (truncated, narrow the path)"
+	}
+	return fmt.Sprintf("🗂 `%s`\n```\n%s\n```", path, out)
+}
+
+// stateSet changes one field. The edited state must decode into the state type with the
+// value intact and must not add integrity issues (/doctor); otherwise nothing changes.
+func (w *Watcher) stateSet(path, raw string) string {
+	keys, err := splitStatePath(path)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	value := parseStateValue(raw)
+
+	// Dry run on a copy first, so a rejected edit never costs a backup file
+	w.mu.RLock()
+	_, _, _, err = applyStateEdit(w.state, keys, value)
+	w.mu.RUnlock()
+	if err != nil {
+		return fmt.Sprintf("❌ %s not changed: %v", path, err)
+	}
+
+	w.FlushState()
+	backup, err := storage.BackupState()
+	if err != nil {
+		return fmt.Sprintf("❌ Backup failed (%v). Nothing was changed.", err)
+	}
+
+	w.mu.Lock()
+	// Re-apply on the live state: it may have moved since the dry run
+	edited, old, ticker, err := applyStateEdit(w.state, keys, value)
+	if err == nil {
+		if ticker != "" && keys[len(keys)-1] == "high_water_mark" {
+			// A deliberate correction, not a regression for the HWM guard to undo
+			if hwm, err := decimal.NewFromString(fmt.Sprint(value)); err == nil {
+				w.writer.RebaseHWM(ticker, hwm)
+			}
+		}
+		w.state = edited
+		w.saveStateLocked()
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return fmt.Sprintf("❌ %s not changed: %v", path, err)
+	}
+
+	oldJSON, _ := json.Marshal(old)
+	newJSON, _ := json.Marshal(value)
+	log.Printf("State Edit: %s %s -> %s (backup %s)", path, oldJSON, newJSON, backup)
+	if ticker != "" {
+		w.recordAudit(ticker, auditEdited, "USER", decimal.Zero, fmt.Sprintf("%s: %s -> %s", strings.Join(keys[2:], "."), oldJSON, newJSON))
+	}
+	return fmt.Sprintf("✅ `%s`: `%s` → `%s`\n💾 Backup: `%s`", path, oldJSON, newJSON, backup)
+}
+
+// applyStateEdit returns a copy of s with the field at keys set to value, the old
+// value, and the ticker of the edited position ("" when the edit is not on a position).
+func applyStateEdit(s models.PortfolioState, keys []string, value interface{}) (models.PortfolioState, interface{}, string, error) {
+	tree, err := stateTree(s)
+	if err != nil {
+		return s, nil, "", err
+	}
+	old, oldErr := lookupState(tree, keys) // May be absent (omitted empty field, new map entry)
+	if err := assignState(tree, keys, value); err != nil {
+		return s, nil, "", err
+	}
+
+	b, err := json.Marshal(tree)
+	if err != nil {
+		return s, nil, "", err
+	}
+	var edited models.PortfolioState
+	if err := json.Unmarshal(b, &edited); err != nil {
+		return s, nil, "", fmt.Errorf("invalid value: %v", err)
+	}
+
+	// The value must survive the round trip: unknown fields are silently dropped
+	check, err := stateTree(edited)
+	if err != nil {
+		return s, nil, "", err
+	}
+	got, err := lookupState(check, keys)
+	cleared := err != nil && oldErr == nil && (value == nil || value == "") // Emptied an omitempty field
+	if !cleared && (err != nil || !sameJSON(got, value)) {
+		return s, nil, "", fmt.Errorf("unknown field or value not accepted by the state schema")
+	}
+	ticker := ""
+	if len(keys) >= 3 && keys[0] == "positions" {
+		if p, err := lookupState(check, keys[:2]); err == nil {
+			if m, ok := p.(map[string]interface{}); ok {
+				ticker, _ = m["ticker"].(string)
+			}
+		}
+	}
+
+	before := len(validateState(s))
+	if issues := validateState(edited); len(issues) > before {
+		var msgs []string
+		for _, i := range issues {
+			msgs = append(msgs, i.Ticker+": "+i.Problem)
+		}
+		return s, nil, "", fmt.Errorf("the edit fails the integrity check (%s)", strings.Join(msgs, "; "))
+	}
+	return edited, old, ticker, nil
+}
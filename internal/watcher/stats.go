@@ -0,0 +1,105 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+)
+
+const statsErrorLen = 120 // Characters of each last error shown by /stats
+
+// providerMetrics returns the call metrics wrapper in the provider chain, or nil
+// when METRICS_ENABLED is off.
+func (w *Watcher) providerMetrics() *market.MetricsProvider {
+	p := w.provider
+	for {
+		if m, ok := p.(*market.MetricsProvider); ok {
+			return m
+		}
+		wrapper, ok := p.(interface{ Unwrap() market.MarketProvider })
+		if !ok {
+			return nil
+		}
+		p = wrapper.Unwrap()
+	}
+}
+
+// trackPoll starts timing a poll; the returned func logs its duration and the provider
+// calls it made. Usage: defer w.trackPoll()()
+func (w *Watcher) trackPoll() func() {
+	start := w.clock.Now()
+	m := w.providerMetrics()
+	if m == nil {
+		return func() {}
+	}
+	calls, errs, spent := m.Totals()
+	return func() {
+		c, e, s := m.Totals()
+		if c < calls {
+			return // Reset by /stats reset mid-poll
+		}
+		log.Printf("Poll Stats: took %s, %d provider calls (%d errors), %s in calls",
+			w.since(start).Round(time.Millisecond), c-calls, e-errs, (s - spent).Round(time.Millisecond))
+	}
+}
+
+// shortDuration formats a latency as 850ms or 2.3s.
+func shortDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// handleStatsCommand shows provider call metrics per method. /stats [reset]
+func (w *Watcher) handleStatsCommand(parts []string) string {
+	m := w.providerMetrics()
+	if m == nil {
+		return "⚠️ Call metrics are off (METRICS_ENABLED=false)."
+	}
+	if len(parts) >= 2 {
+		if strings.ToLower(parts[1]) != "reset" {
+			return "Usage: /stats [reset]"
+		}
+		m.Reset()
+		return "✅ Provider stats reset."
+	}
+
+	stats, since := m.Snapshot()
+	if len(stats) == 0 {
+		return "📊 No provider calls recorded yet."
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📊 *PROVIDER STATS* (since %s, %s)\n```\n", since.In(config.CetLoc).Format("01-02 15:04"), w.since(since).Round(time.Minute)))
+	sb.WriteString(fmt.Sprintf("%-19s %5s %5s %6s %6s %6s\n", "Method", "Calls", "Err%", "Avg", "p95", "Max"))
+	var calls, errs int
+	var total time.Duration
+	for _, s := range stats {
+		sb.WriteString(fmt.Sprintf("%-19s %5d %5.1f %6s %6s %6s\n", s.Method, s.Calls, s.ErrorRate(), shortDuration(s.Avg()), shortDuration(s.P95), shortDuration(s.Max)))
+		calls += s.Calls
+		errs += s.Errors
+		total += s.Total
+	}
+	sb.WriteString("```")
+	sb.WriteString(fmt.Sprintf("\nTotal: %d calls, %d errors, %s in calls", calls, errs, total.Round(time.Second)))
+
+	var failing []string
+	for _, s := range stats {
+		if s.Errors > 0 {
+			msg := s.LastError
+			if len(msg) > statsErrorLen {
+				msg = msg[:statsErrorLen] + "…"
+			}
+			failing = append(failing, fmt.Sprintf("%s (%s): %s", s.Method, s.LastErrAt.In(config.CetLoc).Format("15:04"), msg))
+		}
+	}
+	if len(failing) > 0 {
+		// Code block: broker errors may contain Markdown characters
+		sb.WriteString("\n\n*Last errors*\n```\n" + strings.Join(failing, "\n") + "\n```")
+	}
+	return sb.String()
+}
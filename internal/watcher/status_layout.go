@@ -0,0 +1,210 @@
+package watcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/money"
+
+	"github.com/shopspring/decimal"
+)
+
+// statusDetail is the live data for one position in the /status dashboard.
+type statusDetail struct {
+	Ticker    string
+	Qty       decimal.Decimal
+	Current   decimal.Decimal
+	PrevClose decimal.Decimal
+	Entry     decimal.Decimal
+	SL        decimal.Decimal
+	TP        decimal.Decimal
+	TrailPct  decimal.Decimal
+	HWM       decimal.Decimal
+	DayHigh   decimal.Decimal // Today's range (zero = unknown)
+	DayLow    decimal.Decimal
+	Carry     decimal.Decimal // Accrued crypto carry, part of the total P/L
+}
+
+// statusCells holds the formatted values for each selectable column.
+type statusCells map[string]string
+
+func plString(v decimal.Decimal) string {
+	icon := "🟢"
+	if v.IsNegative() {
+		icon = "🔴"
+	}
+	return icon + v.StringFixed(2)
+}
+
+// cells formats every column for a position. weightBase is the denominator for "weight".
+func (d statusDetail) cells(weightBase decimal.Decimal) statusCells {
+	c := statusCells{
+		"price":  strings.TrimPrefix(money.USD(d.Current), "$"),
+		"day":    "-",
+		"total":  plString(d.Current.Sub(d.Entry).Mul(d.Qty).Add(d.Carry)),
+		"carry":  "-",
+		"sl":     "N/A",
+		"hwm":    money.USD(d.HWM),
+		"weight": "-",
+	}
+	if !d.PrevClose.IsZero() {
+		c["day"] = plString(d.Current.Sub(d.PrevClose).Mul(d.Qty))
+	}
+	if !d.SL.IsZero() {
+		// (Current - SL) / Current * 100
+		pct := d.Current.Sub(d.SL).Div(d.Current).Mul(decimal.NewFromInt(100))
+		c["sl"] = fmt.Sprintf("%s (%s%%)", money.USD(d.SL), pct.StringFixed(1))
+	}
+	if !d.Carry.IsZero() {
+		c["carry"] = plString(d.Carry)
+	}
+	if weightBase.IsPositive() {
+		c["weight"] = d.Current.Mul(d.Qty).Div(weightBase).Mul(decimal.NewFromInt(100)).StringFixed(1) + "%"
+	}
+	return c
+}
+
+var statusColumnLabels = map[string]string{
+	"price":  "Price",
+	"day":    "DayP/L",
+	"total":  "TotP/L",
+	"sl":     "SL",
+	"hwm":    "HWM",
+	"weight": "Weight",
+	"carry":  "Carry",
+	"ladder": "Range",
+}
+
+// effectiveStop is the level that exits the position first: the fixed stop or the
+// trailing stop's trigger, whichever is higher.
+func (d statusDetail) effectiveStop() (label string, level decimal.Decimal) {
+	label, level = "SL", d.SL
+	if d.TrailPct.IsPositive() && d.HWM.IsPositive() {
+		trail := d.HWM.Mul(decimal.NewFromInt(100).Sub(d.TrailPct)).Div(decimal.NewFromInt(100))
+		if trail.GreaterThan(level) {
+			label, level = "TS", trail
+		}
+	}
+	return label, level
+}
+
+// ladder places the exit levels and the price within today's range, in price order:
+// L 181.20 ─ SL 182.00 ─ ● 185.30 ─ H 187.10 ─ TP 200.00
+// A stop inside the range is within an ordinary day's swing, so it is flagged.
+func (d statusDetail) ladder() string {
+	type rung struct {
+		label string
+		level decimal.Decimal
+	}
+	stopLabel, stop := d.effectiveStop()
+	var rungs []rung // Ties keep this order: low, stop, price, target, high
+	hasRange := d.DayLow.IsPositive() && d.DayHigh.IsPositive()
+	if hasRange {
+		rungs = append(rungs, rung{"L", d.DayLow})
+	}
+	if stop.IsPositive() {
+		rungs = append(rungs, rung{stopLabel, stop})
+	}
+	rungs = append(rungs, rung{"●", d.Current})
+	if d.TP.IsPositive() {
+		rungs = append(rungs, rung{"TP", d.TP})
+	}
+	if hasRange {
+		rungs = append(rungs, rung{"H", d.DayHigh})
+	}
+	sort.SliceStable(rungs, func(i, j int) bool { return rungs[i].level.LessThan(rungs[j].level) })
+
+	parts := make([]string, len(rungs))
+	for i, r := range rungs {
+		parts[i] = r.label + " " + strings.TrimPrefix(money.USD(r.level), "$")
+	}
+	s := strings.Join(parts, " ─ ")
+	if hasRange && stop.IsPositive() && !stop.LessThan(d.DayLow) && !stop.GreaterThan(d.DayHigh) {
+		s += fmt.Sprintf(" ⚠️ %s inside today's range", stopLabel)
+	}
+	return s
+}
+
+// renderStatusPositions formats the positions block of /status.
+// table:   monospaced rows (price/day/total) with a context line (sl/hwm/weight)
+// cards:   one small block per position, readable on narrow screens
+// minimal: one short line per position
+func renderStatusPositions(details []statusDetail, layout string, cols []string, equity decimal.Decimal) string {
+	var sb strings.Builder
+
+	tickerWidth := 6
+	for _, d := range details {
+		if len(d.Ticker) > tickerWidth {
+			tickerWidth = len(d.Ticker)
+		}
+	}
+
+	rowCols, ctxCols, cardCols := []string{}, []string{}, []string{}
+	showLadder := false
+	for _, c := range cols {
+		if c == "ladder" { // A line of its own
+			showLadder = true
+			continue
+		}
+		cardCols = append(cardCols, c)
+		switch c {
+		case "price", "day", "total":
+			rowCols = append(rowCols, c)
+		default:
+			ctxCols = append(ctxCols, c)
+		}
+	}
+
+	if layout == layoutTable {
+		header := fmt.Sprintf("%-*s", tickerWidth, "Ticker")
+		for _, c := range rowCols {
+			header += " | " + statusColumnLabels[c]
+		}
+		sb.WriteString(fmt.Sprintf("`%s`\n", header))
+		sb.WriteString(fmt.Sprintf("`%s`\n", strings.Repeat("-", len(header)+8)))
+	}
+
+	for _, d := range details {
+		if d.Current.IsZero() {
+			sb.WriteString(fmt.Sprintf("`%-*s | ERR`\n", tickerWidth, d.Ticker))
+			continue
+		}
+		cells := d.cells(equity)
+
+		switch layout {
+		case layoutCards:
+			sb.WriteString(fmt.Sprintf("*%s*\n", d.Ticker))
+			var parts []string
+			for _, c := range cardCols {
+				parts = append(parts, fmt.Sprintf("%s: %s", statusColumnLabels[c], cells[c]))
+			}
+			if len(parts) > 0 {
+				sb.WriteString("  " + strings.Join(parts, " | ") + "\n")
+			}
+			if showLadder {
+				sb.WriteString(fmt.Sprintf("  `%s`\n", d.ladder()))
+			}
+		case layoutMinimal:
+			sb.WriteString(fmt.Sprintf("• %s $%s %s\n", d.Ticker, cells["price"], cells["total"]))
+		default:
+			row := fmt.Sprintf("%-*s", tickerWidth, d.Ticker)
+			for _, c := range rowCols {
+				row += " | " + cells[c]
+			}
+			sb.WriteString(fmt.Sprintf("`%s`\n", row))
+
+			var ctx []string
+			for _, c := range ctxCols {
+				ctx = append(ctx, fmt.Sprintf("%s: %s", statusColumnLabels[c], cells[c]))
+			}
+			if len(ctx) > 0 {
+				sb.WriteString(fmt.Sprintf("      ↳ %s\n", strings.Join(ctx, " | ")))
+			}
+			if showLadder {
+				sb.WriteString(fmt.Sprintf("      `%s`\n", d.ladder()))
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,210 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// The weekly stop review: after the last session of the week, every position's stop is
+// shown against its weekly range, and stops that can be raised to just under the weekly
+// low are proposed together behind one APPLY ALL button.
+
+const (
+	stopReviewSessions = 5              // Daily bars that make up "the week"
+	stopReviewTTL      = 72 * time.Hour // The APPLY ALL button outlives the weekend
+)
+
+// stopChange is one proposed stop raise.
+type stopChange struct {
+	Ticker string
+	From   decimal.Decimal
+	To     decimal.Decimal
+}
+
+// pendingStopReview is a batch of stop raises awaiting APPLY ALL.
+type pendingStopReview struct {
+	id      string
+	at      time.Time
+	changes []stopChange
+}
+
+// maybeWeeklyStopReview sends the review once per ISO week, after the close of the
+// week's last session (the next open falls in another week).
+func (w *Watcher) maybeWeeklyStopReview(session string, nextOpen time.Time) {
+	if !w.config.StopReviewEnabled {
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", session, easternLoc())
+	if err != nil {
+		return
+	}
+	y, wk := day.ISOWeek()
+	if ny, nwk := nextOpen.In(easternLoc()).ISOWeek(); !nextOpen.IsZero() && ny == y && nwk == wk {
+		return
+	}
+	label := fmt.Sprintf("%d-W%02d", y, wk)
+	w.mu.Lock()
+	if w.state.LastStopReview >= label {
+		w.mu.Unlock()
+		return
+	}
+	w.state.LastStopReview = label
+	w.saveStateLocked()
+	w.mu.Unlock()
+
+	log.Printf("Weekly stop review for %s", label)
+	if msg := w.sendStopReview(); msg != "" {
+		w.notifyRoutine(msg)
+	}
+}
+
+// buildStopReview lists each active position's stop against its weekly range and the
+// raises to propose: the weekly low less STOP_REVIEW_BUFFER_PCT, when that is above the
+// current stop and below the price. Stops are never lowered.
+func (w *Watcher) buildStopReview() (string, []stopChange) {
+	w.mu.RLock()
+	var positions []models.Position
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			positions = append(positions, p)
+		}
+	}
+	w.mu.RUnlock()
+	if len(positions) == 0 {
+		return "", nil
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Ticker < positions[j].Ticker })
+
+	buffer := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(w.config.StopReviewBufferPct).Div(decimal.NewFromInt(100)))
+	var sb strings.Builder
+	var changes []stopChange
+	for _, p := range positions {
+		bars, err := w.provider.GetBars(w.ctx, p.Ticker, stopReviewSessions)
+		if err != nil || len(bars) == 0 {
+			sb.WriteString(fmt.Sprintf("\n• %s: SL $%s (no weekly bars)", p.Ticker, p.StopLoss.StringFixed(2)))
+			continue
+		}
+		low, high := bars[0].Low, bars[0].High
+		for _, b := range bars[1:] {
+			low, high = min(low, b.Low), max(high, b.High)
+		}
+		price := decimal.NewFromFloat(bars[len(bars)-1].Close)
+		wkLow, wkHigh := decimal.NewFromFloat(low), decimal.NewFromFloat(high)
+
+		line := fmt.Sprintf("\n• %s: SL $%s | week $%s–$%s | close $%s", p.Ticker, p.StopLoss.StringFixed(2), wkLow.StringFixed(2), wkHigh.StringFixed(2), price.StringFixed(2))
+		if wkLow.IsPositive() && p.StopLoss.IsPositive() {
+			line += fmt.Sprintf(" (SL %s%% under the low)", wkLow.Sub(p.StopLoss).Div(wkLow).Mul(decimal.NewFromInt(100)).StringFixed(1))
+		}
+		to := money.Price(p.Ticker, wkLow.Mul(buffer))
+		if to.GreaterThan(p.StopLoss) && to.LessThan(price) && (p.TakeProfit.IsZero() || to.LessThan(p.TakeProfit)) {
+			changes = append(changes, stopChange{Ticker: p.Ticker, From: p.StopLoss, To: to})
+			line += fmt.Sprintf("\n   ⬆️ Raise to $%s", to.StringFixed(2))
+		}
+		sb.WriteString(line)
+	}
+	return sb.String(), changes
+}
+
+// sendStopReview sends the review. With raises to propose it goes out with the
+// APPLY ALL / SKIP buttons and "" is returned; otherwise the report is returned.
+func (w *Watcher) sendStopReview() string {
+	body, changes := w.buildStopReview()
+	if body == "" {
+		return "🧱 No active positions to review."
+	}
+	head := fmt.Sprintf("🧱 *WEEKLY STOP REVIEW* (last %d sessions)", stopReviewSessions)
+	if len(changes) == 0 {
+		return head + body + "\n\n✅ No stop to raise this week."
+	}
+
+	id := fmt.Sprintf("%d", w.clock.Now().UnixNano())
+	w.mu.Lock()
+	w.pendingStops = &pendingStopReview{id: id, at: w.clock.Now(), changes: changes}
+	w.mu.Unlock()
+
+	telegram.SendInteractiveMessage(fmt.Sprintf("%s%s\n\nRaise %d stop(s) to %.1f%% under the weekly low? Take-profits are kept.",
+		head, body, len(changes), w.config.StopReviewBufferPct),
+		[]telegram.Button{
+			{Text: fmt.Sprintf("✅ APPLY ALL (%d)", len(changes)), CallbackData: "STOPS_APPLY_" + id},
+			{Text: "❌ SKIP", CallbackData: "STOPS_SKIP_" + id},
+		})
+	return ""
+}
+
+// handleStopsCallback applies (STOPS_APPLY_<id>) or skips the proposed raises. Each
+// raise is re-checked against the live position and price, as /update would.
+func (w *Watcher) handleStopsCallback(data string) string {
+	parts := strings.SplitN(data, "_", 3)
+	if len(parts) < 3 {
+		return "⚠️ Invalid stop review callback data."
+	}
+	w.mu.Lock()
+	pending := w.pendingStops
+	w.pendingStops = nil
+	w.mu.Unlock()
+
+	if pending == nil || pending.id != parts[2] || w.since(pending.at) > stopReviewTTL {
+		return "⚠️ Stop review expired. Send /stops for a fresh one."
+	}
+	if parts[1] != "APPLY" {
+		return "❌ Stop review skipped. No stop was changed."
+	}
+
+	var out []string
+	var applied []string
+	for _, c := range pending.changes {
+		price, err := w.provider.GetPrice(w.ctx, c.Ticker)
+		if err != nil || !c.To.LessThan(price) {
+			out = append(out, fmt.Sprintf("⚠️ %s: skipped, $%s is no longer below the price.", c.Ticker, c.To.StringFixed(2)))
+			continue
+		}
+		w.mu.Lock()
+		idx := -1
+		for i, p := range w.state.Positions {
+			if p.Ticker == c.Ticker && p.Status == "ACTIVE" {
+				idx = i
+				break
+			}
+		}
+		switch {
+		case idx < 0:
+			out = append(out, fmt.Sprintf("⚠️ %s: skipped, position closed.", c.Ticker))
+		case !c.To.GreaterThan(w.state.Positions[idx].StopLoss):
+			out = append(out, fmt.Sprintf("ℹ️ %s: skipped, stop already at $%s.", c.Ticker, w.state.Positions[idx].StopLoss.StringFixed(2)))
+		default:
+			w.state.Positions[idx].StopLoss = c.To
+			w.saveStateLocked()
+			applied = append(applied, c.Ticker)
+			out = append(out, fmt.Sprintf("✅ %s: SL $%s → $%s", c.Ticker, c.From.StringFixed(2), c.To.StringFixed(2)))
+		}
+		w.mu.Unlock()
+	}
+
+	// Broker-side exits follow the new stops, as after /update
+	for _, t := range applied {
+		w.mu.RLock()
+		hasExit := false
+		for _, p := range w.state.Positions {
+			if p.Ticker == t && p.Status == "ACTIVE" {
+				hasExit = brokerExitID(p) != ""
+			}
+		}
+		w.mu.RUnlock()
+		if hasExit {
+			go w.syncBrokerExit(t)
+		} else if w.config.BrokerProtectionEnabled {
+			go w.protectAtBroker(t)
+		}
+	}
+	log.Printf("Weekly stop review: %d of %d raises applied", len(applied), len(pending.changes))
+	return "🧱 *STOPS UPDATED*\n" + strings.Join(out, "\n")
+}
@@ -0,0 +1,272 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/market"
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
+	"alpha_trading/internal/telegram"
+
+	"github.com/shopspring/decimal"
+)
+
+// Idle cash above SWEEP_CASH_THRESHOLD for SWEEP_IDLE_DAYS is offered a sweep into
+// SWEEP_ETF (a money-market/T-bill ETF). The sweep is a cash equivalent, not a
+// position: it is kept out of the tracked positions, the budget and the risk checks,
+// and a confirmed buy short of buying power sells from it first.
+
+const sweepProposalEvery = 24 * time.Hour // Re-offer a declined sweep at most daily
+
+// isSweepTicker reports whether ticker is the configured cash-sweep ETF.
+func (w *Watcher) isSweepTicker(ticker string) bool {
+	return w.config.SweepETF != "" && ticker == w.config.SweepETF
+}
+
+// sweepHolding returns the broker's SWEEP_ETF quantity and market value (zero if none).
+func (w *Watcher) sweepHolding() (qty, value decimal.Decimal, err error) {
+	if w.config.SweepETF == "" {
+		return decimal.Zero, decimal.Zero, nil
+	}
+	positions, err := w.provider.ListPositions(w.ctx)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	for _, p := range positions {
+		if p.Symbol == w.config.SweepETF {
+			if p.MarketValue != nil {
+				value = *p.MarketValue
+			}
+			return p.Qty, value, nil
+		}
+	}
+	return decimal.Zero, decimal.Zero, nil
+}
+
+// idleCash returns the account's cash and how much of it is over SWEEP_CASH_THRESHOLD.
+func (w *Watcher) idleCash() (cash, excess decimal.Decimal, err error) {
+	acct, err := w.provider.GetAccount(w.ctx)
+	if err != nil {
+		return decimal.Zero, decimal.Zero, err
+	}
+	excess = acct.Cash.Sub(decimal.NewFromFloat(w.config.SweepCashThreshold))
+	return acct.Cash, decimal.Max(decimal.Zero, excess), nil
+}
+
+// checkCashSweep tracks how long cash has sat above SWEEP_CASH_THRESHOLD and, after
+// SWEEP_IDLE_DAYS, proposes sweeping the excess (at most once a day, market open).
+func (w *Watcher) checkCashSweep() {
+	if w.config.SweepETF == "" || !w.wasMarketOpen {
+		return
+	}
+	_, excess, err := w.idleCash()
+	if err != nil {
+		log.Printf("Cash Sweep: account unavailable: %v", err)
+		return
+	}
+
+	w.mu.Lock()
+	if !excess.IsPositive() {
+		if w.state.CashIdleSince != "" {
+			w.state.CashIdleSince = ""
+			w.saveStateLocked()
+		}
+		w.mu.Unlock()
+		return
+	}
+	if w.state.CashIdleSince == "" {
+		w.state.CashIdleSince = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
+		w.saveStateLocked()
+		w.mu.Unlock()
+		return
+	}
+	since, err := time.Parse(time.RFC3339, w.state.CashIdleSince)
+	if err != nil || w.since(since) < time.Duration(w.config.SweepIdleDays)*24*time.Hour {
+		w.mu.Unlock()
+		return
+	}
+	if last, ok := w.lastAlerts["CASH_SWEEP"]; ok && w.since(last) < sweepProposalEvery {
+		w.mu.Unlock()
+		return
+	}
+	w.lastAlerts["CASH_SWEEP"] = w.clock.Now()
+	w.mu.Unlock()
+
+	if msg := w.proposeSweep(excess, w.since(since)); msg != "" {
+		log.Printf("Cash Sweep: %s", msg)
+	}
+}
+
+// proposeSweep offers to buy $excess of SWEEP_ETF with the EXECUTE/CANCEL buttons.
+// It is never auto-confirmed. Returns a message only when no proposal was sent.
+func (w *Watcher) proposeSweep(excess decimal.Decimal, idle time.Duration) string {
+	ticker := w.config.SweepETF
+	excess = money.Cash(excess)
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil || !price.IsPositive() {
+		return fmt.Sprintf("⚠️ Could not fetch price for %s.", ticker)
+	}
+	confirm := w.confirmTier(excess)
+	if confirm == confirmAuto {
+		confirm = confirmButton
+	}
+
+	w.mu.Lock()
+	w.pendingProposals[ticker] = PendingProposal{
+		Ticker:    ticker,
+		Qty:       money.Qty(ticker, excess.Div(price), true),
+		Price:     price,
+		TotalCost: excess,
+		Notional:  excess,
+		Sweep:     true,
+		Confirm:   confirm,
+		Timestamp: w.clock.Now(),
+	}
+	w.mu.Unlock()
+	w.recordAudit(ticker, auditProposed, "SYSTEM", price, fmt.Sprintf("cash sweep $%s", excess.StringFixed(2)))
+
+	msg := fmt.Sprintf("💤 *CASH SWEEP*\nCash has been above $%s for %s.\nSweep $%s into %s (~$%s)?\n\n"+
+		"No stops are attached, it stays out of /status and the budget, and it is sold back automatically when a confirmed buy needs the buying power.\n\n⏱️ Valid for %d seconds.",
+		money.Cash(decimal.NewFromFloat(w.config.SweepCashThreshold)).StringFixed(2), idleDays(idle), excess.StringFixed(2), ticker, price.StringFixed(2),
+		w.config.ConfirmationTTLSec)
+	if confirm == confirmPIN {
+		msg += "\n🔐 Large order: after SWEEP, send /pin <PIN> to place it."
+	}
+	telegram.SendInteractiveMessage(msg, []telegram.Button{
+		{Text: "💤 SWEEP", CallbackData: fmt.Sprintf("EXECUTE_BUY_%s", ticker)},
+		{Text: "❌ KEEP CASH", CallbackData: fmt.Sprintf("CANCEL_BUY_%s", ticker)},
+	})
+	return ""
+}
+
+// idleDays formats an idle duration as "3 days" (or hours under a day).
+func idleDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
+
+// executeSweep buys the confirmed sweep as a notional market order. No position is opened.
+func (w *Watcher) executeSweep(proposal PendingProposal) string {
+	ticker := proposal.Ticker
+	order, err := w.placeOrder(models.OrderIntent{Ticker: ticker, Qty: proposal.Qty, Side: "buy", Source: "SWEEP"}, market.OrderOptions{Notional: proposal.Notional})
+	if err != nil {
+		msg := fmt.Sprintf("❌ Sweep Failed: %v", err)
+		log.Printf("[FATAL_TRADE_ERROR] %s", msg)
+		return msg
+	}
+	verified, err := w.verifyOrderExecution(order.ID)
+	if err != nil {
+		return fmt.Sprintf("🚨 Sweep Verification Failed: %v", err)
+	}
+	status := strings.ToLower(verified.Status)
+	if status == "canceled" || status == "rejected" {
+		return fmt.Sprintf("❌ Sweep Failed: Order Status '%s'.", status)
+	}
+
+	w.mu.Lock()
+	w.state.CashIdleSince = ""
+	w.saveStateLocked()
+	w.mu.Unlock()
+	fill := proposal.Price
+	if verified.FilledAvgPrice != nil {
+		fill = *verified.FilledAvgPrice
+	}
+	w.recordAudit(ticker, auditFilled, "USER", fill, fmt.Sprintf("cash sweep $%s", proposal.Notional.StringFixed(2)))
+	return fmt.Sprintf("💤 Swept $%s into %s (Status: %s).", proposal.Notional.StringFixed(2), ticker, status)
+}
+
+// redeemSweepFor sells enough SWEEP_ETF to cover cost when buying power falls short,
+// then waits for the proceeds (SETTLEMENT_WAIT_SEC). The returned lines go into the
+// execution report; nil means nothing was sold.
+func (w *Watcher) redeemSweepFor(cost decimal.Decimal) []string {
+	if w.config.SweepETF == "" || !cost.IsPositive() {
+		return nil
+	}
+	bp, err := w.getBuyingPowerBreakdown()
+	if err != nil || !cost.GreaterThan(bp.Total) {
+		return nil
+	}
+	held, value, err := w.sweepHolding()
+	if err != nil || !held.IsPositive() || !value.IsPositive() {
+		return nil
+	}
+
+	ticker := w.config.SweepETF
+	price := value.Div(held)
+	// 1% headroom for the sale price drifting below the last mark
+	qty := money.Qty(ticker, cost.Sub(bp.Total).Mul(decimal.NewFromFloat(1.01)).Div(price), true)
+	if qty.GreaterThanOrEqual(held) || held.Sub(qty).Mul(price).LessThan(decimal.NewFromInt(1)) {
+		qty = held // Do not leave dust behind
+	}
+	if !qty.IsPositive() {
+		return nil
+	}
+
+	order, err := w.placeOrder(models.OrderIntent{Ticker: ticker, Qty: qty, Side: "sell", Source: "SWEEP"})
+	if err != nil {
+		log.Printf("Cash Sweep: redemption failed: %v", err)
+		return []string{fmt.Sprintf("⚠️ Could not sell %s from the cash sweep: %v", ticker, err)}
+	}
+	if _, err := w.verifyOrderExecution(order.ID); err != nil {
+		return []string{fmt.Sprintf("⚠️ Cash sweep sale of %s unverified: %v", ticker, err)}
+	}
+	w.recordAudit(ticker, auditFilled, "SYSTEM", price, fmt.Sprintf("cash sweep redemption x%s", qty.String()))
+	notes := []string{fmt.Sprintf("💤 Sold %s %s (~$%s) from the cash sweep to fund this buy.", qty.String(), ticker, qty.Mul(price).StringFixed(2))}
+	if after, waited := w.waitForSettledFunds(cost); waited >= settlementPollInterval {
+		notes = append(notes, fmt.Sprintf("⏳ Waited %s for settlement (Non-margin: $%s | Margin: $%s).",
+			waited.Round(time.Second), after.NonMargin.StringFixed(2), after.Margin.StringFixed(2)))
+	}
+	return notes
+}
+
+// handleSweepCommand shows the cash sweep. /sweep [now]
+func (w *Watcher) handleSweepCommand(parts []string) string {
+	if w.config.SweepETF == "" {
+		return "💤 Cash sweep is off. Set SWEEP_ETF (e.g., SGOV) to enable it."
+	}
+	cash, excess, err := w.idleCash()
+	if err != nil {
+		return fmt.Sprintf("⚠️ Could not read the account: %v", err)
+	}
+	w.mu.RLock()
+	idleSince := w.state.CashIdleSince
+	w.mu.RUnlock()
+
+	if len(parts) >= 2 {
+		if !strings.EqualFold(parts[1], "now") {
+			return "Usage: /sweep [now]"
+		}
+		if !excess.IsPositive() {
+			return fmt.Sprintf("💤 Cash ($%s) is within the $%.2f threshold: nothing to sweep.", cash.StringFixed(2), w.config.SweepCashThreshold)
+		}
+		var idle time.Duration
+		if t, err := time.Parse(time.RFC3339, idleSince); err == nil {
+			idle = w.since(t)
+		}
+		return w.proposeSweep(excess, idle)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("💤 *CASH SWEEP* (%s)\n", w.config.SweepETF))
+	sb.WriteString(fmt.Sprintf("Cash: $%s | Threshold: $%.2f | Excess: $%s\n", cash.StringFixed(2), w.config.SweepCashThreshold, excess.StringFixed(2)))
+	if t, err := time.Parse(time.RFC3339, idleSince); err == nil {
+		sb.WriteString(fmt.Sprintf("Idle for %s (proposed after %d days)\n", idleDays(w.since(t)), w.config.SweepIdleDays))
+	}
+	if qty, value, err := w.sweepHolding(); err != nil {
+		sb.WriteString(fmt.Sprintf("Holding: unavailable (%v)", err))
+	} else if qty.IsPositive() {
+		sb.WriteString(fmt.Sprintf("Holding: %s %s ($%s), sold back as buys need it", qty.String(), w.config.SweepETF, value.StringFixed(2)))
+	} else {
+		sb.WriteString("Holding: none")
+	}
+	if excess.IsPositive() {
+		sb.WriteString("\n\nSweep the excess now: `/sweep now`")
+	}
+	return sb.String()
+}
@@ -6,14 +6,16 @@ import (
 	"strings"
 	"time"
 
+	"alpha_trading/internal/budget"
+	"alpha_trading/internal/market"
 	"alpha_trading/internal/models"
+	"alpha_trading/internal/money"
 	"alpha_trading/internal/storage"
+	"alpha_trading/internal/telegram"
 
 	"github.com/shopspring/decimal"
 )
 
-// saveStateAsync saves without blocking, or just call storage?
-// For simplicity and safety, we just call storage.SaveState since it's fast enough on low volume.
 // saveState persists the current state to disk with updated metrics.
 // It acquires the lock internally.
 func (w *Watcher) saveState() {
@@ -26,24 +28,47 @@ func (w *Watcher) saveState() {
 // It assumes w.mu is ALREADY LOCKED by the caller.
 func (w *Watcher) saveStateLocked() {
 	// Spec 65: Update Budget Metrics before save
-	// Calculate Exposure
-	currentExposure := decimal.Zero
-	for _, p := range w.state.Positions {
-		if p.Status == "ACTIVE" {
-			cost := p.Quantity.Mul(p.EntryPrice)
-			currentExposure = currentExposure.Add(cost)
-		}
+	w.budgetLocked().Apply(&w.state)
+
+	// Spec 52: HWM monotonicity (always logged; HWM_STRICT_MODE also blocks it)
+	if regs := w.writer.Audit(w.state); len(regs) > 0 && w.config.HWMStrictMode {
+		w.restoreHWMLocked(regs)
 	}
 
-	w.state.CurrentExposure = currentExposure
-	w.state.FiscalLimit = decimal.NewFromFloat(w.config.FiscalBudgetLimit)
-	w.state.AvailableBudget = w.state.FiscalLimit.Sub(currentExposure)
+	w.recordStateEventsLocked()
+	w.writer.Save(w.state) // Debounced; the file catches up within STATE_SAVE_DEBOUNCE_MS
+}
+
+// budgetLocked returns the budget metrics for the current positions, capped by the
+// equity seen at the last JIT sync. Caller holds w.mu.
+func (w *Watcher) budgetLocked() budget.Metrics {
+	return budget.Compute(w.state.Positions, decimal.NewFromFloat(w.config.FiscalBudgetLimit), w.lastEquity)
+}
+
+// restoreHWMLocked puts back the last saved HWM of every regressed position and
+// alerts with the call site. Caller holds w.mu.
+func (w *Watcher) restoreHWMLocked(regs []storage.HWMRegression) {
+	var sb strings.Builder
+	sb.WriteString("🛑 *HWM REGRESSION BLOCKED*\n")
+	for _, r := range regs {
+		for i := range w.state.Positions {
+			if w.state.Positions[i].Ticker == r.Ticker {
+				w.state.Positions[i].HighWaterMark = r.Old
+			}
+		}
+		sb.WriteString(fmt.Sprintf("• %s: $%s → $%s refused, kept $%s\n  at %s\n", r.Ticker, r.Old.StringFixed(2), r.New.StringFixed(2), r.Old.StringFixed(2), r.CallSite))
+	}
+	log.Printf("HWM strict mode: restored %d regressed high water mark(s).", len(regs))
+	go telegram.Notify(sb.String()) // Don't block the caller's lock on the network
+}
 
-	storage.SaveState(w.state)
+// FlushState writes any pending save now (shutdown, or before reading the state file).
+func (w *Watcher) FlushState() {
+	w.writer.Flush()
 }
 
 func (w *Watcher) searchAssets(query string) string {
-	assets, err := w.provider.SearchAssets(query)
+	assets, err := w.provider.SearchAssets(w.ctx, query)
 	if err != nil {
 		log.Printf("Error searching assets: %v", err)
 		return "⚠️ Error: Could not search assets."
@@ -61,15 +86,43 @@ func (w *Watcher) searchAssets(query string) string {
 	return sb.String()
 }
 
+// validateSymbol confirms a ticker is a known, tradable asset before any order path uses it.
+// On failure it returns a user-facing message with search suggestions.
+func (w *Watcher) validateSymbol(ticker string) (string, bool) {
+	asset, err := w.provider.GetAsset(w.ctx, ticker)
+	if err == nil && asset != nil && asset.Tradable {
+		return "", true
+	}
+
+	reason := "not found"
+	if err == nil && asset != nil && !asset.Tradable {
+		reason = "not tradable"
+	}
+	log.Printf("Symbol validation failed for %s (%s, err: %v)", ticker, reason, err)
+	return fmt.Sprintf("⚠️ Symbol '%s' is %s. Did you mean:\n\n%s", ticker, reason, w.searchAssets(ticker)), false
+}
+
 func (w *Watcher) getPrice(ticker string) string {
-	price, err := w.provider.GetPrice(ticker)
+	snap, err := w.getSnapshot(ticker)
+	price := snap.Last
 
 	if err != nil || price.IsZero() {
 		log.Printf("Price lookup failed for %s (err: %v, price: %v). Falling back to search.", ticker, err, price)
 		searchResult := w.searchAssets(ticker)
 		return fmt.Sprintf("⚠️ Price not found for '%s'. Did you mean:\n\n%s", ticker, searchResult)
 	}
-	return fmt.Sprintf("💲 *%s*: $%s", ticker, price.StringFixed(2))
+
+	msg := fmt.Sprintf("💲 *%s*: $%s", ticker, price.StringFixed(2))
+	if !snap.PrevClose.IsZero() {
+		msg += fmt.Sprintf("\nDay: %s%% (Prev Close $%s)", snap.DayChangePct().StringFixed(2), snap.PrevClose.StringFixed(2))
+	}
+	if !snap.GapPct().IsZero() {
+		msg += fmt.Sprintf("\nGap: %s%% (Open $%s)", snap.GapPct().StringFixed(2), snap.Open.StringFixed(2))
+	}
+	if snap.Bid.IsPositive() && snap.Ask.IsPositive() {
+		msg += fmt.Sprintf("\nBid/Ask: $%s / $%s", snap.Bid.StringFixed(2), snap.Ask.StringFixed(2))
+	}
+	return msg
 }
 
 // SyncWithBroker implements Spec 68: Just-In-Time Broker Reconciliation.
@@ -78,12 +131,12 @@ func (w *Watcher) getPrice(ticker string) string {
 // Returns the updated portfolio state.
 func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 	// 1. Fetch Data in Parallel (could use goroutines, but sequential is safer/easier for now)
-	account, err := w.provider.GetAccount()
+	account, err := w.provider.GetAccount(w.ctx)
 	if err != nil {
 		return w.state, fmt.Errorf("JIT Sync: Failed to get account: %v", err)
 	}
 
-	positions, err := w.provider.ListPositions()
+	positions, err := w.provider.ListPositions(w.ctx)
 	if err != nil {
 		return w.state, fmt.Errorf("JIT Sync: Failed to list positions: %v", err)
 	}
@@ -104,10 +157,12 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 	}
 
 	newPositions := []models.Position{}
-	var currentExposure decimal.Decimal // For Spec 69
 
 	for _, p := range positions {
 		ticker := p.Symbol
+		if w.isSweepTicker(ticker) {
+			continue // Cash equivalent (SWEEP_ETF), not a tracked position
+		}
 		qty := p.Qty
 		avgEntry := p.AvgEntryPrice
 
@@ -116,11 +171,6 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 			currentPrice = *p.CurrentPrice
 		}
 
-		// Calculate Cost for Exposure (Spec 69)
-		// Exposure = Qty * EntryPrice (Cost Basis)
-		cost := qty.Mul(avgEntry)
-		currentExposure = currentExposure.Add(cost)
-
 		// HWM Logic
 		hwm := avgEntry
 		if currentPrice.GreaterThan(hwm) {
@@ -131,7 +181,8 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 		sl := decimal.Zero
 		tp := decimal.Zero
 		tsPct := decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
-		thesisID := fmt.Sprintf("IMPORTED_%d", time.Now().Unix())
+		thesisID := fmt.Sprintf("IMPORTED_%d", w.clock.Now().Unix())
+		book := ""
 		var openedAt time.Time // Default zero
 
 		// Check local state for overrides
@@ -140,6 +191,7 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 			tp = oldP.TakeProfit
 			tsPct = oldP.TrailingStopPct
 			thesisID = oldP.ThesisID
+			book = oldP.Book
 
 			// Spec 66: Stagnation Timer - Persist OpenedAt
 			if !oldP.OpenedAt.IsZero() {
@@ -150,20 +202,25 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 			if oldP.HighWaterMark.GreaterThan(hwm) {
 				hwm = oldP.HighWaterMark
 			}
+		} else if po, ok := w.pendingOrderLocked(ticker); ok {
+			// A tracked limit/extended-hours buy filled: keep the levels the user confirmed
+			sl, tp, tsPct, thesisID, book = po.StopLoss, po.TakeProfit, po.TrailingStopPct, po.ThesisID, po.Book
+			openedAt = w.clock.Now()
+			log.Printf("ℹ️ Position discovered from pending order %s: %s", po.OrderID, ticker)
 		} else {
 			// New Position Discovery
-			openedAt = time.Now()
+			openedAt = w.clock.Now()
 			log.Printf("ℹ️ Position discovered: %s", ticker)
 		}
 
 		// Ensure defaults if missing or zero (Spec 42)
 		if sl.IsZero() {
 			slMult := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(w.config.DefaultStopLossPct).Div(decimal.NewFromInt(100)))
-			sl = avgEntry.Mul(slMult)
+			sl = money.Price(ticker, avgEntry.Mul(slMult))
 		}
 		if tp.IsZero() {
 			tpMult := decimal.NewFromInt(1).Add(decimal.NewFromFloat(w.config.DefaultTakeProfitPct).Div(decimal.NewFromInt(100)))
-			tp = avgEntry.Mul(tpMult)
+			tp = money.Price(ticker, avgEntry.Mul(tpMult))
 		}
 		if tsPct.IsZero() {
 			tsPct = decimal.NewFromFloat(w.config.DefaultTrailingStopPct)
@@ -180,69 +237,44 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 			TrailingStopPct: tsPct,
 			ThesisID:        thesisID,
 			OpenedAt:        openedAt,
+			BrokerOCOID:     existsMap[ticker].BrokerOCOID,
+			BrokerTrailID:   existsMap[ticker].BrokerTrailID,
+			Book:            book,
+			Priority:        existsMap[ticker].Priority,
 		}
 
 		newPositions = append(newPositions, newPos)
 	}
 
-	w.state.Positions = newPositions
-
-	// 3. Dynamic Budget Calculation (Spec 69 & 77)
-	// Spec 77: The Formula:
-	// Real_Cap = min(Alpaca_Equity, fiscal_limit)
-	// AvailableBudget = Real_Cap - CurrentTotalExposure
-
-	fiscalLimit := decimal.NewFromFloat(w.config.FiscalBudgetLimit)
-
-	// We need Equity for Spec 77. We fetched 'account' at the start.
-	// We didn't pass equity to this function, but we have 'account'.
-	// account.Equity is numeric.
-	equity := account.Equity
-
-	// Real Cap
-	realCap := fiscalLimit
-	if equity.LessThan(realCap) {
-		realCap = equity
+	// Local records the broker no longer holds are archived (exit fill unknown) rather than silently dropped
+	onBroker := make(map[string]bool)
+	for _, p := range newPositions {
+		onBroker[p.Ticker] = true
 	}
-
-	// Available
-	available := realCap.Sub(currentExposure)
-
-	// Clamp to 0 if negative
-	if available.IsNegative() {
-		available = decimal.Zero
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" && onBroker[p.Ticker] {
+			continue
+		}
+		reason := "RECONCILED"
+		if p.Status != "ACTIVE" {
+			reason = p.Status // Legacy EXECUTED records
+		}
+		w.archiveTrade(p, decimal.Zero, reason)
 	}
 
-	// Note: We ignore Buying Power here as the 'Strategic' limit,
-	// assuming RealCap is the stricter constraint for the AI.
-	// However, we must physically check BP before trade execution separately.
-	// For AI planning, we use this "Ghost Money" fixed budget.
+	w.state.Positions = newPositions
 
-	w.state.CurrentExposure = currentExposure
-	w.state.FiscalLimit = fiscalLimit
-	w.state.AvailableBudget = available
+	// 3. Dynamic Budget Calculation (Spec 69 & 77): Real_Cap = min(Equity, fiscal_limit).
+	// Buying power is checked separately at execution; this is the planning budget.
+	w.lastEquity = account.Equity
+	w.budgetLocked().Apply(&w.state)
 
 	// Spec 72: Watchlist Price Grounding (Env & State)
 	// Refresh Logic: Fetch LatestTrade for all tickers in WATCHLIST_TICKERS and update the local state.
 	// We do this AFTER reconciling positions, but before saving.
-	if len(w.config.WatchlistTickers) > 0 {
-		if w.state.WatchlistPrices == nil {
-			w.state.WatchlistPrices = make(map[string]float64)
-		}
-		for _, ticker := range w.config.WatchlistTickers {
-			ticker = strings.ToUpper(strings.TrimSpace(ticker))
-			if ticker == "" {
-				continue
-			}
-			// Use GetPrice (returns decimal) -> float64
-			priceDec, err := w.provider.GetPrice(ticker)
-			if err != nil {
-				log.Printf("Watchlist Warning: Could not fetch price for %s: %v", ticker, err)
-				continue
-			}
-			f, _ := priceDec.Float64()
-			w.state.WatchlistPrices[ticker] = f
-		}
+	// Runtime entries (/watch, /scan) are merged with the static env list.
+	if tickers := w.watchlistTickers(); len(tickers) > 0 {
+		w.groundWatchlistPricesLocked(tickers)
 	}
 
 	// LastSync updated in SaveState
@@ -251,6 +283,42 @@ func (w *Watcher) SyncWithBroker() (models.PortfolioState, error) {
 	return w.state, nil
 }
 
+// groundWatchlistPricesLocked refreshes WatchlistPrices (Spec 72). Last-known prices
+// younger than WATCHLIST_PRICE_MAX_AGE_SEC come from the provider chain's
+// LastPriceSource at no API cost; the rest fall back to REST. Every price keeps its
+// observation time. Caller holds w.mu.
+func (w *Watcher) groundWatchlistPricesLocked(tickers []string) {
+	if w.state.WatchlistPrices == nil {
+		w.state.WatchlistPrices = make(map[string]decimal.Decimal)
+	}
+	if w.state.WatchlistPricesAt == nil {
+		w.state.WatchlistPricesAt = make(map[string]time.Time)
+	}
+	maxAge := time.Duration(w.config.WatchlistPriceMaxAgeSec) * time.Second
+	src := market.FindLastPriceSource(w.provider)
+
+	reused, fetched := 0, 0
+	for _, ticker := range tickers {
+		if src != nil {
+			if price, at, ok := src.LastPrice(ticker); ok && price.IsPositive() && w.since(at) <= maxAge {
+				w.state.WatchlistPrices[ticker] = price
+				w.state.WatchlistPricesAt[ticker] = at
+				reused++
+				continue
+			}
+		}
+		price, err := w.provider.GetPrice(w.ctx, ticker)
+		if err != nil {
+			log.Printf("Watchlist Warning: Could not fetch price for %s: %v", ticker, err)
+			continue
+		}
+		w.state.WatchlistPrices[ticker] = price
+		w.state.WatchlistPricesAt[ticker] = w.clock.Now()
+		fetched++
+	}
+	log.Printf("Watchlist grounding: %d last-known, %d fetched", reused, fetched)
+}
+
 // syncState passes through to SyncWithBroker now to unify logic.
 // Returns count, discovered (empty if sync works generally), error.
 func (w *Watcher) syncState() (int, []string, error) {
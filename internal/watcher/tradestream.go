@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"alpha_trading/internal/clock"
+
+	"github.com/alpacahq/alpaca-trade-api-go/v3/alpaca"
+)
+
+const (
+	orderVerifyTimeout = 5 * time.Second  // Same budget the polling loop had
+	orderEventKeep     = 10 * time.Minute // Terminal events kept for late waiters
+)
+
+// orderEvents holds the terminal order states pushed by the trade-updates stream, so
+// verifyOrderExecution can wait for an event instead of polling GetOrder.
+type orderEvents struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	up      bool // Stream connected; while false, callers poll
+	orders  map[string]orderEvent
+	waiters map[string][]chan alpaca.Order
+}
+
+type orderEvent struct {
+	order alpaca.Order
+	at    time.Time
+}
+
+func newOrderEvents(clk clock.Clock) *orderEvents {
+	return &orderEvents{
+		clock:   clk,
+		orders:  make(map[string]orderEvent),
+		waiters: make(map[string][]chan alpaca.Order),
+	}
+}
+
+func (e *orderEvents) setUp(up bool) {
+	e.mu.Lock()
+	e.up = up
+	e.mu.Unlock()
+}
+
+// terminal reports whether an order status is final.
+func terminal(status string) bool {
+	switch strings.ToLower(status) {
+	case "filled", "canceled", "rejected", "expired":
+		return true
+	}
+	return false
+}
+
+// publish records a terminal order and wakes its waiters.
+func (e *orderEvents) publish(o alpaca.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.clock.Now()
+	for id, ev := range e.orders {
+		if now.Sub(ev.at) > orderEventKeep {
+			delete(e.orders, id)
+		}
+	}
+	e.orders[o.ID] = orderEvent{order: o, at: now}
+	for _, ch := range e.waiters[o.ID] {
+		ch <- o // Buffered, one send per waiter
+	}
+	delete(e.waiters, o.ID)
+}
+
+// wait blocks until order id reaches a terminal state or timeout passes. streamed=false
+// means the stream is down and the caller must poll; a nil order with streamed=true
+// means no terminal event arrived in time.
+func (e *orderEvents) wait(id string, timeout time.Duration) (order *alpaca.Order, streamed bool) {
+	e.mu.Lock()
+	if !e.up {
+		e.mu.Unlock()
+		return nil, false
+	}
+	if ev, ok := e.orders[id]; ok {
+		e.mu.Unlock()
+		o := ev.order
+		return &o, true
+	}
+	ch := make(chan alpaca.Order, 1)
+	e.waiters[id] = append(e.waiters[id], ch)
+	e.mu.Unlock()
+
+	select {
+	case o := <-ch:
+		return &o, true
+	case <-e.clock.After(timeout):
+		e.mu.Lock()
+		waiters := e.waiters[id]
+		for i, c := range waiters {
+			if c == ch {
+				e.waiters[id] = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(e.waiters[id]) == 0 {
+			delete(e.waiters, id)
+		}
+		e.mu.Unlock()
+		return nil, true
+	}
+}
+
+// StartTradeStream subscribes to the Alpaca trade-updates stream (TRADE_STREAM_ENABLED)
+// until ctx is done. Without it, order confirmation polls the broker.
+func (w *Watcher) StartTradeStream(ctx context.Context) {
+	if !w.config.TradeStreamEnabled {
+		return
+	}
+	alp := w.alpacaProvider()
+	if alp == nil {
+		return
+	}
+	log.Printf("Trade stream: subscribing to order updates.")
+	go alp.StreamTradeUpdates(ctx, w.onTradeUpdate, w.orders.setUp)
+}
+
+// onTradeUpdate handles one order event from the stream.
+func (w *Watcher) onTradeUpdate(tu alpaca.TradeUpdate) {
+	o := tu.Order
+	switch tu.Event {
+	case "fill", "partial_fill", "canceled", "rejected", "expired":
+		log.Printf("[TRADE_UPDATE] %s %s %s %s (order %s, filled %s)", tu.Event, o.Side, o.Symbol, o.Status, o.ID, o.FilledQty.String())
+	}
+	if terminal(o.Status) {
+		w.orders.publish(o)
+	}
+}
@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// defaultTriggerPrecedence is the Spec 36 order: TP > SL > TS.
+var defaultTriggerPrecedence = []string{"TP", "SL", "TS"}
+
+var triggerActionNames = map[string]string{
+	"TP": "TAKE PROFIT",
+	"SL": "STOP LOSS",
+	"TS": "TRAILING STOP",
+}
+
+// triggerPrecedence returns TRIGGER_PRECEDENCE validated against the known trigger types.
+// Unknown or duplicate entries fall back to the default order; omitted types are appended
+// in default order so every trigger still has a rank.
+func (w *Watcher) triggerPrecedence() []string {
+	seen := make(map[string]bool)
+	var order []string
+	for _, t := range w.config.TriggerPrecedence {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if _, ok := triggerActionNames[t]; !ok || seen[t] {
+			log.Printf("Warning: Invalid TRIGGER_PRECEDENCE %v. Using default %v.", w.config.TriggerPrecedence, defaultTriggerPrecedence)
+			return defaultTriggerPrecedence
+		}
+		seen[t] = true
+		order = append(order, t)
+	}
+	for _, t := range defaultTriggerPrecedence {
+		if !seen[t] {
+			order = append(order, t)
+		}
+	}
+	return order
+}
+
+// breachBps returns how far price is beyond level, in basis points of level.
+// For stops (below=true) the breach is level - price; for targets it is price - level.
+func breachBps(level, price decimal.Decimal, below bool) decimal.Decimal {
+	if level.IsZero() {
+		return decimal.Zero
+	}
+	diff := price.Sub(level)
+	if below {
+		diff = level.Sub(price)
+	}
+	return diff.Div(level).Mul(decimal.NewFromInt(10000))
+}
+
+// confirmTrigger applies the hysteresis band to a raw trigger touch. A touch is confirmed when
+// it breaches by at least TRIGGER_HYSTERESIS_BPS, or persists for TRIGGER_CONFIRM_CHECKS
+// consecutive checks. With neither configured, any touch confirms (legacy behavior).
+// Caller must hold w.mu.
+func (w *Watcher) confirmTrigger(ticker, trigger string, touched bool, depthBps decimal.Decimal) bool {
+	key := fmt.Sprintf("%s_%s", ticker, trigger)
+	if !touched {
+		delete(w.triggerStreaks, key)
+		delete(w.triggerFirstSeen, key)
+		return false
+	}
+	w.triggerStreaks[key]++
+	streak := w.triggerStreaks[key]
+	if streak == 1 {
+		w.triggerFirstSeen[key] = w.clock.Now()
+	}
+
+	bandBps := w.config.TriggerHysteresisBps
+	checks := w.config.TriggerConfirmChecks
+	deepEnough := bandBps <= 0 || depthBps.GreaterThanOrEqual(decimal.NewFromFloat(bandBps))
+
+	var confirmed bool
+	switch {
+	case checks <= 1:
+		confirmed = deepEnough
+	case bandBps <= 0:
+		confirmed = streak >= checks
+	default:
+		confirmed = deepEnough || streak >= checks
+	}
+
+	if !confirmed {
+		log.Printf("[%s] %s touched but not confirmed (Breach: %s bps, Checks: %d/%d)", ticker, trigger, depthBps.StringFixed(1), streak, checks)
+	}
+	return confirmed
+}
+
+// triggerDetectedAt returns when the current breach of a trigger was first seen
+// (now if unknown). Caller holds w.mu.
+func (w *Watcher) triggerDetectedAt(ticker, trigger string) time.Time {
+	if t, ok := w.triggerFirstSeen[fmt.Sprintf("%s_%s", ticker, trigger)]; ok {
+		return t
+	}
+	return w.clock.Now()
+}
+
+// triggerPrices returns the prices used to evaluate stops (SL/TS) and targets (TP/HWM).
+// TRIGGER_PRICE_SOURCE:
+//   - last: latest trade for both (default)
+//   - mid:  bid/ask midpoint for both, ignoring off-market prints
+//   - bid:  bid for stops (the price a sell would actually get), mid for targets
+//
+// bid/ask come from a batched snapshot when available; zero fetches a fresh quote.
+// Falls back to the last trade when the quote is missing, one-sided or crossed.
+func (w *Watcher) triggerPrices(ticker string, last, bid, ask decimal.Decimal) (stop, target decimal.Decimal) {
+	source := w.config.TriggerPriceSource
+	if source != "mid" && source != "bid" {
+		return last, last
+	}
+
+	if !bid.IsPositive() || !ask.IsPositive() {
+		quote, err := w.provider.GetQuote(w.ctx, ticker)
+		if err != nil || quote == nil {
+			log.Printf("[%s] No usable quote for %s trigger pricing (Err: %v). Using last trade.", ticker, source, err)
+			return last, last
+		}
+		bid, ask = decimal.NewFromFloat(quote.BidPrice), decimal.NewFromFloat(quote.AskPrice)
+	}
+	if !bid.IsPositive() || !ask.IsPositive() || bid.GreaterThan(ask) {
+		log.Printf("[%s] No usable quote for %s trigger pricing (bid %s, ask %s). Using last trade.", ticker, source, bid, ask)
+		return last, last
+	}
+
+	mid := bid.Add(ask).Div(decimal.NewFromInt(2))
+	if source == "bid" {
+		return bid, mid
+	}
+	return mid, mid
+}
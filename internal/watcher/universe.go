@@ -0,0 +1,157 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"alpha_trading/internal/ai"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	universeMaxSymbols = 25 // Keeps the prompt small
+	universeBars       = 21 // 20 daily returns
+)
+
+// resolveAnalyzeScope interprets the /analyze argument:
+// "watchlist", "holdings", "sector:<name>" (sector/industry match), or a bare sector name.
+// Bare words must name a sector exactly so short tickers are not mistaken for substrings;
+// anything else is a single ticker focus (the original behavior).
+// Returns the universe name and its tickers; an empty name means a ticker focus.
+func (w *Watcher) resolveAnalyzeScope(arg string) (string, []string) {
+	key := strings.ToLower(strings.TrimSpace(arg))
+	switch {
+	case key == "watchlist":
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		return "watchlist", w.watchlistTickers()
+	case key == "holdings":
+		w.mu.RLock()
+		defer w.mu.RUnlock()
+		var held []string
+		for _, p := range w.state.Positions {
+			if p.Status == "ACTIVE" && !contains(held, p.Ticker) {
+				held = append(held, p.Ticker)
+			}
+		}
+		return "holdings", held
+	case strings.HasPrefix(key, "sector:"):
+		name := strings.TrimPrefix(key, "sector:")
+		return "sector:" + name, w.scanUniverse(name)
+	}
+	if _, legacy := sectors[key]; legacy || strings.Contains(key, " ") || containsFold(w.metadata.Sectors(), key) {
+		if tickers := w.scanUniverse(key); len(tickers) > 0 {
+			return "sector:" + key, tickers
+		}
+	}
+	return "", nil
+}
+
+// buildUniverseData gathers the per-symbol data set for a focused review.
+func (w *Watcher) buildUniverseData(tickers []string) []ai.UniverseSymbol {
+	if len(tickers) > universeMaxSymbols {
+		log.Printf("AI universe truncated from %d to %d symbols", len(tickers), universeMaxSymbols)
+		tickers = tickers[:universeMaxSymbols]
+	}
+
+	w.mu.RLock()
+	held := map[string][2]decimal.Decimal{} // qty, entry
+	for _, p := range w.state.Positions {
+		if p.Status == "ACTIVE" {
+			held[p.Ticker] = [2]decimal.Decimal{p.Quantity, p.EntryPrice}
+		}
+	}
+	w.mu.RUnlock()
+
+	var out []ai.UniverseSymbol
+	for _, t := range tickers {
+		snap, err := w.getSnapshot(t)
+		if err != nil || snap.Last.IsZero() {
+			log.Printf("AI universe: skipping %s (no price: %v)", t, err)
+			continue
+		}
+		u := ai.UniverseSymbol{
+			Ticker:       t,
+			Sector:       w.metadata.Sector(t),
+			Price:        snap.Last,
+			DayChangePct: snap.DayChangePct().Round(2),
+		}
+
+		if bars, err := w.provider.GetBars(w.ctx, t, universeBars); err == nil && len(bars) >= 2 {
+			closes := make([]float64, len(bars))
+			volume := 0.0
+			for i, b := range bars {
+				closes[i] = b.Close
+				volume += float64(b.Volume)
+			}
+			last := snap.Last.InexactFloat64()
+			u.Return5DPct = pctChange(closes, 5, last)
+			u.Return20DPct = pctChange(closes, 20, last)
+			u.Volatility20D = decimal.NewFromFloat(dailyVolatility(closes)).Round(2)
+			u.AvgVolume20D = math.Round(volume / float64(len(bars)))
+		}
+
+		if h, ok := held[t]; ok {
+			u.Held = true
+			u.HeldQty = h[0]
+			if h[1].IsPositive() {
+				u.UnrealizedPct = snap.Last.Sub(h[1]).Div(h[1]).Mul(decimal.NewFromInt(100)).Round(2)
+			}
+		}
+		out = append(out, u)
+	}
+	return out
+}
+
+// pctChange returns the % change from the close n bars back to last (all available bars if fewer).
+func pctChange(closes []float64, n int, last float64) decimal.Decimal {
+	i := len(closes) - 1 - n
+	if i < 0 {
+		i = 0
+	}
+	if closes[i] == 0 {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat((last - closes[i]) / closes[i] * 100).Round(2)
+}
+
+// dailyVolatility is the sample standard deviation of daily % returns.
+func dailyVolatility(closes []float64) float64 {
+	var rets []float64
+	for i := 1; i < len(closes); i++ {
+		if closes[i-1] != 0 {
+			rets = append(rets, (closes[i]-closes[i-1])/closes[i-1]*100)
+		}
+	}
+	if len(rets) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, r := range rets {
+		mean += r
+	}
+	mean /= float64(len(rets))
+	ss := 0.0
+	for _, r := range rets {
+		ss += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(ss / float64(len(rets)-1))
+}
+
+// universeContext is the prompt addendum for a focused review.
+func universeContext(universe string, n int) string {
+	return fmt.Sprintf("\nFOCUS_CONTEXT: The user requested a focused review of the universe '%s' (%d symbols, see universe_data). "+
+		"Compare these symbols against each other and against current holdings, and consider sector rotation opportunities within this scope.", universe, n)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
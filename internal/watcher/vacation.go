@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/telegram"
+)
+
+const (
+	policyExecute = "execute"
+	policyDismiss = "dismiss"
+)
+
+// vacationPolicy parses VACATION_POLICY ("SL=execute,TS=execute,TP=dismiss")
+// into trigger -> action. Unknown or missing triggers default to dismiss.
+func (w *Watcher) vacationPolicy() map[string]string {
+	policy := map[string]string{}
+	for _, item := range w.config.VacationPolicy {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		action := strings.ToLower(strings.TrimSpace(kv[1]))
+		if action != policyExecute && action != policyDismiss {
+			log.Printf("Warning: Ignoring invalid VACATION_POLICY entry %q", item)
+			continue
+		}
+		policy[strings.ToUpper(strings.TrimSpace(kv[0]))] = action
+	}
+	return policy
+}
+
+// scheduleTriggerExpiry arms the vacation-mode fallback for a trigger alert:
+// when the confirmation TTL elapses without an answer, the default policy applies.
+func (w *Watcher) scheduleTriggerExpiry(ticker string, alertedAt time.Time) {
+	ttl := time.Duration(w.config.ConfirmationTTLSec) * time.Second
+	w.clock.AfterFunc(ttl, func() { w.handleExpiredTrigger(ticker, alertedAt) })
+}
+
+// handleExpiredTrigger applies the vacation policy to an unanswered trigger alert.
+// Outside vacation mode the pending alert is left as is (normal behavior).
+func (w *Watcher) handleExpiredTrigger(ticker string, alertedAt time.Time) {
+	w.mu.Lock()
+	pending, exists := w.pendingActions[ticker]
+	if !exists || !pending.Timestamp.Equal(alertedAt) || !w.state.Settings.VacationMode {
+		w.mu.Unlock()
+		return // Answered, replaced, or nobody away
+	}
+	delete(w.pendingActions, ticker)
+	w.mu.Unlock()
+
+	action := w.vacationPolicy()[pending.Trigger]
+	if action == "" {
+		action = policyDismiss
+	}
+	log.Printf("Vacation mode: %s alert for %s expired unanswered. Policy: %s", pending.Trigger, ticker, action)
+
+	if action == policyDismiss {
+		w.recordAudit(ticker, auditCancelled, "SYSTEM", pending.TriggerPrice, pending.Trigger+" alert expired (vacation policy: dismiss)")
+		telegram.Notify(fmt.Sprintf("🏖️ *VACATION MODE*: %s alert for %s expired unanswered. Dismissed by policy.", pending.Trigger, ticker))
+		return
+	}
+
+	w.recordAudit(ticker, auditTrigger, "SYSTEM", pending.TriggerPrice, pending.Trigger+" alert expired (vacation policy: execute)")
+	result := w.executeTriggerSell(pending, true)
+	telegram.Notify(fmt.Sprintf("🏖️ *VACATION MODE*: %s alert for %s expired unanswered. Executing by policy.\n%s", pending.Trigger, ticker, result))
+}
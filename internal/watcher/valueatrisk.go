@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"alpha_trading/internal/config"
+	"alpha_trading/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// What the open positions stand to lose, for the /status footer and the EOD report:
+//   - stop risk: the loss if every active stop-loss filled at its level (a position
+//     without a stop counts with its full value);
+//   - historical VaR: the 5th percentile of the portfolio's one-day P/L, replaying the
+//     holdings' daily returns over the last hedgeHistoryDays sessions at today's values.
+
+const varConfidence = 95 // Historical VaR confidence, in %
+
+// closesEntry caches a ticker's daily closes for one CET day.
+type closesEntry struct {
+	day    string
+	closes map[string]float64
+}
+
+// portfolioRisk is the at-risk summary of the active positions.
+type portfolioRisk struct {
+	StopRisk decimal.Decimal // Loss if every SL fills at its level
+	NoStop   []string        // Positions without a stop (full value in StopRisk)
+	VaR      decimal.Decimal // One-day historical VaR; zero = not enough history
+	Days     int             // Daily returns behind VaR
+}
+
+// dailyCloses returns closesByDate(ticker), fetched at most once per CET day. Nil
+// means unavailable.
+func (w *Watcher) dailyCloses(ticker string) map[string]float64 {
+	day := w.clock.Now().In(config.CetLoc).Format("2006-01-02")
+	w.mu.RLock()
+	e, ok := w.closesCache[ticker]
+	w.mu.RUnlock()
+	if ok && e.day == day {
+		return e.closes
+	}
+	closes, err := w.closesByDate(ticker)
+	if err != nil {
+		log.Printf("[%s] VaR: daily bars unavailable: %v", ticker, err)
+		closes = nil
+	}
+	w.mu.Lock()
+	w.closesCache[ticker] = closesEntry{day: day, closes: closes}
+	w.mu.Unlock()
+	return closes
+}
+
+// computeRisk measures positions at prices (the entry where a price is missing).
+func (w *Watcher) computeRisk(positions []models.Position, prices map[string]decimal.Decimal) portfolioRisk {
+	var r portfolioRisk
+	values := make(map[string]float64)
+	for _, p := range positions {
+		price := prices[p.Ticker]
+		if !price.IsPositive() {
+			price = p.EntryPrice
+		}
+		value := price.Mul(p.Quantity)
+		values[p.Ticker] += value.InexactFloat64()
+		switch {
+		case !p.StopLoss.IsPositive():
+			r.NoStop = append(r.NoStop, p.Ticker)
+			r.StopRisk = r.StopRisk.Add(value)
+		case p.StopLoss.LessThan(price):
+			r.StopRisk = r.StopRisk.Add(price.Sub(p.StopLoss).Mul(p.Quantity))
+		}
+	}
+	r.VaR, r.Days = w.historicalVaR(values)
+	return r
+}
+
+// historicalVaR replays the holdings' daily returns over the sessions they all traded
+// and returns the loss at the varConfidence percentile, with the number of days used.
+// Zero when a holding has no history or fewer than hedgeMinReturns days are shared.
+func (w *Watcher) historicalVaR(values map[string]float64) (decimal.Decimal, int) {
+	if len(values) == 0 {
+		return decimal.Zero, 0
+	}
+	closes := make(map[string]map[string]float64, len(values))
+	var base map[string]float64 // Any one series: shared dates are a subset of its dates
+	for t := range values {
+		c := w.dailyCloses(t)
+		if len(c) == 0 {
+			return decimal.Zero, 0
+		}
+		closes[t], base = c, c
+	}
+	var dates []string
+	for d := range base {
+		shared := true
+		for _, c := range closes {
+			if _, ok := c[d]; !ok {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			dates = append(dates, d)
+		}
+	}
+	sort.Strings(dates)
+
+	var pl []decimal.Decimal
+	for i := 1; i < len(dates); i++ {
+		prev, cur := dates[i-1], dates[i]
+		day := 0.0
+		for t, v := range values {
+			day += v * (closes[t][cur]/closes[t][prev] - 1)
+		}
+		pl = append(pl, decimal.NewFromFloat(day))
+	}
+	if len(pl) < hedgeMinReturns {
+		return decimal.Zero, 0
+	}
+	sort.Slice(pl, func(i, j int) bool { return pl[i].LessThan(pl[j]) })
+	loss := nearestRank(pl, 100-varConfidence).Neg()
+	return decimal.Max(decimal.Zero, loss), len(pl)
+}
+
+// formatRisk renders the at-risk lines; % are of equity when it is known.
+func (w *Watcher) formatRisk(r portfolioRisk, equity decimal.Decimal) string {
+	pct := func(v decimal.Decimal) string {
+		if !equity.IsPositive() {
+			return ""
+		}
+		return fmt.Sprintf(" (%s%%)", v.Div(equity).Mul(decimal.NewFromInt(100)).StringFixed(1))
+	}
+	line := fmt.Sprintf("🛡️ %s: $%s%s if every SL hits", w.tr("At Risk"), r.StopRisk.StringFixed(2), pct(r.StopRisk))
+	if r.VaR.IsPositive() {
+		line += fmt.Sprintf(" | 1d VaR %d%%: $%s%s", varConfidence, r.VaR.StringFixed(2), pct(r.VaR))
+	} else {
+		line += " | 1d VaR: n/a"
+	}
+	if len(r.NoStop) > 0 {
+		line += fmt.Sprintf("\n⚠️ No stop (full value counted): %s", strings.Join(r.NoStop, ", "))
+	}
+	return line
+}
+
+// riskSection is the EOD report's value-at-risk block.
+func (w *Watcher) riskSection(positions []models.Position, prices map[string]decimal.Decimal, equity decimal.Decimal) string {
+	if len(positions) == 0 {
+		return ""
+	}
+	r := w.computeRisk(positions, prices)
+	out := fmt.Sprintf("*%s*\n%s", w.tr("Value at Risk"), w.formatRisk(r, equity))
+	if r.Days > 0 {
+		out += fmt.Sprintf("\nVaR from %d daily returns of the current holdings.", r.Days)
+	}
+	return out
+}
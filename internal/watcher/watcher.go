@@ -1,6 +1,7 @@
 package watcher
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -8,14 +9,19 @@ import (
 	"time"
 
 	"alpha_trading/internal/ai"
+	"alpha_trading/internal/budget"
+	"alpha_trading/internal/clock"
+	"alpha_trading/internal/compliance"
 	"alpha_trading/internal/config"
 	"alpha_trading/internal/market"
+	"alpha_trading/internal/metadata"
 	"alpha_trading/internal/models"
 	"alpha_trading/internal/storage"
+	"alpha_trading/internal/symbols"
 	"alpha_trading/internal/telegram"
-)
 
-var startTime = time.Now()
+	"github.com/shopspring/decimal"
+)
 
 var sectors = map[string][]string{
 	"biotech": {"XBI", "VRTX", "AMGN"},
@@ -25,56 +31,167 @@ var sectors = map[string][]string{
 }
 
 type Watcher struct {
-	provider         market.MarketProvider
-	state            models.PortfolioState
-	mu               sync.RWMutex
-	commands         []CommandDoc
-	pendingActions   map[string]PendingAction
-	pendingProposals map[string]PendingProposal
-	lastAlerts       map[string]time.Time // To prevent alert fatigue (Spec 38)
-	lastAnalyzeTime  map[string]time.Time // To prevent API spam (Spec 64)
-	wasMarketOpen    bool                 // For EOD trigger (Spec 49)
-	config           *config.Config
+	ctx               context.Context // Parent of every broker call; cancelled on shutdown
+	clock             clock.Clock     // Time source (config.Clock); fake in tests
+	startedAt         time.Time       // For /status uptime
+	provider          market.MarketProvider
+	state             models.PortfolioState
+	mu                sync.RWMutex
+	commands          []CommandDoc
+	pendingActions    map[string]PendingAction
+	pendingProposals  map[string]PendingProposal
+	lastAlerts        map[string]time.Time   // To prevent alert fatigue (Spec 38)
+	lastAnalyzeTime   map[string]time.Time   // To prevent API spam (Spec 64)
+	triggerStreaks    map[string]int         // Consecutive breaching checks per ticker/trigger (hysteresis)
+	triggerFirstSeen  map[string]time.Time   // First breaching check per ticker/trigger (latency tracking)
+	atrCache          map[string]atrEntry    // Daily ATR per ticker (stagnation check)
+	closesCache       map[string]closesEntry // Daily closes per ticker (value at risk)
+	carryCache        map[string]carryEntry  // Daily provider carry rate per ticker (crypto carry)
+	fundCache         map[string]fundEntry   // Fundamentals per ticker (/info, AI snapshot)
+	aiCallsDay        string                 // CET date aiCallsToday refers to (AI_DAILY_CALL_LIMIT)
+	aiCallsToday      int
+	lastAI            *aiCacheEntry          // Last analysis, reused while the snapshot is unchanged
+	pendingReview     *pendingStrategyReview // Strategy review awaiting APPLY/DISMISS
+	loggedPositions   []models.Position      // Positions as of the last state event (diff baseline)
+	pendingEnv        *pendingEnvSwitch      // Live account switch awaiting the final button
+	pendingResume     *pendingResumeConfirm  // /resume awaiting its button (kill switch)
+	pendingStops      *pendingStopReview     // Weekly stop raises awaiting APPLY ALL
+	pendingChecklist  *pendingTradeChecklist // /buy proposal held back by TRADE_CHECKLIST
+	pendingPDT        *pendingPDTSell        // /sell held back by the PDT guard
+	pendingImport     *pendingImport         // Previewed CSV import awaiting the confirm button
+	pendingConfig     *pendingConfigImport   // Previewed config bundle awaiting the confirm button
+	peers             []shardPeer            // Secondary instances reached by /<name> (SHARD_PEERS)
+	polls             int                    // Polls run since start (slow positions, /priority)
+	spreadCheckDay    string                 // CET date of the last wide-spread check
+	writer            *storage.StateWriter   // Single goroutine for debounced state saves
+	lastEquity        decimal.Decimal        // Broker equity at the last JIT sync (budget cap; zero = unknown)
+	lastScheduledSync time.Time              // Last SYNC_INTERVAL_MINS reconciliation
+	equityToday       *intradayEquity        // Today's equity samples (intraday high/low/drawdown)
+	orders            *orderEvents           // Terminal order events from the trade stream
+	wasMarketOpen     bool                   // For EOD trigger (Spec 49)
+	config            *config.Config
+	metadata          *metadata.Store           // Sector/industry classification
+	fundamentals      market.FundamentalsSource // Market cap, P/E (FUNDAMENTALS_PROVIDER); nil = bars only
+	rules             *compliance.Rules         // Pre-trade compliance rules
 }
 
-func New(cfg *config.Config, provider market.MarketProvider) *Watcher {
+// since and until are time.Since and time.Until on the watcher's clock.
+func (w *Watcher) since(t time.Time) time.Duration { return w.clock.Now().Sub(t) }
+func (w *Watcher) until(t time.Time) time.Duration { return t.Sub(w.clock.Now()) }
+
+func New(ctx context.Context, cfg *config.Config, provider market.MarketProvider) *Watcher {
+	// User-defined symbol aliases (e.g., GOOGLE=GOOGL)
+	symbols.RegisterAliases(cfg.SymbolAliases)
+
 	// Load initial state into memory
 	s, err := storage.LoadState()
 	if err != nil {
 		log.Printf("CRITICAL: Could not load initial state: %v", err)
 	}
 
+	clk := cfg.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+
 	w := &Watcher{
+		ctx:              ctx,
+		clock:            clk,
+		startedAt:        clk.Now(),
 		provider:         provider,
 		state:            s,
+		writer:           storage.NewStateWriter(s, time.Duration(cfg.StateSaveDebounceMs)*time.Millisecond),
 		pendingActions:   make(map[string]PendingAction),
 		pendingProposals: make(map[string]PendingProposal),
 		lastAlerts:       make(map[string]time.Time),
 		lastAnalyzeTime:  make(map[string]time.Time),
+		triggerStreaks:   make(map[string]int),
+		triggerFirstSeen: make(map[string]time.Time),
+		atrCache:         make(map[string]atrEntry),
+		closesCache:      make(map[string]closesEntry),
+		carryCache:       make(map[string]carryEntry),
+		fundCache:        make(map[string]fundEntry),
+		fundamentals:     loadFundamentalsSource(cfg.FundamentalsProvider),
+		orders:           newOrderEvents(clk),
 		config:           cfg,
+		metadata:         metadata.Load(cfg.AssetMetadataFile),
+		rules:            compliance.Load(cfg.ComplianceRulesFile),
 		wasMarketOpen:    false, // Default to false, will sync on first poll
 		commands: []CommandDoc{
-			{"/buy", "Propose a new trade", "/buy <ticker> <qty> [sl] [tp]"},
+			{"/buy", "Propose a new trade ($amount = notional, ext = extended-hours limit)", "/buy <ticker> <qty|$amount> [sl] [tp] [ext] [book=<name>]"},
+			{"/protect", "Attach a broker-side exit (OCO pair or native trailing stop)", "/protect <ticker|all>"},
+			{"/buylimit", "Propose a limit buy; tracked until it fills", "/buylimit <ticker> <qty> <limit> [day|gtc] [ext] [book=<name>]"},
 			{"/sell", "Liquidate and clean state", "/sell <ticker>"},
 			{"/refresh", "Sync local state with Alpaca truth", "/refresh"},
 			{"/status", "Immediate Rich Dashboard", "/status"},
 			{"/list", "List active positions", "/list"},
-			{"/price", "Get real-time price for a ticker", "/price AAPL"},
+			{"/price", "Get real-time price for a ticker (or favorites)", "/price [AAPL]"},
 			{"/market", "Check market status", "/market"},
 			{"/search", "Search for assets by name/ticker", "/search Apple"},
+			{"/news", "Latest headlines for a ticker", "/news <ticker> [count]"},
+			{"/info", "Fundamentals: market cap, P/E, avg volume, 52-week range", "/info <ticker>"},
 			{"/ping", "Check bot latency", "/ping"},
 			{"/update", "Update SL/TP for active position", "/update <ticker> <sl> <tp> [ts-pct]"},
-			{"/scan", "Scan sector health (biotech, metals, energy, defense)", "/scan <sector>"},
-			{"/analyze", "Request AI portfolio analysis (10m cooldown)", "/analyze [ticker]"},
+			{"/scan", "Scan sector or industry health (e.g., biotech, uranium, semiconductors)", "/scan <sector>"},
+			{"/stops", "Review stops against the weekly range and raise them in one tap", "/stops"},
+			{"/resume", "Lift the max drawdown kill switch (asks for confirmation)", "/resume"},
+			{"/sweep", "Show idle cash and the SWEEP_ETF holding; now proposes sweeping the excess", "/sweep [now]"},
+			{"/size", "Size a buy so the stop loses a fixed risk (RISK_PER_TRADE_PCT or the amount given)", "/size AAPL [50] [180.5]"},
+			{"/hedge", "Show the portfolio's beta to SPY and size an inverse-ETF or short-SPY hedge (buy proposes it)", "/hedge [buy] [pct]"},
+			{"/spreads", "Show sampled bid/ask spreads vs the default stop distance", "/spreads [ticker]"},
+			{"/priority", "Check a position more often (hot) or less often (slow)", "/priority [<ticker> hot|normal|slow]"},
+			{"/watch", "List or manage the runtime watchlist (forex pairs are watch-only)", "/watch [add|remove] <ticker> | /watch add EURUSD below=1.05 above=1.12 | /watch levels <ticker> [below=<p>] [above=<p>] | /watch sync"},
+			{"/settings", "Preferences: layout, columns, language, quiet hours, default qty, favorites, EOD report", "/settings [layout|show|hide|lang|quiet|qty|fav|vacation|eod|reset] ..."},
+			{"/benchmark", "Manage comparison portfolios for the EOD report", "/benchmark add 60-40 SPY=60 AGG=40"},
+			{"/book", "Virtual sub-portfolios with their own budget, limits and P/L", "/book add swing 500 max=3 sl=4 tp=10"},
+			{"/project", "Monte Carlo projection of 1-month outcomes", "/project"},
+			{"/risk", "Risk overview (sector exposure)", "/risk"},
+			{"/analyze", "Request AI portfolio analysis (10m cooldown)", "/analyze [force] [ticker|watchlist|holdings|<sector>]"},
 			{"/portfolio", "Dump raw portfolio state for debugging", "/portfolio"},
+			{"/setup", "First-run configuration wizard", "/setup"},
+			{"/pin", "Complete a large buy that needs the PIN", "/pin <PIN> [TICKER]"},
+			{"/history", "Closed trades from the archive", "/history [TICKER|30d]"},
+			{"/why", "Decision trail of the latest trade in a symbol", "/why <ticker>"},
+			{"/stats", "Provider call counts, latencies and error rates per method", "/stats [reset]"},
+			{"/latency", "Trigger-to-fill latency percentiles", "/latency [days]"},
+			{"/slippage", "Fill vs decision price report for a month", "/slippage [YYYY-MM]"},
+			{"/returns", "Time- and money-weighted returns for a month", "/returns [YYYY-MM]"},
+			{"/goal", "Track equity and drawdown goals with a projected trajectory", "/goal [equity 5000 by december | drawdown 10 | remove <n>]"},
+			{"/divergence", "Paper vs live fills, slippage and P/L", "/divergence [days]"},
+			{"/optimize", "Sweep SL/TP/TS through a backtest with out-of-sample validation", "/optimize [bars] | walk [bars] [folds]"},
+			{"/events", "Position event log: history, time travel, replay check", "/events [TICKER] | at YYYY-MM-DD [HH:MM] | verify"},
+			{"/env", "Show or switch the Alpaca account (paper/live)", "/env [paper | live]"},
+			{"/review", "Run the AI strategy review of the last 7 days", "/review"},
+			{"/block", "Block a ticker from every buy path (list if no ticker)", "/block [ticker]"},
+			{"/unblock", "Remove a ticker from the blocklist", "/unblock <ticker>"},
+			{"/doctor", "Check state integrity (and repair with backup)", "/doctor [fix]"},
+			{"/import", "Import existing positions' levels from a CSV (file or pasted)", "/import (then CSV rows, or upload a .csv with caption /import)"},
+			{"/export-config", "Export settings, watchlist, benchmarks, books, goals and blocklist (no secrets)", "/export-config"},
+			{"/import-config", "Import a bundle from /export-config (file or pasted)", "/import-config (then the JSON, or upload the file with caption /import-config)"},
+			{"/state", "Read or correct one state field (validated, with backup)", "/state get [path] | /state set <path> <value>"},
 			{"/help", "Show this help message", "/help"},
 		},
 	}
 
+	w.peers = w.loadShardPeers()
+
+	// Align the position event log with the loaded state (or rebuild from it)
+	w.initEventLog(err)
+
+	// Runtime overrides saved by /setup
+	w.applySettingsOverrides()
+
+	// The state may belong to the other Alpaca account (/env before the restart)
+	w.alignEnvState()
+
+	// Orders placed just before the last shutdown, before the first sync can import them blind
+	w.reconcileIntents()
+
 	return w
 }
 
 func (w *Watcher) Poll() {
+	defer w.trackPoll()()
 	w.checkEOD()
 
 	var sendDashboard bool
@@ -83,6 +200,7 @@ func (w *Watcher) Poll() {
 	func() {
 		w.mu.Lock()
 		defer w.mu.Unlock()
+		w.polls++ // Slow positions are checked every SLOW_POLL_EVERY polls
 
 		// --- AUTO-STATUS / HEARTBEAT ---
 		// Spec 34: If market is OPEN and PollInterval elapsed (implied by Poll call), send dashboard.
@@ -110,35 +228,39 @@ func (w *Watcher) Poll() {
 				sendDashboard = true
 			} else {
 				lastHB, _ := time.Parse(time.RFC3339, w.state.LastHeartbeat)
-				if time.Since(lastHB) >= 24*time.Hour {
+				if w.since(lastHB) >= 24*time.Hour {
 					sendDashboard = true
 				}
 			}
 		}
 
 		if sendDashboard {
-			w.state.LastHeartbeat = time.Now().In(config.CetLoc).Format(time.RFC3339)
+			w.state.LastHeartbeat = w.clock.Now().In(config.CetLoc).Format(time.RFC3339)
 		}
 	}()
 
 	// 2. Dashboard Delivery (Outside Lock)
 	if sendDashboard {
 		// Spec 43: Check Market Status
-		clock, err := w.provider.GetClock()
+		clock, err := w.provider.GetClock(w.ctx)
 		isMarketOpen := err == nil && clock.IsOpen
 
 		shouldSend := false
 		if w.config.AutoStatusEnabled {
-			if isMarketOpen {
-				shouldSend = true // Only send if Market is OPEN
+			if isMarketOpen || w.holdsCrypto() {
+				shouldSend = true // Only send if Market is OPEN (crypto positions move 24/7)
 			}
 		} else {
-			shouldSend = true // Fallback 24h heartbeat
+			// Fallback 24h heartbeat; a secondary's is merged into the primary's
+			shouldSend = !w.isSecondary()
 		}
 
 		if shouldSend {
 			msg := w.getStatus()
-			telegram.Notify(msg)
+			if !w.config.AutoStatusEnabled {
+				msg += "\n\n" + w.heartbeatDetails(clock) + w.peerHeartbeats()
+			}
+			w.notifyRoutine(msg) // Muted during quiet hours (/settings quiet)
 		}
 	}
 
@@ -148,7 +270,65 @@ func (w *Watcher) Poll() {
 	// We can put Risk Logic in its own method `checkRisk()`?
 	// Or just do it here. The original function was monolithic.
 	// Let's run risk check here.
-	w.checkRisk()
+	// 2.8 Scheduled broker reconciliation (drift alerts)
+	w.checkScheduledSync()
+
+	// 2.9 Corporate actions first: a split must rescale the levels before they are checked
+	w.checkCorporateActions()
+	w.checkRisk(false)
+
+	// 3.1 Daily loss circuit breaker and max drawdown kill switch (after the exits this poll took)
+	w.checkDailyLoss()
+	w.checkDrawdown()
+
+	// 3.3 Intraday equity curve (one sample per poll)
+	w.sampleEquity()
+
+	// 3.31 Crypto carry (staking yield, funding/borrow cost) accrued per position
+	w.accrueCarry()
+
+	// 3.32 Bid/ask spread history (liquidity guard), with a daily wide-spread check
+	w.sampleSpreads()
+	w.checkSpreads()
+
+	// 3.35 Option positions (monitor only, expiry warnings)
+	w.refreshOptions()
+
+	// 3.4 Resting buy orders (limit, extended hours)
+	w.checkPendingOrders()
+
+	// 3.42 Idle cash sweep into SWEEP_ETF (proposal after SWEEP_IDLE_DAYS)
+	w.checkCashSweep()
+
+	// 3.45 Broker-side exits: SL/TP pairs or native trailing stops (BROKER_PROTECTION_ENABLED)
+	w.checkBrokerExits()
+
+	// 3.5 Watchlist Move Alerts (Scan -> Watch -> Alert pipeline)
+	w.checkWatchlist()
+
+	// 3.6 Opening Gap Alerts (held positions, once per session)
+	w.checkGaps()
+
+	// 3.65 State Integrity Validator (/doctor)
+	w.checkStateIntegrity()
+
+	// 3.66 Weekly trigger-to-fill latency report
+	w.checkWeeklyLatencyReport()
+
+	// 3.665 Weekly paper/live divergence report
+	w.checkWeeklyDivergenceReport()
+
+	// 3.67 Monthly slippage report (previous month)
+	w.checkMonthlySlippageReport()
+
+	// 3.675 Monthly TWR/MWR report (previous month)
+	w.checkMonthlyReturnsReport()
+
+	// 3.676 Goal tracking (daily milestones, weekly progress report)
+	w.checkGoals()
+
+	// 3.68 Weekly AI strategy review
+	w.checkWeeklyStrategyReview()
 
 	// 4. AI Analysis Loop (Spec 58)
 	// Trigger: Success of Poll Interval AND Market is Open (or Pre-Market)
@@ -156,8 +336,11 @@ func (w *Watcher) Poll() {
 	// We need to check if Market is Open.
 	// Re-fetch clock to be sure or reuse if we had it?
 	// We fetch it fresh to be safe.
-	c, err := w.provider.GetClock()
+	c, err := w.provider.GetClock(w.ctx)
 	if err == nil {
+		// 3.7 Pre-open protection checklist (once per day)
+		w.checkMorningProtection(c)
+
 		// Time Gates:
 		// 1. Market Open
 		// 2. Pre-Market (14:30 - 15:30 CET). US Open is 15:30 CET.
@@ -172,19 +355,25 @@ func (w *Watcher) Poll() {
 			runAI = true
 		} else {
 			// Check Pre-Market (1 hour before open)
-			if time.Until(c.NextOpen) <= 1*time.Hour {
+			if w.until(c.NextOpen) <= 1*time.Hour {
 				runAI = true
 			}
 		}
 
+		if rule, _ := w.tradingHalt(); runAI && rule != "" {
+			runAI = false // Paused by the kill switch or the daily loss circuit breaker
+		}
 		if runAI {
 			// Run AI Analysis Async
-			go w.runAIAnalysis("", false)
+			go w.runAIAnalysis("", "", nil, false, false)
 		}
 	}
 }
 
-func (w *Watcher) runAIAnalysis(ticker string, isManual bool) {
+// runAIAnalysis runs one AI review. ticker focuses a single asset; universe/universeTickers
+// scope a focused review (see resolveAnalyzeScope). Both empty means a global review.
+// force skips the analysis cache.
+func (w *Watcher) runAIAnalysis(ticker, universe string, universeTickers []string, isManual, force bool) {
 	// Spec 58 & 64: AI Analysis Loop
 	if w.config.GeminiAPIKey == "" {
 		return
@@ -196,6 +385,26 @@ func (w *Watcher) runAIAnalysis(ticker string, isManual bool) {
 		log.Printf("AI Error: Failed to build snapshot: %v", err)
 		return
 	}
+	if universe != "" {
+		snapshot.Universe = universe
+		snapshot.UniverseData = w.buildUniverseData(universeTickers)
+		snapshot.MarketContext = fmt.Sprintf("Universe Review: %s", universe)
+	}
+
+	// Unchanged snapshot: reuse the last answer instead of paying for a new call
+	fp := w.snapshotFingerprint(ticker+"|"+universe, snapshot)
+	if !force {
+		if cached, at, ok := w.cachedAnalysis(fp); ok {
+			since := at.In(config.CetLoc).Format("15:04")
+			if !isManual {
+				log.Printf("AI cache hit: snapshot unchanged since %s CET. Skipping model call.", since)
+				return
+			}
+			telegram.Notify(fmt.Sprintf("♻️ Snapshot unchanged since %s CET. Reusing that analysis (use /analyze force for a fresh call).", since))
+			w.handleAIResult(cached, snapshot, isManual)
+			return
+		}
+	}
 
 	// 2. Call AI
 	// We need an AI Client.
@@ -205,6 +414,15 @@ func (w *Watcher) runAIAnalysis(ticker string, isManual bool) {
 	// But since we are patching, let's instantiate.
 	aiClient := ai.NewClient() // We'll fix imports later
 
+	// Daily AI call budget
+	if !w.reserveAICall() {
+		log.Printf("AI Analysis skipped: daily call limit reached (%d).", w.config.AIDailyCallLimit)
+		if isManual {
+			telegram.Notify(fmt.Sprintf("⚠️ AI daily call limit reached (%d). Try again tomorrow.", w.config.AIDailyCallLimit))
+		}
+		return
+	}
+
 	// Load System Instruction
 	sysInstr, err := os.ReadFile("portfolio_review_update.md")
 	if err != nil {
@@ -216,15 +434,31 @@ func (w *Watcher) runAIAnalysis(ticker string, isManual bool) {
 	contextMsg := ""
 	if ticker != "" {
 		contextMsg = fmt.Sprintf("\nFOCUS_CONTEXT: The user requested a specific analysis for %s. Please prioritize this asset in your review.", ticker)
+	} else if universe != "" {
+		contextMsg = universeContext(universe, len(snapshot.UniverseData))
 	}
 
-	analysis, err := aiClient.AnalyzePortfolio(string(sysInstr)+contextMsg, *snapshot)
+	// Manual runs stream the answer into a message that is edited as tokens arrive
+	var analysis *ai.AIAnalysis
+	title := "Global Review"
+	if ticker != "" {
+		title = ticker
+	} else if universe != "" {
+		title = universe
+	}
+	if stream := w.newReportStream(isManual, title); stream != nil {
+		analysis, err = aiClient.AnalyzePortfolioStream(string(sysInstr)+contextMsg, *snapshot, stream.update)
+		stream.finish(analysis, err)
+	} else {
+		analysis, err = aiClient.AnalyzePortfolio(string(sysInstr)+contextMsg, *snapshot)
+	}
 	if err != nil {
 		log.Printf("AI Error: API failure: %v", err)
 		// Always notify on API failure (e.g. Quota Exceeded) so user knows why AI is silent
 		telegram.Notify(fmt.Sprintf("⚠️ AI Analysis Failed:\n```\n%v\n```", err))
 		return
 	}
+	w.storeAnalysis(fp, *analysis)
 
 	// 3. Process Result (Spec 59, 60, 61, 62)
 	w.handleAIResult(analysis, snapshot, isManual)
@@ -236,6 +470,13 @@ func (w *Watcher) buildPortfolioSnapshot(ticker string) (*ai.PortfolioSnapshot,
 	if _, err := w.SyncWithBroker(); err != nil {
 		log.Printf("Snapshot Warning: JIT Sync failed: %v", err)
 	}
+	intraday := w.intradayBars(ticker) // Network calls, before taking the lock
+	news := w.newsHeadlines(ticker)
+	fundamentals := w.snapshotFundamentals(ticker)
+	intradayFrame := ""
+	if intraday != nil {
+		intradayFrame = w.config.AIIntradayTimeframe
+	}
 
 	w.mu.RLock()
 	defer w.mu.RUnlock()
@@ -258,16 +499,16 @@ func (w *Watcher) buildPortfolioSnapshot(ticker string) (*ai.PortfolioSnapshot,
 		}
 	}
 
-	equity, err := w.provider.GetEquity()
+	equity, err := w.provider.GetEquity(w.ctx)
 	if err != nil {
 		return nil, err
 	}
-	bp, err := w.provider.GetBuyingPower()
+	bp, err := w.provider.GetBuyingPower(w.ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	clock, _ := w.provider.GetClock()
+	clock, _ := w.provider.GetClock(w.ctx)
 	status := "CLOSED"
 	if clock != nil && clock.IsOpen {
 		status = "OPEN"
@@ -278,16 +519,24 @@ func (w *Watcher) buildPortfolioSnapshot(ticker string) (*ai.PortfolioSnapshot,
 		marketContext = fmt.Sprintf("Analysis Focus: %s", ticker)
 	}
 
+	// Same rules as the persisted metrics, capped by the equity fetched just now
+	b := budget.Compute(w.state.Positions, decimal.NewFromFloat(w.config.FiscalBudgetLimit), equity)
+
 	return &ai.PortfolioSnapshot{
-		Timestamp:       time.Now().Format(time.RFC3339),
+		Timestamp:       w.clock.Now().Format(time.RFC3339),
 		MarketStatus:    status,
 		Capital:         bp,
 		Equity:          equity,
-		FiscalLimit:     w.state.FiscalLimit,
-		AvailableBudget: w.state.AvailableBudget,
-		CurrentExposure: w.state.CurrentExposure,
+		FiscalLimit:     b.FiscalLimit,
+		AvailableBudget: b.Available,
+		CurrentExposure: b.Exposure,
 		Positions:       w.state.Positions,
 		MarketContext:   marketContext,
 		WatchlistPrices: w.state.WatchlistPrices, // Spec 74
+		WatchlistAsOf:   w.state.WatchlistPricesAt,
+		Intraday:        intraday,
+		IntradayFrame:   intradayFrame,
+		News:            news,
+		Fundamentals:    fundamentals,
 	}, nil
 }
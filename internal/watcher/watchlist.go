@@ -0,0 +1,422 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"alpha_trading/internal/models"
+	"alpha_trading/internal/symbols"
+
+	"github.com/shopspring/decimal"
+)
+
+// handleWatchCommand manages the runtime watchlist.
+// /watch                                         -> list entries
+// /watch add <ticker> [below=<p>] [above=<p>]     -> start tracking (optional level alerts)
+// /watch levels <ticker> [below=<p>] [above=<p>]  -> set or clear the level alerts
+// /watch remove <ticker>                          -> stop tracking
+// /watch sync                                     -> two-way merge with the Alpaca server-side watchlist
+// Forex pairs (EURUSD) are watch-only: they are priced by FX_PROVIDER and cannot be traded.
+func (w *Watcher) handleWatchCommand(parts []string) string {
+	if len(parts) < 2 {
+		return w.getWatchlist()
+	}
+
+	sub := strings.ToLower(parts[1])
+	switch sub {
+	case "add":
+		if len(parts) < 3 {
+			return "Usage: /watch add <ticker> [below=<price>] [above=<price>]"
+		}
+		below, above, err := parseWatchLevels(parts[3:])
+		if err != nil {
+			return "⚠️ " + err.Error()
+		}
+		ticker := symbols.Normalize(parts[2])
+		msg := w.addToWatchlist(ticker, "MANUAL")
+		if below.IsPositive() || above.IsPositive() {
+			if reply, ok := w.setWatchLevels(ticker, below, above); ok {
+				msg += "\n" + reply
+			}
+		}
+		return msg
+	case "levels":
+		if len(parts) < 3 {
+			return "Usage: /watch levels <ticker> [below=<price>] [above=<price>] (no levels clears them)"
+		}
+		below, above, err := parseWatchLevels(parts[3:])
+		if err != nil {
+			return "⚠️ " + err.Error()
+		}
+		reply, _ := w.setWatchLevels(symbols.Normalize(parts[2]), below, above)
+		return reply
+	case "remove", "rm":
+		if len(parts) < 3 {
+			return "Usage: /watch remove <ticker>"
+		}
+		return w.removeFromWatchlist(symbols.Normalize(parts[2]))
+	case "sync":
+		return w.syncAlpacaWatchlist()
+	default:
+		return "Usage: /watch [add|remove|levels] <ticker> | /watch sync"
+	}
+}
+
+// parseWatchLevels reads below=<price> and above=<price> arguments.
+func parseWatchLevels(args []string) (below, above decimal.Decimal, err error) {
+	for _, a := range args {
+		key, val, ok := strings.Cut(strings.ToLower(a), "=")
+		p, perr := decimal.NewFromString(val)
+		if !ok || perr != nil || !p.IsPositive() {
+			return below, above, fmt.Errorf("invalid level %q (use below=<price> or above=<price>)", a)
+		}
+		switch key {
+		case "below":
+			below = p
+		case "above":
+			above = p
+		default:
+			return below, above, fmt.Errorf("invalid level %q (use below=<price> or above=<price>)", a)
+		}
+	}
+	if below.IsPositive() && above.IsPositive() && !below.LessThan(above) {
+		return below, above, fmt.Errorf("below (%s) must be under above (%s)", below, above)
+	}
+	return below, above, nil
+}
+
+// setWatchLevels stores the level alerts of a runtime watchlist entry (zero clears).
+func (w *Watcher) setWatchLevels(ticker string, below, above decimal.Decimal) (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, e := range w.state.Watchlist {
+		if e.Ticker != ticker {
+			continue
+		}
+		w.state.Watchlist[i].AlertBelow = below
+		w.state.Watchlist[i].AlertAbove = above
+		delete(w.lastAlerts, ticker+"_WATCH_BELOW")
+		delete(w.lastAlerts, ticker+"_WATCH_ABOVE")
+		w.saveStateLocked()
+		log.Printf("Watchlist: %s levels below=%s above=%s", ticker, below, above)
+		if below.IsZero() && above.IsZero() {
+			return fmt.Sprintf("🔕 Level alerts cleared for %s.", ticker), true
+		}
+		return fmt.Sprintf("🔔 %s level alerts: %s.", ticker, watchLevelsText(ticker, below, above)), true
+	}
+	return fmt.Sprintf("⚠️ %s is not on the runtime watchlist.", ticker), false
+}
+
+func watchLevelsText(ticker string, below, above decimal.Decimal) string {
+	var levels []string
+	if below.IsPositive() {
+		levels = append(levels, "below "+watchPrice(ticker, below))
+	}
+	if above.IsPositive() {
+		levels = append(levels, "above "+watchPrice(ticker, above))
+	}
+	return strings.Join(levels, ", ")
+}
+
+// watchPrice formats a watchlist price: FX rates need four decimals.
+func watchPrice(ticker string, p decimal.Decimal) string {
+	if symbols.IsForex(ticker) {
+		return p.StringFixed(4)
+	}
+	return "$" + p.StringFixed(2)
+}
+
+// addToWatchlist validates the ticker against the market and persists a new entry.
+// The current price is stored as the reference for move alerts. The entry is mirrored
+// to the Alpaca watchlist if one has been linked via /watch sync.
+func (w *Watcher) addToWatchlist(ticker, source string) string {
+	msg, added := w.addLocalWatch(ticker, source)
+	if added {
+		w.mirrorWatchlistChange(ticker, true)
+	}
+	return msg
+}
+
+// addLocalWatch adds the entry to local state only. Returns the reply and whether it was added.
+func (w *Watcher) addLocalWatch(ticker, source string) (string, bool) {
+	price, err := w.provider.GetPrice(w.ctx, ticker)
+	if err != nil || price.IsZero() {
+		if symbols.IsForex(ticker) && w.config.FXProvider == "" {
+			return fmt.Sprintf("⚠️ %s is a forex pair. Set FX_PROVIDER to watch it.", ticker), false
+		}
+		return fmt.Sprintf("⚠️ Could not fetch price for %s. Not added to watchlist.", ticker), false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, e := range w.state.Watchlist {
+		if e.Ticker == ticker {
+			return fmt.Sprintf("ℹ️ %s is already on the watchlist.", ticker), false
+		}
+	}
+
+	w.state.Watchlist = append(w.state.Watchlist, models.WatchlistEntry{
+		Ticker:         ticker,
+		AddedAt:        w.clock.Now(),
+		Source:         source,
+		ReferencePrice: price,
+	})
+	w.saveStateLocked()
+
+	log.Printf("Watchlist: Added %s (Source: %s, Ref: %s)", ticker, source, watchPrice(ticker, price))
+	msg := fmt.Sprintf("👀 Watching %s @ %s. You'll be alerted on a ±%.1f%% move.",
+		ticker, watchPrice(ticker, price), w.config.WatchlistAlertPct)
+	if symbols.IsForex(ticker) {
+		msg += " Watch-only: forex pairs cannot be traded."
+	}
+	return msg, true
+}
+
+func (w *Watcher) removeFromWatchlist(ticker string) string {
+	w.mu.Lock()
+	removed := false
+	for i, e := range w.state.Watchlist {
+		if e.Ticker == ticker {
+			w.state.Watchlist = append(w.state.Watchlist[:i], w.state.Watchlist[i+1:]...)
+			delete(w.state.WatchlistPrices, ticker)
+			delete(w.state.WatchlistPricesAt, ticker)
+			w.saveStateLocked()
+			removed = true
+			break
+		}
+	}
+	w.mu.Unlock()
+
+	if !removed {
+		return fmt.Sprintf("⚠️ %s is not on the runtime watchlist.", ticker)
+	}
+	w.mirrorWatchlistChange(ticker, false)
+	return fmt.Sprintf("🗑️ %s removed from watchlist.", ticker)
+}
+
+// syncAlpacaWatchlist merges the runtime watchlist with the Alpaca watchlist named
+// ALPACA_WATCHLIST_NAME (created on first sync). Symbols missing on either side are added
+// to the other, so the bot and the Alpaca app show the same list.
+func (w *Watcher) syncAlpacaWatchlist() string {
+	name := w.config.AlpacaWatchlistName
+	remote, err := w.provider.GetWatchlistByName(w.ctx, name)
+	if err != nil {
+		return fmt.Sprintf("❌ Could not read Alpaca watchlists: %v", err)
+	}
+
+	w.mu.RLock()
+	local := make(map[string]bool)
+	var localTickers []string
+	for _, e := range w.state.Watchlist {
+		local[e.Ticker] = true
+		localTickers = append(localTickers, e.Ticker)
+	}
+	w.mu.RUnlock()
+
+	if remote == nil {
+		created, err := w.provider.CreateWatchlist(w.ctx, name, localTickers)
+		if err != nil {
+			return fmt.Sprintf("❌ Could not create Alpaca watchlist '%s': %v", name, err)
+		}
+		log.Printf("Watchlist Sync: Created Alpaca watchlist %s (%s) with %d symbols", name, created.ID, len(localTickers))
+		return fmt.Sprintf("🔗 Created Alpaca watchlist '%s' with %d symbols.", name, len(localTickers))
+	}
+
+	remoteSet := make(map[string]bool)
+	var pulled, pushed, failed []string
+	for _, a := range remote.Assets {
+		ticker := symbols.Normalize(a.Symbol)
+		remoteSet[ticker] = true
+		if local[ticker] {
+			continue
+		}
+		if _, ok := w.addLocalWatch(ticker, "ALPACA"); ok {
+			pulled = append(pulled, ticker)
+		} else {
+			failed = append(failed, ticker)
+		}
+	}
+	for _, t := range localTickers {
+		if remoteSet[t] || symbols.IsForex(t) {
+			continue
+		}
+		if err := w.provider.AddToWatchlist(w.ctx, remote.ID, t); err != nil {
+			log.Printf("Watchlist Sync: Failed to push %s: %v", t, err)
+			failed = append(failed, t)
+			continue
+		}
+		pushed = append(pushed, t)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔗 *WATCHLIST SYNC* ('%s')\n", name))
+	sb.WriteString(fmt.Sprintf("⬇️ From Alpaca: %s\n", joinOrNone(pulled)))
+	sb.WriteString(fmt.Sprintf("⬆️ To Alpaca: %s", joinOrNone(pushed)))
+	if len(failed) > 0 {
+		sb.WriteString(fmt.Sprintf("\n⚠️ Failed: %s", strings.Join(failed, ", ")))
+	}
+	return sb.String()
+}
+
+// mirrorWatchlistChange applies a local add/remove to the linked Alpaca watchlist.
+// Best effort: if no list exists yet (no /watch sync), nothing is done.
+func (w *Watcher) mirrorWatchlistChange(ticker string, add bool) {
+	if symbols.IsForex(ticker) {
+		return // Not an Alpaca asset
+	}
+	remote, err := w.provider.GetWatchlistByName(w.ctx, w.config.AlpacaWatchlistName)
+	if err != nil || remote == nil {
+		return
+	}
+	if add {
+		err = w.provider.AddToWatchlist(w.ctx, remote.ID, ticker)
+	} else {
+		err = w.provider.RemoveFromWatchlist(w.ctx, remote.ID, ticker)
+	}
+	if err != nil {
+		log.Printf("Watchlist Sync: Failed to mirror %s (add=%t): %v", ticker, add, err)
+	}
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+func (w *Watcher) getWatchlist() string {
+	w.mu.RLock()
+	entries := make([]models.WatchlistEntry, len(w.state.Watchlist))
+	copy(entries, w.state.Watchlist)
+	w.mu.RUnlock()
+
+	if len(entries) == 0 && len(w.config.WatchlistTickers) == 0 {
+		return "👀 Watchlist is empty. Use /watch add <ticker> or the buttons under /scan."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("👀 *WATCHLIST*\n")
+	for _, e := range entries {
+		line := fmt.Sprintf("• %s (ref %s, %s)", e.Ticker, watchPrice(e.Ticker, e.ReferencePrice), e.Source)
+		if price, err := w.provider.GetPrice(w.ctx, e.Ticker); err == nil && !e.ReferencePrice.IsZero() {
+			pct := price.Sub(e.ReferencePrice).Div(e.ReferencePrice).Mul(decimal.NewFromInt(100))
+			line = fmt.Sprintf("• %s: %s (%s%% since added, %s)", e.Ticker, watchPrice(e.Ticker, price), pct.StringFixed(2), e.Source)
+		}
+		if levels := watchLevelsText(e.Ticker, e.AlertBelow, e.AlertAbove); levels != "" {
+			line += " 🔔 " + levels
+		}
+		if symbols.IsForex(e.Ticker) {
+			line += " _watch-only_"
+		}
+		sb.WriteString(line + "\n")
+	}
+	if len(w.config.WatchlistTickers) > 0 {
+		sb.WriteString(fmt.Sprintf("\n_Static (env):_ %s", strings.Join(w.config.WatchlistTickers, ", ")))
+	}
+	return sb.String()
+}
+
+// watchlistTickers merges the static env watchlist with runtime entries (deduplicated, uppercased).
+// It assumes w.mu is held (read or write) by the caller.
+func (w *Watcher) watchlistTickers() []string {
+	seen := make(map[string]bool)
+	var tickers []string
+	add := func(t string) {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			return
+		}
+		seen[t] = true
+		tickers = append(tickers, t)
+	}
+	for _, t := range w.config.WatchlistTickers {
+		add(t)
+	}
+	for _, e := range w.state.Watchlist {
+		add(e.Ticker)
+	}
+	return tickers
+}
+
+// handleWatchCallback processes WATCH_ADD_<TICKER> buttons from /scan results.
+func (w *Watcher) handleWatchCallback(data string) string {
+	parts := strings.Split(data, "_")
+	if len(parts) < 3 || parts[1] != "ADD" {
+		return "⚠️ Invalid watch callback data."
+	}
+	ticker := parts[2]
+	return w.addToWatchlist(ticker, "SCAN")
+}
+
+// checkWatchlist alerts when a runtime watchlist ticker moves beyond WATCHLIST_ALERT_PCT
+// from its reference price, or reaches one of its levels (/watch levels). Alerts are
+// throttled to once per 24h per ticker and kind.
+func (w *Watcher) checkWatchlist() {
+	w.mu.RLock()
+	entries := make([]models.WatchlistEntry, len(w.state.Watchlist))
+	copy(entries, w.state.Watchlist)
+	w.mu.RUnlock()
+
+	threshold := decimal.NewFromFloat(w.config.WatchlistAlertPct)
+	for _, e := range entries {
+		hasLevels := e.AlertBelow.IsPositive() || e.AlertAbove.IsPositive()
+		if !hasLevels && (e.ReferencePrice.IsZero() || !threshold.IsPositive()) {
+			continue
+		}
+		price, err := w.provider.GetPrice(w.ctx, e.Ticker)
+		if err != nil || price.IsZero() {
+			log.Printf("Watchlist Warning: Could not fetch price for %s: %v", e.Ticker, err)
+			continue
+		}
+
+		next := fmt.Sprintf("Propose an entry with `/buy %s <qty>`.", e.Ticker)
+		if symbols.IsForex(e.Ticker) {
+			next = "Watch-only: forex pairs cannot be traded."
+		}
+		if e.AlertBelow.IsPositive() && price.LessThanOrEqual(e.AlertBelow) {
+			w.sendWatchAlert(e.Ticker+"_WATCH_BELOW", fmt.Sprintf("🔔 *WATCHLIST LEVEL: %s*\n🔴 At %s, at or below your level %s.\n%s",
+				e.Ticker, watchPrice(e.Ticker, price), watchPrice(e.Ticker, e.AlertBelow), next))
+		}
+		if e.AlertAbove.IsPositive() && price.GreaterThanOrEqual(e.AlertAbove) {
+			w.sendWatchAlert(e.Ticker+"_WATCH_ABOVE", fmt.Sprintf("🔔 *WATCHLIST LEVEL: %s*\n🟢 At %s, at or above your level %s.\n%s",
+				e.Ticker, watchPrice(e.Ticker, price), watchPrice(e.Ticker, e.AlertAbove), next))
+		}
+
+		if e.ReferencePrice.IsZero() || !threshold.IsPositive() {
+			continue
+		}
+		pct := price.Sub(e.ReferencePrice).Div(e.ReferencePrice).Mul(decimal.NewFromInt(100))
+		if pct.Abs().LessThan(threshold) {
+			continue
+		}
+		icon := "🟢"
+		if pct.IsNegative() {
+			icon = "🔴"
+		}
+		w.sendWatchAlert(e.Ticker+"_WATCH", fmt.Sprintf("👀 *WATCHLIST ALERT: %s*\n%s Moved %s%% since added (%s → %s).\n%s",
+			e.Ticker, icon, pct.StringFixed(2), watchPrice(e.Ticker, e.ReferencePrice), watchPrice(e.Ticker, price), next))
+	}
+}
+
+// sendWatchAlert sends a routine watchlist alert at most once per 24h per key.
+func (w *Watcher) sendWatchAlert(key, msg string) {
+	w.mu.Lock()
+	last, ok := w.lastAlerts[key]
+	if ok && w.since(last) < 24*time.Hour {
+		w.mu.Unlock()
+		return
+	}
+	w.lastAlerts[key] = w.clock.Now()
+	w.mu.Unlock()
+
+	if !w.notifyRoutine(msg) {
+		// Muted by quiet hours: allow the alert again on a later poll
+		w.mu.Lock()
+		delete(w.lastAlerts, key)
+		w.mu.Unlock()
+	}
+}